@@ -29,7 +29,7 @@ const (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:path=clusterprofiles,scope=Cluster
+//+kubebuilder:resource:path=clusterprofiles,scope=Cluster,categories=sveltos
 //+kubebuilder:subresource:status
 
 // ClusterProfile is the Schema for the clusterprofiles API
@@ -208,7 +208,7 @@ type ClusterSummaryStatus struct {
 }
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:path=clustersummaries,scope=Namespaced
+//+kubebuilder:resource:path=clustersummaries,scope=Namespaced,categories=sveltos
 //+kubebuilder:subresource:status
 
 // ClusterSummary is the Schema for the clustersummaries API
@@ -70,6 +70,16 @@ func Convert_v1beta1_HelmChart_To_v1alpha1_HelmChart(src *configv1beta1.HelmChar
 	return nil
 }
 
+func Convert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(
+	src *configv1beta1.HelmOptions, dst *HelmOptions, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func Convert_v1beta1_HelmInstallOptions_To_v1alpha1_HelmInstallOptions(
 	src *configv1beta1.HelmInstallOptions, dst *HelmInstallOptions, s conversion.Scope) error {
 
@@ -99,3 +109,85 @@ func Convert_v1beta1_HelmUpgradeOptions_To_v1alpha1_HelmUpgradeOptions(
 
 	return nil
 }
+
+func Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(
+	src *configv1beta1.HelmChartSummary, dst *HelmChartSummary, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_Status_To_v1alpha1_Status(
+	src *configv1beta1.Status, dst *Status, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_Status_To_v1alpha1_Status(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(
+	src *configv1beta1.ClusterSummaryStatus, dst *ClusterSummaryStatus, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(
+	src *configv1beta1.FeatureSummary, dst *FeatureSummary, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(
+	src *configv1beta1.ClusterReportStatus, dst *ClusterReportStatus, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(src, dst, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_Feature_To_v1alpha1_Feature(
+	src *configv1beta1.Feature, dst *Feature, s conversion.Scope) error {
+
+	if err := autoConvert_v1beta1_Feature_To_v1alpha1_Feature(src, dst, s); err != nil {
+		return err
+	}
+
+	resources, err := src.GetResources()
+	if err != nil {
+		return err
+	}
+	// CompressedResources does not exist in v1alpha1. Resources are always stored uncompressed
+	dst.Resources = nil
+	if resources != nil {
+		dst.Resources = make([]Resource, len(resources))
+		for i := range resources {
+			if err := Convert_v1beta1_Resource_To_v1alpha1_Resource(&resources[i], &dst.Resources[i], s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func Convert_v1beta1_Chart_To_v1alpha1_Chart(
+	src *configv1beta1.Chart, dst *Chart, s conversion.Scope) error {
+
+	// Notes and ComputedValues do not exist in v1alpha1
+	return autoConvert_v1beta1_Chart_To_v1alpha1_Chart(src, dst, s)
+}
@@ -29,7 +29,7 @@ const (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:path=profiles,scope=Namespaced
+//+kubebuilder:resource:path=profiles,scope=Namespaced,categories=sveltos
 //+kubebuilder:subresource:status
 
 // Profile is the Schema for the profiles API
@@ -46,11 +46,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Chart)(nil), (*Chart)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Chart_To_v1alpha1_Chart(a.(*v1beta1.Chart), b.(*Chart), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*ClusterConfiguration)(nil), (*v1beta1.ClusterConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ClusterConfiguration_To_v1beta1_ClusterConfiguration(a.(*ClusterConfiguration), b.(*v1beta1.ClusterConfiguration), scope)
 	}); err != nil {
@@ -146,11 +141,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.ClusterReportStatus)(nil), (*ClusterReportStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(a.(*v1beta1.ClusterReportStatus), b.(*ClusterReportStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*ClusterSummary)(nil), (*v1beta1.ClusterSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ClusterSummary_To_v1beta1_ClusterSummary(a.(*ClusterSummary), b.(*v1beta1.ClusterSummary), scope)
 	}); err != nil {
@@ -186,11 +176,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.ClusterSummaryStatus)(nil), (*ClusterSummaryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(a.(*v1beta1.ClusterSummaryStatus), b.(*ClusterSummaryStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Clusters)(nil), (*v1beta1.Clusters)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Clusters_To_v1beta1_Clusters(a.(*Clusters), b.(*v1beta1.Clusters), scope)
 	}); err != nil {
@@ -216,11 +201,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Feature)(nil), (*Feature)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Feature_To_v1alpha1_Feature(a.(*v1beta1.Feature), b.(*Feature), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*FeatureDeploymentInfo)(nil), (*v1beta1.FeatureDeploymentInfo)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_FeatureDeploymentInfo_To_v1beta1_FeatureDeploymentInfo(a.(*FeatureDeploymentInfo), b.(*v1beta1.FeatureDeploymentInfo), scope)
 	}); err != nil {
@@ -236,11 +216,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.FeatureSummary)(nil), (*FeatureSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(a.(*v1beta1.FeatureSummary), b.(*FeatureSummary), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*HelmChart)(nil), (*v1beta1.HelmChart)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_HelmChart_To_v1beta1_HelmChart(a.(*HelmChart), b.(*v1beta1.HelmChart), scope)
 	}); err != nil {
@@ -251,11 +226,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.HelmChartSummary)(nil), (*HelmChartSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(a.(*v1beta1.HelmChartSummary), b.(*HelmChartSummary), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*HelmInstallOptions)(nil), (*v1beta1.HelmInstallOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_HelmInstallOptions_To_v1beta1_HelmInstallOptions(a.(*HelmInstallOptions), b.(*v1beta1.HelmInstallOptions), scope)
 	}); err != nil {
@@ -266,11 +236,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.HelmOptions)(nil), (*HelmOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(a.(*v1beta1.HelmOptions), b.(*HelmOptions), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*HelmUninstallOptions)(nil), (*v1beta1.HelmUninstallOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_HelmUninstallOptions_To_v1beta1_HelmUninstallOptions(a.(*HelmUninstallOptions), b.(*v1beta1.HelmUninstallOptions), scope)
 	}); err != nil {
@@ -366,11 +331,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Status)(nil), (*Status)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Status_To_v1alpha1_Status(a.(*v1beta1.Status), b.(*Status), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*TemplateResourceRef)(nil), (*v1beta1.TemplateResourceRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_TemplateResourceRef_To_v1beta1_TemplateResourceRef(a.(*TemplateResourceRef), b.(*v1beta1.TemplateResourceRef), scope)
 	}); err != nil {
@@ -406,6 +366,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Chart)(nil), (*Chart)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Chart_To_v1alpha1_Chart(a.(*v1beta1.Chart), b.(*Chart), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.ClusterReportStatus)(nil), (*ClusterReportStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(a.(*v1beta1.ClusterReportStatus), b.(*ClusterReportStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.ClusterSummaryStatus)(nil), (*ClusterSummaryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(a.(*v1beta1.ClusterSummaryStatus), b.(*ClusterSummaryStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.Feature)(nil), (*Feature)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Feature_To_v1alpha1_Feature(a.(*v1beta1.Feature), b.(*Feature), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.FeatureSummary)(nil), (*FeatureSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(a.(*v1beta1.FeatureSummary), b.(*FeatureSummary), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.HelmChartSummary)(nil), (*HelmChartSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(a.(*v1beta1.HelmChartSummary), b.(*HelmChartSummary), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.HelmChart)(nil), (*HelmChart)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_HelmChart_To_v1alpha1_HelmChart(a.(*v1beta1.HelmChart), b.(*HelmChart), scope)
 	}); err != nil {
@@ -416,6 +406,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.HelmOptions)(nil), (*HelmOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(a.(*v1beta1.HelmOptions), b.(*HelmOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.HelmUninstallOptions)(nil), (*HelmUninstallOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_HelmUninstallOptions_To_v1alpha1_HelmUninstallOptions(a.(*v1beta1.HelmUninstallOptions), b.(*HelmUninstallOptions), scope)
 	}); err != nil {
@@ -431,6 +426,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Status)(nil), (*Status)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Status_To_v1alpha1_Status(a.(*v1beta1.Status), b.(*Status), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -458,14 +458,11 @@ func autoConvert_v1beta1_Chart_To_v1alpha1_Chart(in *v1beta1.Chart, out *Chart,
 	out.AppVersion = in.AppVersion
 	out.Icon = in.Icon
 	out.LastAppliedTime = (*v1.Time)(unsafe.Pointer(in.LastAppliedTime))
+	// WARNING: in.Notes requires manual conversion: does not exist in peer-type
+	// WARNING: in.ComputedValues requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Chart_To_v1alpha1_Chart is an autogenerated conversion function.
-func Convert_v1beta1_Chart_To_v1alpha1_Chart(in *v1beta1.Chart, out *Chart, s conversion.Scope) error {
-	return autoConvert_v1beta1_Chart_To_v1alpha1_Chart(in, out, s)
-}
-
 func autoConvert_v1alpha1_ClusterConfiguration_To_v1beta1_ClusterConfiguration(in *ClusterConfiguration, out *v1beta1.ClusterConfiguration, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_ClusterConfigurationStatus_To_v1beta1_ClusterConfigurationStatus(&in.Status, &out.Status, s); err != nil {
@@ -494,7 +491,17 @@ func Convert_v1beta1_ClusterConfiguration_To_v1alpha1_ClusterConfiguration(in *v
 
 func autoConvert_v1alpha1_ClusterConfigurationList_To_v1beta1_ClusterConfigurationList(in *ClusterConfigurationList, out *v1beta1.ClusterConfigurationList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1beta1.ClusterConfiguration)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1beta1.ClusterConfiguration, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ClusterConfiguration_To_v1beta1_ClusterConfiguration(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -505,7 +512,17 @@ func Convert_v1alpha1_ClusterConfigurationList_To_v1beta1_ClusterConfigurationLi
 
 func autoConvert_v1beta1_ClusterConfigurationList_To_v1alpha1_ClusterConfigurationList(in *v1beta1.ClusterConfigurationList, out *ClusterConfigurationList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]ClusterConfiguration)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterConfiguration, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ClusterConfiguration_To_v1alpha1_ClusterConfiguration(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -515,8 +532,28 @@ func Convert_v1beta1_ClusterConfigurationList_To_v1alpha1_ClusterConfigurationLi
 }
 
 func autoConvert_v1alpha1_ClusterConfigurationStatus_To_v1beta1_ClusterConfigurationStatus(in *ClusterConfigurationStatus, out *v1beta1.ClusterConfigurationStatus, s conversion.Scope) error {
-	out.ClusterProfileResources = *(*[]v1beta1.ClusterProfileResource)(unsafe.Pointer(&in.ClusterProfileResources))
-	out.ProfileResources = *(*[]v1beta1.ProfileResource)(unsafe.Pointer(&in.ProfileResources))
+	if in.ClusterProfileResources != nil {
+		in, out := &in.ClusterProfileResources, &out.ClusterProfileResources
+		*out = make([]v1beta1.ClusterProfileResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ClusterProfileResource_To_v1beta1_ClusterProfileResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ClusterProfileResources = nil
+	}
+	if in.ProfileResources != nil {
+		in, out := &in.ProfileResources, &out.ProfileResources
+		*out = make([]v1beta1.ProfileResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ProfileResource_To_v1beta1_ProfileResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ProfileResources = nil
+	}
 	return nil
 }
 
@@ -526,8 +563,28 @@ func Convert_v1alpha1_ClusterConfigurationStatus_To_v1beta1_ClusterConfiguration
 }
 
 func autoConvert_v1beta1_ClusterConfigurationStatus_To_v1alpha1_ClusterConfigurationStatus(in *v1beta1.ClusterConfigurationStatus, out *ClusterConfigurationStatus, s conversion.Scope) error {
-	out.ClusterProfileResources = *(*[]ClusterProfileResource)(unsafe.Pointer(&in.ClusterProfileResources))
-	out.ProfileResources = *(*[]ProfileResource)(unsafe.Pointer(&in.ProfileResources))
+	if in.ClusterProfileResources != nil {
+		in, out := &in.ClusterProfileResources, &out.ClusterProfileResources
+		*out = make([]ClusterProfileResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ClusterProfileResource_To_v1alpha1_ClusterProfileResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ClusterProfileResources = nil
+	}
+	if in.ProfileResources != nil {
+		in, out := &in.ProfileResources, &out.ProfileResources
+		*out = make([]ProfileResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ProfileResource_To_v1alpha1_ProfileResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ProfileResources = nil
+	}
 	return nil
 }
 
@@ -612,7 +669,17 @@ func Convert_v1beta1_ClusterProfileList_To_v1alpha1_ClusterProfileList(in *v1bet
 
 func autoConvert_v1alpha1_ClusterProfileResource_To_v1beta1_ClusterProfileResource(in *ClusterProfileResource, out *v1beta1.ClusterProfileResource, s conversion.Scope) error {
 	out.ClusterProfileName = in.ClusterProfileName
-	out.Features = *(*[]v1beta1.Feature)(unsafe.Pointer(&in.Features))
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]v1beta1.Feature, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_Feature_To_v1beta1_Feature(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Features = nil
+	}
 	return nil
 }
 
@@ -623,7 +690,17 @@ func Convert_v1alpha1_ClusterProfileResource_To_v1beta1_ClusterProfileResource(i
 
 func autoConvert_v1beta1_ClusterProfileResource_To_v1alpha1_ClusterProfileResource(in *v1beta1.ClusterProfileResource, out *ClusterProfileResource, s conversion.Scope) error {
 	out.ClusterProfileName = in.ClusterProfileName
-	out.Features = *(*[]Feature)(unsafe.Pointer(&in.Features))
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]Feature, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_Feature_To_v1alpha1_Feature(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Features = nil
+	}
 	return nil
 }
 
@@ -666,7 +743,17 @@ func Convert_v1beta1_ClusterReport_To_v1alpha1_ClusterReport(in *v1beta1.Cluster
 
 func autoConvert_v1alpha1_ClusterReportList_To_v1beta1_ClusterReportList(in *ClusterReportList, out *v1beta1.ClusterReportList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1beta1.ClusterReport)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1beta1.ClusterReport, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ClusterReport_To_v1beta1_ClusterReport(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -677,7 +764,17 @@ func Convert_v1alpha1_ClusterReportList_To_v1beta1_ClusterReportList(in *Cluster
 
 func autoConvert_v1beta1_ClusterReportList_To_v1alpha1_ClusterReportList(in *v1beta1.ClusterReportList, out *ClusterReportList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]ClusterReport)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterReport, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ClusterReport_To_v1alpha1_ClusterReport(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -724,14 +821,11 @@ func autoConvert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(in
 	out.ReleaseReports = *(*[]ReleaseReport)(unsafe.Pointer(&in.ReleaseReports))
 	out.ResourceReports = *(*[]ResourceReport)(unsafe.Pointer(&in.ResourceReports))
 	out.KustomizeResourceReports = *(*[]ResourceReport)(unsafe.Pointer(&in.KustomizeResourceReports))
+	// WARNING: in.LastUpdateTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.History requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus is an autogenerated conversion function.
-func Convert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(in *v1beta1.ClusterReportStatus, out *ClusterReportStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_ClusterReportStatus_To_v1alpha1_ClusterReportStatus(in, out, s)
-}
-
 func autoConvert_v1alpha1_ClusterSummary_To_v1beta1_ClusterSummary(in *ClusterSummary, out *v1beta1.ClusterSummary, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_ClusterSummarySpec_To_v1beta1_ClusterSummarySpec(&in.Spec, &out.Spec, s); err != nil {
@@ -838,9 +932,29 @@ func Convert_v1beta1_ClusterSummarySpec_To_v1alpha1_ClusterSummarySpec(in *v1bet
 
 func autoConvert_v1alpha1_ClusterSummaryStatus_To_v1beta1_ClusterSummaryStatus(in *ClusterSummaryStatus, out *v1beta1.ClusterSummaryStatus, s conversion.Scope) error {
 	out.Dependencies = (*string)(unsafe.Pointer(in.Dependencies))
-	out.FeatureSummaries = *(*[]v1beta1.FeatureSummary)(unsafe.Pointer(&in.FeatureSummaries))
+	if in.FeatureSummaries != nil {
+		in, out := &in.FeatureSummaries, &out.FeatureSummaries
+		*out = make([]v1beta1.FeatureSummary, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_FeatureSummary_To_v1beta1_FeatureSummary(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.FeatureSummaries = nil
+	}
 	out.DeployedGVKs = *(*[]v1beta1.FeatureDeploymentInfo)(unsafe.Pointer(&in.DeployedGVKs))
-	out.HelmReleaseSummaries = *(*[]v1beta1.HelmChartSummary)(unsafe.Pointer(&in.HelmReleaseSummaries))
+	if in.HelmReleaseSummaries != nil {
+		in, out := &in.HelmReleaseSummaries, &out.HelmReleaseSummaries
+		*out = make([]v1beta1.HelmChartSummary, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_HelmChartSummary_To_v1beta1_HelmChartSummary(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.HelmReleaseSummaries = nil
+	}
 	return nil
 }
 
@@ -851,17 +965,42 @@ func Convert_v1alpha1_ClusterSummaryStatus_To_v1beta1_ClusterSummaryStatus(in *C
 
 func autoConvert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(in *v1beta1.ClusterSummaryStatus, out *ClusterSummaryStatus, s conversion.Scope) error {
 	out.Dependencies = (*string)(unsafe.Pointer(in.Dependencies))
-	out.FeatureSummaries = *(*[]FeatureSummary)(unsafe.Pointer(&in.FeatureSummaries))
+	if in.FeatureSummaries != nil {
+		in, out := &in.FeatureSummaries, &out.FeatureSummaries
+		*out = make([]FeatureSummary, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.FeatureSummaries = nil
+	}
 	out.DeployedGVKs = *(*[]FeatureDeploymentInfo)(unsafe.Pointer(&in.DeployedGVKs))
-	out.HelmReleaseSummaries = *(*[]HelmChartSummary)(unsafe.Pointer(&in.HelmReleaseSummaries))
+	if in.HelmReleaseSummaries != nil {
+		in, out := &in.HelmReleaseSummaries, &out.HelmReleaseSummaries
+		*out = make([]HelmChartSummary, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.HelmReleaseSummaries = nil
+	}
+	// WARNING: in.MissingReferences requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastSuccessfulSyncTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
+	// WARNING: in.TierStatuses requires manual conversion: does not exist in peer-type
+	// WARNING: in.Ready requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailedFeature requires manual conversion: does not exist in peer-type
+	// WARNING: in.CurrentRevision requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeploymentOutcomes requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResolvedImages requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conflicts requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus is an autogenerated conversion function.
-func Convert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(in *v1beta1.ClusterSummaryStatus, out *ClusterSummaryStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_ClusterSummaryStatus_To_v1alpha1_ClusterSummaryStatus(in, out, s)
-}
-
 func autoConvert_v1alpha1_Clusters_To_v1beta1_Clusters(in *Clusters, out *v1beta1.Clusters, s conversion.Scope) error {
 	out.Hash = *(*[]byte)(unsafe.Pointer(&in.Hash))
 	out.Clusters = *(*[]corev1.ObjectReference)(unsafe.Pointer(&in.Clusters))
@@ -905,7 +1044,17 @@ func Convert_v1beta1_DryRunReconciliationError_To_v1alpha1_DryRunReconciliationE
 func autoConvert_v1alpha1_Feature_To_v1beta1_Feature(in *Feature, out *v1beta1.Feature, s conversion.Scope) error {
 	out.FeatureID = v1beta1.FeatureID(in.FeatureID)
 	out.Resources = *(*[]v1beta1.Resource)(unsafe.Pointer(&in.Resources))
-	out.Charts = *(*[]v1beta1.Chart)(unsafe.Pointer(&in.Charts))
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]v1beta1.Chart, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_Chart_To_v1beta1_Chart(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Charts = nil
+	}
 	return nil
 }
 
@@ -917,15 +1066,21 @@ func Convert_v1alpha1_Feature_To_v1beta1_Feature(in *Feature, out *v1beta1.Featu
 func autoConvert_v1beta1_Feature_To_v1alpha1_Feature(in *v1beta1.Feature, out *Feature, s conversion.Scope) error {
 	out.FeatureID = FeatureID(in.FeatureID)
 	out.Resources = *(*[]Resource)(unsafe.Pointer(&in.Resources))
-	out.Charts = *(*[]Chart)(unsafe.Pointer(&in.Charts))
+	// WARNING: in.CompressedResources requires manual conversion: does not exist in peer-type
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]Chart, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_Chart_To_v1alpha1_Chart(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Charts = nil
+	}
 	return nil
 }
 
-// Convert_v1beta1_Feature_To_v1alpha1_Feature is an autogenerated conversion function.
-func Convert_v1beta1_Feature_To_v1alpha1_Feature(in *v1beta1.Feature, out *Feature, s conversion.Scope) error {
-	return autoConvert_v1beta1_Feature_To_v1alpha1_Feature(in, out, s)
-}
-
 func autoConvert_v1alpha1_FeatureDeploymentInfo_To_v1beta1_FeatureDeploymentInfo(in *FeatureDeploymentInfo, out *v1beta1.FeatureDeploymentInfo, s conversion.Scope) error {
 	out.FeatureID = v1beta1.FeatureID(in.FeatureID)
 	out.DeployedGroupVersionKind = *(*[]string)(unsafe.Pointer(&in.DeployedGroupVersionKind))
@@ -972,14 +1127,11 @@ func autoConvert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(in *v1beta1.F
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.DeployedGroupVersionKind = *(*[]string)(unsafe.Pointer(&in.DeployedGroupVersionKind))
 	out.LastAppliedTime = (*v1.Time)(unsafe.Pointer(in.LastAppliedTime))
+	// WARNING: in.ConsecutiveFailures requires manual conversion: does not exist in peer-type
+	// WARNING: in.PendingResources requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary is an autogenerated conversion function.
-func Convert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(in *v1beta1.FeatureSummary, out *FeatureSummary, s conversion.Scope) error {
-	return autoConvert_v1beta1_FeatureSummary_To_v1alpha1_FeatureSummary(in, out, s)
-}
-
 func autoConvert_v1alpha1_HelmChart_To_v1beta1_HelmChart(in *HelmChart, out *v1beta1.HelmChart, s conversion.Scope) error {
 	out.RepositoryURL = in.RepositoryURL
 	out.RepositoryName = in.RepositoryName
@@ -1009,13 +1161,17 @@ func Convert_v1alpha1_HelmChart_To_v1beta1_HelmChart(in *HelmChart, out *v1beta1
 
 func autoConvert_v1beta1_HelmChart_To_v1alpha1_HelmChart(in *v1beta1.HelmChart, out *HelmChart, s conversion.Scope) error {
 	out.RepositoryURL = in.RepositoryURL
+	// WARNING: in.SourceRef requires manual conversion: does not exist in peer-type
 	out.RepositoryName = in.RepositoryName
 	out.ChartName = in.ChartName
 	out.ChartVersion = in.ChartVersion
+	// WARNING: in.VersionPolicy requires manual conversion: does not exist in peer-type
 	out.ReleaseName = in.ReleaseName
 	out.ReleaseNamespace = in.ReleaseNamespace
 	out.Values = in.Values
 	out.ValuesFrom = *(*[]ValueFrom)(unsafe.Pointer(&in.ValuesFrom))
+	// WARNING: in.ValuesPresets requires manual conversion: does not exist in peer-type
+	// WARNING: in.Patches requires manual conversion: does not exist in peer-type
 	out.HelmChartAction = HelmChartAction(in.HelmChartAction)
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
@@ -1027,6 +1183,8 @@ func autoConvert_v1beta1_HelmChart_To_v1alpha1_HelmChart(in *v1beta1.HelmChart,
 		out.Options = nil
 	}
 	// WARNING: in.RegistryCredentialsConfig requires manual conversion: does not exist in peer-type
+	// WARNING: in.Verify requires manual conversion: does not exist in peer-type
+	// WARNING: in.DependencyRepositories requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1050,14 +1208,16 @@ func autoConvert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(in *v1bet
 	out.Status = HelmChartStatus(in.Status)
 	out.ValuesHash = *(*[]byte)(unsafe.Pointer(&in.ValuesHash))
 	out.ConflictMessage = in.ConflictMessage
+	// WARNING: in.ResolvedVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.PendingUpgradeVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.Storage requires manual conversion: does not exist in peer-type
+	// WARNING: in.RollbackMessage requires manual conversion: does not exist in peer-type
+	// WARNING: in.ValuesDiff requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeployedVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary is an autogenerated conversion function.
-func Convert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(in *v1beta1.HelmChartSummary, out *HelmChartSummary, s conversion.Scope) error {
-	return autoConvert_v1beta1_HelmChartSummary_To_v1alpha1_HelmChartSummary(in, out, s)
-}
-
 func autoConvert_v1alpha1_HelmInstallOptions_To_v1beta1_HelmInstallOptions(in *HelmInstallOptions, out *v1beta1.HelmInstallOptions, s conversion.Scope) error {
 	out.CreateNamespace = in.CreateNamespace
 	out.Replace = in.Replace
@@ -1119,6 +1279,10 @@ func autoConvert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(in *v1beta1.HelmOpt
 	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
 	out.EnableClientCache = in.EnableClientCache
 	out.Description = in.Description
+	// WARNING: in.RunHelmTests requires manual conversion: does not exist in peer-type
+	// WARNING: in.HelmTestsTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.RollbackOnHelmTestsFailure requires manual conversion: does not exist in peer-type
+	// WARNING: in.Storage requires manual conversion: does not exist in peer-type
 	if err := Convert_v1beta1_HelmInstallOptions_To_v1alpha1_HelmInstallOptions(&in.InstallOptions, &out.InstallOptions, s); err != nil {
 		return err
 	}
@@ -1131,11 +1295,6 @@ func autoConvert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(in *v1beta1.HelmOpt
 	return nil
 }
 
-// Convert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions is an autogenerated conversion function.
-func Convert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(in *v1beta1.HelmOptions, out *HelmOptions, s conversion.Scope) error {
-	return autoConvert_v1beta1_HelmOptions_To_v1alpha1_HelmOptions(in, out, s)
-}
-
 func autoConvert_v1alpha1_HelmUninstallOptions_To_v1beta1_HelmUninstallOptions(in *HelmUninstallOptions, out *v1beta1.HelmUninstallOptions, s conversion.Scope) error {
 	out.KeepHistory = in.KeepHistory
 	out.DeletionPropagation = in.DeletionPropagation
@@ -1151,6 +1310,8 @@ func autoConvert_v1beta1_HelmUninstallOptions_To_v1alpha1_HelmUninstallOptions(i
 	out.KeepHistory = in.KeepHistory
 	out.DeletionPropagation = in.DeletionPropagation
 	// WARNING: in.DisableHooks requires manual conversion: does not exist in peer-type
+	// WARNING: in.Wait requires manual conversion: does not exist in peer-type
+	// WARNING: in.Timeout requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1240,6 +1401,8 @@ func autoConvert_v1beta1_PolicyRef_To_v1alpha1_PolicyRef(in *v1beta1.PolicyRef,
 	out.Kind = in.Kind
 	out.Path = in.Path
 	out.DeploymentType = DeploymentType(in.DeploymentType)
+	// WARNING: in.ReloadWorkloads requires manual conversion: does not exist in peer-type
+	// WARNING: in.PruneAllowlist requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1324,7 +1487,17 @@ func Convert_v1beta1_ProfileList_To_v1alpha1_ProfileList(in *v1beta1.ProfileList
 
 func autoConvert_v1alpha1_ProfileResource_To_v1beta1_ProfileResource(in *ProfileResource, out *v1beta1.ProfileResource, s conversion.Scope) error {
 	out.ProfileName = in.ProfileName
-	out.Features = *(*[]v1beta1.Feature)(unsafe.Pointer(&in.Features))
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]v1beta1.Feature, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_Feature_To_v1beta1_Feature(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Features = nil
+	}
 	return nil
 }
 
@@ -1335,7 +1508,17 @@ func Convert_v1alpha1_ProfileResource_To_v1beta1_ProfileResource(in *ProfileReso
 
 func autoConvert_v1beta1_ProfileResource_To_v1alpha1_ProfileResource(in *v1beta1.ProfileResource, out *ProfileResource, s conversion.Scope) error {
 	out.ProfileName = in.ProfileName
-	out.Features = *(*[]Feature)(unsafe.Pointer(&in.Features))
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]Feature, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_Feature_To_v1alpha1_Feature(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Features = nil
+	}
 	return nil
 }
 
@@ -1469,15 +1652,32 @@ func autoConvert_v1beta1_Spec_To_v1alpha1_Spec(in *v1beta1.Spec, out *Spec, s co
 	// WARNING: in.ClusterSelector requires manual conversion: inconvertible types (github.com/projectsveltos/libsveltos/api/v1beta1.Selector vs github.com/projectsveltos/libsveltos/api/v1alpha1.Selector)
 	out.ClusterRefs = *(*[]corev1.ObjectReference)(unsafe.Pointer(&in.ClusterRefs))
 	out.SetRefs = *(*[]string)(unsafe.Pointer(&in.SetRefs))
+	// WARNING: in.ParentProfile requires manual conversion: does not exist in peer-type
 	out.SyncMode = SyncMode(in.SyncMode)
 	out.Tier = in.Tier
 	out.ContinueOnConflict = in.ContinueOnConflict
+	// WARNING: in.ContinueOnError requires manual conversion: does not exist in peer-type
+	// WARNING: in.GenerateRBAC requires manual conversion: does not exist in peer-type
+	// WARNING: in.DedicatedIdentity requires manual conversion: does not exist in peer-type
 	out.MaxUpdate = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUpdate))
 	out.StopMatchingBehavior = StopMatchingBehavior(in.StopMatchingBehavior)
+	// WARNING: in.StopMatchingBehaviorTemplate requires manual conversion: does not exist in peer-type
 	out.Reloader = in.Reloader
 	out.TemplateResourceRefs = *(*[]TemplateResourceRef)(unsafe.Pointer(&in.TemplateResourceRefs))
+	// WARNING: in.Variables requires manual conversion: does not exist in peer-type
 	out.DependsOn = *(*[]string)(unsafe.Pointer(&in.DependsOn))
+	// WARNING: in.DeploymentOrder requires manual conversion: does not exist in peer-type
+	// WARNING: in.SupersededBy requires manual conversion: does not exist in peer-type
+	// WARNING: in.HealthCheckGates requires manual conversion: does not exist in peer-type
+	// WARNING: in.WriteBudget requires manual conversion: does not exist in peer-type
+	// WARNING: in.ErrorBudget requires manual conversion: does not exist in peer-type
+	// WARNING: in.MatchExpansionGuard requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeletionProtection requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecretTransformer requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageDigestResolution requires manual conversion: does not exist in peer-type
+	// WARNING: in.WasmPlugins requires manual conversion: does not exist in peer-type
 	out.PolicyRefs = *(*[]PolicyRef)(unsafe.Pointer(&in.PolicyRefs))
+	// WARNING: in.InlineResources requires manual conversion: does not exist in peer-type
 	if in.HelmCharts != nil {
 		in, out := &in.HelmCharts, &out.HelmCharts
 		*out = make([]HelmChart, len(*in))
@@ -1490,6 +1690,9 @@ func autoConvert_v1beta1_Spec_To_v1alpha1_Spec(in *v1beta1.Spec, out *Spec, s co
 		out.HelmCharts = nil
 	}
 	out.KustomizationRefs = *(*[]KustomizationRef)(unsafe.Pointer(&in.KustomizationRefs))
+	// WARNING: in.Jobs requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecretRotationHooks requires manual conversion: does not exist in peer-type
+	// WARNING: in.Extensions requires manual conversion: does not exist in peer-type
 	out.ValidateHealths = *(*[]ValidateHealth)(unsafe.Pointer(&in.ValidateHealths))
 	// WARNING: in.Patches requires manual conversion: does not exist in peer-type
 	// WARNING: in.DriftExclusions requires manual conversion: does not exist in peer-type
@@ -1522,14 +1725,15 @@ func autoConvert_v1beta1_Status_To_v1alpha1_Status(in *v1beta1.Status, out *Stat
 	if err := Convert_v1beta1_Clusters_To_v1alpha1_Clusters(&in.UpdatedClusters, &out.UpdatedClusters, s); err != nil {
 		return err
 	}
+	// WARNING: in.ReferenceValidationErrors requires manual conversion: does not exist in peer-type
+	// WARNING: in.MigratedClusterRefs requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReadyClusters requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailedClusters requires manual conversion: does not exist in peer-type
+	// WARNING: in.Rollout requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Status_To_v1alpha1_Status is an autogenerated conversion function.
-func Convert_v1beta1_Status_To_v1alpha1_Status(in *v1beta1.Status, out *Status, s conversion.Scope) error {
-	return autoConvert_v1beta1_Status_To_v1alpha1_Status(in, out, s)
-}
-
 func autoConvert_v1alpha1_TemplateResourceRef_To_v1beta1_TemplateResourceRef(in *TemplateResourceRef, out *v1beta1.TemplateResourceRef, s conversion.Scope) error {
 	out.Resource = in.Resource
 	out.Identifier = in.Identifier
@@ -1602,6 +1806,8 @@ func autoConvert_v1beta1_ValueFrom_To_v1alpha1_ValueFrom(in *v1beta1.ValueFrom,
 	out.Namespace = in.Namespace
 	out.Name = in.Name
 	out.Kind = in.Kind
+	// WARNING: in.Key requires manual conversion: does not exist in peer-type
+	// WARNING: in.Optional requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -89,6 +89,18 @@ type Chart struct {
 
 	// LastAppliedTime identifies when this resource was last applied to the cluster.
 	LastAppliedTime *metav1.Time `json:"lastAppliedTime"`
+
+	// Notes contains, gzipped and base64 encoded, the rendered NOTES.txt of the release.
+	// Use GetNotes to access it.
+	// +optional
+	Notes string `json:"notes,omitempty"`
+
+	// ComputedValues contains, gzipped and base64 encoded, the final values (in YAML format)
+	// the release was deployed with. Values whose key suggests sensitive content, like
+	// passwords or tokens, are redacted.
+	// Use GetComputedValues to access them.
+	// +optional
+	ComputedValues string `json:"computedValues,omitempty"`
 }
 
 type Feature struct {
@@ -99,6 +111,12 @@ type Feature struct {
 	// +optional
 	Resources []Resource `json:"resources,omitempty"`
 
+	// CompressedResources contains, gzipped and base64 encoded, the list of resources
+	// deployed in the Cluster. It is used in place of Resources when the list is large.
+	// Use GetResources to access resources independently of how they are stored.
+	// +optional
+	CompressedResources string `json:"compressedResources,omitempty"`
+
 	// Charts is a list of helm charts deployed in the Cluster.
 	// +optional
 	Charts []Chart `json:"charts,omitempty"`
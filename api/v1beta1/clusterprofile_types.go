@@ -28,10 +28,16 @@ const (
 	ClusterProfileKind = "ClusterProfile"
 )
 
+//nolint: lll // marker
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=clusterprofiles,scope=Cluster
+// +kubebuilder:resource:path=clusterprofiles,scope=Cluster,categories=sveltos
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.readyClusters",description="Matching clusters with all features provisioned"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedClusters",description="Matching clusters with at least one feature failed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterProfile is the Schema for the clusterprofiles API
 type ClusterProfile struct {
@@ -20,6 +20,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	ClusterReportKind = "ClusterReport"
+)
+
 // HelmAction represents the type of action on a give resource or helm release
 type HelmAction string
 
@@ -107,6 +111,36 @@ type ClusterReportStatus struct {
 	// deployed because of KustomizationRefs
 	// +optional
 	KustomizeResourceReports []ResourceReport `json:"kustomizeResourceReports,omitempty"`
+
+	// LastUpdateTime is the last time reports were updated
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// History contains previous reports, most recent first. It allows comparing
+	// successive DryRun plans. Number of entries kept is configured in the
+	// addon-controller (cluster-report-history). No history is kept by default.
+	// +optional
+	History []ClusterReportSnapshot `json:"history,omitempty"`
+}
+
+// ClusterReportSnapshot contains the reports of a previous DryRun
+type ClusterReportSnapshot struct {
+	// Time is when those reports were generated
+	Time metav1.Time `json:"time"`
+
+	// ReleaseReports contains report on helm releases
+	// +optional
+	ReleaseReports []ReleaseReport `json:"releaseReports,omitempty"`
+
+	// ResourceReports contains report on Kubernetes resources
+	// deployed because of PolicyRefs
+	// +optional
+	ResourceReports []ResourceReport `json:"resourceReports,omitempty"`
+
+	// KustomizeResourceReports contains report on Kubernetes resources
+	// deployed because of KustomizationRefs
+	// +optional
+	KustomizeResourceReports []ResourceReport `json:"kustomizeResourceReports,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -36,9 +36,78 @@ const (
 	ClusterSummaryFinalizer = "clustersummaryfinalizer.projectsveltos.io"
 
 	ClusterSummaryKind = "ClusterSummary"
+
+	// SyncStaleCondition is True when ClusterSummary has not been successfully synced
+	// within the configured sync SLO window
+	SyncStaleCondition = "SyncStale"
+
+	// SyncedReason is the SyncStaleCondition reason when all features are successfully deployed
+	SyncedReason = "Synced"
+
+	// SyncWithinSLOReason is the SyncStaleCondition reason when features are not deployed
+	// yet but last successful sync is within the sync SLO window
+	SyncWithinSLOReason = "WithinSLO"
+
+	// SyncSLOExceededReason is the SyncStaleCondition reason when last successful sync is
+	// older than the sync SLO window
+	SyncSLOExceededReason = "SLOExceeded"
+
+	// PreflightFailedCondition is True when the managed cluster is not reachable or does not
+	// grant the permissions needed to deploy the ClusterSummary
+	PreflightFailedCondition = "PreflightFailed"
+
+	// PreflightPassedReason is the PreflightFailedCondition reason when all preflight checks passed
+	PreflightPassedReason = "PreflightPassed"
+
+	// ClusterUnreachableReason is the PreflightFailedCondition reason when the managed cluster
+	// API server cannot be reached
+	ClusterUnreachableReason = "ClusterUnreachable"
+
+	// InsufficientPermissionsReason is the PreflightFailedCondition reason when some of the
+	// permissions needed are not granted in the managed cluster
+	InsufficientPermissionsReason = "InsufficientPermissions"
+
+	// PausedCondition is True when deployments to the managed cluster are suspended because
+	// either the cluster or the ClusterSummary is paused
+	PausedCondition = "Paused"
+
+	// ClusterPausedReason is the PausedCondition reason when the CAPI Cluster or SveltosCluster
+	// has spec.paused set
+	ClusterPausedReason = "ClusterPaused"
+
+	// ClusterSummaryPausedReason is the PausedCondition reason when the ClusterSummary has the
+	// paused annotation
+	ClusterSummaryPausedReason = "ClusterSummaryPaused"
+
+	// NotPausedReason is the PausedCondition reason when deployments are not suspended
+	NotPausedReason = "NotPaused"
+
+	// OutsideMaintenanceWindowCondition is True when deployments to the managed cluster are held
+	// back till the cluster maintenance window opens
+	OutsideMaintenanceWindowCondition = "OutsideMaintenanceWindow"
+
+	// WaitingForMaintenanceWindowReason is the OutsideMaintenanceWindowCondition reason when
+	// deployments are held back till the next maintenance window
+	WaitingForMaintenanceWindowReason = "WaitingForMaintenanceWindow"
+
+	// DeploymentAllowedReason is the OutsideMaintenanceWindowCondition reason when the cluster has
+	// no maintenance window, the window is open or it is bypassed by a break-glass
+	DeploymentAllowedReason = "DeploymentAllowed"
+
+	// GuardrailsViolatedCondition is True when the Spec violates the Guardrails configured for
+	// the controller. Nothing is deployed till the Spec is fixed.
+	GuardrailsViolatedCondition = "GuardrailsViolated"
+
+	// GuardrailsViolationReason is the GuardrailsViolatedCondition reason when the Spec violates
+	// the Guardrails
+	GuardrailsViolationReason = "GuardrailsViolation"
+
+	// WithinGuardrailsReason is the GuardrailsViolatedCondition reason when the Spec does not
+	// violate the Guardrails
+	WithinGuardrailsReason = "WithinGuardrails"
 )
 
-// +kubebuilder:validation:Enum:=Resources;Helm;Kustomize
+// +kubebuilder:validation:Enum:=Resources;Helm;Kustomize;Jobs;Extensions
 type FeatureID string
 
 const (
@@ -50,6 +119,13 @@ const (
 
 	// FeatureKustomize is the identifier for Kustomize feature
 	FeatureKustomize = FeatureID("Kustomize")
+
+	// FeatureJobs is the identifier for Jobs feature
+	FeatureJobs = FeatureID("Jobs")
+
+	// FeatureExtensions is the identifier for Extensions feature.
+	// Extensions are deployed by out-of-tree plugins
+	FeatureExtensions = FeatureID("Extensions")
 )
 
 // +kubebuilder:validation:Enum:=Provisioning;Provisioned;Failed;FailedNonRetriable;Removing;Removed
@@ -113,6 +189,16 @@ type FeatureSummary struct {
 	// LastAppliedTime is the time feature was last reconciled
 	// +optional
 	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// ConsecutiveFailures is the number of consecutive failed deployments of
+	// this feature. It is reset once feature is successfully deployed.
+	// +optional
+	ConsecutiveFailures uint32 `json:"consecutiveFailures,omitempty"`
+
+	// PendingResources is, when a WriteBudget is set and resources are being applied in chunks,
+	// the number of resources still to be applied to the managed cluster.
+	// +optional
+	PendingResources *int32 `json:"pendingResources,omitempty"`
 }
 
 type FeatureDeploymentInfo struct {
@@ -161,6 +247,44 @@ type HelmChartSummary struct {
 	// chart or there is a conflict
 	// +optional
 	ConflictMessage string `json:"conflictMessage,omitempty"`
+
+	// ResolvedVersion is the chart version deployed according to the helm chart
+	// VersionPolicy or pinned by the chart versions ConfigMap the profile references.
+	// Only set when VersionPolicy is SemverRange or Latest, or the version is pinned.
+	// +optional
+	ResolvedVersion string `json:"resolvedVersion,omitempty"`
+
+	// PendingUpgradeVersion is a newer chart version, matching the helm chart
+	// VersionPolicy, waiting for manual approval (UpgradeMode Manual). Upgrade is
+	// approved by setting ChartVersion to this version.
+	// +optional
+	PendingUpgradeVersion string `json:"pendingUpgradeVersion,omitempty"`
+
+	// Storage is where helm stores release information. Recorded so the release
+	// can still be uninstalled once the helm chart is not referenced anymore.
+	// +optional
+	Storage *HelmStorage `json:"storage,omitempty"`
+
+	// RollbackMessage reports why the last upgrade of the helm release failed and was
+	// rolled back to the last successful revision (HelmOptions.Atomic). Cleared once the
+	// helm release is successfully deployed.
+	// +optional
+	RollbackMessage string `json:"rollbackMessage,omitempty"`
+
+	// ValuesDiff is the diff, with sensitive values redacted, between the values the helm
+	// release was deployed with before and after the last upgrade changing them.
+	// +optional
+	ValuesDiff string `json:"valuesDiff,omitempty"`
+
+	// DeployedVersion is the chart version the helm release is currently deployed with.
+	// Empty if the helm release is not deployed.
+	// +optional
+	DeployedVersion string `json:"deployedVersion,omitempty"`
+
+	// FailureMessage reports why the last deployment of the helm release failed.
+	// Cleared once the helm release is successfully deployed.
+	// +optional
+	FailureMessage string `json:"failureMessage,omitempty"`
 }
 
 // ClusterSummarySpec defines the desired state of ClusterSummary
@@ -180,6 +304,33 @@ type ClusterSummarySpec struct {
 	ClusterProfileSpec Spec `json:"clusterProfileSpec,omitempty"`
 }
 
+// MissingReference is a ConfigMap/Secret referenced by a ClusterSummary which does not exist
+type MissingReference struct {
+	// FeatureID is the feature referencing the resource
+	FeatureID FeatureID `json:"featureID"`
+
+	// Kind of the referenced resource (ConfigMap or Secret)
+	Kind string `json:"kind"`
+
+	// Namespace of the referenced resource
+	Namespace string `json:"namespace"`
+
+	// Name of the referenced resource
+	Name string `json:"name"`
+}
+
+// TierStatus reports the deployment progress of the ClusterSummaries in a Tier
+type TierStatus struct {
+	// Tier of the ClusterSummaries
+	Tier int32 `json:"tier"`
+
+	// ClusterSummaries is the number of ClusterSummaries, for the same cluster, in this Tier
+	ClusterSummaries int32 `json:"clusterSummaries"`
+
+	// Provisioned is the number of ClusterSummaries, in this Tier, with all features provisioned
+	Provisioned int32 `json:"provisioned"`
+}
+
 // ClusterSummaryStatus defines the observed state of ClusterSummary
 type ClusterSummaryStatus struct {
 	// Dependencies is a summary reporting the status of the dependencies
@@ -205,13 +356,145 @@ type ClusterSummaryStatus struct {
 	// +listType=atomic
 	// +optional
 	HelmReleaseSummaries []HelmChartSummary `json:"helmReleaseSummaries,omitempty"`
+
+	// MissingReferences reports the ConfigMaps/Secrets referenced by this ClusterSummary
+	// which do not exist in the management cluster. Deployment of the features consuming
+	// those automatically resumes as soon as the referenced resources are created.
+	// +listType=atomic
+	// +optional
+	MissingReferences []MissingReference `json:"missingReferences,omitempty"`
+
+	// LastSuccessfulSyncTime is the last time all features of this ClusterSummary
+	// were successfully deployed in the managed cluster
+	// +optional
+	LastSuccessfulSyncTime *metav1.Time `json:"lastSuccessfulSyncTime,omitempty"`
+
+	// Conditions reports the ClusterSummary conditions
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// TierStatuses reports, when tier ordered deployment is enabled, the deployment progress
+	// in each Tier of all ClusterSummaries for the same cluster. Features of a ClusterSummary
+	// are deployed the first time only once all ClusterSummaries in lower Tiers are provisioned.
+	// +listType=map
+	// +listMapKey=tier
+	// +optional
+	TierStatuses []TierStatus `json:"tierStatuses,omitempty"`
+
+	// Ready is true when all features of this ClusterSummary are provisioned
+	// in the managed cluster
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// FailedFeature is the first feature, if any, which failed to be deployed
+	// +optional
+	FailedFeature FeatureID `json:"failedFeature,omitempty"`
+
+	// CurrentRevision is the ClusterSummary generation whose features were last
+	// all successfully deployed in the managed cluster
+	// +optional
+	CurrentRevision int64 `json:"currentRevision,omitempty"`
+
+	// DeploymentOutcomes contains the outcome of the most recent deployments, within
+	// the ErrorBudget window. Only tracked when ErrorBudget is set.
+	// +listType=atomic
+	// +optional
+	DeploymentOutcomes []DeploymentOutcome `json:"deploymentOutcomes,omitempty"`
+
+	// ResolvedImages reports, when ImageDigestResolution is set, the digest each image
+	// deployed in the managed cluster was last resolved to
+	// +listType=atomic
+	// +optional
+	ResolvedImages []ResolvedImage `json:"resolvedImages,omitempty"`
+
+	// Conflicts lists the resources and helm releases this ClusterSummary cannot deploy
+	// because a different ClusterProfile/Profile is managing them
+	// +listType=atomic
+	// +optional
+	Conflicts []ConflictDetail `json:"conflicts,omitempty"`
+}
+
+// DeploymentOutcome is the outcome of a feature deployment in a managed cluster
+type DeploymentOutcome struct {
+	// FeatureID is the feature which was deployed
+	FeatureID FeatureID `json:"featureID"`
+
+	// Time is when the outcome of the deployment was known
+	Time metav1.Time `json:"time"`
+
+	// Succeeded is true when the deployment succeeded
+	Succeeded bool `json:"succeeded"`
+}
+
+// ResolvedImage is an image whose tag was resolved to a digest before being deployed
+type ResolvedImage struct {
+	// FeatureID is the feature which deployed the image
+	FeatureID FeatureID `json:"featureID"`
+
+	// Image is the image as referenced by the rendered resources
+	Image string `json:"image"`
+
+	// Digest is the digest the image tag was resolved to
+	Digest string `json:"digest"`
+}
+
+// ConflictDetail describes a resource or helm release a ClusterSummary cannot deploy, along
+// with the ClusterProfile/Profile currently managing it
+type ConflictDetail struct {
+	// FeatureID is the feature trying to deploy the contested resource
+	FeatureID FeatureID `json:"featureID"`
+
+	// Kind of the contested resource. HelmRelease for helm releases.
+	Kind string `json:"kind"`
+
+	// Group of the contested resource. Empty for the core group and for helm releases.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Namespace of the contested resource. Empty for resources scoped at cluster level.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the contested resource
+	Name string `json:"name"`
+
+	// OwnerKind is the kind (ClusterProfile or Profile) of the profile managing the contested resource
+	// +optional
+	OwnerKind string `json:"ownerKind,omitempty"`
+
+	// OwnerNamespace is the namespace of the Profile managing the contested resource.
+	// Empty for ClusterProfiles.
+	// +optional
+	OwnerNamespace string `json:"ownerNamespace,omitempty"`
+
+	// OwnerName is the name of the profile managing the contested resource
+	// +optional
+	OwnerName string `json:"ownerName,omitempty"`
+
+	// OwnerTier is the tier of the profile managing the contested resource
+	// +optional
+	OwnerTier int32 `json:"ownerTier,omitempty"`
+
+	// Message contains details about the conflict
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 //nolint: lll // marker
+// +genclient
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=clustersummaries,scope=Namespaced
+// +kubebuilder:resource:path=clustersummaries,scope=Namespaced,categories=sveltos
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Indicates whether all features are provisioned"
+// +kubebuilder:printcolumn:name="FailedFeature",type="string",JSONPath=".status.failedFeature",description="First feature which failed to be deployed"
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".status.currentRevision",description="Generation last successfully deployed"
+// +kubebuilder:printcolumn:name="LastSync",type="date",JSONPath=".status.lastSuccessfulSyncTime",description="Last time all features were successfully deployed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Releases",type="string",JSONPath=".status.helmReleaseSummaries[*].releaseName",description="Helm releases managed",priority=1
+// +kubebuilder:printcolumn:name="ReleaseStatus",type="string",JSONPath=".status.helmReleaseSummaries[*].status",description="Whether each Helm release is managed or in conflict",priority=1
 // +kubebuilder:printcolumn:name="HelmCharts",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Helm\")].status",description="Indicates whether HelmCharts are all provisioned",priority=2
 // +kubebuilder:printcolumn:name="KustomizeRefs",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Kustomize\")].status",description="Indicates whether KustomizeRefs are all provisioned",priority=2
 // +kubebuilder:printcolumn:name="PolicyRefs",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Resources\")].status",description="Indicates whether PolicyRefs are all provisioned",priority=2
@@ -0,0 +1,129 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// CompressedPayloadPrefix marks a status field whose content is gzipped and base64 encoded.
+	// The version part allows changing the encoding in the future.
+	CompressedPayloadPrefix = "sveltos.gzip.v1:"
+)
+
+// IsCompressedPayload returns true if payload was produced by CompressPayload
+func IsCompressedPayload(payload string) bool {
+	return strings.HasPrefix(payload, CompressedPayloadPrefix)
+}
+
+// CompressPayload gzips and base64 encodes data. Result is prefixed with CompressedPayloadPrefix
+func CompressPayload(data []byte) (string, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return CompressedPayloadPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecompressPayload reverts CompressPayload. A payload without CompressedPayloadPrefix is
+// returned as it is.
+func DecompressPayload(payload string) ([]byte, error) {
+	if !IsCompressedPayload(payload) {
+		return []byte(payload), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(payload, CompressedPayloadPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode compressed payload: %w", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// GetMessage returns the ResourceReport message, decompressing it if needed
+func (r *ResourceReport) GetMessage() (string, error) {
+	message, err := DecompressPayload(r.Message)
+	if err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
+
+// GetMessage returns the ReleaseReport message, decompressing it if needed
+func (r *ReleaseReport) GetMessage() (string, error) {
+	message, err := DecompressPayload(r.Message)
+	if err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
+
+// GetResources returns the resources deployed because of this feature, independently
+// of those being stored in Resources or CompressedResources
+func (f *Feature) GetResources() ([]Resource, error) {
+	if f.CompressedResources == "" {
+		return f.Resources, nil
+	}
+
+	data, err := DecompressPayload(f.CompressedResources)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compressed resources: %w", err)
+	}
+	return resources, nil
+}
+
+// GetNotes returns the rendered NOTES.txt of the release, decompressing it if needed
+func (c *Chart) GetNotes() (string, error) {
+	notes, err := DecompressPayload(c.Notes)
+	if err != nil {
+		return "", err
+	}
+	return string(notes), nil
+}
+
+// GetComputedValues returns the values, in YAML format, the release was deployed with,
+// decompressing them if needed
+func (c *Chart) GetComputedValues() (string, error) {
+	values, err := DecompressPayload(c.ComputedValues)
+	if err != nil {
+		return "", err
+	}
+	return string(values), nil
+}
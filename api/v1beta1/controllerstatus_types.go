@@ -0,0 +1,109 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ControllerStatusKind = "ControllerStatus"
+
+	// DefaultControllerStatusName is the name of the ControllerStatus of the
+	// addon-controller instance started without a shard key
+	DefaultControllerStatusName = "default"
+)
+
+// ClusterBacklog is the number of deployment requests pending for a cluster
+type ClusterBacklog struct {
+	// Cluster is the managed cluster
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// PendingRequests is the number of deployment requests for the cluster
+	// not processed yet
+	PendingRequests int32 `json:"pendingRequests"`
+}
+
+// WatcherStatus reports the health of a watcher started by the controller
+type WatcherStatus struct {
+	// GroupVersionKind is the type of resources watched
+	GroupVersionKind string `json:"groupVersionKind"`
+
+	// Synced is true once the watcher has completed its initial listing
+	Synced bool `json:"synced"`
+}
+
+// ControllerStatusStatus defines the observed state of ControllerStatus
+type ControllerStatusStatus struct {
+	// QueueDepth is the number of deployment requests not processed yet
+	// +optional
+	QueueDepth int32 `json:"queueDepth,omitempty"`
+
+	// OldestPendingRequestAge is the age of the oldest deployment request
+	// not processed yet
+	// +optional
+	OldestPendingRequestAge *metav1.Duration `json:"oldestPendingRequestAge,omitempty"`
+
+	// ClusterBacklogs lists, starting from the one with the most pending requests,
+	// the clusters with deployment requests not processed yet.
+	// Only the first 100 clusters are reported.
+	// +listType=atomic
+	// +optional
+	ClusterBacklogs []ClusterBacklog `json:"clusterBacklogs,omitempty"`
+
+	// Watchers reports the health of the watchers started to track resources
+	// in the management cluster
+	// +listType=atomic
+	// +optional
+	Watchers []WatcherStatus `json:"watchers,omitempty"`
+
+	// LastUpdateTime is the last time this status was updated
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+//nolint: lll // marker
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=controllerstatuses,scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Queue",type="integer",JSONPath=".status.queueDepth",description="Deployment requests not processed yet"
+// +kubebuilder:printcolumn:name="Oldest",type="string",JSONPath=".status.oldestPendingRequestAge",description="Age of the oldest pending deployment request"
+// +kubebuilder:printcolumn:name="Updated",type="date",JSONPath=".status.lastUpdateTime",description="Last time status was updated"
+
+// ControllerStatus reports the load of an addon-controller instance. There is one
+// ControllerStatus per shard, named after the shard key (default when no shard key
+// is set). ControllerStatuses are periodically updated by the addon-controller.
+type ControllerStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ControllerStatusStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ControllerStatusList contains a list of ControllerStatus
+type ControllerStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControllerStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControllerStatus{}, &ControllerStatusList{})
+}
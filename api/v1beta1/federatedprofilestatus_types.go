@@ -0,0 +1,120 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	FederatedProfileStatusKind = "FederatedProfileStatus"
+
+	// FederationSourceLabel is set on FederatedProfileStatuses to the name of the
+	// management cluster which published them
+	FederationSourceLabel = "projectsveltos.io/federation-source"
+)
+
+// FederatedFeatureStatus is the status of a feature in a managed cluster
+type FederatedFeatureStatus struct {
+	// FeatureID is an indentifier of the feature whose status is reported
+	FeatureID FeatureID `json:"featureID"`
+
+	// Status represents the state of the feature in the managed cluster
+	// +optional
+	Status FeatureStatus `json:"status,omitempty"`
+
+	// FailureMessage provides more information about the error.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// FederatedClusterStatus is the rollout status of a profile in a matching cluster
+type FederatedClusterStatus struct {
+	// Cluster is the matching cluster
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// FeatureSummaries reports the status of each feature in the cluster.
+	// Empty if the profile has not been processed for this cluster yet.
+	// +listType=map
+	// +listMapKey=featureID
+	// +optional
+	FeatureSummaries []FederatedFeatureStatus `json:"featureSummaries,omitempty"`
+
+	// LastSuccessfulSyncTime is the last time all features were successfully
+	// deployed in the cluster
+	// +optional
+	LastSuccessfulSyncTime *metav1.Time `json:"lastSuccessfulSyncTime,omitempty"`
+}
+
+// FederatedProfileStatusSpec defines the state of a profile as published by
+// a peer management cluster
+type FederatedProfileStatusSpec struct {
+	// ManagementCluster is the name of the management cluster the profile is defined in
+	ManagementCluster string `json:"managementCluster"`
+
+	// ProfileKind is the kind of the profile (ClusterProfile or Profile)
+	// +kubebuilder:validation:Enum=ClusterProfile;Profile
+	ProfileKind string `json:"profileKind"`
+
+	// ProfileNamespace is the namespace of the profile. Empty for ClusterProfiles.
+	// +optional
+	ProfileNamespace string `json:"profileNamespace,omitempty"`
+
+	// ProfileName is the name of the profile
+	ProfileName string `json:"profileName"`
+
+	// Clusters reports the clusters matching the profile along with the
+	// rollout status in each of them
+	// +listType=atomic
+	// +optional
+	Clusters []FederatedClusterStatus `json:"clusters,omitempty"`
+
+	// LastPublishedTime is the last time this information was published
+	LastPublishedTime metav1.Time `json:"lastPublishedTime"`
+}
+
+//nolint: lll // marker
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=federatedprofilestatuses,scope=Cluster
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.managementCluster",description="Management cluster the profile is defined in"
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.profileKind",description="Kind of the profile"
+// +kubebuilder:printcolumn:name="Published",type="date",JSONPath=".spec.lastPublishedTime",description="Last time status was published"
+
+// FederatedProfileStatus reports, in a peer management cluster, the clusters matching
+// a profile of another management cluster and the rollout status in each of them.
+// FederatedProfileStatuses are created and kept up to date by the addon-controller of
+// the management cluster the profile is defined in.
+type FederatedProfileStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FederatedProfileStatusSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FederatedProfileStatusList contains a list of FederatedProfileStatus
+type FederatedProfileStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FederatedProfileStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FederatedProfileStatus{}, &FederatedProfileStatusList{})
+}
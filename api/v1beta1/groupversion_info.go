@@ -28,9 +28,19 @@ var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "config.projectsveltos.io", Version: "v1beta1"}
 
+	// SchemeGroupVersion is an alias of GroupVersion used by the generated clientset
+	SchemeGroupVersion = GroupVersion
+
 	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
 	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
 
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
 )
+
+// GroupResource takes an unqualified resource and returns a Group qualified GroupResource.
+// This package has a Resource type, so this is the function generated listers call in place
+// of the conventional Resource function (see hack/tools/lister-gen).
+func GroupResource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
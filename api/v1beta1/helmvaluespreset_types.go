@@ -0,0 +1,74 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	HelmValuesPresetKind = "HelmValuesPreset"
+)
+
+// HelmValuesPresetVersion is a version of a helm values fragment
+type HelmValuesPresetVersion struct {
+	// Version identifies this version of the values fragment
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+
+	// Values is the helm values fragment.
+	// These values can be static or leverage Go templates for dynamic customization,
+	// same as HelmChart Values.
+	// +kubebuilder:validation:MinLength=1
+	Values string `json:"values"`
+}
+
+// HelmValuesPresetSpec defines the desired state of HelmValuesPreset
+type HelmValuesPresetSpec struct {
+	// Versions lists all the versions of the values fragment. HelmCharts referencing
+	// this preset without a version get the last one.
+	// +listType=map
+	// +listMapKey=version
+	// +kubebuilder:validation:MinItems=1
+	Versions []HelmValuesPresetVersion `json:"versions"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=helmvaluespresets,scope=Cluster
+
+// HelmValuesPreset is a named, versioned, helm values fragment HelmCharts of any
+// ClusterProfile/Profile can reference. Meant for values common to the whole organization
+// (resources, securityContext, network policies) which are then defined once.
+type HelmValuesPreset struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HelmValuesPresetSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HelmValuesPresetList contains a list of HelmValuesPreset
+type HelmValuesPresetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HelmValuesPreset `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HelmValuesPreset{}, &HelmValuesPresetList{})
+}
@@ -28,10 +28,15 @@ const (
 	ProfileKind = "Profile"
 )
 
+//nolint: lll // marker
+// +genclient
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=profiles,scope=Namespaced
+// +kubebuilder:resource:path=profiles,scope=Namespaced,categories=sveltos
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.readyClusters",description="Matching clusters with all features provisioned"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedClusters",description="Matching clusters with at least one feature failed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Profile is the Schema for the profiles API
 type Profile struct {
@@ -0,0 +1,113 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	PromotionKind = "Promotion"
+
+	// PromotionLabel is set, to the Promotion name, on the ClusterProfiles and on the
+	// ConfigMaps/Secrets generated for a Promotion
+	PromotionLabel = "projectsveltos.io/promotion"
+
+	// PromotionRevisionLabel is set, to the revision, on the ConfigMaps/Secrets generated
+	// for a Promotion revision
+	PromotionRevisionLabel = "projectsveltos.io/promotion-revision"
+)
+
+// PromotionStage is a group of clusters a revision is deployed to
+type PromotionStage struct {
+	// ClusterSelector identifies the clusters of the stage
+	ClusterSelector libsveltosv1beta1.Selector `json:"clusterSelector"`
+}
+
+// PromotionSpec defines the desired state of Promotion
+type PromotionSpec struct {
+	// ClusterProfileName is the name of the ClusterProfile whose Spec is promoted.
+	// Its ClusterSelector, ClusterRefs and SetRefs are ignored: such ClusterProfile is
+	// expected not to match any cluster itself.
+	// ConfigMaps/Secrets it references must have a namespace and a non templated name.
+	// +kubebuilder:validation:MinLength=1
+	ClusterProfileName string `json:"clusterProfileName"`
+
+	// Staging is the group of clusters every revision of the ClusterProfile is first deployed to
+	Staging PromotionStage `json:"staging"`
+
+	// Production is the group of clusters promoted revisions are deployed to
+	Production PromotionStage `json:"production"`
+
+	// PromotedRevision is the revision deployed to the Production clusters. Setting it to
+	// Status.StagedRevision promotes the revision currently deployed to the Staging clusters.
+	// When not set, nothing is deployed to the Production clusters.
+	// +optional
+	PromotedRevision string `json:"promotedRevision,omitempty"`
+}
+
+// PromotionStatus defines the observed state of Promotion
+type PromotionStatus struct {
+	// StagedRevision is the revision deployed to the Staging clusters
+	// +optional
+	StagedRevision string `json:"stagedRevision,omitempty"`
+
+	// ProductionRevision is the revision deployed to the Production clusters
+	// +optional
+	ProductionRevision string `json:"productionRevision,omitempty"`
+
+	// FailureMessage provides more information if an error occurs.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+//nolint: lll // marker
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=promotions,scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ClusterProfile",type="string",JSONPath=".spec.clusterProfileName",description="ClusterProfile being promoted"
+// +kubebuilder:printcolumn:name="Staged",type="string",JSONPath=".status.stagedRevision",description="Revision deployed to the Staging clusters"
+// +kubebuilder:printcolumn:name="Production",type="string",JSONPath=".status.productionRevision",description="Revision deployed to the Production clusters"
+
+// Promotion deploys every revision of a ClusterProfile to a group of staging clusters
+// first. A revision is deployed to the production clusters only once promoted.
+// A revision is a snapshot of the ClusterProfile Spec along with the content of the
+// ConfigMaps/Secrets it references and with helm chart versions pinned. Promoting copies
+// the very same snapshot, which is never rendered again, so staging and production
+// clusters receive byte-identical configuration.
+type Promotion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PromotionSpec   `json:"spec,omitempty"`
+	Status PromotionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PromotionList contains a list of Promotion
+type PromotionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Promotion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Promotion{}, &PromotionList{})
+}
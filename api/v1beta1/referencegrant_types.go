@@ -0,0 +1,85 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReferenceGrantKind = "ReferenceGrant"
+)
+
+// ReferenceGrantFrom identifies the Profiles allowed to reference resources
+type ReferenceGrantFrom struct {
+	// Namespace of the Profiles allowed to reference resources in the
+	// ReferenceGrant namespace
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantTo identifies the resources which can be referenced
+type ReferenceGrantTo struct {
+	// Kind of the resources which can be referenced
+	// +kubebuilder:validation:Enum=ConfigMap;Secret;GitRepository;OCIRepository;Bucket;HelmRepository
+	Kind string `json:"kind"`
+
+	// Name of the resource which can be referenced. When not set, all
+	// resources of this Kind in the ReferenceGrant namespace can be referenced.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ReferenceGrantSpec defines the desired state of ReferenceGrant
+type ReferenceGrantSpec struct {
+	// From lists the namespaces whose Profiles can reference resources
+	// in the ReferenceGrant namespace
+	// +kubebuilder:validation:MinItems=1
+	From []ReferenceGrantFrom `json:"from"`
+
+	// To lists the resources, in the ReferenceGrant namespace, which
+	// can be referenced
+	// +kubebuilder:validation:MinItems=1
+	To []ReferenceGrantTo `json:"to"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=referencegrants,scope=Namespaced
+
+// ReferenceGrant allows Profiles in other namespaces to reference resources (ConfigMaps, Secrets
+// and Flux sources) in the namespace the ReferenceGrant is created in.
+// Profiles can always reference resources in their own namespace. Any reference to a
+// resource in a different namespace requires a ReferenceGrant in the target namespace.
+type ReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReferenceGrantList contains a list of ReferenceGrant
+type ReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReferenceGrant{}, &ReferenceGrantList{})
+}
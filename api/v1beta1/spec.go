@@ -123,7 +123,8 @@ type ValueFrom struct {
 	// Namespace of the referenced resource.
 	// For ClusterProfile namespace can be left empty. In such a case, namespace will
 	// be implicit set to cluster's namespace.
-	// For Profile namespace must be left empty. The Profile namespace will be used.
+	// For Profile namespace can be left empty. In such a case, the Profile namespace will be used.
+	// Referencing a resource in a different namespace requires a ReferenceGrant in that namespace.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
@@ -139,12 +140,27 @@ type ValueFrom struct {
 	// - ConfigMap/Secret
 	// +kubebuilder:validation:Enum=ConfigMap;Secret
 	Kind string `json:"kind"`
+
+	// Key selects a single key within the ConfigMap/Secret data. If not set, all keys are used.
+	// This allows a ConfigMap/Secret holding several values files (for instance values.yaml and
+	// values-prod.yaml) to be referenced multiple times, each entry selecting a key, in the order
+	// those need to be merged.
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Optional, when set, tolerates a missing ConfigMap/Secret or a missing Key: this entry is
+	// then skipped. Otherwise deployment fails.
+	// +kubebuilder:default:=false
+	// +optional
+	Optional bool `json:"optional,omitempty"`
 }
 
 type RegistryCredentialsConfig struct {
 	// CredentialsSecretRef references a secret containing credentials
 	// For ClusterProfile namespace can be left empty. In such a case, namespace will
 	// be implicit set to cluster's namespace.
+	// For HTTP helm repositories, the secret must contain the username and password
+	// keys, used for basic authentication.
 	// +optional
 	CredentialsSecretRef *corev1.SecretReference `json:"credentials,omitempty"`
 
@@ -160,6 +176,14 @@ type RegistryCredentialsConfig struct {
 	// +optional
 	CASecretRef *corev1.SecretReference `json:"ca,omitempty"`
 
+	// CertSecretRef references a secret containing the TLS client certificate and key
+	// used to authenticate to HTTP helm repositories.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// keys: tls.crt, tls.key
+	// +optional
+	CertSecretRef *corev1.SecretReference `json:"cert,omitempty"`
+
 	// InsecureSkipTLSVerify controls server certificate verification.
 	// +optional
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
@@ -181,6 +205,102 @@ const (
 	HelmChartActionUninstall = HelmChartAction("Uninstall")
 )
 
+// ChartVerificationProvider specifies the method used to verify a helm chart
+// +kubebuilder:validation:Enum:=cosign;gpg
+type ChartVerificationProvider string
+
+const (
+	// ChartVerificationProviderCosign verifies the cosign signature of a chart stored in an OCI registry
+	ChartVerificationProviderCosign = ChartVerificationProvider("cosign")
+
+	// ChartVerificationProviderGPG verifies the provenance file (.prov) of a chart stored in an HTTP repository
+	ChartVerificationProviderGPG = ChartVerificationProvider("gpg")
+)
+
+// VersionPolicyType specifies how the helm chart version to deploy is selected
+// +kubebuilder:validation:Enum:=Exact;SemverRange;Latest
+type VersionPolicyType string
+
+const (
+	// VersionPolicyTypeExact deploys exactly the version specified in ChartVersion
+	VersionPolicyTypeExact = VersionPolicyType("Exact")
+
+	// VersionPolicyTypeSemverRange deploys the highest version available in the
+	// repository matching the semver range in Constraint
+	VersionPolicyTypeSemverRange = VersionPolicyType("SemverRange")
+
+	// VersionPolicyTypeLatest deploys the highest stable version available in the repository
+	VersionPolicyTypeLatest = VersionPolicyType("Latest")
+)
+
+// VersionUpgradeMode specifies what happens when a newer version matching
+// the VersionPolicy is found in the repository
+// +kubebuilder:validation:Enum:=Auto;Manual
+type VersionUpgradeMode string
+
+const (
+	// VersionUpgradeModeAuto upgrades the helm release to the newer version
+	VersionUpgradeModeAuto = VersionUpgradeMode("Auto")
+
+	// VersionUpgradeModeManual keeps deploying ChartVersion and only reports the
+	// newer version in the ClusterSummary status (PendingUpgradeVersion).
+	// Upgrade is approved by updating ChartVersion.
+	VersionUpgradeModeManual = VersionUpgradeMode("Manual")
+)
+
+type VersionPolicy struct {
+	// Type indicates how the chart version to deploy is selected.
+	// +kubebuilder:default:=Exact
+	// +optional
+	Type VersionPolicyType `json:"type,omitempty"`
+
+	// Constraint is a semver range (for instance ">=1.2 <2.0").
+	// Only used when Type is SemverRange.
+	// +optional
+	Constraint string `json:"constraint,omitempty"`
+
+	// UpgradeMode indicates whether a newer version matching the policy is
+	// automatically deployed or needs to be manually approved.
+	// +kubebuilder:default:=Auto
+	// +optional
+	UpgradeMode VersionUpgradeMode `json:"upgradeMode,omitempty"`
+}
+
+// HelmStorageDriver is the helm storage backend release information is stored in
+// +kubebuilder:validation:Enum:=secret;configmap;sql
+type HelmStorageDriver string
+
+const (
+	// HelmStorageDriverSecret stores release information in Secrets
+	HelmStorageDriverSecret = HelmStorageDriver("secret")
+
+	// HelmStorageDriverConfigMap stores release information in ConfigMaps
+	HelmStorageDriverConfigMap = HelmStorageDriver("configmap")
+
+	// HelmStorageDriverSQL stores release information in a SQL database
+	HelmStorageDriverSQL = HelmStorageDriver("sql")
+)
+
+// HelmStorage configures where helm stores release information in the managed cluster
+type HelmStorage struct {
+	// Driver is the helm storage backend. Defaults to secret.
+	// +optional
+	Driver HelmStorageDriver `json:"driver,omitempty"`
+
+	// Namespace is the namespace release information is stored in.
+	// Defaults to the release namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SQLConnectionSecretRef references a Secret, in the management cluster, containing
+	// the SQL connection string. Required when Driver is sql.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// key: connectionString
+	// +optional
+	SQLConnectionSecretRef *corev1.SecretReference `json:"sqlConnectionSecretRef,omitempty"`
+}
+
 type HelmOptions struct {
 	// SkipCRDs controls whether CRDs should be installed during install/upgrade operation.
 	// By default, CRDs are installed if not already present.
@@ -230,7 +350,8 @@ type HelmOptions struct {
 	// +optional
 	Atomic bool `json:"atomic,omitempty"`
 
-	// update dependencies if they are missing before installing the chart
+	// update dependencies, ignoring Chart.lock, when they are missing before installing/upgrading the chart.
+	// Missing dependencies are always downloaded, from Chart.lock when present.
 	// Default to false
 	// +kubebuilder:default:=false
 	// +optional
@@ -249,6 +370,32 @@ type HelmOptions struct {
 	// +optional
 	Description string `json:"description,omitempty"`
 
+	// RunHelmTests, if set, runs the chart tests (helm test) after each install/upgrade.
+	// The helm feature is marked as provisioned only once all tests pass. Tests are
+	// retried until they succeed. Logs of failing tests are reported in the status.
+	// +kubebuilder:default:=false
+	// +optional
+	RunHelmTests bool `json:"runHelmTests,omitempty"`
+
+	// HelmTestsTimeout is the time each chart test is given to complete. Only used when
+	// RunHelmTests is set. Defaults to Timeout or, if Timeout is not set either, to 5m.
+	// +optional
+	HelmTestsTimeout *metav1.Duration `json:"helmTestsTimeout,omitempty"`
+
+	// RollbackOnHelmTestsFailure, if set, rolls the release back to its previous revision when
+	// chart tests fail after an upgrade. Tests are then not retried: the helm feature is marked
+	// as failed till the helm chart configuration changes. Only used when RunHelmTests is set.
+	// +kubebuilder:default:=false
+	// +optional
+	RollbackOnHelmTestsFailure bool `json:"rollbackOnHelmTestsFailure,omitempty"`
+
+	// Storage configures where helm stores release information. By default release
+	// information is stored in Secrets in the release namespace.
+	// Changing it for an installed release is not supported: the release would be
+	// installed again.
+	// +optional
+	Storage *HelmStorage `json:"storage,omitempty"`
+
 	// HelmInstallOptions are options specific to helm install
 	// +optional
 	InstallOptions HelmInstallOptions `json:"installOptions,omitempty"`
@@ -352,7 +499,9 @@ type HelmUninstallOptions struct {
 	// +optional
 	KeepHistory bool `json:"keepHistory,omitempty"`
 
-	// DeletionPropagation
+	// DeletionPropagation is the cascade policy used to delete the release resources:
+	// background (default), foreground (resources are deleted only once their dependents are)
+	// or orphan (dependents, for instance Pods of a Deployment, are left behind)
 	// +kubebuilder:validation:Enum:=orphan;foreground;background
 	// +optional
 	DeletionPropagation string `json:"deletionPropagation,omitempty"`
@@ -364,12 +513,34 @@ type HelmUninstallOptions struct {
 	// +kubebuilder:default:=false
 	// +optional
 	DisableHooks bool `json:"disableHooks,omitempty"`
+
+	// Wait, if set, overrides Wait in HelmOptions for uninstall. When true, uninstall waits for
+	// all the release resources to be deleted, up to the uninstall timeout. Set it to false for
+	// charts with PVCs or finalizers which can block deletion, so uninstall does not wait on them.
+	// +optional
+	Wait *bool `json:"wait,omitempty"`
+
+	// Timeout, if set, overrides Timeout in HelmOptions for uninstall
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="has(self.repositoryURL) != has(self.sourceRef)",message="exactly one of repositoryURL and sourceRef must be set"
 type HelmChart struct {
-	// RepositoryURL is the URL helm chart repository
+	// RepositoryURL is the URL helm chart repository.
+	// Exactly one of RepositoryURL and SourceRef must be set.
 	// +kubebuilder:validation:MinLength=1
-	RepositoryURL string `json:"repositoryURL"`
+	// +optional
+	RepositoryURL string `json:"repositoryURL,omitempty"`
+
+	// SourceRef references the Flux source (HelmRepository, GitRepository, OCIRepository or Bucket)
+	// containing the chart, as an alternative to RepositoryURL.
+	// For a HelmRepository, URL and credentials of the HelmRepository are used (unless
+	// RegistryCredentialsConfig is set) and ChartName follows the same rules as with RepositoryURL.
+	// For a GitRepository/OCIRepository/Bucket, the chart is taken from the artifact fetched by Flux at
+	// SourceRef Path and ChartVersion must match the version in the chart Chart.yaml.
+	// +optional
+	SourceRef *ChartSourceRef `json:"sourceRef,omitempty"`
 
 	// RepositoryName is the name helm chart repository
 	// +kubebuilder:validation:MinLength=1
@@ -379,15 +550,27 @@ type HelmChart struct {
 	// +kubebuilder:validation:MinLength=1
 	ChartName string `json:"chartName"`
 
-	// ChartVersion is the chart version
+	// ChartVersion is the chart version. It must be an exact version: semver ranges
+	// (for instance ">=1.4.0 <2.0.0") are expressed with a SemverRange VersionPolicy.
+	// When VersionPolicy is set to SemverRange or Latest with UpgradeMode Auto, the version
+	// deployed is the one resolved from the repository index and ChartVersion is ignored.
 	// +kubebuilder:validation:MinLength=1
 	ChartVersion string `json:"chartVersion"`
 
+	// VersionPolicy, when set, allows the chart version to be discovered from the repository.
+	// Repository is periodically polled for newer versions matching the policy.
+	// +optional
+	VersionPolicy *VersionPolicy `json:"versionPolicy,omitempty"`
+
 	// ReleaseName is the chart release
+	// It can be expressed as a Go template, filled in using the Cluster the chart is deployed
+	// to (e.g. {{ .Cluster.metadata.labels.env }}-ingress).
 	// +kubebuilder:validation:MinLength=1
 	ReleaseName string `json:"releaseName"`
 
 	// ReleaseNamespace is the namespace release will be installed
+	// It can be expressed as a Go template, filled in using the Cluster the chart is deployed
+	// to (e.g. ingress-{{ .Cluster.metadata.labels.env }}).
 	// +kubebuilder:validation:MinLength=1
 	ReleaseNamespace string `json:"releaseNamespace"`
 
@@ -403,9 +586,21 @@ type HelmChart struct {
 	// These values can be static or leverage Go templates for dynamic customization.
 	// When expressed as templates, the values are filled in using information from
 	// resources within the management cluster before deployment (Cluster and TemplateResourceRefs)
+	// ValuesFrom are merged over Values in the order they are listed, each overriding the
+	// previous ones. Keys within a ConfigMap/Secret are merged in alphabetical order.
 	// +optional
 	ValuesFrom []ValueFrom `json:"valuesFrom,omitempty"`
 
+	// ValuesPresets references HelmValuesPresets. Presets are merged in order, each overriding
+	// the previous ones. Values and ValuesFrom override the merged presets.
+	// +optional
+	ValuesPresets []ValuesPresetRef `json:"valuesPresets,omitempty"`
+
+	// Patches are Kustomize inline patches applied to the manifests rendered by this helm chart
+	// before they are deployed. They are applied after the Patches defined at profile level.
+	// +optional
+	Patches []libsveltosv1beta1.Patch `json:"patches,omitempty"`
+
 	// HelmChartAction is the action that will be taken on the helm chart
 	// +kubebuilder:default:=Install
 	// +optional
@@ -419,13 +614,168 @@ type HelmChart struct {
 	// including information to connect to private registries.
 	// +optional
 	RegistryCredentialsConfig *RegistryCredentialsConfig `json:"registryCredentialsConfig,omitempty"`
+
+	// Verify, if set, makes Sveltos verify the chart integrity before deploying it.
+	// The chart is not deployed if verification fails.
+	// +optional
+	Verify *ChartVerification `json:"verify,omitempty"`
+
+	// DependencyRepositories configures credentials for the HTTP helm repositories
+	// the chart dependencies, declared in Chart.yaml, are downloaded from.
+	// Dependencies stored in other repositories are downloaded anonymously. Dependencies
+	// stored in OCI registries are downloaded with the chart RegistryCredentialsConfig.
+	// +listType=map
+	// +listMapKey=url
+	// +optional
+	DependencyRepositories []DependencyRepository `json:"dependencyRepositories,omitempty"`
+}
+
+// ChartVerification configures how the integrity of a helm chart is verified
+type ChartVerification struct {
+	// Provider is the verification method. cosign is supported for charts stored
+	// in OCI registries, gpg for charts stored in HTTP repositories.
+	Provider ChartVerificationProvider `json:"provider"`
+
+	// SecretRef references the Secret containing the keys used to verify the chart.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// For cosign, each key ending with .pub contains a PEM encoded public key. The chart
+	// is valid if signed with any of those keys.
+	// For gpg, each key contains a public keyring (as exported by gpg --export).
+	SecretRef corev1.SecretReference `json:"secretRef"`
+}
+
+// DependencyRepository configures how to access an HTTP helm repository chart dependencies are
+// downloaded from
+type DependencyRepository struct {
+	// URL of the helm repository, as declared by the dependencies in Chart.yaml
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// RegistryCredentialsConfig is the configuration for credentials and certificates
+	// used to connect to the repository.
+	// +optional
+	RegistryCredentialsConfig *RegistryCredentialsConfig `json:"registryCredentialsConfig,omitempty"`
+}
+
+// ChartSourceRef references a Flux source containing a helm chart
+type ChartSourceRef struct {
+	// Kind of the Flux source
+	// +kubebuilder:validation:Enum=HelmRepository;GitRepository;OCIRepository;Bucket
+	Kind string `json:"kind"`
+
+	// Namespace of the Flux source.
+	// For Profiles, a Flux source in a namespace other than the Profile namespace can only be
+	// referenced if granted by a ReferenceGrant.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the Flux source
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Path is the path of the chart within the GitRepository/OCIRepository/Bucket artifact.
+	// Ignored for HelmRepository.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ValuesPresetRef references a HelmValuesPreset
+type ValuesPresetRef struct {
+	// Name of the referenced HelmValuesPreset
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Version of the HelmValuesPreset values to use. When not set, the last
+	// version is used.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// JobRef references a ConfigMap/Secret whose data contains one or more Job manifests.
+// Content can be expressed as a template, same as PolicyRefs.
+type JobRef struct {
+	// Namespace of the referenced resource.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// For Profile namespace can be left empty. In such a case, the Profile namespace will be used.
+	// Referencing a resource in a different namespace requires a ReferenceGrant in that namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced resource.
+	// Name can be expressed as a template and instantiate using
+	// - cluster namespace: .Cluster.metadata.namespace
+	// - cluster name: .Cluster.metadata.name
+	// - cluster type: .Cluster.kind
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the resource. Supported kinds are:
+	// - ConfigMap/Secret
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// Timeout is the maximum time each Job is given to complete. It is set as the
+	// Job activeDeadlineSeconds unless the Job manifest already defines it.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// BackoffLimit, if set, overrides the number of retries of each Job before
+	// the Job is considered failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// SecretRotationHook declares the actions run in the managed cluster once a new version of a
+// Secret referenced in PolicyRefs has been delivered.
+type SecretRotationHook struct {
+	// Namespace of the Secret. It must match the namespace of a PolicyRef referencing the Secret.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the Secret. It must match the name of a PolicyRef referencing the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// RestartDeployments, when set, triggers a rolling restart of the Deployments in the
+	// managed cluster annotated with projectsveltos.io/restart-on-secret-rotation. The
+	// annotation value is a comma separated list of Secret names.
+	// Deployments are not restarted when the Secret is first delivered.
+	// +optional
+	RestartDeployments bool `json:"restartDeployments,omitempty"`
+
+	// Job, if set, references a ConfigMap/Secret containing Job manifests. Jobs are
+	// created in the managed cluster when the Secret is first delivered and created again
+	// after every rotation. Sveltos does not wait for those Jobs to complete.
+	// +optional
+	Job *JobRef `json:"job,omitempty"`
+}
+
+// Extension is a configuration handed, as is, to an out-of-tree deployment engine.
+// Deployment engines register with addon-controller as gRPC plugins, one per Kind.
+type Extension struct {
+	// Kind identifies the plugin this extension is dispatched to.
+	// A plugin must be registered for Kind
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name identifies this extension amongst all the extensions of the same Kind
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Config is passed, unmodified, to the plugin. Its format is defined by the plugin.
+	// +optional
+	Config string `json:"config,omitempty"`
 }
 
 type KustomizationRef struct {
 	// Namespace of the referenced resource.
 	// For ClusterProfile namespace can be left empty. In such a case, namespace will
 	// be implicit set to cluster's namespace.
-	// For Profile namespace must be left empty. The Profile namespace will be used.
+	// For Profile namespace can be left empty. In such a case, the Profile namespace will be used.
+	// Referencing a resource in a different namespace requires a ReferenceGrant in that namespace.
 	Namespace string `json:"namespace"`
 
 	// Name of the referenced resource.
@@ -447,7 +797,11 @@ type KustomizationRef struct {
 	// Defaults to 'None', which translates to the root path of the SourceRef.
 	// These values can be static or leverage Go templates for dynamic customization.
 	// When expressed as templates, the values are filled in using information from
-	// resources within the management cluster before deployment (Cluster)
+	// resources within the management cluster before deployment (Cluster), so a single
+	// profile can deploy per cluster overlays (e.g. overlays/{{ .Cluster.metadata.labels.region }}).
+	// Clusters are redeployed when a change in the Cluster leads to a different path.
+	// Path can also be a glob (e.g. clusters/prod/*/addons) or a comma separated list
+	// of paths/globs. Each matching directory is built and deployed.
 	// +optional
 	Path string `json:"path,omitempty"`
 
@@ -529,11 +883,61 @@ type TemplateResourceRef struct {
 	Identifier string `json:"identifier"`
 }
 
+// VariableType is the type of a profile Variable
+// +kubebuilder:validation:Enum:=String;Int;Bool;Enum
+type VariableType string
+
+// Define the VariableType constants.
+const (
+	VariableTypeString VariableType = "String"
+	VariableTypeInt    VariableType = "Int"
+	VariableTypeBool   VariableType = "Bool"
+	VariableTypeEnum   VariableType = "Enum"
+)
+
+// Variable declares a typed value available to all templates (helm values, resources,
+// kustomize and inline resources) of a profile as .Variables.<name>
+type Variable struct {
+	// Name of the variable. Templates refer to it as .Variables.<name>
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Description documents the variable
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Type of the variable. Value and Default are converted to this type
+	// before being passed to templates
+	// +kubebuilder:default:=String
+	// +optional
+	Type VariableType `json:"type,omitempty"`
+
+	// Enum lists the allowed values. Mandatory, and only allowed, for type Enum
+	// +listType=set
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+
+	// Required indicates either Value or Default must be set
+	// +kubebuilder:default:=false
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// Default is used when Value is not set
+	// +optional
+	Default *string `json:"default,omitempty"`
+
+	// Value of the variable
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
 type PolicyRef struct {
 	// Namespace of the referenced resource.
 	// For ClusterProfile namespace can be left empty. In such a case, namespace will
 	// be implicit set to cluster's namespace.
-	// For Profile namespace must be left empty. Profile namespace will be used.
+	// For Profile namespace can be left empty. In such a case, the Profile namespace will be used.
+	// Referencing a resource in a different namespace requires a ReferenceGrant in that namespace.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
@@ -553,6 +957,8 @@ type PolicyRef struct {
 
 	// Path to the directory containing the YAML files.
 	// Defaults to 'None', which translates to the root path of the SourceRef.
+	// Path can also be a glob (e.g. clusters/prod/*/addons) or a comma separated list
+	// of paths/globs. YAML files in all matching directories are deployed.
 	// Used only for GitRepository;OCIRepository;Bucket
 	// +optional
 	Path string `json:"path,omitempty"`
@@ -562,6 +968,60 @@ type PolicyRef struct {
 	// +kubebuilder:default:=Remote
 	// +optional
 	DeploymentType DeploymentType `json:"deploymentType,omitempty"`
+
+	// ReloadWorkloads, when set, makes Sveltos start a rolling restart of the Deployments and
+	// StatefulSets consuming (as volume, envFrom or env) a ConfigMap/Secret deployed from this
+	// PolicyRef every time its content changes. Workloads do not need to be deployed by Sveltos.
+	// Ignored when DeploymentType is Local.
+	// +kubebuilder:default:=false
+	// +optional
+	ReloadWorkloads bool `json:"reloadWorkloads,omitempty"`
+
+	// PruneAllowlist, when set, lists the only kinds Sveltos can ever remove from the managed
+	// cluster once resources deployed from this PolicyRef are not deployed anymore (for instance
+	// because the referenced ConfigMap/Secret was edited, this PolicyRef removed or the cluster
+	// stopped matching). Resources of any other kind are left in place with Sveltos labels removed.
+	// This guards precious kinds (Namespaces, PersistentVolumeClaims, CustomResourceDefinitions)
+	// against accidental removal. If not set, all stale resources are removed.
+	// +listType=atomic
+	// +optional
+	PruneAllowlist []PruneKind `json:"pruneAllowlist,omitempty"`
+}
+
+// PruneKind identifies a kind of resources
+type PruneKind struct {
+	// Group of the resources. Empty for the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the resources. If not set, all versions match.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Kind of the resources
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+}
+
+// InlineResource contains kubernetes resources expressed directly in the profile
+type InlineResource struct {
+	// Name identifies the inline resource within the profile
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Content contains the YAML/JSON of one or more kubernetes resources.
+	// Multiple resources must be separated by "---".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=16384
+	Content string `json:"content"`
+
+	// Template indicates Content is a Go template. When set, Content is instantiated
+	// using information from resources within the management cluster before deployment
+	// (Cluster and TemplateResourceRefs)
+	// +kubebuilder:default:=false
+	// +optional
+	Template bool `json:"template,omitempty"`
 }
 
 type DriftExclusion struct {
@@ -574,6 +1034,185 @@ type DriftExclusion struct {
 	Target *libsveltosv1beta1.PatchSelector `json:"target,omitempty"`
 }
 
+// ErrorBudget defines the minimum deployment success rate a ClusterProfile/Profile is expected
+// to keep across all matching clusters
+type ErrorBudget struct {
+	// Window is the rolling time window deployment outcomes are evaluated over
+	// +kubebuilder:default:="1h"
+	// +optional
+	Window metav1.Duration `json:"window,omitempty"`
+
+	// SuccessRateThreshold is the minimum percentage of deployments, attempted within
+	// Window across all matching clusters, which must succeed. When the success rate drops
+	// below it, the Degraded condition is set to true.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	SuccessRateThreshold int32 `json:"successRateThreshold"`
+}
+
+// MatchExpansionGuard limits how many clusters can start matching a ClusterProfile/Profile at once
+type MatchExpansionGuard struct {
+	// MaxIncrease is the maximum number of clusters which can be added, in a single reconciliation,
+	// to the clusters currently matching. A bigger increase (a selector edit going from 5 to 500
+	// matching clusters, for instance) pauses the rollout to the new clusters until acknowledged.
+	// +kubebuilder:validation:Minimum=1
+	MaxIncrease int32 `json:"maxIncrease"`
+}
+
+// SecretTransformerType is the kind of object Secrets are delivered to managed clusters as
+// +kubebuilder:validation:Enum:=ExternalSecret;SealedSecret
+type SecretTransformerType string
+
+const (
+	// SecretTransformerExternalSecret delivers each Secret as an external-secrets.io ExternalSecret.
+	// Secret data is not delivered: it must be available in the managed cluster secret store.
+	SecretTransformerExternalSecret = SecretTransformerType("ExternalSecret")
+
+	// SecretTransformerSealedSecret delivers each Secret as a bitnami.com SealedSecret, encrypted
+	// with the certificate of the managed cluster sealed-secrets controller.
+	SecretTransformerSealedSecret = SecretTransformerType("SealedSecret")
+)
+
+// SecretStoreRef references a SecretStore/ClusterSecretStore in the managed cluster
+type SecretStoreRef struct {
+	// Name of the SecretStore/ClusterSecretStore
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the secret store
+	// +kubebuilder:validation:Enum=SecretStore;ClusterSecretStore
+	// +kubebuilder:default:=SecretStore
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// SealedSecretsCertificateRef references the ConfigMap/Secret, in the management cluster,
+// containing the PEM encoded certificate of the managed cluster sealed-secrets controller
+type SealedSecretsCertificateRef struct {
+	// Kind of the resource. Supported kinds are:
+	// - ConfigMap/Secret
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// Namespace of the referenced resource.
+	// Namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced resource.
+	// Name can be expressed as a template and instantiate using
+	// - cluster namespace: .Cluster.metadata.namespace
+	// - cluster name: .Cluster.metadata.name
+	// - cluster type: .Cluster.kind
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key, within the referenced resource, containing the certificate
+	// +kubebuilder:default:="cert.pem"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// SecretTransformer defines how Secrets, deployed by the Resources and Kustomize features,
+// are delivered to the managed clusters
+type SecretTransformer struct {
+	// Type is the kind of object each Secret is transformed into
+	Type SecretTransformerType `json:"type"`
+
+	// SecretStoreRef is the secret store ExternalSecrets read from.
+	// Required when Type is ExternalSecret.
+	// +optional
+	SecretStoreRef *SecretStoreRef `json:"secretStoreRef,omitempty"`
+
+	// RemoteKeyPrefix is prepended to "<namespace>/<name>" of each Secret to form the key the
+	// ExternalSecret reads from the secret store. Each Secret data key is read from the property
+	// with the same name.
+	// +optional
+	RemoteKeyPrefix string `json:"remoteKeyPrefix,omitempty"`
+
+	// CertificateRef references the certificate SealedSecrets are encrypted with.
+	// Required when Type is SealedSecret.
+	// +optional
+	CertificateRef *SealedSecretsCertificateRef `json:"certificateRef,omitempty"`
+}
+
+// ImageDigestResolution configures how image tags are resolved to digests
+type ImageDigestResolution struct {
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson with the
+	// credentials used to query the registries. Registries with no credentials are queried anonymously.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// +optional
+	CredentialsSecretRef *corev1.SecretReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// WasmModuleConfigMapRef references the ConfigMap, in the management cluster, containing a
+// WebAssembly module
+type WasmModuleConfigMapRef struct {
+	// Namespace of the referenced ConfigMap.
+	// Namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced ConfigMap.
+	// Name can be expressed as a template and instantiate using
+	// - cluster namespace: .Cluster.metadata.namespace
+	// - cluster name: .Cluster.metadata.name
+	// - cluster type: .Cluster.kind
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key, within the ConfigMap binaryData, containing the module
+	// +kubebuilder:default:="plugin.wasm"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// WasmPlugin is a WebAssembly module run over the resources rendered for the managed clusters.
+// The module must be a WASI command: it reads the rendered resources, as a YAML stream, from
+// stdin and writes the resources to deploy, as a YAML stream, to stdout. A module mutates
+// resources by writing them modified and validates them by exiting with a non-zero code, in
+// which case nothing is deployed and stderr is reported.
+// Modules have no access to the filesystem, the network or the environment.
+type WasmPlugin struct {
+	// Name of the plugin
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ConfigMapRef references the ConfigMap containing the module.
+	// Exactly one of ConfigMapRef and Image must be set.
+	// +optional
+	ConfigMapRef *WasmModuleConfigMapRef `json:"configMapRef,omitempty"`
+
+	// Image is the OCI reference (for instance registry.io/plugins/policy:v1 or
+	// registry.io/plugins/policy@sha256:...) of an artifact whose layer is the module.
+	// Exactly one of ConfigMapRef and Image must be set.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson with the
+	// credentials used to pull Image. If not set, Image is pulled anonymously.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// +optional
+	CredentialsSecretRef *corev1.SecretReference `json:"credentialsSecretRef,omitempty"`
+
+	// Timeout is the maximum time the module can run for. Past it, the module is stopped
+	// and nothing is deployed.
+	// +kubebuilder:default:="10s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxMemoryMiB is the maximum memory, in MiB, the module can use.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4096
+	// +kubebuilder:default:=16
+	// +optional
+	MaxMemoryMiB int32 `json:"maxMemoryMiB,omitempty"`
+}
+
 type Clusters struct {
 	// Hash represents of a unique value for ClusterProfile Spec at
 	// a fixed point in time
@@ -601,6 +1240,15 @@ type Spec struct {
 	// +optional
 	SetRefs []string `json:"setRefs,omitempty"`
 
+	// ParentProfile references the parent of this profile:
+	// - for a ClusterProfile, the name of a ClusterProfile;
+	// - for a Profile, the name of a Profile in the same namespace.
+	// When set, this profile matches the clusters currently matching the parent, and
+	// ClusterSelector, ClusterRefs and SetRefs are ignored. In each matching cluster, the
+	// add-ons and applications of this profile are deployed only once the ones of the parent are.
+	// +optional
+	ParentProfile string `json:"parentProfile,omitempty"`
+
 	// SyncMode specifies how features are synced in a matching workload cluster.
 	// - OneTime means, first time a workload cluster matches the ClusterProfile,
 	// features will be deployed in such cluster. Any subsequent feature configuration
@@ -637,6 +1285,30 @@ type Spec struct {
 	// +optional
 	ContinueOnConflict bool `json:"continueOnConflict,omitempty"`
 
+	// By default (when ContinueOnError is unset or set to false), Sveltos stops deploying a feature
+	// (helm charts, KustomizationRefs or PolicyRefs) after the first failure.
+	// If set to true, Sveltos will attempt to deploy the remaining helm charts, KustomizationRefs and
+	// PolicyRefs even if previous ones failed. Feature status then reports which ones failed.
+	// +kubebuilder:default:=false
+	// +optional
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+
+	// GenerateRBAC, when set, makes Sveltos compute the minimal RBAC required to apply the
+	// resources deployed because of PolicyRefs and deploy it in the managed cluster alongside
+	// those resources: a Role per namespace for namespaced resources and a ClusterRole for
+	// cluster-wide resources. Roles can then be bound to a restricted identity.
+	// +kubebuilder:default:=false
+	// +optional
+	GenerateRBAC bool `json:"generateRBAC,omitempty"`
+
+	// DedicatedIdentity, when set, makes Sveltos apply PolicyRefs, KustomizationRefs and Jobs in the
+	// managed cluster using a ServiceAccount dedicated to the profile, instead of the cluster admin
+	// kubeconfig. Sveltos creates the ServiceAccount (in the projectsveltos namespace) and rotates
+	// its token, so managed cluster audit logs attribute changes to the owning profile.
+	// +kubebuilder:default:=false
+	// +optional
+	DedicatedIdentity bool `json:"dedicatedIdentity,omitempty"`
+
 	// The maximum number of clusters that can be updated concurrently.
 	// Value can be an absolute number (ex: 5) or a percentage of desired cluster (ex: 10%).
 	// Defaults to 100%.
@@ -656,6 +1328,16 @@ type Spec struct {
 	// +optional
 	StopMatchingBehavior StopMatchingBehavior `json:"stopMatchingBehavior,omitempty"`
 
+	// StopMatchingBehaviorTemplate, if set, decides StopMatchingBehavior per feature and per cluster.
+	// It is a template instantiated, when a Cluster stops matching, using the same objects available
+	// to other templates (.Cluster, .InfrastructureProvider, .KubeadmControlPlane, .Variables) plus
+	// .Feature, the feature being withdrawn (Resources, Helm, Kustomize). It must evaluate to either
+	// LeavePolicies or WithdrawPolicies. An empty result falls back to StopMatchingBehavior.
+	// For instance:
+	// {{ if eq (index .Cluster.metadata.labels "env") "prod" }}LeavePolicies{{ else }}WithdrawPolicies{{ end }}
+	// +optional
+	StopMatchingBehaviorTemplate string `json:"stopMatchingBehaviorTemplate,omitempty"`
+
 	// Reloader indicates whether Deployment/StatefulSet/DaemonSet instances deployed
 	// by Sveltos and part of this ClusterProfile need to be restarted via rolling upgrade
 	// when a ConfigMap/Secret instance mounted as volume is modified.
@@ -674,12 +1356,102 @@ type Spec struct {
 	// +optional
 	TemplateResourceRefs []TemplateResourceRef `json:"templateResourceRefs,omitempty" patchStrategy:"merge" patchMergeKey:"identifier"`
 
+	// Variables declares typed values available to all templates of this profile as
+	// .Variables.<name>. Values not matching their declaration are reported in the SpecInvalid
+	// condition and the profile is not deployed.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Variables []Variable `json:"variables,omitempty"`
+
 	// DependsOn specifies a list of other ClusterProfiles that this instance depends on.
 	// In any managed cluster that matches this ClusterProfile, the add-ons and applications
 	// defined in this instance will not be deployed until all add-ons and applications in the
 	// ClusterProfiles listed as dependencies are deployed.
 	DependsOn []string `json:"dependsOn,omitempty"`
 
+	// DeploymentOrder overrides the order in which features are deployed in each matching
+	// cluster (by default all features are deployed at the same time). Each listed feature is
+	// deployed only once all features preceding it are provisioned, for instance raw CRDs in
+	// PolicyRefs can be deployed before the helm charts depending on those.
+	// Features not listed are deployed, after the listed ones, in the default order.
+	// Helm charts are always deployed in the order they are listed in HelmCharts.
+	// +kubebuilder:validation:MaxItems=5
+	// +listType=set
+	// +optional
+	DeploymentOrder []FeatureID `json:"deploymentOrder,omitempty"`
+
+	// SupersededBy marks this instance as deprecated in favor of another ClusterProfile (or
+	// Profile in the same namespace for a Profile).
+	// In each managed cluster matching both, ownership of the deployed resources and helm
+	// releases is handed over to the successor without removing them. Clusters are migrated
+	// one by one, respecting MaxUpdate: a cluster counts as being updated till the successor
+	// has provisioned all its features there.
+	// +optional
+	SupersededBy string `json:"supersededBy,omitempty"`
+
+	// HealthCheckGates lists ClusterHealthChecks gating updates. Once add-ons and applications
+	// have been deployed in a managed cluster, any further update is deployed there only when all
+	// conditions reported by those ClusterHealthChecks for the cluster are passing. This prevents
+	// piling changes onto an already unhealthy cluster.
+	// ClusterHealthChecks not matching the cluster are ignored.
+	// +listType=set
+	// +optional
+	HealthCheckGates []string `json:"healthCheckGates,omitempty"`
+
+	// WriteBudget is the maximum number of resources applied to a managed cluster in a single
+	// deployment pass of the Resources and Kustomize features. Profiles with thousands of resources
+	// are then applied in chunks across consecutive passes, smoothing the load on the managed cluster.
+	// Progress is reported in the ClusterSummary status (PendingResources).
+	// If not set, all resources are applied in a single pass.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WriteBudget *int32 `json:"writeBudget,omitempty"`
+
+	// ErrorBudget, if set, tracks the rolling success rate of deployments across all matching
+	// clusters. When the success rate drops below the configured threshold, the
+	// ClusterProfile/Profile Degraded condition is set.
+	// +optional
+	ErrorBudget *ErrorBudget `json:"errorBudget,omitempty"`
+
+	// MatchExpansionGuard, when set, protects against fat-fingered selector changes. When the
+	// number of matching clusters increases by more than MaxIncrease, clusters not previously
+	// matching are left out and the MatchExpansionPending condition is set, until the increase
+	// is acknowledged by setting the projectsveltos.io/acknowledge-match-count annotation to
+	// the new number of matching clusters.
+	// +optional
+	MatchExpansionGuard *MatchExpansionGuard `json:"matchExpansionGuard,omitempty"`
+
+	// DeletionProtection, when set, prevents deleting the ClusterProfile/Profile, or removing
+	// all of its helm charts, unless the projectsveltos.io/confirm-deletion annotation is set
+	// to "true". Meant for fleet-critical add-ons (CNI, for instance). Till confirmed, nothing
+	// is removed and the DeletionBlocked condition is set.
+	// +kubebuilder:default:=false
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// SecretTransformer, when set, delivers Secrets deployed by the Resources and Kustomize
+	// features as ExternalSecrets or SealedSecrets instead of plain Secrets, for fleets with
+	// policies against plaintext secrets delivered over the management plane.
+	// Secrets deployed by helm charts are not transformed.
+	// +optional
+	SecretTransformer *SecretTransformer `json:"secretTransformer,omitempty"`
+
+	// ImageDigestResolution, when set, resolves the image tags of the containers deployed by the
+	// Resources, Kustomize and Helm features to digests, querying the registries, before anything
+	// is deployed. Deployments are then reproducible and not affected by tags being moved.
+	// Resolved images are reported in the ClusterSummary status.
+	// +optional
+	ImageDigestResolution *ImageDigestResolution `json:"imageDigestResolution,omitempty"`
+
+	// WasmPlugins are WebAssembly modules run, in order, over the resources deployed by the
+	// Resources, Kustomize and Helm features, after Patches are applied and before anything
+	// is deployed. Each plugin is passed the output of the previous one.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	WasmPlugins []WasmPlugin `json:"wasmPlugins,omitempty"`
+
 	// PolicyRefs references all the ConfigMaps/Secrets/Flux Sources containing kubernetes resources
 	// that need to be deployed in the matching managed clusters.
 	// The values contained in those resources can be static or leverage Go templates for dynamic customization.
@@ -688,6 +1460,17 @@ type Spec struct {
 	// +optional
 	PolicyRefs []PolicyRef `json:"policyRefs,omitempty"`
 
+	// InlineResources contains kubernetes resources, expressed directly in the profile, that need
+	// to be deployed in the matching managed clusters. Meant for small snippets (a Namespace, a
+	// NetworkPolicy) not worth a ConfigMap. Those are deployed along with PolicyRefs.
+	// Content which is not a valid resource, unless a template, or exceeding the maximum size is
+	// reported in the SpecInvalid condition and the profile is not deployed.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	InlineResources []InlineResource `json:"inlineResources,omitempty"`
+
 	// Helm charts is a list of helm charts that need to be deployed
 	HelmCharts []HelmChart `json:"helmCharts,omitempty"`
 
@@ -695,6 +1478,22 @@ type Spec struct {
 	// be run on those paths and the outcome will be deployed.
 	KustomizationRefs []KustomizationRef `json:"kustomizationRefs,omitempty"`
 
+	// Jobs is a list of references to ConfigMaps/Secrets containing Job manifests.
+	// Jobs are run, in order, to completion in the managed cluster. Useful for one-time
+	// cluster bootstrap tasks. A Job is run again only if its manifest changes.
+	// +optional
+	Jobs []JobRef `json:"jobs,omitempty"`
+
+	// SecretRotationHooks are run in the managed cluster after a new version of a Secret,
+	// referenced in PolicyRefs, has been delivered, completing the secret rotation end-to-end.
+	// +optional
+	SecretRotationHooks []SecretRotationHook `json:"secretRotationHooks,omitempty"`
+
+	// Extensions is a list of configurations handled by out-of-tree deployment engines.
+	// Each extension is dispatched to the plugin registered for its Kind.
+	// +optional
+	Extensions []Extension `json:"extensions,omitempty"`
+
 	// ValidateHealths is a slice of Lua functions to run against
 	// the managed cluster to validate the state of those add-ons/applications
 	// is healthy
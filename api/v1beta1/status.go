@@ -18,6 +18,76 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClustersUnmanagedCondition is True when some of the clusters matching the ClusterProfile/Profile
+	// have opted out of Sveltos management
+	ClustersUnmanagedCondition = "ClustersUnmanaged"
+
+	// ClusterOptedOutReason is the ClustersUnmanagedCondition reason when at least one matching
+	// cluster has opted out of Sveltos management
+	ClusterOptedOutReason = "ClusterOptedOut"
+
+	// AllClustersManagedReason is the ClustersUnmanagedCondition reason when all matching
+	// clusters are managed
+	AllClustersManagedReason = "AllClustersManaged"
+
+	// DegradedCondition is True when the deployment success rate of the ClusterProfile/Profile,
+	// over the ErrorBudget window, is below the ErrorBudget threshold
+	DegradedCondition = "Degraded"
+
+	// ErrorBudgetExhaustedReason is the DegradedCondition reason when the success rate is
+	// below the threshold
+	ErrorBudgetExhaustedReason = "ErrorBudgetExhausted"
+
+	// WithinErrorBudgetReason is the DegradedCondition reason when the success rate is
+	// at or above the threshold
+	WithinErrorBudgetReason = "WithinErrorBudget"
+
+	// MatchExpansionPendingCondition is True when the number of matching clusters increased by more
+	// than MatchExpansionGuard allows and the increase has not been acknowledged yet
+	MatchExpansionPendingCondition = "MatchExpansionPending"
+
+	// MatchExpansionExceededReason is the MatchExpansionPendingCondition reason when new matching
+	// clusters are left out waiting for acknowledgment
+	MatchExpansionExceededReason = "MatchExpansionExceeded"
+
+	// MatchExpansionAllowedReason is the MatchExpansionPendingCondition reason when all matching
+	// clusters are managed
+	MatchExpansionAllowedReason = "MatchExpansionAllowed"
+
+	// SpecWarningsCondition is True when the ClusterProfile/Profile Spec contains common pitfalls,
+	// such as helm release name collisions or deprecated fields. Those do not prevent deployment.
+	SpecWarningsCondition = "SpecWarnings"
+
+	// SpecPitfallsFoundReason is the SpecWarningsCondition reason when at least one pitfall is found
+	SpecPitfallsFoundReason = "SpecPitfallsFound"
+
+	// NoSpecPitfallsReason is the SpecWarningsCondition reason when no pitfall is found
+	NoSpecPitfallsReason = "NoSpecPitfalls"
+
+	// SpecInvalidCondition is True when the ClusterProfile/Profile Spec fails validation. Till the Spec
+	// is fixed, ClusterSummaries are not updated and matching clusters keep what was last deployed.
+	SpecInvalidCondition = "SpecInvalid"
+
+	// SpecValidationFailedReason is the SpecInvalidCondition reason when the Spec fails validation
+	SpecValidationFailedReason = "ValidationFailed"
+
+	// SpecValidReason is the SpecInvalidCondition reason when the Spec is valid
+	SpecValidReason = "SpecValid"
+
+	// DeletionBlockedCondition is True when the ClusterProfile/Profile has DeletionProtection set
+	// and its deletion, or the removal of all of its helm charts, is not confirmed. Till then the
+	// ClusterProfile/Profile is not deleted and ClusterSummaries are not updated.
+	DeletionBlockedCondition = "DeletionBlocked"
+
+	// DeletionNotConfirmedReason is the DeletionBlockedCondition reason when deletion is not confirmed
+	DeletionNotConfirmedReason = "DeletionNotConfirmed"
+
+	// DeletionAllowedReason is the DeletionBlockedCondition reason when nothing is blocked
+	DeletionAllowedReason = "DeletionAllowed"
 )
 
 // Status defines the observed state of ClusterProfile/Profile
@@ -37,4 +107,83 @@ type Status struct {
 	// Spec
 	// +optional
 	UpdatedClusters Clusters `json:"updatedClusters,omitempty"`
+
+	// ReferenceValidationErrors lists problems found validating, in the background, the content
+	// of the ConfigMaps/Secrets referenced by the ClusterProfile/Profile.
+	// Those are reported before any deployment is attempted.
+	// +optional
+	ReferenceValidationErrors []ReferenceValidationError `json:"referenceValidationErrors,omitempty"`
+
+	// MigratedClusterRefs references the matching clusters which have been handed over
+	// to the successor set in SupersededBy
+	// +optional
+	MigratedClusterRefs []corev1.ObjectReference `json:"migratedClusters,omitempty"`
+
+	// ReadyClusters reports, in the form <ready>/<matching>, how many of the matching
+	// clusters have all features provisioned
+	// +optional
+	ReadyClusters string `json:"readyClusters,omitempty"`
+
+	// FailedClusters is the number of matching clusters where at least one feature
+	// failed to be deployed
+	// +optional
+	FailedClusters int32 `json:"failedClusters,omitempty"`
+
+	// Rollout reports the progress of the rollout of the current Spec to the matching clusters
+	// +optional
+	Rollout *RolloutProgress `json:"rollout,omitempty"`
+
+	// Conditions reports the ClusterProfile/Profile conditions
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// RolloutProgress reports how the rollout of a ClusterProfile/Profile Spec is progressing
+type RolloutProgress struct {
+	// Hash identifies the Spec being rolled out
+	// +optional
+	Hash []byte `json:"hash,omitempty"`
+
+	// StartTime is when the rollout of the Spec started
+	StartTime metav1.Time `json:"startTime"`
+
+	// UpdatedClusters is the number of matching clusters where the Spec has been deployed
+	UpdatedClusters int32 `json:"updatedClusters"`
+
+	// RemainingClusters is the number of matching clusters where the Spec has not been deployed yet
+	RemainingClusters int32 `json:"remainingClusters"`
+
+	// ClustersPerMinute is the rolling average of the number of clusters updated per minute,
+	// computed over the most recently updated clusters
+	// +optional
+	ClustersPerMinute string `json:"clustersPerMinute,omitempty"`
+
+	// EstimatedCompletionTime is when, at the current pace, all matching clusters will be updated
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+
+	// CompletionTime is when all matching clusters were updated
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ReferenceValidationError reports a problem found in the content of a referenced ConfigMap/Secret
+type ReferenceValidationError struct {
+	// Kind of the referenced resource (ConfigMap or Secret)
+	Kind string `json:"kind"`
+
+	// Namespace of the referenced resource
+	Namespace string `json:"namespace"`
+
+	// Name of the referenced resource
+	Name string `json:"name"`
+
+	// Key is the key, within the referenced resource data, containing the invalid content
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Message describes the problem
+	Message string `json:"message"`
 }
@@ -21,8 +21,8 @@ limitations under the License.
 package v1beta1
 
 import (
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -48,6 +48,53 @@ func (in *Chart) DeepCopy() *Chart {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartSourceRef) DeepCopyInto(out *ChartSourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartSourceRef.
+func (in *ChartSourceRef) DeepCopy() *ChartSourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartSourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartVerification) DeepCopyInto(out *ChartVerification) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartVerification.
+func (in *ChartVerification) DeepCopy() *ChartVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterBacklog) DeepCopyInto(out *ClusterBacklog) {
+	*out = *in
+	out.Cluster = in.Cluster
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBacklog.
+func (in *ClusterBacklog) DeepCopy() *ClusterBacklog {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBacklog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfiguration) DeepCopyInto(out *ClusterConfiguration) {
 	*out = *in
@@ -275,6 +322,41 @@ func (in *ClusterReportList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReportSnapshot) DeepCopyInto(out *ClusterReportSnapshot) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.ReleaseReports != nil {
+		in, out := &in.ReleaseReports, &out.ReleaseReports
+		*out = make([]ReleaseReport, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceReports != nil {
+		in, out := &in.ResourceReports, &out.ResourceReports
+		*out = make([]ResourceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KustomizeResourceReports != nil {
+		in, out := &in.KustomizeResourceReports, &out.KustomizeResourceReports
+		*out = make([]ResourceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReportSnapshot.
+func (in *ClusterReportSnapshot) DeepCopy() *ClusterReportSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReportSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterReportSpec) DeepCopyInto(out *ClusterReportSpec) {
 	*out = *in
@@ -312,6 +394,17 @@ func (in *ClusterReportStatus) DeepCopyInto(out *ClusterReportStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ClusterReportSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReportStatus.
@@ -428,6 +521,44 @@ func (in *ClusterSummaryStatus) DeepCopyInto(out *ClusterSummaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MissingReferences != nil {
+		in, out := &in.MissingReferences, &out.MissingReferences
+		*out = make([]MissingReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSuccessfulSyncTime != nil {
+		in, out := &in.LastSuccessfulSyncTime, &out.LastSuccessfulSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TierStatuses != nil {
+		in, out := &in.TierStatuses, &out.TierStatuses
+		*out = make([]TierStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeploymentOutcomes != nil {
+		in, out := &in.DeploymentOutcomes, &out.DeploymentOutcomes
+		*out = make([]DeploymentOutcome, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResolvedImages != nil {
+		in, out := &in.ResolvedImages, &out.ResolvedImages
+		*out = make([]ResolvedImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]ConflictDetail, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSummaryStatus.
@@ -450,7 +581,7 @@ func (in *Clusters) DeepCopyInto(out *Clusters) {
 	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
-		*out = make([]v1.ObjectReference, len(*in))
+		*out = make([]corev1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -465,6 +596,149 @@ func (in *Clusters) DeepCopy() *Clusters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConflictDetail) DeepCopyInto(out *ConflictDetail) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConflictDetail.
+func (in *ConflictDetail) DeepCopy() *ConflictDetail {
+	if in == nil {
+		return nil
+	}
+	out := new(ConflictDetail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerStatus) DeepCopyInto(out *ControllerStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerStatus.
+func (in *ControllerStatus) DeepCopy() *ControllerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ControllerStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerStatusList) DeepCopyInto(out *ControllerStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ControllerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerStatusList.
+func (in *ControllerStatusList) DeepCopy() *ControllerStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ControllerStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerStatusStatus) DeepCopyInto(out *ControllerStatusStatus) {
+	*out = *in
+	if in.OldestPendingRequestAge != nil {
+		in, out := &in.OldestPendingRequestAge, &out.OldestPendingRequestAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ClusterBacklogs != nil {
+		in, out := &in.ClusterBacklogs, &out.ClusterBacklogs
+		*out = make([]ClusterBacklog, len(*in))
+		copy(*out, *in)
+	}
+	if in.Watchers != nil {
+		in, out := &in.Watchers, &out.Watchers
+		*out = make([]WatcherStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerStatusStatus.
+func (in *ControllerStatusStatus) DeepCopy() *ControllerStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyRepository) DeepCopyInto(out *DependencyRepository) {
+	*out = *in
+	if in.RegistryCredentialsConfig != nil {
+		in, out := &in.RegistryCredentialsConfig, &out.RegistryCredentialsConfig
+		*out = new(RegistryCredentialsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyRepository.
+func (in *DependencyRepository) DeepCopy() *DependencyRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentOutcome) DeepCopyInto(out *DeploymentOutcome) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOutcome.
+func (in *DeploymentOutcome) DeepCopy() *DeploymentOutcome {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentOutcome)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriftExclusion) DeepCopyInto(out *DriftExclusion) {
 	*out = *in
@@ -505,6 +779,37 @@ func (in *DryRunReconciliationError) DeepCopy() *DryRunReconciliationError {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErrorBudget) DeepCopyInto(out *ErrorBudget) {
+	*out = *in
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErrorBudget.
+func (in *ErrorBudget) DeepCopy() *ErrorBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(ErrorBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Extension) DeepCopyInto(out *Extension) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extension.
+func (in *Extension) DeepCopy() *Extension {
+	if in == nil {
+		return nil
+	}
+	out := new(Extension)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Feature) DeepCopyInto(out *Feature) {
 	*out = *in
@@ -581,6 +886,11 @@ func (in *FeatureSummary) DeepCopyInto(out *FeatureSummary) {
 		in, out := &in.LastAppliedTime, &out.LastAppliedTime
 		*out = (*in).DeepCopy()
 	}
+	if in.PendingResources != nil {
+		in, out := &in.PendingResources, &out.PendingResources
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureSummary.
@@ -594,62 +904,229 @@ func (in *FeatureSummary) DeepCopy() *FeatureSummary {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmChart) DeepCopyInto(out *HelmChart) {
+func (in *FederatedClusterStatus) DeepCopyInto(out *FederatedClusterStatus) {
 	*out = *in
-	if in.ValuesFrom != nil {
-		in, out := &in.ValuesFrom, &out.ValuesFrom
-		*out = make([]ValueFrom, len(*in))
-		copy(*out, *in)
-	}
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
-		*out = new(HelmOptions)
-		(*in).DeepCopyInto(*out)
+	out.Cluster = in.Cluster
+	if in.FeatureSummaries != nil {
+		in, out := &in.FeatureSummaries, &out.FeatureSummaries
+		*out = make([]FederatedFeatureStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.RegistryCredentialsConfig != nil {
-		in, out := &in.RegistryCredentialsConfig, &out.RegistryCredentialsConfig
-		*out = new(RegistryCredentialsConfig)
-		(*in).DeepCopyInto(*out)
+	if in.LastSuccessfulSyncTime != nil {
+		in, out := &in.LastSuccessfulSyncTime, &out.LastSuccessfulSyncTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChart.
-func (in *HelmChart) DeepCopy() *HelmChart {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterStatus.
+func (in *FederatedClusterStatus) DeepCopy() *FederatedClusterStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HelmChart)
+	out := new(FederatedClusterStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmChartSummary) DeepCopyInto(out *HelmChartSummary) {
+func (in *FederatedFeatureStatus) DeepCopyInto(out *FederatedFeatureStatus) {
 	*out = *in
-	if in.ValuesHash != nil {
-		in, out := &in.ValuesHash, &out.ValuesHash
-		*out = make([]byte, len(*in))
-		copy(*out, *in)
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSummary.
-func (in *HelmChartSummary) DeepCopy() *HelmChartSummary {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedFeatureStatus.
+func (in *FederatedFeatureStatus) DeepCopy() *FederatedFeatureStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HelmChartSummary)
+	out := new(FederatedFeatureStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmInstallOptions) DeepCopyInto(out *HelmInstallOptions) {
+func (in *FederatedProfileStatus) DeepCopyInto(out *FederatedProfileStatus) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmInstallOptions.
-func (in *HelmInstallOptions) DeepCopy() *HelmInstallOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedProfileStatus.
+func (in *FederatedProfileStatus) DeepCopy() *FederatedProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedProfileStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedProfileStatusList) DeepCopyInto(out *FederatedProfileStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FederatedProfileStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedProfileStatusList.
+func (in *FederatedProfileStatusList) DeepCopy() *FederatedProfileStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedProfileStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedProfileStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedProfileStatusSpec) DeepCopyInto(out *FederatedProfileStatusSpec) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]FederatedClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastPublishedTime.DeepCopyInto(&out.LastPublishedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedProfileStatusSpec.
+func (in *FederatedProfileStatusSpec) DeepCopy() *FederatedProfileStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedProfileStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChart) DeepCopyInto(out *HelmChart) {
+	*out = *in
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(ChartSourceRef)
+		**out = **in
+	}
+	if in.VersionPolicy != nil {
+		in, out := &in.VersionPolicy, &out.VersionPolicy
+		*out = new(VersionPolicy)
+		**out = **in
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValueFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesPresets != nil {
+		in, out := &in.ValuesPresets, &out.ValuesPresets
+		*out = make([]ValuesPresetRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]apiv1beta1.Patch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(HelmOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryCredentialsConfig != nil {
+		in, out := &in.RegistryCredentialsConfig, &out.RegistryCredentialsConfig
+		*out = new(RegistryCredentialsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(ChartVerification)
+		**out = **in
+	}
+	if in.DependencyRepositories != nil {
+		in, out := &in.DependencyRepositories, &out.DependencyRepositories
+		*out = make([]DependencyRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChart.
+func (in *HelmChart) DeepCopy() *HelmChart {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSummary) DeepCopyInto(out *HelmChartSummary) {
+	*out = *in
+	if in.ValuesHash != nil {
+		in, out := &in.ValuesHash, &out.ValuesHash
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(HelmStorage)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSummary.
+func (in *HelmChartSummary) DeepCopy() *HelmChartSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmInstallOptions) DeepCopyInto(out *HelmInstallOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmInstallOptions.
+func (in *HelmInstallOptions) DeepCopy() *HelmInstallOptions {
 	if in == nil {
 		return nil
 	}
@@ -663,7 +1140,7 @@ func (in *HelmOptions) DeepCopyInto(out *HelmOptions) {
 	*out = *in
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	if in.Labels != nil {
@@ -673,9 +1150,19 @@ func (in *HelmOptions) DeepCopyInto(out *HelmOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.HelmTestsTimeout != nil {
+		in, out := &in.HelmTestsTimeout, &out.HelmTestsTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(HelmStorage)
+		(*in).DeepCopyInto(*out)
+	}
 	out.InstallOptions = in.InstallOptions
 	out.UpgradeOptions = in.UpgradeOptions
-	out.UninstallOptions = in.UninstallOptions
+	in.UninstallOptions.DeepCopyInto(&out.UninstallOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmOptions.
@@ -688,9 +1175,39 @@ func (in *HelmOptions) DeepCopy() *HelmOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmStorage) DeepCopyInto(out *HelmStorage) {
+	*out = *in
+	if in.SQLConnectionSecretRef != nil {
+		in, out := &in.SQLConnectionSecretRef, &out.SQLConnectionSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmStorage.
+func (in *HelmStorage) DeepCopy() *HelmStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmUninstallOptions) DeepCopyInto(out *HelmUninstallOptions) {
 	*out = *in
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmUninstallOptions.
@@ -718,6 +1235,159 @@ func (in *HelmUpgradeOptions) DeepCopy() *HelmUpgradeOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesPreset) DeepCopyInto(out *HelmValuesPreset) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesPreset.
+func (in *HelmValuesPreset) DeepCopy() *HelmValuesPreset {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesPreset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmValuesPreset) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesPresetList) DeepCopyInto(out *HelmValuesPresetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HelmValuesPreset, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesPresetList.
+func (in *HelmValuesPresetList) DeepCopy() *HelmValuesPresetList {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesPresetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmValuesPresetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesPresetSpec) DeepCopyInto(out *HelmValuesPresetSpec) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]HelmValuesPresetVersion, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesPresetSpec.
+func (in *HelmValuesPresetSpec) DeepCopy() *HelmValuesPresetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesPresetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesPresetVersion) DeepCopyInto(out *HelmValuesPresetVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesPresetVersion.
+func (in *HelmValuesPresetVersion) DeepCopy() *HelmValuesPresetVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesPresetVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDigestResolution) DeepCopyInto(out *ImageDigestResolution) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDigestResolution.
+func (in *ImageDigestResolution) DeepCopy() *ImageDigestResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDigestResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InlineResource) DeepCopyInto(out *InlineResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlineResource.
+func (in *InlineResource) DeepCopy() *InlineResource {
+	if in == nil {
+		return nil
+	}
+	out := new(InlineResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRef) DeepCopyInto(out *JobRef) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRef.
+func (in *JobRef) DeepCopy() *JobRef {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KustomizationRef) DeepCopyInto(out *KustomizationRef) {
 	*out = *in
@@ -745,9 +1415,44 @@ func (in *KustomizationRef) DeepCopy() *KustomizationRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchExpansionGuard) DeepCopyInto(out *MatchExpansionGuard) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchExpansionGuard.
+func (in *MatchExpansionGuard) DeepCopy() *MatchExpansionGuard {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchExpansionGuard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissingReference) DeepCopyInto(out *MissingReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissingReference.
+func (in *MissingReference) DeepCopy() *MissingReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MissingReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRef) DeepCopyInto(out *PolicyRef) {
 	*out = *in
+	if in.PruneAllowlist != nil {
+		in, out := &in.PruneAllowlist, &out.PruneAllowlist
+		*out = make([]PruneKind, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRef.
@@ -801,42 +1506,297 @@ func (in *ProfileList) DeepCopyInto(out *ProfileList) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileList.
-func (in *ProfileList) DeepCopy() *ProfileList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileList.
+func (in *ProfileList) DeepCopy() *ProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileResource) DeepCopyInto(out *ProfileResource) {
+	*out = *in
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]Feature, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileResource.
+func (in *ProfileResource) DeepCopy() *ProfileResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Promotion) DeepCopyInto(out *Promotion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Promotion.
+func (in *Promotion) DeepCopy() *Promotion {
+	if in == nil {
+		return nil
+	}
+	out := new(Promotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Promotion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionList) DeepCopyInto(out *PromotionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Promotion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionList.
+func (in *PromotionList) DeepCopy() *PromotionList {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PromotionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionSpec) DeepCopyInto(out *PromotionSpec) {
+	*out = *in
+	in.Staging.DeepCopyInto(&out.Staging)
+	in.Production.DeepCopyInto(&out.Production)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionSpec.
+func (in *PromotionSpec) DeepCopy() *PromotionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStage) DeepCopyInto(out *PromotionStage) {
+	*out = *in
+	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStage.
+func (in *PromotionStage) DeepCopy() *PromotionStage {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStatus) DeepCopyInto(out *PromotionStatus) {
+	*out = *in
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStatus.
+func (in *PromotionStatus) DeepCopy() *PromotionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneKind) DeepCopyInto(out *PruneKind) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneKind.
+func (in *PruneKind) DeepCopy() *PruneKind {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrant) DeepCopyInto(out *ReferenceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrant.
+func (in *ReferenceGrant) DeepCopy() *ReferenceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantFrom) DeepCopyInto(out *ReferenceGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantFrom.
+func (in *ReferenceGrantFrom) DeepCopy() *ReferenceGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantList) DeepCopyInto(out *ReferenceGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReferenceGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantList.
+func (in *ReferenceGrantList) DeepCopy() *ReferenceGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReferenceGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantSpec) DeepCopyInto(out *ReferenceGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]ReferenceGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]ReferenceGrantTo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantSpec.
+func (in *ReferenceGrantSpec) DeepCopy() *ReferenceGrantSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfileList)
+	out := new(ReferenceGrantSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProfileList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantTo) DeepCopyInto(out *ReferenceGrantTo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantTo.
+func (in *ReferenceGrantTo) DeepCopy() *ReferenceGrantTo {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ReferenceGrantTo)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileResource) DeepCopyInto(out *ProfileResource) {
+func (in *ReferenceValidationError) DeepCopyInto(out *ReferenceValidationError) {
 	*out = *in
-	if in.Features != nil {
-		in, out := &in.Features, &out.Features
-		*out = make([]Feature, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileResource.
-func (in *ProfileResource) DeepCopy() *ProfileResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceValidationError.
+func (in *ReferenceValidationError) DeepCopy() *ReferenceValidationError {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfileResource)
+	out := new(ReferenceValidationError)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -846,12 +1806,17 @@ func (in *RegistryCredentialsConfig) DeepCopyInto(out *RegistryCredentialsConfig
 	*out = *in
 	if in.CredentialsSecretRef != nil {
 		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	if in.CASecretRef != nil {
 		in, out := &in.CASecretRef, &out.CASecretRef
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.CertSecretRef != nil {
+		in, out := &in.CertSecretRef, &out.CertSecretRef
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 }
@@ -881,6 +1846,21 @@ func (in *ReleaseReport) DeepCopy() *ReleaseReport {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedImage) DeepCopyInto(out *ResolvedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedImage.
+func (in *ResolvedImage) DeepCopy() *ResolvedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resource) DeepCopyInto(out *Resource) {
 	*out = *in
@@ -917,13 +1897,117 @@ func (in *ResourceReport) DeepCopy() *ResourceReport {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutProgress) DeepCopyInto(out *RolloutProgress) {
+	*out = *in
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutProgress.
+func (in *RolloutProgress) DeepCopy() *RolloutProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SealedSecretsCertificateRef) DeepCopyInto(out *SealedSecretsCertificateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SealedSecretsCertificateRef.
+func (in *SealedSecretsCertificateRef) DeepCopy() *SealedSecretsCertificateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SealedSecretsCertificateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotationHook) DeepCopyInto(out *SecretRotationHook) {
+	*out = *in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotationHook.
+func (in *SecretRotationHook) DeepCopy() *SecretRotationHook {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotationHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretStoreRef) DeepCopyInto(out *SecretStoreRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreRef.
+func (in *SecretStoreRef) DeepCopy() *SecretStoreRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretStoreRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretTransformer) DeepCopyInto(out *SecretTransformer) {
+	*out = *in
+	if in.SecretStoreRef != nil {
+		in, out := &in.SecretStoreRef, &out.SecretStoreRef
+		*out = new(SecretStoreRef)
+		**out = **in
+	}
+	if in.CertificateRef != nil {
+		in, out := &in.CertificateRef, &out.CertificateRef
+		*out = new(SealedSecretsCertificateRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformer.
+func (in *SecretTransformer) DeepCopy() *SecretTransformer {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTransformer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Spec) DeepCopyInto(out *Spec) {
 	*out = *in
 	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
 	if in.ClusterRefs != nil {
 		in, out := &in.ClusterRefs, &out.ClusterRefs
-		*out = make([]v1.ObjectReference, len(*in))
+		*out = make([]corev1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.SetRefs != nil {
@@ -941,14 +2025,70 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 		*out = make([]TemplateResourceRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]Variable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.DependsOn != nil {
 		in, out := &in.DependsOn, &out.DependsOn
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeploymentOrder != nil {
+		in, out := &in.DeploymentOrder, &out.DeploymentOrder
+		*out = make([]FeatureID, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheckGates != nil {
+		in, out := &in.HealthCheckGates, &out.HealthCheckGates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WriteBudget != nil {
+		in, out := &in.WriteBudget, &out.WriteBudget
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ErrorBudget != nil {
+		in, out := &in.ErrorBudget, &out.ErrorBudget
+		*out = new(ErrorBudget)
+		**out = **in
+	}
+	if in.MatchExpansionGuard != nil {
+		in, out := &in.MatchExpansionGuard, &out.MatchExpansionGuard
+		*out = new(MatchExpansionGuard)
+		**out = **in
+	}
+	if in.SecretTransformer != nil {
+		in, out := &in.SecretTransformer, &out.SecretTransformer
+		*out = new(SecretTransformer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageDigestResolution != nil {
+		in, out := &in.ImageDigestResolution, &out.ImageDigestResolution
+		*out = new(ImageDigestResolution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WasmPlugins != nil {
+		in, out := &in.WasmPlugins, &out.WasmPlugins
+		*out = make([]WasmPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PolicyRefs != nil {
 		in, out := &in.PolicyRefs, &out.PolicyRefs
 		*out = make([]PolicyRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InlineResources != nil {
+		in, out := &in.InlineResources, &out.InlineResources
+		*out = make([]InlineResource, len(*in))
 		copy(*out, *in)
 	}
 	if in.HelmCharts != nil {
@@ -965,6 +2105,25 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]JobRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretRotationHooks != nil {
+		in, out := &in.SecretRotationHooks, &out.SecretRotationHooks
+		*out = make([]SecretRotationHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]Extension, len(*in))
+		copy(*out, *in)
+	}
 	if in.ValidateHealths != nil {
 		in, out := &in.ValidateHealths, &out.ValidateHealths
 		*out = make([]ValidateHealth, len(*in))
@@ -1017,11 +2176,33 @@ func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
 	if in.MatchingClusterRefs != nil {
 		in, out := &in.MatchingClusterRefs, &out.MatchingClusterRefs
-		*out = make([]v1.ObjectReference, len(*in))
+		*out = make([]corev1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	in.UpdatingClusters.DeepCopyInto(&out.UpdatingClusters)
 	in.UpdatedClusters.DeepCopyInto(&out.UpdatedClusters)
+	if in.ReferenceValidationErrors != nil {
+		in, out := &in.ReferenceValidationErrors, &out.ReferenceValidationErrors
+		*out = make([]ReferenceValidationError, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigratedClusterRefs != nil {
+		in, out := &in.MigratedClusterRefs, &out.MigratedClusterRefs
+		*out = make([]corev1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
@@ -1050,6 +2231,21 @@ func (in *TemplateResourceRef) DeepCopy() *TemplateResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TierStatus) DeepCopyInto(out *TierStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TierStatus.
+func (in *TierStatus) DeepCopy() *TierStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TierStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValidateHealth) DeepCopyInto(out *ValidateHealth) {
 	*out = *in
@@ -1084,3 +2280,123 @@ func (in *ValueFrom) DeepCopy() *ValueFrom {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesPresetRef) DeepCopyInto(out *ValuesPresetRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesPresetRef.
+func (in *ValuesPresetRef) DeepCopy() *ValuesPresetRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesPresetRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Variable) DeepCopyInto(out *Variable) {
+	*out = *in
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Variable.
+func (in *Variable) DeepCopy() *Variable {
+	if in == nil {
+		return nil
+	}
+	out := new(Variable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionPolicy) DeepCopyInto(out *VersionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionPolicy.
+func (in *VersionPolicy) DeepCopy() *VersionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WasmModuleConfigMapRef) DeepCopyInto(out *WasmModuleConfigMapRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WasmModuleConfigMapRef.
+func (in *WasmModuleConfigMapRef) DeepCopy() *WasmModuleConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(WasmModuleConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WasmPlugin) DeepCopyInto(out *WasmPlugin) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(WasmModuleConfigMapRef)
+		**out = **in
+	}
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WasmPlugin.
+func (in *WasmPlugin) DeepCopy() *WasmPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(WasmPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatcherStatus) DeepCopyInto(out *WatcherStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatcherStatus.
+func (in *WatcherStatus) DeepCopy() *WatcherStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WatcherStatus)
+	in.DeepCopyInto(out)
+	return out
+}
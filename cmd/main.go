@@ -56,6 +56,7 @@ import (
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/api/v1beta1/index"
 	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/crd"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
@@ -65,25 +66,65 @@ import (
 )
 
 var (
-	setupLog                = ctrl.Log.WithName("setup")
-	diagnosticsAddress      string
-	insecureDiagnostics     bool
-	shardKey                string
-	workers                 int
-	concurrentReconciles    int
-	agentInMgmtCluster      bool
-	reportMode              controllers.ReportMode
-	tmpReportMode           int
-	restConfigQPS           float32
-	restConfigBurst         int
-	webhookPort             int
-	syncPeriod              time.Duration
-	conflictRetryTime       time.Duration
-	version                 string
-	healthAddr              string
-	profilerAddress         string
-	driftDetectionConfigMap string
-	disableCaching          bool
+	setupLog                 = ctrl.Log.WithName("setup")
+	diagnosticsAddress       string
+	insecureDiagnostics      bool
+	shardKey                 string
+	workers                  int
+	concurrentReconciles     int
+	agentInMgmtCluster       bool
+	reportMode               controllers.ReportMode
+	tmpReportMode            int
+	restConfigQPS            float32
+	restConfigBurst          int
+	webhookPort              int
+	syncPeriod               time.Duration
+	conflictRetryTime        time.Duration
+	chartVersionPollInterval time.Duration
+	referenceLintInterval    time.Duration
+	profileWebhook           bool
+	tenantIsolation          bool
+	syncSLOWindow            time.Duration
+	tierOrderedDeployment    bool
+	preflightChecks          bool
+	observeOnly              bool
+	migrationVersion         string
+	clusterReportHistory     int
+	compressionThreshold     int
+	controllerStatusInterval time.Duration
+	kubeconfigCacheTTL       time.Duration
+	helmCacheDir             string
+	helmCacheSize            int
+	registryMirrors          string
+	version                  string
+	healthAddr               string
+	profilerAddress          string
+	driftDetectionConfigMap  string
+	disableCaching           bool
+	commitStatusProvider     string
+	commitStatusAPIURL       string
+	commitStatusSecret       string
+	remediationThreshold     uint
+	remediationConfigMap     string
+	remediationWebhookURL    string
+	externalMatcherURL       string
+	deployerResultStore      string
+	featureWorkers           string
+	tenantFairness           bool
+	tenantWeights            string
+	federationName           string
+	federationPeerSecret     string
+	federationInterval       time.Duration
+	fluxTakeover             bool
+	clusterInventory         bool
+	relabelInventory         bool
+	extensionPlugins         map[string]string
+	maxProfileResources      int
+	maxProfileRenderedSize   int
+	forbiddenKinds           string
+	forbidClusterAdmin       bool
+	clusterSnapshotInterval  time.Duration
+	clusterSnapshotRetention time.Duration
 )
 
 const (
@@ -142,6 +183,12 @@ func main() {
 		PprofBindAddress: profilerAddress,
 	}
 
+	if federationPeerSecret != "" && federationName == "" {
+		setupLog.Error(fmt.Errorf("federation-name is required when federation-peer-secret is set"),
+			"invalid federation configuration")
+		os.Exit(1)
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	restConfig.QPS = restConfigQPS
 	restConfig.Burst = restConfigBurst
@@ -155,7 +202,71 @@ func main() {
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 	controllers.SetManagementClusterAccess(mgr.GetClient(), mgr.GetConfig())
+	controllers.SetEventRecorder(mgr.GetEventRecorderFor("addon-controller"))
 	controllers.SetDriftdetectionConfigMap(driftDetectionConfigMap)
+	controllers.SetCommitStatusProvider(controllers.CommitStatusProvider(commitStatusProvider),
+		commitStatusAPIURL, commitStatusSecret)
+	controllers.SetRemediationHook(uint32(remediationThreshold), remediationConfigMap, remediationWebhookURL)
+	controllers.SetExternalMatcher(externalMatcherURL)
+	controllers.SetExtensionPlugins(extensionPlugins)
+	controllers.SetTenantNamespaceIsolation(tenantIsolation)
+	controllers.SetSyncSLOWindow(syncSLOWindow)
+	controllers.SetTierOrderedDeployment(tierOrderedDeployment)
+	controllers.SetPreflightChecks(preflightChecks)
+	forbidden, err := controllers.ParseForbiddenKinds(forbiddenKinds)
+	if err != nil {
+		setupLog.Error(err, "invalid forbidden-kinds")
+		os.Exit(1)
+	}
+	controllers.SetGuardrails(controllers.Guardrails{
+		MaxResources:               maxProfileResources,
+		MaxRenderedSize:            maxProfileRenderedSize,
+		ForbiddenKinds:             forbidden,
+		ForbidClusterAdminBindings: forbidClusterAdmin,
+	})
+	mirrors, err := controllers.ParseRegistryMirrors(registryMirrors)
+	if err != nil {
+		setupLog.Error(err, "invalid registry-mirrors")
+		os.Exit(1)
+	}
+	controllers.SetRegistryMirrors(mirrors)
+	controllers.SetObserveOnly(observeOnly)
+	controllers.SetMigrationVersion(migrationVersion)
+	controllers.SetClusterReportHistory(clusterReportHistory)
+	controllers.SetStatusCompressionThreshold(compressionThreshold)
+	secretprovider.SetCacheTTL(kubeconfigCacheTTL)
+	const bytesPerMiB = 1024 * 1024
+	if err := controllers.SetHelmChartCache(helmCacheDir, int64(helmCacheSize)*bytesPerMiB,
+		ctrl.Log.WithName("helm-chart-cache")); err != nil {
+		setupLog.Error(err, "unable to set up helm chart cache")
+		os.Exit(1)
+	}
+
+	// The cluster resync endpoint modifies ClusterSummaries while the profile diff and cluster report
+	// endpoints expose rendered content, so those are only served when diagnostics endpoint requires
+	// authentication/authorization.
+	if !insecureDiagnostics {
+		if err := mgr.AddMetricsServerExtraHandler(controllers.ProfileDiffPath,
+			controllers.NewProfileDiffHandler(mgr.GetClient(), ctrl.Log.WithName("profile-diff"))); err != nil {
+			setupLog.Error(err, "unable to add profile diff handler")
+			os.Exit(1)
+		}
+		if err := mgr.AddMetricsServerExtraHandler(controllers.ClusterReportPath,
+			controllers.NewClusterReportHandler(mgr.GetClient(), ctrl.Log.WithName("cluster-report"))); err != nil {
+			setupLog.Error(err, "unable to add cluster report handler")
+			os.Exit(1)
+		}
+		if err := mgr.AddMetricsServerExtraHandler(controllers.ProfileSimulationPath,
+			controllers.NewProfileSimulationHandler(mgr.GetClient(), ctrl.Log.WithName("profile-simulation"))); err != nil {
+			setupLog.Error(err, "unable to add profile simulation handler")
+			os.Exit(1)
+		}
+		if err := mgr.AddMetricsServerExtraHandler(controllers.ClusterResyncPath,
+			controllers.NewClusterResyncHandler(mgr.GetClient(), ctrl.Log.WithName("cluster-resync"))); err != nil {
+			setupLog.Error(err, "unable to add cluster resync handler")
+			os.Exit(1)
+		}
+	}
 
 	logsettings.RegisterForLogSettings(ctx,
 		libsveltosv1beta1.ComponentAddonManager, ctrl.Log.WithName("log-setter"),
@@ -199,6 +310,10 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&shardKey, "shard-key", "",
 		"If set, only clusters will annotation matching this shard key will be reconciled by this deployment")
 
+	fs.StringVar(&migrationVersion, "migration-version", "",
+		"If set, only clusters with the projectsveltos.io/addon-controller-version annotation matching this value will be reconciled by this deployment. "+
+			"If not set, only clusters without such annotation will be reconciled. Used to run two addon-controller versions side by side")
+
 	fs.IntVar(&workers, "worker-number", defaultWorkers,
 		"Number of worker. Workers are used to deploy features in CAPI clusters")
 
@@ -216,6 +331,69 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&driftDetectionConfigMap, "drift-detection-config", "",
 		"The name of the ConfigMap in the projectsveltos namespace containing the drift-detection-manager configuration")
 
+	fs.StringVar(&commitStatusProvider, "commit-status-provider", "",
+		"Git provider (github or gitlab) rollout results are reported to for profiles annotated with projectsveltos.io/commit")
+
+	fs.StringVar(&commitStatusAPIURL, "commit-status-api-url", "",
+		"Base URL of the Git provider API. Defaults to the public GitHub/GitLab API")
+
+	fs.StringVar(&commitStatusSecret, "commit-status-secret", "",
+		"The name of the Secret in the projectsveltos namespace containing, in the token key, the Git provider API token")
+
+	fs.UintVar(&remediationThreshold, "remediation-failure-threshold", 0,
+		"Number of consecutive failures of a feature on a cluster after which the remediation hook is invoked. Set to 0 to disable")
+
+	fs.StringVar(&remediationConfigMap, "remediation-job-config", "",
+		"The name of the ConfigMap in the projectsveltos namespace containing, in the job key, the template of the Job created in the management cluster on chronic failures")
+
+	fs.StringVar(&remediationWebhookURL, "remediation-webhook-url", "",
+		"URL receiving a POST request, with the failure context as JSON payload, on chronic failures")
+
+	fs.StringVar(&externalMatcherURL, "external-matcher-url", "",
+		"HTTPS endpoint receiving a POST request, with the cluster metadata and the profile as JSON payload, for every cluster matching a ClusterProfile/Profile. The endpoint replies with {\"match\": true|false}. Leave empty to disable")
+
+	fs.StringVar(&featureWorkers, "feature-workers", "",
+		"Comma separated list of <feature>=<workers>[:<timeout>] (for instance Helm=5:10m,Kustomize=10) limiting how many of the worker-number workers process requests for Helm, Kustomize and Resources features at the same time. The timeout, if set, is the maximum time a request for the feature can take")
+
+	fs.BoolVar(&tenantFairness, "tenant-fairness", false,
+		"When set, the worker-number workers are shared across the cluster namespaces with pending requests, so a tenant with many or large Profiles cannot monopolize the workers")
+
+	fs.StringVar(&tenantWeights, "tenant-weights", "",
+		"Comma separated list of <namespace>=<weight> (for instance team-a=3,team-b=1). With tenant-fairness, the share of workers of a namespace is proportional to its weight. Namespaces not listed have weight 1")
+
+	fs.StringVar(&deployerResultStore, "deployer-result-store", "",
+		"URL (redis://<user>:<password>@<host>:<port>/<db>) of the Redis server deployment results are persisted to, so they survive restarts and are shared across shards. Password can also be set with the DEPLOYER_RESULT_STORE_PASSWORD environment variable")
+
+	fs.StringVar(&federationPeerSecret, "federation-peer-secret", "",
+		"The name of the Secret in the projectsveltos namespace containing, in the kubeconfig key, the kubeconfig of the peer management cluster ClusterProfiles/Profiles matching and rollout status is published to. When not set, federation is disabled")
+
+	fs.StringVar(&federationName, "federation-name", "",
+		"Name identifying this management cluster in the federation peer. Required when federation-peer-secret is set")
+
+	const defaultFederationInterval = 60
+	fs.DurationVar(&federationInterval, "federation-interval", defaultFederationInterval*time.Second,
+		fmt.Sprintf("The interval at which status is published to the federation peer. Default: %d seconds",
+			defaultFederationInterval))
+
+	fs.DurationVar(&clusterSnapshotInterval, "cluster-snapshot-interval", 0,
+		"The interval at which labels and Kubernetes version of all clusters are recorded, so ClusterProfiles/Profiles can be simulated against clusters as they were in the past. Set to 0 to disable")
+
+	const defaultClusterSnapshotRetention = 30 * 24
+	fs.DurationVar(&clusterSnapshotRetention, "cluster-snapshot-retention", defaultClusterSnapshotRetention*time.Hour,
+		fmt.Sprintf("How long cluster snapshots are kept. Default: %d hours", defaultClusterSnapshotRetention))
+
+	fs.StringToStringVar(&extensionPlugins, "extension-plugins", map[string]string{},
+		"Out-of-tree plugins extensions are dispatched to, as <kind>=<gRPC address> (e.g. db-migration=unix:///plugins/db.sock)")
+
+	fs.BoolVar(&fluxTakeover, "flux-takeover", false,
+		"When set, Flux Kustomizations/HelmReleases annotated with projectsveltos.io/takeover are converted to ClusterProfiles")
+
+	fs.BoolVar(&relabelInventory, "relabel-inventory", false,
+		"When set, at startup resources previously deployed in managed clusters are labeled with projectsveltos.io/inventory without being redeployed")
+
+	fs.BoolVar(&clusterInventory, "cluster-inventory", false,
+		"When set, CAPI Clusters are labeled with node count, instance types and GPU node pools derived from their MachineDeployments/MachinePools")
+
 	const defautlRestConfigQPS = 20
 	fs.Float32Var(&restConfigQPS, "kube-api-qps", defautlRestConfigQPS,
 		fmt.Sprintf("Maximum queries per second from the controller client to the Kubernetes API server. Defaults to %d",
@@ -239,6 +417,73 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&conflictRetryTime, "conflict-retry-time", defaultConflictRetryTime*time.Second,
 		fmt.Sprintf("The minimum interval at which watched ClusterProfile with conflicts are retried. Defaul: %d seconds",
 			defaultConflictRetryTime))
+
+	const defaultChartVersionPollInterval = 10
+	fs.DurationVar(&chartVersionPollInterval, "chart-version-poll-interval", defaultChartVersionPollInterval*time.Minute,
+		fmt.Sprintf("The interval at which repositories of helm charts with a SemverRange/Latest VersionPolicy are polled for new versions. Default: %d minutes",
+			defaultChartVersionPollInterval))
+
+	const defaultReferenceLintInterval = 5
+	fs.DurationVar(&referenceLintInterval, "reference-lint-interval", defaultReferenceLintInterval*time.Minute,
+		fmt.Sprintf("The interval at which content of ConfigMaps/Secrets referenced by ClusterProfiles/Profiles is validated. Set to 0 to disable. Default: %d minutes",
+			defaultReferenceLintInterval))
+
+	fs.BoolVar(&tenantIsolation, "tenant-namespace-isolation", false,
+		"When set, resources deployed by Profiles are confined, in the managed clusters, to namespaces prefixed with the Profile namespace and a dash (dashes in the Profile namespace are doubled, so namespace nginx of Profile namespace team-a is team--a-nginx)")
+
+	fs.BoolVar(&profileWebhook, "profile-webhook", false,
+		"When set, the Profile and ClusterProfile validating webhooks are served. Those reject at admission what the reconcilers otherwise report in the SpecInvalid condition (for instance invalid inline resources, guardrails or variables and cross-namespace references not granted by a ReferenceGrant) or in the DeletionBlocked condition (unconfirmed deletions with DeletionProtection). Webhook configuration is not part of the default install")
+
+	fs.DurationVar(&syncSLOWindow, "sync-slo-window", 0,
+		"When set, ClusterSummaries not successfully synced within this window are reported with the SyncStale condition. Set to 0 to disable")
+
+	fs.BoolVar(&tierOrderedDeployment, "tier-ordered-deployment", false,
+		"When set, profiles matching a cluster are deployed there the first time in Tier order: a profile is deployed only once all profiles with a lower Tier are provisioned")
+
+	fs.BoolVar(&preflightChecks, "preflight-checks", false,
+		"When set, before deploying to a cluster, its API server reachability and the permissions needed are verified. Failures are reported with the ClusterSummary PreflightFailed condition")
+
+	fs.IntVar(&maxProfileResources, "max-profile-resources", 0,
+		"Maximum number of resources a ClusterProfile/Profile can deploy with the Resources feature, the Kustomize feature and each helm release. Set to 0 to disable")
+
+	fs.IntVar(&maxProfileRenderedSize, "max-profile-rendered-size", 0,
+		"Maximum size, in bytes, of the resources a ClusterProfile/Profile can deploy with the Resources feature, the Kustomize feature and each helm release. Set to 0 to disable")
+
+	fs.StringVar(&forbiddenKinds, "forbidden-kinds", "",
+		"Comma separated list of <Kind>.<group> (or <Kind> for the core group), for instance ClusterRoleBinding.rbac.authorization.k8s.io,Namespace, ClusterProfiles/Profiles cannot deploy")
+
+	fs.BoolVar(&forbidClusterAdmin, "forbid-cluster-admin-bindings", false,
+		"When set, ClusterProfiles/Profiles cannot deploy ClusterRoleBindings/RoleBindings to the cluster-admin ClusterRole")
+
+	fs.BoolVar(&observeOnly, "observe-only", false,
+		"When set, the controller computes matching clusters and renders content but never applies anything to managed clusters: every ClusterSummary is processed as if its SyncMode was DryRun")
+
+	fs.IntVar(&clusterReportHistory, "cluster-report-history", 0,
+		"Number of previous DryRun reports kept in each ClusterReport. Set to 0 to disable")
+
+	fs.IntVar(&compressionThreshold, "status-compression-threshold", 0,
+		"Size, in bytes, above which ClusterReport messages and ClusterConfiguration resource lists are stored gzipped and base64 encoded. Set to 0 to disable")
+
+	const defaultControllerStatusInterval = 30
+	fs.DurationVar(&controllerStatusInterval, "controller-status-interval", defaultControllerStatusInterval*time.Second,
+		fmt.Sprintf("The interval at which the ControllerStatus of this shard is updated with deployer queue and watchers health. Set to 0 to disable. Default: %d seconds",
+			defaultControllerStatusInterval))
+
+	const defaultKubeconfigCacheTTL = 300
+	fs.DurationVar(&kubeconfigCacheTTL, "kubeconfig-provider-cache-ttl", defaultKubeconfigCacheTTL*time.Second,
+		fmt.Sprintf("For how long kubeconfigs fetched from a secret provider (SveltosClusters with the projectsveltos.io/kubeconfig-provider annotation) are cached. Set to 0 to disable caching. Default: %d seconds",
+			defaultKubeconfigCacheTTL))
+
+	fs.StringVar(&helmCacheDir, "helm-cache-dir", "",
+		"Directory where helm chart archives are cached, so all ClusterSummaries deploying the same chart version reuse them instead of downloading them again. Leave empty to disable the cache")
+
+	const defaultHelmCacheSize = 1024
+	fs.IntVar(&helmCacheSize, "helm-cache-size", defaultHelmCacheSize,
+		fmt.Sprintf("Maximum size, in MiB, of the helm chart cache. Least recently used charts are removed when the limit is exceeded. Default: %d MiB",
+			defaultHelmCacheSize))
+
+	fs.StringVar(&registryMirrors, "registry-mirrors", "",
+		"Comma separated list of <source>=<mirror>, for instance docker.io=registry.internal/docker.io,charts.bitnami.com/bitnami=https://charts.internal/bitnami. Helm charts and OCI artifacts are pulled from the mirror of the longest matching source")
 }
 
 func setupIndexes(ctx context.Context, mgr ctrl.Manager) {
@@ -308,6 +553,62 @@ func isFluxInstalled(ctx context.Context, c client.Client) (bool, error) {
 	return true, nil
 }
 
+// isCRDInstalled returns true if the CRD with given name is installed, false otherwise
+func isCRDInstalled(ctx context.Context, c client.Reader, crdName string) (bool, error) {
+	customResourceDefinition := &apiextensionsv1.CustomResourceDefinition{}
+
+	err := c.Get(ctx, types.NamespacedName{Name: crdName}, customResourceDefinition)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// startFluxTakeover starts a FluxTakeoverReconciler for each Flux Kustomization/HelmRelease
+// CRD present in the management cluster
+func startFluxTakeover(ctx context.Context, mgr ctrl.Manager, logger logr.Logger) {
+	crds := map[string]schema.GroupVersionKind{
+		"kustomizations.kustomize.toolkit.fluxcd.io": controllers.FluxKustomizationGVK,
+		"helmreleases.helm.toolkit.fluxcd.io":        controllers.FluxHelmReleaseGVK,
+	}
+
+	const maxRetries = 20
+	for crdName, gvk := range crds {
+		for retries := 0; ; retries++ {
+			present, err := isCRDInstalled(ctx, mgr.GetAPIReader(), crdName)
+			if err != nil {
+				if retries < maxRetries {
+					logger.Info(fmt.Sprintf("failed to verify if %s is present: %v", crdName, err))
+					time.Sleep(time.Second)
+					continue
+				}
+				break
+			}
+			if !present {
+				logger.V(logsettings.LogInfo).Info(fmt.Sprintf("%s not present. Flux takeover disabled for %s",
+					crdName, gvk.Kind))
+				break
+			}
+
+			takeoverReconciler := &controllers.FluxTakeoverReconciler{
+				Client:               mgr.GetClient(),
+				Scheme:               mgr.GetScheme(),
+				ConcurrentReconciles: concurrentReconciles,
+				Logger:               ctrl.Log.WithName("fluxtakeoverreconciler"),
+				GVK:                  gvk,
+			}
+			if err := takeoverReconciler.SetupWithManager(mgr); err != nil {
+				logger.Error(err, "unable to create controller", "controller", fmt.Sprintf("fluxtakeover-%s", gvk.Kind))
+			}
+			break
+		}
+	}
+}
+
 func capiWatchers(ctx context.Context, mgr ctrl.Manager, watchersForCAPI []watcherForCAPI, logger logr.Logger) {
 	const maxRetries = 20
 	retries := 0
@@ -417,24 +718,59 @@ func getClusterProfileReconciler(mgr manager.Manager) *controllers.ClusterProfil
 }
 
 func getClusterSummaryReconciler(ctx context.Context, mgr manager.Manager) *controllers.ClusterSummaryReconciler {
-	d := deployer.GetClient(ctx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers)
+	var d deployer.DeployerInterface = deployer.GetClient(ctx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers)
 	controllers.RegisterFeatures(d, setupLog)
 
+	pools, err := controllers.ParseFeatureWorkerPools(featureWorkers)
+	if err != nil {
+		setupLog.Error(err, "invalid feature-workers")
+		os.Exit(1)
+	}
+	var fairness *controllers.TenantFairness
+	if tenantFairness {
+		weights, err := controllers.ParseTenantWeights(tenantWeights)
+		if err != nil {
+			setupLog.Error(err, "invalid tenant-weights")
+			os.Exit(1)
+		}
+		fairness = &controllers.TenantFairness{Workers: workers, Weights: weights}
+	}
+	if len(pools) > 0 || fairness != nil {
+		d = controllers.NewFeaturePoolDeployer(d, pools, fairness, ctrl.Log.WithName("feature-workers"))
+	}
+
+	if deployerResultStore != "" {
+		store, err := controllers.NewRedisResultStore(ctx, deployerResultStore)
+		if err != nil {
+			setupLog.Error(err, "unable to connect to deployer result store")
+			os.Exit(1)
+		}
+		d = controllers.NewPersistentDeployer(d, store, ctrl.Log.WithName("deployer-result-store"))
+	}
+
+	if controllerStatusInterval > 0 {
+		tracker := controllers.NewTrackingDeployer(d)
+		go controllers.UpdateControllerStatus(ctx, mgr.GetClient(), controllers.GetControllerStatusName(shardKey),
+			controllerStatusInterval, tracker, ctrl.Log.WithName("controller-status"))
+		d = tracker
+	}
+
 	return &controllers.ClusterSummaryReconciler{
-		Config:               mgr.GetConfig(),
-		Client:               mgr.GetClient(),
-		Scheme:               mgr.GetScheme(),
-		ShardKey:             shardKey,
-		Version:              version,
-		ReportMode:           reportMode,
-		AgentInMgmtCluster:   agentInMgmtCluster,
-		Deployer:             d,
-		ClusterMap:           make(map[corev1.ObjectReference]*libsveltosset.Set),
-		ReferenceMap:         make(map[corev1.ObjectReference]*libsveltosset.Set),
-		PolicyMux:            sync.Mutex{},
-		ConcurrentReconciles: concurrentReconciles,
-		ConflictRetryTime:    conflictRetryTime,
-		Logger:               ctrl.Log.WithName("clustersummaryreconciler"),
+		Config:                   mgr.GetConfig(),
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		ShardKey:                 shardKey,
+		Version:                  version,
+		ReportMode:               reportMode,
+		AgentInMgmtCluster:       agentInMgmtCluster,
+		Deployer:                 d,
+		ClusterMap:               make(map[corev1.ObjectReference]*libsveltosset.Set),
+		ReferenceMap:             make(map[corev1.ObjectReference]*libsveltosset.Set),
+		PolicyMux:                sync.Mutex{},
+		ConcurrentReconciles:     concurrentReconciles,
+		ConflictRetryTime:        conflictRetryTime,
+		ChartVersionPollInterval: chartVersionPollInterval,
+		Logger:                   ctrl.Log.WithName("clustersummaryreconciler"),
 	}
 }
 
@@ -452,6 +788,33 @@ func getSetReconciler(mgr manager.Manager) *controllers.SetReconciler {
 	}
 }
 
+func getClusterReportReconciler(mgr manager.Manager) *controllers.ClusterReportReconciler {
+	return &controllers.ClusterReportReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConcurrentReconciles: concurrentReconciles,
+		Logger:               ctrl.Log.WithName("clusterreportreconciler"),
+	}
+}
+
+func getPromotionReconciler(mgr manager.Manager) *controllers.PromotionReconciler {
+	return &controllers.PromotionReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConcurrentReconciles: concurrentReconciles,
+		Logger:               ctrl.Log.WithName("promotionreconciler"),
+	}
+}
+
+func getClusterInventoryReconciler(mgr manager.Manager) *controllers.ClusterInventoryReconciler {
+	return &controllers.ClusterInventoryReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConcurrentReconciles: concurrentReconciles,
+		Logger:               ctrl.Log.WithName("clusterinventoryreconciler"),
+	}
+}
+
 func getClusterSetReconciler(mgr manager.Manager) *controllers.ClusterSetReconciler {
 	return &controllers.ClusterSetReconciler{
 		Client:               mgr.GetClient(),
@@ -517,10 +880,12 @@ func startControllersAndWatchers(ctx context.Context, mgr manager.Manager) {
 	watchersForCAPI := make([]watcherForCAPI, 0)
 	watchersForFlux := make([]watcherForFlux, 0)
 
-	if shardKey == "" {
+	if shardKey == "" && migrationVersion == "" {
 		// Only if shardKey is not set, start ClusterProfile/Profile and ClusterSet/Set reconcilers.
 		// When shardKey is set, only ClusterSummary reconciler will be started and only
-		// cluster matching the shardkey will be managed
+		// cluster matching the shardkey will be managed.
+		// Same when migrationVersion is set: ClusterSummaries are created by the deployment running
+		// without migrationVersion, this deployment only reconciles the ones for clusters migrated to it.
 		clusterProfileReconciler = getClusterProfileReconciler(mgr)
 		err = clusterProfileReconciler.SetupWithManager(mgr)
 		if err != nil {
@@ -552,6 +917,61 @@ func startControllersAndWatchers(ctx context.Context, mgr manager.Manager) {
 			os.Exit(1)
 		}
 		watchersForCAPI = append(watchersForCAPI, setReconciler)
+
+		clusterReportReconciler := getClusterReportReconciler(mgr)
+		err = clusterReportReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", configv1beta1.ClusterReportKind)
+			os.Exit(1)
+		}
+
+		promotionReconciler := getPromotionReconciler(mgr)
+		err = promotionReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", configv1beta1.PromotionKind)
+			os.Exit(1)
+		}
+
+		if clusterInventory {
+			// Controller is created only once CAPI is detected
+			watchersForCAPI = append(watchersForCAPI, getClusterInventoryReconciler(mgr))
+		}
+
+		if profileWebhook {
+			profileValidator := &controllers.ProfileValidator{Client: mgr.GetClient()}
+			if err = profileValidator.SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", configv1beta1.ProfileKind)
+				os.Exit(1)
+			}
+			clusterProfileValidator := &controllers.ClusterProfileValidator{}
+			if err = clusterProfileValidator.SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", configv1beta1.ClusterProfileKind)
+				os.Exit(1)
+			}
+		}
+
+		if referenceLintInterval > 0 {
+			go controllers.LintReferencedResources(ctx, mgr.GetClient(), referenceLintInterval,
+				ctrl.Log.WithName("reference-linter"))
+		}
+
+		if relabelInventory {
+			go func() {
+				if mgr.GetCache().WaitForCacheSync(ctx) {
+					controllers.RelabelInventory(ctx, mgr.GetClient(), ctrl.Log.WithName("inventory-relabeler"))
+				}
+			}()
+		}
+
+		if clusterSnapshotInterval > 0 {
+			go controllers.RecordClusterSnapshots(ctx, mgr.GetClient(), clusterSnapshotInterval,
+				clusterSnapshotRetention, ctrl.Log.WithName("cluster-snapshots"))
+		}
+
+		if federationPeerSecret != "" {
+			go controllers.PublishToFederationPeer(ctx, mgr.GetClient(), federationName, federationPeerSecret,
+				federationInterval, ctrl.Log.WithName("federation-publisher"))
+		}
 	}
 
 	clusterSummaryReconciler := getClusterSummaryReconciler(ctx, mgr)
@@ -560,10 +980,38 @@ func startControllersAndWatchers(ctx context.Context, mgr manager.Manager) {
 		setupLog.Error(err, "unable to create controller", "controller", configv1beta1.ClusterSummaryKind)
 		os.Exit(1)
 	}
+
+	if err := controllers.RegisterHelmReleaseCollector(mgr.GetClient(),
+		ctrl.Log.WithName("helm-release-collector")); err != nil {
+		setupLog.Error(err, "unable to register helm release collector")
+		os.Exit(1)
+	}
+
+	if err := controllers.RegisterMissingReferenceCollector(mgr.GetClient(),
+		ctrl.Log.WithName("missing-reference-collector")); err != nil {
+		setupLog.Error(err, "unable to register missing reference collector")
+		os.Exit(1)
+	}
+
+	if err := controllers.RegisterLastSuccessfulSyncCollector(mgr.GetClient(),
+		ctrl.Log.WithName("last-successful-sync-collector")); err != nil {
+		setupLog.Error(err, "unable to register last successful sync collector")
+		os.Exit(1)
+	}
+
+	if err := controllers.RegisterVersionSkewCollector(mgr.GetClient(),
+		ctrl.Log.WithName("version-skew-collector")); err != nil {
+		setupLog.Error(err, "unable to register version skew collector")
+		os.Exit(1)
+	}
 	watchersForCAPI = append(watchersForCAPI, clusterSummaryReconciler)
 	watchersForFlux = append(watchersForFlux, clusterSummaryReconciler)
 
 	startWatchers(ctx, mgr, watchersForCAPI, watchersForFlux)
+
+	if fluxTakeover && shardKey == "" && migrationVersion == "" {
+		go startFluxTakeover(ctx, mgr, setupLog)
+	}
 }
 
 // printMemUsage memory stats. Call GC
@@ -0,0 +1,128 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// BreakGlassAnnotation can be set on a ClusterProfile/Profile to temporarily deploy its add-ons to one
+// cluster bypassing the cluster maintenance window, the HealthCheckGates and MaxUpdate.
+// It is meant for urgent changes, for instance security patches.
+// Value is a JSON object, for instance
+// {"cluster": "default/prod-eu", "clusterType": "Capi", "expires": "2024-06-01T18:00:00Z", "reason": "CVE-2024-1234"}
+// clusterType is optional (any cluster type matches when not set) while reason and expires are mandatory.
+// Once expires is reached the override is ignored. Every bypass is logged along with its reason.
+const BreakGlassAnnotation = "projectsveltos.io/break-glass"
+
+// breakGlass is the parsed value of BreakGlassAnnotation
+type breakGlass struct {
+	// Cluster is the cluster, in the form <namespace>/<name>, the override applies to
+	Cluster string `json:"cluster"`
+
+	// ClusterType is the type of the cluster. Any cluster type matches if not set
+	ClusterType libsveltosv1beta1.ClusterType `json:"clusterType,omitempty"`
+
+	// Expires is the time the override stops being honored
+	Expires metav1.Time `json:"expires"`
+
+	// Reason explains why the override is needed
+	Reason string `json:"reason"`
+}
+
+// parseBreakGlass parses the value of BreakGlassAnnotation
+func parseBreakGlass(value string) (*breakGlass, error) {
+	bg := &breakGlass{}
+	if err := json.Unmarshal([]byte(value), bg); err != nil {
+		return nil, fmt.Errorf("invalid break-glass override: %w", err)
+	}
+
+	if len(strings.Split(bg.Cluster, "/")) != 2 {
+		return nil, fmt.Errorf("invalid break-glass override: cluster %q is not in the form <namespace>/<name>",
+			bg.Cluster)
+	}
+	if bg.ClusterType != "" && bg.ClusterType != libsveltosv1beta1.ClusterTypeCapi &&
+		bg.ClusterType != libsveltosv1beta1.ClusterTypeSveltos {
+
+		return nil, fmt.Errorf("invalid break-glass override: unknown cluster type %q", bg.ClusterType)
+	}
+	if bg.Expires.IsZero() {
+		return nil, fmt.Errorf("invalid break-glass override: expires is mandatory")
+	}
+	if strings.TrimSpace(bg.Reason) == "" {
+		return nil, fmt.Errorf("invalid break-glass override: reason is mandatory")
+	}
+
+	return bg, nil
+}
+
+// getActiveBreakGlass returns the break-glass override, defined in annotations, for the cluster.
+// Returns nil if no override is defined for the cluster, if it is not valid or if it has expired.
+func getActiveBreakGlass(annotations map[string]string, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, now time.Time, logger logr.Logger) *breakGlass {
+
+	value, ok := annotations[BreakGlassAnnotation]
+	if !ok {
+		return nil
+	}
+
+	bg, err := parseBreakGlass(value)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(err.Error())
+		return nil
+	}
+
+	if bg.Cluster != fmt.Sprintf("%s/%s", clusterNamespace, clusterName) ||
+		(bg.ClusterType != "" && bg.ClusterType != clusterType) {
+
+		return nil
+	}
+
+	if !now.Before(bg.Expires.Time) {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("break-glass override expired at %s",
+			bg.Expires.Format(time.RFC3339)))
+		return nil
+	}
+
+	return bg
+}
+
+// recordBreakGlass logs that gate is being bypassed because of the break-glass override
+func recordBreakGlass(bg *breakGlass, gate string, logger logr.Logger) {
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("break-glass override: bypassing %s for cluster %s (expires %s). Reason: %s",
+		gate, bg.Cluster, bg.Expires.Format(time.RFC3339), bg.Reason))
+}
+
+// validateBreakGlass verifies BreakGlassAnnotation, if set, is valid
+func validateBreakGlass(annotations map[string]string) error {
+	value, ok := annotations[BreakGlassAnnotation]
+	if !ok {
+		return nil
+	}
+
+	_, err := parseBreakGlass(value)
+	return err
+}
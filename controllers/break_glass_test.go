@@ -0,0 +1,87 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/klog/v2/textlogger"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Break-glass override", func() {
+	var clusterNamespace, clusterName string
+
+	BeforeEach(func() {
+		clusterNamespace = randomString()
+		clusterName = randomString()
+	})
+
+	getAnnotations := func(value string) map[string]string {
+		return map[string]string{controllers.BreakGlassAnnotation: value}
+	}
+
+	It("validateBreakGlass requires cluster, expires and reason", func() {
+		Expect(controllers.ValidateBreakGlass(nil)).To(Succeed())
+
+		valid := fmt.Sprintf(`{"cluster": "%s/%s", "expires": "2024-06-01T18:00:00Z", "reason": "CVE"}`,
+			clusterNamespace, clusterName)
+		Expect(controllers.ValidateBreakGlass(getAnnotations(valid))).To(Succeed())
+
+		for _, value := range []string{
+			"not json",
+			fmt.Sprintf(`{"cluster": "%s", "expires": "2024-06-01T18:00:00Z", "reason": "CVE"}`, clusterName),
+			fmt.Sprintf(`{"cluster": "%s/%s", "reason": "CVE"}`, clusterNamespace, clusterName),
+			fmt.Sprintf(`{"cluster": "%s/%s", "expires": "2024-06-01T18:00:00Z"}`, clusterNamespace, clusterName),
+			fmt.Sprintf(`{"cluster": "%s/%s", "clusterType": "Unknown", "expires": "2024-06-01T18:00:00Z", "reason": "CVE"}`,
+				clusterNamespace, clusterName),
+		} {
+			Expect(controllers.ValidateBreakGlass(getAnnotations(value))).ToNot(Succeed(), value)
+		}
+	})
+
+	It("getActiveBreakGlass returns override only for the cluster and till it expires", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		expires := time.Now().Add(time.Hour)
+		annotations := getAnnotations(fmt.Sprintf(
+			`{"cluster": "%s/%s", "clusterType": "Capi", "expires": "%s", "reason": "CVE"}`,
+			clusterNamespace, clusterName, expires.UTC().Format(time.RFC3339)))
+
+		Expect(controllers.GetActiveBreakGlass(annotations, clusterNamespace, clusterName,
+			libsveltosv1beta1.ClusterTypeCapi, time.Now(), logger)).ToNot(BeNil())
+
+		// Different cluster
+		Expect(controllers.GetActiveBreakGlass(annotations, clusterNamespace, randomString(),
+			libsveltosv1beta1.ClusterTypeCapi, time.Now(), logger)).To(BeNil())
+		Expect(controllers.GetActiveBreakGlass(annotations, clusterNamespace, clusterName,
+			libsveltosv1beta1.ClusterTypeSveltos, time.Now(), logger)).To(BeNil())
+
+		// Expired
+		Expect(controllers.GetActiveBreakGlass(annotations, clusterNamespace, clusterName,
+			libsveltosv1beta1.ClusterTypeCapi, expires.Add(time.Minute), logger)).To(BeNil())
+
+		// No annotation
+		Expect(controllers.GetActiveBreakGlass(nil, clusterNamespace, clusterName,
+			libsveltosv1beta1.ClusterTypeCapi, time.Now(), logger)).To(BeNil())
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// isCertificateError returns true if err is caused by the managed cluster API server
+// certificate not being trusted. That is what happens, until kubeconfig is re-read,
+// after the managed cluster control plane certificates are rotated.
+func isCertificateError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var certificateInvalidError x509.CertificateInvalidError
+	var hostnameError x509.HostnameError
+	var verificationError *tls.CertificateVerificationError
+	if errors.As(err, &unknownAuthorityError) || errors.As(err, &certificateInvalidError) ||
+		errors.As(err, &hostnameError) || errors.As(err, &verificationError) {
+
+		return true
+	}
+
+	// Some libraries (helm, kubectl apply) flatten the error chain into a message
+	return strings.Contains(err.Error(), "x509: ")
+}
+
+// forgetClusterCredentials drops any cached kubeconfig/restConfig for the managed cluster,
+// so that next access re-reads the kubeconfig (Secret, CAPI kubeconfig or secret provider)
+// and rebuilds the clients.
+func forgetClusterCredentials(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) {
+
+	clustercache.GetManager().RemoveCluster(clusterNamespace, clusterName, clusterType)
+
+	uri, err := secretprovider.GetKubeconfigURI(ctx, c, clusterNamespace, clusterName, clusterType)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get kubeconfig provider: %v", err))
+		return
+	}
+	if uri != "" {
+		secretprovider.ForgetSecret(uri)
+	}
+}
+
+// retryOnCertificateError invokes fn. If fn fails because the managed cluster API server certificate
+// is not trusted, cached credentials for the cluster are dropped and fn is invoked one more time.
+func retryOnCertificateError(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger, fn func() error) error {
+
+	err := fn()
+	if !isCertificateError(err) {
+		return err
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("certificate of cluster API server not trusted (%v). "+
+		"Re-reading kubeconfig", err))
+	forgetClusterCredentials(ctx, c, clusterNamespace, clusterName, clusterType, logger)
+
+	return fn()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Certificate rotation", func() {
+	It("isCertificateError detects untrusted API server certificates", func() {
+		err := &url.Error{Op: "Get", URL: "https://10.0.0.1:6443", Err: x509.UnknownAuthorityError{}}
+		Expect(controllers.IsCertificateError(err)).To(BeTrue())
+		Expect(controllers.IsCertificateError(fmt.Errorf("failed to apply: %w", err))).To(BeTrue())
+		Expect(controllers.IsCertificateError(
+			errors.New("Kubernetes cluster unreachable: tls: failed to verify certificate: x509: " +
+				"certificate signed by unknown authority"))).To(BeTrue())
+
+		Expect(controllers.IsCertificateError(nil)).To(BeFalse())
+		Expect(controllers.IsCertificateError(errors.New("connection refused"))).To(BeFalse())
+	})
+
+	It("retryOnCertificateError drops cached credentials and retries once", func() {
+		clusterNamespace := randomString()
+		clusterName := randomString()
+		clusterType := libsveltosv1beta1.ClusterTypeCapi
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		cacheMgr := clustercache.GetManager()
+		cacheMgr.StoreRestConfig(clusterNamespace, clusterName, clusterType, &rest.Config{Host: randomString()})
+
+		certificateError := &url.Error{Op: "Get", URL: "https://10.0.0.1:6443", Err: x509.UnknownAuthorityError{}}
+
+		calls := 0
+		err := controllers.RetryOnCertificateError(context.TODO(), c, clusterNamespace, clusterName, clusterType,
+			logger, func() error {
+				calls++
+				if calls == 1 {
+					return certificateError
+				}
+				return nil
+			})
+		Expect(err).To(BeNil())
+		Expect(calls).To(Equal(2))
+
+		// Cached restConfig was dropped, so next access fails re-reading the (missing) kubeconfig
+		_, err = cacheMgr.GetKubernetesRestConfig(context.TODO(), c, clusterNamespace, clusterName, "", "",
+			clusterType, logger)
+		Expect(err).ToNot(BeNil())
+
+		// Other errors are not retried
+		calls = 0
+		err = controllers.RetryOnCertificateError(context.TODO(), c, clusterNamespace, clusterName, clusterType,
+			logger, func() error {
+				calls++
+				return errors.New("connection refused")
+			})
+		Expect(err).ToNot(BeNil())
+		Expect(calls).To(Equal(1))
+
+		// Retried only once
+		calls = 0
+		err = controllers.RetryOnCertificateError(context.TODO(), c, clusterNamespace, clusterName, clusterType,
+			logger, func() error {
+				calls++
+				return certificateError
+			})
+		Expect(controllers.IsCertificateError(err)).To(BeTrue())
+		Expect(calls).To(Equal(2))
+	})
+})
@@ -0,0 +1,149 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	// ChartVersionsAnnotation, set on a ClusterProfile/Profile, references a ConfigMap pinning the
+	// version of the helm charts the profile deploys. ConfigMap keys identify charts by repository URL
+	// and chart name (see getChartVersionsKey), values are chart versions. Helm charts without an explicit
+	// VersionPolicy whose key is present are deployed with the version in the ConfigMap instead of ChartVersion.
+	// Moving a chart to a new version across all profiles referencing the ConfigMap is a single update
+	// of the ConfigMap, subject to RBAC and admission. Reverting the ConfigMap rolls the update back.
+	// For a ClusterProfile the value is in the form namespace/name. A Profile can only reference a
+	// ConfigMap in its own namespace.
+	ChartVersionsAnnotation = "projectsveltos.io/chart-versions"
+)
+
+// getChartVersionsReference returns the ConfigMap pinning chart versions for clusterSummary.
+// Nil if the profile owning clusterSummary does not reference any.
+func getChartVersionsReference(clusterSummary *configv1beta1.ClusterSummary) (*corev1.ObjectReference, error) {
+	value := strings.TrimSpace(clusterSummary.Annotations[ChartVersionsAnnotation])
+	if value == "" {
+		return nil, nil
+	}
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, name := "", value
+	if i := strings.Index(value, "/"); i >= 0 {
+		namespace, name = value[:i], value[i+1:]
+	}
+
+	if profileOwnerRef.Kind == configv1beta1.ProfileKind {
+		// Profile and its ClusterSummaries are in the same namespace
+		if namespace != "" && namespace != clusterSummary.Namespace {
+			return nil, &NonRetriableError{
+				Message: fmt.Sprintf("annotation %s: Profile can only reference a ConfigMap in namespace %s",
+					ChartVersionsAnnotation, clusterSummary.Namespace)}
+		}
+		namespace = clusterSummary.Namespace
+	}
+
+	if namespace == "" || name == "" {
+		return nil, &NonRetriableError{
+			Message: fmt.Sprintf("annotation %s: %q is not in the form namespace/name", ChartVersionsAnnotation, value)}
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+		Namespace:  namespace,
+		Name:       name,
+	}, nil
+}
+
+// invalidConfigMapKeyChars matches the characters not allowed in ConfigMap keys
+var invalidConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// getChartName returns the name of the helm chart without repository.
+// For instance "cert-manager" for both "jetstack/cert-manager" and "oci://registry.io/charts/cert-manager".
+func getChartName(helmChart *configv1beta1.HelmChart) string {
+	return path.Base(strings.TrimPrefix(helmChart.ChartName, helmChart.RepositoryName+"/"))
+}
+
+// getChartVersionsKey returns the key, in the chart versions ConfigMap, of the helm chart: repository URL
+// followed by the chart name, both without scheme, with characters not allowed in ConfigMap keys replaced
+// by "_". Charts with the same name from different repositories have different keys.
+// For instance "charts.jetstack.io_jetstack_cert-manager" for chart "jetstack/cert-manager" of repository
+// "https://charts.jetstack.io" and "registry.io_charts_cert-manager" for chart
+// "oci://registry.io/charts/cert-manager" of repository "oci://registry.io/charts".
+func getChartVersionsKey(helmChart *configv1beta1.HelmChart) string {
+	repository := trimURLScheme(strings.TrimSuffix(helmChart.RepositoryURL, "/"))
+	key := trimURLScheme(helmChart.ChartName)
+	// OCI chart names already contain the repository
+	if repository != "" && !strings.HasPrefix(key, repository+"/") {
+		key = repository + "/" + key
+	}
+
+	return invalidConfigMapKeyChars.ReplaceAllString(key, "_")
+}
+
+func trimURLScheme(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		return url[i+len("://"):]
+	}
+	return url
+}
+
+// getPinnedChartVersions returns, for each helm chart referenced by clusterSummary (by index) whose
+// version is pinned by the chart versions ConfigMap, the pinned version.
+func getPinnedChartVersions(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (map[int]string, error) {
+
+	ref, err := getChartVersionsReference(clusterSummary)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[int]string)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		helmChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		// An explicit VersionPolicy takes precedence
+		if helmChart.VersionPolicy != nil {
+			continue
+		}
+		if version, ok := configMap.Data[getChartVersionsKey(helmChart)]; ok {
+			pinned[i] = strings.TrimSpace(version)
+		}
+	}
+
+	return pinned, nil
+}
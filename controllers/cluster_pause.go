@@ -0,0 +1,83 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// getPausedReason returns the PausedCondition reason if deployments to the cluster must be
+// suspended, an empty string otherwise. Deployments are suspended when the CAPI Cluster or
+// SveltosCluster is paused or when the ClusterSummary has the paused annotation.
+func getPausedReason(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (string, error) {
+
+	isClusterPaused, err := clusterproxy.IsClusterPaused(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if isClusterPaused {
+		return configv1beta1.ClusterPausedReason, nil
+	}
+
+	if annotations.HasPaused(clusterSummary) {
+		return configv1beta1.ClusterSummaryPausedReason, nil
+	}
+
+	return "", nil
+}
+
+// setPausedCondition reports in the PausedCondition whether deployments to the cluster are
+// suspended. An empty reason means deployments are not suspended.
+func setPausedCondition(clusterSummary *configv1beta1.ClusterSummary, reason string) {
+	condition := metav1.Condition{
+		Type:               configv1beta1.PausedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.NotPausedReason,
+		Message:            "deployments are not suspended",
+		ObservedGeneration: clusterSummary.Generation,
+	}
+
+	switch reason {
+	case configv1beta1.ClusterPausedReason:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = fmt.Sprintf("%s cluster %s/%s is paused. Deployments resume once it is unpaused",
+			clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
+	case configv1beta1.ClusterSummaryPausedReason:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = "ClusterSummary is paused. Deployments resume once the paused annotation is removed"
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, condition)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ClusterResyncPath is the path the cluster resync endpoint is served on
+const ClusterResyncPath = "/cluster-resync"
+
+// ClusterResync is the request accepted by the cluster resync endpoint. All ClusterSummaries
+// for clusters matching ClusterSelector are redeployed, without any change to the profiles.
+type ClusterResync struct {
+	// ClusterSelector is a label selector (for instance "region=eu") selecting the clusters
+	ClusterSelector string `json:"clusterSelector"`
+	// Features, if set, restricts the resync to these features (Resources, Helm, Kustomize, Jobs).
+	// All features are redeployed otherwise.
+	Features []string `json:"features,omitempty"`
+	// DryRun, if set, reports the ClusterSummaries which would be redeployed without changing them
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ClusterResyncResult lists the ClusterSummaries a redeploy was requested for
+type ClusterResyncResult struct {
+	ClusterSummaries []corev1.ObjectReference `json:"clusterSummaries"`
+}
+
+type clusterResyncHandler struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// NewClusterResyncHandler returns an http.Handler forcing an immediate redeployment of all ClusterSummaries
+// for the clusters matching a label selector, for instance after an out-of-band regional incident.
+// Redeployment is requested setting the RedeployAnnotation on each ClusterSummary.
+func NewClusterResyncHandler(c client.Client, logger logr.Logger) http.Handler {
+	return &clusterResyncHandler{c: c, logger: logger}
+}
+
+func (h *clusterResyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resync := &ClusterResync{}
+	if err := json.NewDecoder(r.Body).Decode(resync); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateClusterResync(resync); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	const timeout = time.Minute
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	clusterSummaries, err := resyncClusters(ctx, h.c, resync, h.logger)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&ClusterResyncResult{ClusterSummaries: clusterSummaries})
+	if err != nil {
+		h.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write response: %v", err))
+	}
+}
+
+func validateClusterResync(resync *ClusterResync) error {
+	// An empty selector would match the whole fleet
+	if strings.TrimSpace(resync.ClusterSelector) == "" {
+		return fmt.Errorf("clusterSelector is required")
+	}
+
+	if _, err := labels.Parse(resync.ClusterSelector); err != nil {
+		return fmt.Errorf("invalid clusterSelector: %w", err)
+	}
+
+	if _, err := getRedeployFeatures(getClusterResyncFeatures(resync)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getClusterResyncFeatures returns the RedeployAnnotation value for the resync request
+func getClusterResyncFeatures(resync *ClusterResync) string {
+	if len(resync.Features) == 0 {
+		return redeployAllFeatures
+	}
+	return strings.Join(resync.Features, ",")
+}
+
+// resyncClusters requests, via RedeployAnnotation, the redeployment of all ClusterSummaries for
+// clusters matching the resync ClusterSelector. Returns the ClusterSummaries redeploy was requested for.
+func resyncClusters(ctx context.Context, c client.Client, resync *ClusterResync, logger logr.Logger,
+) ([]corev1.ObjectReference, error) {
+
+	selector, err := labels.Parse(resync.ClusterSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	err = c.List(ctx, clusterSummaries)
+	if err != nil {
+		return nil, err
+	}
+
+	features := getClusterResyncFeatures(resync)
+	result := make([]corev1.ObjectReference, 0)
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		if !cs.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		var cluster client.Object
+		cluster, err = clusterproxy.GetCluster(ctx, c, cs.Spec.ClusterNamespace, cs.Spec.ClusterName,
+			cs.Spec.ClusterType)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if !selector.Matches(labels.Set(cluster.GetLabels())) {
+			continue
+		}
+
+		if !resync.DryRun {
+			err = requestClusterSummaryRedeploy(ctx, c, cs, features)
+			if err != nil {
+				return nil, err
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("requested redeploy of ClusterSummary %s/%s (features: %s)",
+				cs.Namespace, cs.Name, features))
+		}
+
+		result = append(result, corev1.ObjectReference{
+			APIVersion: configv1beta1.GroupVersion.String(),
+			Kind:       configv1beta1.ClusterSummaryKind,
+			Namespace:  cs.Namespace,
+			Name:       cs.Name,
+		})
+	}
+
+	return result, nil
+}
+
+func requestClusterSummaryRedeploy(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, features string) error {
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		annotations := currentClusterSummary.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[RedeployAnnotation] = features
+		currentClusterSummary.SetAnnotations(annotations)
+		return c.Update(ctx, currentClusterSummary)
+	})
+}
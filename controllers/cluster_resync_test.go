@@ -0,0 +1,102 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Cluster resync", func() {
+	getClusterAndClusterSummary := func(clusterLabels map[string]string,
+	) (*libsveltosv1beta1.SveltosCluster, *configv1beta1.ClusterSummary) {
+
+		cluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    clusterLabels,
+			},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+		}
+
+		return cluster, clusterSummary
+	}
+
+	It("validateClusterResync requires a valid selector and supported features", func() {
+		Expect(controllers.ValidateClusterResync(&controllers.ClusterResync{ClusterSelector: "region=eu"})).To(Succeed())
+		Expect(controllers.ValidateClusterResync(&controllers.ClusterResync{})).ToNot(Succeed())
+		Expect(controllers.ValidateClusterResync(&controllers.ClusterResync{ClusterSelector: "region in eu"})).ToNot(Succeed())
+		Expect(controllers.ValidateClusterResync(&controllers.ClusterResync{ClusterSelector: "region=eu",
+			Features: []string{"Helm", randomString()}})).ToNot(Succeed())
+	})
+
+	It("resyncClusters requests redeploy only for ClusterSummaries of matching clusters", func() {
+		euCluster, euClusterSummary := getClusterAndClusterSummary(map[string]string{"region": "eu"})
+		usCluster, usClusterSummary := getClusterAndClusterSummary(map[string]string{"region": "us"})
+
+		initObjects := []client.Object{euCluster, euClusterSummary, usCluster, usClusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		resync := &controllers.ClusterResync{ClusterSelector: "region=eu", DryRun: true}
+		clusterSummaries, err := controllers.ResyncClusters(context.TODO(), c, resync,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(len(clusterSummaries)).To(Equal(1))
+		Expect(clusterSummaries[0].Name).To(Equal(euClusterSummary.Name))
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: euClusterSummary.Namespace,
+			Name: euClusterSummary.Name}, currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Annotations).ToNot(HaveKey(controllers.RedeployAnnotation))
+
+		resync = &controllers.ClusterResync{ClusterSelector: "region=eu", Features: []string{"Helm"}}
+		_, err = controllers.ResyncClusters(context.TODO(), c, resync, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: euClusterSummary.Namespace,
+			Name: euClusterSummary.Name}, currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Annotations[controllers.RedeployAnnotation]).To(Equal("Helm"))
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: usClusterSummary.Namespace,
+			Name: usClusterSummary.Name}, currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Annotations).ToNot(HaveKey(controllers.RedeployAnnotation))
+	})
+})
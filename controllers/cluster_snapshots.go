@@ -0,0 +1,292 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Cluster metadata (labels and Kubernetes version) is periodically recorded, so that
+// ClusterProfiles/Profiles can be simulated against clusters as they were in the past.
+// Snapshots of a cluster are stored in a ConfigMap in the projectsveltos namespace.
+// A snapshot is only recorded when metadata changes, so each snapshot describes the
+// cluster until the next one.
+
+const (
+	clusterSnapshotLabel        = "projectsveltos.io/cluster-snapshots"
+	clusterSnapshotPrefix       = "cluster-snapshots-"
+	clusterSnapshotClusterKey   = "cluster"
+	clusterSnapshotSnapshotsKey = "snapshots"
+)
+
+// clusterSnapshot is the metadata of a cluster from Time until the next snapshot
+type clusterSnapshot struct {
+	Time    metav1.Time       `json:"time"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Version string            `json:"version,omitempty"`
+	// Deleted is set once the cluster does not exist anymore
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// RecordClusterSnapshots periodically records labels and Kubernetes version of all clusters.
+// Snapshots older than retention, and not describing the cluster at now-retention, are pruned.
+func RecordClusterSnapshots(ctx context.Context, c client.Client, interval, retention time.Duration,
+	logger logr.Logger) {
+
+	for {
+		time.Sleep(interval)
+
+		logger.V(logs.LogVerbose).Info("recording cluster snapshots")
+
+		if err := recordClusterSnapshots(ctx, c, time.Now(), retention, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record cluster snapshots: %v", err))
+		}
+	}
+}
+
+func recordClusterSnapshots(ctx context.Context, c client.Client, now time.Time, retention time.Duration,
+	logger logr.Logger) error {
+
+	clusters, err := clusterproxy.GetListOfClusters(ctx, c, "", logger)
+	if err != nil {
+		return err
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	err = c.List(ctx, configMaps, client.InNamespace(projectsveltos),
+		client.HasLabels{clusterSnapshotLabel})
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*corev1.ConfigMap, len(configMaps.Items))
+	for i := range configMaps.Items {
+		existing[configMaps.Items[i].Name] = &configMaps.Items[i]
+	}
+
+	for i := range clusters {
+		var cluster client.Object
+		cluster, err = clusterproxy.GetCluster(ctx, c, clusters[i].Namespace, clusters[i].Name,
+			clusterproxy.GetClusterType(&clusters[i]))
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get cluster %s/%s: %v",
+				clusters[i].Namespace, clusters[i].Name, err))
+			continue
+		}
+
+		snapshot := clusterSnapshot{
+			Time:    metav1.NewTime(now),
+			Labels:  cluster.GetLabels(),
+			Version: getClusterVersion(cluster),
+		}
+
+		name := getClusterSnapshotConfigMapName(&clusters[i])
+		err = updateClusterSnapshots(ctx, c, existing[name], &clusters[i], snapshot, now, retention)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record snapshot for cluster %s/%s: %v",
+				clusters[i].Namespace, clusters[i].Name, err))
+		}
+		delete(existing, name)
+	}
+
+	// Remaining ConfigMaps are for clusters which do not exist anymore
+	for name := range existing {
+		var clusterRef *corev1.ObjectReference
+		var snapshots []clusterSnapshot
+		clusterRef, snapshots, err = getClusterSnapshots(existing[name])
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to read cluster snapshots %s: %v", name, err))
+			continue
+		}
+
+		snapshots = pruneClusterSnapshots(snapshots, now, retention)
+		if len(snapshots) == 1 && snapshots[0].Deleted && snapshots[0].Time.Time.Before(now.Add(-retention)) {
+			err = c.Delete(ctx, existing[name])
+			if err != nil && !apierrors.IsNotFound(err) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to delete cluster snapshots %s: %v", name, err))
+			}
+			continue
+		}
+
+		snapshot := clusterSnapshot{Time: metav1.NewTime(now), Deleted: true}
+		err = updateClusterSnapshots(ctx, c, existing[name], clusterRef, snapshot, now, retention)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record deletion of cluster %s/%s: %v",
+				clusterRef.Namespace, clusterRef.Name, err))
+		}
+	}
+
+	return nil
+}
+
+// updateClusterSnapshots appends snapshot, if metadata changed since last snapshot, and prunes
+// snapshots. configMap is nil if no snapshot was ever recorded for the cluster.
+func updateClusterSnapshots(ctx context.Context, c client.Client, configMap *corev1.ConfigMap,
+	clusterRef *corev1.ObjectReference, snapshot clusterSnapshot, now time.Time, retention time.Duration) error {
+
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: projectsveltos,
+				Name:      getClusterSnapshotConfigMapName(clusterRef),
+				Labels:    map[string]string{clusterSnapshotLabel: "ok"},
+			},
+		}
+		data, err := encodeClusterSnapshots(clusterRef, []clusterSnapshot{snapshot})
+		if err != nil {
+			return err
+		}
+		configMap.Data = data
+		return c.Create(ctx, configMap)
+	}
+
+	_, snapshots, err := getClusterSnapshots(configMap)
+	if err != nil {
+		return err
+	}
+
+	updated := pruneClusterSnapshots(snapshots, now, retention)
+	changed := len(updated) != len(snapshots)
+	if len(updated) == 0 || !sameClusterMetadata(&updated[len(updated)-1], &snapshot) {
+		updated = append(updated, snapshot)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	data, err := encodeClusterSnapshots(clusterRef, updated)
+	if err != nil {
+		return err
+	}
+	configMap.Data = data
+	return c.Update(ctx, configMap)
+}
+
+func sameClusterMetadata(current, desired *clusterSnapshot) bool {
+	return current.Deleted == desired.Deleted && current.Version == desired.Version &&
+		sameMaps(current.Labels, desired.Labels)
+}
+
+// sameMaps returns true if current and desired contain the same entries.
+// A nil and an empty map are considered equal.
+func sameMaps(current, desired map[string]string) bool {
+	if len(current) == 0 && len(desired) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(current, desired)
+}
+
+// pruneClusterSnapshots removes snapshots superseded before now-retention.
+// The last snapshot is never removed.
+func pruneClusterSnapshots(snapshots []clusterSnapshot, now time.Time, retention time.Duration) []clusterSnapshot {
+	cutoff := now.Add(-retention)
+	first := 0
+	for first < len(snapshots)-1 && !snapshots[first+1].Time.Time.After(cutoff) {
+		first++
+	}
+	return snapshots[first:]
+}
+
+// getClusterSnapshotAt returns the snapshot describing the cluster at time at.
+// Returns nil if the cluster did not exist at that time (or no snapshot was recorded yet).
+func getClusterSnapshotAt(snapshots []clusterSnapshot, at time.Time) *clusterSnapshot {
+	var result *clusterSnapshot
+	for i := range snapshots {
+		if snapshots[i].Time.Time.After(at) {
+			break
+		}
+		result = &snapshots[i]
+	}
+
+	if result != nil && result.Deleted {
+		return nil
+	}
+	return result
+}
+
+func getClusterSnapshotConfigMapName(clusterRef *corev1.ObjectReference) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", clusterproxy.GetClusterType(clusterRef),
+		clusterRef.Namespace, clusterRef.Name)))
+	const hashLength = 20
+	return fmt.Sprintf("%s%x", clusterSnapshotPrefix, h[:hashLength])
+}
+
+func getClusterSnapshots(configMap *corev1.ConfigMap) (*corev1.ObjectReference, []clusterSnapshot, error) {
+	clusterRef := &corev1.ObjectReference{}
+	if err := json.Unmarshal([]byte(configMap.Data[clusterSnapshotClusterKey]), clusterRef); err != nil {
+		return nil, nil, err
+	}
+
+	var snapshots []clusterSnapshot
+	if err := json.Unmarshal([]byte(configMap.Data[clusterSnapshotSnapshotsKey]), &snapshots); err != nil {
+		return nil, nil, err
+	}
+
+	return clusterRef, snapshots, nil
+}
+
+func encodeClusterSnapshots(clusterRef *corev1.ObjectReference, snapshots []clusterSnapshot,
+) (map[string]string, error) {
+
+	cluster, err := json.Marshal(clusterRef)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		clusterSnapshotClusterKey:   string(cluster),
+		clusterSnapshotSnapshotsKey: string(data),
+	}, nil
+}
+
+// getClusterVersion returns the Kubernetes version of the cluster, when known to the
+// management cluster (CAPI managed topology or SveltosCluster status)
+func getClusterVersion(cluster client.Object) string {
+	switch c := cluster.(type) {
+	case *clusterv1.Cluster:
+		if c.Spec.Topology != nil {
+			return c.Spec.Topology.Version
+		}
+	case *libsveltosv1beta1.SveltosCluster:
+		return c.Status.Version
+	}
+
+	return ""
+}
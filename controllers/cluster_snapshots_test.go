@@ -0,0 +1,131 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Cluster snapshots", func() {
+	const retention = 24 * time.Hour
+
+	It("simulateProfile matches clusters as they were at the simulated time", func() {
+		cluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "production"},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		recorded := time.Now().Add(-time.Hour)
+		Expect(controllers.RecordClusterSnapshotsAt(context.TODO(), c, recorded, retention, logger)).To(Succeed())
+
+		relabeled := recorded.Add(30 * time.Minute)
+		cluster.Labels = map[string]string{"env": "staging"}
+		Expect(c.Update(context.TODO(), cluster)).To(Succeed())
+		Expect(controllers.RecordClusterSnapshotsAt(context.TODO(), c, relabeled, retention, logger)).To(Succeed())
+
+		spec := &configv1beta1.Spec{
+			ClusterSelector: libsveltosv1beta1.Selector{
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+			},
+		}
+
+		// No snapshot recorded yet
+		simulation, err := controllers.SimulateProfile(context.TODO(), c, "", spec, recorded.Add(-time.Minute))
+		Expect(err).To(BeNil())
+		Expect(simulation.MatchingClusters).To(BeEmpty())
+
+		simulation, err = controllers.SimulateProfile(context.TODO(), c, "", spec, recorded.Add(time.Minute))
+		Expect(err).To(BeNil())
+		Expect(len(simulation.MatchingClusters)).To(Equal(1))
+		Expect(simulation.MatchingClusters[0].Cluster.Name).To(Equal(cluster.Name))
+		Expect(simulation.MatchingClusters[0].Cluster.Kind).To(Equal(libsveltosv1beta1.SveltosClusterKind))
+
+		simulation, err = controllers.SimulateProfile(context.TODO(), c, "", spec, relabeled.Add(time.Minute))
+		Expect(err).To(BeNil())
+		Expect(simulation.MatchingClusters).To(BeEmpty())
+
+		// Profiles only match clusters in their namespace
+		simulation, err = controllers.SimulateProfile(context.TODO(), c, randomString(), spec, recorded.Add(time.Minute))
+		Expect(err).To(BeNil())
+		Expect(simulation.MatchingClusters).To(BeEmpty())
+	})
+
+	It("recordClusterSnapshots records deleted clusters and removes their snapshots after retention", func() {
+		cluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{randomString(): randomString()},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		recorded := time.Now().Add(-time.Hour)
+		Expect(controllers.RecordClusterSnapshotsAt(context.TODO(), c, recorded, retention, logger)).To(Succeed())
+
+		deleted := recorded.Add(time.Minute)
+		Expect(c.Delete(context.TODO(), cluster)).To(Succeed())
+		Expect(controllers.RecordClusterSnapshotsAt(context.TODO(), c, deleted, retention, logger)).To(Succeed())
+
+		spec := &configv1beta1.Spec{
+			ClusterRefs: []corev1.ObjectReference{
+				{
+					Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+					Namespace: cluster.Namespace, Name: cluster.Name,
+				},
+			},
+		}
+
+		simulation, err := controllers.SimulateProfile(context.TODO(), c, "", spec, recorded.Add(time.Second))
+		Expect(err).To(BeNil())
+		Expect(len(simulation.MatchingClusters)).To(Equal(1))
+
+		simulation, err = controllers.SimulateProfile(context.TODO(), c, "", spec, deleted.Add(time.Second))
+		Expect(err).To(BeNil())
+		Expect(simulation.MatchingClusters).To(BeEmpty())
+
+		configMaps := &corev1.ConfigMapList{}
+		Expect(c.List(context.TODO(), configMaps)).To(Succeed())
+		Expect(len(configMaps.Items)).To(Equal(1))
+
+		Expect(controllers.RecordClusterSnapshotsAt(context.TODO(), c, deleted.Add(2*retention), retention,
+			logger)).To(Succeed())
+		Expect(c.List(context.TODO(), configMaps)).To(Succeed())
+		Expect(configMaps.Items).To(BeEmpty())
+	})
+})
@@ -0,0 +1,165 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+const (
+	// throttleBaseBackoff is how long deployments to a managed cluster are held back the
+	// first time the cluster throttles requests. It doubles at each consecutive throttling.
+	throttleBaseBackoff = 5 * time.Second
+
+	// throttleMaxBackoff is the maximum time deployments to a managed cluster are held back
+	throttleMaxBackoff = 5 * time.Minute
+
+	// throttleJitterFactor spreads retries so clusters throttled at the same time are not
+	// all retried at the same time
+	throttleJitterFactor = 0.5
+)
+
+// ClusterThrottledError is returned when a managed cluster is throttling requests (API
+// priority and fairness) or its apiserver is unavailable. Deployments to that cluster are
+// held back for RetryAfter.
+type ClusterThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ClusterThrottledError) Error() string {
+	return fmt.Sprintf("managed cluster is throttling requests. Retry in %s", e.RetryAfter)
+}
+
+type clusterThrottleState struct {
+	// failures is the number of consecutive times the cluster throttled requests
+	failures int
+	// retryAt is when deployments to the cluster can be attempted again
+	retryAt time.Time
+	// waiting contains the ClusterSummary features whose last deployment was throttled
+	// and has not been retried yet
+	waiting map[string]bool
+}
+
+var (
+	throttleMux       sync.Mutex
+	throttledClusters = make(map[string]*clusterThrottleState)
+)
+
+// isClusterThrottlingError returns true if err is a 429 (API priority and fairness) or
+// a 503 (apiserver unavailable) returned by a managed cluster
+func isClusterThrottlingError(err error) bool {
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err)
+}
+
+func getClusterThrottleKey(clusterSummary *configv1beta1.ClusterSummary) string {
+	return fmt.Sprintf("%s:%s/%s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName)
+}
+
+// getThrottleBackoff returns, with jitter, the exponential backoff after consecutive failures
+func getThrottleBackoff(failures int) time.Duration {
+	backoff := throttleBaseBackoff
+	for i := 1; i < failures && backoff < throttleMaxBackoff; i++ {
+		backoff += backoff
+	}
+	return wait.Jitter(min(backoff, throttleMaxBackoff), throttleJitterFactor)
+}
+
+// getThrottleMetricLabels returns the cluster and cluster_type labels of the throttling metrics
+func getThrottleMetricLabels(clusterSummary *configv1beta1.ClusterSummary) []string {
+	return []string{fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName),
+		string(clusterSummary.Spec.ClusterType)}
+}
+
+// recordClusterThrottling is invoked when deploying a ClusterSummary feature failed because the managed
+// cluster throttled requests. It returns how long to hold back before redeploying the feature.
+// The deployer keeps reporting the throttled result till the feature is redeployed. So once the backoff
+// has elapsed, zero is returned, meaning the feature can be redeployed.
+func recordClusterThrottling(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	err error, now time.Time) time.Duration {
+
+	throttleMux.Lock()
+	defer throttleMux.Unlock()
+
+	key := getClusterThrottleKey(clusterSummary)
+	state, ok := throttledClusters[key]
+	if !ok {
+		state = &clusterThrottleState{waiting: make(map[string]bool)}
+		throttledClusters[key] = state
+	}
+
+	entry := fmt.Sprintf("%s/%s:%s", clusterSummary.Namespace, clusterSummary.Name, featureID)
+	if state.waiting[entry] {
+		if now.Before(state.retryAt) {
+			return state.retryAt.Sub(now)
+		}
+		delete(state.waiting, entry)
+		return 0
+	}
+
+	// Multiple features throttled within the same backoff count as one
+	if !now.Before(state.retryAt) {
+		state.failures++
+		backoff := getThrottleBackoff(state.failures)
+		state.retryAt = now.Add(backoff)
+		clusterThrottleBackoffGauge.WithLabelValues(getThrottleMetricLabels(clusterSummary)...).Set(backoff.Seconds())
+	}
+	state.waiting[entry] = true
+	clusterThrottledCounter.WithLabelValues(append(getThrottleMetricLabels(clusterSummary),
+		string(apierrors.ReasonForError(err)))...).Inc()
+
+	return state.retryAt.Sub(now)
+}
+
+// getClusterThrottleDelay returns how long deployments to the ClusterSummary managed cluster
+// must still be held back. Zero if cluster is not throttling requests.
+func getClusterThrottleDelay(clusterSummary *configv1beta1.ClusterSummary, now time.Time) time.Duration {
+	throttleMux.Lock()
+	defer throttleMux.Unlock()
+
+	state, ok := throttledClusters[getClusterThrottleKey(clusterSummary)]
+	if !ok || !now.Before(state.retryAt) {
+		return 0
+	}
+	return state.retryAt.Sub(now)
+}
+
+// clearClusterThrottling is invoked when a ClusterSummary feature is successfully deployed.
+// Once no feature is waiting, the managed cluster is not considered throttling anymore.
+func clearClusterThrottling(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) {
+	throttleMux.Lock()
+	defer throttleMux.Unlock()
+
+	key := getClusterThrottleKey(clusterSummary)
+	state, ok := throttledClusters[key]
+	if !ok {
+		return
+	}
+
+	delete(state.waiting, fmt.Sprintf("%s/%s:%s", clusterSummary.Namespace, clusterSummary.Name, featureID))
+	if len(state.waiting) == 0 {
+		delete(throttledClusters, key)
+		clusterThrottleBackoffGauge.WithLabelValues(getThrottleMetricLabels(clusterSummary)...).Set(0)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("ClusterThrottling", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var throttlingErr error
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		throttlingErr = apierrors.NewTooManyRequests("too many requests", 1)
+	})
+
+	It("isClusterThrottlingError detects 429 and 503 responses", func() {
+		Expect(controllers.IsClusterThrottlingError(throttlingErr)).To(BeTrue())
+		Expect(controllers.IsClusterThrottlingError(fmt.Errorf("failed to apply: %w", throttlingErr))).To(BeTrue())
+		Expect(controllers.IsClusterThrottlingError(
+			apierrors.NewServiceUnavailable("apiserver unavailable"))).To(BeTrue())
+		Expect(controllers.IsClusterThrottlingError(
+			apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, randomString()))).To(BeFalse())
+		Expect(controllers.IsClusterThrottlingError(errors.New(randomString()))).To(BeFalse())
+		Expect(controllers.IsClusterThrottlingError(nil)).To(BeFalse())
+	})
+
+	It("recordClusterThrottling holds back the whole cluster till backoff elapses", func() {
+		now := time.Now()
+
+		retryAfter := controllers.RecordClusterThrottling(clusterSummary, configv1beta1.FeatureHelm,
+			throttlingErr, now)
+		Expect(retryAfter).To(BeNumerically(">", 0))
+		Expect(controllers.GetClusterThrottleDelay(clusterSummary, now)).To(Equal(retryAfter))
+
+		// Another ClusterSummary for the same cluster is held back as well
+		other := clusterSummary.DeepCopy()
+		other.Name = randomString()
+		Expect(controllers.GetClusterThrottleDelay(other, now)).To(Equal(retryAfter))
+
+		// Same throttled result, still within backoff
+		Expect(controllers.RecordClusterThrottling(clusterSummary, configv1beta1.FeatureHelm,
+			throttlingErr, now.Add(time.Second))).To(Equal(retryAfter - time.Second))
+
+		// Same throttled result once backoff elapsed: feature can be redeployed
+		later := now.Add(retryAfter)
+		Expect(controllers.RecordClusterThrottling(clusterSummary, configv1beta1.FeatureHelm,
+			throttlingErr, later)).To(BeZero())
+		Expect(controllers.GetClusterThrottleDelay(clusterSummary, later)).To(BeZero())
+
+		// Redeployment throttled again: backoff increases
+		Expect(controllers.RecordClusterThrottling(clusterSummary, configv1beta1.FeatureHelm,
+			throttlingErr, later)).To(BeNumerically(">", retryAfter))
+
+		controllers.ClearClusterThrottling(clusterSummary, configv1beta1.FeatureHelm)
+		Expect(controllers.GetClusterThrottleDelay(clusterSummary, later)).To(BeZero())
+	})
+})
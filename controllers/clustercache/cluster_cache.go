@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
@@ -48,6 +49,10 @@ type clusterCache struct {
 	// key: cluster, value: Secret with kubeconfig
 	clusters map[corev1.ObjectReference]*corev1.ObjectReference
 
+	// key: cluster, value: URI of the kubeconfig in the secret provider
+	// Only clusters whose kubeconfig is fetched from a secret provider are tracked
+	kubeconfigURIs map[corev1.ObjectReference]string
+
 	// key: secret, value: set of clusters
 	// A secret can potentially contain kubeconfig for one or more clusters
 	secrets map[corev1.ObjectReference]*libsveltosset.Set
@@ -60,10 +65,11 @@ func GetManager() *clusterCache {
 		defer lock.Unlock()
 		if managerInstance == nil {
 			managerInstance = &clusterCache{
-				configs:  make(map[corev1.ObjectReference]*rest.Config),
-				clusters: make(map[corev1.ObjectReference]*corev1.ObjectReference),
-				secrets:  make(map[corev1.ObjectReference]*libsveltosset.Set),
-				rwMux:    sync.RWMutex{},
+				configs:        make(map[corev1.ObjectReference]*rest.Config),
+				clusters:       make(map[corev1.ObjectReference]*corev1.ObjectReference),
+				kubeconfigURIs: make(map[corev1.ObjectReference]string),
+				secrets:        make(map[corev1.ObjectReference]*libsveltosset.Set),
+				rwMux:          sync.RWMutex{},
 			}
 		}
 	}
@@ -89,6 +95,7 @@ func (m *clusterCache) RemoveCluster(clusterNamespace, clusterName string,
 
 	// Do not track this cluster anymore
 	delete(m.clusters, *cluster)
+	delete(m.kubeconfigURIs, *cluster)
 }
 
 // RemoveSecret removes any in-memory data related to secret
@@ -111,7 +118,9 @@ func (m *clusterCache) RemoveSecret(sec *corev1.ObjectReference) {
 // GetKubernetesRestConfig returns managed cluster restConfig.
 // If result is cached, it will be returned immediately. Otherwise it will be built
 // by fetching the Secret containing the cluster kubeconfig.
-// Admins restConfig are never cached.
+// Admins restConfig are never cached. Neither are restConfig of clusters whose kubeconfig
+// is fetched from a secret provider (the secret provider caches those for a limited time):
+// only the secret provider URI, resolved the first time, is.
 func (m *clusterCache) GetKubernetesRestConfig(ctx context.Context, mgmtClient client.Client,
 	clusterNamespace, clusterName, adminNamespace, adminName string,
 	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (*rest.Config, error) {
@@ -133,6 +142,22 @@ func (m *clusterCache) GetKubernetesRestConfig(ctx context.Context, mgmtClient c
 		return config, nil
 	}
 
+	uri, ok := m.kubeconfigURIs[*cluster]
+	if !ok {
+		var err error
+		uri, err = secretprovider.GetKubeconfigURI(ctx, mgmtClient, clusterNamespace, clusterName, clusterType)
+		if err != nil {
+			return nil, err
+		}
+		if uri != "" {
+			m.kubeconfigURIs[*cluster] = uri
+		}
+	}
+	if uri != "" {
+		logger.V(logs.LogVerbose).Info("get kubeconfig from secret provider")
+		return secretprovider.GetKubernetesRestConfigFromURI(ctx, uri)
+	}
+
 	logger.V(logs.LogDebug).Info("remote restConfig cache miss")
 	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, mgmtClient, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterType, logger)
@@ -18,6 +18,8 @@ package clustercache_test
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -28,6 +30,7 @@ import (
 	"k8s.io/klog/v2/textlogger"
 
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 )
 
@@ -114,8 +117,51 @@ var _ = Describe("Clustercache", func() {
 		cacheMgr.RemoveSecret(secretObj)
 		Expect(cacheMgr.GetConfigFromMap(clusterObj)).To(BeNil())
 	})
+
+	It("GetKubernetesRestConfig resolves secret provider URI only once", func() {
+		scheme := "cache" + randomString()
+		p := &staticProvider{data: testEnv.Kubeconfig}
+		secretprovider.RegisterProvider(scheme, p)
+
+		uri := fmt.Sprintf("%s://%s", scheme, randomString())
+		cluster.Annotations = map[string]string{
+			secretprovider.KubeconfigProviderAnnotation: uri,
+		}
+		createClusterResources(cluster)
+
+		cacheMgr := clustercache.GetManager()
+		_, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), testEnv.Client, cluster.Namespace,
+			cluster.Name, "", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		clusterObj := &corev1.ObjectReference{
+			Namespace:  cluster.Namespace,
+			Name:       cluster.Name,
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+		Expect(cacheMgr.GetKubeconfigURIForCluster(clusterObj)).To(Equal(uri))
+		Expect(cacheMgr.GetConfigFromMap(clusterObj)).To(BeNil())
+
+		// URI is not resolved again: the SveltosCluster is not fetched anymore
+		Expect(testEnv.Delete(context.TODO(), cluster)).To(Succeed())
+		_, err = cacheMgr.GetKubernetesRestConfig(context.TODO(), testEnv.Client, cluster.Namespace,
+			cluster.Name, "", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		cacheMgr.RemoveCluster(cluster.Namespace, cluster.Name, libsveltosv1beta1.ClusterTypeSveltos)
+		Expect(cacheMgr.GetKubeconfigURIForCluster(clusterObj)).To(BeEmpty())
+	})
 })
 
+type staticProvider struct {
+	data []byte
+}
+
+func (p *staticProvider) GetSecret(_ context.Context, _ *url.URL) ([]byte, error) {
+	return p.data, nil
+}
+
 func createClusterResources(cluster *libsveltosv1beta1.SveltosCluster) *corev1.Secret {
 	By("Create the cluster's namespace")
 	ns := &corev1.Namespace{
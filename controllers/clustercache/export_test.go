@@ -29,6 +29,10 @@ func (m *clusterCache) GetSecretForCluster(cluster *corev1.ObjectReference) *cor
 	return m.clusters[*cluster]
 }
 
+func (m *clusterCache) GetKubeconfigURIForCluster(cluster *corev1.ObjectReference) string {
+	return m.kubeconfigURIs[*cluster]
+}
+
 func (m *clusterCache) GetClusterFromSecret(secret *corev1.ObjectReference) *corev1.ObjectReference {
 	set := m.secrets[*secret]
 	if set == nil {
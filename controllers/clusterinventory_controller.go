@@ -0,0 +1,353 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// NodeCountLabel is set on CAPI Clusters to the number of nodes requested by all their
+	// MachineDeployments and MachinePools
+	NodeCountLabel = "inventory.projectsveltos.io/node-count"
+
+	// GPULabel is set to "true" on CAPI Clusters with at least one GPU node pool
+	GPULabel = "inventory.projectsveltos.io/gpu"
+
+	// InstanceTypeLabelPrefix, followed by an instance type, is set to "true" on CAPI Clusters
+	// with at least one node pool of that instance type (for instance
+	// instance-type.inventory.projectsveltos.io/m5.xlarge)
+	InstanceTypeLabelPrefix = "instance-type.inventory.projectsveltos.io/"
+
+	// gpuCountAnnotation is the cluster-autoscaler annotation reporting the number of GPUs
+	// of each node of a node pool
+	gpuCountAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-count"
+)
+
+// instanceTypeFields are the fields, in the infrastructure machine templates of the most
+// common providers, containing the instance type (AWS, Azure, GCP)
+var instanceTypeFields = [][]string{
+	{"spec", "template", "spec", "instanceType"},
+	{"spec", "template", "spec", "vmSize"},
+	{"spec", "template", "spec", "machineType"},
+	{"spec", "instanceType"},
+	{"spec", "vmSize"},
+	{"spec", "machineType"},
+}
+
+// ClusterInventoryReconciler labels CAPI Clusters with facts derived from their
+// MachineDeployments and MachinePools (node count, instance types, GPU node pools), so
+// that ClusterProfiles/Profiles can select clusters on those without manual labeling.
+type ClusterInventoryReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+	Logger               logr.Logger
+}
+
+// nodePool contains what is relevant, for the inventory, of a MachineDeployment/MachinePool
+type nodePool struct {
+	replicas          int32
+	annotations       map[string]string
+	infrastructureRef *corev1.ObjectReference
+}
+
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools,verbs=get;list;watch
+
+func (r *ClusterInventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(logs.LogDebug).Info("Reconciling")
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, "Failed to fetch Cluster")
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to fetch Cluster %s", req.NamespacedName)
+	}
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	nodePools, err := r.getNodePools(ctx, cluster)
+	if err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to get node pools")
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	inventoryLabels := getInventoryLabels(ctx, r.Client, nodePools, logger)
+
+	labels := getNonInventoryLabels(cluster.Labels)
+	for k, v := range inventoryLabels {
+		labels[k] = v
+	}
+
+	if reflect.DeepEqual(labels, cluster.Labels) ||
+		(len(labels) == 0 && len(cluster.Labels) == 0) {
+
+		return reconcile.Result{}, nil
+	}
+
+	patch := client.MergeFrom(cluster.DeepCopy())
+	cluster.Labels = labels
+	if err := r.Patch(ctx, cluster, patch); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update Cluster labels")
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	logger.V(logs.LogDebug).Info("Reconcile success")
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager does nothing: the ClusterInventory controller can only be created once
+// CAPI is present (WatchForCAPI).
+func (r *ClusterInventoryReconciler) SetupWithManager(_ ctrl.Manager) error {
+	return nil
+}
+
+// WatchForCAPI creates the controller watching CAPI Clusters, MachineDeployments and,
+// if installed, MachinePools
+func (r *ClusterInventoryReconciler) WatchForCAPI(mgr ctrl.Manager, _ controller.Controller) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("clusterinventory").
+		For(&clusterv1.Cluster{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Watches(&clusterv1.MachineDeployment{},
+			handler.EnqueueRequestsFromMapFunc(requeueClusterForNodePool),
+		)
+
+	machinePoolKind := expclusterv1.GroupVersion.WithKind("MachinePool")
+	if _, err := mgr.GetRESTMapper().RESTMapping(machinePoolKind.GroupKind(), machinePoolKind.Version); err == nil {
+		b = b.Watches(&expclusterv1.MachinePool{},
+			handler.EnqueueRequestsFromMapFunc(requeueClusterForNodePool),
+		)
+	}
+
+	_, err := b.Build(r)
+	if err != nil {
+		return errors.Wrap(err, "error creating controller")
+	}
+
+	return nil
+}
+
+func (r *ClusterInventoryReconciler) GetController() controller.Controller {
+	return nil
+}
+
+// requeueClusterForNodePool requeues the Cluster a MachineDeployment/MachinePool belongs to
+func requeueClusterForNodePool(_ context.Context, o client.Object) []reconcile.Request {
+	clusterName, ok := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: clusterName}},
+	}
+}
+
+// getNodePools returns the MachineDeployments and MachinePools of a Cluster
+func (r *ClusterInventoryReconciler) getNodePools(ctx context.Context, cluster *clusterv1.Cluster,
+) ([]nodePool, error) {
+
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name},
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.List(ctx, machineDeployments, listOptions...); err != nil {
+		return nil, err
+	}
+
+	nodePools := make([]nodePool, 0, len(machineDeployments.Items))
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+		nodePools = append(nodePools, nodePool{
+			replicas:          getReplicas(md.Spec.Replicas),
+			annotations:       md.Annotations,
+			infrastructureRef: getInfrastructureRef(&md.Spec.Template.Spec.InfrastructureRef, cluster.Namespace),
+		})
+	}
+
+	machinePools := &expclusterv1.MachinePoolList{}
+	if err := r.List(ctx, machinePools, listOptions...); err != nil {
+		// MachinePool CRD is only installed when the feature is enabled
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	for i := range machinePools.Items {
+		mp := &machinePools.Items[i]
+		nodePools = append(nodePools, nodePool{
+			replicas:          getReplicas(mp.Spec.Replicas),
+			annotations:       mp.Annotations,
+			infrastructureRef: getInfrastructureRef(&mp.Spec.Template.Spec.InfrastructureRef, cluster.Namespace),
+		})
+	}
+
+	return nodePools, nil
+}
+
+// getInfrastructureRef returns a copy of ref with namespace defaulted to the Cluster namespace
+func getInfrastructureRef(ref *corev1.ObjectReference, clusterNamespace string) *corev1.ObjectReference {
+	infrastructureRef := ref.DeepCopy()
+	if infrastructureRef.Namespace == "" {
+		infrastructureRef.Namespace = clusterNamespace
+	}
+	return infrastructureRef
+}
+
+func getReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		// CAPI defaults replicas to 1
+		return 1
+	}
+	return *replicas
+}
+
+// getInventoryLabels returns the inventory labels for a Cluster with the given node pools.
+// Node pools whose infrastructure template cannot be fetched only contribute to the node count.
+func getInventoryLabels(ctx context.Context, c client.Client, nodePools []nodePool,
+	logger logr.Logger) map[string]string {
+
+	labels := make(map[string]string)
+
+	var nodeCount int32
+	for i := range nodePools {
+		nodeCount += nodePools[i].replicas
+
+		if hasGPU(nodePools[i].annotations) {
+			labels[GPULabel] = "true"
+		}
+
+		infrastructure, err := getInfrastructureTemplate(ctx, c, nodePools[i].infrastructureRef)
+		if err != nil {
+			logger.V(logs.LogDebug).Info("failed to get infrastructure template", "error", err.Error())
+			continue
+		}
+
+		if hasGPUCapacity(infrastructure) {
+			labels[GPULabel] = "true"
+		}
+
+		if instanceType := getInstanceType(infrastructure); instanceType != "" {
+			key := InstanceTypeLabelPrefix + instanceType
+			if len(validation.IsQualifiedName(key)) == 0 {
+				labels[key] = "true"
+			}
+		}
+	}
+
+	if len(nodePools) != 0 {
+		labels[NodeCountLabel] = strconv.Itoa(int(nodeCount))
+	}
+
+	return labels
+}
+
+// getNonInventoryLabels returns a copy of labels without the inventory labels
+func getNonInventoryLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k == NodeCountLabel || k == GPULabel || strings.HasPrefix(k, InstanceTypeLabelPrefix) {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func getInfrastructureTemplate(ctx context.Context, c client.Client, ref *corev1.ObjectReference,
+) (*unstructured.Unstructured, error) {
+
+	if ref == nil || ref.Kind == "" || ref.Name == "" {
+		return nil, errors.New("no infrastructureRef")
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(ref.APIVersion)
+	u.SetKind(ref.Kind)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// getInstanceType returns the instance type of an infrastructure machine template,
+// or an empty string if the provider is not known
+func getInstanceType(u *unstructured.Unstructured) string {
+	for i := range instanceTypeFields {
+		instanceType, found, err := unstructured.NestedString(u.Object, instanceTypeFields[i]...)
+		if err == nil && found && instanceType != "" {
+			return instanceType
+		}
+	}
+	return ""
+}
+
+// hasGPU returns true if node pool annotations (cluster-autoscaler capacity) report GPUs
+func hasGPU(annotations map[string]string) bool {
+	count, err := strconv.Atoi(annotations[gpuCountAnnotation])
+	return err == nil && count > 0
+}
+
+// hasGPUCapacity returns true if the infrastructure machine template capacity (CAPI
+// autoscaling from zero contract) contains GPUs
+func hasGPUCapacity(u *unstructured.Unstructured) bool {
+	capacity, found, err := unstructured.NestedStringMap(u.Object, "status", "capacity")
+	if err != nil || !found {
+		return false
+	}
+
+	for resource, quantity := range capacity {
+		if strings.HasSuffix(resource, "/gpu") && quantity != "0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("ClusterInventoryReconciler", func() {
+	var cluster *clusterv1.Cluster
+	var machineTemplate *unstructured.Unstructured
+
+	BeforeEach(func() {
+		namespace := randomString()
+
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels: map[string]string{
+					"env": "production",
+					controllers.InstanceTypeLabelPrefix + "t3.small": "true",
+				},
+			},
+		}
+
+		machineTemplate = &unstructured.Unstructured{}
+		machineTemplate.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta2")
+		machineTemplate.SetKind("AWSMachineTemplate")
+		machineTemplate.SetNamespace(namespace)
+		machineTemplate.SetName(randomString())
+		Expect(unstructured.SetNestedField(machineTemplate.Object, "m5.xlarge",
+			"spec", "template", "spec", "instanceType")).To(Succeed())
+	})
+
+	It("labels Cluster with node count, instance types and GPU node pools", func() {
+		workers := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: cluster.Name,
+				Replicas:    ptr.To(int32(3)),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: cluster.Name,
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: machineTemplate.GetAPIVersion(),
+							Kind:       machineTemplate.GetKind(),
+							Name:       machineTemplate.GetName(),
+						},
+					},
+				},
+			},
+		}
+
+		gpuWorkers := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   cluster.Namespace,
+				Name:        randomString(),
+				Labels:      map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				Annotations: map[string]string{"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "2"},
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: cluster.Name,
+				Replicas:    ptr.To(int32(2)),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: cluster.Name,
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: machineTemplate.GetAPIVersion(),
+							Kind:       machineTemplate.GetKind(),
+							Name:       randomString(), // does not exist
+						},
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, workers, gpuWorkers, machineTemplate).Build()
+
+		reconciler := &controllers.ClusterInventoryReconciler{
+			Client: c,
+			Scheme: scheme,
+			Logger: textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+		})
+		Expect(err).To(BeNil())
+
+		currentCluster := &clusterv1.Cluster{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, currentCluster)).To(Succeed())
+		Expect(currentCluster.Labels).To(Equal(map[string]string{
+			"env":                      "production",
+			controllers.NodeCountLabel: "5",
+			controllers.GPULabel:       "true",
+			controllers.InstanceTypeLabelPrefix + "m5.xlarge": "true",
+		}))
+	})
+
+	It("removes inventory labels once Cluster has no node pool", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		reconciler := &controllers.ClusterInventoryReconciler{
+			Client: c,
+			Scheme: scheme,
+			Logger: textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+		})
+		Expect(err).To(BeNil())
+
+		currentCluster := &clusterv1.Cluster{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, currentCluster)).To(Succeed())
+		Expect(currentCluster.Labels).To(Equal(map[string]string{"env": "production"}))
+	})
+})
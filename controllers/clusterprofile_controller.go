@@ -160,30 +160,53 @@ func (r *ClusterProfileReconciler) reconcileNormal(
 		}
 	}
 
-	// Get all clusters matching clusterSelector and ClusterRefs
-	matchingCluster, err := getMatchingClusters(ctx, r.Client, "", profileScope.GetSelector(),
-		profileScope.GetSpec().ClusterRefs, logger)
-	if err != nil {
-		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+	var matchingCluster []corev1.ObjectReference
+	var err error
+	if profileScope.GetSpec().ParentProfile != "" {
+		// Clusters are inherited from the parent ClusterProfile
+		matchingCluster, err = getParentMatchingClusters(ctx, r.Client, profileScope, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+	} else {
+		// Get all clusters matching clusterSelector and ClusterRefs
+		matchingCluster, err = getMatchingClusters(ctx, r.Client, "", profileScope.GetSelector(),
+			profileScope.GetSpec().ClusterRefs, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+
+		// Get all clusters from referenced ClusterSets
+		var clusterSetClusters []corev1.ObjectReference
+		clusterSetClusters, err = r.getClustersFromClusterSets(ctx, profileScope.GetSpec().SetRefs, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+		matchingCluster = append(matchingCluster, clusterSetClusters...)
 	}
 
-	// Get all clusters from referenced ClusterSets
-	clusterSetClusters, err := r.getClustersFromClusterSets(ctx, profileScope.GetSpec().SetRefs, logger)
+	// External matcher, if configured, has the final say on which clusters are a match
+	matchingCluster, err = filterByExternalMatcher(ctx, r.Client, profileScope, removeDuplicates(matchingCluster), logger)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
-	matchingCluster = append(matchingCluster, clusterSetClusters...)
 
-	profileScope.SetMatchingClusterRefs(removeDuplicates(matchingCluster))
+	// Clusters newly matching are held back if their number exceeds MatchExpansionGuard
+	profileScope.SetMatchingClusterRefs(guardMatchExpansion(profileScope, matchingCluster, logger))
 
 	r.updateMaps(profileScope)
 
-	if err := reconcileNormalCommon(ctx, r.Client, profileScope, logger); err != nil {
+	result, err := reconcileNormalCommon(ctx, r.Client, profileScope, logger)
+	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
 
 	logger.V(logs.LogInfo).Info("Reconcile success")
-	return reconcile.Result{}
+	if result.RequeueAfter == 0 && profileScope.GetSpec().ErrorBudget != nil {
+		// Re-evaluate the Degraded condition as deployment outcomes leave the window
+		return reconcile.Result{RequeueAfter: errorBudgetRequeueAfter}
+	}
+	return result
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -199,6 +222,9 @@ func (r *ClusterProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SetPredicates(mgr.GetLogger().WithValues("predicate", "clustersetpredicate")),
 			),
 		).
+		Watches(&configv1beta1.ClusterProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForParent),
+		).
 		Watches(&libsveltosv1beta1.SveltosCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForSveltosCluster),
 			builder.WithPredicates(
@@ -27,6 +27,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
@@ -102,6 +103,46 @@ var _ = Describe("Profile: Reconciler", func() {
 		).Should(BeTrue())
 	})
 
+	It("Reconciliation reports invalid InlineResources in the SpecInvalid condition", func() {
+		clusterProfile.Spec.InlineResources = []configv1beta1.InlineResource{
+			{Name: randomString(), Content: "kind: Namespace"},
+		}
+
+		initObjects := []client.Object{
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		reconciler := &controllers.ClusterProfileReconciler{
+			Client:          c,
+			Scheme:          scheme,
+			ClusterMap:      make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ClusterProfiles: make(map[corev1.ObjectReference]libsveltosv1beta1.Selector),
+			ClusterLabels:   make(map[corev1.ObjectReference]map[string]string),
+			Mux:             sync.Mutex{},
+		}
+
+		clusterProfileName := client.ObjectKey{
+			Name: clusterProfile.Name,
+		}
+
+		result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: clusterProfileName,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).ToNot(BeZero())
+
+		currentClusterProfile := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), clusterProfileName, currentClusterProfile)).To(Succeed())
+		condition := meta.FindStatusCondition(currentClusterProfile.Status.Conditions,
+			configv1beta1.SpecInvalidCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(clusterProfile.Spec.InlineResources[0].Name))
+	})
+
 	It("getClustersFromClusterSets gets cluster selected by referenced clusterSet", func() {
 		clusterSet1 := &libsveltosv1beta1.ClusterSet{
 			ObjectMeta: metav1.ObjectMeta{
@@ -260,6 +301,58 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(apierrors.IsNotFound(err)).To(BeTrue())
 	})
+
+	It("Reconciliation does not remove finalizer till deletion of a protected ClusterProfile is confirmed", func() {
+		clusterProfile.Spec.DeletionProtection = true
+		now := metav1.NewTime(time.Now())
+		clusterProfile.DeletionTimestamp = &now
+		controllerutil.AddFinalizer(clusterProfile, configv1beta1.ClusterProfileFinalizer)
+
+		initObjects := []client.Object{
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := &controllers.ClusterProfileReconciler{
+			Client:          c,
+			Scheme:          scheme,
+			ClusterMap:      make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ClusterProfiles: make(map[corev1.ObjectReference]libsveltosv1beta1.Selector),
+			ClusterLabels:   make(map[corev1.ObjectReference]map[string]string),
+			Mux:             sync.Mutex{},
+		}
+
+		clusterProfileName := client.ObjectKey{
+			Name: clusterProfile.Name,
+		}
+
+		result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: clusterProfileName,
+		})
+		Expect(err).To(BeNil())
+		Expect(result.Requeue).To(BeTrue())
+
+		currentClusterProfile := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), clusterProfileName, currentClusterProfile)).To(Succeed())
+		condition := meta.FindStatusCondition(currentClusterProfile.Status.Conditions,
+			configv1beta1.DeletionBlockedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.DeletionNotConfirmedReason))
+
+		currentClusterProfile.Annotations = map[string]string{controllers.ConfirmDeletionAnnotation: "true"}
+		Expect(c.Update(context.TODO(), currentClusterProfile)).To(Succeed())
+
+		_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: clusterProfileName,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = c.Get(context.TODO(), clusterProfileName, currentClusterProfile)
+		Expect(err).To(HaveOccurred())
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
 })
 
 var _ = Describe("ClusterProfileReconciler: requeue methods", func() {
@@ -18,12 +18,15 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
 func (r *ClusterProfileReconciler) requeueClusterProfileForClusterSet(
@@ -75,3 +78,26 @@ func (r *ClusterProfileReconciler) requeueClusterProfileForMachine(
 
 	return requeueForMachine(machine, r.ClusterProfiles, r.ClusterLabels, r.ClusterMap, configv1beta1.ClusterProfileKind, r.Logger)
 }
+
+// requeueClusterProfileForParent requeues all ClusterProfiles having the ClusterProfile as ParentProfile
+func (r *ClusterProfileReconciler) requeueClusterProfileForParent(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	clusterProfiles := &configv1beta1.ClusterProfileList{}
+	if err := r.List(ctx, clusterProfiles); err != nil {
+		r.Logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterProfiles: %v", err))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range clusterProfiles.Items {
+		if clusterProfiles.Items[i].Spec.ParentProfile == o.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: clusterProfiles.Items[i].Name},
+			})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+//nolint: lll // marker
+//+kubebuilder:webhook:path=/validate-config-projectsveltos-io-v1beta1-clusterprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=config.projectsveltos.io,resources=clusterprofiles,verbs=create;update;delete,versions=v1beta1,name=vclusterprofile.projectsveltos.io,admissionReviewVersions=v1
+
+// ClusterProfileValidator rejects ClusterProfiles with invalid InlineResources and
+// deletions of ClusterProfiles with DeletionProtection not confirmed. Common pitfalls
+// are reported as warnings.
+type ClusterProfileValidator struct {
+}
+
+// SetupWebhookWithManager registers the ClusterProfile validating webhook with the manager.
+func (v *ClusterProfileValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&configv1beta1.ClusterProfile{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *ClusterProfileValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if err := v.validate(obj); err != nil {
+		return nil, err
+	}
+
+	clusterProfile, ok := obj.(*configv1beta1.ClusterProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterProfile but got %T", obj)
+	}
+	return lintSpec(&clusterProfile.Spec), nil
+}
+
+func (v *ClusterProfileValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	if err := v.validate(newObj); err != nil {
+		return nil, err
+	}
+
+	oldClusterProfile, ok := oldObj.(*configv1beta1.ClusterProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterProfile but got %T", oldObj)
+	}
+	clusterProfile, ok := newObj.(*configv1beta1.ClusterProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterProfile but got %T", newObj)
+	}
+	if err := validateHelmChartsRemoval(configv1beta1.ClusterProfileKind, clusterProfile.Name,
+		clusterProfile.Annotations, &oldClusterProfile.Spec, &clusterProfile.Spec); err != nil {
+		return nil, err
+	}
+
+	return lintSpec(&clusterProfile.Spec), nil
+}
+
+func (v *ClusterProfileValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	clusterProfile, ok := obj.(*configv1beta1.ClusterProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterProfile but got %T", obj)
+	}
+
+	return nil, validateProfileDeletion(configv1beta1.ClusterProfileKind, clusterProfile.Name,
+		clusterProfile.Annotations, &clusterProfile.Spec)
+}
+
+func (v *ClusterProfileValidator) validate(obj runtime.Object) error {
+	clusterProfile, ok := obj.(*configv1beta1.ClusterProfile)
+	if !ok {
+		return fmt.Errorf("expected a ClusterProfile but got %T", obj)
+	}
+
+	// ClusterProfile references are never restricted, so no client is needed
+	return validateProfileSpec(context.TODO(), nil, configv1beta1.ClusterProfileKind, "",
+		clusterProfile.Annotations, &clusterProfile.Spec)
+}
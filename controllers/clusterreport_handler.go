@@ -0,0 +1,95 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ClusterReportPath is the path the cluster report endpoint is served on
+const ClusterReportPath = "/cluster-report"
+
+type clusterReportHandler struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// NewClusterReportHandler returns an http.Handler serving a ClusterReport in the requested format.
+// Query parameters:
+// - namespace and name identify the ClusterReport;
+// - format (json or summary) selects the output format. Default is json.
+// ClusterReports do not contain resource content, so unified format is not supported.
+func NewClusterReportHandler(c client.Client, logger logr.Logger) http.Handler {
+	return &clusterReportHandler{c: c, logger: logger}
+}
+
+func (h *clusterReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	format, err := getDiffFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == DiffFormatUnified {
+		http.Error(w, fmt.Sprintf("format %s is not supported for ClusterReports", format),
+			http.StatusBadRequest)
+		return
+	}
+
+	const timeout = time.Minute
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	clusterReport := &configv1beta1.ClusterReport{}
+	err = h.c.Get(ctx, types.NamespacedName{Namespace: query.Get("namespace"), Name: query.Get("name")},
+		clusterReport)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if format == DiffFormatSummary {
+		err = json.NewEncoder(w).Encode(summarizeClusterReport(clusterReport))
+	} else {
+		err = json.NewEncoder(w).Encode(clusterReport)
+	}
+	if err != nil {
+		h.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write response: %v", err))
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// clusterReportHistory is the number of previous reports kept in each ClusterReport.
+	// 0 means no history is kept.
+	clusterReportHistory int
+)
+
+// SetClusterReportHistory sets the number of previous reports kept in each ClusterReport
+func SetClusterReportHistory(history int) {
+	clusterReportHistory = history
+}
+
+// sameReports returns true if current and desired contain the same reports.
+// A nil and an empty list are considered equal.
+func sameReports[T any](current, desired []T) bool {
+	if len(current) == 0 && len(desired) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(current, desired)
+}
+
+// archiveClusterReport must be invoked right before ClusterReport reports are changed.
+// If history is enabled, current reports are added to the ClusterReport History, which is then
+// pruned. LastUpdateTime is set to now.
+func archiveClusterReport(clusterReport *configv1beta1.ClusterReport, now time.Time) {
+	status := &clusterReport.Status
+
+	if clusterReportHistory > 0 && (len(status.ReleaseReports) != 0 || len(status.ResourceReports) != 0 ||
+		len(status.KustomizeResourceReports) != 0) {
+
+		// ClusterReports updated before LastUpdateTime was introduced
+		generated := clusterReport.CreationTimestamp
+		if status.LastUpdateTime != nil {
+			generated = *status.LastUpdateTime
+		}
+
+		snapshot := configv1beta1.ClusterReportSnapshot{
+			Time:                     generated,
+			ReleaseReports:           status.ReleaseReports,
+			ResourceReports:          status.ResourceReports,
+			KustomizeResourceReports: status.KustomizeResourceReports,
+		}
+		status.History = append([]configv1beta1.ClusterReportSnapshot{snapshot}, status.History...)
+	}
+
+	pruneClusterReportHistory(clusterReport)
+	status.LastUpdateTime = &metav1.Time{Time: now}
+}
+
+// pruneClusterReportHistory removes the oldest entries exceeding clusterReportHistory.
+// Returns true if any entry was removed.
+func pruneClusterReportHistory(clusterReport *configv1beta1.ClusterReport) bool {
+	if len(clusterReport.Status.History) <= clusterReportHistory {
+		return false
+	}
+
+	if clusterReportHistory == 0 {
+		clusterReport.Status.History = nil
+	} else {
+		clusterReport.Status.History = clusterReport.Status.History[:clusterReportHistory]
+	}
+	return true
+}
+
+// ClusterReportReconciler prunes ClusterReport History. History is also pruned every time a
+// ClusterReport is updated. This reconciler takes care of ClusterReports not updated anymore
+// (for instance once a profile is moved away from DryRun mode) after cluster-report-history is lowered.
+type ClusterReportReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+	Logger               logr.Logger
+}
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports/status,verbs=get;list;update
+
+func (r *ClusterReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	clusterReport := &configv1beta1.ClusterReport{}
+	if err := r.Get(ctx, req.NamespacedName, clusterReport); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to fetch ClusterReport %s", req.NamespacedName)
+	}
+
+	if !clusterReport.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	if !pruneClusterReportHistory(clusterReport) {
+		return reconcile.Result{}, nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("pruning history to %d entries", clusterReportHistory))
+	if err := r.Status().Update(ctx, clusterReport); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to prune ClusterReport %s history", req.NamespacedName)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&configv1beta1.ClusterReport{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Complete(r)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("ClusterReport history", func() {
+	var clusterReport *configv1beta1.ClusterReport
+
+	BeforeEach(func() {
+		clusterReport = &configv1beta1.ClusterReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Status: configv1beta1.ClusterReportStatus{
+				ResourceReports: []configv1beta1.ResourceReport{
+					{Action: string(configv1beta1.CreateResourceAction)},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetClusterReportHistory(0)
+	})
+
+	It("archiveClusterReport does not keep history when disabled", func() {
+		controllers.SetClusterReportHistory(0)
+		now := time.Now()
+		controllers.ArchiveClusterReport(clusterReport, now)
+		Expect(clusterReport.Status.History).To(BeEmpty())
+		Expect(clusterReport.Status.LastUpdateTime).ToNot(BeNil())
+		Expect(clusterReport.Status.LastUpdateTime.Time).To(Equal(now))
+	})
+
+	It("archiveClusterReport keeps last N reports, most recent first", func() {
+		controllers.SetClusterReportHistory(2)
+
+		first := time.Now().Add(-time.Hour)
+		clusterReport.Status.LastUpdateTime = &metav1.Time{Time: first}
+
+		second := first.Add(time.Minute)
+		controllers.ArchiveClusterReport(clusterReport, second)
+		Expect(clusterReport.Status.History).To(HaveLen(1))
+		Expect(clusterReport.Status.History[0].Time.Time).To(Equal(first))
+
+		third := second.Add(time.Minute)
+		controllers.ArchiveClusterReport(clusterReport, third)
+		fourth := third.Add(time.Minute)
+		controllers.ArchiveClusterReport(clusterReport, fourth)
+
+		Expect(clusterReport.Status.History).To(HaveLen(2))
+		Expect(clusterReport.Status.History[0].Time.Time).To(Equal(third))
+		Expect(clusterReport.Status.History[1].Time.Time).To(Equal(second))
+		Expect(clusterReport.Status.History[0].ResourceReports).To(HaveLen(1))
+	})
+
+	It("pruneClusterReportHistory removes oldest entries", func() {
+		clusterReport.Status.History = []configv1beta1.ClusterReportSnapshot{
+			{Time: metav1.Now()}, {Time: metav1.Now()}, {Time: metav1.Now()},
+		}
+
+		controllers.SetClusterReportHistory(3)
+		Expect(controllers.PruneClusterReportHistory(clusterReport)).To(BeFalse())
+
+		controllers.SetClusterReportHistory(1)
+		Expect(controllers.PruneClusterReportHistory(clusterReport)).To(BeTrue())
+		Expect(clusterReport.Status.History).To(HaveLen(1))
+
+		controllers.SetClusterReportHistory(0)
+		Expect(controllers.PruneClusterReportHistory(clusterReport)).To(BeTrue())
+		Expect(clusterReport.Status.History).To(BeNil())
+	})
+})
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
@@ -32,9 +33,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	"sigs.k8s.io/cluster-api/util/annotations"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -46,6 +47,7 @@ import (
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
@@ -96,7 +98,10 @@ type ClusterSummaryReconciler struct {
 	ClusterMap           map[corev1.ObjectReference]*libsveltosset.Set // key: Sveltos/Cluster; value: set of all ClusterSummaries for that Cluster
 
 	ConflictRetryTime time.Duration
-	ctrl              controller.Controller
+	// ChartVersionPollInterval is how often repositories of helm charts with a SemverRange/Latest
+	// VersionPolicy are polled for new versions. Zero disables polling.
+	ChartVersionPollInterval time.Duration
+	ctrl                     controller.Controller
 }
 
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clustersummaries,verbs=get;list;watch;create;update;patch;delete
@@ -106,8 +111,13 @@ type ClusterSummaryReconciler struct {
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterconfigurations/status,verbs=get;list;update
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports,verbs=get;list;watch
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports/status,verbs=get;list;update
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=helmvaluespresets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=clusterhealthchecks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=sveltosclusters,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;watch;list
 //+kubebuilder:rbac:groups="infrastructure.cluster.x-k8s.io",resources="*",verbs=get;watch;list
 //+kubebuilder:rbac:groups="source.toolkit.fluxcd.io",resources=gitrepositories,verbs=get;watch;list
@@ -197,6 +207,20 @@ func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return r.reconcileDelete(ctx, clusterSummaryScope, logger)
 	}
 
+	// A paused cluster might be still provisioning. Check for pause before readiness so features
+	// are not reported as failed while deployments are suspended.
+	pausedReason, err := getPausedReason(ctx, r.Client, clusterSummary)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	setPausedCondition(clusterSummary, pausedReason)
+	if pausedReason != "" {
+		logger.V(logs.LogInfo).Info("cluster is paused. Do nothing.")
+		// When cluster is unpaused, all matching clusterSummaries will be requeued for reconciliation
+		_ = r.updateMaps(clusterSummaryScope, logger)
+		return reconcile.Result{}, nil
+	}
+
 	isReady, err := r.isReady(ctx, clusterSummary, logger)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
@@ -208,12 +232,25 @@ func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// if cluster is not ready, do nothing and don't queue for reconciliation.
 		// When cluster becomes ready, all matching clusterSummaries will be requeued for reconciliation
 		_ = r.updateMaps(clusterSummaryScope, logger)
+		updateClusterSummaryStatusSummary(clusterSummary)
 
+		// still requeue, if needed, to report ClusterSummary as stale once sync SLO window expires
+		if left := updateSyncStaleCondition(clusterSummaryScope, time.Now()); left > 0 {
+			return reconcile.Result{Requeue: true, RequeueAfter: left}, nil
+		}
 		return reconcile.Result{}, nil
 	}
 
 	// Handle non-deleted clusterSummary
-	return r.reconcileNormal(ctx, clusterSummaryScope, logger)
+	result, err := r.reconcileNormal(ctx, clusterSummaryScope, logger)
+	updateClusterSummaryStatusSummary(clusterSummary)
+	if left := updateSyncStaleCondition(clusterSummaryScope, time.Now()); left > 0 {
+		if result.RequeueAfter == 0 || left < result.RequeueAfter {
+			result.Requeue = true
+			result.RequeueAfter = left
+		}
+	}
+	return result, err
 }
 
 func (r *ClusterSummaryReconciler) reconcileDelete(
@@ -245,6 +282,18 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 			return reconcile.Result{}, nil
 		}
 
+		unmanaged, err := r.isUnmanaged(ctx, clusterSummaryScope.ClusterSummary)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if unmanaged {
+			// Nothing is removed from a cluster which opted out of management. Deployed resources are
+			// left in place and the ClusterSummary is released.
+			logger.V(logs.LogInfo).Info("cluster has opted out of management. Leave deployed resources in place.")
+			r.cleanupQueuedCleanOperations(clusterSummaryScope.ClusterSummary)
+			return r.finalizeDelete(ctx, clusterSummaryScope, logger)
+		}
+
 		if !isDeleted {
 			// if cluster is marked for deletion do not try to remove ResourceSummaries.
 			// those are only deployed in the managed cluster so no need to cleanup on a deleted cluster
@@ -255,12 +304,20 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 			}
 		}
 
+		if !isDeleted {
+			err = r.removeProfileIdentity(ctx, clusterSummaryScope, logger)
+			if err != nil {
+				logger.V(logs.LogInfo).Error(err, "failed to remove profile ServiceAccount.")
+				return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
+			}
+		}
+
 		// still call undeploy even if cluster is deleted. Sveltos might have deployed resources
 		// in the management cluster and those need to be removed.
 		err = r.undeploy(ctx, clusterSummaryScope, logger)
 		if err != nil {
 			// In DryRun mode it is expected to always get an error back
-			if !clusterSummaryScope.IsDryRunSync() {
+			if !isDryRunSync(clusterSummaryScope.ClusterSummary) {
 				logger.V(logs.LogInfo).Error(err, "failed to undeploy")
 				return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
 			}
@@ -289,6 +346,16 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 	}
 
 	// Cluster is not present anymore or cleanup succeeded
+	return r.finalizeDelete(ctx, clusterSummaryScope, logger)
+}
+
+// finalizeDelete removes the ClusterSummary finalizer and any in-memory state kept for the ClusterSummary
+func (r *ClusterSummaryReconciler) finalizeDelete(
+	ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger,
+) (reconcile.Result, error) {
+
 	logger.V(logs.LogInfo).Info("Removing finalizer")
 	if controllerutil.ContainsFinalizer(clusterSummaryScope.ClusterSummary, configv1beta1.ClusterSummaryFinalizer) {
 		if finalizersUpdated := controllerutil.RemoveFinalizer(clusterSummaryScope.ClusterSummary,
@@ -326,6 +393,8 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		}
 	}
 
+	r.processRedeployRequest(clusterSummaryScope, logger)
+
 	if !r.shouldReconcile(clusterSummaryScope, logger) {
 		logger.V(logs.LogInfo).Info("ClusterSummary does not need a reconciliation")
 		return reconcile.Result{}, nil
@@ -336,12 +405,44 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		return reconcile.Result{}, err
 	}
 
-	paused, err := r.isPaused(ctx, clusterSummaryScope.ClusterSummary)
+	err = r.updateMissingReferences(ctx, clusterSummaryScope, logger)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	if paused {
-		logger.V(logs.LogInfo).Info("cluster is paused. Do nothing.")
+
+	unmanaged, err := r.isUnmanaged(ctx, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if unmanaged {
+		logger.V(logs.LogInfo).Info("cluster has opted out of management. Do nothing.")
+		return reconcile.Result{}, nil
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	bg := getActiveBreakGlass(clusterSummary.Annotations, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType, time.Now(), logger)
+
+	now := time.Now()
+	waitFor, err := getTimeUntilMaintenanceWindow(ctx, r.Client, clusterSummary, now)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate cluster maintenance window: %v", err))
+		r.setFailureMessage(clusterSummaryScope, err.Error())
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if waitFor > 0 {
+		if bg == nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("outside cluster maintenance window. Next window opens in %s", waitFor))
+			setMaintenanceWindowCondition(clusterSummary, waitFor, now)
+			return reconcile.Result{Requeue: true, RequeueAfter: waitFor}, nil
+		}
+		recordBreakGlass(bg, "maintenance window", logger)
+	}
+	setMaintenanceWindowCondition(clusterSummary, 0, now)
+
+	// A Spec violating the Guardrails is not retried till it changes
+	if err := updateGuardrailsCondition(clusterSummary); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("spec violates guardrails: %v", err))
 		return reconcile.Result{}, nil
 	}
 
@@ -360,6 +461,31 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
+	lowerTiersProvisioned, msg, err := r.areLowerTiersProvisioned(ctx, clusterSummaryScope, logger)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if !lowerTiersProvisioned {
+		clusterSummaryScope.SetDependenciesMessage(&msg)
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	healthChecksPassing, msg, err := r.areHealthChecksPassing(ctx, clusterSummaryScope, logger)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if !healthChecksPassing {
+		if bg == nil {
+			clusterSummaryScope.SetDependenciesMessage(&msg)
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+		}
+		recordBreakGlass(bg, "health check gates", logger)
+	}
+
+	if !r.runPreflight(ctx, clusterSummary, logger) {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
 	err = r.updateChartMap(ctx, clusterSummaryScope, logger)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
@@ -381,10 +507,17 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 			logger.V(logs.LogInfo).Error(err, "failed to deploy because of conflict")
 			return reconcile.Result{Requeue: true, RequeueAfter: r.ConflictRetryTime}, nil
 		}
+		var throttledErr *ClusterThrottledError
+		if errors.As(err, &throttledErr) {
+			logger.V(logs.LogInfo).Info(err.Error())
+			return reconcile.Result{Requeue: true, RequeueAfter: throttledErr.RetryAfter}, nil
+		}
 		logger.V(logs.LogInfo).Error(err, "failed to deploy")
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
+	setSyncSucceeded(clusterSummaryScope, time.Now())
+
 	logger.V(logs.LogInfo).Info("Reconciling ClusterSummary success")
 	return reconcile.Result{}, nil
 }
@@ -414,6 +547,9 @@ func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctr
 				SecretPredicates(mgr.GetLogger().WithValues("predicate", "secretpredicate")),
 			),
 		).
+		Watches(&configv1beta1.HelmValuesPreset{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForReference),
+		).
 		Build(r)
 	if err != nil {
 		return errors.Wrap(err, "error creating controller")
@@ -426,6 +562,11 @@ func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctr
 		go collectAndProcessResourceSummaries(ctx, mgr.GetClient(), r.ShardKey, r.Version, mgr.GetLogger())
 	}
 
+	if r.ChartVersionPollInterval > 0 {
+		go pollChartVersions(ctx, mgr.GetClient(), r.ShardKey, r.ChartVersionPollInterval,
+			mgr.GetLogger().WithName("chart-version-poller"))
+	}
+
 	initializeManager(ctrl.Log.WithName("watchers"), mgr.GetConfig(), mgr.GetClient())
 
 	r.ctrl = c
@@ -506,12 +647,20 @@ func (r *ClusterSummaryReconciler) deploy(ctx context.Context, clusterSummarySco
 	clusterSummary := clusterSummaryScope.ClusterSummary
 	logger = logger.WithValues("clusternamespace", clusterSummary.Spec.ClusterNamespace, "clustername", clusterSummary.Spec.ClusterName)
 
+	if len(clusterSummary.Spec.ClusterProfileSpec.DeploymentOrder) != 0 {
+		return r.deployInOrder(ctx, clusterSummaryScope, logger)
+	}
+
 	resourceErr := r.deployResources(ctx, clusterSummaryScope, logger)
 
 	helmErr := r.deployHelm(ctx, clusterSummaryScope, logger)
 
 	kustomizeError := r.deployKustomizeRefs(ctx, clusterSummaryScope, logger)
 
+	jobsErr := r.deployJobs(ctx, clusterSummaryScope, logger)
+
+	extensionsErr := r.deployExtensions(ctx, clusterSummaryScope, logger)
+
 	if resourceErr != nil {
 		return resourceErr
 	}
@@ -524,9 +673,45 @@ func (r *ClusterSummaryReconciler) deploy(ctx context.Context, clusterSummarySco
 		return kustomizeError
 	}
 
+	if jobsErr != nil {
+		return jobsErr
+	}
+
+	if extensionsErr != nil {
+		return extensionsErr
+	}
+
 	return nil
 }
 
+func (r *ClusterSummaryReconciler) deployJobs(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Jobs == nil {
+		logger.V(logs.LogDebug).Info("no jobs configuration")
+		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureJobs) {
+			logger.V(logs.LogDebug).Info("no jobs status. Do not reconcile this")
+			return nil
+		}
+	}
+
+	f := getHandlersForFeature(configv1beta1.FeatureJobs)
+
+	return r.deployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
+func (r *ClusterSummaryReconciler) deployExtensions(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Extensions == nil {
+		logger.V(logs.LogDebug).Info("no extensions configuration")
+		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureExtensions) {
+			logger.V(logs.LogDebug).Info("no extensions status. Do not reconcile this")
+			return nil
+		}
+	}
+
+	f := getHandlersForFeature(configv1beta1.FeatureExtensions)
+
+	return r.deployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
 func (r *ClusterSummaryReconciler) deployKustomizeRefs(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs == nil {
 		logger.V(logs.LogDebug).Info("no kustomize policy configuration")
@@ -542,7 +727,8 @@ func (r *ClusterSummaryReconciler) deployKustomizeRefs(ctx context.Context, clus
 }
 
 func (r *ClusterSummaryReconciler) deployResources(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
-	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs == nil {
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs == nil &&
+		clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.InlineResources == nil {
 		logger.V(logs.LogDebug).Info("no policy configuration")
 		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureResources) {
 			logger.V(logs.LogDebug).Info("no policy status. Do not reconcile this")
@@ -594,6 +780,10 @@ func (r *ClusterSummaryReconciler) undeploy(ctx context.Context, clusterSummaryS
 
 	helmErr := r.undeployHelm(ctx, clusterSummaryScope, logger)
 
+	jobsErr := r.undeployJobs(ctx, clusterSummaryScope, logger)
+
+	extensionsErr := r.undeployExtensions(ctx, clusterSummaryScope, logger)
+
 	if resourceErr != nil {
 		return resourceErr
 	}
@@ -606,6 +796,14 @@ func (r *ClusterSummaryReconciler) undeploy(ctx context.Context, clusterSummaryS
 		return helmErr
 	}
 
+	if jobsErr != nil {
+		return jobsErr
+	}
+
+	if extensionsErr != nil {
+		return extensionsErr
+	}
+
 	return nil
 }
 
@@ -624,6 +822,26 @@ func (r *ClusterSummaryReconciler) undeployHelm(ctx context.Context, clusterSumm
 	return r.undeployFeature(ctx, clusterSummaryScope, f, logger)
 }
 
+func (r *ClusterSummaryReconciler) undeployJobs(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	// Jobs were never run. Nothing to clean up.
+	if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureJobs) {
+		return nil
+	}
+
+	f := getHandlersForFeature(configv1beta1.FeatureJobs)
+	return r.undeployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
+func (r *ClusterSummaryReconciler) undeployExtensions(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	// Extensions were never deployed. Nothing to clean up.
+	if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureExtensions) {
+		return nil
+	}
+
+	f := getHandlersForFeature(configv1beta1.FeatureExtensions)
+	return r.undeployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
 func (r *ClusterSummaryReconciler) updateChartMap(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
 	logger logr.Logger) error {
 
@@ -644,9 +862,15 @@ func (r *ClusterSummaryReconciler) updateChartMap(ctx context.Context, clusterSu
 		return err
 	}
 
+	// Helm releases are registered with their name and namespace instantiated for this cluster
+	clusterSummary, err := instantiateHelmReleases(ctx, clusterSummaryScope.ClusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
 	// First try to be elected manager. Only if that succeeds, manage an helm chart.
 	logger.V(logs.LogDebug).Info("register clustersummary with helm chart manager")
-	chartManager.RegisterClusterSummaryForCharts(clusterSummaryScope.ClusterSummary)
+	chartManager.RegisterClusterSummaryForCharts(clusterSummary)
 
 	// Registration for helm chart not referenced anymore, are cleaned only after such helm
 	// chart are removed from Sveltos/Cluster. That is done as part of deployHelmCharts and
@@ -789,7 +1013,8 @@ func (r *ClusterSummaryReconciler) shouldReconcile(clusterSummaryScope *scope.Cl
 		return true
 	}
 
-	if len(clusterSummary.Spec.ClusterProfileSpec.PolicyRefs) != 0 {
+	if len(clusterSummary.Spec.ClusterProfileSpec.PolicyRefs) != 0 ||
+		len(clusterSummary.Spec.ClusterProfileSpec.InlineResources) != 0 {
 		if !r.isFeatureDeployed(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureResources) {
 			logger.V(logs.LogDebug).Info("Mode set to one time. Resources not deployed yet. Reconciliation is needed.")
 			return true
@@ -810,6 +1035,20 @@ func (r *ClusterSummaryReconciler) shouldReconcile(clusterSummaryScope *scope.Cl
 		}
 	}
 
+	if len(clusterSummary.Spec.ClusterProfileSpec.Jobs) != 0 {
+		if !r.isFeatureDeployed(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureJobs) {
+			logger.V(logs.LogDebug).Info("Mode set to one time. Jobs not completed yet. Reconciliation is needed.")
+			return true
+		}
+	}
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.Extensions) != 0 {
+		if !r.isFeatureDeployed(clusterSummaryScope.ClusterSummary, configv1beta1.FeatureExtensions) {
+			logger.V(logs.LogDebug).Info("Mode set to one time. Extensions not deployed yet. Reconciliation is needed.")
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -833,6 +1072,120 @@ func (r *ClusterSummaryReconciler) getCurrentReferences(clusterSummaryScope *sco
 	}
 	currentReferences.Append(helmRefs)
 
+	jobRefs, err := r.getJobRefReferences(clusterSummaryScope)
+	if err != nil {
+		return nil, err
+	}
+	currentReferences.Append(jobRefs)
+
+	certificateRef, err := getSealedSecretsCertificateReference(clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return nil, err
+	}
+	if certificateRef != nil {
+		currentReferences.Insert(certificateRef)
+	}
+
+	wasmPluginsRefs, err := getWasmPluginsReferences(clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return nil, err
+	}
+	for i := range wasmPluginsRefs {
+		currentReferences.Insert(wasmPluginsRefs[i])
+	}
+
+	return currentReferences, nil
+}
+
+// updateMissingReferences verifies all ConfigMaps/Secrets referenced by ClusterSummary exist and
+// reports, in the ClusterSummary Status, the ones which don't.
+// ClusterSummary is watching all referenced ConfigMaps/Secrets (ReferenceMap), so as soon as a missing
+// resource is created, ClusterSummary is reconciled again and deployment resumes.
+func (r *ClusterSummaryReconciler) updateMissingReferences(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+
+	type featureReferences struct {
+		featureID  configv1beta1.FeatureID
+		references func(*scope.ClusterSummaryScope) (*libsveltosset.Set, error)
+	}
+
+	features := []featureReferences{
+		{featureID: configv1beta1.FeatureResources, references: r.getPolicyRefReferences},
+		{featureID: configv1beta1.FeatureKustomize, references: r.getKustomizationRefReferences},
+		{featureID: configv1beta1.FeatureHelm, references: r.getHelmChartsReferences},
+		{featureID: configv1beta1.FeatureJobs, references: r.getJobRefReferences},
+	}
+
+	var missingReferences []configv1beta1.MissingReference
+	for i := range features {
+		references, err := features[i].references(clusterSummaryScope)
+		if err != nil {
+			return err
+		}
+
+		items := references.Items()
+		sort.Slice(items, func(i, j int) bool {
+			return fmt.Sprintf("%s/%s/%s", items[i].Kind, items[i].Namespace, items[i].Name) <
+				fmt.Sprintf("%s/%s/%s", items[j].Kind, items[j].Namespace, items[j].Name)
+		})
+
+		for j := range items {
+			var object client.Object
+			switch items[j].Kind {
+			case string(libsveltosv1beta1.ConfigMapReferencedResourceKind):
+				object = &corev1.ConfigMap{}
+			case string(libsveltosv1beta1.SecretReferencedResourceKind):
+				object = &corev1.Secret{}
+			default:
+				continue
+			}
+
+			err = r.Client.Get(ctx, types.NamespacedName{Namespace: items[j].Namespace, Name: items[j].Name}, object)
+			if err == nil {
+				continue
+			}
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("referenced %s %s/%s does not exist",
+				items[j].Kind, items[j].Namespace, items[j].Name))
+			missingReferences = append(missingReferences, configv1beta1.MissingReference{
+				FeatureID: features[i].featureID,
+				Kind:      items[j].Kind,
+				Namespace: items[j].Namespace,
+				Name:      items[j].Name,
+			})
+		}
+	}
+
+	clusterSummaryScope.ClusterSummary.Status.MissingReferences = missingReferences
+	return nil
+}
+
+// getJobRefReferences get all references considering the Jobs section
+func (r *ClusterSummaryReconciler) getJobRefReferences(clusterSummaryScope *scope.ClusterSummaryScope,
+) (*libsveltosset.Set, error) {
+
+	currentReferences := &libsveltosset.Set{}
+	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Jobs {
+		jobRef := &clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Jobs[i]
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummaryScope.Namespace(), jobRef.Namespace)
+
+		cs := clusterSummaryScope.ClusterSummary
+		referencedName, err := libsveltostemplate.GetReferenceResourceName(cs.Spec.ClusterNamespace, cs.Spec.ClusterName,
+			string(cs.Spec.ClusterType), jobRef.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		currentReferences.Insert(&corev1.ObjectReference{
+			APIVersion: corev1.SchemeGroupVersion.String(), // the only resources that can be referenced are Secret and ConfigMap
+			Kind:       jobRef.Kind,
+			Namespace:  namespace,
+			Name:       referencedName,
+		})
+	}
 	return currentReferences, nil
 }
 
@@ -952,7 +1305,23 @@ func (r *ClusterSummaryReconciler) getHelmChartsReferences(clusterSummaryScope *
 			return nil, err
 		}
 		currentReferences.Append(valuesFromReferences)
+		currentReferences.Append(getValuesPresetsReferences(hc))
+
+		// Chart stored in a Flux source. ClusterSummary must be reconciled when source changes.
+		if s := getFluxChartSource(hc); s != nil {
+			currentReferences.Insert(s.getObjectReference())
+		}
+	}
+
+	// ClusterSummary must be reconciled when the chart versions ConfigMap changes
+	chartVersionsRef, err := getChartVersionsReference(clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return nil, err
 	}
+	if chartVersionsRef != nil {
+		currentReferences.Insert(chartVersionsRef)
+	}
+
 	return currentReferences, nil
 }
 
@@ -1026,21 +1395,20 @@ func (r *ClusterSummaryReconciler) isReady(ctx context.Context,
 func (r *ClusterSummaryReconciler) isPaused(ctx context.Context,
 	clusterSummary *configv1beta1.ClusterSummary) (bool, error) {
 
-	isClusterPaused, err := clusterproxy.IsClusterPaused(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
-		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
-
+	reason, err := getPausedReason(ctx, r.Client, clusterSummary)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return false, nil
-		}
 		return false, err
 	}
 
-	if isClusterPaused {
-		return true, nil
-	}
+	return reason != "", nil
+}
 
-	return annotations.HasPaused(clusterSummary), nil
+// isUnmanaged returns true if Sveltos/Cluster has opted out of management with UnmanagedLabel
+func (r *ClusterSummaryReconciler) isUnmanaged(ctx context.Context,
+	clusterSummary *configv1beta1.ClusterSummary) (bool, error) {
+
+	return isClusterUnmanaged(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 }
 
 // canRemoveFinalizer returns true if finalizer can be removed.
@@ -1105,10 +1473,15 @@ func (r *ClusterSummaryReconciler) canRemoveFinalizer(ctx context.Context,
 func (r *ClusterSummaryReconciler) removeResourceSummary(ctx context.Context,
 	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
 
+	// Nothing is ever removed from managed clusters in observe-only mode
+	if observeOnly {
+		return nil
+	}
+
 	// ResourceSummary is a Sveltos resource deployed in managed clusters.
 	// Such resources are always created, removed using cluster-admin roles.
 	cs := clusterSummaryScope.ClusterSummary
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, r.Client, cs.Spec.ClusterNamespace,
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, r.Client, cs.Spec.ClusterNamespace,
 		cs.Spec.ClusterName, "", "", cs.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -1128,6 +1501,25 @@ func (r *ClusterSummaryReconciler) removeResourceSummary(ctx context.Context,
 	return err
 }
 
+func (r *ClusterSummaryReconciler) removeProfileIdentity(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+
+	// Nothing is ever removed from managed clusters in observe-only mode
+	if observeOnly {
+		return nil
+	}
+
+	// Profile ServiceAccount is created, and so removed, using cluster-admin roles
+	cs := clusterSummaryScope.ClusterSummary
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, r.Client, cs.Spec.ClusterNamespace,
+		cs.Spec.ClusterName, "", "", cs.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	return removeProfileIdentity(ctx, remoteClient, cs, logger)
+}
+
 func (r *ClusterSummaryReconciler) updateClusterShardPair(ctx context.Context,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
 
@@ -1168,6 +1560,12 @@ func (r *ClusterSummaryReconciler) isClusterAShardMatch(ctx context.Context,
 		return false, nil
 	}
 
+	if !isMigrationVersionAMatch(cluster) {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("cluster is managed by addon-controller version %q",
+			cluster.GetAnnotations()[ControllerVersionAnnotation]))
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -1227,8 +1625,9 @@ func (r *ClusterSummaryReconciler) areDependenciesDeployed(ctx context.Context,
 		return false, "", fmt.Errorf("profile owner not found: %w", err)
 	}
 
-	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn {
-		profileName := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn[i]
+	dependencies := getProfileDependencies(&clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec)
+	for i := range dependencies {
+		profileName := dependencies[i]
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("Considering %s %s", profileReference.Kind, profileName))
 		var cs *configv1beta1.ClusterSummary
 		cs, err = getClusterSummary(ctx, r.Client, profileReference.Kind, profileName,
@@ -1252,7 +1651,7 @@ func (r *ClusterSummaryReconciler) areDependenciesDeployed(ctx context.Context,
 	}
 
 	dependencyMessage = "All dependencies deployed"
-	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn == nil {
+	if len(dependencies) == 0 {
 		dependencyMessage = "no dependencies"
 	}
 
@@ -1263,24 +1662,38 @@ func (r *ClusterSummaryReconciler) setFailureMessage(clusterSummaryScope *scope.
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.HelmCharts != nil {
 		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureHelm, &failureMessage)
 	}
-	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs != nil {
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs != nil ||
+		clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.InlineResources != nil {
 		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureResources, &failureMessage)
 	}
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs != nil {
 		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureKustomize, &failureMessage)
 	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Jobs != nil {
+		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureJobs, &failureMessage)
+	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Extensions != nil {
+		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureExtensions, &failureMessage)
+	}
 }
 
 func (r *ClusterSummaryReconciler) resetFeatureStatus(clusterSummaryScope *scope.ClusterSummaryScope, status configv1beta1.FeatureStatus) {
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.HelmCharts != nil {
 		clusterSummaryScope.SetFeatureStatus(configv1beta1.FeatureHelm, status, nil)
 	}
-	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs != nil {
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs != nil ||
+		clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.InlineResources != nil {
 		clusterSummaryScope.SetFeatureStatus(configv1beta1.FeatureResources, status, nil)
 	}
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs != nil {
 		clusterSummaryScope.SetFeatureStatus(configv1beta1.FeatureKustomize, status, nil)
 	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Jobs != nil {
+		clusterSummaryScope.SetFeatureStatus(configv1beta1.FeatureJobs, status, nil)
+	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Extensions != nil {
+		clusterSummaryScope.SetFeatureStatus(configv1beta1.FeatureExtensions, status, nil)
+	}
 }
 
 func (r *ClusterSummaryReconciler) GetController() controller.Controller {
@@ -1315,4 +1728,10 @@ func (r *ClusterSummaryReconciler) cleanupQueuedCleanOperations(clusterSummary *
 
 	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Name,
 		string(configv1beta1.FeatureResources), clusterSummary.Spec.ClusterType, true)
+
+	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Name,
+		string(configv1beta1.FeatureJobs), clusterSummary.Spec.ClusterType, true)
+
+	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Name,
+		string(configv1beta1.FeatureExtensions), clusterSummary.Spec.ClusterType, true)
 }
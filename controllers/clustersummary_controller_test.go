@@ -28,6 +28,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
@@ -187,6 +188,20 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(controllers.IsPaused(reconciler, context.TODO(), clusterSummary)).To(BeFalse())
 	})
 
+	It("setPausedCondition reports whether deployments are suspended", func() {
+		controllers.SetPausedCondition(clusterSummary, configv1beta1.ClusterPausedReason)
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.PausedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.ClusterPausedReason))
+
+		controllers.SetPausedCondition(clusterSummary, "")
+		condition = meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.PausedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.NotPausedReason))
+	})
+
 	It("shouldReconcile returns true when mode is Continuous", func() {
 		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1beta1.SyncModeContinuous
 
@@ -892,6 +907,54 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(items[0].Namespace).To(Equal(clusterSummary.Namespace))
 	})
 
+	It("updateMissingReferences reports referenced ConfigMaps/Secrets which do not exist", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Namespace, Name: randomString()},
+		}
+		missingSecretName := randomString()
+
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1beta1.PolicyRef{
+			{Name: configMap.Name, Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+		}
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts = []configv1beta1.HelmChart{
+			{
+				RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+				ReleaseName: randomString(), ReleaseNamespace: randomString(),
+				ValuesFrom: []configv1beta1.ValueFrom{
+					{Name: missingSecretName, Kind: string(libsveltosv1beta1.SecretReferencedResourceKind)},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+		clusterSummaryScope := getClusterSummaryScope(c,
+			textlogger.NewLogger(textlogger.NewConfig()), clusterProfile, clusterSummary)
+		reconciler := getClusterSummaryReconciler(c, nil)
+		Expect(controllers.UpdateMissingReferences(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		missingReferences := clusterSummaryScope.ClusterSummary.Status.MissingReferences
+		Expect(len(missingReferences)).To(Equal(1))
+		Expect(missingReferences[0].FeatureID).To(Equal(configv1beta1.FeatureHelm))
+		Expect(missingReferences[0].Kind).To(Equal(string(libsveltosv1beta1.SecretReferencedResourceKind)))
+		Expect(missingReferences[0].Namespace).To(Equal(clusterSummary.Namespace))
+		Expect(missingReferences[0].Name).To(Equal(missingSecretName))
+
+		labels := controllers.GetMissingReferenceInfo(clusterSummaryScope.ClusterSummary)
+		Expect(len(labels)).To(Equal(1))
+		Expect(labels[0]).To(ContainElement(missingSecretName))
+
+		// Once created, the Secret is not reported anymore
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Namespace, Name: missingSecretName},
+		}
+		Expect(c.Create(context.TODO(), secret)).To(Succeed())
+		Expect(controllers.UpdateMissingReferences(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		Expect(clusterSummaryScope.ClusterSummary.Status.MissingReferences).To(BeEmpty())
+	})
+
 	It("reconcileDelete successfully returns when cluster is not found", func() {
 		clusterSummary.Spec.ClusterProfileSpec.HelmCharts = []configv1beta1.HelmChart{
 			{RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(), ReleaseName: randomString()},
@@ -930,6 +993,46 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(result.Requeue).To(BeFalse())
 	})
 
+	It("reconcileDelete removes finalizer without cleaning up a cluster which opted out of management", func() {
+		cluster.Labels[controllers.UnmanagedLabel] = "true"
+		cluster.Status.ControlPlaneReady = true
+
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1beta1.PolicyRef{
+			{Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+		}
+		clusterSummary.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+			{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned},
+		}
+		controllerutil.AddFinalizer(clusterSummary, configv1beta1.ClusterSummaryFinalizer)
+
+		initObjects := []client.Object{
+			clusterProfile,
+			clusterSummary,
+			cluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		dep := fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c)
+		clusterSummaryReconciler := getClusterSummaryReconciler(c, dep)
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		var result reconcile.Result
+		result, err = controllers.ReconcileDelete(clusterSummaryReconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(result.Requeue).To(BeFalse())
+		Expect(controllerutil.ContainsFinalizer(clusterSummaryScope.ClusterSummary,
+			configv1beta1.ClusterSummaryFinalizer)).To(BeFalse())
+	})
+
 	It("areDependenciesDeployed returns true when all dependencies are deployed", func() {
 		clusterProfileAName := randomString()
 		clusterSummaryAName := controllers.GetClusterSummaryName(configv1beta1.ClusterProfileKind,
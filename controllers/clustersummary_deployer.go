@@ -121,12 +121,45 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 		resultError = result.Err
 	}
 
-	if status != nil {
+	if status != nil && isClusterThrottlingError(resultError) {
+		// Not a failure. Hold back redeploying till managed cluster recovers.
+		if retryAfter := recordClusterThrottling(clusterSummary, f.id, resultError, time.Now()); retryAfter > 0 {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("managed cluster is throttling requests: %v", resultError))
+			return &ClusterThrottledError{RetryAfter: retryAfter}
+		}
+		// Backoff elapsed. Redeploy.
+		status = nil
+	}
+
+	if status != nil && isDeploymentSupersededError(resultError) {
+		// Not a failure. ClusterSummary changed while deploying. Deploy latest Spec.
+		logger.V(logs.LogDebug).Info(resultError.Error())
+		status = nil
+	}
+
+	var writeBudgetError *WriteBudgetExhaustedError
+	if status != nil && errors.As(resultError, &writeBudgetError) {
+		// Not a failure. Because of the WriteBudget resources are applied in chunks. Deploy next chunk.
+		logger.V(logs.LogDebug).Info(writeBudgetError.Error())
+		s := configv1beta1.FeatureStatusProvisioning
+		status = &s
+		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		clusterSummaryScope.SetFailureMessage(f.id, nil)
+		clusterSummaryScope.SetPendingResources(f.id, &writeBudgetError.Pending)
+	} else if status != nil {
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("result is available. updating status: %v", *status))
 		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, resultError, logger)
+		if *status == configv1beta1.FeatureStatusProvisioned || *status == configv1beta1.FeatureStatusFailed {
+			recordDeploymentOutcome(clusterSummary, f.id, *status == configv1beta1.FeatureStatusProvisioned,
+				time.Now())
+		}
 		if *status == configv1beta1.FeatureStatusProvisioned {
+			clearClusterThrottling(clusterSummary, f.id)
 			return nil
 		}
+		if *status == configv1beta1.FeatureStatusFailed {
+			r.processFeatureFailure(ctx, clusterSummaryScope, f.id, logger)
+		}
 		if resultError != nil {
 			// Check if error is a NonRetriableError type
 			var nonRetriableError *NonRetriableError
@@ -148,6 +181,10 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 
 	// Getting here means either feature failed to be deployed or configuration has changed.
 	// Feature must be (re)deployed.
+	if retryAfter := getClusterThrottleDelay(clusterSummary, time.Now()); retryAfter > 0 {
+		return &ClusterThrottledError{RetryAfter: retryAfter}
+	}
+
 	options := deployer.Options{HandlerOptions: map[string]string{}}
 	if r.AgentInMgmtCluster {
 		options.HandlerOptions[driftDetectionInMgtmCluster] = "management"
@@ -177,7 +214,9 @@ func genericDeploy(ctx context.Context, c client.Client,
 
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1beta1.FeatureID(featureID))
-	err := featureHandler.deploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	err := retryOnCertificateError(ctx, c, clusterNamespace, clusterName, clusterType, logger, func() error {
+		return featureHandler.deploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	})
 	if err != nil {
 		return err
 	}
@@ -200,6 +239,7 @@ func (r *ClusterSummaryReconciler) undeployFeature(ctx context.Context, clusterS
 
 	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Name,
 		string(f.id), clusterSummary.Spec.ClusterType, false)
+	forgetWriteBudgetProgress(clusterSummary, f.id)
 
 	// If deploying feature is in progress, wait for it to complete.
 	// Otherwise, if we cleanup feature while same feature is still being provisioned, if two workers process those request in
@@ -282,7 +322,10 @@ func genericUndeploy(ctx context.Context, c client.Client,
 
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1beta1.FeatureID(featureID))
-	if err := featureHandler.undeploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger); err != nil {
+	err = retryOnCertificateError(ctx, c, clusterNamespace, clusterName, clusterType, logger, func() error {
+		return featureHandler.undeploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -370,9 +413,12 @@ func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scop
 	case configv1beta1.FeatureStatusProvisioned:
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusProvisioned, hash)
 		clusterSummaryScope.SetFailureMessage(featureID, nil)
+		clusterSummaryScope.SetConsecutiveFailures(featureID, 0)
+		clusterSummaryScope.SetPendingResources(featureID, nil)
 	case configv1beta1.FeatureStatusRemoved:
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusRemoved, hash)
 		clusterSummaryScope.SetFailureMessage(featureID, nil)
+		clusterSummaryScope.SetConsecutiveFailures(featureID, 0)
 	case configv1beta1.FeatureStatusProvisioning:
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusProvisioning, hash)
 	case configv1beta1.FeatureStatusRemoving:
@@ -411,7 +457,7 @@ func (r *ClusterSummaryReconciler) convertResultStatus(result deployer.Result) *
 func (r *ClusterSummaryReconciler) shouldRedeploy(clusterSummaryScope *scope.ClusterSummaryScope, f feature,
 	isConfigSame bool, logger logr.Logger) bool {
 
-	if clusterSummaryScope.IsDryRunSync() {
+	if isDryRunSync(clusterSummaryScope.ClusterSummary) {
 		logger.V(logs.LogDebug).Info("dry run mode. Always redeploy.")
 		return true
 	}
@@ -48,6 +48,18 @@ func RegisterFeatures(d deployer.DeployerInterface, setupLog logr.Logger) {
 		os.Exit(1)
 	}
 
+	err = d.RegisterFeatureID(string(configv1beta1.FeatureJobs))
+	if err != nil {
+		setupLog.Error(err, "failed to register feature FeatureJobs")
+		os.Exit(1)
+	}
+
+	err = d.RegisterFeatureID(string(configv1beta1.FeatureExtensions))
+	if err != nil {
+		setupLog.Error(err, "failed to register feature FeatureExtensions")
+		os.Exit(1)
+	}
+
 	creatFeatureHandlerMaps()
 }
 
@@ -62,6 +74,12 @@ func creatFeatureHandlerMaps() {
 
 	featuresHandlers[configv1beta1.FeatureKustomize] = feature{id: configv1beta1.FeatureKustomize, currentHash: kustomizationHash,
 		deploy: deployKustomizeRefs, undeploy: undeployKustomizeRefs, getRefs: getKustomizationRefs}
+
+	featuresHandlers[configv1beta1.FeatureJobs] = feature{id: configv1beta1.FeatureJobs, currentHash: jobsHash,
+		deploy: deployJobs, undeploy: undeployJobs, getRefs: getJobRefs}
+
+	featuresHandlers[configv1beta1.FeatureExtensions] = feature{id: configv1beta1.FeatureExtensions, currentHash: extensionsHash,
+		deploy: deployExtensions, undeploy: undeployExtensions, getRefs: getExtensionRefs}
 }
 
 func getHandlersForFeature(featureID configv1beta1.FeatureID) feature {
@@ -0,0 +1,95 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// RedeployAnnotation is set on a ClusterSummary to force an immediate re-deployment of features
+	// in the matching cluster, even if nothing has changed. Value is a comma separated list of features
+	// to redeploy (case insensitive): Resources, Helm, Kustomize or Jobs. "all" redeploys all features.
+	// The annotation is removed once the request has been processed.
+	RedeployAnnotation = "projectsveltos.io/redeploy"
+
+	redeployAllFeatures = "all"
+)
+
+// getRedeployFeatures returns the features requested to be redeployed via RedeployAnnotation
+func getRedeployFeatures(value string) ([]configv1beta1.FeatureID, error) {
+	features := []configv1beta1.FeatureID{configv1beta1.FeatureResources, configv1beta1.FeatureHelm,
+		configv1beta1.FeatureKustomize, configv1beta1.FeatureJobs}
+	if strings.EqualFold(strings.TrimSpace(value), redeployAllFeatures) {
+		return features, nil
+	}
+
+	result := make([]configv1beta1.FeatureID, 0)
+	for _, entry := range strings.Split(value, ",") {
+		found := false
+		for i := range features {
+			if strings.EqualFold(strings.TrimSpace(entry), string(features[i])) {
+				result = append(result, features[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported feature %q", entry)
+		}
+	}
+
+	return result, nil
+}
+
+// processRedeployRequest handles RedeployAnnotation. Resetting the hash and status of the requested
+// features makes them look like never deployed, so they are redeployed even when SyncMode is OneTime.
+func (r *ClusterSummaryReconciler) processRedeployRequest(clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	value, ok := clusterSummary.Annotations[RedeployAnnotation]
+	if !ok {
+		return
+	}
+
+	// Remove annotation so the request is processed only once. Change is persisted when scope is closed.
+	delete(clusterSummary.Annotations, RedeployAnnotation)
+
+	featureIDs, err := getRedeployFeatures(value)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("ignoring %s annotation: %v", RedeployAnnotation, err))
+		return
+	}
+
+	for _, featureID := range featureIDs {
+		for i := range clusterSummary.Status.FeatureSummaries {
+			if clusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("redeploy of feature %s requested", featureID))
+				clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusProvisioning, nil)
+				break
+			}
+		}
+	}
+}
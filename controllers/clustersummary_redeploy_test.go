@@ -0,0 +1,115 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var _ = Describe("ClusterSummary redeploy", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned,
+						Hash: []byte(randomString())},
+					{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned,
+						Hash: []byte(randomString())},
+				},
+			},
+		}
+	})
+
+	processRedeployRequest := func() {
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		controllers.ProcessRedeployRequest(reconciler, clusterSummaryScope, textlogger.NewLogger(textlogger.NewConfig()))
+	}
+
+	It("processRedeployRequest resets only the requested feature and removes the annotation", func() {
+		resourcesHash := clusterSummary.Status.FeatureSummaries[1].Hash
+		clusterSummary.Annotations = map[string]string{controllers.RedeployAnnotation: "helm"}
+
+		processRedeployRequest()
+
+		Expect(clusterSummary.Annotations).ToNot(HaveKey(controllers.RedeployAnnotation))
+		Expect(clusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusProvisioning))
+		Expect(clusterSummary.Status.FeatureSummaries[0].Hash).To(BeNil())
+		Expect(clusterSummary.Status.FeatureSummaries[1].Status).To(Equal(configv1beta1.FeatureStatusProvisioned))
+		Expect(clusterSummary.Status.FeatureSummaries[1].Hash).To(Equal(resourcesHash))
+	})
+
+	It("processRedeployRequest resets all requested features", func() {
+		clusterSummary.Annotations = map[string]string{controllers.RedeployAnnotation: "Helm, resources"}
+
+		processRedeployRequest()
+
+		Expect(clusterSummary.Annotations).ToNot(HaveKey(controllers.RedeployAnnotation))
+		for i := range clusterSummary.Status.FeatureSummaries {
+			Expect(clusterSummary.Status.FeatureSummaries[i].Status).To(Equal(configv1beta1.FeatureStatusProvisioning))
+			Expect(clusterSummary.Status.FeatureSummaries[i].Hash).To(BeNil())
+		}
+	})
+
+	It("processRedeployRequest resets all features when all is requested", func() {
+		clusterSummary.Annotations = map[string]string{controllers.RedeployAnnotation: "all"}
+
+		processRedeployRequest()
+
+		for i := range clusterSummary.Status.FeatureSummaries {
+			Expect(clusterSummary.Status.FeatureSummaries[i].Status).To(Equal(configv1beta1.FeatureStatusProvisioning))
+		}
+	})
+
+	It("processRedeployRequest ignores unsupported features", func() {
+		clusterSummary.Annotations = map[string]string{controllers.RedeployAnnotation: randomString()}
+
+		processRedeployRequest()
+
+		Expect(clusterSummary.Annotations).ToNot(HaveKey(controllers.RedeployAnnotation))
+		for i := range clusterSummary.Status.FeatureSummaries {
+			Expect(clusterSummary.Status.FeatureSummaries[i].Status).To(Equal(configv1beta1.FeatureStatusProvisioned))
+		}
+	})
+})
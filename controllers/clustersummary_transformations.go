@@ -29,6 +29,7 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
@@ -158,6 +159,12 @@ func (r *ClusterSummaryReconciler) requeueClusterSummaryForReference(
 		}
 		cacheMgr := clustercache.GetManager()
 		cacheMgr.RemoveSecret(&key)
+	case *configv1beta1.HelmValuesPreset:
+		key = corev1.ObjectReference{
+			APIVersion: configv1beta1.GroupVersion.String(),
+			Kind:       configv1beta1.HelmValuesPresetKind,
+			Name:       o.GetName(),
+		}
 	default:
 		key = corev1.ObjectReference{
 			APIVersion: o.GetObjectKind().GroupVersionKind().GroupVersion().String(),
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/go-logr/logr"
@@ -59,6 +60,9 @@ type manager struct {
 	// Value: stop channel
 	watchers map[schema.GroupVersionKind]context.CancelFunc
 
+	// Informers backing the watchers. Used to report watchers health
+	informers map[schema.GroupVersionKind]cache.SharedIndexInformer
+
 	// List of ClusterSummary requesting a watcher for a given GVK
 	// Those are resources deployed in the management cluster. ClusterSummaries
 	// need to watch those resources and reconcile when those resources are modified
@@ -89,6 +93,7 @@ func initializeManager(l logr.Logger, config *rest.Config, c client.Client) {
 			managerInstance = &manager{log: l, Client: c, config: config}
 			managerInstance.watchMu = &sync.RWMutex{}
 			managerInstance.watchers = make(map[schema.GroupVersionKind]context.CancelFunc)
+			managerInstance.informers = make(map[schema.GroupVersionKind]cache.SharedIndexInformer)
 			managerInstance.requestorForMgmtResourcesKustomizeRef = make(map[schema.GroupVersionKind]*libsveltosset.Set)
 			managerInstance.requestorForMgmtResourcesPolicyRef = make(map[schema.GroupVersionKind]*libsveltosset.Set)
 			managerInstance.requestorForTemplateResourceRefs = make(map[schema.GroupVersionKind]*libsveltosset.Set)
@@ -235,6 +240,7 @@ func (m *manager) stopStaleWatchForMgmtResource(currentResources map[corev1.Obje
 				m.log.V(logs.LogInfo).Info(fmt.Sprintf("stop watching gvk: %s", gvk.String()))
 				cancel()
 				delete(m.watchers, gvk)
+				delete(m.informers, gvk)
 			}
 		}
 	}
@@ -303,6 +309,7 @@ func (m *manager) stopStaleWatchForTemplateResourceRef(clusterSummary *configv1b
 				m.log.V(logs.LogInfo).Info(fmt.Sprintf("stop watching gvk: %s", gvk.String()))
 				cancel()
 				delete(m.watchers, gvk)
+				delete(m.informers, gvk)
 			}
 		}
 	}
@@ -335,10 +342,30 @@ func (m *manager) startWatcher(ctx context.Context, gvk *schema.GroupVersionKind
 
 	watcherCtx, cancel := context.WithCancel(ctx)
 	m.watchers[*gvk] = cancel
+	m.informers[*gvk] = dcinformer.Informer()
 	go m.runInformer(watcherCtx.Done(), dcinformer.Informer(), logger)
 	return nil
 }
 
+// getWatcherStatuses reports, for each watcher, whether its initial listing is complete
+func (m *manager) getWatcherStatuses() []configv1beta1.WatcherStatus {
+	m.watchMu.RLock()
+	defer m.watchMu.RUnlock()
+
+	result := make([]configv1beta1.WatcherStatus, 0, len(m.informers))
+	for gvk, informer := range m.informers {
+		result = append(result, configv1beta1.WatcherStatus{
+			GroupVersionKind: gvk.String(),
+			Synced:           informer.HasSynced(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GroupVersionKind < result[j].GroupVersionKind
+	})
+	return result
+}
+
 func (m *manager) getDynamicInformer(gvk *schema.GroupVersionKind) (informers.GenericInformer, error) {
 	// Grab a dynamic interface that we can create informers from
 	d, err := dynamic.NewForConfig(m.config)
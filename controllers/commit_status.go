@@ -0,0 +1,348 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// CommitAnnotation can be set on a ClusterProfile/Profile by a GitOps tool. Value is the
+	// SHA of the commit which produced the current profile Spec. When set, and a commit status
+	// provider is configured, rollout results are reported back to the Git provider for this commit.
+	CommitAnnotation = "projectsveltos.io/commit"
+
+	// CommitRepositoryAnnotation identifies the repository the commit belongs to.
+	// For GitHub this is "owner/repo". For GitLab this is the project ID or the project path,
+	// for instance "group/subgroup/project".
+	CommitRepositoryAnnotation = "projectsveltos.io/commit-repository"
+
+	// commitStatusTokenKey is the key, in the commit status Secret, containing the API token
+	commitStatusTokenKey = "token"
+
+	// commitStatusRequeueAfter is how often a profile whose rollout is pending is reconciled,
+	// so the final rollout state is reported once reached
+	commitStatusRequeueAfter = 10 * time.Second
+)
+
+// CommitStatusProvider identifies the Git provider rollout results are reported to
+type CommitStatusProvider string
+
+const (
+	CommitStatusProviderNone   = CommitStatusProvider("")
+	CommitStatusProviderGitHub = CommitStatusProvider("github")
+	CommitStatusProviderGitLab = CommitStatusProvider("gitlab")
+)
+
+type commitState string
+
+const (
+	commitStatePending = commitState("pending")
+	commitStateSuccess = commitState("success")
+	commitStateFailure = commitState("failure")
+)
+
+var (
+	commitStatusProvider   CommitStatusProvider
+	commitStatusAPIURL     string
+	commitStatusSecretName string
+
+	// key: profile; value: last commit/state reported. Used to avoid
+	// posting the same status over and over.
+	reportedCommitStatus    = make(map[string]string)
+	reportedCommitStatusMux sync.Mutex
+)
+
+// SetCommitStatusProvider configures where rollout results for profiles carrying the
+// CommitAnnotation are reported. secretName is the name of a Secret, in the projectsveltos
+// namespace, containing the API token in the "token" key.
+func SetCommitStatusProvider(provider CommitStatusProvider, apiURL, secretName string) {
+	commitStatusProvider = provider
+	commitStatusAPIURL = strings.TrimSuffix(apiURL, "/")
+	commitStatusSecretName = secretName
+}
+
+func getCommitStatusAPIURL() string {
+	if commitStatusAPIURL != "" {
+		return commitStatusAPIURL
+	}
+
+	switch commitStatusProvider {
+	case CommitStatusProviderGitHub:
+		return "https://api.github.com"
+	case CommitStatusProviderGitLab:
+		return "https://gitlab.com/api/v4"
+	default:
+		return ""
+	}
+}
+
+// getCommitInfo returns commit SHA and repository set on the profile.
+// Empty values are returned if profile is not annotated.
+func getCommitInfo(profile client.Object) (sha, repository string) {
+	annotations := profile.GetAnnotations()
+	if annotations == nil {
+		return "", ""
+	}
+
+	return annotations[CommitAnnotation], annotations[CommitRepositoryAnnotation]
+}
+
+// getCommitState evaluates the rollout state of a profile across all matching clusters.
+// - failure if at least one ClusterSummary has a feature in failed state;
+// - pending if not all matching clusters have been provisioned yet;
+// - success otherwise.
+func getCommitState(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	updateErr error) (commitState, string, error) {
+
+	if updateErr != nil {
+		return commitStatePending, fmt.Sprintf("rollout in progress: %v", updateErr), nil
+	}
+
+	provisioned := 0
+	matching := profileScope.GetStatus().MatchingClusterRefs
+	for i := range matching {
+		cluster := &matching[i]
+		clusterSummary, err := getClusterSummary(ctx, c, profileScope.GetKind(), profileScope.Name(),
+			cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster))
+		if err != nil {
+			// ClusterSummary might not be created yet (for instance cluster not ready)
+			continue
+		}
+
+		for j := range clusterSummary.Status.FeatureSummaries {
+			fs := &clusterSummary.Status.FeatureSummaries[j]
+			if fs.Status == configv1beta1.FeatureStatusFailed ||
+				fs.Status == configv1beta1.FeatureStatusFailedNonRetriable {
+
+				return commitStateFailure, fmt.Sprintf("cluster %s/%s: %s failed",
+					cluster.Namespace, cluster.Name, fs.FeatureID), nil
+			}
+		}
+
+		if isCluterSummaryProvisioned(clusterSummary) {
+			provisioned++
+		}
+	}
+
+	description := fmt.Sprintf("%d/%d clusters provisioned", provisioned, len(matching))
+	if provisioned != len(matching) {
+		return commitStatePending, description, nil
+	}
+
+	return commitStateSuccess, description, nil
+}
+
+// reportCommitStatus posts the rollout state of a profile to the configured Git provider.
+// It is a no-op if no provider is configured or the profile carries no CommitAnnotation.
+// Returns true if rollout is still pending, so caller can requeue and report the final state
+// once reached.
+func reportCommitStatus(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	updateErr error, logger logr.Logger) bool {
+
+	if commitStatusProvider == CommitStatusProviderNone {
+		return false
+	}
+
+	sha, repository := getCommitInfo(profileScope.Profile)
+	if sha == "" || repository == "" {
+		return false
+	}
+
+	logger = logger.WithValues("commit", sha, "repository", repository)
+
+	state, description, err := getCommitState(ctx, c, profileScope, updateErr)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate rollout state: %v", err))
+		return true
+	}
+
+	profileKey := fmt.Sprintf("%s/%s", profileScope.GetKind(), types.NamespacedName{
+		Namespace: profileScope.Profile.GetNamespace(), Name: profileScope.Name()})
+	reportKey := fmt.Sprintf("%s/%s/%s", repository, sha, state)
+
+	reportedCommitStatusMux.Lock()
+	alreadyReported := reportedCommitStatus[profileKey] == reportKey
+	reportedCommitStatusMux.Unlock()
+
+	if !alreadyReported {
+		if err := postCommitStatus(ctx, c, repository, sha, getCommitStatusContext(profileScope),
+			state, description); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to report commit status: %v", err))
+			return true
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("reported commit status %s", state))
+		reportedCommitStatusMux.Lock()
+		reportedCommitStatus[profileKey] = reportKey
+		reportedCommitStatusMux.Unlock()
+	}
+
+	return state == commitStatePending
+}
+
+// forgetCommitStatus removes any memory of commit status reported for a profile
+func forgetCommitStatus(profileScope *scope.ProfileScope) {
+	profileKey := fmt.Sprintf("%s/%s", profileScope.GetKind(), types.NamespacedName{
+		Namespace: profileScope.Profile.GetNamespace(), Name: profileScope.Name()})
+
+	reportedCommitStatusMux.Lock()
+	defer reportedCommitStatusMux.Unlock()
+	delete(reportedCommitStatus, profileKey)
+}
+
+// getCommitStatusContext returns the name used to identify the status on the commit.
+// Each profile gets its own entry so multiple profiles from the same commit are reported separately.
+func getCommitStatusContext(profileScope *scope.ProfileScope) string {
+	if profileScope.Profile.GetNamespace() == "" {
+		return fmt.Sprintf("sveltos/%s/%s", profileScope.GetKind(), profileScope.Name())
+	}
+	return fmt.Sprintf("sveltos/%s/%s/%s", profileScope.GetKind(), profileScope.Profile.GetNamespace(),
+		profileScope.Name())
+}
+
+func getCommitStatusToken(ctx context.Context, c client.Client) (string, error) {
+	if commitStatusSecretName == "" {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: commitStatusSecretName},
+		secret); err != nil {
+		return "", err
+	}
+
+	token, ok := secret.Data[commitStatusTokenKey]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s does not contain key %s", projectsveltos,
+			commitStatusSecretName, commitStatusTokenKey)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
+
+func postCommitStatus(ctx context.Context, c client.Client, repository, sha, statusContext string,
+	state commitState, description string) error {
+
+	token, err := getCommitStatusToken(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var req *http.Request
+	switch commitStatusProvider {
+	case CommitStatusProviderGitHub:
+		req, err = getGitHubCommitStatusRequest(ctx, repository, sha, statusContext, state, description)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case CommitStatusProviderGitLab:
+		req, err = getGitLabCommitStatusRequest(ctx, repository, sha, statusContext, state, description)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	default:
+		return fmt.Errorf("unsupported commit status provider %q", commitStatusProvider)
+	}
+
+	const timeout = 10 * time.Second
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("commit status request failed with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func getGitHubCommitStatusRequest(ctx context.Context, repository, sha, statusContext string,
+	state commitState, description string) (*http.Request, error) {
+
+	body, err := json.Marshal(map[string]string{
+		"state":       string(state),
+		"context":     statusContext,
+		"description": description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", getCommitStatusAPIURL(), repository, sha)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func getGitLabCommitStatusRequest(ctx context.Context, repository, sha, statusContext string,
+	state commitState, description string) (*http.Request, error) {
+
+	// GitLab names the failure state "failed"
+	gitlabState := string(state)
+	if state == commitStateFailure {
+		gitlabState = "failed"
+	}
+
+	params := url.Values{}
+	params.Set("state", gitlabState)
+	params.Set("name", statusContext)
+	params.Set("description", description)
+
+	// GitLab expects the project path URL-encoded. An already encoded path is accepted as well
+	// and decoded first so it is not encoded twice.
+	project, err := url.PathUnescape(repository)
+	if err != nil {
+		project = repository
+	}
+
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s?%s", getCommitStatusAPIURL(),
+		url.PathEscape(project), sha, params.Encode())
+	return http.NewRequestWithContext(ctx, http.MethodPost, endpoint, http.NoBody)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Commit status", func() {
+	var clusterProfile *configv1beta1.ClusterProfile
+	var clusterSummary *configv1beta1.ClusterSummary
+	var cluster corev1.ObjectReference
+
+	BeforeEach(func() {
+		cluster = corev1.ObjectReference{
+			Namespace:  randomString(),
+			Name:       randomString(),
+			Kind:       clusterKind,
+			APIVersion: clusterv1.GroupVersion.String(),
+		}
+
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+				Annotations: map[string]string{
+					controllers.CommitAnnotation:           randomString(),
+					controllers.CommitRepositoryAnnotation: "projectsveltos/addon-controller",
+				},
+			},
+			Spec: configv1beta1.Spec{
+				PolicyRefs: []configv1beta1.PolicyRef{
+					{Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				},
+			},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{cluster},
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, clusterProfile)).To(Succeed())
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace:   cluster.Namespace,
+				ClusterName:        cluster.Name,
+				ClusterType:        libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: clusterProfile.Spec,
+			},
+		}
+		addLabelsToClusterSummary(clusterSummary, clusterProfile.Name, cluster.Name, libsveltosv1beta1.ClusterTypeCapi)
+	})
+
+	It("getCommitState reports pending, failure and success", func() {
+		initObjects := []client.Object{
+			clusterProfile,
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// No feature deployed yet
+		state, _, err := controllers.GetCommitState(context.TODO(), c, profileScope, nil)
+		Expect(err).To(BeNil())
+		Expect(string(state)).To(Equal("pending"))
+
+		clusterSummary.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+			{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusFailed},
+		}
+		Expect(c.Status().Update(context.TODO(), clusterSummary)).To(Succeed())
+
+		state, _, err = controllers.GetCommitState(context.TODO(), c, profileScope, nil)
+		Expect(err).To(BeNil())
+		Expect(string(state)).To(Equal("failure"))
+
+		clusterSummary.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+			{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned},
+		}
+		Expect(c.Status().Update(context.TODO(), clusterSummary)).To(Succeed())
+
+		state, _, err = controllers.GetCommitState(context.TODO(), c, profileScope, nil)
+		Expect(err).To(BeNil())
+		Expect(string(state)).To(Equal("success"))
+	})
+
+	It("getGitLabCommitStatusRequest encodes nested group project paths once", func() {
+		sha := randomString()
+		for _, repository := range []string{"group/subgroup/project", "group%2Fsubgroup%2Fproject"} {
+			req, err := controllers.GetGitLabCommitStatusRequest(context.TODO(), repository, sha,
+				randomString(), "success", randomString())
+			Expect(err).To(BeNil())
+			Expect(req.URL.EscapedPath()).To(HaveSuffix("/projects/group%2Fsubgroup%2Fproject/statuses/" + sha))
+		}
+	})
+
+	It("reconcileNormalCommon requeues, with no error, while rollout of commit is in progress", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		controllers.SetCommitStatusProvider(controllers.CommitStatusProviderGitHub, server.URL, "")
+		defer controllers.SetCommitStatusProvider(controllers.CommitStatusProviderNone, "", "")
+
+		clusterConfiguration := &configv1beta1.ClusterConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      controllers.GetClusterConfigurationName(cluster.Name, libsveltosv1beta1.ClusterTypeCapi),
+			},
+		}
+
+		// Cluster is not ready, so no ClusterSummary is created for it
+		capiCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      cluster.Name,
+			},
+		}
+
+		initObjects := []client.Object{
+			clusterProfile,
+			clusterConfiguration,
+			capiCluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		result, err := controllers.ReconcileNormalCommon(context.TODO(), c, profileScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(result.RequeueAfter).ToNot(BeZero())
+	})
+})
@@ -19,9 +19,15 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
@@ -29,6 +35,13 @@ import (
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
+const (
+	// helmReleaseConflictKind is the Kind reported for contested helm releases
+	helmReleaseConflictKind = "HelmRelease"
+
+	conflictEventReason = "Conflict"
+)
+
 // Any Helm chart can be managed by only one ClusterProfile/Profile instance
 // Any Kubernetes resources can be managed by only one ClusterProfile/Profile instance
 // A conflict arises when a ClusterProfile or Profile tries to manage a resource (chart
@@ -129,3 +142,222 @@ func requeueClusterSummary(ctx context.Context, featureID configv1beta1.FeatureI
 
 	return c.Status().Update(ctx, clusterSummaryScope.ClusterSummary)
 }
+
+type conflictsContextKey struct{}
+
+// conflictTracker collects the conflicts found while deploying a ClusterSummary feature
+type conflictTracker struct {
+	conflicts []configv1beta1.ConflictDetail
+}
+
+// withConflicts returns a context collecting the conflicts found while deploying a ClusterSummary feature
+func withConflicts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, conflictsContextKey{}, &conflictTracker{})
+}
+
+func getConflictTracker(ctx context.Context) *conflictTracker {
+	t, ok := ctx.Value(conflictsContextKey{}).(*conflictTracker)
+	if !ok {
+		return nil
+	}
+	return t
+}
+
+// trackConflict adds conflict to the conflicts found so far. Nothing is done if ctx does not
+// collect conflicts.
+func trackConflict(ctx context.Context, conflict *configv1beta1.ConflictDetail) {
+	t := getConflictTracker(ctx)
+	if t == nil || conflict == nil {
+		return
+	}
+	t.conflicts = append(t.conflicts, *conflict)
+}
+
+// getResourceConflict returns the conflict for a resource which cannot be deployed because a
+// different ClusterProfile/Profile, as reported by resourceInfo, is managing it
+func getResourceConflict(featureID configv1beta1.FeatureID, resource *configv1beta1.Resource,
+	resourceInfo *deployer.ResourceInfo, message string) *configv1beta1.ConflictDetail {
+
+	conflict := &configv1beta1.ConflictDetail{
+		FeatureID: featureID,
+		Kind:      resource.Kind,
+		Group:     resource.Group,
+		Namespace: resource.Namespace,
+		Name:      resource.Name,
+		Message:   message,
+	}
+
+	if resourceInfo == nil {
+		return conflict
+	}
+
+	for i := range resourceInfo.OwnerReferences {
+		owner := &resourceInfo.OwnerReferences[i]
+		if owner.Kind != configv1beta1.ClusterProfileKind && owner.Kind != configv1beta1.ProfileKind {
+			continue
+		}
+		ownerName := getProfileNameFromOwnerReferenceName(owner.Name)
+		conflict.OwnerKind = owner.Kind
+		conflict.OwnerNamespace = ownerName.Namespace
+		conflict.OwnerName = ownerName.Name
+		break
+	}
+
+	if resourceInfo.OwnerTier != "" {
+		conflict.OwnerTier = getTier(resourceInfo.OwnerTier)
+	}
+
+	return conflict
+}
+
+// getHelmChartConflict returns the conflict for an helm release which cannot be deployed because
+// the ClusterSummary managerName is managing it. The helm release is reported with its name and
+// namespace instantiated for the cluster.
+func getHelmChartConflict(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart, managerName, message string, logger logr.Logger,
+) *configv1beta1.ConflictDetail {
+
+	if isHelmReleaseTemplate(currentChart) {
+		releaseName, releaseNamespace, err := instantiateHelmChartRelease(ctx, clusterSummary, currentChart, logger)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate helm release: %v", err))
+		} else {
+			currentChart = currentChart.DeepCopy()
+			currentChart.ReleaseName, currentChart.ReleaseNamespace = releaseName, releaseNamespace
+		}
+	}
+
+	conflict := &configv1beta1.ConflictDetail{
+		FeatureID: configv1beta1.FeatureHelm,
+		Kind:      helmReleaseConflictKind,
+		Namespace: currentChart.ReleaseNamespace,
+		Name:      currentChart.ReleaseName,
+		Message:   message,
+	}
+
+	manager, err := configv1beta1.GetClusterSummary(ctx, c, clusterSummary.Spec.ClusterNamespace, managerName)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get ClusterSummary %s: %v", managerName, err))
+		return conflict
+	}
+
+	owner, err := configv1beta1.GetProfileOwnerReference(manager)
+	if err != nil || owner == nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get profile owning ClusterSummary %s", managerName))
+		return conflict
+	}
+
+	conflict.OwnerKind = owner.Kind
+	conflict.OwnerName = owner.Name
+	if owner.Kind == configv1beta1.ProfileKind {
+		conflict.OwnerNamespace = manager.Namespace
+	}
+	conflict.OwnerTier = manager.Spec.ClusterProfileSpec.Tier
+
+	return conflict
+}
+
+// recordConflicts reports, in the ClusterSummary status, the conflicts found while deploying featureID
+// and emits an event for each new conflict. Conflicts of featureID are removed once a deployment pass
+// succeeds without finding any. If the pass failed without finding any conflict, reported conflicts
+// are left unchanged.
+func recordConflicts(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, deployError error, logger logr.Logger) {
+
+	t := getConflictTracker(ctx)
+	if t == nil || (len(t.conflicts) == 0 && deployError != nil) {
+		return
+	}
+
+	conflicts := make([]configv1beta1.ConflictDetail, len(t.conflicts))
+	copy(conflicts, t.conflicts)
+	sortConflicts(conflicts)
+
+	var newConflicts []configv1beta1.ConflictDetail
+	var currentClusterSummary *configv1beta1.ClusterSummary
+	c := getManagementClusterClient()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary = &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		reported := make(map[configv1beta1.ConflictDetail]bool)
+		updated := make([]configv1beta1.ConflictDetail, 0, len(currentClusterSummary.Status.Conflicts)+len(conflicts))
+		for i := range currentClusterSummary.Status.Conflicts {
+			if currentClusterSummary.Status.Conflicts[i].FeatureID == featureID {
+				reported[currentClusterSummary.Status.Conflicts[i]] = true
+				continue
+			}
+			updated = append(updated, currentClusterSummary.Status.Conflicts[i])
+		}
+		updated = append(updated, conflicts...)
+		sortConflicts(updated)
+
+		newConflicts = nil
+		for i := range conflicts {
+			if !reported[conflicts[i]] {
+				newConflicts = append(newConflicts, conflicts[i])
+			}
+		}
+
+		if len(updated) == 0 {
+			updated = nil
+		}
+		if reflect.DeepEqual(updated, currentClusterSummary.Status.Conflicts) {
+			return nil
+		}
+
+		currentClusterSummary.Status.Conflicts = updated
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+	if err != nil {
+		// Status is informational only
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to report conflicts: %v", err))
+		return
+	}
+
+	recorder := getEventRecorder()
+	if recorder == nil {
+		return
+	}
+	for i := range newConflicts {
+		recorder.Event(currentClusterSummary, corev1.EventTypeWarning, conflictEventReason,
+			getConflictEventMessage(&newConflicts[i]))
+	}
+}
+
+func getConflictEventMessage(conflict *configv1beta1.ConflictDetail) string {
+	resource := fmt.Sprintf("%s %s", conflict.Kind, conflict.Name)
+	if conflict.Namespace != "" {
+		resource = fmt.Sprintf("%s %s/%s", conflict.Kind, conflict.Namespace, conflict.Name)
+	}
+
+	if conflict.OwnerKind == "" {
+		return fmt.Sprintf("cannot deploy %s: managed by a different profile", resource)
+	}
+
+	owner := conflict.OwnerName
+	if conflict.OwnerNamespace != "" {
+		owner = fmt.Sprintf("%s/%s", conflict.OwnerNamespace, conflict.OwnerName)
+	}
+	return fmt.Sprintf("cannot deploy %s: managed by %s %s (tier %d)", resource, conflict.OwnerKind, owner,
+		conflict.OwnerTier)
+}
+
+func sortConflicts(conflicts []configv1beta1.ConflictDetail) {
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].FeatureID != conflicts[j].FeatureID {
+			return conflicts[i].FeatureID < conflicts[j].FeatureID
+		}
+		if conflicts[i].Kind != conflicts[j].Kind {
+			return conflicts[i].Kind < conflicts[j].Kind
+		}
+		if conflicts[i].Namespace != conflicts[j].Namespace {
+			return conflicts[i].Namespace < conflicts[j].Namespace
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+}
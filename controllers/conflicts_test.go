@@ -0,0 +1,127 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+var _ = Describe("Conflicts", func() {
+	It("getResourceConflict reports the profile managing the contested resource", func() {
+		profileNamespace := randomString()
+		profileName := randomString()
+		resource := &configv1beta1.Resource{
+			Kind: "Deployment", Group: "apps", Version: "v1", Namespace: randomString(), Name: randomString(),
+		}
+		resourceInfo := &deployer.ResourceInfo{
+			OwnerReferences: []corev1.ObjectReference{
+				{
+					Kind: configv1beta1.ProfileKind, APIVersion: configv1beta1.GroupVersion.String(),
+					Name: fmt.Sprintf("%s/%s", profileNamespace, profileName),
+				},
+			},
+			OwnerTier: "50",
+		}
+
+		conflict := controllers.GetResourceConflict(configv1beta1.FeatureResources, resource, resourceInfo,
+			randomString())
+		Expect(conflict.FeatureID).To(Equal(configv1beta1.FeatureResources))
+		Expect(conflict.Kind).To(Equal(resource.Kind))
+		Expect(conflict.Group).To(Equal(resource.Group))
+		Expect(conflict.Namespace).To(Equal(resource.Namespace))
+		Expect(conflict.Name).To(Equal(resource.Name))
+		Expect(conflict.OwnerKind).To(Equal(configv1beta1.ProfileKind))
+		Expect(conflict.OwnerNamespace).To(Equal(profileNamespace))
+		Expect(conflict.OwnerName).To(Equal(profileName))
+		Expect(conflict.OwnerTier).To(Equal(int32(50)))
+	})
+
+	It("recordConflicts reports conflicts in ClusterSummary Status", func() {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, ns)).To(Succeed())
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: ns.Name,
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: ns.Name,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), clusterSummary)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, clusterSummary)).To(Succeed())
+
+		conflict := &configv1beta1.ConflictDetail{
+			FeatureID: configv1beta1.FeatureHelm, Kind: "HelmRelease", Namespace: randomString(), Name: randomString(),
+			OwnerKind: configv1beta1.ClusterProfileKind, OwnerName: randomString(), OwnerTier: 10,
+		}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		ctx := controllers.WithConflicts(context.TODO())
+		controllers.TrackConflict(ctx, conflict)
+		controllers.RecordConflicts(ctx, clusterSummary, configv1beta1.FeatureHelm, errors.New(randomString()), logger)
+
+		Eventually(func() bool {
+			currentClusterSummary := &configv1beta1.ClusterSummary{}
+			err := testEnv.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, currentClusterSummary)
+			return err == nil && len(currentClusterSummary.Status.Conflicts) == 1 &&
+				currentClusterSummary.Status.Conflicts[0] == *conflict
+		}, timeout, pollingInterval).Should(BeTrue())
+
+		// A failed pass which found no conflict leaves reported conflicts
+		ctx = controllers.WithConflicts(context.TODO())
+		controllers.RecordConflicts(ctx, clusterSummary, configv1beta1.FeatureHelm, errors.New(randomString()), logger)
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.Conflicts).To(HaveLen(1))
+
+		// A successful pass clears conflicts
+		controllers.RecordConflicts(ctx, clusterSummary, configv1beta1.FeatureHelm, nil, logger)
+		Eventually(func() bool {
+			currentClusterSummary := &configv1beta1.ClusterSummary{}
+			err := testEnv.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, currentClusterSummary)
+			return err == nil && len(currentClusterSummary.Status.Conflicts) == 0
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+})
@@ -0,0 +1,68 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// ControllerVersionAnnotation can be set on a cluster to migrate it between addon-controller deployments
+// running side by side (for instance the current and the next version during a gradual upgrade).
+// A cluster is managed only by the addon-controller deployment started with a --migration-version matching
+// the annotation value. Clusters without the annotation are managed by the deployment started without
+// --migration-version. Both sharding and migration version must match for a cluster to be managed.
+const ControllerVersionAnnotation = "projectsveltos.io/addon-controller-version"
+
+var (
+	// migrationVersion is the value of ControllerVersionAnnotation of the clusters managed by
+	// this addon-controller deployment
+	migrationVersion string
+)
+
+// SetMigrationVersion sets the value of ControllerVersionAnnotation of the clusters this addon-controller
+// deployment manages. When empty, only clusters without the annotation are managed.
+func SetMigrationVersion(version string) {
+	migrationVersion = version
+}
+
+// isMigrationVersionAMatch returns true if cluster is owned by this addon-controller deployment
+func isMigrationVersionAMatch(cluster client.Object) bool {
+	return cluster.GetAnnotations()[ControllerVersionAnnotation] == migrationVersion
+}
+
+// isClusterAMigrationVersionMatch returns true if the cluster referenced by clusterRef is owned
+// by this addon-controller deployment
+func isClusterAMigrationVersionMatch(ctx context.Context, c client.Client, clusterRef *corev1.ObjectReference,
+) (bool, error) {
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterRef.Namespace, clusterRef.Name,
+		clusterproxy.GetClusterType(clusterRef))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return isMigrationVersionAMatch(cluster), nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Controller migration", func() {
+	var cluster *libsveltosv1beta1.SveltosCluster
+
+	BeforeEach(func() {
+		cluster = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetMigrationVersion("")
+	})
+
+	It("isMigrationVersionAMatch returns true only for clusters owned by this addon-controller version", func() {
+		newVersion := randomString()
+
+		// Clusters without annotation are owned by the deployment running without migration version
+		controllers.SetMigrationVersion("")
+		Expect(controllers.IsMigrationVersionAMatch(cluster)).To(BeTrue())
+		controllers.SetMigrationVersion(newVersion)
+		Expect(controllers.IsMigrationVersionAMatch(cluster)).To(BeFalse())
+
+		// Migrate cluster
+		cluster.Annotations = map[string]string{controllers.ControllerVersionAnnotation: newVersion}
+		Expect(controllers.IsMigrationVersionAMatch(cluster)).To(BeTrue())
+		controllers.SetMigrationVersion("")
+		Expect(controllers.IsMigrationVersionAMatch(cluster)).To(BeFalse())
+		controllers.SetMigrationVersion(randomString())
+		Expect(controllers.IsMigrationVersionAMatch(cluster)).To(BeFalse())
+	})
+})
@@ -0,0 +1,231 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// maxClusterBacklogs is the maximum number of clusters reported in ControllerStatus
+	maxClusterBacklogs = 100
+)
+
+type pendingRequest struct {
+	cluster corev1.ObjectReference
+	since   time.Time
+}
+
+// TrackingDeployer is a deployer.DeployerInterface keeping track of the requests submitted
+// to the embedded deployer and not processed yet. A request is pending from the moment it
+// is submitted till its handler is invoked.
+type TrackingDeployer struct {
+	deployer.DeployerInterface
+
+	mu      *sync.Mutex
+	pending map[string]*pendingRequest
+}
+
+// NewTrackingDeployer returns a TrackingDeployer tracking requests submitted to d
+func NewTrackingDeployer(d deployer.DeployerInterface) *TrackingDeployer {
+	return &TrackingDeployer{
+		DeployerInterface: d,
+		mu:                &sync.Mutex{},
+		pending:           make(map[string]*pendingRequest),
+	}
+}
+
+func (t *TrackingDeployer) Deploy(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool, f deployer.RequestHandler, m deployer.MetricHandler,
+	o deployer.Options) error {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	handler := func(ctx context.Context, c client.Client, clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType, o deployer.Options, logger logr.Logger) error {
+
+		t.removePending(key)
+		return f(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	}
+
+	// Recorded before submitting the request, as handler might be invoked before Deploy returns
+	t.addPending(key, getClusterReference(clusterNamespace, clusterName, clusterType))
+
+	err := t.DeployerInterface.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, clusterType,
+		cleanup, handler, m, o)
+	if err != nil {
+		t.removePending(key)
+		return err
+	}
+
+	return nil
+}
+
+func (t *TrackingDeployer) CleanupEntries(clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) {
+
+	t.removePending(deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup))
+	t.DeployerInterface.CleanupEntries(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+}
+
+// addPending records a request as pending. If a request for the same key is already pending,
+// the deployer will process only one of them, so the oldest submission time is kept.
+func (t *TrackingDeployer) addPending(key string, cluster *corev1.ObjectReference) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.pending[key]; ok {
+		return
+	}
+	t.pending[key] = &pendingRequest{cluster: *cluster, since: time.Now()}
+}
+
+func (t *TrackingDeployer) removePending(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.pending, key)
+}
+
+// getQueueStatus returns the number of pending requests, the age of the oldest one and the
+// clusters with pending requests (starting from the one with the most pending requests)
+func (t *TrackingDeployer) getQueueStatus(now time.Time) (depth int, oldest time.Duration,
+	backlogs []configv1beta1.ClusterBacklog) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	perCluster := make(map[corev1.ObjectReference]int32)
+	for _, req := range t.pending {
+		perCluster[req.cluster]++
+		if age := now.Sub(req.since); age > oldest {
+			oldest = age
+		}
+	}
+
+	backlogs = make([]configv1beta1.ClusterBacklog, 0, len(perCluster))
+	for cluster, pending := range perCluster {
+		backlogs = append(backlogs, configv1beta1.ClusterBacklog{Cluster: cluster, PendingRequests: pending})
+	}
+
+	sort.Slice(backlogs, func(i, j int) bool {
+		if backlogs[i].PendingRequests != backlogs[j].PendingRequests {
+			return backlogs[i].PendingRequests > backlogs[j].PendingRequests
+		}
+		if backlogs[i].Cluster.Namespace != backlogs[j].Cluster.Namespace {
+			return backlogs[i].Cluster.Namespace < backlogs[j].Cluster.Namespace
+		}
+		return backlogs[i].Cluster.Name < backlogs[j].Cluster.Name
+	})
+
+	if len(backlogs) > maxClusterBacklogs {
+		backlogs = backlogs[:maxClusterBacklogs]
+	}
+
+	return len(t.pending), oldest, backlogs
+}
+
+func getClusterReference(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
+) *corev1.ObjectReference {
+
+	cluster := &corev1.ObjectReference{Namespace: clusterNamespace, Name: clusterName}
+	if clusterType == libsveltosv1beta1.ClusterTypeSveltos {
+		cluster.Kind = libsveltosv1beta1.SveltosClusterKind
+		cluster.APIVersion = libsveltosv1beta1.GroupVersion.String()
+	} else {
+		cluster.Kind = clusterKind
+		cluster.APIVersion = clusterv1.GroupVersion.String()
+	}
+	return cluster
+}
+
+// GetControllerStatusName returns the name of the ControllerStatus for a given shard
+func GetControllerStatusName(shardKey string) string {
+	if shardKey == "" {
+		return configv1beta1.DefaultControllerStatusName
+	}
+	return shardKey
+}
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=controllerstatuses,verbs=get;create
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=controllerstatuses/status,verbs=get;update
+
+// UpdateControllerStatus periodically updates the ControllerStatus name with the deployer
+// queue tracked by t and the health of the watchers.
+func UpdateControllerStatus(ctx context.Context, c client.Client, name string, interval time.Duration,
+	t *TrackingDeployer, logger logr.Logger) {
+
+	logger = logger.WithValues("controllerstatus", name)
+	for {
+		select {
+		case <-time.After(interval):
+			if err := updateControllerStatus(ctx, c, name, t); err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update ControllerStatus: %v", err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func updateControllerStatus(ctx context.Context, c client.Client, name string, t *TrackingDeployer) error {
+	controllerStatus := &configv1beta1.ControllerStatus{}
+	err := c.Get(ctx, types.NamespacedName{Name: name}, controllerStatus)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		controllerStatus.Name = name
+		if err := c.Create(ctx, controllerStatus); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	depth, oldest, backlogs := t.getQueueStatus(now)
+
+	controllerStatus.Status = configv1beta1.ControllerStatusStatus{
+		QueueDepth:      int32(depth),
+		ClusterBacklogs: backlogs,
+		LastUpdateTime:  &metav1.Time{Time: now},
+	}
+	if depth > 0 {
+		controllerStatus.Status.OldestPendingRequestAge = &metav1.Duration{Duration: oldest.Round(time.Second)}
+	}
+	if m := getManager(); m != nil {
+		controllerStatus.Status.Watchers = m.getWatcherStatuses()
+	}
+
+	return c.Status().Update(ctx, controllerStatus)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	fakedeployer "github.com/projectsveltos/libsveltos/lib/deployer/fake"
+)
+
+var _ = Describe("ControllerStatus", func() {
+	const featureID = string(configv1beta1.FeatureResources)
+
+	handler := func(_ context.Context, _ client.Client, _, _, _, _ string, _ libsveltosv1beta1.ClusterType,
+		_ deployer.Options, _ logr.Logger) error {
+
+		return nil
+	}
+
+	metricHandler := func(_ time.Duration, _, _, _ string, _ libsveltosv1beta1.ClusterType, _ logr.Logger) {}
+
+	It("TrackingDeployer reports requests not processed yet per cluster", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		// fake deployer never invokes the handler, so requests stay pending
+		tracker := controllers.NewTrackingDeployer(fakedeployer.GetClient(context.TODO(), logger, c))
+
+		clusterNamespace := randomString()
+		busyCluster := randomString()
+		otherCluster := randomString()
+
+		for _, applicant := range []string{randomString(), randomString()} {
+			Expect(tracker.Deploy(context.TODO(), clusterNamespace, busyCluster, applicant, featureID,
+				libsveltosv1beta1.ClusterTypeCapi, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+		}
+		applicant := randomString()
+		Expect(tracker.Deploy(context.TODO(), clusterNamespace, otherCluster, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeSveltos, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+		// Same request submitted again is counted once
+		Expect(tracker.Deploy(context.TODO(), clusterNamespace, otherCluster, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeSveltos, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+
+		depth, oldest, backlogs := controllers.GetQueueStatus(tracker, time.Now().Add(time.Minute))
+		Expect(depth).To(Equal(3))
+		Expect(oldest >= time.Minute).To(BeTrue())
+		Expect(backlogs).To(HaveLen(2))
+		Expect(backlogs[0].Cluster.Name).To(Equal(busyCluster))
+		Expect(backlogs[0].PendingRequests).To(Equal(int32(2)))
+		Expect(backlogs[1].Cluster.Name).To(Equal(otherCluster))
+		Expect(backlogs[1].Cluster.Kind).To(Equal(libsveltosv1beta1.SveltosClusterKind))
+
+		tracker.CleanupEntries(clusterNamespace, otherCluster, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeSveltos, false)
+		depth, _, backlogs = controllers.GetQueueStatus(tracker, time.Now())
+		Expect(depth).To(Equal(2))
+		Expect(backlogs).To(HaveLen(1))
+	})
+
+	It("TrackingDeployer stops reporting a request once its handler is invoked", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		// deployer is a singleton (its workers are not stopped when test ends), so
+		// feature might already be registered
+		d := deployer.GetClient(context.Background(), logger, c, 1)
+		_ = d.RegisterFeatureID(featureID)
+		tracker := controllers.NewTrackingDeployer(d)
+
+		Expect(tracker.Deploy(context.TODO(), randomString(), randomString(), randomString(), featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+
+		Eventually(func() int {
+			depth, _, _ := controllers.GetQueueStatus(tracker, time.Now())
+			return depth
+		}, timeout, pollingInterval).Should(BeZero())
+	})
+
+	It("updateControllerStatus creates and updates ControllerStatus", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithStatusSubresource(&configv1beta1.ControllerStatus{}).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		tracker := controllers.NewTrackingDeployer(fakedeployer.GetClient(context.TODO(), logger, c))
+
+		Expect(tracker.Deploy(context.TODO(), randomString(), randomString(), randomString(), featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+
+		name := controllers.GetControllerStatusName("")
+		Expect(controllers.UpdateControllerStatusOnce(context.TODO(), c, name, tracker)).To(Succeed())
+
+		controllerStatus := &configv1beta1.ControllerStatus{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name}, controllerStatus)).To(Succeed())
+		Expect(controllerStatus.Status.QueueDepth).To(Equal(int32(1)))
+		Expect(controllerStatus.Status.OldestPendingRequestAge).ToNot(BeNil())
+		Expect(controllerStatus.Status.ClusterBacklogs).To(HaveLen(1))
+		Expect(controllerStatus.Status.LastUpdateTime).ToNot(BeNil())
+	})
+})
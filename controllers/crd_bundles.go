@@ -0,0 +1,106 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// crdDiscoveryInterval is how often discovery is refreshed while waiting for a CRD,
+	// deployed as part of the same bundle, to be served
+	crdDiscoveryInterval = time.Second
+	// crdDiscoveryTimeout is how long to wait for a CRD, deployed as part of the same bundle,
+	// to be served before giving up
+	crdDiscoveryTimeout = 30 * time.Second
+)
+
+func isCustomResourceDefinition(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().GroupKind() == apiextensionsv1.Kind("CustomResourceDefinition")
+}
+
+// sortCustomResourceDefinitionsFirst returns resources with all CustomResourceDefinitions
+// moved first. Relative order of all other resources is preserved.
+func sortCustomResourceDefinitionsFirst(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	result := make([]*unstructured.Unstructured, len(resources))
+	copy(result, resources)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return isCustomResourceDefinition(result[i]) && !isCustomResourceDefinition(result[j])
+	})
+
+	return result
+}
+
+// getCustomResourceDefinitionGroupKinds returns the GroupKinds defined by the CustomResourceDefinitions
+// contained in resources
+func getCustomResourceDefinitionGroupKinds(resources []*unstructured.Unstructured) map[schema.GroupKind]bool {
+	result := make(map[schema.GroupKind]bool)
+	for i := range resources {
+		if !isCustomResourceDefinition(resources[i]) {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(resources[i].Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(resources[i].Object, "spec", "names", "kind")
+		if kind == "" {
+			continue
+		}
+		result[schema.GroupKind{Group: group, Kind: kind}] = true
+	}
+
+	return result
+}
+
+// waitForDiscovery waits for gvk to be served by the API server. It is used when a bundle contains both
+// CustomResourceDefinitions and instances of those. CustomResourceDefinitions are applied first,
+// then, before an instance is deployed, discovery is refreshed till the API server serves the new GroupVersionKind.
+func waitForDiscovery(ctx context.Context, destConfig *rest.Config, gvk schema.GroupVersionKind,
+	logger logr.Logger) error {
+
+	err := wait.PollUntilContextTimeout(ctx, crdDiscoveryInterval, crdDiscoveryTimeout, true,
+		func(context.Context) (bool, error) {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(gvk)
+			if _, err := isNamespaced(u, destConfig); err != nil {
+				if meta.IsNoMatchError(err) {
+					logger.V(logs.LogDebug).Info(fmt.Sprintf("%s not discoverable yet", gvk.String()))
+					return false, nil
+				}
+				return false, err
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("%s defined in the same bundle is not served yet: %w", gvk.String(), err)
+	}
+
+	return nil
+}
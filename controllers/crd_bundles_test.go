@@ -0,0 +1,89 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	gatewayClassCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gatewayclasses.gateway.networking.k8s.io
+spec:
+  group: gateway.networking.k8s.io
+  names:
+    kind: GatewayClass
+    plural: gatewayclasses
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true`
+
+	gatewayClass = `apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: %s
+spec:
+  controllerName: example.com/gateway-controller`
+
+	crdBundleNamespace = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s`
+)
+
+var _ = Describe("CRD bundles", func() {
+	getUnstructured := func(content string) *unstructured.Unstructured {
+		u, err := utils.GetUnstructured([]byte(content))
+		Expect(err).To(BeNil())
+		return u
+	}
+
+	It("sortCustomResourceDefinitionsFirst moves CustomResourceDefinitions first", func() {
+		gc := getUnstructured(fmt.Sprintf(gatewayClass, randomString()))
+		ns := getUnstructured(fmt.Sprintf(crdBundleNamespace, randomString()))
+		crd := getUnstructured(gatewayClassCRD)
+
+		resources := []*unstructured.Unstructured{gc, ns, crd}
+		sorted := controllers.SortCustomResourceDefinitionsFirst(resources)
+		Expect(sorted).To(Equal([]*unstructured.Unstructured{crd, gc, ns}))
+		// Original slice is not modified
+		Expect(resources[0]).To(Equal(gc))
+	})
+
+	It("getCustomResourceDefinitionGroupKinds returns GroupKinds defined in the bundle", func() {
+		resources := []*unstructured.Unstructured{
+			getUnstructured(fmt.Sprintf(gatewayClass, randomString())), getUnstructured(fmt.Sprintf(crdBundleNamespace, randomString())), getUnstructured(gatewayClassCRD),
+		}
+
+		groupKinds := controllers.GetCustomResourceDefinitionGroupKinds(resources)
+		Expect(groupKinds).To(HaveLen(1))
+		Expect(groupKinds).To(HaveKey(schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "GatewayClass"}))
+	})
+})
@@ -0,0 +1,141 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+// ConfirmDeletionAnnotation, set to "true" on a ClusterProfile/Profile with DeletionProtection,
+// allows deleting it or removing its last helm chart
+const ConfirmDeletionAnnotation = "projectsveltos.io/confirm-deletion"
+
+func isDeletionConfirmed(annotations map[string]string) bool {
+	return annotations[ConfirmDeletionAnnotation] == "true"
+}
+
+// validateProfileDeletion rejects the deletion of a ClusterProfile/Profile with DeletionProtection
+// unless deletion is confirmed
+func validateProfileDeletion(kind, name string, annotations map[string]string, spec *configv1beta1.Spec) error {
+	if !spec.DeletionProtection || isDeletionConfirmed(annotations) {
+		return nil
+	}
+
+	return fmt.Errorf("%s %s has deletionProtection set: annotate it with %s=true to confirm deletion",
+		kind, name, ConfirmDeletionAnnotation)
+}
+
+// validateHelmChartsRemoval rejects an update removing all helm charts of a ClusterProfile/Profile
+// with DeletionProtection unless deletion is confirmed. DeletionProtection of the existing
+// object is considered, so protection cannot be lifted in the same update removing the charts.
+func validateHelmChartsRemoval(kind, name string, annotations map[string]string,
+	oldSpec, newSpec *configv1beta1.Spec) error {
+
+	if !oldSpec.DeletionProtection || len(oldSpec.HelmCharts) == 0 || len(newSpec.HelmCharts) != 0 {
+		return nil
+	}
+	if isDeletionConfirmed(annotations) {
+		return nil
+	}
+
+	return fmt.Errorf("%s %s has deletionProtection set: annotate it with %s=true to confirm removal of all helm charts",
+		kind, name, ConfirmDeletionAnnotation)
+}
+
+// setDeletionBlockedCondition updates DeletionBlockedCondition with the outcome of the deletion
+// protection check. The condition is removed when DeletionProtection is not set.
+func setDeletionBlockedCondition(profileScope *scope.ProfileScope, blocked error) {
+	if !profileScope.GetSpec().DeletionProtection && blocked == nil {
+		meta.RemoveStatusCondition(&profileScope.GetStatus().Conditions, configv1beta1.DeletionBlockedCondition)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.DeletionBlockedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.DeletionAllowedReason,
+		Message:            "nothing is blocked by deletionProtection",
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+	if blocked != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.DeletionNotConfirmedReason
+		condition.Message = blocked.Error()
+	}
+
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+}
+
+// guardProfileDeletion is invoked by the reconcilers, which hold the ClusterProfile/Profile
+// finalizer, before cleaning up. It returns an error, and sets DeletionBlockedCondition, while
+// the deletion of a ClusterProfile/Profile with DeletionProtection is not confirmed.
+func guardProfileDeletion(profileScope *scope.ProfileScope) error {
+	err := validateProfileDeletion(profileScope.GetKind(), profileScope.Name(),
+		profileScope.Profile.GetAnnotations(), profileScope.GetSpec())
+	if err != nil {
+		setDeletionBlockedCondition(profileScope, err)
+	}
+	return err
+}
+
+// guardHelmChartsRemoval is invoked by the reconcilers before updating ClusterSummaries. It
+// returns an error, and sets DeletionBlockedCondition, when all helm charts have been removed
+// from a ClusterProfile/Profile and removal is not confirmed. The Spec last propagated to the
+// ClusterSummaries is the one considered for DeletionProtection, so protection cannot be
+// lifted in the same update removing the charts.
+func guardHelmChartsRemoval(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	spec := profileScope.GetSpec()
+	if len(spec.HelmCharts) != 0 || isDeletionConfirmed(profileScope.Profile.GetAnnotations()) {
+		setDeletionBlockedCondition(profileScope, nil)
+		return nil
+	}
+
+	listOptions := []client.ListOption{}
+	if profileScope.GetKind() == configv1beta1.ClusterProfileKind {
+		listOptions = append(listOptions, client.MatchingLabels{ClusterProfileLabelName: profileScope.Name()})
+	} else {
+		listOptions = append(listOptions,
+			client.MatchingLabels{ProfileLabelName: profileScope.Name()},
+			client.InNamespace(profileScope.Namespace()))
+	}
+
+	clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaryList, listOptions...); err != nil {
+		return err
+	}
+
+	for i := range clusterSummaryList.Items {
+		cs := &clusterSummaryList.Items[i]
+		err := validateHelmChartsRemoval(profileScope.GetKind(), profileScope.Name(),
+			profileScope.Profile.GetAnnotations(), &cs.Spec.ClusterProfileSpec, spec)
+		if err != nil {
+			setDeletionBlockedCondition(profileScope, err)
+			return err
+		}
+	}
+
+	setDeletionBlockedCondition(profileScope, nil)
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var _ = Describe("Deletion protection", func() {
+	var helmChart configv1beta1.HelmChart
+
+	BeforeEach(func() {
+		helmChart = configv1beta1.HelmChart{
+			RepositoryURL: randomString(), RepositoryName: randomString(), ChartName: randomString(),
+			ChartVersion: randomString(), ReleaseName: randomString(), ReleaseNamespace: randomString(),
+		}
+	})
+
+	It("ClusterProfileValidator rejects deletion of protected ClusterProfiles unless confirmed", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				HelmCharts: []configv1beta1.HelmChart{helmChart},
+			},
+		}
+
+		validator := &controllers.ClusterProfileValidator{}
+		_, err := validator.ValidateDelete(context.TODO(), clusterProfile)
+		Expect(err).To(BeNil())
+
+		clusterProfile.Spec.DeletionProtection = true
+		_, err = validator.ValidateDelete(context.TODO(), clusterProfile)
+		Expect(err).ToNot(BeNil())
+
+		clusterProfile.Annotations = map[string]string{controllers.ConfirmDeletionAnnotation: "true"}
+		_, err = validator.ValidateDelete(context.TODO(), clusterProfile)
+		Expect(err).To(BeNil())
+	})
+
+	It("ClusterProfileValidator rejects removal of last helm chart of protected ClusterProfiles unless confirmed", func() {
+		oldClusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				DeletionProtection: true,
+				HelmCharts:         []configv1beta1.HelmChart{helmChart},
+			},
+		}
+
+		// Protection cannot be lifted while removing all helm charts
+		newClusterProfile := oldClusterProfile.DeepCopy()
+		newClusterProfile.Spec.HelmCharts = nil
+		newClusterProfile.Spec.DeletionProtection = false
+
+		validator := &controllers.ClusterProfileValidator{}
+		_, err := validator.ValidateUpdate(context.TODO(), oldClusterProfile, newClusterProfile)
+		Expect(err).ToNot(BeNil())
+
+		newClusterProfile.Annotations = map[string]string{controllers.ConfirmDeletionAnnotation: "true"}
+		_, err = validator.ValidateUpdate(context.TODO(), oldClusterProfile, newClusterProfile)
+		Expect(err).To(BeNil())
+
+		// Other updates are allowed
+		_, err = validator.ValidateUpdate(context.TODO(), oldClusterProfile, oldClusterProfile)
+		Expect(err).To(BeNil())
+	})
+
+	It("ProfileValidator rejects deletion of protected Profiles unless confirmed", func() {
+		oldProfile := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.Spec{
+				DeletionProtection: true,
+				HelmCharts:         []configv1beta1.HelmChart{helmChart},
+			},
+		}
+
+		validator := &controllers.ProfileValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+		_, err := validator.ValidateDelete(context.TODO(), oldProfile)
+		Expect(err).ToNot(BeNil())
+
+		newProfile := oldProfile.DeepCopy()
+		newProfile.Spec.HelmCharts = nil
+		_, err = validator.ValidateUpdate(context.TODO(), oldProfile, newProfile)
+		Expect(err).ToNot(BeNil())
+
+		newProfile.Annotations = map[string]string{controllers.ConfirmDeletionAnnotation: "true"}
+		_, err = validator.ValidateUpdate(context.TODO(), oldProfile, newProfile)
+		Expect(err).To(BeNil())
+		_, err = validator.ValidateDelete(context.TODO(), newProfile)
+		Expect(err).To(BeNil())
+	})
+
+	It("guardHelmChartsRemoval blocks removal of all helm charts deployed with DeletionProtection", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		Expect(addTypeInformationToObject(scheme, clusterProfile)).To(Succeed())
+
+		// ClusterSummary carries the Spec last propagated by the ClusterProfile
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+				Labels:    map[string]string{controllers.ClusterProfileLabelName: clusterProfile.Name},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					DeletionProtection: true,
+					HelmCharts:         []configv1beta1.HelmChart{helmChart},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile, clusterSummary).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Lifting protection while removing all charts is blocked as well
+		Expect(controllers.GuardHelmChartsRemoval(context.TODO(), c, profileScope)).ToNot(Succeed())
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.DeletionBlockedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.DeletionNotConfirmedReason))
+
+		clusterProfile.Annotations = map[string]string{controllers.ConfirmDeletionAnnotation: "true"}
+		Expect(controllers.GuardHelmChartsRemoval(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.DeletionBlockedCondition)).To(BeNil())
+	})
+})
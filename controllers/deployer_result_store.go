@@ -0,0 +1,272 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// deployerResultStorePasswordEnv, when set, overrides the password of the result store URL
+	deployerResultStorePasswordEnv = "DEPLOYER_RESULT_STORE_PASSWORD"
+
+	// results are kept in the store for this long. Once expired, feature is simply redeployed.
+	deployerResultTTL = 7 * 24 * time.Hour
+
+	// timeout for each store operation
+	deployerResultStoreTimeout = 5 * time.Second
+
+	redisKeyPrefix = "sveltos:deployer:"
+)
+
+const (
+	errorKindNonRetriable = "nonRetriable"
+	errorKindConflict     = "conflict"
+	errorKindWriteBudget  = "writeBudget"
+)
+
+// DeployerResult is the outcome of a deployer request as persisted in a DeployerResultStore
+type DeployerResult struct {
+	// Status is the deployer.ResultStatus string representation (deployed, failed, removed)
+	Status string `json:"status"`
+
+	// Message is the error message when Status is failed
+	Message string `json:"message,omitempty"`
+
+	// ErrorKind preserves the type of the error (nonRetriable, conflict, writeBudget) when Status is failed
+	ErrorKind string `json:"errorKind,omitempty"`
+
+	// Applied and Pending are the number of resources applied and still to be applied when
+	// ErrorKind is writeBudget
+	Applied int32 `json:"applied,omitempty"`
+	Pending int32 `json:"pending,omitempty"`
+}
+
+// DeployerResultStore persists deployer results outside of the addon-controller process, so
+// results survive restarts and can be queried by other shards.
+// Get returns nil if no result is stored for key.
+type DeployerResultStore interface {
+	Get(ctx context.Context, key string) (*DeployerResult, error)
+	Set(ctx context.Context, key string, result *DeployerResult) error
+	Delete(ctx context.Context, key string) error
+}
+
+// persistentDeployer is a deployer.DeployerInterface storing the results of the requests processed
+// by the embedded in-memory deployer in a DeployerResultStore. When the in-memory deployer has no
+// result for a request (for instance after a restart), the stored one is returned.
+type persistentDeployer struct {
+	deployer.DeployerInterface
+	store  DeployerResultStore
+	logger logr.Logger
+}
+
+// NewPersistentDeployer returns a deployer.DeployerInterface backing up the results of d in store
+func NewPersistentDeployer(d deployer.DeployerInterface, store DeployerResultStore, logger logr.Logger,
+) deployer.DeployerInterface {
+
+	return &persistentDeployer{DeployerInterface: d, store: store, logger: logger}
+}
+
+func (p *persistentDeployer) Deploy(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool, f deployer.RequestHandler, m deployer.MetricHandler,
+	o deployer.Options) error {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	// Since there is a new request, result of previous request, if any, is not valid anymore
+	p.deleteResult(key)
+
+	handler := func(ctx context.Context, c client.Client, clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType, o deployer.Options, logger logr.Logger) error {
+
+		err := f(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+		p.setResult(key, getDeployerResult(err, cleanup))
+		return err
+	}
+
+	return p.DeployerInterface.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, clusterType,
+		cleanup, handler, m, o)
+}
+
+func (p *persistentDeployer) GetResult(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) deployer.Result {
+
+	result := p.DeployerInterface.GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+		clusterType, cleanup)
+	if result.ResultStatus != deployer.Unavailable {
+		return result
+	}
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+	ctx, cancel := context.WithTimeout(ctx, deployerResultStoreTimeout)
+	defer cancel()
+
+	stored, err := p.store.Get(ctx, key)
+	if err != nil {
+		p.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get result from store: %v", err))
+		return result
+	}
+	if stored == nil {
+		return result
+	}
+
+	return toDeployerResult(stored)
+}
+
+func (p *persistentDeployer) CleanupEntries(clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) {
+
+	p.DeployerInterface.CleanupEntries(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+	p.deleteResult(deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup))
+}
+
+func (p *persistentDeployer) setResult(key string, result *DeployerResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), deployerResultStoreTimeout)
+	defer cancel()
+
+	if err := p.store.Set(ctx, key, result); err != nil {
+		p.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to store result: %v", err))
+	}
+}
+
+func (p *persistentDeployer) deleteResult(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), deployerResultStoreTimeout)
+	defer cancel()
+
+	if err := p.store.Delete(ctx, key); err != nil {
+		p.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to remove result from store: %v", err))
+	}
+}
+
+func getDeployerResult(err error, cleanup bool) *DeployerResult {
+	if err == nil {
+		if cleanup {
+			return &DeployerResult{Status: deployer.Removed.String()}
+		}
+		return &DeployerResult{Status: deployer.Deployed.String()}
+	}
+
+	result := &DeployerResult{Status: deployer.Failed.String(), Message: err.Error()}
+
+	var nonRetriableError *NonRetriableError
+	var conflictError *deployer.ConflictError
+	var writeBudgetError *WriteBudgetExhaustedError
+	if errors.As(err, &nonRetriableError) {
+		result.ErrorKind = errorKindNonRetriable
+	} else if errors.As(err, &conflictError) {
+		result.ErrorKind = errorKindConflict
+	} else if errors.As(err, &writeBudgetError) {
+		result.ErrorKind = errorKindWriteBudget
+		result.Applied = writeBudgetError.Applied
+		result.Pending = writeBudgetError.Pending
+	}
+
+	return result
+}
+
+func toDeployerResult(stored *DeployerResult) deployer.Result {
+	switch stored.Status {
+	case deployer.Deployed.String():
+		return deployer.Result{ResultStatus: deployer.Deployed}
+	case deployer.Removed.String():
+		return deployer.Result{ResultStatus: deployer.Removed}
+	case deployer.Failed.String():
+		var err error
+		switch stored.ErrorKind {
+		case errorKindNonRetriable:
+			err = &NonRetriableError{Message: stored.Message}
+		case errorKindConflict:
+			err = deployer.NewConflictError(stored.Message)
+		case errorKindWriteBudget:
+			err = &WriteBudgetExhaustedError{Applied: stored.Applied, Pending: stored.Pending}
+		default:
+			err = errors.New(stored.Message)
+		}
+		return deployer.Result{ResultStatus: deployer.Failed, Err: err}
+	default:
+		return deployer.Result{ResultStatus: deployer.Unavailable}
+	}
+}
+
+// redisResultStore is a DeployerResultStore backed by Redis
+type redisResultStore struct {
+	client *redis.Client
+}
+
+// NewRedisResultStore returns a DeployerResultStore for the Redis server at url
+// (redis://<user>:<password>@<host>:<port>/<db>). Password can also be provided
+// with the DEPLOYER_RESULT_STORE_PASSWORD environment variable.
+func NewRedisResultStore(ctx context.Context, url string) (DeployerResultStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if password := os.Getenv(deployerResultStorePasswordEnv); password != "" {
+		options.Password = password
+	}
+
+	c := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(ctx, deployerResultStoreTimeout)
+	defer cancel()
+	if err := c.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", options.Addr, err)
+	}
+
+	return &redisResultStore{client: c}, nil
+}
+
+func (r *redisResultStore) Get(ctx context.Context, key string) (*DeployerResult, error) {
+	data, err := r.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := &DeployerResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *redisResultStore) Set(ctx context.Context, key string, result *DeployerResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, redisKeyPrefix+key, data, deployerResultTTL).Err()
+}
+
+func (r *redisResultStore) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, redisKeyPrefix+key).Err()
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	fakedeployer "github.com/projectsveltos/libsveltos/lib/deployer/fake"
+)
+
+type memoryResultStore struct {
+	mu      sync.Mutex
+	results map[string]controllers.DeployerResult
+}
+
+func (m *memoryResultStore) Get(_ context.Context, key string) (*controllers.DeployerResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result, ok := m.results[key]
+	if !ok {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+func (m *memoryResultStore) Set(_ context.Context, key string, result *controllers.DeployerResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.results[key] = *result
+	return nil
+}
+
+func (m *memoryResultStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.results, key)
+	return nil
+}
+
+var _ = Describe("Deployer result store", func() {
+	var store *memoryResultStore
+	var clusterNamespace, clusterName, applicant string
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	const featureID = string(configv1beta1.FeatureResources)
+
+	BeforeEach(func() {
+		store = &memoryResultStore{results: make(map[string]controllers.DeployerResult)}
+		clusterNamespace = randomString()
+		clusterName = randomString()
+		applicant = randomString()
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	getDeployer := func() deployer.DeployerInterface {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		// deployer is a singleton (its workers are not stopped when test ends), so
+		// feature might already be registered
+		d := deployer.GetClient(context.Background(), logger, c, 1)
+		_ = d.RegisterFeatureID(featureID)
+		return controllers.NewPersistentDeployer(d, store, logger)
+	}
+
+	// getRestartedDeployer returns a deployer with no result in memory, as after a restart
+	getRestartedDeployer := func() deployer.DeployerInterface {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		return controllers.NewPersistentDeployer(fakedeployer.GetClient(ctx, logger, c), store, logger)
+	}
+
+	getHandler := func(err error) deployer.RequestHandler {
+		return func(_ context.Context, _ client.Client, _, _, _, _ string, _ libsveltosv1beta1.ClusterType,
+			_ deployer.Options, _ logr.Logger) error {
+
+			return err
+		}
+	}
+
+	metricHandler := func(_ time.Duration, _, _, _ string, _ libsveltosv1beta1.ClusterType, _ logr.Logger) {}
+
+	It("results survive a restart", func() {
+		d := getDeployer()
+		Expect(d.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, libsveltosv1beta1.ClusterTypeCapi,
+			false, getHandler(&controllers.NonRetriableError{Message: "invalid"}), metricHandler,
+			deployer.Options{})).To(Succeed())
+
+		key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, libsveltosv1beta1.ClusterTypeCapi, false)
+		Eventually(func() bool {
+			result, err := store.Get(ctx, key)
+			return err == nil && result != nil
+		}, timeout, pollingInterval).Should(BeTrue())
+
+		// A restarted deployer has nothing in memory and falls back to the store
+		restarted := getRestartedDeployer()
+		result := restarted.GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.Failed))
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(result.Err, &nonRetriableError)).To(BeTrue())
+		Expect(result.Err.Error()).To(Equal("invalid"))
+
+		Expect(d.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false, getHandler(nil), metricHandler, deployer.Options{})).To(Succeed())
+		Eventually(func() bool {
+			result, err := store.Get(ctx, key)
+			return err == nil && result != nil && result.Status == deployer.Deployed.String()
+		}, timeout, pollingInterval).Should(BeTrue())
+
+		result = getRestartedDeployer().GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.Deployed))
+		Expect(result.Err).To(BeNil())
+	})
+
+	It("CleanupEntries removes stored result", func() {
+		key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, libsveltosv1beta1.ClusterTypeCapi, true)
+		Expect(store.Set(ctx, key, &controllers.DeployerResult{Status: deployer.Removed.String()})).To(Succeed())
+
+		d := getRestartedDeployer()
+		result := d.GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, true)
+		Expect(result.ResultStatus).To(Equal(deployer.Removed))
+
+		d.CleanupEntries(clusterNamespace, clusterName, applicant, featureID, libsveltosv1beta1.ClusterTypeCapi, true)
+		stored, err := store.Get(ctx, key)
+		Expect(err).To(BeNil())
+		Expect(stored).To(BeNil())
+
+		result = d.GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, true)
+		Expect(result.ResultStatus).To(Equal(deployer.Unavailable))
+	})
+})
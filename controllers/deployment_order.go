@@ -0,0 +1,89 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// defaultDeploymentOrder is the order features are deployed in when not listed in DeploymentOrder
+var defaultDeploymentOrder = []configv1beta1.FeatureID{
+	configv1beta1.FeatureResources,
+	configv1beta1.FeatureHelm,
+	configv1beta1.FeatureKustomize,
+	configv1beta1.FeatureJobs,
+	configv1beta1.FeatureExtensions,
+}
+
+// getFeatureDeploymentOrder returns all features in the order they need to be deployed: the ones
+// listed in deploymentOrder first, followed by all others in the default order
+func getFeatureDeploymentOrder(deploymentOrder []configv1beta1.FeatureID) []configv1beta1.FeatureID {
+	result := make([]configv1beta1.FeatureID, 0, len(defaultDeploymentOrder))
+	listed := make(map[configv1beta1.FeatureID]bool, len(deploymentOrder))
+	for i := range deploymentOrder {
+		if listed[deploymentOrder[i]] {
+			continue
+		}
+		listed[deploymentOrder[i]] = true
+		result = append(result, deploymentOrder[i])
+	}
+
+	for i := range defaultDeploymentOrder {
+		if !listed[defaultDeploymentOrder[i]] {
+			result = append(result, defaultDeploymentOrder[i])
+		}
+	}
+
+	return result
+}
+
+// deployInOrder deploys the features of a ClusterSummary one after the other, following its
+// DeploymentOrder. A feature is deployed only once all the features preceding it are provisioned.
+func (r *ClusterSummaryReconciler) deployInOrder(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) error {
+
+	deployers := map[configv1beta1.FeatureID]func(context.Context, *scope.ClusterSummaryScope, logr.Logger) error{
+		configv1beta1.FeatureResources:  r.deployResources,
+		configv1beta1.FeatureHelm:       r.deployHelm,
+		configv1beta1.FeatureKustomize:  r.deployKustomizeRefs,
+		configv1beta1.FeatureJobs:       r.deployJobs,
+		configv1beta1.FeatureExtensions: r.deployExtensions,
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	order := getFeatureDeploymentOrder(clusterSummary.Spec.ClusterProfileSpec.DeploymentOrder)
+	for i := range order {
+		if err := deployers[order[i]](ctx, clusterSummaryScope, logger); err != nil {
+			return err
+		}
+
+		// Features with no status have nothing to deploy
+		if r.isFeatureStatusPresent(clusterSummary, order[i]) && !r.isFeatureDeployed(clusterSummary, order[i]) {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("waiting for feature %s to be provisioned", order[i]))
+			return fmt.Errorf("feature %s is still being provisioned", order[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("DeploymentOrder", func() {
+	It("getFeatureDeploymentOrder returns listed features first, followed by the others in default order", func() {
+		order := controllers.GetFeatureDeploymentOrder(
+			[]configv1beta1.FeatureID{configv1beta1.FeatureKustomize, configv1beta1.FeatureHelm})
+		Expect(order).To(Equal([]configv1beta1.FeatureID{
+			configv1beta1.FeatureKustomize,
+			configv1beta1.FeatureHelm,
+			configv1beta1.FeatureResources,
+			configv1beta1.FeatureJobs,
+			configv1beta1.FeatureExtensions,
+		}))
+	})
+
+	It("getFeatureDeploymentOrder ignores duplicates", func() {
+		order := controllers.GetFeatureDeploymentOrder(
+			[]configv1beta1.FeatureID{configv1beta1.FeatureHelm, configv1beta1.FeatureHelm})
+		Expect(order).To(Equal([]configv1beta1.FeatureID{
+			configv1beta1.FeatureHelm,
+			configv1beta1.FeatureResources,
+			configv1beta1.FeatureKustomize,
+			configv1beta1.FeatureJobs,
+			configv1beta1.FeatureExtensions,
+		}))
+	})
+})
@@ -0,0 +1,103 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+// DeploymentSupersededError is returned when a deployment pass is aborted because the ClusterSummary
+// Spec changed while the pass was in progress. It is not a failure: the latest Spec is deployed instead,
+// so intermediate revisions of a profile edited multiple times in a row are not applied to every cluster.
+type DeploymentSupersededError struct {
+	// Generation is the ClusterSummary generation the aborted pass was deploying
+	Generation int64
+	// CurrentGeneration is the ClusterSummary generation to deploy instead
+	CurrentGeneration int64
+	// Deleted is set if the ClusterSummary was deleted while the pass was in progress
+	Deleted bool
+}
+
+func (e *DeploymentSupersededError) Error() string {
+	if e.Deleted {
+		return fmt.Sprintf("deployment of generation %d superseded: ClusterSummary deleted", e.Generation)
+	}
+	return fmt.Sprintf("deployment of generation %d superseded by generation %d",
+		e.Generation, e.CurrentGeneration)
+}
+
+func isDeploymentSupersededError(err error) bool {
+	var supersededError *DeploymentSupersededError
+	return errors.As(err, &supersededError)
+}
+
+type deploymentRevisionContextKey struct{}
+
+// deploymentRevision is the ClusterSummary generation a deployment pass is deploying
+type deploymentRevision struct {
+	c          client.Client
+	key        types.NamespacedName
+	generation int64
+}
+
+// withDeploymentRevision returns a context recording the ClusterSummary generation a deployment
+// pass started from
+func withDeploymentRevision(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary) context.Context {
+
+	return context.WithValue(ctx, deploymentRevisionContextKey{},
+		&deploymentRevision{
+			c:          c,
+			key:        types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			generation: clusterSummary.Generation,
+		})
+}
+
+// checkDeploymentSuperseded returns a DeploymentSupersededError if the ClusterSummary Spec changed
+// since the deployment pass started. While the pass is in progress, the deployer queues the request
+// for the latest Spec only once, so all revisions in between are skipped. A ClusterSummary deleted
+// while the pass is in progress supersedes the pass as well.
+// Nothing is checked if ctx does not carry the generation being deployed.
+func checkDeploymentSuperseded(ctx context.Context) error {
+	revision, ok := ctx.Value(deploymentRevisionContextKey{}).(*deploymentRevision)
+	if !ok {
+		return nil
+	}
+
+	currentClusterSummary := &configv1beta1.ClusterSummary{}
+	if err := revision.c.Get(ctx, revision.key, currentClusterSummary); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &DeploymentSupersededError{Generation: revision.generation, Deleted: true}
+		}
+		return err
+	}
+
+	if currentClusterSummary.Generation != revision.generation {
+		return &DeploymentSupersededError{Generation: revision.generation,
+			CurrentGeneration: currentClusterSummary.Generation}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Deployment superseded", func() {
+	It("checkDeploymentSuperseded aborts a deployment once ClusterSummary Spec changes", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       randomString(),
+				Namespace:  randomString(),
+				Generation: 3,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+
+		Expect(controllers.CheckDeploymentSuperseded(context.TODO())).To(Succeed())
+
+		ctx := controllers.WithDeploymentRevision(context.TODO(), c, clusterSummary)
+		Expect(controllers.CheckDeploymentSuperseded(ctx)).To(Succeed())
+
+		deployed := clusterSummary.DeepCopy()
+		deployed.Generation = 1
+		ctx = controllers.WithDeploymentRevision(context.TODO(), c, deployed)
+		err := controllers.CheckDeploymentSuperseded(ctx)
+		Expect(err).ToNot(BeNil())
+
+		var supersededError *controllers.DeploymentSupersededError
+		Expect(errors.As(err, &supersededError)).To(BeTrue())
+		Expect(supersededError.Generation).To(Equal(int64(1)))
+		Expect(supersededError.CurrentGeneration).To(Equal(int64(3)))
+	})
+
+	It("checkDeploymentSuperseded aborts a deployment once ClusterSummary is deleted", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       randomString(),
+				Namespace:  randomString(),
+				Generation: 2,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		ctx := controllers.WithDeploymentRevision(context.TODO(), c, clusterSummary)
+		err := controllers.CheckDeploymentSuperseded(ctx)
+		Expect(err).ToNot(BeNil())
+
+		var supersededError *controllers.DeploymentSupersededError
+		Expect(errors.As(err, &supersededError)).To(BeTrue())
+		Expect(supersededError.Deleted).To(BeTrue())
+		Expect(supersededError.Generation).To(Equal(int64(2)))
+	})
+
+	It("checkDeploymentSuperseded returns any other error fetching the ClusterSummary", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       randomString(),
+				Namespace:  randomString(),
+				Generation: 2,
+			},
+		}
+
+		// ClusterSummary is not registered in the scheme
+		c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+
+		ctx := controllers.WithDeploymentRevision(context.TODO(), c, clusterSummary)
+		err := controllers.CheckDeploymentSuperseded(ctx)
+		Expect(err).ToNot(BeNil())
+
+		var supersededError *controllers.DeploymentSupersededError
+		Expect(errors.As(err, &supersededError)).To(BeFalse())
+	})
+})
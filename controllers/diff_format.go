@@ -0,0 +1,345 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+// DiffFormat is the output format of the profile diff and cluster report endpoints
+type DiffFormat string
+
+const (
+	// DiffFormatJSON is the structured format. For resources, changes are reported
+	// as JSON patches
+	DiffFormatJSON = DiffFormat("json")
+
+	// DiffFormatSummary reports, per kind, how many resources would be created/updated/deleted
+	DiffFormatSummary = DiffFormat("summary")
+
+	// DiffFormatUnified reports changes as a unified text diff of the resources YAML
+	DiffFormatUnified = DiffFormat("unified")
+
+	// unifiedDiffContext is the number of unchanged lines shown around each change
+	unifiedDiffContext = 3
+)
+
+// getDiffFormat returns the format requested via the format query parameter.
+// DiffFormatJSON is returned when no format is requested.
+func getDiffFormat(format string) (DiffFormat, error) {
+	switch DiffFormat(format) {
+	case "", DiffFormatJSON:
+		return DiffFormatJSON, nil
+	case DiffFormatSummary, DiffFormatUnified:
+		return DiffFormat(format), nil
+	default:
+		return "", fmt.Errorf("format must be one of %s, %s, %s", DiffFormatJSON, DiffFormatSummary,
+			DiffFormatUnified)
+	}
+}
+
+// ProfileDiffSummary counts, per kind and action, the changes in a ProfileDiff
+type ProfileDiffSummary struct {
+	Cluster corev1.ObjectReference `json:"cluster"`
+	// Resources contains, per kind, the number of resources per action
+	Resources map[string]map[DiffAction]int `json:"resources,omitempty"`
+	// HelmReleases contains the number of helm releases per action
+	HelmReleases map[DiffAction]int `json:"helmReleases,omitempty"`
+}
+
+func summarizeProfileDiff(diff *ProfileDiff) *ProfileDiffSummary {
+	summary := &ProfileDiffSummary{
+		Cluster:      diff.Cluster,
+		Resources:    make(map[string]map[DiffAction]int),
+		HelmReleases: make(map[DiffAction]int),
+	}
+
+	for i := range diff.Resources {
+		kind := diff.Resources[i].Kind
+		if summary.Resources[kind] == nil {
+			summary.Resources[kind] = make(map[DiffAction]int)
+		}
+		summary.Resources[kind][diff.Resources[i].Action]++
+	}
+
+	for i := range diff.HelmReleases {
+		summary.HelmReleases[diff.HelmReleases[i].Action]++
+	}
+
+	return summary
+}
+
+// renderUnifiedProfileDiff renders a ProfileDiff as a unified text diff. Resources not changing
+// are skipped. Helm releases are reported one per line.
+func renderUnifiedProfileDiff(diff *ProfileDiff) string {
+	var sb strings.Builder
+
+	for i := range diff.Resources {
+		rd := &diff.Resources[i]
+		if rd.Action == DiffActionNoChange {
+			continue
+		}
+
+		name := fmt.Sprintf("%s/%s/%s/%s", rd.APIVersion, rd.Kind, rd.Namespace, rd.Name)
+		if rd.Namespace == "" {
+			name = fmt.Sprintf("%s/%s/%s", rd.APIVersion, rd.Kind, rd.Name)
+		}
+
+		fromName, toName := "a/"+name, "b/"+name
+		switch rd.Action {
+		case DiffActionCreate:
+			fromName = "/dev/null"
+		case DiffActionDelete:
+			toName = "/dev/null"
+		}
+
+		sb.WriteString(unifiedDiff(fromName, toName, rd.current, rd.desired))
+	}
+
+	for i := range diff.HelmReleases {
+		hd := &diff.HelmReleases[i]
+		if hd.Action == DiffActionNoChange {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("helm release %s/%s: %s", hd.ReleaseNamespace, hd.ReleaseName, hd.Action))
+		if hd.DeployedVersion != "" || hd.DesiredVersion != "" {
+			sb.WriteString(fmt.Sprintf(" (%s -> %s)", hd.DeployedVersion, hd.DesiredVersion))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ClusterReportSummary counts, per kind and action, the changes reported in a ClusterReport
+type ClusterReportSummary struct {
+	Cluster corev1.ObjectReference `json:"cluster"`
+	// Resources contains, per kind, the number of resources deployed because of
+	// PolicyRefs per action
+	Resources map[string]map[string]int `json:"resources,omitempty"`
+	// KustomizeResources contains, per kind, the number of resources deployed because
+	// of KustomizationRefs per action
+	KustomizeResources map[string]map[string]int `json:"kustomizeResources,omitempty"`
+	// HelmReleases contains the number of helm releases per action
+	HelmReleases map[string]int `json:"helmReleases,omitempty"`
+}
+
+func summarizeClusterReport(clusterReport *configv1beta1.ClusterReport) *ClusterReportSummary {
+	summary := &ClusterReportSummary{
+		Cluster: corev1.ObjectReference{
+			Namespace: clusterReport.Spec.ClusterNamespace,
+			Name:      clusterReport.Spec.ClusterName,
+		},
+		Resources:          summarizeResourceReports(clusterReport.Status.ResourceReports),
+		KustomizeResources: summarizeResourceReports(clusterReport.Status.KustomizeResourceReports),
+		HelmReleases:       make(map[string]int),
+	}
+
+	for i := range clusterReport.Status.ReleaseReports {
+		summary.HelmReleases[clusterReport.Status.ReleaseReports[i].Action]++
+	}
+
+	return summary
+}
+
+func summarizeResourceReports(resourceReports []configv1beta1.ResourceReport) map[string]map[string]int {
+	result := make(map[string]map[string]int)
+	for i := range resourceReports {
+		kind := resourceReports[i].Resource.Kind
+		if result[kind] == nil {
+			result[kind] = make(map[string]int)
+		}
+		result[kind][resourceReports[i].Action]++
+	}
+	return result
+}
+
+// marshalDiffContent returns the YAML representation of a resource content
+func marshalDiffContent(content interface{}) (string, error) {
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// filterDiffFields returns current with only the map fields also set in desired.
+// Lists and values are returned as they are.
+func filterDiffFields(current, desired interface{}) interface{} {
+	currentMap, ok := current.(map[string]interface{})
+	if !ok {
+		return current
+	}
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok {
+		return current
+	}
+
+	result := make(map[string]interface{})
+	for key := range desiredMap {
+		if value, ok := currentMap[key]; ok {
+			result[key] = filterDiffFields(value, desiredMap[key])
+		}
+	}
+	return result
+}
+
+// diffLine is a line of an edit script: op is ' ' (unchanged), '-' (removed) or '+' (added)
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the unified diff between from and to. Returns an empty string
+// if there is no difference.
+func unifiedDiff(fromName, toName, from, to string) string {
+	edits := computeLineEdits(splitLines(from), splitLines(to))
+
+	changed := false
+	for i := range edits {
+		if edits[i].op != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	// fromLine and toLine are the number of lines of from and to before edits[i]
+	fromLine, toLine := 0, 0
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			fromLine++
+			toLine++
+			i++
+			continue
+		}
+
+		// Hunk starts unifiedDiffContext lines before the change
+		start := i
+		for start > 0 && i-start < unifiedDiffContext && edits[start-1].op == ' ' {
+			start--
+		}
+		hunkFromStart, hunkToStart := fromLine-(i-start), toLine-(i-start)
+
+		// Changes separated by at most 2*unifiedDiffContext unchanged lines are in the same hunk.
+		// Hunk ends unifiedDiffContext lines after its last change
+		lastChange := i
+		for k := i; k < len(edits) && k-lastChange <= 2*unifiedDiffContext; k++ {
+			if edits[k].op != ' ' {
+				lastChange = k
+			}
+		}
+		end := min(lastChange+unifiedDiffContext+1, len(edits))
+
+		fromCount, toCount := 0, 0
+		var hunk strings.Builder
+		for j := start; j < end; j++ {
+			hunk.WriteString(fmt.Sprintf("%c%s\n", edits[j].op, edits[j].text))
+			if edits[j].op != '+' {
+				fromCount++
+			}
+			if edits[j].op != '-' {
+				toCount++
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunkFromStart, fromCount),
+			hunkRange(hunkToStart, toCount)))
+		sb.WriteString(hunk.String())
+
+		for j := i; j < end; j++ {
+			if edits[j].op != '+' {
+				fromLine++
+			}
+			if edits[j].op != '-' {
+				toLine++
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats a hunk range. linesBefore is the number of lines preceding the hunk.
+func hunkRange(linesBefore, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", linesBefore)
+	}
+	return fmt.Sprintf("%d,%d", linesBefore+1, count)
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// computeLineEdits returns the edit script transforming from into to, based on
+// their longest common subsequence
+func computeLineEdits(from, to []string) []diffLine {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]diffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			edits = append(edits, diffLine{op: ' ', text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, diffLine{op: '-', text: from[i]})
+			i++
+		default:
+			edits = append(edits, diffLine{op: '+', text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		edits = append(edits, diffLine{op: '-', text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		edits = append(edits, diffLine{op: '+', text: to[j]})
+	}
+
+	return edits
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Diff formats", func() {
+	It("unifiedDiff reports changed lines with context", func() {
+		Expect(controllers.UnifiedDiff("a/x", "b/x", "a\nb\n", "a\nb\n")).To(BeEmpty())
+
+		Expect(controllers.UnifiedDiff("a/x", "b/x", "a\nb\nc\n", "a\nB\nc\n")).To(Equal(
+			"--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"))
+
+		Expect(controllers.UnifiedDiff("/dev/null", "b/x", "", "x: 1\n")).To(Equal(
+			"--- /dev/null\n+++ b/x\n@@ -0,0 +1,1 @@\n+x: 1\n"))
+	})
+
+	It("renderUnifiedProfileDiff renders updated resources as unified diff", func() {
+		configMap := &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{"key": "value"},
+		}
+
+		initObjects := []client.Object{configMap}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+		Expect(err).To(BeNil())
+		desired := &unstructured.Unstructured{Object: content}
+		unstructured.RemoveNestedField(desired.Object, "metadata", "creationTimestamp")
+		Expect(unstructured.SetNestedField(desired.Object, "new-value", "data", "key")).To(Succeed())
+
+		resourceDiff, err := controllers.GetResourceDiff(context.TODO(), c, desired)
+		Expect(err).To(BeNil())
+
+		diff := &controllers.ProfileDiff{
+			Resources: []controllers.ResourceDiff{*resourceDiff},
+			HelmReleases: []controllers.HelmReleaseDiff{
+				{ReleaseNamespace: "kyverno", ReleaseName: "kyverno", Action: controllers.DiffActionUpgrade,
+					DeployedVersion: "v3.0.1", DesiredVersion: "v3.0.2"},
+			},
+		}
+
+		result := controllers.RenderUnifiedProfileDiff(diff)
+		Expect(result).To(ContainSubstring("--- a/v1/ConfigMap/%s/%s", configMap.Namespace, configMap.Name))
+		Expect(result).To(ContainSubstring("-  key: value\n"))
+		Expect(result).To(ContainSubstring("+  key: new-value\n"))
+		Expect(result).To(ContainSubstring("helm release kyverno/kyverno: Upgrade (v3.0.1 -> v3.0.2)"))
+	})
+
+	It("summarizeProfileDiff counts changes per kind and action", func() {
+		diff := &controllers.ProfileDiff{
+			Resources: []controllers.ResourceDiff{
+				{Kind: "ConfigMap", Action: controllers.DiffActionCreate},
+				{Kind: "ConfigMap", Action: controllers.DiffActionCreate},
+				{Kind: "ConfigMap", Action: controllers.DiffActionUpdate},
+				{Kind: "Secret", Action: controllers.DiffActionDelete},
+			},
+			HelmReleases: []controllers.HelmReleaseDiff{
+				{Action: controllers.DiffActionInstall},
+			},
+		}
+
+		summary := controllers.SummarizeProfileDiff(diff)
+		Expect(summary.Resources).To(Equal(map[string]map[controllers.DiffAction]int{
+			"ConfigMap": {controllers.DiffActionCreate: 2, controllers.DiffActionUpdate: 1},
+			"Secret":    {controllers.DiffActionDelete: 1},
+		}))
+		Expect(summary.HelmReleases).To(Equal(map[controllers.DiffAction]int{controllers.DiffActionInstall: 1}))
+	})
+
+	It("summarizeClusterReport counts reports per kind and action", func() {
+		clusterReport := &configv1beta1.ClusterReport{
+			Status: configv1beta1.ClusterReportStatus{
+				ResourceReports: []configv1beta1.ResourceReport{
+					{Resource: configv1beta1.Resource{Kind: "ConfigMap"}, Action: string(configv1beta1.CreateResourceAction)},
+					{Resource: configv1beta1.Resource{Kind: "ConfigMap"}, Action: string(configv1beta1.CreateResourceAction)},
+				},
+				KustomizeResourceReports: []configv1beta1.ResourceReport{
+					{Resource: configv1beta1.Resource{Kind: "Service"}, Action: string(configv1beta1.UpdateResourceAction)},
+				},
+				ReleaseReports: []configv1beta1.ReleaseReport{
+					{ReleaseName: randomString(), Action: string(configv1beta1.InstallHelmAction)},
+				},
+			},
+		}
+
+		summary := controllers.SummarizeClusterReport(clusterReport)
+		Expect(summary.Resources).To(Equal(map[string]map[string]int{
+			"ConfigMap": {string(configv1beta1.CreateResourceAction): 2},
+		}))
+		Expect(summary.KustomizeResources).To(Equal(map[string]map[string]int{
+			"Service": {string(configv1beta1.UpdateResourceAction): 1},
+		}))
+		Expect(summary.HelmReleases).To(Equal(map[string]int{string(configv1beta1.InstallHelmAction): 1}))
+	})
+})
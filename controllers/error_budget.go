@@ -0,0 +1,126 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+const (
+	// defaultErrorBudgetWindow is the window used when ErrorBudget does not set one
+	defaultErrorBudgetWindow = time.Hour
+
+	// maxDeploymentOutcomes is the maximum number of deployment outcomes tracked per ClusterSummary
+	maxDeploymentOutcomes = 50
+
+	// errorBudgetRequeueAfter is how often a ClusterProfile/Profile with an ErrorBudget is
+	// reconciled so that outcomes leaving the window are accounted for
+	errorBudgetRequeueAfter = time.Minute
+)
+
+func getErrorBudgetWindow(errorBudget *configv1beta1.ErrorBudget) time.Duration {
+	if errorBudget.Window.Duration <= 0 {
+		return defaultErrorBudgetWindow
+	}
+	return errorBudget.Window.Duration
+}
+
+// recordDeploymentOutcome appends the outcome of a feature deployment to the ClusterSummary
+// Status. Outcomes older than the ErrorBudget window are dropped. When no ErrorBudget is set,
+// outcomes are not tracked.
+func recordDeploymentOutcome(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	succeeded bool, now time.Time) {
+
+	errorBudget := clusterSummary.Spec.ClusterProfileSpec.ErrorBudget
+	if errorBudget == nil {
+		clusterSummary.Status.DeploymentOutcomes = nil
+		return
+	}
+
+	outcomes := append(clusterSummary.Status.DeploymentOutcomes, configv1beta1.DeploymentOutcome{
+		FeatureID: featureID,
+		Time:      metav1.NewTime(now),
+		Succeeded: succeeded,
+	})
+
+	cutoff := now.Add(-getErrorBudgetWindow(errorBudget))
+	first := 0
+	for first < len(outcomes) && (outcomes[first].Time.Time.Before(cutoff) ||
+		len(outcomes)-first > maxDeploymentOutcomes) {
+
+		first++
+	}
+
+	clusterSummary.Status.DeploymentOutcomes = outcomes[first:]
+}
+
+// updateDegradedCondition sets the ClusterProfile/Profile DegradedCondition based on the
+// deployment outcomes, within the ErrorBudget window, of the ClusterSummaries of matching clusters.
+// Condition is removed when no ErrorBudget is set.
+func updateDegradedCondition(profileScope *scope.ProfileScope, clusterSummaries []*configv1beta1.ClusterSummary,
+	now time.Time) {
+
+	errorBudget := profileScope.GetSpec().ErrorBudget
+	if errorBudget == nil {
+		meta.RemoveStatusCondition(&profileScope.GetStatus().Conditions, configv1beta1.DegradedCondition)
+		return
+	}
+
+	window := getErrorBudgetWindow(errorBudget)
+	cutoff := now.Add(-window)
+
+	var attempted, succeeded int
+	for i := range clusterSummaries {
+		for j := range clusterSummaries[i].Status.DeploymentOutcomes {
+			outcome := &clusterSummaries[i].Status.DeploymentOutcomes[j]
+			if outcome.Time.Time.Before(cutoff) {
+				continue
+			}
+			attempted++
+			if outcome.Succeeded {
+				succeeded++
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.DegradedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.WithinErrorBudgetReason,
+		Message:            fmt.Sprintf("no deployment attempted in the last %s", window),
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+
+	if attempted != 0 {
+		successRate := float64(succeeded) * 100 / float64(attempted)
+		condition.Message = fmt.Sprintf("success rate %.1f%% (%d/%d deployments) in the last %s, threshold %d%%",
+			successRate, succeeded, attempted, window, errorBudget.SuccessRateThreshold)
+		if successRate < float64(errorBudget.SuccessRateThreshold) {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = configv1beta1.ErrorBudgetExhaustedReason
+		}
+	}
+
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var _ = Describe("Error budget", func() {
+	It("recordDeploymentOutcome tracks outcomes within the window", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), Namespace: randomString()},
+		}
+
+		now := time.Now()
+		controllers.RecordDeploymentOutcome(clusterSummary, configv1beta1.FeatureHelm, true, now)
+		// No ErrorBudget, nothing is tracked
+		Expect(clusterSummary.Status.DeploymentOutcomes).To(BeEmpty())
+
+		clusterSummary.Spec.ClusterProfileSpec.ErrorBudget = &configv1beta1.ErrorBudget{
+			Window:               metav1.Duration{Duration: time.Hour},
+			SuccessRateThreshold: 90,
+		}
+		controllers.RecordDeploymentOutcome(clusterSummary, configv1beta1.FeatureHelm, true, now.Add(-2*time.Hour))
+		controllers.RecordDeploymentOutcome(clusterSummary, configv1beta1.FeatureHelm, false, now)
+		Expect(clusterSummary.Status.DeploymentOutcomes).To(HaveLen(1))
+		Expect(clusterSummary.Status.DeploymentOutcomes[0].Succeeded).To(BeFalse())
+		Expect(clusterSummary.Status.DeploymentOutcomes[0].FeatureID).To(Equal(configv1beta1.FeatureHelm))
+
+		// Number of outcomes tracked is bounded
+		for i := 0; i < 100; i++ {
+			controllers.RecordDeploymentOutcome(clusterSummary, configv1beta1.FeatureResources, true, now)
+		}
+		Expect(len(clusterSummary.Status.DeploymentOutcomes)).To(BeNumerically("<", 100))
+		Expect(clusterSummary.Status.DeploymentOutcomes[0].FeatureID).To(Equal(configv1beta1.FeatureResources))
+	})
+
+	It("updateDegradedCondition sets Degraded when success rate is below threshold", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: textlogger.NewLogger(textlogger.NewConfig()), Profile: clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		now := time.Now()
+		outcome := func(succeeded bool, age time.Duration) configv1beta1.DeploymentOutcome {
+			return configv1beta1.DeploymentOutcome{
+				FeatureID: configv1beta1.FeatureHelm, Time: metav1.NewTime(now.Add(-age)), Succeeded: succeeded,
+			}
+		}
+		clusterSummaries := []*configv1beta1.ClusterSummary{
+			{Status: configv1beta1.ClusterSummaryStatus{DeploymentOutcomes: []configv1beta1.DeploymentOutcome{
+				outcome(true, time.Minute), outcome(true, time.Minute), outcome(false, 2*time.Hour),
+			}}},
+			{Status: configv1beta1.ClusterSummaryStatus{DeploymentOutcomes: []configv1beta1.DeploymentOutcome{
+				outcome(true, time.Minute), outcome(false, time.Minute),
+			}}},
+		}
+
+		// No ErrorBudget, no condition
+		controllers.UpdateDegradedCondition(profileScope, clusterSummaries, now)
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.DegradedCondition)).To(BeNil())
+
+		clusterProfile.Spec.ErrorBudget = &configv1beta1.ErrorBudget{
+			Window:               metav1.Duration{Duration: time.Hour},
+			SuccessRateThreshold: 90,
+		}
+		controllers.UpdateDegradedCondition(profileScope, clusterSummaries, now)
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.DegradedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.ErrorBudgetExhaustedReason))
+		Expect(condition.Message).To(ContainSubstring("75.0%"))
+
+		clusterProfile.Spec.ErrorBudget.SuccessRateThreshold = 75
+		controllers.UpdateDegradedCondition(profileScope, clusterSummaries, now)
+		condition = meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.DegradedCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.WithinErrorBudgetReason))
+
+		clusterProfile.Spec.ErrorBudget = nil
+		controllers.UpdateDegradedCondition(profileScope, clusterSummaries, now)
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.DegradedCondition)).To(BeNil())
+	})
+})
@@ -16,6 +16,20 @@ limitations under the License.
 
 package controllers
 
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
 var (
 	UpdateClusterSummaries                = updateClusterSummaries
 	CreateClusterSummary                  = createClusterSummary
@@ -30,6 +44,11 @@ var (
 	GetMaxUpdate                          = getMaxUpdate
 	ReviseUpdatedAndUpdatingClusters      = reviseUpdatedAndUpdatingClusters
 	GetUpdatedAndUpdatingClusters         = getUpdatedAndUpdatingClusters
+	MigrateToSuccessor                    = migrateToSuccessor
+	IsOwnedByAdoptedProfile               = isOwnedByAdoptedProfile
+	IsOwnedByAdoptingProfile              = isOwnedByAdoptingProfile
+	IsAdoptingFrom                        = isAdoptingFrom
+	ReleaseClusterSummary                 = releaseClusterSummary
 )
 
 var (
@@ -53,6 +72,8 @@ var (
 	DeployFeature                        = (*ClusterSummaryReconciler).deployFeature
 	UndeployFeature                      = (*ClusterSummaryReconciler).undeployFeature
 	GetCurrentReferences                 = (*ClusterSummaryReconciler).getCurrentReferences
+	UpdateMissingReferences              = (*ClusterSummaryReconciler).updateMissingReferences
+	ProcessRedeployRequest               = (*ClusterSummaryReconciler).processRedeployRequest
 	IsPaused                             = (*ClusterSummaryReconciler).isPaused
 	IsReady                              = (*ClusterSummaryReconciler).isReady
 	ShouldReconcile                      = (*ClusterSummaryReconciler).shouldReconcile
@@ -61,6 +82,8 @@ var (
 	CanRemoveFinalizer                   = (*ClusterSummaryReconciler).canRemoveFinalizer
 	ReconcileDelete                      = (*ClusterSummaryReconciler).reconcileDelete
 	AreDependenciesDeployed              = (*ClusterSummaryReconciler).areDependenciesDeployed
+	AreLowerTiersProvisioned             = (*ClusterSummaryReconciler).areLowerTiersProvisioned
+	AreHealthChecksPassing               = (*ClusterSummaryReconciler).areHealthChecksPassing
 	SetFailureMessage                    = (*ClusterSummaryReconciler).setFailureMessage
 	ResetFeatureStatus                   = (*ClusterSummaryReconciler).resetFeatureStatus
 
@@ -93,6 +116,7 @@ var (
 	GetDeployedGroupVersionKinds = getDeployedGroupVersionKinds
 	CanDelete                    = canDelete
 	HandleResourceDelete         = handleResourceDelete
+	IsLeavePolicies              = isLeavePolicies
 	GetSecret                    = getSecret
 	ReadFiles                    = readFiles
 
@@ -105,6 +129,8 @@ var (
 
 	UndeployKustomizeRefs             = undeployKustomizeRefs
 	KustomizationHash                 = kustomizationHash
+	InstantiateKustomizationPath      = instantiateKustomizationPath
+	DeployEachKustomizeRefs           = deployEachKustomizeRefs
 	GetKustomizeReferenceResourceHash = getKustomizeReferenceResourceHash
 	ExtractTarGz                      = extractTarGz
 	//nolint: gocritic // getDataSectionHash is generic and needs instantiation
@@ -128,8 +154,55 @@ var (
 	HandleCharts                             = handleCharts
 	GetHelmReferenceResourceHash             = getHelmReferenceResourceHash
 	GetHelmChartValuesHash                   = getHelmChartValuesHash
+	MergeValues                              = mergeValues
+	MergeHelmChartValuesFrom                 = mergeHelmChartValuesFrom
+	IsHelmRollbackError                      = isHelmRollbackError
+	GetHelmUninstallClient                   = getHelmUninstallClient
+	InstantiateHelmReleases                  = instantiateHelmReleases
 	GetCredentialsAndCAFiles                 = getCredentialsAndCAFiles
 
+	SelectChartVersion              = selectChartVersion
+	ValidateHelmChartVersions       = validateHelmChartVersions
+	GetPendingUpgradeVersion        = getPendingUpgradeVersion
+	ResolveChartVersion             = resolveChartVersion
+	GetChartVersionsCacheKey        = getChartVersionsCacheKey
+	PollChartVersions               = pollChartVersions
+	ResolveHelmChartVersions        = resolveHelmChartVersions
+	GetChartVersionsKey             = getChartVersionsKey
+	GetPinnedChartVersions          = getPinnedChartVersions
+	PollClusterSummaryChartVersions = pollClusterSummaryChartVersions
+
+	GetTimeUntilMaintenanceWindow = getTimeUntilMaintenanceWindow
+	SetMaintenanceWindowCondition = setMaintenanceWindowCondition
+
+	GetActiveBreakGlass = getActiveBreakGlass
+	ValidateBreakGlass  = validateBreakGlass
+
+	SortCustomResourceDefinitionsFirst    = sortCustomResourceDefinitionsFirst
+	GetCustomResourceDefinitionGroupKinds = getCustomResourceDefinitionGroupKinds
+
+	GetResourceDiff     = getResourceDiff
+	GetHelmReleasesDiff = getHelmReleasesDiff
+
+	HelmTestsPassed          = helmTestsPassed
+	GetHelmTestsTimeoutValue = getHelmTestsTimeoutValue
+
+	GetHelmStorageOptions            = getHelmStorageOptions
+	GetHelmStorageDriverAndNamespace = getHelmStorageDriverAndNamespace
+	GetHelmReleaseStorages           = getHelmReleaseStorages
+
+	GetJobs              = getJobs
+	DeployJob            = deployJob
+	RemoveStaleJobs      = removeStaleJobs
+	GetJobFailureMessage = getJobFailureMessage
+
+	LintProfileSpec = lintProfileSpec
+
+	ValidateClusterSummaryReferences = validateClusterSummaryReferences
+
+	DeployExtensions   = deployExtensions
+	UndeployExtensions = undeployExtensions
+
 	InstantiateTemplateValues = instantiateTemplateValues
 
 	IsCluterSummaryProvisioned = isCluterSummaryProvisioned
@@ -172,7 +245,8 @@ var (
 )
 
 const (
-	ReasonLabel = reasonLabel
+	ReasonLabel        = reasonLabel
+	JobOwnerAnnotation = jobOwnerAnnotation
 )
 
 var (
@@ -205,3 +279,373 @@ var (
 var (
 	RemoveDuplicates = removeDuplicates
 )
+
+var (
+	GetCommitState               = getCommitState
+	ReconcileNormalCommon        = reconcileNormalCommon
+	GetGitLabCommitStatusRequest = getGitLabCommitStatusRequest
+)
+
+var (
+	GenerateClusterProfile          = (*FluxTakeoverReconciler).generateClusterProfile
+	ApplyFluxTakeoverClusterProfile = (*FluxTakeoverReconciler).applyClusterProfile
+)
+
+var (
+	IsClusterSummaryInSync  = isClusterSummaryInSync
+	GetHelmReleaseInfo      = getHelmReleaseInfo
+	GetMissingReferenceInfo = getMissingReferenceInfo
+)
+
+var (
+	GetTenant                  = getTenant
+	GetTenantNamespace         = getTenantNamespace
+	IsTenantNamespace          = isTenantNamespace
+	ApplyTenantNamespace       = applyTenantNamespace
+	NewTenantPostRenderer      = newTenantPostRenderer
+	ValidateTenantReleaseHooks = validateTenantReleaseHooks
+)
+
+var (
+	GetGeneratedRBAC = getGeneratedRBAC
+)
+
+var (
+	GetProfileIdentityName = getProfileIdentityName
+	EnsureProfileIdentity  = ensureProfileIdentity
+	RemoveProfileIdentity  = removeProfileIdentity
+	GetTokenRestConfig     = getTokenRestConfig
+)
+
+var (
+	NewArtifactCache   = newArtifactCache
+	ArtifactCacheFetch = (*artifactCache).fetch
+)
+
+var (
+	GetSourcePaths = getSourcePaths
+)
+
+var (
+	ValidateInlineResources = validateInlineResources
+)
+
+var (
+	SetSyncSucceeded          = setSyncSucceeded
+	UpdateSyncStaleCondition  = updateSyncStaleCondition
+	GetLastSuccessfulSyncInfo = getLastSuccessfulSyncInfo
+)
+
+var (
+	ProcessFeatureFailure = (*ClusterSummaryReconciler).processFeatureFailure
+)
+
+var (
+	PublishToFederationPeerOnce = publishToFederationPeer
+)
+
+var (
+	WithWriteBudget     = withWriteBudget
+	GetWriteBudget      = getWriteBudget
+	ConsumeWriteBudget  = (*writeBudget).consume
+	CompleteWriteBudget = (*writeBudget).complete
+)
+
+var (
+	ApplyObserveOnly = applyObserveOnly
+	IsDryRunSync     = isDryRunSync
+)
+
+var (
+	IsMigrationVersionAMatch = isMigrationVersionAMatch
+)
+
+var (
+	UpdateClusterSummaryStatusSummary = updateClusterSummaryStatusSummary
+	UpdateProfileStatusSummary        = updateProfileStatusSummary
+)
+
+var (
+	IsFluxChartSource                 = isFluxChartSource
+	GetFluxChartSource                = getFluxChartSource
+	GetFluxChartSourceObjectReference = (*fluxChartSource).getObjectReference
+	ValidateFluxChartVersion          = validateFluxChartVersion
+	ResolveChartSourceRef             = resolveChartSourceRef
+	ValidateHelmChartSources          = validateHelmChartSources
+)
+
+var (
+	ValidateHelmChartVerification = validateHelmChartVerification
+	VerifyCosignPayload           = verifyCosignPayload
+)
+
+var (
+	GetTemplateVariables = getTemplateVariables
+	ValidateVariables    = validateVariables
+)
+
+var (
+	ArchiveClusterReport      = archiveClusterReport
+	PruneClusterReportHistory = pruneClusterReportHistory
+)
+
+var (
+	CompressResourceReports = compressResourceReports
+	CompressReleaseReports  = compressReleaseReports
+	SetFeatureResources     = setFeatureResources
+)
+
+var (
+	SummarizeProfileDiff     = summarizeProfileDiff
+	SummarizeClusterReport   = summarizeClusterReport
+	RenderUnifiedProfileDiff = renderUnifiedProfileDiff
+	UnifiedDiff              = unifiedDiff
+)
+
+var (
+	UpdateControllerStatusOnce = updateControllerStatus
+	GetQueueStatus             = (*TrackingDeployer).getQueueStatus
+)
+
+var (
+	GetProfileMetricLabels               = getProfileMetricLabels
+	ObserveTemplateInstantiation         = observeTemplateInstantiation
+	ObserveLuaEvaluation                 = observeLuaEvaluation
+	TemplateInstantiationFailuresCounter = templateInstantiationFailuresCounter
+	LuaEvaluationFailuresCounter         = luaEvaluationFailuresCounter
+)
+
+var (
+	GetTenantShare = (*TenantFairness).getShare
+)
+
+var (
+	RecordDeploymentOutcome = recordDeploymentOutcome
+	UpdateDegradedCondition = updateDegradedCondition
+)
+
+var (
+	IsCertificateError      = isCertificateError
+	RetryOnCertificateError = retryOnCertificateError
+)
+
+var (
+	ShouldRunPreflight       = shouldRunPreflight
+	CheckPreflight           = checkPreflight
+	GetPreflightRequirements = getPreflightRequirements
+	SetPreflightCondition    = setPreflightCondition
+)
+
+var (
+	GetStopMatchingBehavior              = getStopMatchingBehavior
+	ValidateStopMatchingBehaviorTemplate = validateStopMatchingBehaviorTemplate
+)
+
+var (
+	ValidateSecretTransformer = validateSecretTransformer
+	GetSecretTransformer      = getSecretTransformer
+)
+
+func (t *secretTransformer) Transform(policy *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return t.transform(policy)
+}
+
+var (
+	ReconcilePromotion      = (*PromotionReconciler).reconcilePromotion
+	GetPromotionProfileName = getPromotionProfileName
+)
+
+var (
+	GuardMatchExpansion = guardMatchExpansion
+)
+
+var (
+	GuardHelmChartsRemoval = guardHelmChartsRemoval
+)
+
+var (
+	LintSpec                               = lintSpec
+	UpdateSpecInvalidCondition             = updateSpecInvalidCondition
+	CheckInstantiatedHelmReleaseCollisions = checkInstantiatedHelmReleaseCollisions
+)
+
+var (
+	GetHelmValuesPresetValues = getHelmValuesPresetValues
+	GetValuesPresets          = getValuesPresets
+)
+
+var (
+	GetFeatureDeploymentOrder = getFeatureDeploymentOrder
+)
+
+var (
+	IsClusterThrottlingError = isClusterThrottlingError
+	RecordClusterThrottling  = recordClusterThrottling
+	GetClusterThrottleDelay  = getClusterThrottleDelay
+	ClearClusterThrottling   = clearClusterThrottling
+)
+
+var (
+	GetInventoryLabelValue = getInventoryLabelValue
+)
+
+var (
+	FilterByExternalMatcher = filterByExternalMatcher
+)
+
+var (
+	SetPausedCondition = setPausedCondition
+)
+
+var (
+	RedactValues             = redactValues
+	GetReleaseComputedValues = getReleaseComputedValues
+	GetValuesDiff            = getValuesDiff
+)
+
+func SetChartReleaseDetails(chart *configv1beta1.Chart, notes string, values map[string]interface{}) error {
+	return setChartReleaseDetails(chart, &releaseInfo{Notes: notes, ComputedValues: values})
+}
+
+var (
+	GetMetadataFieldManager = getMetadataFieldManager
+	GetExtraMetadataObject  = getExtraMetadataObject
+	IsManagedBy             = isManagedBy
+)
+
+var (
+	GetParentMatchingClusters = getParentMatchingClusters
+	GetProfileDependencies    = getProfileDependencies
+)
+
+var (
+	ValidateGuardrails        = validateGuardrails
+	CheckReleaseGuardrails    = checkReleaseGuardrails
+	UpdateGuardrailsCondition = updateGuardrailsCondition
+	ValidateRenderedRelease   = validateRenderedRelease
+)
+
+func CheckGuardrails(resources []*unstructured.Unstructured) error {
+	return checkGuardrails(resources, &guardrailsTally{})
+}
+
+// GetHelmRepositoryEntry returns the repository entry of requestedChart. Client certificate and
+// key files, if any, must be removed by the caller.
+func GetHelmRepositoryEntry(ctx context.Context, c client.Client, clusterNamespace string,
+	requestedChart *configv1beta1.HelmChart) (*repo.Entry, error) {
+
+	registryOptions := &registryClientOptions{}
+	err := setHelmRepositoryAuth(ctx, c, clusterNamespace, requestedChart, registryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return getHelmRepositoryEntry(requestedChart.RepositoryName, requestedChart.RepositoryURL, registryOptions), nil
+}
+
+var (
+	RecordClusterSnapshotsAt = recordClusterSnapshots
+	SimulateProfile          = simulateProfile
+)
+
+var (
+	ResyncClusters        = resyncClusters
+	ValidateClusterResync = validateClusterResync
+)
+
+var (
+	RestartDeploymentsOnSecretRotation = restartDeploymentsOnSecretRotation
+	ValidateSecretRotationHooks        = validateSecretRotationHooks
+	ReloadWorkloads                    = reloadWorkloads
+)
+
+var (
+	ValidateDependencyRepositories = validateDependencyRepositories
+)
+
+func ResolveChartDependencies(ctx context.Context, c client.Client, requestedChart *configv1beta1.HelmChart,
+	chartRequested *chart.Chart, chartPath string, settings *cli.EnvSettings, logger logr.Logger) (*chart.Chart, error) {
+
+	return resolveChartDependencies(ctx, c, &configv1beta1.ClusterSummary{}, requestedChart, chartRequested, chartPath,
+		settings, &registryClientOptions{}, "", logger)
+}
+
+var (
+	ResolveImageDigests = resolveImageDigests
+)
+
+// WithResolvedImageDigests returns a context pinning images to the digests in resolved.
+// Registries are never queried.
+func WithResolvedImageDigests(ctx context.Context, resolved map[string]string) context.Context {
+	return context.WithValue(ctx, imageDigestsContextKey{}, &imageDigestResolver{resolved: resolved})
+}
+
+var (
+	GetChartCacheKey = getChartCacheKey
+)
+
+// RunHelmTests runs the tests of the requestedChart release, stored in the default helm storage
+func RunHelmTests(requestedChart *configv1beta1.HelmChart, kubeconfig string, logger logr.Logger) error {
+	return runHelmTests(requestedChart, kubeconfig, &registryClientOptions{}, logger)
+}
+
+// GetHelmActionConfig returns the helm action configuration for releases in namespace, stored in
+// the default helm storage
+func GetHelmActionConfig(namespace, kubeconfig string) (*action.Configuration, error) {
+	return actionConfigInit(namespace, kubeconfig, &registryClientOptions{}, false)
+}
+
+func AddToHelmChartCache(key, chartPath string) error {
+	return helmChartCache.add(key, chartPath, logr.Discard())
+}
+
+func GetFromHelmChartCache(key string) (chartPath, tmpDir string, found bool) {
+	return helmChartCache.get(key)
+}
+
+var (
+	FilterByNodePlatforms = filterByNodePlatforms
+)
+
+var (
+	GetMirroredURL   = getMirroredURL
+	GetMirroredChart = getMirroredChart
+)
+
+var (
+	UpdateRolloutProgress = updateRolloutProgress
+)
+
+var (
+	WithDeploymentRevision    = withDeploymentRevision
+	CheckDeploymentSuperseded = checkDeploymentSuperseded
+)
+
+var (
+	GetPruneAllowlists    = getPruneAllowlists
+	GetPruneAllowlistKey  = getPruneAllowlistKey
+	WithPruneAllowlist    = withPruneAllowlist
+	MarkPruneProtected    = markPruneProtected
+	UndeployStaleResource = undeployStaleResource
+)
+
+var (
+	WithConflicts       = withConflicts
+	TrackConflict       = trackConflict
+	GetResourceConflict = getResourceConflict
+	RecordConflicts     = recordConflicts
+)
+
+var (
+	ValidateWasmPlugins        = validateWasmPlugins
+	WithWasmPlugins            = withWasmPlugins
+	RunWasmPlugins             = runWasmPlugins
+	GetWasmPluginsPostRenderer = getWasmPluginsPostRenderer
+	GetWasmPluginsReferences   = getWasmPluginsReferences
+)
+
+var (
+	RegisterVirtualClusters   = registerVirtualClusters
+	UnregisterVirtualClusters = unregisterVirtualClusters
+	GetVirtualClusterName     = getVirtualClusterName
+)
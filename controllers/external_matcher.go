@@ -0,0 +1,154 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// externalMatcherURL is the endpoint deciding whether a cluster, selected by a profile,
+	// is a match. Empty disables the external matcher.
+	externalMatcherURL string
+)
+
+// SetExternalMatcher configures the endpoint consulted for every cluster matching a
+// ClusterProfile/Profile. The endpoint receives a POST request with the cluster metadata
+// and the profile, and decides whether the cluster is a match.
+func SetExternalMatcher(url string) {
+	externalMatcherURL = url
+}
+
+// externalMatchRequest is the payload of the request sent to the external matcher
+type externalMatchRequest struct {
+	ProfileKind      string              `json:"profileKind"`
+	ProfileNamespace string              `json:"profileNamespace,omitempty"`
+	ProfileName      string              `json:"profileName"`
+	ProfileSpec      *configv1beta1.Spec `json:"profileSpec"`
+	ClusterNamespace string              `json:"clusterNamespace"`
+	ClusterName      string              `json:"clusterName"`
+	ClusterType      string              `json:"clusterType"`
+	ClusterLabels    map[string]string   `json:"clusterLabels,omitempty"`
+}
+
+// externalMatchResponse is the payload expected back from the external matcher
+type externalMatchResponse struct {
+	Match bool `json:"match"`
+}
+
+// filterByExternalMatcher returns the subset of clusters the external matcher considers a match.
+// When no external matcher is configured, clusters is returned unchanged.
+// Any failure reaching the external matcher is returned, so that the matching clusters are not
+// changed based on a partial answer.
+func filterByExternalMatcher(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	clusters []corev1.ObjectReference, logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	if externalMatcherURL == "" {
+		return clusters, nil
+	}
+
+	matching := make([]corev1.ObjectReference, 0, len(clusters))
+	for i := range clusters {
+		match, err := isExternalMatch(ctx, c, profileScope, &clusters[i])
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to consult external matcher for cluster %s/%s: %v",
+				clusters[i].Namespace, clusters[i].Name, err))
+			return nil, err
+		}
+		if match {
+			matching = append(matching, clusters[i])
+		} else {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("cluster %s/%s rejected by external matcher",
+				clusters[i].Namespace, clusters[i].Name))
+		}
+	}
+
+	return matching, nil
+}
+
+func isExternalMatch(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	clusterRef *corev1.ObjectReference) (bool, error) {
+
+	clusterType := clusterproxy.GetClusterType(clusterRef)
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterRef.Namespace, clusterRef.Name, clusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	request := &externalMatchRequest{
+		ProfileKind:      profileScope.GetKind(),
+		ProfileNamespace: profileScope.Profile.GetNamespace(),
+		ProfileName:      profileScope.Profile.GetName(),
+		ProfileSpec:      profileScope.GetSpec(),
+		ClusterNamespace: clusterRef.Namespace,
+		ClusterName:      clusterRef.Name,
+		ClusterType:      string(clusterType),
+		ClusterLabels:    cluster.GetLabels(),
+	}
+
+	return callExternalMatcher(ctx, externalMatcherURL, request)
+}
+
+func callExternalMatcher(ctx context.Context, matcherURL string, request *externalMatchRequest) (bool, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, matcherURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	const timeout = 10 * time.Second
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return false, fmt.Errorf("external matcher request failed with status code %d", resp.StatusCode)
+	}
+
+	response := &externalMatchResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return false, fmt.Errorf("failed to decode external matcher response: %w", err)
+	}
+
+	return response.Match, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("External matcher", func() {
+	const approvedLabel = "approved"
+
+	var clusterProfile *configv1beta1.ClusterProfile
+	var approved *libsveltosv1beta1.SveltosCluster
+	var rejected *libsveltosv1beta1.SveltosCluster
+
+	BeforeEach(func() {
+		clusterProfile = &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+
+		approved = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{approvedLabel: "true"},
+			},
+		}
+		rejected = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetExternalMatcher("")
+	})
+
+	getClusterRef := func(cluster *libsveltosv1beta1.SveltosCluster) corev1.ObjectReference {
+		return corev1.ObjectReference{
+			Namespace:  cluster.Namespace,
+			Name:       cluster.Name,
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+	}
+
+	getProfileScope := func() (client.Client, *scope.ProfileScope) {
+		initObjects := []client.Object{clusterProfile, approved, rejected}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: textlogger.NewLogger(textlogger.NewConfig()), Profile: clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		return c, profileScope
+	}
+
+	It("filterByExternalMatcher keeps only clusters accepted by the external matcher", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			payload := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			Expect(payload["profileName"]).To(Equal(clusterProfile.Name))
+			Expect(payload["profileKind"]).To(Equal(configv1beta1.ClusterProfileKind))
+
+			match := false
+			if labels, ok := payload["clusterLabels"].(map[string]interface{}); ok {
+				match = labels[approvedLabel] == "true"
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]bool{"match": match})).To(Succeed())
+		}))
+		defer server.Close()
+
+		controllers.SetExternalMatcher(server.URL)
+
+		c, profileScope := getProfileScope()
+		clusters := []corev1.ObjectReference{getClusterRef(approved), getClusterRef(rejected)}
+		matching, err := controllers.FilterByExternalMatcher(context.TODO(), c, profileScope,
+			clusters, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(Equal([]corev1.ObjectReference{getClusterRef(approved)}))
+	})
+
+	It("filterByExternalMatcher returns an error when the external matcher fails", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		controllers.SetExternalMatcher(server.URL)
+
+		c, profileScope := getProfileScope()
+		clusters := []corev1.ObjectReference{getClusterRef(approved)}
+		_, err := controllers.FilterByExternalMatcher(context.TODO(), c, profileScope,
+			clusters, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("filterByExternalMatcher returns all clusters when no external matcher is configured", func() {
+		c, profileScope := getProfileScope()
+		clusters := []corev1.ObjectReference{getClusterRef(approved), getClusterRef(rejected)}
+		matching, err := controllers.FilterByExternalMatcher(context.TODO(), c, profileScope,
+			clusters, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(Equal(clusters))
+	})
+})
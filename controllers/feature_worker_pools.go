@@ -0,0 +1,292 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// FeatureWorkerPool configures the deployer workers available to a feature
+type FeatureWorkerPool struct {
+	// Workers is the maximum number of deployer workers processing requests for the feature
+	// at the same time
+	Workers int
+
+	// Timeout, if set, is the maximum time a request for the feature can take.
+	// Once expired, the context passed to the handler is canceled.
+	Timeout time.Duration
+}
+
+// ParseFeatureWorkerPools parses a comma separated list of <feature>=<workers>[:<timeout>]
+// (for instance Helm=5:10m,Kustomize=10,Resources=20)
+func ParseFeatureWorkerPools(value string) (map[configv1beta1.FeatureID]FeatureWorkerPool, error) {
+	pools := make(map[configv1beta1.FeatureID]FeatureWorkerPool)
+	if strings.TrimSpace(value) == "" {
+		return pools, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		featureAndPool := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(featureAndPool) != 2 {
+			return nil, fmt.Errorf("malformed worker pool %q: expected <feature>=<workers>[:<timeout>]", entry)
+		}
+
+		featureID := configv1beta1.FeatureID(featureAndPool[0])
+		switch featureID {
+		case configv1beta1.FeatureHelm, configv1beta1.FeatureKustomize, configv1beta1.FeatureResources:
+		default:
+			return nil, fmt.Errorf("unknown feature %q in worker pool %q", featureID, entry)
+		}
+		if _, ok := pools[featureID]; ok {
+			return nil, fmt.Errorf("worker pool for feature %s defined more than once", featureID)
+		}
+
+		workersAndTimeout := strings.SplitN(featureAndPool[1], ":", 2)
+		workers, err := strconv.Atoi(workersAndTimeout[0])
+		if err != nil || workers <= 0 {
+			return nil, fmt.Errorf("invalid number of workers %q in worker pool %q", workersAndTimeout[0], entry)
+		}
+
+		pool := FeatureWorkerPool{Workers: workers}
+		if len(workersAndTimeout) == 2 {
+			pool.Timeout, err = time.ParseDuration(workersAndTimeout[1])
+			if err != nil || pool.Timeout <= 0 {
+				return nil, fmt.Errorf("invalid timeout %q in worker pool %q", workersAndTimeout[1], entry)
+			}
+		}
+
+		pools[featureID] = pool
+	}
+
+	return pools, nil
+}
+
+// featurePoolDeployer is a deployer.DeployerInterface limiting the number of deployer workers
+// processing requests for a feature at the same time, so slow requests for a feature (for instance
+// Helm installs waiting for resources to be ready) do not starve requests for other features.
+// With tenant fairness, it also limits the number of workers processing requests for a tenant
+// to its share, so a tenant with many or large Profiles cannot monopolize the workers.
+// All requests go through the embedded deployer queue. A request taken by a worker while its
+// feature or tenant is at its limit is not processed: it is submitted again to the embedded
+// deployer which, since the request is in progress, queues it back once the worker is done with it.
+type featurePoolDeployer struct {
+	deployer.DeployerInterface
+	pools map[string]FeatureWorkerPool
+	// fairness, if set, configures the share of the deployer workers of each tenant
+	fairness *TenantFairness
+
+	mu *sync.Mutex
+	// running contains, per feature, the number of requests being processed
+	running map[string]int
+	// tenantRunning contains, per tenant, the number of requests being processed
+	tenantRunning map[string]int
+	// outstanding contains, per request key, the tenant of the requests submitted and not
+	// processed yet
+	outstanding map[string]string
+	// pending contains, per tenant, the number of outstanding requests
+	pending map[string]int
+	// deferred contains the keys of the requests queued back instead of being processed
+	deferred map[string]bool
+}
+
+// NewFeaturePoolDeployer returns a deployer.DeployerInterface limiting, for the features in pools,
+// the number of workers of d processing requests for the feature at the same time.
+// If fairness is not nil, the number of workers of d processing requests for a namespace is
+// limited as well.
+func NewFeaturePoolDeployer(d deployer.DeployerInterface, pools map[configv1beta1.FeatureID]FeatureWorkerPool,
+	fairness *TenantFairness, logger logr.Logger) deployer.DeployerInterface {
+
+	p := &featurePoolDeployer{
+		DeployerInterface: d,
+		pools:             make(map[string]FeatureWorkerPool),
+		fairness:          fairness,
+		mu:                &sync.Mutex{},
+		running:           make(map[string]int),
+		tenantRunning:     make(map[string]int),
+		outstanding:       make(map[string]string),
+		pending:           make(map[string]int),
+		deferred:          make(map[string]bool),
+	}
+
+	for featureID := range pools {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("feature %s: up to %d workers (timeout %s)",
+			featureID, pools[featureID].Workers, pools[featureID].Timeout))
+		p.pools[string(featureID)] = pools[featureID]
+	}
+
+	if fairness != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("tenant fairness: %d workers shared across namespaces",
+			fairness.Workers))
+	}
+
+	return p
+}
+
+func (p *featurePoolDeployer) Deploy(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool, f deployer.RequestHandler, m deployer.MetricHandler,
+	o deployer.Options) error {
+
+	pool, ok := p.pools[featureID]
+	if !ok && p.fairness == nil {
+		return p.DeployerInterface.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, clusterType,
+			cleanup, f, m, o)
+	}
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+	p.addOutstanding(key, clusterNamespace)
+
+	var handler deployer.RequestHandler
+	metric := func(elapsed time.Duration, clusterNamespace, clusterName, featureID string,
+		clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) {
+
+		// Requests queued back were not processed
+		if !p.takeDeferred(key) && m != nil {
+			m(elapsed, clusterNamespace, clusterName, featureID, clusterType, logger)
+		}
+	}
+
+	handler = func(ctx context.Context, c client.Client, clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType, o deployer.Options, logger logr.Logger) error {
+
+		if !p.acquire(key, featureID, clusterNamespace) {
+			logger.V(logs.LogDebug).Info("all workers for feature or namespace are busy. Queuing request back")
+			p.setDeferred(key)
+			return p.DeployerInterface.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, clusterType,
+				cleanup, handler, metric, o)
+		}
+		defer p.release(featureID, clusterNamespace)
+
+		if pool.Timeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, pool.Timeout)
+			defer cancel()
+		}
+
+		err := f(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+		if err != nil && pool.Timeout != 0 && ctx.Err() != nil {
+			err = fmt.Errorf("request did not complete within %s: %w", pool.Timeout, err)
+		}
+		return err
+	}
+
+	err := p.DeployerInterface.Deploy(ctx, clusterNamespace, clusterName, applicant, featureID, clusterType,
+		cleanup, handler, metric, o)
+	if err != nil {
+		p.mu.Lock()
+		p.removeOutstanding(key)
+		p.mu.Unlock()
+	}
+	return err
+}
+
+// acquire returns true, and counts the request with key as running, if fewer requests than allowed
+// are running for featureID and, with tenant fairness, for tenant
+func (p *featurePoolDeployer) acquire(key, featureID, tenant string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pool, ok := p.pools[featureID]; ok && p.running[featureID] >= pool.Workers {
+		return false
+	}
+	if p.fairness != nil && p.tenantRunning[tenant] >= p.fairness.getShare(tenant, p.getActiveTenants()) {
+		return false
+	}
+
+	p.running[featureID]++
+	p.tenantRunning[tenant]++
+	p.removeOutstanding(key)
+	return true
+}
+
+func (p *featurePoolDeployer) release(featureID, tenant string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running[featureID]--
+	p.tenantRunning[tenant]--
+	if p.tenantRunning[tenant] == 0 {
+		delete(p.tenantRunning, tenant)
+	}
+}
+
+// getActiveTenants returns the tenants with outstanding or running requests.
+// Must be called with mu held.
+func (p *featurePoolDeployer) getActiveTenants() map[string]bool {
+	active := make(map[string]bool)
+	for tenant := range p.pending {
+		active[tenant] = true
+	}
+	for tenant := range p.tenantRunning {
+		active[tenant] = true
+	}
+	return active
+}
+
+func (p *featurePoolDeployer) addOutstanding(key, tenant string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.outstanding[key]; ok {
+		return
+	}
+	p.outstanding[key] = tenant
+	p.pending[tenant]++
+}
+
+// removeOutstanding marks the request with key as processed. Must be called with mu held.
+func (p *featurePoolDeployer) removeOutstanding(key string) {
+	tenant, ok := p.outstanding[key]
+	if !ok {
+		return
+	}
+
+	delete(p.outstanding, key)
+	p.pending[tenant]--
+	if p.pending[tenant] == 0 {
+		delete(p.pending, tenant)
+	}
+}
+
+func (p *featurePoolDeployer) setDeferred(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.deferred[key] = true
+}
+
+// takeDeferred returns true if the request with key was queued back instead of being processed
+func (p *featurePoolDeployer) takeDeferred(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deferred := p.deferred[key]
+	delete(p.deferred, key)
+	return deferred
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	fakedeployer "github.com/projectsveltos/libsveltos/lib/deployer/fake"
+)
+
+// recordingDeployer records the requests submitted to it, so that their handlers can be
+// invoked as deployer workers do
+type recordingDeployer struct {
+	deployer.DeployerInterface
+
+	mu          sync.Mutex
+	handlers    map[string]deployer.RequestHandler
+	metrics     map[string]deployer.MetricHandler
+	submissions map[string]int
+}
+
+func (r *recordingDeployer) Deploy(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool, f deployer.RequestHandler, m deployer.MetricHandler,
+	o deployer.Options) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+	r.handlers[key] = f
+	r.metrics[key] = m
+	r.submissions[key]++
+	return nil
+}
+
+func (r *recordingDeployer) getSubmissions(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.submissions[key]
+}
+
+var _ = Describe("Feature worker pools", func() {
+	var clusterNamespace, applicant string
+	var recorder *recordingDeployer
+
+	BeforeEach(func() {
+		clusterNamespace = randomString()
+		applicant = randomString()
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		recorder = &recordingDeployer{
+			DeployerInterface: fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c),
+			handlers:          make(map[string]deployer.RequestHandler),
+			metrics:           make(map[string]deployer.MetricHandler),
+			submissions:       make(map[string]int),
+		}
+	})
+
+	getDeployer := func(pools map[configv1beta1.FeatureID]controllers.FeatureWorkerPool,
+		fairness *controllers.TenantFairness) deployer.DeployerInterface {
+
+		return controllers.NewFeaturePoolDeployer(recorder, pools, fairness, textlogger.NewLogger(textlogger.NewConfig()))
+	}
+
+	// processInNamespace invokes, as a deployer worker does, the handler of the request for
+	// cluster clusterNamespace/clusterName
+	processInNamespace := func(clusterNamespace, clusterName, featureID string) error {
+		key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false)
+		recorder.mu.Lock()
+		handler := recorder.handlers[key]
+		recorder.mu.Unlock()
+		Expect(handler).ToNot(BeNil())
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		err := handler(context.TODO(), nil, clusterNamespace, clusterName, applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, deployer.Options{}, logger)
+
+		recorder.mu.Lock()
+		metric := recorder.metrics[key]
+		recorder.mu.Unlock()
+		if metric != nil {
+			metric(time.Second, clusterNamespace, clusterName, featureID, libsveltosv1beta1.ClusterTypeCapi, logger)
+		}
+		return err
+	}
+
+	process := func(clusterName, featureID string) error {
+		return processInNamespace(clusterNamespace, clusterName, featureID)
+	}
+
+	It("ParseFeatureWorkerPools parses worker pools", func() {
+		pools, err := controllers.ParseFeatureWorkerPools("Helm=5:10m, Kustomize=10")
+		Expect(err).To(BeNil())
+		Expect(pools).To(HaveLen(2))
+		Expect(pools[configv1beta1.FeatureHelm]).To(Equal(
+			controllers.FeatureWorkerPool{Workers: 5, Timeout: 10 * time.Minute}))
+		Expect(pools[configv1beta1.FeatureKustomize]).To(Equal(controllers.FeatureWorkerPool{Workers: 10}))
+
+		pools, err = controllers.ParseFeatureWorkerPools("")
+		Expect(err).To(BeNil())
+		Expect(pools).To(BeEmpty())
+
+		for _, value := range []string{"Helm", "Unknown=1", "Helm=0", "Helm=a", "Helm=1:x", "Helm=1,Helm=2"} {
+			_, err = controllers.ParseFeatureWorkerPools(value)
+			Expect(err).ToNot(BeNil(), value)
+		}
+	})
+
+	It("requests for a feature at its worker limit are queued back", func() {
+		d := getDeployer(map[configv1beta1.FeatureID]controllers.FeatureWorkerPool{
+			configv1beta1.FeatureHelm: {Workers: 1},
+		}, nil)
+
+		featureID := string(configv1beta1.FeatureHelm)
+		release := make(chan struct{})
+		var mu sync.Mutex
+		processed := make(map[string]int)
+		handler := func(_ context.Context, _ client.Client, _, clusterName, _, _ string,
+			_ libsveltosv1beta1.ClusterType, _ deployer.Options, _ logr.Logger) error {
+
+			mu.Lock()
+			processed[clusterName]++
+			mu.Unlock()
+			if clusterName == "first" {
+				<-release
+			}
+			return nil
+		}
+		getProcessed := func(clusterName string) int {
+			mu.Lock()
+			defer mu.Unlock()
+			return processed[clusterName]
+		}
+
+		metrics := 0
+		metricHandler := func(_ time.Duration, _, _, _ string, _ libsveltosv1beta1.ClusterType, _ logr.Logger) {
+			mu.Lock()
+			defer mu.Unlock()
+			metrics++
+		}
+
+		for _, clusterName := range []string{"first", "second"} {
+			Expect(d.Deploy(context.TODO(), clusterNamespace, clusterName, applicant, featureID,
+				libsveltosv1beta1.ClusterTypeCapi, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+		}
+
+		done := make(chan error)
+		go func() {
+			done <- process("first", featureID)
+		}()
+		Eventually(func() int {
+			return getProcessed("first")
+		}, timeout, pollingInterval).Should(Equal(1))
+
+		// The only Helm worker is busy: second request is submitted again instead of being processed
+		secondKey := deployer.GetKey(clusterNamespace, "second", applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(process("second", featureID)).To(Succeed())
+		Expect(getProcessed("second")).To(Equal(0))
+		Expect(recorder.getSubmissions(secondKey)).To(Equal(2))
+		mu.Lock()
+		Expect(metrics).To(Equal(0))
+		mu.Unlock()
+
+		// Requests for features without a limit are processed
+		resourcesID := string(configv1beta1.FeatureResources)
+		Expect(d.Deploy(context.TODO(), clusterNamespace, "third", applicant, resourcesID,
+			libsveltosv1beta1.ClusterTypeCapi, false, handler, metricHandler, deployer.Options{})).To(Succeed())
+		Expect(process("third", resourcesID)).To(Succeed())
+		Expect(getProcessed("third")).To(Equal(1))
+
+		close(release)
+		Eventually(done, timeout, pollingInterval).Should(Receive(BeNil()))
+
+		Expect(process("second", featureID)).To(Succeed())
+		Expect(getProcessed("second")).To(Equal(1))
+		Expect(recorder.getSubmissions(secondKey)).To(Equal(2))
+		mu.Lock()
+		Expect(metrics).To(Equal(3))
+		mu.Unlock()
+	})
+
+	It("requests exceeding the feature timeout fail", func() {
+		d := getDeployer(map[configv1beta1.FeatureID]controllers.FeatureWorkerPool{
+			configv1beta1.FeatureKustomize: {Workers: 1, Timeout: time.Second},
+		}, nil)
+
+		featureID := string(configv1beta1.FeatureKustomize)
+		handler := func(ctx context.Context, _ client.Client, _, _, _, _ string, _ libsveltosv1beta1.ClusterType,
+			_ deployer.Options, _ logr.Logger) error {
+
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		Expect(d.Deploy(context.TODO(), clusterNamespace, "first", applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false, handler, nil, deployer.Options{})).To(Succeed())
+
+		err := process("first", featureID)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("did not complete within %s", time.Second)))
+	})
+
+	It("with tenant fairness, requests for a namespace beyond its share of workers are queued back", func() {
+		d := getDeployer(nil, &controllers.TenantFairness{Workers: 2})
+
+		otherNamespace := randomString()
+		featureID := string(configv1beta1.FeatureResources)
+		release := make(chan struct{})
+		var mu sync.Mutex
+		processed := make(map[string]int)
+		handler := func(_ context.Context, _ client.Client, _, clusterName, _, _ string,
+			_ libsveltosv1beta1.ClusterType, _ deployer.Options, _ logr.Logger) error {
+
+			mu.Lock()
+			processed[clusterName]++
+			mu.Unlock()
+			<-release
+			return nil
+		}
+		getProcessed := func(clusterName string) int {
+			mu.Lock()
+			defer mu.Unlock()
+			return processed[clusterName]
+		}
+
+		for _, clusterName := range []string{"first", "second"} {
+			Expect(d.Deploy(context.TODO(), clusterNamespace, clusterName, applicant, featureID,
+				libsveltosv1beta1.ClusterTypeCapi, false, handler, nil, deployer.Options{})).To(Succeed())
+		}
+		Expect(d.Deploy(context.TODO(), otherNamespace, "third", applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false, handler, nil, deployer.Options{})).To(Succeed())
+
+		done := make(chan error, 2)
+		go func() {
+			done <- process("first", featureID)
+		}()
+		Eventually(func() int {
+			return getProcessed("first")
+		}, timeout, pollingInterval).Should(Equal(1))
+
+		// Two namespaces have requests: each one can use one of the two workers
+		Expect(process("second", featureID)).To(Succeed())
+		Expect(getProcessed("second")).To(Equal(0))
+		secondKey := deployer.GetKey(clusterNamespace, "second", applicant, featureID,
+			libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(recorder.getSubmissions(secondKey)).To(Equal(2))
+
+		go func() {
+			done <- processInNamespace(otherNamespace, "third", featureID)
+		}()
+		Eventually(func() int {
+			return getProcessed("third")
+		}, timeout, pollingInterval).Should(Equal(1))
+
+		close(release)
+		Eventually(done, timeout, pollingInterval).Should(Receive(BeNil()))
+		Eventually(done, timeout, pollingInterval).Should(Receive(BeNil()))
+
+		Expect(process("second", featureID)).To(Succeed())
+		Expect(getProcessed("second")).To(Equal(1))
+	})
+})
@@ -0,0 +1,258 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// federationKubeconfigKey is the key, in the federation Secret, containing the kubeconfig
+	// of the peer management cluster
+	federationKubeconfigKey = "kubeconfig"
+)
+
+type profileKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// PublishToFederationPeer periodically publishes, to the peer management cluster whose kubeconfig
+// is stored in the secretName Secret (projectsveltos namespace), one FederatedProfileStatus per
+// ClusterProfile/Profile reporting matching clusters and rollout status in each of them.
+// FederatedProfileStatuses previously published for profiles which do not exist anymore are
+// removed from the peer. managementCluster identifies this management cluster in the peer.
+func PublishToFederationPeer(ctx context.Context, c client.Client, managementCluster, secretName string,
+	interval time.Duration, logger logr.Logger) {
+
+	for {
+		time.Sleep(interval)
+
+		logger.V(logs.LogVerbose).Info("publishing to federation peer")
+
+		peerClient, err := getFederationPeerClient(ctx, c, secretName)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get federation peer client: %v", err))
+			continue
+		}
+
+		if err := publishToFederationPeer(ctx, c, peerClient, managementCluster, time.Now()); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to publish to federation peer: %v", err))
+		}
+	}
+}
+
+func getFederationPeerClient(ctx context.Context, c client.Client, secretName string) (client.Client, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: secretName}, secret); err != nil {
+		return nil, err
+	}
+
+	kubeconfig, ok := secret.Data[federationKubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s does not contain key %s", projectsveltos, secretName,
+			federationKubeconfigKey)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.New(config, client.Options{Scheme: c.Scheme()})
+}
+
+// getProfileClusterKey returns a key identifying a profile and a cluster
+func getProfileClusterKey(key profileKey, clusterType libsveltosv1beta1.ClusterType,
+	clusterNamespace, clusterName string) string {
+
+	return fmt.Sprintf("%s/%s/%s/%s:%s/%s", key.kind, key.namespace, key.name, clusterType,
+		clusterNamespace, clusterName)
+}
+
+// getFederatedProfileStatusName returns the name of the FederatedProfileStatus, in the peer, for a profile
+func getFederatedProfileStatusName(managementCluster string, key profileKey) string {
+	if key.namespace == "" {
+		return fmt.Sprintf("%s.%s.%s", managementCluster, strings.ToLower(key.kind), key.name)
+	}
+	return fmt.Sprintf("%s.%s.%s.%s", managementCluster, strings.ToLower(key.kind), key.namespace, key.name)
+}
+
+// getFederatedProfileStatuses returns the FederatedProfileStatuses describing all ClusterProfiles/Profiles
+// in the management cluster
+func getFederatedProfileStatuses(ctx context.Context, c client.Client, managementCluster string,
+	now time.Time) ([]*configv1beta1.FederatedProfileStatus, error) {
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaries); err != nil {
+		return nil, err
+	}
+
+	// key: profile and cluster (see getProfileClusterKey); value: ClusterSummary
+	clusterSummaryMap := make(map[string]*configv1beta1.ClusterSummary)
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(cs)
+		if err != nil || profileOwnerRef == nil {
+			continue
+		}
+		key := profileKey{kind: profileOwnerRef.Kind, name: profileOwnerRef.Name}
+		if profileOwnerRef.Kind == configv1beta1.ProfileKind {
+			// ClusterSummaries created by a Profile are in the Profile namespace
+			key.namespace = cs.Namespace
+		}
+		clusterSummaryMap[getProfileClusterKey(key, cs.Spec.ClusterType, cs.Spec.ClusterNamespace,
+			cs.Spec.ClusterName)] = cs
+	}
+
+	result := make([]*configv1beta1.FederatedProfileStatus, 0)
+
+	clusterProfiles := &configv1beta1.ClusterProfileList{}
+	if err := c.List(ctx, clusterProfiles); err != nil {
+		return nil, err
+	}
+	for i := range clusterProfiles.Items {
+		cp := &clusterProfiles.Items[i]
+		result = append(result, getFederatedProfileStatus(managementCluster,
+			profileKey{kind: configv1beta1.ClusterProfileKind, name: cp.Name},
+			cp.Status.MatchingClusterRefs, clusterSummaryMap, now))
+	}
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := c.List(ctx, profiles); err != nil {
+		return nil, err
+	}
+	for i := range profiles.Items {
+		p := &profiles.Items[i]
+		result = append(result, getFederatedProfileStatus(managementCluster,
+			profileKey{kind: configv1beta1.ProfileKind, namespace: p.Namespace, name: p.Name},
+			p.Status.MatchingClusterRefs, clusterSummaryMap, now))
+	}
+
+	return result, nil
+}
+
+func getFederatedProfileStatus(managementCluster string, key profileKey, matchingClusters []corev1.ObjectReference,
+	clusterSummaryMap map[string]*configv1beta1.ClusterSummary, now time.Time) *configv1beta1.FederatedProfileStatus {
+
+	clusters := make([]configv1beta1.FederatedClusterStatus, len(matchingClusters))
+	for i := range matchingClusters {
+		cluster := &matchingClusters[i]
+		clusters[i].Cluster = *cluster
+
+		cs, ok := clusterSummaryMap[getProfileClusterKey(key, clusterproxy.GetClusterType(cluster),
+			cluster.Namespace, cluster.Name)]
+		if !ok {
+			continue
+		}
+
+		clusters[i].LastSuccessfulSyncTime = cs.Status.LastSuccessfulSyncTime
+		for j := range cs.Status.FeatureSummaries {
+			fs := &cs.Status.FeatureSummaries[j]
+			clusters[i].FeatureSummaries = append(clusters[i].FeatureSummaries,
+				configv1beta1.FederatedFeatureStatus{
+					FeatureID:      fs.FeatureID,
+					Status:         fs.Status,
+					FailureMessage: fs.FailureMessage,
+				})
+		}
+	}
+
+	return &configv1beta1.FederatedProfileStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   getFederatedProfileStatusName(managementCluster, key),
+			Labels: map[string]string{configv1beta1.FederationSourceLabel: managementCluster},
+		},
+		Spec: configv1beta1.FederatedProfileStatusSpec{
+			ManagementCluster: managementCluster,
+			ProfileKind:       key.kind,
+			ProfileNamespace:  key.namespace,
+			ProfileName:       key.name,
+			Clusters:          clusters,
+			LastPublishedTime: metav1.Time{Time: now},
+		},
+	}
+}
+
+// publishToFederationPeer creates/updates in the peer the FederatedProfileStatuses for all profiles
+// and removes stale ones
+func publishToFederationPeer(ctx context.Context, c, peerClient client.Client, managementCluster string,
+	now time.Time) error {
+
+	statuses, err := getFederatedProfileStatuses(ctx, c, managementCluster, now)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]bool, len(statuses))
+	for i := range statuses {
+		current[statuses[i].Name] = true
+		if err := updateFederatedProfileStatus(ctx, peerClient, statuses[i]); err != nil {
+			return err
+		}
+	}
+
+	published := &configv1beta1.FederatedProfileStatusList{}
+	if err := peerClient.List(ctx, published,
+		client.MatchingLabels{configv1beta1.FederationSourceLabel: managementCluster}); err != nil {
+		return err
+	}
+	for i := range published.Items {
+		if current[published.Items[i].Name] {
+			continue
+		}
+		if err := peerClient.Delete(ctx, &published.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateFederatedProfileStatus(ctx context.Context, peerClient client.Client,
+	status *configv1beta1.FederatedProfileStatus) error {
+
+	currentStatus := &configv1beta1.FederatedProfileStatus{}
+	err := peerClient.Get(ctx, types.NamespacedName{Name: status.Name}, currentStatus)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return peerClient.Create(ctx, status)
+		}
+		return err
+	}
+
+	currentStatus.Labels = status.Labels
+	currentStatus.Spec = status.Spec
+	return peerClient.Update(ctx, currentStatus)
+}
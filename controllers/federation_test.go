@@ -0,0 +1,134 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Federation", func() {
+	It("publishToFederationPeer publishes profiles status and removes stale ones", func() {
+		const managementCluster = "eu"
+
+		provisionedCluster := corev1.ObjectReference{Namespace: randomString(), Name: randomString(),
+			Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String()}
+		pendingCluster := corev1.ObjectReference{Namespace: randomString(), Name: randomString(),
+			Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String()}
+
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{provisionedCluster, pendingCluster},
+			},
+		}
+
+		profile := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+		}
+
+		lastSync := metav1.NewTime(time.Now().Truncate(time.Second))
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: provisionedCluster.Namespace,
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ClusterProfileKind,
+						Name: clusterProfile.Name, UID: "1"},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: provisionedCluster.Namespace,
+				ClusterName:      provisionedCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+				LastSuccessfulSyncTime: &lastSync,
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile, profile, clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		// Stale status published by this management cluster and status published by another one
+		staleStatus := &configv1beta1.FederatedProfileStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   managementCluster + ".clusterprofile." + randomString(),
+				Labels: map[string]string{configv1beta1.FederationSourceLabel: managementCluster},
+			},
+		}
+		otherStatus := &configv1beta1.FederatedProfileStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "us.clusterprofile." + randomString(),
+				Labels: map[string]string{configv1beta1.FederationSourceLabel: "us"},
+			},
+		}
+		peerClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(staleStatus, otherStatus).Build()
+
+		Expect(controllers.PublishToFederationPeerOnce(context.TODO(), c, peerClient, managementCluster,
+			time.Now())).To(Succeed())
+
+		statuses := &configv1beta1.FederatedProfileStatusList{}
+		Expect(peerClient.List(context.TODO(), statuses)).To(Succeed())
+		Expect(statuses.Items).To(HaveLen(3))
+
+		current := &configv1beta1.FederatedProfileStatus{}
+		Expect(peerClient.Get(context.TODO(),
+			types.NamespacedName{Name: managementCluster + ".clusterprofile." + clusterProfile.Name},
+			current)).To(Succeed())
+		Expect(current.Spec.ManagementCluster).To(Equal(managementCluster))
+		Expect(current.Spec.ProfileKind).To(Equal(configv1beta1.ClusterProfileKind))
+		Expect(current.Spec.Clusters).To(HaveLen(2))
+		Expect(current.Spec.Clusters[0].Cluster).To(Equal(provisionedCluster))
+		Expect(current.Spec.Clusters[0].FeatureSummaries).To(HaveLen(1))
+		Expect(current.Spec.Clusters[0].FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusProvisioned))
+		Expect(current.Spec.Clusters[0].LastSuccessfulSyncTime.Time.Equal(lastSync.Time)).To(BeTrue())
+		Expect(current.Spec.Clusters[1].Cluster).To(Equal(pendingCluster))
+		Expect(current.Spec.Clusters[1].FeatureSummaries).To(BeEmpty())
+
+		Expect(peerClient.Get(context.TODO(),
+			types.NamespacedName{Name: managementCluster + ".profile." + profile.Namespace + "." + profile.Name},
+			current)).To(Succeed())
+		Expect(current.Spec.ProfileNamespace).To(Equal(profile.Namespace))
+
+		Expect(peerClient.Get(context.TODO(), types.NamespacedName{Name: otherStatus.Name}, current)).To(Succeed())
+		err := peerClient.Get(context.TODO(), types.NamespacedName{Name: staleStatus.Name}, current)
+		Expect(err).ToNot(BeNil())
+
+		// Publishing again updates existing statuses
+		Expect(controllers.PublishToFederationPeerOnce(context.TODO(), c, peerClient, managementCluster,
+			time.Now())).To(Succeed())
+		Expect(peerClient.List(context.TODO(), statuses)).To(Succeed())
+		Expect(statuses.Items).To(HaveLen(3))
+	})
+})
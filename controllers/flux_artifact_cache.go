@@ -0,0 +1,209 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fluxcd/pkg/http/fetch"
+	"github.com/fluxcd/pkg/tar"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/go-logr/logr"
+	"github.com/opencontainers/go-digest"
+	_ "github.com/opencontainers/go-digest/blake3"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// maximum number of Flux artifacts kept in the cache
+	maxCachedArtifacts = 50
+)
+
+var (
+	fluxArtifactCache = newArtifactCache(filepath.Join(os.TempDir(), "sveltos-artifacts"), maxCachedArtifacts)
+)
+
+// artifactCache keeps the tarballs of Flux artifacts, keyed by digest, so that an artifact referenced by
+// many profiles is downloaded from source-controller only once per revision.
+// Cached tarballs are verified against their digest every time they are used.
+type artifactCache struct {
+	dir        string
+	maxEntries int
+
+	mu sync.Mutex
+	// key: artifact digest; value: last time artifact was used
+	lastUsed map[string]time.Time
+	// key: artifact digest; serializes download and verification of a given artifact
+	locks map[string]*sync.Mutex
+}
+
+func newArtifactCache(dir string, maxEntries int) *artifactCache {
+	return &artifactCache{
+		dir:        dir,
+		maxEntries: maxEntries,
+		lastUsed:   make(map[string]time.Time),
+		locks:      make(map[string]*sync.Mutex),
+	}
+}
+
+// normalizeArtifactDigest returns the digest in the algorithm:encoded form. Older artifacts
+// report the sha256 checksum only.
+func normalizeArtifactDigest(artifactDigest string) string {
+	if !strings.Contains(artifactDigest, ":") {
+		return "sha256:" + artifactDigest
+	}
+	return artifactDigest
+}
+
+func (a *artifactCache) getLock(artifactDigest string) *sync.Mutex {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	l, ok := a.locks[artifactDigest]
+	if !ok {
+		l = &sync.Mutex{}
+		a.locks[artifactDigest] = l
+	}
+	return l
+}
+
+func (a *artifactCache) getFileName(artifactDigest string) string {
+	return strings.ReplaceAll(artifactDigest, ":", "-") + ".tar.gz"
+}
+
+// fetch extracts artifact content into dir. Artifact is downloaded only if not cached yet or if the
+// cached tarball does not match the artifact digest anymore.
+func (a *artifactCache) fetch(artifact *sourcev1.Artifact, dir string, logger logr.Logger) error {
+	if artifact.Digest == "" {
+		return fmt.Errorf("artifact %s has no digest", artifact.URL)
+	}
+	artifactDigest := normalizeArtifactDigest(artifact.Digest)
+
+	l := a.getLock(artifactDigest)
+	l.Lock()
+	defer l.Unlock()
+
+	if err := os.MkdirAll(a.dir, 0o700); err != nil {
+		return err
+	}
+
+	fileName := a.getFileName(artifactDigest)
+	filePath := filepath.Join(a.dir, fileName)
+
+	size, err := verifyArtifactFile(filePath, artifactDigest)
+	if err == nil {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("artifact %s found in cache", artifactDigest))
+		artifactCacheRequestsCounter.WithLabelValues("hit").Inc()
+		artifactSavedBytesCounter.Add(float64(size))
+	} else {
+		if !os.IsNotExist(err) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("cached artifact %s is not valid: %v", artifactDigest, err))
+		}
+		if err := os.RemoveAll(filePath); err != nil {
+			return err
+		}
+
+		artifactFetcher := fetch.New(
+			fetch.WithRetries(1),
+			fetch.WithMaxDownloadSize(tar.UnlimitedUntarSize),
+			fetch.WithFileName(fileName),
+			fetch.WithHostnameOverwrite(os.Getenv("SOURCE_CONTROLLER_LOCALHOST")))
+
+		// Download artifact in the cache. Digest is verified by the fetcher.
+		if err := artifactFetcher.Fetch(artifact.URL, artifactDigest, a.dir); err != nil {
+			os.Remove(filePath)
+			return err
+		}
+
+		fi, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		artifactCacheRequestsCounter.WithLabelValues("miss").Inc()
+		artifactDownloadedBytesCounter.Add(float64(fi.Size()))
+	}
+
+	a.markUsed(artifactDigest)
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tar.Untar(f, dir, tar.WithMaxUntarSize(tar.UnlimitedUntarSize))
+}
+
+// markUsed records artifact has just been used and evicts least recently used artifacts
+// if the cache has grown beyond its limit
+func (a *artifactCache) markUsed(artifactDigest string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastUsed[artifactDigest] = time.Now()
+	if len(a.lastUsed) <= a.maxEntries {
+		return
+	}
+
+	digests := make([]string, 0, len(a.lastUsed))
+	for d := range a.lastUsed {
+		digests = append(digests, d)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return a.lastUsed[digests[i]].Before(a.lastUsed[digests[j]])
+	})
+
+	for _, d := range digests[:len(digests)-a.maxEntries] {
+		// An artifact being extracted stays readable till its file is closed
+		os.Remove(filepath.Join(a.dir, a.getFileName(d)))
+		delete(a.lastUsed, d)
+	}
+}
+
+// verifyArtifactFile returns the size of the file at filePath, or an error if the file
+// does not exist or its content does not match artifactDigest
+func verifyArtifactFile(filePath, artifactDigest string) (int64, error) {
+	d, err := digest.Parse(artifactDigest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse digest '%s': %w", artifactDigest, err)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	verifier := d.Verifier()
+	size, err := io.Copy(verifier, f)
+	if err != nil {
+		return 0, err
+	}
+	if !verifier.Verified() {
+		return 0, fmt.Errorf("computed digest doesn't match '%s'", artifactDigest)
+	}
+
+	return size, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"k8s.io/klog/v2/textlogger"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Flux artifact cache", func() {
+	var tarball []byte
+	var downloads int
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		content := []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: nginx\n")
+		Expect(tw.WriteHeader(&tar.Header{Name: "namespace.yaml", Mode: 0o600, Size: int64(len(content))})).To(Succeed())
+		_, err := tw.Write(content)
+		Expect(err).To(BeNil())
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		tarball = buf.Bytes()
+
+		downloads = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			downloads++
+			_, _ = w.Write(tarball)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("fetch downloads an artifact only once and verifies cached copy", func() {
+		cacheDir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(cacheDir)
+
+		cache := controllers.NewArtifactCache(cacheDir, 2)
+		artifact := &sourcev1.Artifact{
+			URL:    server.URL + "/artifact.tar.gz",
+			Digest: digest.FromBytes(tarball).String(),
+		}
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		for i := 0; i < 2; i++ {
+			dir, err := os.MkdirTemp("", randomString())
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(dir)
+
+			Expect(controllers.ArtifactCacheFetch(cache, artifact, dir, logger)).To(Succeed())
+			_, err = os.Stat(filepath.Join(dir, "namespace.yaml"))
+			Expect(err).To(BeNil())
+		}
+		Expect(downloads).To(Equal(1))
+
+		// Corrupt cached copy. It must be detected and artifact downloaded again
+		files, err := os.ReadDir(cacheDir)
+		Expect(err).To(BeNil())
+		Expect(len(files)).To(Equal(1))
+		Expect(strings.HasPrefix(files[0].Name(), "sha256-")).To(BeTrue())
+		Expect(os.WriteFile(filepath.Join(cacheDir, files[0].Name()), []byte(randomString()), 0o600)).To(Succeed())
+
+		dir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		Expect(controllers.ArtifactCacheFetch(cache, artifact, dir, logger)).To(Succeed())
+		Expect(downloads).To(Equal(2))
+	})
+
+	It("fetch fails when downloaded artifact does not match digest", func() {
+		cacheDir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(cacheDir)
+
+		cache := controllers.NewArtifactCache(cacheDir, 2)
+		artifact := &sourcev1.Artifact{
+			URL:    server.URL + "/artifact.tar.gz",
+			Digest: digest.FromString(randomString()).String(),
+		}
+
+		dir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		Expect(controllers.ArtifactCacheFetch(cache, artifact, dir,
+			textlogger.NewLogger(textlogger.NewConfig()))).ToNot(Succeed())
+
+		files, err := os.ReadDir(cacheDir)
+		Expect(err).To(BeNil())
+		Expect(files).To(BeEmpty())
+	})
+})
@@ -20,9 +20,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/fluxcd/pkg/http/fetch"
-	"github.com/fluxcd/pkg/tar"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 	"github.com/go-logr/logr"
@@ -50,21 +50,52 @@ func prepareFileSystemWithFluxSource(source sourcev1.Source, logger logr.Logger)
 		return "", err
 	}
 
-	artifactFetcher := fetch.New(
-		fetch.WithRetries(1),
-		fetch.WithMaxDownloadSize(tar.UnlimitedUntarSize),
-		fetch.WithUntar(tar.WithMaxUntarSize(tar.UnlimitedUntarSize)),
-		fetch.WithHostnameOverwrite(os.Getenv("SOURCE_CONTROLLER_LOCALHOST")))
-
-	// Download artifact and extract files to the tmp dir.
-	err = artifactFetcher.Fetch(source.GetArtifact().URL, source.GetArtifact().Digest, tmpDir)
+	// Extract artifact files to the tmp dir. Artifact is downloaded only if not cached already.
+	err = fluxArtifactCache.fetch(source.GetArtifact(), tmpDir, logger)
 	if err != nil {
+		os.RemoveAll(tmpDir)
 		return "", err
 	}
 
 	return tmpDir, nil
 }
 
+// getSourcePaths returns the directories, within rootDir, path refers to.
+// path can be a single path, a glob or a comma separated list of those. Each element
+// must match at least one directory. Directories matching a glob are sorted.
+func getSourcePaths(rootDir, path string) ([]string, error) {
+	result := make([]string, 0)
+	for _, p := range strings.Split(path, ",") {
+		p = strings.TrimSpace(p)
+		pattern := filepath.Join(rootDir, p)
+
+		if !strings.ContainsAny(p, "*?[") {
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, err
+			}
+			result = append(result, pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", p, err)
+		}
+		found := false
+		for i := range matches {
+			if fi, err := os.Stat(matches[i]); err == nil && fi.IsDir() {
+				result = append(result, matches[i])
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no directory matches path %q", p)
+		}
+	}
+
+	return result, nil
+}
+
 func getSource(ctx context.Context, c client.Client, namespace, sourceName, sourceKind string,
 ) (client.Object, error) {
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Flux source", func() {
+	var rootDir string
+
+	BeforeEach(func() {
+		var err error
+		rootDir, err = os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+
+		for _, dir := range []string{"clusters/prod/eu/addons", "clusters/prod/us/addons", "clusters/test/eu/addons",
+			"common"} {
+			Expect(os.MkdirAll(filepath.Join(rootDir, dir), 0o700)).To(Succeed())
+		}
+		// A file matching the glob must be ignored
+		Expect(os.WriteFile(filepath.Join(rootDir, "clusters/prod/addons"), []byte(randomString()), 0o600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	It("getSourcePaths returns the directory when path is not a glob", func() {
+		paths, err := controllers.GetSourcePaths(rootDir, "common")
+		Expect(err).To(BeNil())
+		Expect(paths).To(Equal([]string{filepath.Join(rootDir, "common")}))
+
+		paths, err = controllers.GetSourcePaths(rootDir, "")
+		Expect(err).To(BeNil())
+		Expect(paths).To(Equal([]string{rootDir}))
+
+		_, err = controllers.GetSourcePaths(rootDir, randomString())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("getSourcePaths expands globs and lists", func() {
+		paths, err := controllers.GetSourcePaths(rootDir, "clusters/prod/*/addons, common")
+		Expect(err).To(BeNil())
+		Expect(paths).To(Equal([]string{
+			filepath.Join(rootDir, "clusters/prod/eu/addons"),
+			filepath.Join(rootDir, "clusters/prod/us/addons"),
+			filepath.Join(rootDir, "common"),
+		}))
+
+		_, err = controllers.GetSourcePaths(rootDir, "clusters/staging/*/addons")
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,559 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// FluxTakeoverAnnotation is set on a Flux Kustomization/HelmRelease to request conversion
+	// into an equivalent ClusterProfile. Accepted values are "preview" and "apply".
+	// - preview: generated ClusterProfile and the JSON patch against the existing one (if any) are
+	// stored in a ConfigMap in the projectsveltos namespace. Nothing else is changed.
+	// - apply: generated ClusterProfile is created/updated.
+	FluxTakeoverAnnotation = "projectsveltos.io/takeover"
+
+	// FluxTakeoverClusterSelectorAnnotation contains the label selector (e.g. "env=prod,region in (eu)")
+	// the generated ClusterProfile uses to select managed clusters. It is required: takeover is refused
+	// when it is missing or empty, as an empty selector would match every cluster.
+	FluxTakeoverClusterSelectorAnnotation = "projectsveltos.io/takeover-cluster-selector"
+
+	// FluxTakeoverSourceLabel is added to ClusterProfiles and preview ConfigMaps generated from
+	// a Flux Kustomization/HelmRelease. Existing ClusterProfiles/ConfigMaps are only updated if they
+	// carry this label for the same Flux resource.
+	FluxTakeoverSourceLabel = "projectsveltos.io/flux-takeover-source"
+
+	fluxTakeoverPreview = "preview"
+	fluxTakeoverApply   = "apply"
+
+	fluxTakeoverProfileKey = "clusterprofile.yaml"
+	fluxTakeoverPatchKey   = "patch.json"
+
+	// fluxDefaultValuesKey is the key Flux reads from a HelmRelease valuesFrom ConfigMap/Secret
+	// when valuesKey is not set
+	fluxDefaultValuesKey = "values.yaml"
+)
+
+var (
+	// FluxKustomizationGVK is the GroupVersionKind of Flux Kustomization
+	FluxKustomizationGVK = schema.GroupVersionKind{
+		Group:   "kustomize.toolkit.fluxcd.io",
+		Version: "v1",
+		Kind:    "Kustomization",
+	}
+
+	// FluxHelmReleaseGVK is the GroupVersionKind of Flux HelmRelease
+	FluxHelmReleaseGVK = schema.GroupVersionKind{
+		Group:   "helm.toolkit.fluxcd.io",
+		Version: "v2",
+		Kind:    "HelmRelease",
+	}
+)
+
+// FluxTakeoverReconciler converts Flux Kustomizations/HelmReleases annotated with
+// projectsveltos.io/takeover into equivalent ClusterProfiles. This eases migrating
+// fleet configuration from per-cluster Flux to Sveltos.
+// One instance is started for each of the Flux kinds.
+type FluxTakeoverReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+	Logger               logr.Logger
+
+	// GVK is either FluxKustomizationGVK or FluxHelmReleaseGVK
+	GVK schema.GroupVersionKind
+}
+
+//+kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch
+//+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=helmrepositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterprofiles,verbs=create
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update
+
+func (r *FluxTakeoverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(logs.LogInfo).Info("Reconciling")
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(r.GVK)
+	if err := r.Get(ctx, req.NamespacedName, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Generated ClusterProfile is intentionally left in place. Removing the Flux
+			// resource is the expected last step of a migration.
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, fmt.Sprintf("Failed to fetch %s", r.GVK.Kind))
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to fetch %s %s", r.GVK.Kind, req.NamespacedName)
+	}
+
+	mode := u.GetAnnotations()[FluxTakeoverAnnotation]
+	if mode != fluxTakeoverPreview && mode != fluxTakeoverApply {
+		logger.V(logs.LogDebug).Info("takeover not requested")
+		return reconcile.Result{}, nil
+	}
+
+	clusterProfile, err := r.generateClusterProfile(ctx, u)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to convert to ClusterProfile: %v", err))
+		return getFluxTakeoverResult(err), nil
+	}
+
+	if mode == fluxTakeoverPreview {
+		err = r.storePreview(ctx, clusterProfile)
+	} else {
+		err = r.applyClusterProfile(ctx, clusterProfile)
+	}
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to %s ClusterProfile: %v", mode, err))
+		return getFluxTakeoverResult(err), nil
+	}
+
+	logger.V(logs.LogInfo).Info("Reconcile success")
+	return reconcile.Result{}, nil
+}
+
+// getFluxTakeoverResult returns the result of a failed takeover. Failures which can only be fixed
+// by changing the Flux resource (or the conflicting ClusterProfile) are not retried.
+func getFluxTakeoverResult(err error) reconcile.Result {
+	var nonRetriableError *NonRetriableError
+	if errors.As(err, &nonRetriableError) {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FluxTakeoverReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(r.GVK)
+
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		Named(fmt.Sprintf("fluxtakeover-%s", strings.ToLower(r.GVK.Kind))).
+		For(u).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "error creating controller")
+	}
+
+	return nil
+}
+
+// getFluxTakeoverProfileName returns the name of the ClusterProfile generated for a Flux resource
+func getFluxTakeoverProfileName(u *unstructured.Unstructured) string {
+	return strings.ToLower(fmt.Sprintf("flux-%s-%s-%s", u.GetKind(), u.GetNamespace(), u.GetName()))
+}
+
+func getFluxTakeoverSource(u *unstructured.Unstructured) string {
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s", u.GetKind(), u.GetNamespace(), u.GetName()))
+}
+
+func (r *FluxTakeoverReconciler) generateClusterProfile(ctx context.Context, u *unstructured.Unstructured,
+) (*configv1beta1.ClusterProfile, error) {
+
+	// An empty selector matches every cluster. Takeover of a single Flux resource must never
+	// result in a fleet-wide deployment, so a non empty selector is required.
+	selector, err := metav1.ParseToLabelSelector(
+		strings.TrimSpace(u.GetAnnotations()[FluxTakeoverClusterSelectorAnnotation]))
+	if err != nil {
+		return nil, &NonRetriableError{
+			Message: fmt.Sprintf("invalid %s annotation: %v", FluxTakeoverClusterSelectorAnnotation, err)}
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return nil, &NonRetriableError{
+			Message: fmt.Sprintf("takeover requires a non empty %s annotation", FluxTakeoverClusterSelectorAnnotation)}
+	}
+
+	clusterProfile := &configv1beta1.ClusterProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: getFluxTakeoverProfileName(u),
+			Labels: map[string]string{
+				FluxTakeoverSourceLabel: getFluxTakeoverSource(u),
+			},
+		},
+		Spec: configv1beta1.Spec{
+			ClusterSelector: libsveltosv1beta1.Selector{LabelSelector: *selector},
+		},
+	}
+	addTypeInformationToObject(r.Scheme, clusterProfile)
+
+	switch u.GetKind() {
+	case FluxKustomizationGVK.Kind:
+		kustomizationRef, err := convertFluxKustomization(u)
+		if err != nil {
+			return nil, err
+		}
+		clusterProfile.Spec.KustomizationRefs = []configv1beta1.KustomizationRef{*kustomizationRef}
+	case FluxHelmReleaseGVK.Kind:
+		helmChart, err := r.convertFluxHelmRelease(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		clusterProfile.Spec.HelmCharts = []configv1beta1.HelmChart{*helmChart}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", u.GetKind())
+	}
+
+	return clusterProfile, nil
+}
+
+// convertFluxKustomization converts a Flux Kustomization into a KustomizationRef
+// referencing the same Flux source.
+func convertFluxKustomization(u *unstructured.Unstructured) (*configv1beta1.KustomizationRef, error) {
+	sourceKind, _, err := unstructured.NestedString(u.Object, "spec", "sourceRef", "kind")
+	if err != nil {
+		return nil, err
+	}
+	sourceName, _, err := unstructured.NestedString(u.Object, "spec", "sourceRef", "name")
+	if err != nil {
+		return nil, err
+	}
+	sourceNamespace, _, err := unstructured.NestedString(u.Object, "spec", "sourceRef", "namespace")
+	if err != nil {
+		return nil, err
+	}
+	if sourceNamespace == "" {
+		sourceNamespace = u.GetNamespace()
+	}
+	sourcePath, _, err := unstructured.NestedString(u.Object, "spec", "path")
+	if err != nil {
+		return nil, err
+	}
+	targetNamespace, _, err := unstructured.NestedString(u.Object, "spec", "targetNamespace")
+	if err != nil {
+		return nil, err
+	}
+
+	switch sourceKind {
+	case sourcev1.GitRepositoryKind, sourcev1b2.OCIRepositoryKind, sourcev1b2.BucketKind:
+	default:
+		return nil, fmt.Errorf("unsupported sourceRef kind %q", sourceKind)
+	}
+
+	return &configv1beta1.KustomizationRef{
+		Namespace:       sourceNamespace,
+		Name:            sourceName,
+		Kind:            sourceKind,
+		Path:            sourcePath,
+		TargetNamespace: targetNamespace,
+		DeploymentType:  configv1beta1.DeploymentTypeRemote,
+	}, nil
+}
+
+// convertFluxHelmRelease converts a Flux HelmRelease, whose chart is sourced from a Flux
+// HelmRepository, into a HelmChart.
+func (r *FluxTakeoverReconciler) convertFluxHelmRelease(ctx context.Context, u *unstructured.Unstructured,
+) (*configv1beta1.HelmChart, error) {
+
+	chartSpec, found, err := unstructured.NestedMap(u.Object, "spec", "chart", "spec")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("only HelmReleases with spec.chart.spec are supported")
+	}
+
+	chart, _, _ := unstructured.NestedString(chartSpec, "chart")
+	version, _, _ := unstructured.NestedString(chartSpec, "version")
+	sourceKind, _, _ := unstructured.NestedString(chartSpec, "sourceRef", "kind")
+	sourceName, _, _ := unstructured.NestedString(chartSpec, "sourceRef", "name")
+	sourceNamespace, _, _ := unstructured.NestedString(chartSpec, "sourceRef", "namespace")
+	if sourceNamespace == "" {
+		sourceNamespace = u.GetNamespace()
+	}
+
+	if sourceKind != sourcev1.HelmRepositoryKind {
+		return nil, fmt.Errorf("unsupported chart sourceRef kind %q", sourceKind)
+	}
+
+	helmRepository := &sourcev1.HelmRepository{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: sourceNamespace, Name: sourceName},
+		helmRepository); err != nil {
+		return nil, err
+	}
+
+	releaseNamespace, _, _ := unstructured.NestedString(u.Object, "spec", "targetNamespace")
+	releaseName, _, _ := unstructured.NestedString(u.Object, "spec", "releaseName")
+	if releaseName == "" {
+		// Same default Flux uses
+		releaseName = u.GetName()
+		if releaseNamespace != "" {
+			releaseName = fmt.Sprintf("%s-%s", releaseNamespace, u.GetName())
+		}
+	}
+	if releaseNamespace == "" {
+		releaseNamespace = u.GetNamespace()
+	}
+
+	helmChart := &configv1beta1.HelmChart{
+		RepositoryURL:    helmRepository.Spec.URL,
+		RepositoryName:   helmRepository.Name,
+		ChartName:        chart,
+		ChartVersion:     version,
+		ReleaseName:      releaseName,
+		ReleaseNamespace: releaseNamespace,
+		HelmChartAction:  configv1beta1.HelmChartActionInstall,
+	}
+
+	if helmRepository.Spec.Type != sourcev1.HelmRepositoryTypeOCI {
+		// For non OCI repositories, chart is referenced as <repository name>/<chart name>
+		helmChart.ChartName = fmt.Sprintf("%s/%s", helmRepository.Name, chart)
+	}
+
+	values, found, err := unstructured.NestedMap(u.Object, "spec", "values")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		valuesYAML, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		helmChart.Values = string(valuesYAML)
+	}
+
+	helmChart.ValuesFrom, err = r.convertFluxValuesFrom(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(helmChart.ValuesFrom) != 0 && helmChart.Values != "" {
+		// Flux merges spec.values over spec.valuesFrom while Sveltos merges ValuesFrom over
+		// Values. Converting both would silently invert their precedence.
+		return nil, &NonRetriableError{
+			Message: "HelmReleases with both spec.values and spec.valuesFrom are not supported"}
+	}
+
+	return helmChart, nil
+}
+
+// convertFluxValuesFrom converts the valuesFrom of a Flux HelmRelease into ValuesFrom. Each entry
+// selects the same key Flux reads, in the same order. Entries which cannot be expressed
+// (targetPath) or which Sveltos would not accept (Secrets not of type ClusterProfileSecretType)
+// fail the conversion.
+func (r *FluxTakeoverReconciler) convertFluxValuesFrom(ctx context.Context, u *unstructured.Unstructured,
+) ([]configv1beta1.ValueFrom, error) {
+
+	valuesFrom, _, err := unstructured.NestedSlice(u.Object, "spec", "valuesFrom")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]configv1beta1.ValueFrom, 0, len(valuesFrom))
+	for i := range valuesFrom {
+		entry, ok := valuesFrom[i].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid spec.valuesFrom[%d]", i)
+		}
+
+		kind, _, _ := unstructured.NestedString(entry, "kind")
+		name, _, _ := unstructured.NestedString(entry, "name")
+		valuesKey, _, _ := unstructured.NestedString(entry, "valuesKey")
+		targetPath, _, _ := unstructured.NestedString(entry, "targetPath")
+		optional, _, _ := unstructured.NestedBool(entry, "optional")
+
+		if targetPath != "" {
+			return nil, &NonRetriableError{
+				Message: fmt.Sprintf("spec.valuesFrom[%d]: targetPath is not supported", i)}
+		}
+		if valuesKey == "" {
+			valuesKey = fluxDefaultValuesKey
+		}
+
+		switch kind {
+		case string(libsveltosv1beta1.ConfigMapReferencedResourceKind):
+		case string(libsveltosv1beta1.SecretReferencedResourceKind):
+			if err := r.verifyFluxValuesSecret(ctx, u.GetNamespace(), name, optional); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, &NonRetriableError{
+				Message: fmt.Sprintf("spec.valuesFrom[%d]: unsupported kind %q", i, kind)}
+		}
+
+		result = append(result, configv1beta1.ValueFrom{
+			Namespace: u.GetNamespace(),
+			Name:      name,
+			Kind:      kind,
+			Key:       valuesKey,
+			Optional:  optional,
+		})
+	}
+
+	return result, nil
+}
+
+// verifyFluxValuesSecret verifies a Secret referenced by a HelmRelease valuesFrom can be referenced
+// by Sveltos. Only Secrets of type ClusterProfileSecretType are.
+func (r *FluxTakeoverReconciler) verifyFluxValuesSecret(ctx context.Context, namespace, name string,
+	optional bool) error {
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) && optional {
+			return nil
+		}
+		return err
+	}
+
+	if secret.Type != libsveltosv1beta1.ClusterProfileSecretType {
+		return &NonRetriableError{
+			Message: fmt.Sprintf("Secret %s/%s referenced in spec.valuesFrom must be of type %s",
+				namespace, name, libsveltosv1beta1.ClusterProfileSecretType)}
+	}
+
+	return nil
+}
+
+// storePreview stores generated ClusterProfile along with the JSON patch, against the currently
+// existing ClusterProfile (if any), in a ConfigMap in the projectsveltos namespace.
+func (r *FluxTakeoverReconciler) storePreview(ctx context.Context, clusterProfile *configv1beta1.ClusterProfile,
+) error {
+
+	currentSpec := configv1beta1.Spec{}
+	currentClusterProfile := &configv1beta1.ClusterProfile{}
+	err := r.Get(ctx, types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)
+	if err == nil {
+		currentSpec = currentClusterProfile.Spec
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	patch, err := getSpecJSONPatch(&currentSpec, &clusterProfile.Spec)
+	if err != nil {
+		return err
+	}
+
+	profileYAML, err := yaml.Marshal(clusterProfile)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: clusterProfile.Name}, configMap)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		configMap.Namespace = projectsveltos
+		configMap.Name = clusterProfile.Name
+		configMap.Labels = clusterProfile.Labels
+		configMap.Data = map[string]string{
+			fluxTakeoverProfileKey: string(profileYAML),
+			fluxTakeoverPatchKey:   patch,
+		}
+		return r.Create(ctx, configMap)
+	}
+
+	if err := verifyFluxTakeoverSource("ConfigMap", configMap, clusterProfile); err != nil {
+		return err
+	}
+
+	configMap.Labels = clusterProfile.Labels
+	configMap.Data = map[string]string{
+		fluxTakeoverProfileKey: string(profileYAML),
+		fluxTakeoverPatchKey:   patch,
+	}
+	return r.Update(ctx, configMap)
+}
+
+// applyClusterProfile creates generated ClusterProfile or updates the Spec of the existing one.
+// An existing ClusterProfile is only updated if it was generated by takeover of the same Flux resource.
+func (r *FluxTakeoverReconciler) applyClusterProfile(ctx context.Context, clusterProfile *configv1beta1.ClusterProfile,
+) error {
+
+	currentClusterProfile := &configv1beta1.ClusterProfile{}
+	err := r.Get(ctx, types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.Create(ctx, clusterProfile)
+		}
+		return err
+	}
+
+	if err := verifyFluxTakeoverSource(configv1beta1.ClusterProfileKind, currentClusterProfile, clusterProfile); err != nil {
+		return err
+	}
+
+	currentClusterProfile.Spec = clusterProfile.Spec
+	return r.Update(ctx, currentClusterProfile)
+}
+
+// verifyFluxTakeoverSource returns an error if current was not generated by takeover of the
+// Flux resource clusterProfile is generated from
+func verifyFluxTakeoverSource(kind string, current client.Object, clusterProfile *configv1beta1.ClusterProfile) error {
+	source := clusterProfile.Labels[FluxTakeoverSourceLabel]
+	if current.GetLabels()[FluxTakeoverSourceLabel] != source {
+		return &NonRetriableError{
+			Message: fmt.Sprintf("%s %s exists and is not generated by takeover of %s", kind, current.GetName(), source)}
+	}
+	return nil
+}
+
+// getSpecJSONPatch returns the JSON patch (RFC 6902) which transforms current into desired
+func getSpecJSONPatch(current, desired *configv1beta1.Spec) (string, error) {
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+
+	desiredJSON, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+
+	operations, err := jsonpatch.CreatePatch(currentJSON, desiredJSON)
+	if err != nil {
+		return "", err
+	}
+
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		return "", err
+	}
+
+	return string(patch), nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Flux takeover", func() {
+	var namespace string
+
+	BeforeEach(func() {
+		namespace = randomString()
+	})
+
+	It("generateClusterProfile converts a Flux Kustomization", func() {
+		kustomization := &unstructured.Unstructured{}
+		kustomization.SetGroupVersionKind(controllers.FluxKustomizationGVK)
+		kustomization.SetNamespace(namespace)
+		kustomization.SetName(randomString())
+		kustomization.SetAnnotations(map[string]string{
+			controllers.FluxTakeoverAnnotation:                "preview",
+			controllers.FluxTakeoverClusterSelectorAnnotation: "env=prod",
+		})
+		gitRepository := randomString()
+		Expect(unstructured.SetNestedField(kustomization.Object, sourcev1.GitRepositoryKind,
+			"spec", "sourceRef", "kind")).To(Succeed())
+		Expect(unstructured.SetNestedField(kustomization.Object, gitRepository,
+			"spec", "sourceRef", "name")).To(Succeed())
+		Expect(unstructured.SetNestedField(kustomization.Object, "./apps/prod",
+			"spec", "path")).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		reconciler := &controllers.FluxTakeoverReconciler{Client: c, Scheme: scheme,
+			GVK: controllers.FluxKustomizationGVK}
+
+		clusterProfile, err := controllers.GenerateClusterProfile(reconciler, context.TODO(), kustomization)
+		Expect(err).To(BeNil())
+		Expect(clusterProfile.Spec.ClusterSelector.MatchLabels).To(HaveKeyWithValue("env", "prod"))
+		Expect(len(clusterProfile.Spec.KustomizationRefs)).To(Equal(1))
+		Expect(clusterProfile.Spec.KustomizationRefs[0].Kind).To(Equal(sourcev1.GitRepositoryKind))
+		Expect(clusterProfile.Spec.KustomizationRefs[0].Name).To(Equal(gitRepository))
+		Expect(clusterProfile.Spec.KustomizationRefs[0].Namespace).To(Equal(namespace))
+		Expect(clusterProfile.Spec.KustomizationRefs[0].Path).To(Equal("./apps/prod"))
+	})
+
+	It("generateClusterProfile converts a Flux HelmRelease", func() {
+		helmRepository := &sourcev1.HelmRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "kyverno",
+			},
+			Spec: sourcev1.HelmRepositorySpec{
+				URL: "https://kyverno.github.io/kyverno/",
+			},
+		}
+
+		helmRelease := &unstructured.Unstructured{}
+		helmRelease.SetGroupVersionKind(controllers.FluxHelmReleaseGVK)
+		helmRelease.SetNamespace(namespace)
+		helmRelease.SetName("kyverno")
+		helmRelease.SetAnnotations(map[string]string{
+			controllers.FluxTakeoverAnnotation:                "apply",
+			controllers.FluxTakeoverClusterSelectorAnnotation: "env=prod",
+		})
+		Expect(unstructured.SetNestedMap(helmRelease.Object, map[string]interface{}{
+			"chart":   "kyverno",
+			"version": "3.2.6",
+			"sourceRef": map[string]interface{}{
+				"kind": sourcev1.HelmRepositoryKind,
+				"name": helmRepository.Name,
+			},
+		}, "spec", "chart", "spec")).To(Succeed())
+		Expect(unstructured.SetNestedField(helmRelease.Object, "kyverno", "spec", "targetNamespace")).To(Succeed())
+
+		initObjects := []client.Object{helmRepository}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		reconciler := &controllers.FluxTakeoverReconciler{Client: c, Scheme: scheme,
+			GVK: controllers.FluxHelmReleaseGVK}
+
+		clusterProfile, err := controllers.GenerateClusterProfile(reconciler, context.TODO(), helmRelease)
+		Expect(err).To(BeNil())
+		Expect(len(clusterProfile.Spec.HelmCharts)).To(Equal(1))
+		helmChart := clusterProfile.Spec.HelmCharts[0]
+		Expect(helmChart.RepositoryURL).To(Equal(helmRepository.Spec.URL))
+		Expect(helmChart.ChartName).To(Equal("kyverno/kyverno"))
+		Expect(helmChart.ChartVersion).To(Equal("3.2.6"))
+		Expect(helmChart.ReleaseName).To(Equal("kyverno-kyverno"))
+		Expect(helmChart.ReleaseNamespace).To(Equal("kyverno"))
+		Expect(helmChart.HelmChartAction).To(Equal(configv1beta1.HelmChartActionInstall))
+	})
+
+	It("generateClusterProfile refuses takeover without a cluster selector", func() {
+		kustomization := &unstructured.Unstructured{}
+		kustomization.SetGroupVersionKind(controllers.FluxKustomizationGVK)
+		kustomization.SetNamespace(namespace)
+		kustomization.SetName(randomString())
+		kustomization.SetAnnotations(map[string]string{
+			controllers.FluxTakeoverAnnotation: "apply",
+		})
+		Expect(unstructured.SetNestedField(kustomization.Object, sourcev1.GitRepositoryKind,
+			"spec", "sourceRef", "kind")).To(Succeed())
+		Expect(unstructured.SetNestedField(kustomization.Object, randomString(),
+			"spec", "sourceRef", "name")).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		reconciler := &controllers.FluxTakeoverReconciler{Client: c, Scheme: scheme,
+			GVK: controllers.FluxKustomizationGVK}
+
+		_, err := controllers.GenerateClusterProfile(reconciler, context.TODO(), kustomization)
+		Expect(err).ToNot(BeNil())
+
+		kustomization.SetAnnotations(map[string]string{
+			controllers.FluxTakeoverAnnotation:                "apply",
+			controllers.FluxTakeoverClusterSelectorAnnotation: " ",
+		})
+		_, err = controllers.GenerateClusterProfile(reconciler, context.TODO(), kustomization)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("generateClusterProfile converts HelmRelease valuesFrom", func() {
+		helmRepository := &sourcev1.HelmRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Spec: sourcev1.HelmRepositorySpec{
+				URL: "https://kyverno.github.io/kyverno/",
+			},
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+
+		helmRelease := &unstructured.Unstructured{}
+		helmRelease.SetGroupVersionKind(controllers.FluxHelmReleaseGVK)
+		helmRelease.SetNamespace(namespace)
+		helmRelease.SetName(randomString())
+		helmRelease.SetAnnotations(map[string]string{
+			controllers.FluxTakeoverAnnotation:                "apply",
+			controllers.FluxTakeoverClusterSelectorAnnotation: "env=prod",
+		})
+		Expect(unstructured.SetNestedMap(helmRelease.Object, map[string]interface{}{
+			"chart": "kyverno",
+			"sourceRef": map[string]interface{}{
+				"kind": sourcev1.HelmRepositoryKind,
+				"name": helmRepository.Name,
+			},
+		}, "spec", "chart", "spec")).To(Succeed())
+		configMapName := randomString()
+		Expect(unstructured.SetNestedSlice(helmRelease.Object, []interface{}{
+			map[string]interface{}{"kind": "ConfigMap", "name": configMapName},
+			map[string]interface{}{"kind": "ConfigMap", "name": configMapName, "valuesKey": "prod.yaml", "optional": true},
+		}, "spec", "valuesFrom")).To(Succeed())
+
+		initObjects := []client.Object{helmRepository, secret}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		reconciler := &controllers.FluxTakeoverReconciler{Client: c, Scheme: scheme,
+			GVK: controllers.FluxHelmReleaseGVK}
+
+		clusterProfile, err := controllers.GenerateClusterProfile(reconciler, context.TODO(), helmRelease)
+		Expect(err).To(BeNil())
+		Expect(clusterProfile.Spec.HelmCharts[0].ValuesFrom).To(Equal([]configv1beta1.ValueFrom{
+			{Kind: "ConfigMap", Namespace: namespace, Name: configMapName, Key: "values.yaml"},
+			{Kind: "ConfigMap", Namespace: namespace, Name: configMapName, Key: "prod.yaml", Optional: true},
+		}))
+
+		// Flux values override valuesFrom, the opposite of Sveltos
+		Expect(unstructured.SetNestedMap(helmRelease.Object, map[string]interface{}{"replicaCount": int64(2)},
+			"spec", "values")).To(Succeed())
+		_, err = controllers.GenerateClusterProfile(reconciler, context.TODO(), helmRelease)
+		Expect(err).ToNot(BeNil())
+		unstructured.RemoveNestedField(helmRelease.Object, "spec", "values")
+
+		// targetPath cannot be expressed
+		Expect(unstructured.SetNestedSlice(helmRelease.Object, []interface{}{
+			map[string]interface{}{"kind": "ConfigMap", "name": configMapName, "targetPath": "image.tag"},
+		}, "spec", "valuesFrom")).To(Succeed())
+		_, err = controllers.GenerateClusterProfile(reconciler, context.TODO(), helmRelease)
+		Expect(err).ToNot(BeNil())
+
+		// Secrets Sveltos does not accept are refused
+		Expect(unstructured.SetNestedSlice(helmRelease.Object, []interface{}{
+			map[string]interface{}{"kind": "Secret", "name": secret.Name},
+		}, "spec", "valuesFrom")).To(Succeed())
+		_, err = controllers.GenerateClusterProfile(reconciler, context.TODO(), helmRelease)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("applyClusterProfile only updates ClusterProfiles generated by the same takeover", func() {
+		existing := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+		reconciler := &controllers.FluxTakeoverReconciler{Client: c, Scheme: scheme,
+			GVK: controllers.FluxKustomizationGVK}
+
+		generated := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   existing.Name,
+				Labels: map[string]string{controllers.FluxTakeoverSourceLabel: randomString()},
+			},
+			Spec: configv1beta1.Spec{
+				SetRefs: []string{randomString()},
+			},
+		}
+		Expect(controllers.ApplyFluxTakeoverClusterProfile(reconciler, context.TODO(), generated)).ToNot(Succeed())
+
+		current := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: existing.Name}, current)).To(Succeed())
+		Expect(current.Spec.SetRefs).To(BeEmpty())
+
+		current.Labels = generated.Labels
+		Expect(c.Update(context.TODO(), current)).To(Succeed())
+		Expect(controllers.ApplyFluxTakeoverClusterProfile(reconciler, context.TODO(), generated)).To(Succeed())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: existing.Name}, current)).To(Succeed())
+		Expect(current.Spec.SetRefs).To(Equal(generated.Spec.SetRefs))
+	})
+})
@@ -0,0 +1,279 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	clusterAdminClusterRole = "cluster-admin"
+)
+
+// Guardrails limit what ClusterProfiles/Profiles can deploy. InlineResources violating them
+// are reported in the profile SpecInvalid condition and in the ClusterSummary GuardrailsViolated
+// condition. Rendered content is verified before anything is deployed.
+type Guardrails struct {
+	// MaxResources is the maximum number of resources a profile can deploy with the Resources
+	// feature, the Kustomize feature and each helm release. 0 means no limit.
+	MaxResources int
+
+	// MaxRenderedSize is the maximum size, in bytes, of the resources a profile can deploy with
+	// the Resources feature, the Kustomize feature and each helm release. 0 means no limit.
+	MaxRenderedSize int
+
+	// ForbiddenKinds are the kinds profiles cannot deploy
+	ForbiddenKinds []schema.GroupKind
+
+	// ForbidClusterAdminBindings, when set, prevents profiles from deploying ClusterRoleBindings
+	// and RoleBindings to the cluster-admin ClusterRole
+	ForbidClusterAdminBindings bool
+}
+
+var (
+	guardrails Guardrails
+)
+
+func SetGuardrails(g Guardrails) {
+	guardrails = g
+}
+
+func getGuardrails() *Guardrails {
+	return &guardrails
+}
+
+// ParseForbiddenKinds parses a comma separated list of kinds, each expressed as <Kind>.<group>
+// (for instance ClusterRoleBinding.rbac.authorization.k8s.io) or <Kind> for the core group.
+func ParseForbiddenKinds(value string) ([]schema.GroupKind, error) {
+	forbiddenKinds := make([]schema.GroupKind, 0)
+	if strings.TrimSpace(value) == "" {
+		return forbiddenKinds, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		groupKind := schema.ParseGroupKind(strings.TrimSpace(entry))
+		if groupKind.Kind == "" {
+			return nil, fmt.Errorf("malformed kind %q: expected <Kind>.<group> or <Kind>", entry)
+		}
+		forbiddenKinds = append(forbiddenKinds, groupKind)
+	}
+
+	return forbiddenKinds, nil
+}
+
+// guardrailsTally tracks number and size of the resources rendered for a feature
+type guardrailsTally struct {
+	resources int
+	size      int
+}
+
+type guardrailsContextKey struct{}
+
+// withGuardrails returns a context tracking the resources rendered for a ClusterSummary feature.
+// If no limit on number or size of resources is set, ctx is returned unchanged.
+func withGuardrails(ctx context.Context) context.Context {
+	if getGuardrails().MaxResources == 0 && getGuardrails().MaxRenderedSize == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, guardrailsContextKey{}, &guardrailsTally{})
+}
+
+func getGuardrailsTally(ctx context.Context) *guardrailsTally {
+	tally, ok := ctx.Value(guardrailsContextKey{}).(*guardrailsTally)
+	if !ok {
+		return nil
+	}
+	return tally
+}
+
+// checkGuardrails returns a NonRetriableError if resources violate the Guardrails.
+// Number and size of resources are added to the tally (if any) before being verified.
+func checkGuardrails(resources []*unstructured.Unstructured, tally *guardrailsTally) error {
+	g := getGuardrails()
+	for i := range resources {
+		if err := checkGuardrailsForResource(g, resources[i]); err != nil {
+			return err
+		}
+
+		if tally == nil {
+			continue
+		}
+
+		tally.resources++
+		if g.MaxResources > 0 && tally.resources > g.MaxResources {
+			return &NonRetriableError{Message: fmt.Sprintf("guardrails: number of resources exceeds the maximum %d",
+				g.MaxResources)}
+		}
+
+		if g.MaxRenderedSize > 0 {
+			data, err := resources[i].MarshalJSON()
+			if err != nil {
+				return err
+			}
+			tally.size += len(data)
+			if tally.size > g.MaxRenderedSize {
+				return &NonRetriableError{Message: fmt.Sprintf("guardrails: size of resources exceeds the maximum %d bytes",
+					g.MaxRenderedSize)}
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkGuardrailsForResource(g *Guardrails, resource *unstructured.Unstructured) error {
+	groupKind := resource.GroupVersionKind().GroupKind()
+	for i := range g.ForbiddenKinds {
+		if g.ForbiddenKinds[i] == groupKind {
+			return &NonRetriableError{Message: fmt.Sprintf("guardrails: %s %s/%s: kind %s is forbidden",
+				resource.GetKind(), resource.GetNamespace(), resource.GetName(), groupKind.String())}
+		}
+	}
+
+	if g.ForbidClusterAdminBindings && isClusterAdminBinding(resource) {
+		return &NonRetriableError{Message: fmt.Sprintf("guardrails: %s %s/%s: bindings to ClusterRole %s are forbidden",
+			resource.GetKind(), resource.GetNamespace(), resource.GetName(), clusterAdminClusterRole)}
+	}
+
+	return nil
+}
+
+// isClusterAdminBinding returns true if resource is a ClusterRoleBinding/RoleBinding
+// to the cluster-admin ClusterRole
+func isClusterAdminBinding(resource *unstructured.Unstructured) bool {
+	if resource.GroupVersionKind().Group != rbacv1.GroupName {
+		return false
+	}
+	if resource.GetKind() != "ClusterRoleBinding" && resource.GetKind() != "RoleBinding" {
+		return false
+	}
+
+	kind, _, _ := unstructured.NestedString(resource.Object, "roleRef", "kind")
+	name, _, _ := unstructured.NestedString(resource.Object, "roleRef", "name")
+	return kind == "ClusterRole" && name == clusterAdminClusterRole
+}
+
+// validateGuardrails verifies InlineResources, unless expressed as templates (which can only be
+// validated once instantiated), do not violate the Guardrails.
+func validateGuardrails(spec *configv1beta1.Spec) error {
+	tally := &guardrailsTally{}
+	for i := range spec.InlineResources {
+		inlineResource := &spec.InlineResources[i]
+		if inlineResource.Template {
+			continue
+		}
+
+		resources, err := getUnstructured([]byte(inlineResource.Content), logr.Discard())
+		if err != nil {
+			return fmt.Errorf("inline resource %s: %w", inlineResource.Name, err)
+		}
+		err = checkGuardrails(resources, tally)
+		if err != nil {
+			return fmt.Errorf("inline resource %s: %w", inlineResource.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// updateGuardrailsCondition validates the Spec of clusterSummary against the Guardrails and
+// updates GuardrailsViolatedCondition accordingly. Profiles are validated as well, but a
+// ClusterSummary is never deployed relying on that. The condition is removed when no
+// guardrail is configured.
+func updateGuardrailsCondition(clusterSummary *configv1beta1.ClusterSummary) error {
+	if !hasGuardrails() {
+		meta.RemoveStatusCondition(&clusterSummary.Status.Conditions, configv1beta1.GuardrailsViolatedCondition)
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.GuardrailsViolatedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.WithinGuardrailsReason,
+		Message:            "spec is within guardrails",
+		ObservedGeneration: clusterSummary.Generation,
+	}
+
+	err := validateGuardrails(&clusterSummary.Spec.ClusterProfileSpec)
+	if err != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.GuardrailsViolationReason
+		condition.Message = err.Error()
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, condition)
+	return err
+}
+
+// checkReleaseGuardrails returns a NonRetriableError if the rendered helm release violates the
+// Guardrails. Release manifest, hooks and crds, the CRDs deployed along with the release, are
+// verified together: helm post renderers only see the manifest.
+func checkReleaseGuardrails(rel *release.Release, crds []chart.CRD) error {
+	manifests := []string{rel.Manifest}
+	for i := range rel.Hooks {
+		manifests = append(manifests, rel.Hooks[i].Manifest)
+	}
+	for i := range crds {
+		manifests = append(manifests, string(crds[i].File.Data))
+	}
+
+	resources := make([]*unstructured.Unstructured, 0)
+	for i := range manifests {
+		elements, err := customSplit(manifests[i])
+		if err != nil {
+			return err
+		}
+
+		for j := range elements {
+			if strings.TrimSpace(elements[j]) == "" {
+				continue
+			}
+
+			policy, err := utils.GetUnstructured([]byte(elements[j]))
+			if err != nil {
+				return err
+			}
+			if policy != nil {
+				resources = append(resources, policy)
+			}
+		}
+	}
+
+	return checkGuardrails(resources, &guardrailsTally{})
+}
+
+// hasGuardrails returns true if any guardrail is configured
+func hasGuardrails() bool {
+	g := getGuardrails()
+	return g.MaxResources != 0 || g.MaxRenderedSize != 0 || len(g.ForbiddenKinds) != 0 ||
+		g.ForbidClusterAdminBindings
+}
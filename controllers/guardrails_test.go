@@ -0,0 +1,233 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	clusterAdminBinding = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: %s
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: default`
+
+	guardrailsConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+data:
+  key: value`
+)
+
+var _ = Describe("Guardrails", func() {
+	AfterEach(func() {
+		controllers.SetGuardrails(controllers.Guardrails{})
+	})
+
+	getResource := func(template string) *unstructured.Unstructured {
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(template, randomString())))
+		Expect(err).To(BeNil())
+		return u
+	}
+
+	It("ParseForbiddenKinds parses kinds with and without group", func() {
+		kinds, err := controllers.ParseForbiddenKinds("ClusterRoleBinding.rbac.authorization.k8s.io, Namespace")
+		Expect(err).To(BeNil())
+		Expect(kinds).To(ConsistOf(
+			schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"},
+			schema.GroupKind{Kind: "Namespace"},
+		))
+
+		kinds, err = controllers.ParseForbiddenKinds("")
+		Expect(err).To(BeNil())
+		Expect(kinds).To(BeEmpty())
+
+		_, err = controllers.ParseForbiddenKinds("Namespace,,")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("checkGuardrails rejects forbidden kinds", func() {
+		configMap := getResource(guardrailsConfigMap)
+		Expect(controllers.CheckGuardrails([]*unstructured.Unstructured{configMap})).To(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{
+			ForbiddenKinds: []schema.GroupKind{{Kind: "ConfigMap"}},
+		})
+		err := controllers.CheckGuardrails([]*unstructured.Unstructured{configMap})
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+	})
+
+	It("checkGuardrails rejects cluster-admin bindings", func() {
+		binding := getResource(clusterAdminBinding)
+		Expect(controllers.CheckGuardrails([]*unstructured.Unstructured{binding})).To(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{ForbidClusterAdminBindings: true})
+		Expect(controllers.CheckGuardrails([]*unstructured.Unstructured{binding})).ToNot(Succeed())
+		Expect(controllers.CheckGuardrails([]*unstructured.Unstructured{getResource(guardrailsConfigMap)})).To(Succeed())
+	})
+
+	It("checkGuardrails enforces maximum number and size of resources", func() {
+		resources := []*unstructured.Unstructured{
+			getResource(guardrailsConfigMap), getResource(guardrailsConfigMap),
+		}
+
+		controllers.SetGuardrails(controllers.Guardrails{MaxResources: 2})
+		Expect(controllers.CheckGuardrails(resources)).To(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{MaxResources: 1})
+		Expect(controllers.CheckGuardrails(resources)).ToNot(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{MaxRenderedSize: 10})
+		Expect(controllers.CheckGuardrails(resources)).ToNot(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{MaxRenderedSize: 10000})
+		Expect(controllers.CheckGuardrails(resources)).To(Succeed())
+	})
+
+	It("validateGuardrails skips InlineResources expressed as templates", func() {
+		controllers.SetGuardrails(controllers.Guardrails{ForbidClusterAdminBindings: true})
+
+		spec := &configv1beta1.Spec{
+			InlineResources: []configv1beta1.InlineResource{
+				{Name: randomString(), Content: fmt.Sprintf(clusterAdminBinding, randomString()), Template: true},
+			},
+		}
+		Expect(controllers.ValidateGuardrails(spec)).To(Succeed())
+
+		spec.InlineResources[0].Template = false
+		Expect(controllers.ValidateGuardrails(spec)).ToNot(Succeed())
+	})
+
+	It("checkReleaseGuardrails verifies release manifest, hooks and crds", func() {
+		controllers.SetGuardrails(controllers.Guardrails{ForbidClusterAdminBindings: true})
+
+		rel := &release.Release{Manifest: fmt.Sprintf(guardrailsConfigMap, randomString())}
+		Expect(controllers.CheckReleaseGuardrails(rel, nil)).To(Succeed())
+
+		// Helm does not post render hooks: a hook only forbidden resource is rejected as well
+		rel.Hooks = []*release.Hook{{Path: "templates/binding.yaml",
+			Manifest: fmt.Sprintf(clusterAdminBinding, randomString())}}
+		Expect(controllers.CheckReleaseGuardrails(rel, nil)).ToNot(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{MaxResources: 2})
+		rel.Hooks = []*release.Hook{{Path: "templates/configmap.yaml",
+			Manifest: fmt.Sprintf(guardrailsConfigMap, randomString())}}
+		Expect(controllers.CheckReleaseGuardrails(rel, nil)).To(Succeed())
+		crds := []chart.CRD{{Name: "crds/configmap.yaml",
+			File: &chart.File{Name: "crds/configmap.yaml", Data: []byte(fmt.Sprintf(guardrailsConfigMap, randomString()))}}}
+		Expect(controllers.CheckReleaseGuardrails(rel, crds)).ToNot(Succeed())
+	})
+
+	It("validateRenderedRelease rejects helm releases with hook only resources violating the guardrails", func() {
+		kubeconfig, err := clusterproxy.CreateKubeconfig(textlogger.NewLogger(textlogger.NewConfig()), testEnv.Kubeconfig)
+		Expect(err).To(BeNil())
+
+		releaseNamespace := randomString()
+		actionConfig, err := controllers.GetHelmActionConfig(releaseNamespace, kubeconfig)
+		Expect(err).To(BeNil())
+
+		requestedChart := &configv1beta1.HelmChart{ReleaseName: randomString(), ReleaseNamespace: releaseNamespace}
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), Namespace: randomString()},
+		}
+
+		hookBinding := strings.Replace(fmt.Sprintf(clusterAdminBinding, randomString()), "metadata:\n",
+			"metadata:\n  annotations:\n    helm.sh/hook: pre-install\n", 1)
+		chartRequested := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "guardrails", Version: "0.1.0"},
+			Templates: []*chart.File{
+				{Name: "templates/configmap.yaml", Data: []byte(fmt.Sprintf(guardrailsConfigMap, randomString()))},
+				{Name: "templates/binding.yaml", Data: []byte(hookBinding)},
+			},
+		}
+
+		Expect(controllers.ValidateRenderedRelease(context.TODO(), clusterSummary, requestedChart, actionConfig,
+			chartRequested, map[string]interface{}{}, nil, false, nil)).To(Succeed())
+
+		controllers.SetGuardrails(controllers.Guardrails{ForbidClusterAdminBindings: true})
+		err = controllers.ValidateRenderedRelease(context.TODO(), clusterSummary, requestedChart, actionConfig,
+			chartRequested, map[string]interface{}{}, nil, false, nil)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("bindings to ClusterRole cluster-admin are forbidden"))
+	})
+
+	It("updateGuardrailsCondition reports ClusterSummaries violating the guardrails", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), Namespace: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					InlineResources: []configv1beta1.InlineResource{
+						{Name: randomString(), Content: fmt.Sprintf(clusterAdminBinding, randomString())},
+					},
+				},
+			},
+		}
+
+		// No guardrail configured
+		Expect(controllers.UpdateGuardrailsCondition(clusterSummary)).To(Succeed())
+		Expect(meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1beta1.GuardrailsViolatedCondition)).To(BeNil())
+
+		controllers.SetGuardrails(controllers.Guardrails{ForbidClusterAdminBindings: true})
+		Expect(controllers.UpdateGuardrailsCondition(clusterSummary)).ToNot(Succeed())
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1beta1.GuardrailsViolatedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.GuardrailsViolationReason))
+
+		clusterSummary.Spec.ClusterProfileSpec.InlineResources[0].Content =
+			fmt.Sprintf(guardrailsConfigMap, randomString())
+		Expect(controllers.UpdateGuardrailsCondition(clusterSummary)).To(Succeed())
+		condition = meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1beta1.GuardrailsViolatedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.WithinGuardrailsReason))
+	})
+})
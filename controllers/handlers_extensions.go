@@ -0,0 +1,259 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gdexlab/go-render/render"
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/extension"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// extensionCallTimeout is the maximum time a plugin has to process a Deploy/Undeploy request
+	extensionCallTimeout = 5 * time.Minute
+)
+
+var (
+	extensionMux sync.Mutex
+	// key: extension kind; value: address of the plugin registered for the kind
+	extensionPlugins map[string]string
+	// key: extension kind; value: connection to the plugin
+	extensionConns map[string]*grpc.ClientConn
+)
+
+// SetExtensionPlugins registers the out-of-tree plugins extensions are dispatched to.
+// Key is the extension Kind, value is the plugin gRPC address (for instance unix:///plugins/db.sock
+// or dns:///db-migrator.projectsveltos:9090). Connections are established lazily.
+func SetExtensionPlugins(plugins map[string]string) {
+	extensionMux.Lock()
+	defer extensionMux.Unlock()
+
+	for kind := range extensionConns {
+		_ = extensionConns[kind].Close()
+	}
+
+	extensionPlugins = make(map[string]string, len(plugins))
+	for kind := range plugins {
+		extensionPlugins[kind] = plugins[kind]
+	}
+	extensionConns = make(map[string]*grpc.ClientConn)
+}
+
+// getRegisteredExtensionKinds returns, sorted, all kinds a plugin is registered for
+func getRegisteredExtensionKinds() []string {
+	extensionMux.Lock()
+	defer extensionMux.Unlock()
+
+	kinds := make([]string, 0, len(extensionPlugins))
+	for kind := range extensionPlugins {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// getExtensionPluginClient returns a client for the plugin registered for kind
+func getExtensionPluginClient(kind string) (extension.PluginClient, error) {
+	extensionMux.Lock()
+	defer extensionMux.Unlock()
+
+	if conn, ok := extensionConns[kind]; ok {
+		return extension.NewPluginClient(conn), nil
+	}
+
+	address, ok := extensionPlugins[kind]
+	if !ok {
+		return nil, fmt.Errorf("no plugin registered for extension kind %s", kind)
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to plugin for extension kind %s: %w", kind, err)
+	}
+	extensionConns[kind] = conn
+
+	return extension.NewPluginClient(conn), nil
+}
+
+// groupExtensionsByKind returns the extensions grouped by Kind
+func groupExtensionsByKind(extensions []configv1beta1.Extension) map[string][]configv1beta1.Extension {
+	result := make(map[string][]configv1beta1.Extension)
+	for i := range extensions {
+		result[extensions[i].Kind] = append(result[extensions[i].Kind], extensions[i])
+	}
+	return result
+}
+
+func getExtensionRequest(clusterSummary *configv1beta1.ClusterSummary, kind string,
+	extensions []configv1beta1.Extension) *extension.Request {
+
+	return &extension.Request{
+		Cluster: extension.Cluster{
+			Namespace: clusterSummary.Spec.ClusterNamespace,
+			Name:      clusterSummary.Spec.ClusterName,
+			Type:      clusterSummary.Spec.ClusterType,
+		},
+		ClusterSummary: clusterSummary.Name,
+		Kind:           kind,
+		Extensions:     extensions,
+		DryRun:         clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun,
+	}
+}
+
+func deployExtensions(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1beta1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	clusterSummary, err := configv1beta1.GetClusterSummary(ctx, c, clusterNamespace, applicant)
+	if err != nil {
+		return err
+	}
+	applyObserveOnly(clusterSummary)
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
+	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
+	logger.V(logs.LogDebug).Info("deployExtensions")
+
+	byKind := groupExtensionsByKind(clusterSummary.Spec.ClusterProfileSpec.Extensions)
+
+	kinds := make([]string, 0, len(byKind))
+	for kind := range byKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		if err := invokeExtensionPlugin(ctx, clusterSummary, kind, byKind[kind], false, logger); err != nil {
+			return err
+		}
+	}
+
+	// Extensions of a kind not referenced anymore are removed. Plugins are required to treat
+	// Undeploy as idempotent, so this is safe even if nothing of that kind was ever deployed.
+	for _, kind := range getRegisteredExtensionKinds() {
+		if _, ok := byKind[kind]; ok {
+			continue
+		}
+		if err := invokeExtensionPlugin(ctx, clusterSummary, kind, nil, true, logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func undeployExtensions(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1beta1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	clusterSummary, err := configv1beta1.GetClusterSummary(ctx, c, clusterNamespace, applicant)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	applyObserveOnly(clusterSummary)
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
+	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
+	logger.V(logs.LogDebug).Info("undeployExtensions")
+
+	for _, kind := range getRegisteredExtensionKinds() {
+		if err := invokeExtensionPlugin(ctx, clusterSummary, kind, nil, true, logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// invokeExtensionPlugin invokes Deploy (or Undeploy if undeploy is set) on the plugin registered for kind
+func invokeExtensionPlugin(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary, kind string,
+	extensions []configv1beta1.Extension, undeploy bool, logger logr.Logger) error {
+
+	pluginClient, err := getExtensionPluginClient(kind)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, extensionCallTimeout)
+	defer cancel()
+
+	req := getExtensionRequest(clusterSummary, kind, extensions)
+	var resp *extension.Response
+	if undeploy {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("undeploy extensions of kind %s", kind))
+		resp, err = pluginClient.Undeploy(ctx, req)
+	} else {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("deploy %d extensions of kind %s", len(extensions), kind))
+		resp, err = pluginClient.Deploy(ctx, req)
+	}
+	if err != nil {
+		return fmt.Errorf("plugin for extension kind %s failed: %w", kind, err)
+	}
+
+	if resp.Message != "" {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("plugin for extension kind %s: %s", kind, resp.Message))
+	}
+
+	return nil
+}
+
+// extensionsHash returns the hash of all the extensions. Any change to an extension causes
+// the extensions to be redeployed.
+func extensionsHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) ([]byte, error) {
+
+	clusterProfileSpecHash, err := getClusterProfileSpecHash(ctx, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	var config string
+	config += string(clusterProfileSpecHash)
+	config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Extensions)
+	// A plugin registered or unregistered for a kind might need extensions to be redeployed
+	config += render.AsCode(getRegisteredExtensionKinds())
+
+	h.Write([]byte(config))
+	return h.Sum(nil), nil
+}
+
+func getExtensionRefs(clusterSummary *configv1beta1.ClusterSummary) []configv1beta1.PolicyRef {
+	return nil
+}
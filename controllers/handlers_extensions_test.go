@@ -0,0 +1,135 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/extension"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+type fakeExtensionPlugin struct {
+	mux        sync.Mutex
+	deployed   []*extension.Request
+	undeployed []*extension.Request
+}
+
+func (p *fakeExtensionPlugin) Deploy(ctx context.Context, req *extension.Request) (*extension.Response, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.deployed = append(p.deployed, req)
+	return &extension.Response{Message: "deployed"}, nil
+}
+
+func (p *fakeExtensionPlugin) Undeploy(ctx context.Context, req *extension.Request) (*extension.Response, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.undeployed = append(p.undeployed, req)
+	return &extension.Response{}, nil
+}
+
+var _ = Describe("Extensions", func() {
+	var server *grpc.Server
+	var plugin *fakeExtensionPlugin
+	var address string
+
+	BeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).To(BeNil())
+		address = listener.Addr().String()
+
+		plugin = &fakeExtensionPlugin{}
+		server = grpc.NewServer()
+		extension.RegisterPluginServer(server, plugin)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+	})
+
+	AfterEach(func() {
+		controllers.SetExtensionPlugins(nil)
+		server.Stop()
+	})
+
+	It("deployExtensions dispatches extensions to the plugin registered for their kind", func() {
+		const kind = "db-migration"
+		controllers.SetExtensionPlugins(map[string]string{kind: address, "unused": address})
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					Extensions: []configv1beta1.Extension{
+						{Kind: kind, Name: "orders", Config: "schema: v2"},
+						{Kind: kind, Name: "payments", Config: "schema: v5"},
+					},
+				},
+			},
+		}
+		clusterSummary.Namespace = clusterSummary.Spec.ClusterNamespace
+
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		Expect(controllers.DeployExtensions(context.TODO(), c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, clusterSummary.Name, string(configv1beta1.FeatureExtensions),
+			clusterSummary.Spec.ClusterType, deployer.Options{}, logger)).To(Succeed())
+
+		Expect(len(plugin.deployed)).To(Equal(1))
+		Expect(plugin.deployed[0].Kind).To(Equal(kind))
+		Expect(plugin.deployed[0].ClusterSummary).To(Equal(clusterSummary.Name))
+		Expect(plugin.deployed[0].Cluster.Name).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(plugin.deployed[0].Extensions).To(Equal(clusterSummary.Spec.ClusterProfileSpec.Extensions))
+
+		// Kinds registered but not referenced are cleaned up
+		Expect(len(plugin.undeployed)).To(Equal(1))
+		Expect(plugin.undeployed[0].Kind).To(Equal("unused"))
+
+		Expect(controllers.UndeployExtensions(context.TODO(), c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, clusterSummary.Name, string(configv1beta1.FeatureExtensions),
+			clusterSummary.Spec.ClusterType, deployer.Options{}, logger)).To(Succeed())
+		Expect(len(plugin.undeployed)).To(Equal(3))
+
+		// No plugin registered for kind
+		controllers.SetExtensionPlugins(map[string]string{})
+		Expect(controllers.DeployExtensions(context.TODO(), c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, clusterSummary.Name, string(configv1beta1.FeatureExtensions),
+			clusterSummary.Spec.ClusterType, deployer.Options{}, logger)).ToNot(Succeed())
+	})
+})
@@ -25,7 +25,9 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -39,7 +41,6 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
@@ -52,6 +53,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
@@ -63,6 +65,7 @@ import (
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
@@ -84,6 +87,12 @@ const (
 	notInstalledMessage        = "Not installed yet and action is uninstall"
 	defaultMaxHistory          = 2
 	defaultDeletionPropagation = "background"
+	// helmRollbackMessage is part of the error returned by helm when a failed upgrade is
+	// rolled back to the last successful revision (atomic upgrade)
+	helmRollbackMessage = "has been rolled back due to atomic being set"
+	// helmTestsRollbackMessage is part of the error returned when a release is rolled back
+	// because chart tests failed (RollbackOnHelmTestsFailure)
+	helmTestsRollbackMessage = "has been rolled back due to helm tests failure"
 )
 
 type registryClientOptions struct {
@@ -91,6 +100,13 @@ type registryClientOptions struct {
 	caPath          string
 	skipTLSVerify   bool
 	plainHTTP       bool
+	// username, password, certPath and keyPath authenticate to HTTP helm repositories
+	username string
+	password string
+	certPath string
+	keyPath  string
+	// storage is where helm stores release information. If nil, helm default is used.
+	storage *helmStorageOptions
 }
 
 type releaseInfo struct {
@@ -104,6 +120,8 @@ type releaseInfo struct {
 	AppVersion       string            `json:"app_version"`
 	ReleaseLabels    map[string]string `json:"release_labels"`
 	Icon             string            `json:"icon"`
+	Notes            string            `json:"notes"`
+	ComputedValues   chartutil.Values  `json:"computed_values"`
 }
 
 func deployHelmCharts(ctx context.Context, c client.Client,
@@ -117,6 +135,23 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 		return err
 	}
 
+	// Two helm charts must not be instantiated to the same helm release
+	err = checkInstantiatedHelmReleaseCollisions(ctx, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	// Releases are deployed with their name and namespace instantiated for this cluster
+	clusterSummary, err = instantiateHelmReleases(ctx, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	err = validateClusterSummaryReferences(ctx, c, clusterSummary, configv1beta1.FeatureHelm)
+	if err != nil {
+		return err
+	}
+
 	startInMgmtCluster := startDriftDetectionInMgmtCluster(o)
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
@@ -142,7 +177,7 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
 	logger = logger.WithValues("admin", fmt.Sprintf("%s/%s", adminNamespace, adminName))
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+	kubeconfigContent, err := secretprovider.GetSecretData(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -155,7 +190,31 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	}
 	defer os.Remove(kubeconfig)
 
+	// Deployment is aborted if ClusterSummary Spec changes in the meantime
+	ctx = withDeploymentRevision(ctx, c, clusterSummary)
+	// Image tags are pinned to digests, if ImageDigestResolution is set
+	ctx, err = withImageDigests(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Rendered resources are passed through the WasmPlugins, if any
+	ctx, err = withWasmPlugins(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Helm releases managed by a different profile are reported in the Status
+	ctx = withConflicts(ctx)
+
 	err = handleCharts(ctx, clusterSummary, c, remoteClient, kubeconfig, logger)
+	recordConflicts(ctx, clusterSummary, configv1beta1.FeatureHelm, err, logger)
+	if err != nil {
+		return err
+	}
+
+	recordResolvedImages(ctx, clusterSummary, configv1beta1.FeatureHelm, logger)
+
+	// Virtual clusters deployed by the helm charts are registered, so profiles can target them
+	err = registerVirtualClusters(ctx, c, remoteClient, clusterSummary, logger)
 	if err != nil {
 		return err
 	}
@@ -219,6 +278,13 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 		}
 		return err
 	}
+	applyObserveOnly(clusterSummary)
+
+	// Releases were deployed with their name and namespace instantiated for this cluster
+	clusterSummary, err = instantiateHelmReleases(ctx, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
 
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
@@ -227,7 +293,7 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployHelmCharts")
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+	kubeconfigContent, err := secretprovider.GetSecretData(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -240,6 +306,14 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 	}
 	defer os.Remove(kubeconfig)
 
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun {
+		// Virtual clusters are unregistered before being removed
+		err = unregisterVirtualClusters(ctx, c, clusterSummary, nil, logger)
+		if err != nil {
+			return err
+		}
+	}
+
 	return undeployHelmChartResources(ctx, c, clusterSummary, kubeconfig, logger)
 }
 
@@ -255,7 +329,8 @@ func undeployHelmChartResources(ctx context.Context, c client.Client, clusterSum
 	// not referenced anymore. Only if this operation succeeds, removes all stale
 	// helm release registration for this clusterSummary.
 	var undeployedReports []configv1beta1.ReleaseReport
-	undeployedReports, err = undeployStaleReleases(ctx, c, clusterSummary, kubeconfig, logger)
+	undeployedReports, err = undeployStaleReleases(ctx, c, clusterSummary, getHelmReleaseStorages(clusterSummary),
+		kubeconfig, logger)
 	if err != nil {
 		return err
 	}
@@ -306,6 +381,11 @@ func uninstallHelmCharts(ctx context.Context, c client.Client, clusterSummary *c
 		return nil, err
 	}
 
+	leavePolicies, err := isLeavePolicies(ctx, clusterSummary, configv1beta1.FeatureHelm, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	releaseReports := make([]configv1beta1.ReleaseReport, 0)
 	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
 		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
@@ -336,11 +416,15 @@ func uninstallHelmCharts(ctx context.Context, c client.Client, clusterSummary *c
 				}
 			} else {
 				// If StopMatchingBehavior is LeavePolicies, do not uninstall helm charts
-				if !clusterSummary.DeletionTimestamp.IsZero() &&
-					clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehavior == configv1beta1.LeavePolicies {
-
+				if leavePolicies {
 					logger.V(logs.LogInfo).Info("ClusterProfile StopMatchingBehavior set to LeavePolicies")
 				} else {
+					storageOptions, err := getHelmStorageOptions(ctx, c, clusterSummary.Spec.ClusterNamespace,
+						getHelmStorageValue(currentChart.Options))
+					if err != nil {
+						return nil, err
+					}
+
 					credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, c,
 						clusterSummary.Spec.ClusterNamespace, currentChart)
 					if err != nil {
@@ -352,6 +436,7 @@ func uninstallHelmCharts(ctx context.Context, c client.Client, clusterSummary *c
 						credentialsPath: credentialsPath, caPath: caPath,
 						skipTLSVerify: getInsecureSkipTLSVerify(currentChart),
 						plainHTTP:     getPlainHTTP(currentChart),
+						storage:       storageOptions,
 					}
 
 					currentRelease, err := getReleaseInfo(currentChart.ReleaseName, currentChart.ReleaseNamespace,
@@ -406,6 +491,17 @@ func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.C
 
 		config += render.AsCode(*currentChart)
 
+		// When ReleaseName/ReleaseNamespace are templates, a change in the Cluster (labels for instance)
+		// can lead to a different release being deployed
+		if isHelmReleaseTemplate(currentChart) {
+			releaseName, releaseNamespace, err := instantiateHelmChartRelease(ctx, clusterSummary, currentChart, logger)
+			if err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate release %v", err))
+				return nil, err
+			}
+			config += releaseNamespace + "/" + releaseName
+		}
+
 		valueFromHash, err := getHelmReferenceResourceHash(ctx, c, clusterSummaryScope.ClusterSummary,
 			currentChart, logger)
 		if err != nil {
@@ -417,6 +513,16 @@ func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.C
 		config += valueFromHash
 	}
 
+	// Updating a version in the chart versions ConfigMap requires an upgrade
+	pinnedVersions, err := getPinnedChartVersions(ctx, c, clusterSummary)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get pinned chart versions %v", err))
+		return nil, err
+	}
+	if len(pinnedVersions) > 0 {
+		config += render.AsCode(pinnedVersions)
+	}
+
 	for i := range clusterSummary.Spec.ClusterProfileSpec.ValidateHealths {
 		h := &clusterSummary.Spec.ClusterProfileSpec.ValidateHealths[i]
 		if h.FeatureID == configv1beta1.FeatureHelm {
@@ -428,10 +534,131 @@ func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.C
 	return h.Sum(nil), nil
 }
 
+// isHelmReleaseTemplate returns true if the helm chart ReleaseName or ReleaseNamespace is a template
+func isHelmReleaseTemplate(helmChart *configv1beta1.HelmChart) bool {
+	return strings.Contains(helmChart.ReleaseName, "{{") || strings.Contains(helmChart.ReleaseNamespace, "{{")
+}
+
+// instantiateHelmChartRelease returns the helm chart ReleaseName and ReleaseNamespace instantiated,
+// when expressed as templates, for the cluster clusterSummary is for.
+func instantiateHelmChartRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	helmChart *configv1beta1.HelmChart, logger logr.Logger) (releaseName, releaseNamespace string, err error) {
+
+	releaseName, releaseNamespace = helmChart.ReleaseName, helmChart.ReleaseNamespace
+
+	if strings.Contains(releaseName, "{{") {
+		releaseName, err = instantiateClusterSummaryTemplate(ctx, clusterSummary, clusterSummary.GetName(),
+			releaseName, nil, logger)
+		if err != nil {
+			return "", "", err
+		}
+		releaseName = strings.TrimSpace(releaseName)
+		if err = chartutil.ValidateReleaseName(releaseName); err != nil {
+			return "", "", &NonRetriableError{
+				Message: fmt.Sprintf("releaseName %q instantiated to invalid %q: %v", helmChart.ReleaseName, releaseName, err)}
+		}
+	}
+
+	if strings.Contains(releaseNamespace, "{{") {
+		releaseNamespace, err = instantiateClusterSummaryTemplate(ctx, clusterSummary, clusterSummary.GetName(),
+			releaseNamespace, nil, logger)
+		if err != nil {
+			return "", "", err
+		}
+		releaseNamespace = strings.TrimSpace(releaseNamespace)
+		if errs := validation.IsDNS1123Label(releaseNamespace); len(errs) != 0 {
+			return "", "", &NonRetriableError{
+				Message: fmt.Sprintf("releaseNamespace %q instantiated to invalid %q: %s", helmChart.ReleaseNamespace,
+					releaseNamespace, strings.Join(errs, ", "))}
+		}
+	}
+
+	return releaseName, releaseNamespace, nil
+}
+
+// hasHelmReleaseTemplates returns true if any helm chart ReleaseName or ReleaseNamespace is a template
+func hasHelmReleaseTemplates(clusterSummary *configv1beta1.ClusterSummary) bool {
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		if isHelmReleaseTemplate(&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// getInstantiatedHelmCharts returns the helm charts referenced by clusterSummary, with ReleaseName and
+// ReleaseNamespace instantiated for the cluster clusterSummary is for. clusterSummary is not modified.
+func getInstantiatedHelmCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) ([]configv1beta1.HelmChart, error) {
+
+	if !hasHelmReleaseTemplates(clusterSummary) {
+		return clusterSummary.Spec.ClusterProfileSpec.HelmCharts, nil
+	}
+
+	helmCharts := make([]configv1beta1.HelmChart, len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts))
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i].DeepCopyInto(&helmCharts[i])
+		if !isHelmReleaseTemplate(&helmCharts[i]) {
+			continue
+		}
+
+		var err error
+		helmCharts[i].ReleaseName, helmCharts[i].ReleaseNamespace, err =
+			instantiateHelmChartRelease(ctx, clusterSummary, &helmCharts[i], logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return helmCharts, nil
+}
+
+// instantiateHelmReleases returns a copy of clusterSummary with helm charts ReleaseName and ReleaseNamespace
+// instantiated for the cluster clusterSummary is for. Helm releases are always identified (helm chart
+// registrations, ClusterSummary Status) by their instantiated name and namespace.
+// If no ReleaseName/ReleaseNamespace is a template, clusterSummary is returned.
+func instantiateHelmReleases(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) (*configv1beta1.ClusterSummary, error) {
+
+	if !hasHelmReleaseTemplates(clusterSummary) {
+		return clusterSummary, nil
+	}
+
+	helmCharts, err := getInstantiatedHelmCharts(ctx, clusterSummary, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	instantiated := clusterSummary.DeepCopy()
+	instantiated.Spec.ClusterProfileSpec.HelmCharts = helmCharts
+	return instantiated, nil
+}
+
 func getHelmReferenceResourceHash(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
 	helmChart *configv1beta1.HelmChart, logger logr.Logger) (string, error) {
 
-	return getValuesFromResourceHash(ctx, c, clusterSummary, helmChart.ValuesFrom, logger)
+	hash, err := getValuesFromResourceHash(ctx, c, clusterSummary, helmChart.ValuesFrom, logger)
+	if err != nil {
+		return "", err
+	}
+
+	presetsHash, err := getValuesPresetsHash(ctx, c, helmChart)
+	if err != nil {
+		return "", err
+	}
+	hash += presetsHash
+
+	// Any new revision of the Flux source containing the chart must be deployed
+	if s := getFluxChartSource(helmChart); s != nil {
+		var revision string
+		revision, err = getFluxChartSourceRevision(ctx, c, s)
+		if err != nil {
+			return "", err
+		}
+		hash += revision
+	}
+
+	return hash, nil
 }
 
 func getHelmRefs(clusterSummary *configv1beta1.ClusterSummary) []configv1beta1.PolicyRef {
@@ -452,9 +679,25 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 		return err
 	}
 
-	releaseReports, chartDeployed, deployError := walkChartsAndDeploy(ctx, c, clusterSummary, kubeconfig, logger)
+	// Versions of helm charts with a dynamic VersionPolicy are resolved once. From here on, ClusterSummary
+	// references the chart versions to deploy.
+	clusterSummary, resolveErrors, err := resolveHelmChartVersions(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	releaseReports, chartDeployed, deployError := walkChartsAndDeploy(ctx, c, clusterSummary, resolveErrors,
+		kubeconfig, logger)
+	if isDeploymentSupersededError(deployError) {
+		// Status and stale releases are handled by the deployment of the latest Spec
+		return deployError
+	}
 	// Even if there is a deployment error do not return just yet. Update various status and clean stale resources.
 
+	// Helm storage of releases not referenced anymore is only recorded in the Status entries
+	// removed below. Collect it first so those releases can still be found and uninstalled.
+	releaseStorages := getHelmReleaseStorages(clusterSummary)
+
 	// If there was an helm release previous managed by this ClusterSummary and currently not referenced
 	// anymore, such helm release has been successfully remove at this point. So
 	clusterSummary, err = updateStatusForNonReferencedHelmReleases(ctx, c, clusterSummary)
@@ -465,7 +708,7 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 	// not referenced anymore. Only if this operation succeeds, removes all stale
 	// helm release registration for this clusterSummary.
 	var undeployedReports []configv1beta1.ReleaseReport
-	undeployedReports, err = undeployStaleReleases(ctx, c, clusterSummary, kubeconfig, logger)
+	undeployedReports, err = undeployStaleReleases(ctx, c, clusterSummary, releaseStorages, kubeconfig, logger)
 	if err != nil {
 		return err
 	}
@@ -500,10 +743,35 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 	return nil
 }
 
+// deployChart deploys (install, upgrade or uninstall) requestedChart. Returns the helm chart
+// actually deployed, which differs from requestedChart when the chart is pulled from a Flux source
+// or a registry mirror. On error, requestedChart is returned.
+func deployChart(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	mgmtResources map[string]*unstructured.Unstructured, requestedChart *configv1beta1.HelmChart,
+	kubeconfig string, logger logr.Logger) (*configv1beta1.HelmChart, *releaseInfo, *configv1beta1.ReleaseReport, error) {
+
+	// Charts referenced with a SourceRef are deployed from the referenced Flux source
+	currentChart, err := resolveChartSourceRef(ctx, c, requestedChart)
+	if err != nil {
+		return requestedChart, nil, nil, err
+	}
+	// In disconnected environments, charts are pulled from the registry mirrors
+	currentChart = getMirroredChart(currentChart)
+
+	currentRelease, report, err := handleChart(ctx, clusterSummary, mgmtResources, currentChart, kubeconfig, logger)
+	if err != nil {
+		return requestedChart, nil, nil, err
+	}
+
+	return currentChart, currentRelease, report, nil
+}
+
 // walkChartsAndDeploy walks all referenced helm charts. Deploys (install or upgrade) any chart
-// this clusterSummary is registered to manage.
+// this clusterSummary is registered to manage. Charts whose version could not be resolved
+// (resolveErrors) are reported as failed.
 func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
-	kubeconfig string, logger logr.Logger) ([]configv1beta1.ReleaseReport, []configv1beta1.Chart, error) {
+	resolveErrors map[string]error, kubeconfig string, logger logr.Logger,
+) ([]configv1beta1.ReleaseReport, []configv1beta1.Chart, error) {
 
 	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
 	if err != nil {
@@ -516,10 +784,16 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 	}
 
 	conflictErrorMessage := ""
+	deployErrors := &deploymentErrors{}
 	releaseReports := make([]configv1beta1.ReleaseReport, 0)
 	chartDeployed := make([]configv1beta1.Chart, 0)
 	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
 		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		chartInfo := fmt.Sprintf("helm chart %s/%s", currentChart.ReleaseNamespace, currentChart.ReleaseName)
+		// Do not deploy charts of a superseded ClusterSummary Spec. Latest Spec is deployed next.
+		if err = checkDeploymentSuperseded(ctx); err != nil {
+			return releaseReports, chartDeployed, err
+		}
 		// Eventual conflicts are already resolved before this method is called (in updateStatusForeferencedHelmReleases)
 		// So it is safe to call CanManageChart here
 		if !chartManager.CanManageChart(clusterSummary, currentChart) {
@@ -529,7 +803,7 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 				return releaseReports, chartDeployed, err
 			}
 			releaseReports = append(releaseReports, *report)
-			conflictErrorMessage += generateConflictForHelmChart(ctx, clusterSummary, currentChart)
+			conflictErrorMessage += generateConflictForHelmChart(ctx, clusterSummary, currentChart, logger)
 			// error is reported above, in updateHelmChartStatus.
 			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict ||
 				clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
@@ -544,13 +818,36 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 				&NonRetriableError{Message: conflictErrorMessage}
 		}
 
+		if resolveErr, ok := resolveErrors[fmt.Sprintf("%s/%s", currentChart.ReleaseNamespace,
+			currentChart.ReleaseName)]; ok {
+
+			if updateErr := updateFailureOnHelmChartSummary(ctx, currentChart, clusterSummary, resolveErr); updateErr != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record failure: %v", updateErr))
+			}
+			deployErrors.add(chartInfo, resolveErr)
+			continue
+		}
+
 		var report *configv1beta1.ReleaseReport
 		var currentRelease *releaseInfo
-		currentRelease, report, err = handleChart(ctx, clusterSummary, mgmtResources, currentChart, kubeconfig, logger)
+		currentChart, currentRelease, report, err = deployChart(ctx, c, clusterSummary, mgmtResources,
+			currentChart, kubeconfig, logger)
 		if err != nil {
+			if isHelmRollbackError(err) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("upgrade of %s failed and was rolled back: %v", chartInfo, err))
+			}
+			if updateErr := updateFailureOnHelmChartSummary(ctx, currentChart, clusterSummary, err); updateErr != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record failure: %v", updateErr))
+			}
+			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to deploy %s: %v. Continuing.", chartInfo, err))
+				deployErrors.add(chartInfo, err)
+				continue
+			}
 			return releaseReports, chartDeployed, err
 		}
-		err = updateValueHashOnHelmChartSummary(ctx, currentChart, clusterSummary, logger)
+		err = updateValueHashOnHelmChartSummary(ctx, currentChart, clusterSummary, getDeployedVersion(currentRelease),
+			logger)
 		if err != nil {
 			return releaseReports, chartDeployed, err
 		}
@@ -562,7 +859,7 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 				currentRelease.ReleaseNamespace, currentRelease.ReleaseName, currentRelease.ChartVersion, currentRelease.Status))
 			if currentRelease.Status == release.StatusDeployed.String() {
 				// Deployed chart is used for updating ClusterConfiguration. There is no ClusterConfiguration for mgmt cluster
-				chartDeployed = append(chartDeployed, configv1beta1.Chart{
+				deployed := configv1beta1.Chart{
 					RepoURL:         currentChart.RepositoryURL,
 					Namespace:       currentRelease.ReleaseNamespace,
 					ReleaseName:     currentRelease.ReleaseName,
@@ -570,11 +867,22 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 					AppVersion:      currentRelease.AppVersion,
 					LastAppliedTime: &currentRelease.Updated,
 					Icon:            currentRelease.Icon,
-				})
+				}
+				// Release notes and computed values are informational. Failing to record them
+				// does not fail the deployment
+				if err := setChartReleaseDetails(&deployed, currentRelease); err != nil {
+					logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record release notes and values: %v", err))
+				}
+				chartDeployed = append(chartDeployed, deployed)
 			}
 		}
 	}
 
+	// Failures are retried while conflicts are not. So report failures first.
+	if err = deployErrors.toError(len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts)); err != nil {
+		return releaseReports, chartDeployed, err
+	}
+
 	if conflictErrorMessage != "" {
 		// for helm chart a conflict is a non retriable error.
 		// when profile currently managing the helm chart is removed, all
@@ -585,7 +893,11 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 	return releaseReports, chartDeployed, nil
 }
 
-func generateConflictForHelmChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary, currentChart *configv1beta1.HelmChart) string {
+// generateConflictForHelmChart returns the conflict message for an helm chart managed by a different
+// ClusterSummary. Conflict, along with the profile managing the helm chart, is also tracked in ctx.
+func generateConflictForHelmChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart, logger logr.Logger) string {
+
 	c := getManagementClusterClient()
 
 	message := fmt.Sprintf("cannot manage chart %s/%s.", currentChart.ReleaseNamespace, currentChart.ReleaseName)
@@ -601,6 +913,9 @@ func generateConflictForHelmChart(ctx context.Context, clusterSummary *configv1b
 		return message
 	}
 	message += fmt.Sprintf(" ClusterSummary %s managing it.\n", managerName)
+
+	trackConflict(ctx, getHelmChartConflict(ctx, c, clusterSummary, currentChart, managerName,
+		fmt.Sprintf("ClusterSummary %s managing it", managerName), logger))
 	return message
 }
 
@@ -645,7 +960,14 @@ func determineChartOwnership(ctx context.Context, c client.Client, claimingHelmM
 			return false, err
 		}
 
-		if hasHigherOwnershipPriority(currentHelmManager.Spec.ClusterProfileSpec.Tier, claimingHelmManager.Spec.ClusterProfileSpec.Tier) {
+		// A profile adopting resources from the profile currently managing the chart takes it over.
+		// The adopted profile can never take it back.
+		if isAdoptingFrom(currentHelmManager, claimingHelmManager) {
+			return false, nil
+		}
+
+		if isAdoptingFrom(claimingHelmManager, currentHelmManager) ||
+			hasHigherOwnershipPriority(currentHelmManager.Spec.ClusterProfileSpec.Tier, claimingHelmManager.Spec.ClusterProfileSpec.Tier) {
 			// New ClusterSummary is taking over managing this chart. So reset helmReleaseSummaries for this chart
 			// This needs to happen immediately. helmReleaseSummaries are used by Sveltos to rebuild list of which
 			// clusterSummary is managing an helm chart if pod restarts
@@ -768,6 +1090,12 @@ func handleChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSumma
 	mgmtResources map[string]*unstructured.Unstructured, currentChart *configv1beta1.HelmChart,
 	kubeconfig string, logger logr.Logger) (*releaseInfo, *configv1beta1.ReleaseReport, error) {
 
+	storageOptions, err := getHelmStorageOptions(ctx, getManagementClusterClient(),
+		clusterSummary.Spec.ClusterNamespace, getHelmStorageValue(currentChart.Options))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, getManagementClusterClient(),
 		clusterSummary.Spec.ClusterNamespace, currentChart)
 	if err != nil {
@@ -785,8 +1113,17 @@ func handleChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSumma
 		credentialsPath: credentialsPath, caPath: caPath,
 		skipTLSVerify: getInsecureSkipTLSVerify(currentChart),
 		plainHTTP:     getPlainHTTP(currentChart),
+		storage:       storageOptions,
 	}
 
+	err = setHelmRepositoryAuth(ctx, getManagementClusterClient(), clusterSummary.Spec.ClusterNamespace,
+		currentChart, registryOptions)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to process repository credentials %v", err))
+		return nil, nil, err
+	}
+	defer removeHelmRepositoryAuthFiles(registryOptions)
+
 	currentRelease, err := getReleaseInfo(currentChart.ReleaseName,
 		currentChart.ReleaseNamespace, kubeconfig, registryOptions, getEnableClientCacheValue(currentChart.Options))
 	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
@@ -801,7 +1138,8 @@ func handleChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSumma
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("current installed version %s", currentChart.ChartVersion))
 	}
 
-	if registryOptions.credentialsPath != "" {
+	// HTTP helm repositories are authenticated with the repository entry (setHelmRepositoryAuth)
+	if registryOptions.credentialsPath != "" && registry.IsOCI(currentChart.RepositoryURL) {
 		credentialSecretNamespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
 			currentChart.RegistryCredentialsConfig.CredentialsSecretRef.Namespace)
 		err = doLogin(ctx, getManagementClusterClient(), registryOptions, currentChart.ReleaseNamespace,
@@ -813,6 +1151,9 @@ func handleChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSumma
 		}
 	}
 
+	// Release as deployed before any action. Used to report values changed by an upgrade
+	previousRelease := currentRelease
+
 	if shouldInstall(currentRelease, currentChart) {
 		report, err = handleInstall(ctx, clusterSummary, mgmtResources, currentChart, kubeconfig,
 			registryOptions, logger)
@@ -855,20 +1196,57 @@ func handleChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSumma
 		}
 	}
 
+	if getRunHelmTestsValue(currentChart.Options) && currentChart.HelmChartAction != configv1beta1.HelmChartActionUninstall &&
+		!isDryRunSync(clusterSummary) {
+
+		err = runHelmTests(currentChart, kubeconfig, registryOptions, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	currentRelease, err = getReleaseInfo(currentChart.ReleaseName, currentChart.ReleaseNamespace, kubeconfig,
 		registryOptions, getEnableClientCacheValue(currentChart.Options))
 	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
 		return nil, nil, err
 	}
 
+	if report.Action == string(configv1beta1.UpgradeHelmAction) && previousRelease != nil && currentRelease != nil {
+		recordValuesDiff(ctx, currentChart, clusterSummary, previousRelease, currentRelease, logger)
+	}
+
 	return currentRelease, report, nil
 }
 
+// recordValuesDiff reports, on the ClusterSummary status, how an upgrade changed the values
+// the helm release is deployed with. Upgrades not changing values leave the last reported diff.
+// The diff is informational, so failures are only logged.
+func recordValuesDiff(ctx context.Context, requestedChart *configv1beta1.HelmChart,
+	clusterSummary *configv1beta1.ClusterSummary, previousRelease, currentRelease *releaseInfo, logger logr.Logger) {
+
+	valuesDiff, err := getValuesDiff(previousRelease.ComputedValues, currentRelease.ComputedValues)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to compute values diff: %v", err))
+		return
+	}
+	if valuesDiff == "" {
+		return
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("helm release upgraded with values changes:\n%s", valuesDiff))
+	err = updateValuesDiffOnHelmChartSummary(ctx, requestedChart, clusterSummary, valuesDiff)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to record values diff: %v", err))
+	}
+}
+
 // repoAddOrUpdate adds/updates repo with given name and url
-func repoAddOrUpdate(settings *cli.EnvSettings, name, repoURL string, logger logr.Logger) error {
+func repoAddOrUpdate(settings *cli.EnvSettings, name, repoURL string, registryOptions *registryClientOptions,
+	logger logr.Logger) error {
+
 	logger = logger.WithValues("repoURL", repoURL, "repoName", name)
 
-	entry := &repo.Entry{Name: name, URL: repoURL}
+	entry := getHelmRepositoryEntry(name, repoURL, registryOptions)
 	chartRepo, err := repo.NewChartRepository(entry, getter.All(settings))
 	if err != nil {
 		return err
@@ -876,7 +1254,9 @@ func repoAddOrUpdate(settings *cli.EnvSettings, name, repoURL string, logger log
 
 	chartRepo.CachePath = settings.RepositoryCache
 
-	if storage.Has(entry.Name) {
+	// Credentials and certificates are stored in temporary files, so an entry with
+	// credentials is updated every time
+	if reflect.DeepEqual(storage.Get(entry.Name), entry) {
 		logger.V(logs.LogDebug).Info("repository name already exists")
 		return nil
 	}
@@ -926,22 +1306,40 @@ func installRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 		registryOptions.caPath, "insecure", registryOptions.skipTLSVerify)
 	logger.V(logs.LogDebug).Info("installing release")
 
-	patches, err := initiatePatches(ctx, clusterSummary, requestedChart.ChartName, mgmtResources, logger)
+	patches, err := getHelmChartPatches(ctx, clusterSummary, requestedChart, mgmtResources, logger)
 	if err != nil {
 		return err
 	}
 
-	installClient, err := getHelmInstallClient(requestedChart, kubeconfig, registryOptions, patches)
+	actionConfig, err := actionConfigInit(requestedChart.ReleaseNamespace, kubeconfig, registryOptions,
+		getEnableClientCacheValue(requestedChart.Options))
+	if err != nil {
+		return err
+	}
+
+	installClient, err := getHelmInstallClient(ctx, requestedChart, actionConfig, patches, getTenant(clusterSummary))
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get helm install client: %v", err))
 		return err
 	}
+	installClient.PostRenderer = getImageDigestsPostRenderer(ctx, installClient.PostRenderer)
 
-	cp, err := installClient.ChartPathOptions.LocateChart(chartName, settings)
+	cleanupVerification, err := prepareChartVerification(ctx, clusterSummary, requestedChart, chartName,
+		&installClient.ChartPathOptions, registryOptions, logger)
 	if err != nil {
-		logger.V(logs.LogDebug).Info("LocateChart failed")
 		return err
 	}
+	defer cleanupVerification()
+
+	cp, tmpDir, err := locateChart(ctx, clusterSummary.Spec.ClusterNamespace, requestedChart,
+		&installClient.ChartPathOptions, chartName, settings, logger)
+	if err != nil {
+		logger.V(logs.LogDebug).Info("LocateChart failed")
+		return getChartVerificationError(requestedChart, err)
+	}
+	if tmpDir != "" {
+		defer os.RemoveAll(tmpDir)
+	}
 
 	chartRequested, err := loader.Load(cp)
 	if err != nil {
@@ -949,21 +1347,30 @@ func installRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 		return err
 	}
 
+	err = validateFluxChartVersion(requestedChart, chartRequested)
+	if err != nil {
+		return err
+	}
+
 	validInstallableChart := isChartInstallable(chartRequested)
 	if !validInstallableChart {
 		return fmt.Errorf("chart is not installable")
 	}
 
-	if getDependenciesUpdateValue(requestedChart.Options) {
-		err = checkDependencies(chartRequested, installClient, cp, settings)
-		if err != nil {
-			return err
-		}
+	chartRequested, err = resolveChartDependencies(ctx, getManagementClusterClient(), clusterSummary, requestedChart,
+		chartRequested, cp, settings, registryOptions, installClient.ChartPathOptions.Keyring, logger)
+	if err != nil {
+		return err
 	}
 
-	// Reload the chart with the updated Chart.lock file.
-	if chartRequested, err = loader.Load(cp); err != nil {
-		return fmt.Errorf("%w: failed reloading chart after repo update", err)
+	var crds []chart.CRD
+	if !installClient.SkipCRDs {
+		crds = chartRequested.CRDObjects()
+	}
+	err = validateRenderedRelease(ctx, clusterSummary, requestedChart, actionConfig, chartRequested, values,
+		installClient.PostRenderer, false, crds)
+	if err != nil {
+		return err
 	}
 
 	installClient.DryRun = false
@@ -977,32 +1384,6 @@ func installRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 	return nil
 }
 
-func checkDependencies(chartRequested *chart.Chart, installClient *action.Install, cp string, settings *cli.EnvSettings) error {
-	if req := chartRequested.Metadata.Dependencies; req != nil {
-		err := action.CheckDependencies(chartRequested, req)
-		if err != nil {
-			if installClient.DependencyUpdate {
-				man := &downloader.Manager{
-					ChartPath:        cp,
-					Keyring:          installClient.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          getter.All(settings),
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-				}
-				err = man.Update()
-				if err != nil {
-					return err
-				}
-			} else {
-				return nil
-			}
-		}
-	}
-
-	return nil
-}
-
 // uninstallRelease removes helm release from a CAPI Cluster.
 // No action in DryRun mode.
 func uninstallRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
@@ -1042,10 +1423,7 @@ func uninstallRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 		return err
 	}
 
-	uninstallClient, err := getHelmUninstallClient(helmChart, actionConfig)
-	if err != nil {
-		return err
-	}
+	uninstallClient := getHelmUninstallClient(helmChart, actionConfig)
 
 	_, err = uninstallClient.Run(releaseName)
 	if err != nil {
@@ -1093,43 +1471,73 @@ func upgradeRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 
 	driftExclusionPatches := transformDriftExclusionsToPatches(clusterSummary.Spec.ClusterProfileSpec.DriftExclusions)
 
-	patches, err := initiatePatches(ctx, clusterSummary, requestedChart.ChartName, mgmtResources, logger)
+	patches, err := getHelmChartPatches(ctx, clusterSummary, requestedChart, mgmtResources, logger)
 	if err != nil {
 		return err
 	}
 
 	patches = append(patches, driftExclusionPatches...)
 
-	upgradeClient, err := getHelmUpgradeClient(requestedChart, actionConfig, patches)
+	upgradeClient, err := getHelmUpgradeClient(ctx, requestedChart, actionConfig, patches, getTenant(clusterSummary))
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get helm upgrade client: %v", err))
 		return err
 	}
+	upgradeClient.PostRenderer = getImageDigestsPostRenderer(ctx, upgradeClient.PostRenderer)
 
-	cp, err := upgradeClient.ChartPathOptions.LocateChart(chartName, settings)
+	cleanupVerification, err := prepareChartVerification(ctx, clusterSummary, requestedChart, chartName,
+		&upgradeClient.ChartPathOptions, registryOptions, logger)
 	if err != nil {
 		return err
 	}
+	defer cleanupVerification()
+
+	cp, tmpDir, err := locateChart(ctx, clusterSummary.Spec.ClusterNamespace, requestedChart,
+		&upgradeClient.ChartPathOptions, chartName, settings, logger)
+	if err != nil {
+		return getChartVerificationError(requestedChart, err)
+	}
+	if tmpDir != "" {
+		defer os.RemoveAll(tmpDir)
+	}
 
 	chartRequested, err := loader.Load(cp)
 	if err != nil {
 		return err
 	}
-	if req := chartRequested.Metadata.Dependencies; req != nil {
-		err = action.CheckDependencies(chartRequested, req)
-		if err != nil {
-			return err
-		}
-	}
 
-	upgradeClient.DryRun = false
+	err = validateFluxChartVersion(requestedChart, chartRequested)
+	if err != nil {
+		return err
+	}
+	chartRequested, err = resolveChartDependencies(ctx, getManagementClusterClient(), clusterSummary, requestedChart,
+		chartRequested, cp, settings, registryOptions, upgradeClient.ChartPathOptions.Keyring, logger)
+	if err != nil {
+		return err
+	}
 
-	err = upgradeCRDs(ctx, requestedChart, kubeconfig, chartRequested.CRDObjects(), logger)
+	// CRDs are cluster-wide resources tenants cannot deploy
+	upgradeCRDsEnabled := getTenant(clusterSummary) == "" && getUpgradeCRDs(requestedChart.Options)
+	var crds []chart.CRD
+	if upgradeCRDsEnabled {
+		crds = chartRequested.CRDObjects()
+	}
+	err = validateRenderedRelease(ctx, clusterSummary, requestedChart, actionConfig, chartRequested, values,
+		upgradeClient.PostRenderer, true, crds)
 	if err != nil {
-		logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to upgrade crds: %v", err))
 		return err
 	}
 
+	upgradeClient.DryRun = false
+
+	if upgradeCRDsEnabled {
+		err = upgradeCRDs(ctx, requestedChart, kubeconfig, chartRequested.CRDObjects(), logger)
+		if err != nil {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to upgrade crds: %v", err))
+			return err
+		}
+	}
+
 	_, err = upgradeClient.RunWithContext(ctx, requestedChart.ReleaseName, chartRequested, values)
 	if err != nil {
 		return err
@@ -1252,9 +1660,23 @@ func actionConfigInit(namespace, kubeconfig string, registryOptions *registryCli
 	insecure := true
 	configFlags.Insecure = &insecure
 
-	err := actionConfig.Init(configFlags, namespace, "secret", debugf)
-	if err != nil {
-		return nil, err
+	storageDriver, storageNamespace := getHelmStorageDriverAndNamespace(namespace, registryOptions.storage)
+	if storageDriver == configv1beta1.HelmStorageDriverSQL {
+		// helm reads the sql connection string from an env variable. Initialize with the memory
+		// driver first and then point release storage to the SQL database.
+		err := actionConfig.Init(configFlags, storageNamespace, "memory", debugf)
+		if err != nil {
+			return nil, err
+		}
+		err = setSQLStorage(actionConfig, storageNamespace, registryOptions.storage.sqlConnectionString)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := actionConfig.Init(configFlags, storageNamespace, string(storageDriver), debugf)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	registryClient, err := getRegistryClient(namespace, registryOptions, enableClientCache)
@@ -1301,6 +1723,8 @@ func getReleaseInfo(releaseName, releaseNamespace, kubeconfig string, registryOp
 		AppVersion:       results.Chart.AppVersion(),
 		ReleaseLabels:    results.Labels,
 		Icon:             results.Chart.Metadata.Icon,
+		Notes:            results.Info.Notes,
+		ComputedValues:   getReleaseComputedValues(results),
 	}
 
 	var t metav1.Time
@@ -1426,14 +1850,16 @@ func doInstallRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 
 	settings := getSettings(requestedChart.ReleaseNamespace, registryOptions)
 
-	err := repoAddOrUpdate(settings, requestedChart.RepositoryName,
-		requestedChart.RepositoryURL, logger)
-	if err != nil {
-		return err
+	// Charts stored in a Flux source are not fetched from a helm repository
+	if !isFluxChartSource(requestedChart) {
+		err := repoAddOrUpdate(settings, requestedChart.RepositoryName,
+			requestedChart.RepositoryURL, registryOptions, logger)
+		if err != nil {
+			return err
+		}
 	}
 
-	var values chartutil.Values
-	values, err = getInstantiatedValues(ctx, clusterSummary, mgmtResources, requestedChart, logger)
+	values, err := getInstantiatedValues(ctx, clusterSummary, mgmtResources, requestedChart, logger)
 	if err != nil {
 		return err
 	}
@@ -1485,14 +1911,16 @@ func doUpgradeRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 
 	settings := getSettings(requestedChart.ReleaseNamespace, registryOptions)
 
-	err := repoAddOrUpdate(settings, requestedChart.RepositoryName,
-		requestedChart.RepositoryURL, logger)
-	if err != nil {
-		return err
+	// Charts stored in a Flux source are not fetched from a helm repository
+	if !isFluxChartSource(requestedChart) {
+		err := repoAddOrUpdate(settings, requestedChart.RepositoryName,
+			requestedChart.RepositoryURL, registryOptions, logger)
+		if err != nil {
+			return err
+		}
 	}
 
-	var values chartutil.Values
-	values, err = getInstantiatedValues(ctx, clusterSummary, mgmtResources, requestedChart, logger)
+	values, err := getInstantiatedValues(ctx, clusterSummary, mgmtResources, requestedChart, logger)
 	if err != nil {
 		return err
 	}
@@ -1526,9 +1954,12 @@ func updateChartsInClusterConfiguration(ctx context.Context, c client.Client, cl
 	return updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, configv1beta1.FeatureHelm, nil, chartDeployed)
 }
 
-// undeployStaleReleases uninstalls all helm charts previously managed and not referenced anyomre
+// undeployStaleReleases uninstalls all helm charts previously managed and not referenced anyomre.
+// releaseStorages contains the helm storage (key: releaseNamespace/releaseName) of releases not
+// using the default one.
 func undeployStaleReleases(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
-	kubeconfig string, logger logr.Logger) ([]configv1beta1.ReleaseReport, error) {
+	releaseStorages map[string]*configv1beta1.HelmStorage, kubeconfig string, logger logr.Logger,
+) ([]configv1beta1.ReleaseReport, error) {
 
 	chartManager, err := chartmanager.GetChartManagerInstance(ctx, c)
 	if err != nil {
@@ -1552,8 +1983,15 @@ func undeployStaleReleases(ctx context.Context, c client.Client, clusterSummary
 			logger.V(logs.LogInfo).Info(fmt.Sprintf("helm release %s (namespace %s) used to be managed but not referenced anymore",
 				managedHelmReleases[i].Name, managedHelmReleases[i].Namespace))
 
-			_, err := getReleaseInfo(managedHelmReleases[i].Name,
-				managedHelmReleases[i].Namespace, kubeconfig, &registryClientOptions{}, false)
+			storageOptions, err := getHelmStorageOptions(ctx, c, clusterSummary.Spec.ClusterNamespace,
+				releaseStorages[fmt.Sprintf("%s/%s", managedHelmReleases[i].Namespace, managedHelmReleases[i].Name)])
+			if err != nil {
+				return nil, err
+			}
+			registryOptions := &registryClientOptions{storage: storageOptions}
+
+			_, err = getReleaseInfo(managedHelmReleases[i].Name,
+				managedHelmReleases[i].Namespace, kubeconfig, registryOptions, false)
 			if err != nil {
 				if errors.Is(err, driver.ErrReleaseNotFound) {
 					continue
@@ -1562,7 +2000,7 @@ func undeployStaleReleases(ctx context.Context, c client.Client, clusterSummary
 			}
 
 			if err := uninstallRelease(ctx, clusterSummary, managedHelmReleases[i].Name,
-				managedHelmReleases[i].Namespace, kubeconfig, &registryClientOptions{}, nil,
+				managedHelmReleases[i].Namespace, kubeconfig, registryOptions, nil,
 				logger); err != nil {
 				return nil, err
 			}
@@ -1584,6 +2022,8 @@ func undeployStaleReleases(ctx context.Context, c client.Client, clusterSummary
 // - an error if any occurs
 // - whether there is at least one helm release ClusterSummary is referencing, but currently not
 // allowed to manage.
+// Returned ClusterSummary is a copy of clusterSummary with the updated Status. It must never be used
+// to update ClusterSummary Spec.
 // No action in DryRun mode.
 func updateStatusForeferencedHelmReleases(ctx context.Context, c client.Client,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) (*configv1beta1.ClusterSummary, bool, error) {
@@ -1620,9 +2060,16 @@ func updateStatusForeferencedHelmReleases(ctx context.Context, c client.Client,
 			return err
 		}
 
-		helmReleaseSummaries := make([]configv1beta1.HelmChartSummary, len(currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts))
-		for i := range currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts {
-			currentChart := &currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		// Helm releases are identified by their instantiated name and namespace
+		var helmCharts []configv1beta1.HelmChart
+		helmCharts, err = getInstantiatedHelmCharts(ctx, currentClusterSummary, logger)
+		if err != nil {
+			return err
+		}
+
+		helmReleaseSummaries := make([]configv1beta1.HelmChartSummary, len(helmCharts))
+		for i := range helmCharts {
+			currentChart := &helmCharts[i]
 			var canManage bool
 			canManage, err = determineChartOwnership(ctx, c, clusterSummary, currentChart, logger)
 			if err != nil {
@@ -1635,6 +2082,19 @@ func updateStatusForeferencedHelmReleases(ctx context.Context, c client.Client,
 					Status:           configv1beta1.HelmChartStatusManaging,
 					ValuesHash:       getValueHashFromHelmChartSummary(currentChart, clusterSummary), // if a value is currently stored, keep it.
 					// after chart is deployed such value will be updated
+					Storage: getHelmStorageValue(currentChart.Options),
+				}
+				// Keep what was recorded while resolving and deploying the chart. Those fields are updated
+				// by later steps and would otherwise be lost on every reconciliation.
+				if previous := getHelmChartSummary(currentClusterSummary, currentChart); previous != nil &&
+					previous.Status == configv1beta1.HelmChartStatusManaging {
+
+					helmReleaseSummaries[i].ResolvedVersion = previous.ResolvedVersion
+					helmReleaseSummaries[i].PendingUpgradeVersion = previous.PendingUpgradeVersion
+					helmReleaseSummaries[i].DeployedVersion = previous.DeployedVersion
+					helmReleaseSummaries[i].FailureMessage = previous.FailureMessage
+					helmReleaseSummaries[i].RollbackMessage = previous.RollbackMessage
+					helmReleaseSummaries[i].ValuesDiff = previous.ValuesDiff
 				}
 				currentlyReferenced[helmInfo(currentChart.ReleaseNamespace, currentChart.ReleaseName)] = true
 			} else {
@@ -1671,11 +2131,19 @@ func updateStatusForeferencedHelmReleases(ctx context.Context, c client.Client,
 
 		return c.Status().Update(ctx, currentClusterSummary)
 	})
-	return currentClusterSummary, conflict, err
+	if err != nil {
+		return clusterSummary, conflict, err
+	}
+
+	updatedClusterSummary := clusterSummary.DeepCopy()
+	updatedClusterSummary.Status = currentClusterSummary.Status
+	return updatedClusterSummary, conflict, nil
 }
 
 // updateStatusForNonReferencedHelmReleases walks ClusterSummary.Status entries.
 // Removes any entry pointing to a helm release currently not referenced by ClusterSummary.
+// Returned ClusterSummary is a copy of clusterSummary with the updated Status. It must never be used
+// to update ClusterSummary Spec.
 // No action in DryRun mode.
 func updateStatusForNonReferencedHelmReleases(ctx context.Context, c client.Client,
 	clusterSummary *configv1beta1.ClusterSummary) (*configv1beta1.ClusterSummary, error) {
@@ -1723,7 +2191,9 @@ func updateStatusForNonReferencedHelmReleases(ctx context.Context, c client.Clie
 		return clusterSummary, err
 	}
 
-	return currentClusterSummary, nil
+	updatedClusterSummary := clusterSummary.DeepCopy()
+	updatedClusterSummary.Status = currentClusterSummary.Status
+	return updatedClusterSummary, nil
 }
 
 // getHelmChartConflictManager returns a message listing ClusterProfile managing an helm chart.
@@ -1795,6 +2265,11 @@ func updateClusterReportWithHelmReports(ctx context.Context, c client.Client,
 	clusterReportName := getClusterReportName(profileOwnerRef.Kind, profileOwnerRef.Name,
 		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 
+	releaseReports, err = compressReleaseReports(releaseReports)
+	if err != nil {
+		return err
+	}
+
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		clusterReport := &configv1beta1.ClusterReport{}
 		err = c.Get(ctx,
@@ -1803,6 +2278,11 @@ func updateClusterReportWithHelmReports(ctx context.Context, c client.Client,
 			return err
 		}
 
+		if sameReports(clusterReport.Status.ReleaseReports, releaseReports) {
+			return nil
+		}
+
+		archiveClusterReport(clusterReport, time.Now())
 		clusterReport.Status.ReleaseReports = releaseReports
 		return c.Status().Update(ctx, clusterReport)
 	})
@@ -1814,43 +2294,81 @@ func getInstantiatedValues(ctx context.Context, clusterSummary *configv1beta1.Cl
 	mgmtResources map[string]*unstructured.Unstructured, requestedChart *configv1beta1.HelmChart,
 	logger logr.Logger) (chartutil.Values, error) {
 
-	instantiatedValues, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-		clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+	instantiatedValues, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
 		requestedChart.ChartName, requestedChart.Values, mgmtResources, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("Deploying helm charts with Values %q", instantiatedValues))
+
+	values, err := chartutil.ReadValues([]byte(instantiatedValues))
+	if err != nil {
+		return nil, err
+	}
+
 	c := getManagementClusterClient()
-	templatedValuesFrom, valuesFrom, err := getHelmChartValuesFrom(ctx, c, clusterSummary, requestedChart, logger)
+	// ValuesFrom are merged over Values in the order they are listed
+	for i := range requestedChart.ValuesFrom {
+		values, err = mergeHelmChartValuesFrom(ctx, c, clusterSummary, mgmtResources, requestedChart,
+			&requestedChart.ValuesFrom[i], values, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(requestedChart.ValuesPresets) == 0 {
+		return values, nil
+	}
+
+	// Values and ValuesFrom override the values of referenced HelmValuesPresets
+	presets, err := getValuesPresets(ctx, c, clusterSummary, mgmtResources, requestedChart, logger)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.CoalesceTables(values, presets), nil
+}
+
+// mergeHelmChartValuesFrom merges the values stored in the referenced ConfigMap/Secret over values.
+// Keys within the ConfigMap/Secret are merged in alphabetical order, each overriding the previous ones.
+func mergeHelmChartValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	mgmtResources map[string]*unstructured.Unstructured, requestedChart *configv1beta1.HelmChart,
+	valueFrom *configv1beta1.ValueFrom, values chartutil.Values, logger logr.Logger) (chartutil.Values, error) {
+
+	templatedValuesFrom, valuesFrom, err := getValuesFrom(ctx, c, clusterSummary,
+		[]configv1beta1.ValueFrom{*valueFrom}, false, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	for k := range templatedValuesFrom {
-		instantiatedValuesFrom, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-			clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		valuesFrom[k], err = instantiateClusterSummaryTemplate(ctx, clusterSummary,
 			requestedChart.ChartName, templatedValuesFrom[k], mgmtResources, logger)
 		if err != nil {
 			return nil, err
 		}
-		instantiatedValues += fmt.Sprintf("\n\n%s", instantiatedValuesFrom)
 	}
 
+	return mergeValues(values, valuesFrom)
+}
+
+// mergeValues merges, in alphabetical key order, each entry of valuesFrom over values.
+func mergeValues(values chartutil.Values, valuesFrom map[string]string) (chartutil.Values, error) {
+	keys := make([]string, 0, len(valuesFrom))
 	for k := range valuesFrom {
-		instantiatedValues += fmt.Sprintf("\n\n%s", valuesFrom[k])
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	logger.V(logs.LogDebug).Info(fmt.Sprintf("Deploying helm charts with Values %q", instantiatedValues))
-
-	return chartutil.ReadValues([]byte(instantiatedValues))
-}
-
-// getHelmChartValuesFrom return key-value pair from referenced ConfigMap/Secret
-func getHelmChartValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
-	helmChart *configv1beta1.HelmChart, logger logr.Logger) (templatedValues, nonTemplatedValues map[string]string, err error) {
+	for i := range keys {
+		current, err := chartutil.ReadValues([]byte(valuesFrom[keys[i]]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse values from key %s: %w", keys[i], err)
+		}
+		values = chartutil.MergeTables(current, values)
+	}
 
-	return getValuesFrom(ctx, c, clusterSummary, helmChart.ValuesFrom, false, logger)
+	return values, nil
 }
 
 // collectResourcesFromManagedHelmChartsForDriftDetection collects resources considering all
@@ -1874,6 +2392,12 @@ func collectResourcesFromManagedHelmChartsForDriftDetection(ctx context.Context,
 		l.V(logs.LogDebug).Info("collecting resources for helm chart")
 		// Conflicts are already resolved by the time this is invoked. So it is safe to call CanManageChart
 		if chartManager.CanManageChart(clusterSummary, currentChart) {
+			storageOptions, err := getHelmStorageOptions(ctx, c, clusterSummary.Spec.ClusterNamespace,
+				getHelmStorageValue(currentChart.Options))
+			if err != nil {
+				return nil, err
+			}
+
 			credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, c,
 				clusterSummary.Spec.ClusterNamespace, currentChart)
 			if err != nil {
@@ -1885,6 +2409,7 @@ func collectResourcesFromManagedHelmChartsForDriftDetection(ctx context.Context,
 				credentialsPath: credentialsPath, caPath: caPath,
 				skipTLSVerify: getInsecureSkipTLSVerify(currentChart),
 				plainHTTP:     getPlainHTTP(currentChart),
+				storage:       storageOptions,
 			}
 
 			actionConfig, err := actionConfigInit(currentChart.ReleaseNamespace, kubeconfig, registryOptions,
@@ -2083,6 +2608,35 @@ func getTimeoutValue(options *configv1beta1.HelmOptions) *metav1.Duration {
 	return nil
 }
 
+func getRunHelmTestsValue(options *configv1beta1.HelmOptions) bool {
+	if options != nil {
+		return options.RunHelmTests
+	}
+
+	return false
+}
+
+// getHelmTestsTimeoutValue returns the time each chart test is given to complete
+func getHelmTestsTimeoutValue(options *configv1beta1.HelmOptions) time.Duration {
+	if options != nil && options.HelmTestsTimeout != nil {
+		return options.HelmTestsTimeout.Duration
+	}
+
+	if timeout := getTimeoutValue(options); timeout != nil {
+		return timeout.Duration
+	}
+
+	return defaultHelmTestTimeout
+}
+
+func getRollbackOnHelmTestsFailureValue(options *configv1beta1.HelmOptions) bool {
+	if options != nil {
+		return options.RollbackOnHelmTestsFailure
+	}
+
+	return false
+}
+
 func getDependenciesUpdateValue(options *configv1beta1.HelmOptions) bool {
 	if options != nil {
 		return options.DependencyUpdate
@@ -2157,13 +2711,29 @@ func getKeepHistoryValue(options *configv1beta1.HelmOptions) bool {
 }
 
 func getDeletionPropagation(options *configv1beta1.HelmOptions) string {
-	if options != nil {
+	if options != nil && options.UninstallOptions.DeletionPropagation != "" {
 		return options.UninstallOptions.DeletionPropagation
 	}
 
 	return defaultDeletionPropagation
 }
 
+func getWaitHelmUninstallValue(options *configv1beta1.HelmOptions) bool {
+	if options != nil && options.UninstallOptions.Wait != nil {
+		return *options.UninstallOptions.Wait
+	}
+
+	return getWaitHelmValue(options)
+}
+
+func getTimeoutHelmUninstallValue(options *configv1beta1.HelmOptions) *metav1.Duration {
+	if options != nil && options.UninstallOptions.Timeout != nil {
+		return options.UninstallOptions.Timeout
+	}
+
+	return getTimeoutValue(options)
+}
+
 func getMaxHistoryValue(options *configv1beta1.HelmOptions) int {
 	if options != nil {
 		return options.UpgradeOptions.MaxHistory
@@ -2196,16 +2766,14 @@ func getRecreateValue(options *configv1beta1.HelmOptions) bool {
 	return false
 }
 
-func getHelmInstallClient(requestedChart *configv1beta1.HelmChart, kubeconfig string,
-	registryOptions *registryClientOptions, patches []libsveltosv1beta1.Patch,
-) (*action.Install, error) {
+func getHelmInstallClient(ctx context.Context, requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration,
+	patches []libsveltosv1beta1.Patch, tenant string) (*action.Install, error) {
 
-	actionConfig, err := actionConfigInit(requestedChart.ReleaseNamespace, kubeconfig, registryOptions,
-		getEnableClientCacheValue(requestedChart.Options))
-	if err != nil {
+	if err := validateTenantReleaseNamespace(requestedChart, tenant); err != nil {
 		return nil, err
 	}
 
+	var err error
 	installClient := action.NewInstall(actionConfig)
 	installClient.ReleaseName = requestedChart.ReleaseName
 	installClient.Namespace = requestedChart.ReleaseNamespace
@@ -2234,12 +2802,26 @@ func getHelmInstallClient(requestedChart *configv1beta1.HelmChart, kubeconfig st
 	if len(patches) > 0 {
 		installClient.PostRenderer = &patcher.CustomPatchPostRenderer{Patches: patches}
 	}
+	installClient.PostRenderer = getWasmPluginsPostRenderer(ctx, installClient.PostRenderer)
+
+	if tenant != "" {
+		installClient.SkipCRDs = true
+		installClient.PostRenderer, err = getTenantPostRenderer(actionConfig, tenant, requestedChart.ReleaseNamespace,
+			installClient.PostRenderer)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return installClient, nil
 }
 
-func getHelmUpgradeClient(requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration,
-	patches []libsveltosv1beta1.Patch) (*action.Upgrade, error) {
+func getHelmUpgradeClient(ctx context.Context, requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration,
+	patches []libsveltosv1beta1.Patch, tenant string) (*action.Upgrade, error) {
+
+	if err := validateTenantReleaseNamespace(requestedChart, tenant); err != nil {
+		return nil, err
+	}
 
 	upgradeClient := action.NewUpgrade(actionConfig)
 	upgradeClient.Install = true
@@ -2277,29 +2859,36 @@ func getHelmUpgradeClient(requestedChart *configv1beta1.HelmChart, actionConfig
 	if len(patches) > 0 {
 		upgradeClient.PostRenderer = &patcher.CustomPatchPostRenderer{Patches: patches}
 	}
+	upgradeClient.PostRenderer = getWasmPluginsPostRenderer(ctx, upgradeClient.PostRenderer)
+
+	if tenant != "" {
+		upgradeClient.SkipCRDs = true
+		var err error
+		upgradeClient.PostRenderer, err = getTenantPostRenderer(actionConfig, tenant, requestedChart.ReleaseNamespace,
+			upgradeClient.PostRenderer)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return upgradeClient, nil
 }
 
-func getHelmUninstallClient(requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration) (*action.Uninstall, error) {
+func getHelmUninstallClient(requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration) *action.Uninstall {
 	uninstallClient := action.NewUninstall(actionConfig)
 	uninstallClient.DryRun = false
 	if requestedChart != nil {
-		if timeout := getTimeoutValue(requestedChart.Options); timeout != nil {
-			var err error
-			uninstallClient.Timeout, err = time.ParseDuration(timeout.String())
-			if err != nil {
-				return nil, err
-			}
+		if timeout := getTimeoutHelmUninstallValue(requestedChart.Options); timeout != nil {
+			uninstallClient.Timeout = timeout.Duration
 		}
 
 		uninstallClient.Description = getDescriptionValue(requestedChart.Options)
-		uninstallClient.Wait = getWaitHelmValue(requestedChart.Options)
+		uninstallClient.Wait = getWaitHelmUninstallValue(requestedChart.Options)
 		uninstallClient.DisableHooks = getDisableHooksHelmUninstallValue(requestedChart.Options)
 		uninstallClient.KeepHistory = getKeepHistoryValue(requestedChart.Options)
 		uninstallClient.DeletionPropagation = getDeletionPropagation(requestedChart.Options)
 	}
-	return uninstallClient, nil
+	return uninstallClient
 }
 
 func addExtraMetadata(ctx context.Context, requestedChart *configv1beta1.HelmChart,
@@ -2357,10 +2946,7 @@ func addExtraMetadata(ctx context.Context, requestedChart *configv1beta1.HelmCha
 			return err
 		}
 
-		addExtraLabels(r, clusterSummary.Spec.ClusterProfileSpec.ExtraLabels)
-		addExtraAnnotations(r, clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations)
-
-		err = updateResource(ctx, dr, clusterSummary, r, []string{}, logger)
+		_, err = applyExtraMetadata(ctx, dr, clusterSummary, r, logger)
 		if err != nil {
 			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update resource %s %s/%s: %v",
 				r.GetKind(), r.GetNamespace(), r.GetName(), err))
@@ -2386,6 +2972,36 @@ func getResourceNamespace(r *unstructured.Unstructured, releaseNamespace string,
 	return namespace, nil
 }
 
+// getHelmChartPatches returns the instantiated patches to apply to the manifests rendered by the
+// helm chart: profile Patches first, followed by the helm chart Patches.
+func getHelmChartPatches(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) ([]libsveltosv1beta1.Patch, error) {
+
+	profilePatches, err := initiatePatches(ctx, clusterSummary, requestedChart.ChartName, mgmtResources, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requestedChart.Patches) == 0 {
+		return profilePatches, nil
+	}
+
+	patches := make([]libsveltosv1beta1.Patch, 0, len(profilePatches)+len(requestedChart.Patches))
+	patches = append(patches, profilePatches...)
+	for i := range requestedChart.Patches {
+		patch := requestedChart.Patches[i]
+		patch.Patch, err = instantiateClusterSummaryTemplate(ctx, clusterSummary,
+			requestedChart.ChartName, patch.Patch, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches, nil
+}
+
 func getHelmChartValuesHash(ctx context.Context, c client.Client, requestedChart *configv1beta1.HelmChart,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) ([]byte, error) {
 
@@ -2397,12 +3013,18 @@ func getHelmChartValuesHash(ctx context.Context, c client.Client, requestedChart
 	h := sha256.New()
 	config := render.AsCode(requestedChart.Values)
 	config += valuesFromHash
+	// Changing the helm chart Patches requires an upgrade
+	if len(requestedChart.Patches) != 0 {
+		config += render.AsCode(requestedChart.Patches)
+	}
 	h.Write([]byte(config))
 	return h.Sum(nil), nil
 }
 
+// updateValueHashOnHelmChartSummary records, on the ClusterSummary status, the values hash and the
+// version the helm release is deployed with. Any failure previously recorded is cleared.
 func updateValueHashOnHelmChartSummary(ctx context.Context, requestedChart *configv1beta1.HelmChart,
-	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+	clusterSummary *configv1beta1.ClusterSummary, deployedVersion string, logger logr.Logger) error {
 
 	c := getManagementClusterClient()
 
@@ -2425,6 +3047,9 @@ func updateValueHashOnHelmChartSummary(ctx context.Context, requestedChart *conf
 				rs.ReleaseNamespace == requestedChart.ReleaseNamespace {
 
 				rs.ValuesHash = helmChartValuesHash
+				rs.DeployedVersion = deployedVersion
+				rs.FailureMessage = ""
+				rs.RollbackMessage = ""
 			}
 		}
 
@@ -2434,6 +3059,81 @@ func updateValueHashOnHelmChartSummary(ctx context.Context, requestedChart *conf
 	return err
 }
 
+// isHelmRollbackError returns true if err reports a failed upgrade rolled back
+// to the last successful revision
+func isHelmRollbackError(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), helmRollbackMessage) ||
+		strings.Contains(err.Error(), helmTestsRollbackMessage))
+}
+
+// getDeployedVersion returns the chart version currentRelease is deployed with.
+// Empty if the helm release is not deployed.
+func getDeployedVersion(currentRelease *releaseInfo) string {
+	if currentRelease == nil || currentRelease.Status != release.StatusDeployed.String() {
+		return ""
+	}
+	return currentRelease.ChartVersion
+}
+
+// updateFailureOnHelmChartSummary records, on the ClusterSummary status, why the last
+// deployment of the helm release failed and, if the upgrade was rolled back, why.
+func updateFailureOnHelmChartSummary(ctx context.Context, requestedChart *configv1beta1.HelmChart,
+	clusterSummary *configv1beta1.ClusterSummary, deployErr error) error {
+
+	c := getManagementClusterClient()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx,
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		for i := range currentClusterSummary.Status.HelmReleaseSummaries {
+			rs := &currentClusterSummary.Status.HelmReleaseSummaries[i]
+			if rs.ReleaseName == requestedChart.ReleaseName &&
+				rs.ReleaseNamespace == requestedChart.ReleaseNamespace {
+
+				rs.FailureMessage = deployErr.Error()
+				if isHelmRollbackError(deployErr) {
+					rs.RollbackMessage = deployErr.Error()
+				}
+			}
+		}
+
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+}
+
+// updateValuesDiffOnHelmChartSummary records, on the ClusterSummary status, the values diff
+// of the last upgrade of the helm release
+func updateValuesDiffOnHelmChartSummary(ctx context.Context, requestedChart *configv1beta1.HelmChart,
+	clusterSummary *configv1beta1.ClusterSummary, valuesDiff string) error {
+
+	c := getManagementClusterClient()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx,
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		for i := range currentClusterSummary.Status.HelmReleaseSummaries {
+			rs := &currentClusterSummary.Status.HelmReleaseSummaries[i]
+			if rs.ReleaseName == requestedChart.ReleaseName &&
+				rs.ReleaseNamespace == requestedChart.ReleaseNamespace {
+
+				rs.ValuesDiff = valuesDiff
+			}
+		}
+
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+}
+
 // getValueHashFromHelmChartSummary returns the valueHash stored for this chart
 // in the ClusterSummary
 func getValueHashFromHelmChartSummary(requestedChart *configv1beta1.HelmChart,
@@ -2557,6 +3257,97 @@ func createFileWithCA(ctx context.Context, c client.Client, clusterNamespace str
 	return createTemporaryFile("ca-*.crt", secret.Data[key])
 }
 
+// getHelmRepositoryEntry returns the entry of an HTTP helm repository. The entry carries the
+// credentials, CA and client certificate used both to fetch the index and to download charts.
+func getHelmRepositoryEntry(name, repoURL string, registryOptions *registryClientOptions) *repo.Entry {
+	return &repo.Entry{
+		Name:                  name,
+		URL:                   repoURL,
+		Username:              registryOptions.username,
+		Password:              registryOptions.password,
+		CertFile:              registryOptions.certPath,
+		KeyFile:               registryOptions.keyPath,
+		CAFile:                registryOptions.caPath,
+		InsecureSkipTLSverify: registryOptions.skipTLSVerify,
+	}
+}
+
+// setHelmRepositoryAuth sets, for charts stored in HTTP helm repositories, the basic authentication
+// credentials and the TLS client certificate referenced in the RegistryCredentialsConfig.
+// Certificate and key are written to temporary files, removed by removeHelmRepositoryAuthFiles.
+func setHelmRepositoryAuth(ctx context.Context, c client.Client, clusterNamespace string,
+	requestedChart *configv1beta1.HelmChart, registryOptions *registryClientOptions) error {
+
+	credentialsConfig := requestedChart.RegistryCredentialsConfig
+	if credentialsConfig == nil || registry.IsOCI(requestedChart.RepositoryURL) ||
+		isFluxChartSource(requestedChart) {
+
+		return nil
+	}
+
+	if credentialsConfig.CredentialsSecretRef != nil {
+		secret, err := getRegistryCredentialsSecret(ctx, c, clusterNamespace, credentialsConfig.CredentialsSecretRef)
+		if err != nil {
+			return err
+		}
+
+		registryOptions.username, registryOptions.password, _, err =
+			getUsernameAndPasswordFromSecret(requestedChart.RepositoryURL, secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	if credentialsConfig.CertSecretRef != nil {
+		secret, err := getRegistryCredentialsSecret(ctx, c, clusterNamespace, credentialsConfig.CertSecretRef)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if _, ok := secret.Data[key]; !ok {
+				return fmt.Errorf("secret %s/%s referenced in HelmChart section contains no key %s",
+					secret.Namespace, secret.Name, key)
+			}
+		}
+
+		registryOptions.certPath, err = createTemporaryFile("tls-*.crt", secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			return err
+		}
+		registryOptions.keyPath, err = createTemporaryFile("tls-*.key", secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			removeHelmRepositoryAuthFiles(registryOptions)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeHelmRepositoryAuthFiles(registryOptions *registryClientOptions) {
+	if registryOptions.certPath != "" {
+		os.Remove(registryOptions.certPath)
+	}
+	if registryOptions.keyPath != "" {
+		os.Remove(registryOptions.keyPath)
+	}
+}
+
+func getRegistryCredentialsSecret(ctx context.Context, c client.Client, clusterNamespace string,
+	secretRef *corev1.SecretReference) (*corev1.Secret, error) {
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterNamespace, secretRef.Namespace)
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretRef.Name}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
 func createTemporaryFile(pattern string, data []byte) (string, error) {
 	tmpfile, err := os.CreateTemp("", pattern)
 	if err != nil {
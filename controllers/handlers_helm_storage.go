@@ -0,0 +1,162 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// sqlConnectionStringKey is the key, in the Secret referenced by HelmStorage.SQLConnectionSecretRef,
+	// containing the SQL connection string
+	sqlConnectionStringKey = "connectionString"
+)
+
+var (
+	sqlDriversMux sync.Mutex
+	// key: connection string and storage namespace; value: SQL driver.
+	// Drivers are cached as each one holds a pool of database connections.
+	sqlDrivers = map[string]*driver.SQL{}
+)
+
+// helmStorageOptions contains the resolved helm storage configuration
+type helmStorageOptions struct {
+	driver              configv1beta1.HelmStorageDriver
+	namespace           string
+	sqlConnectionString string
+}
+
+func getHelmStorageValue(options *configv1beta1.HelmOptions) *configv1beta1.HelmStorage {
+	if options != nil {
+		return options.Storage
+	}
+
+	return nil
+}
+
+// getHelmStorageOptions resolves the helm storage configuration. For the sql driver, the connection
+// string is fetched from the referenced Secret in the management cluster.
+// Returns nil if helmStorage is nil (default helm storage).
+func getHelmStorageOptions(ctx context.Context, c client.Client, clusterNamespace string,
+	helmStorage *configv1beta1.HelmStorage) (*helmStorageOptions, error) {
+
+	if helmStorage == nil {
+		return nil, nil
+	}
+
+	storageOptions := &helmStorageOptions{
+		driver:    helmStorage.Driver,
+		namespace: helmStorage.Namespace,
+	}
+	if storageOptions.driver == "" {
+		storageOptions.driver = configv1beta1.HelmStorageDriverSecret
+	}
+
+	if storageOptions.driver != configv1beta1.HelmStorageDriverSQL {
+		return storageOptions, nil
+	}
+
+	if helmStorage.SQLConnectionSecretRef == nil {
+		return nil, &NonRetriableError{Message: "helm storage driver sql requires sqlConnectionSecretRef"}
+	}
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(
+		clusterNamespace, helmStorage.SQLConnectionSecretRef.Namespace)
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx,
+		types.NamespacedName{
+			Namespace: namespace,
+			Name:      helmStorage.SQLConnectionSecretRef.Name,
+		},
+		secret)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionString, ok := secret.Data[sqlConnectionStringKey]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("secret %s/%s referenced in helm storage section contains no key %s",
+			namespace, helmStorage.SQLConnectionSecretRef.Name, sqlConnectionStringKey))
+	}
+	storageOptions.sqlConnectionString = string(connectionString)
+
+	return storageOptions, nil
+}
+
+// getHelmStorageDriverAndNamespace returns the helm storage driver and the namespace release
+// information is stored in. releaseNamespace is used when no storage namespace is set.
+func getHelmStorageDriverAndNamespace(releaseNamespace string, storageOptions *helmStorageOptions,
+) (configv1beta1.HelmStorageDriver, string) {
+
+	if storageOptions == nil {
+		return configv1beta1.HelmStorageDriverSecret, releaseNamespace
+	}
+
+	namespace := storageOptions.namespace
+	if namespace == "" {
+		namespace = releaseNamespace
+	}
+
+	return storageOptions.driver, namespace
+}
+
+// setSQLStorage configures actionConfig to store release information in the SQL database
+func setSQLStorage(actionConfig *action.Configuration, namespace, connectionString string) error {
+	sqlDriversMux.Lock()
+	defer sqlDriversMux.Unlock()
+
+	key := fmt.Sprintf("%s:%s", connectionString, namespace)
+	d, ok := sqlDrivers[key]
+	if !ok {
+		var err error
+		d, err = driver.NewSQL(connectionString, debugf, namespace)
+		if err != nil {
+			return errors.Wrap(err, "failed to connect to helm sql storage")
+		}
+		sqlDrivers[key] = d
+	}
+
+	actionConfig.Releases = helmstorage.Init(d)
+	return nil
+}
+
+// getHelmReleaseStorages returns the helm storage recorded in the ClusterSummary Status for
+// each helm release. Key is releaseNamespace/releaseName.
+func getHelmReleaseStorages(clusterSummary *configv1beta1.ClusterSummary) map[string]*configv1beta1.HelmStorage {
+	result := make(map[string]*configv1beta1.HelmStorage)
+	for i := range clusterSummary.Status.HelmReleaseSummaries {
+		summary := &clusterSummary.Status.HelmReleaseSummaries[i]
+		if summary.Storage != nil {
+			result[fmt.Sprintf("%s/%s", summary.ReleaseNamespace, summary.ReleaseName)] = summary.Storage
+		}
+	}
+	return result
+}
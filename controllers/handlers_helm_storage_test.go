@@ -0,0 +1,94 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm storage", func() {
+	It("getHelmStorageOptions and getHelmStorageDriverAndNamespace resolve the helm storage", func() {
+		clusterNamespace := randomString()
+		releaseNamespace := randomString()
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		// Default helm storage
+		storageOptions, err := controllers.GetHelmStorageOptions(context.TODO(), c, clusterNamespace, nil)
+		Expect(err).To(BeNil())
+		driver, namespace := controllers.GetHelmStorageDriverAndNamespace(releaseNamespace, storageOptions)
+		Expect(driver).To(Equal(configv1beta1.HelmStorageDriverSecret))
+		Expect(namespace).To(Equal(releaseNamespace))
+
+		storageNamespace := randomString()
+		storageOptions, err = controllers.GetHelmStorageOptions(context.TODO(), c, clusterNamespace,
+			&configv1beta1.HelmStorage{Driver: configv1beta1.HelmStorageDriverConfigMap, Namespace: storageNamespace})
+		Expect(err).To(BeNil())
+		driver, namespace = controllers.GetHelmStorageDriverAndNamespace(releaseNamespace, storageOptions)
+		Expect(driver).To(Equal(configv1beta1.HelmStorageDriverConfigMap))
+		Expect(namespace).To(Equal(storageNamespace))
+	})
+
+	It("getHelmStorageOptions fetches the sql connection string from the referenced Secret", func() {
+		clusterNamespace := randomString()
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterNamespace, Name: randomString()},
+			Data:       map[string][]byte{"connectionString": []byte("postgres://helm@db:5432/helm")},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+		// No Secret referenced
+		_, err := controllers.GetHelmStorageOptions(context.TODO(), c, clusterNamespace,
+			&configv1beta1.HelmStorage{Driver: configv1beta1.HelmStorageDriverSQL})
+		Expect(err).ToNot(BeNil())
+
+		// Namespace left empty defaults to cluster namespace
+		storageOptions, err := controllers.GetHelmStorageOptions(context.TODO(), c, clusterNamespace,
+			&configv1beta1.HelmStorage{
+				Driver:                 configv1beta1.HelmStorageDriverSQL,
+				SQLConnectionSecretRef: &corev1.SecretReference{Name: secret.Name},
+			})
+		Expect(err).To(BeNil())
+		driver, _ := controllers.GetHelmStorageDriverAndNamespace(randomString(), storageOptions)
+		Expect(driver).To(Equal(configv1beta1.HelmStorageDriverSQL))
+	})
+
+	It("getHelmReleaseStorages returns helm storage recorded in the ClusterSummary status", func() {
+		storage := &configv1beta1.HelmStorage{Driver: configv1beta1.HelmStorageDriverConfigMap}
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Status: configv1beta1.ClusterSummaryStatus{
+				HelmReleaseSummaries: []configv1beta1.HelmChartSummary{
+					{ReleaseNamespace: "kyverno", ReleaseName: "kyverno", Storage: storage},
+					{ReleaseNamespace: "nginx", ReleaseName: "nginx"},
+				},
+			},
+		}
+
+		storages := controllers.GetHelmReleaseStorages(clusterSummary)
+		Expect(len(storages)).To(Equal(1))
+		Expect(storages["kyverno/kyverno"]).To(Equal(storage))
+	})
+})
@@ -24,13 +24,18 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/gdexlab/go-render/render"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
@@ -222,6 +227,60 @@ var _ = Describe("HandlersHelm", func() {
 		Expect(currentClusterSummary.Status.HelmReleaseSummaries[1].ReleaseNamespace).To(Equal(kyvernoSummary.ReleaseNamespace))
 	})
 
+	It("UpdateStatusForeferencedHelmReleases keeps versions and failures recorded for managed releases", func() {
+		calicoChart := &configv1beta1.HelmChart{
+			RepositoryURL:    "https://projectcalico.docs.tigera.io/charts",
+			RepositoryName:   "projectcalico",
+			ChartName:        "projectcalico/tigera-operator",
+			ChartVersion:     "v3.24.1",
+			ReleaseName:      "calico",
+			ReleaseNamespace: "calico",
+			HelmChartAction:  configv1beta1.HelmChartActionInstall,
+		}
+
+		calicoSummary := configv1beta1.HelmChartSummary{
+			ReleaseName:           calicoChart.ReleaseName,
+			ReleaseNamespace:      calicoChart.ReleaseNamespace,
+			Status:                configv1beta1.HelmChartStatusManaging,
+			ValuesHash:            []byte(randomString()),
+			ResolvedVersion:       "v3.24.2",
+			PendingUpgradeVersion: "v3.25.0",
+			DeployedVersion:       "v3.24.1",
+			FailureMessage:        randomString(),
+			RollbackMessage:       randomString(),
+		}
+
+		clusterSummary.Spec.ClusterProfileSpec = configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{*calicoChart},
+		}
+		clusterSummary.Status = configv1beta1.ClusterSummaryStatus{
+			HelmReleaseSummaries: []configv1beta1.HelmChartSummary{calicoSummary},
+		}
+
+		initObjects := []client.Object{
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		manager, err := chartmanager.GetChartManagerInstance(context.TODO(), c)
+		Expect(err).To(BeNil())
+
+		manager.RegisterClusterSummaryForCharts(clusterSummary)
+
+		_, conflict, err := controllers.UpdateStatusForeferencedHelmReleases(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(conflict).To(BeFalse())
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(len(currentClusterSummary.Status.HelmReleaseSummaries)).To(Equal(1))
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[0]).To(Equal(calicoSummary))
+	})
+
 	It("updateStatusForeferencedHelmReleases is no-op in DryRun mode", func() {
 		clusterSummary.Spec.ClusterProfileSpec = configv1beta1.Spec{
 			HelmCharts: []configv1beta1.HelmChart{
@@ -565,6 +624,169 @@ var _ = Describe("HandlersHelm", func() {
 		}
 		Expect(found).To(BeTrue())
 	})
+
+	It("helmTestsPassed returns true only if tests succeeded after last deployment", func() {
+		lastDeployed := helmtime.Now()
+
+		rel := &release.Release{
+			Info: &release.Info{LastDeployed: lastDeployed, Status: release.StatusDeployed},
+			Hooks: []*release.Hook{
+				{Name: "pre-install", Events: []release.HookEvent{release.HookPreInstall}},
+				{Name: "test-connection", Events: []release.HookEvent{release.HookTest}},
+			},
+		}
+		// Tests never ran for this revision
+		Expect(controllers.HelmTestsPassed(rel)).To(BeFalse())
+
+		rel.Hooks[1].LastRun = release.HookExecution{
+			StartedAt: lastDeployed.Add(time.Second),
+			Phase:     release.HookPhaseFailed,
+		}
+		Expect(controllers.HelmTestsPassed(rel)).To(BeFalse())
+
+		rel.Hooks[1].LastRun.Phase = release.HookPhaseSucceeded
+		Expect(controllers.HelmTestsPassed(rel)).To(BeTrue())
+
+		// Tests passed for a previous revision
+		rel.Info.LastDeployed = lastDeployed.Add(time.Minute)
+		Expect(controllers.HelmTestsPassed(rel)).To(BeFalse())
+
+		// No test hooks
+		rel.Hooks = rel.Hooks[:1]
+		Expect(controllers.HelmTestsPassed(rel)).To(BeTrue())
+	})
+
+	It("getHelmTestsTimeoutValue defaults to Timeout and then to 5m", func() {
+		Expect(controllers.GetHelmTestsTimeoutValue(nil)).To(Equal(5 * time.Minute))
+
+		options := &configv1beta1.HelmOptions{Timeout: &metav1.Duration{Duration: 10 * time.Minute}}
+		Expect(controllers.GetHelmTestsTimeoutValue(options)).To(Equal(10 * time.Minute))
+
+		options.HelmTestsTimeout = &metav1.Duration{Duration: time.Minute}
+		Expect(controllers.GetHelmTestsTimeoutValue(options)).To(Equal(time.Minute))
+	})
+
+	It("runHelmTests does nothing when release is not installed or tests already passed", func() {
+		requestedChart := &configv1beta1.HelmChart{
+			ReleaseName: randomString(), ReleaseNamespace: randomString(),
+			HelmChartAction: configv1beta1.HelmChartActionInstall,
+			Options: &configv1beta1.HelmOptions{
+				RunHelmTests:     true,
+				HelmTestsTimeout: &metav1.Duration{Duration: time.Second},
+			},
+		}
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: requestedChart.ReleaseNamespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, ns)).To(Succeed())
+
+		kubeconfig, err := clusterproxy.CreateKubeconfig(textlogger.NewLogger(textlogger.NewConfig()), testEnv.Kubeconfig)
+		Expect(err).To(BeNil())
+		defer os.Remove(kubeconfig)
+
+		Expect(controllers.RunHelmTests(requestedChart, kubeconfig,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		rel := storeHelmTestsRelease(requestedChart, kubeconfig, 1, randomString())
+		rel.Hooks[0].LastRun = release.HookExecution{
+			StartedAt: rel.Info.LastDeployed.Add(time.Second),
+			Phase:     release.HookPhaseSucceeded,
+		}
+		actionConfig, err := controllers.GetHelmActionConfig(requestedChart.ReleaseNamespace, kubeconfig)
+		Expect(err).To(BeNil())
+		Expect(actionConfig.Releases.Update(rel)).To(Succeed())
+
+		Expect(controllers.RunHelmTests(requestedChart, kubeconfig,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		// Tests did not run again
+		pod := &corev1.Pod{}
+		err = testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: requestedChart.ReleaseNamespace, Name: rel.Hooks[0].Name}, pod)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("runHelmTests returns an error when tests do not complete", func() {
+		requestedChart := &configv1beta1.HelmChart{
+			ReleaseName: randomString(), ReleaseNamespace: randomString(),
+			HelmChartAction: configv1beta1.HelmChartActionInstall,
+			Options: &configv1beta1.HelmOptions{
+				RunHelmTests:               true,
+				HelmTestsTimeout:           &metav1.Duration{Duration: time.Second},
+				RollbackOnHelmTestsFailure: true,
+			},
+		}
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: requestedChart.ReleaseNamespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, ns)).To(Succeed())
+
+		kubeconfig, err := clusterproxy.CreateKubeconfig(textlogger.NewLogger(textlogger.NewConfig()), testEnv.Kubeconfig)
+		Expect(err).To(BeNil())
+		defer os.Remove(kubeconfig)
+
+		// envtest runs no pods, so test pods never complete. A first install is not rolled back.
+		rel := storeHelmTestsRelease(requestedChart, kubeconfig, 1, randomString())
+		err = controllers.RunHelmTests(requestedChart, kubeconfig, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("helm tests for release"))
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeFalse())
+
+		pod := &corev1.Pod{}
+		Expect(testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: requestedChart.ReleaseNamespace, Name: rel.Hooks[0].Name}, pod)).To(Succeed())
+
+		actionConfig, err := controllers.GetHelmActionConfig(requestedChart.ReleaseNamespace, kubeconfig)
+		Expect(err).To(BeNil())
+		currentRelease, err := actionConfig.Releases.Last(requestedChart.ReleaseName)
+		Expect(err).To(BeNil())
+		Expect(currentRelease.Version).To(Equal(1))
+		Expect(controllers.HelmTestsPassed(currentRelease)).To(BeFalse())
+	})
+
+	It("runHelmTests rolls an upgraded release back when tests fail and RollbackOnHelmTestsFailure is set", func() {
+		requestedChart := &configv1beta1.HelmChart{
+			ReleaseName: randomString(), ReleaseNamespace: randomString(),
+			HelmChartAction: configv1beta1.HelmChartActionInstall,
+			Options: &configv1beta1.HelmOptions{
+				RunHelmTests:               true,
+				HelmTestsTimeout:           &metav1.Duration{Duration: time.Second},
+				RollbackOnHelmTestsFailure: true,
+			},
+		}
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: requestedChart.ReleaseNamespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, ns)).To(Succeed())
+
+		kubeconfig, err := clusterproxy.CreateKubeconfig(textlogger.NewLogger(textlogger.NewConfig()), testEnv.Kubeconfig)
+		Expect(err).To(BeNil())
+		defer os.Remove(kubeconfig)
+
+		previousValue := randomString()
+		storeHelmTestsRelease(requestedChart, kubeconfig, 1, previousValue)
+		storeHelmTestsRelease(requestedChart, kubeconfig, 2, randomString())
+
+		err = controllers.RunHelmTests(requestedChart, kubeconfig, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+
+		actionConfig, err := controllers.GetHelmActionConfig(requestedChart.ReleaseNamespace, kubeconfig)
+		Expect(err).To(BeNil())
+		currentRelease, err := actionConfig.Releases.Last(requestedChart.ReleaseName)
+		Expect(err).To(BeNil())
+		Expect(currentRelease.Version).To(Equal(3))
+		Expect(currentRelease.Info.Status).To(Equal(release.StatusDeployed))
+
+		// Resources are back to the previous revision
+		configMap := &corev1.ConfigMap{}
+		Expect(testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: requestedChart.ReleaseNamespace, Name: requestedChart.ReleaseName},
+			configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("value", previousValue))
+	})
 })
 
 var _ = Describe("Hash methods", func() {
@@ -633,6 +855,7 @@ var _ = Describe("Hash methods", func() {
 		config += fmt.Sprintf("%v", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Reloader)
 		config += fmt.Sprintf("%v", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tier)
 		config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict)
+		config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnError)
 		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Patches)
 
 		h := sha256.New()
@@ -784,6 +1007,232 @@ var _ = Describe("Hash methods", func() {
 		Expect(reflect.DeepEqual(hash, h.Sum(nil))).To(BeTrue())
 	})
 
+	It("getHelmChartValuesHash changes when helm chart Patches change", func() {
+		requestedChart := configv1beta1.HelmChart{
+			Values: randomString(),
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		hash, err := controllers.GetHelmChartValuesHash(context.TODO(), c, &requestedChart,
+			clusterSummary, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		requestedChart.Patches = []libsveltosv1beta1.Patch{
+			{
+				Patch: `- op: add
+  path: /metadata/labels/environment
+  value: production`,
+				Target: &libsveltosv1beta1.PatchSelector{
+					Kind: "Deployment",
+				},
+			},
+		}
+
+		patchedHash, err := controllers.GetHelmChartValuesHash(context.TODO(), c, &requestedChart,
+			clusterSummary, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(reflect.DeepEqual(hash, patchedHash)).To(BeFalse())
+	})
+
+	It("mergeValues merges values in alphabetical key order, each overriding the previous ones", func() {
+		values := map[string]interface{}{
+			"replicaCount": 1,
+			"image":        map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+		}
+
+		valuesFrom := map[string]string{
+			"b-values": "image:\n  tag: \"1.27\"\n",
+			"a-values": "image:\n  tag: \"1.26\"\nreplicaCount: 2\n",
+		}
+
+		merged, err := controllers.MergeValues(values, valuesFrom)
+		Expect(err).To(BeNil())
+		Expect(merged["replicaCount"]).To(Equal(float64(2)))
+		image := merged["image"].(map[string]interface{})
+		Expect(image["repository"]).To(Equal("nginx"))
+		Expect(image["tag"]).To(Equal("1.27"))
+
+		_, err = controllers.MergeValues(values, map[string]string{randomString(): "replicaCount: [2"})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("mergeHelmChartValuesFrom merges only the selected key and skips missing optional entries", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string][]byte{
+				"values.yaml":      []byte("replicaCount: 1\nimage:\n  tag: \"1.25\"\n"),
+				"values-prod.yaml": []byte("replicaCount: 3\n"),
+			},
+			Type: libsveltosv1beta1.ClusterProfileSecretType,
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: secret.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: secret.Namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+		requestedChart := &configv1beta1.HelmChart{
+			ChartName: randomString(),
+			ValuesFrom: []configv1beta1.ValueFrom{
+				{
+					Kind: string(libsveltosv1beta1.SecretReferencedResourceKind), Namespace: secret.Namespace,
+					Name: secret.Name, Key: "values.yaml",
+				},
+				{
+					Kind: string(libsveltosv1beta1.SecretReferencedResourceKind), Namespace: secret.Namespace,
+					Name: secret.Name, Key: "values-prod.yaml",
+				},
+				{
+					Kind: string(libsveltosv1beta1.SecretReferencedResourceKind), Namespace: secret.Namespace,
+					Name: secret.Name, Key: randomString(), Optional: true,
+				},
+				{
+					Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: secret.Namespace,
+					Name: randomString(), Optional: true,
+				},
+			},
+		}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		values := map[string]interface{}{}
+		for i := range requestedChart.ValuesFrom {
+			var err error
+			values, err = controllers.MergeHelmChartValuesFrom(context.TODO(), c, clusterSummary, nil, requestedChart,
+				&requestedChart.ValuesFrom[i], values, logger)
+			Expect(err).To(BeNil())
+		}
+
+		// values-prod.yaml is listed last so it overrides values.yaml
+		Expect(values["replicaCount"]).To(Equal(float64(3)))
+		image := values["image"].(map[string]interface{})
+		Expect(image["tag"]).To(Equal("1.25"))
+
+		// A missing key fails unless the entry is optional
+		valueFrom := &configv1beta1.ValueFrom{
+			Kind: string(libsveltosv1beta1.SecretReferencedResourceKind), Namespace: secret.Namespace,
+			Name: secret.Name, Key: randomString(),
+		}
+		_, err := controllers.MergeHelmChartValuesFrom(context.TODO(), c, clusterSummary, nil, requestedChart,
+			valueFrom, values, logger)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("isHelmRollbackError returns true only when a failed upgrade was rolled back", func() {
+		Expect(controllers.IsHelmRollbackError(nil)).To(BeFalse())
+		Expect(controllers.IsHelmRollbackError(errors.New("timed out waiting for the condition"))).To(BeFalse())
+		Expect(controllers.IsHelmRollbackError(fmt.Errorf(
+			"release %s failed, and has been rolled back due to atomic being set: timed out waiting for the condition",
+			randomString()))).To(BeTrue())
+	})
+
+	It("instantiateHelmReleases instantiates templated release name and namespace", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sveltosCluster.Namespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(testEnv.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, sveltosCluster)).To(Succeed())
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: sveltosCluster.Namespace,
+				ClusterName:      sveltosCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{
+							RepositoryURL: randomString(), RepositoryName: randomString(), ChartName: randomString(),
+							ChartVersion: "1.0.0", ReleaseName: "{{ .Cluster.metadata.labels.env }}-ingress",
+							ReleaseNamespace: "ingress-{{ .Cluster.metadata.labels.env }}",
+						},
+						{
+							RepositoryURL: randomString(), RepositoryName: randomString(), ChartName: randomString(),
+							ChartVersion: "1.0.0", ReleaseName: "kyverno", ReleaseNamespace: "kyverno",
+						},
+					},
+				},
+			},
+		}
+
+		instantiated, err := controllers.InstantiateHelmReleases(context.TODO(), currentClusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(instantiated.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseName).To(Equal("prod-ingress"))
+		Expect(instantiated.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseNamespace).To(Equal("ingress-prod"))
+		Expect(instantiated.Spec.ClusterProfileSpec.HelmCharts[1].ReleaseName).To(Equal("kyverno"))
+		Expect(instantiated.Spec.ClusterProfileSpec.HelmCharts[1].ReleaseNamespace).To(Equal("kyverno"))
+
+		// ClusterSummary itself is never modified
+		Expect(currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseName).To(
+			Equal("{{ .Cluster.metadata.labels.env }}-ingress"))
+
+		// Instantiated release name must be a valid helm release name
+		currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseName = "{{ .Cluster.metadata.labels.env }}_ingress"
+		_, err = controllers.InstantiateHelmReleases(context.TODO(), currentClusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+	})
+
+	It("getHelmUninstallClient uses uninstall options, falling back to helm options", func() {
+		helmChart := &configv1beta1.HelmChart{
+			Options: &configv1beta1.HelmOptions{
+				Wait:    true,
+				Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		}
+
+		uninstallClient := controllers.GetHelmUninstallClient(helmChart, &action.Configuration{})
+		Expect(uninstallClient.Wait).To(BeTrue())
+		Expect(uninstallClient.Timeout).To(Equal(10 * time.Minute))
+		Expect(uninstallClient.KeepHistory).To(BeFalse())
+		Expect(uninstallClient.DeletionPropagation).To(Equal("background"))
+
+		wait := false
+		helmChart.Options.UninstallOptions = configv1beta1.HelmUninstallOptions{
+			KeepHistory:         true,
+			DeletionPropagation: "foreground",
+			Wait:                &wait,
+			Timeout:             &metav1.Duration{Duration: 2 * time.Minute},
+		}
+
+		uninstallClient = controllers.GetHelmUninstallClient(helmChart, &action.Configuration{})
+		Expect(uninstallClient.Wait).To(BeFalse())
+		Expect(uninstallClient.Timeout).To(Equal(2 * time.Minute))
+		Expect(uninstallClient.KeepHistory).To(BeTrue())
+		Expect(uninstallClient.DeletionPropagation).To(Equal("foreground"))
+	})
+
 	It("getCredentialsAndCAFiles returns files containing credentials and CA", func() {
 		type Credentials struct {
 			Username     string
@@ -853,8 +1302,124 @@ var _ = Describe("Hash methods", func() {
 		verifyFileContent(caPath, caByte)
 		Expect(os.Remove(caPath)).To(Succeed())
 	})
+
+	It("getHelmRepositoryEntry sets basic auth and client certificate for HTTP helm repositories", func() {
+		namespace := randomString()
+		secretCredentials := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data: map[string][]byte{
+				"username": []byte(randomString()),
+				"password": []byte(randomString()),
+			},
+		}
+		secretCert := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte(randomString()),
+				corev1.TLSPrivateKeyKey: []byte(randomString()),
+			},
+		}
+
+		requestedChart := configv1beta1.HelmChart{
+			RepositoryURL:  "https://charts.example.com",
+			RepositoryName: randomString(),
+			ChartName:      randomString(),
+			ChartVersion:   "1.0.0",
+			ReleaseName:    randomString(),
+			RegistryCredentialsConfig: &configv1beta1.RegistryCredentialsConfig{
+				CredentialsSecretRef: &corev1.SecretReference{Name: secretCredentials.Name},
+				CertSecretRef:        &corev1.SecretReference{Name: secretCert.Name},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secretCredentials, secretCert).Build()
+
+		// For ClusterProfile, secrets are looked up in the cluster namespace
+		entry, err := controllers.GetHelmRepositoryEntry(context.TODO(), c, namespace, &requestedChart)
+		Expect(err).To(BeNil())
+		Expect(entry.URL).To(Equal(requestedChart.RepositoryURL))
+		Expect(entry.Username).To(Equal(string(secretCredentials.Data["username"])))
+		Expect(entry.Password).To(Equal(string(secretCredentials.Data["password"])))
+		Expect(entry.CertFile).ToNot(BeEmpty())
+		verifyFileContent(entry.CertFile, secretCert.Data[corev1.TLSCertKey])
+		Expect(os.Remove(entry.CertFile)).To(Succeed())
+		Expect(entry.KeyFile).ToNot(BeEmpty())
+		verifyFileContent(entry.KeyFile, secretCert.Data[corev1.TLSPrivateKeyKey])
+		Expect(os.Remove(entry.KeyFile)).To(Succeed())
+
+		// OCI registries authenticate with a registry login instead
+		requestedChart.RepositoryURL = "oci://registry.example.com/charts"
+		entry, err = controllers.GetHelmRepositoryEntry(context.TODO(), c, namespace, &requestedChart)
+		Expect(err).To(BeNil())
+		Expect(entry.Username).To(BeEmpty())
+		Expect(entry.CertFile).To(BeEmpty())
+	})
 })
 
+// storeHelmTestsRelease stores, as deployed, revision version of the requestedChart release. Previous
+// revision, if any, is marked as superseded. The release deploys a ConfigMap containing value and
+// has a test hook.
+func storeHelmTestsRelease(requestedChart *configv1beta1.HelmChart, kubeconfig string, version int,
+	value string) *release.Release {
+
+	actionConfig, err := controllers.GetHelmActionConfig(requestedChart.ReleaseNamespace, kubeconfig)
+	Expect(err).To(BeNil())
+
+	if version > 1 {
+		previous, err := actionConfig.Releases.Get(requestedChart.ReleaseName, version-1)
+		Expect(err).To(BeNil())
+		previous.Info.Status = release.StatusSuperseded
+		Expect(actionConfig.Releases.Update(previous)).To(Succeed())
+	}
+
+	testName := fmt.Sprintf("%s-test-%d", requestedChart.ReleaseName, version)
+	rel := &release.Release{
+		Name:      requestedChart.ReleaseName,
+		Namespace: requestedChart.ReleaseNamespace,
+		Version:   version,
+		Info: &release.Info{
+			FirstDeployed: helmtime.Now(),
+			LastDeployed:  helmtime.Now(),
+			Status:        release.StatusDeployed,
+		},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "helm-tests", Version: "0.1.0"},
+		},
+		Manifest: fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+data:
+  value: %s
+`, requestedChart.ReleaseName, value),
+		Hooks: []*release.Hook{
+			{
+				Name:   testName,
+				Kind:   "Pod",
+				Path:   "helm-tests/templates/tests/test.yaml",
+				Events: []release.HookEvent{release.HookTest},
+				Manifest: fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  annotations:
+    helm.sh/hook: test
+spec:
+  restartPolicy: Never
+  containers:
+  - name: test
+    image: busybox
+    command: ["true"]
+`, testName),
+			},
+		},
+	}
+
+	Expect(actionConfig.Releases.Create(rel)).To(Succeed())
+	return rel
+}
+
 func verifyFileContent(filePath string, data []byte) {
 	content, err := os.ReadFile(filePath)
 	Expect(err).To(BeNil())
@@ -0,0 +1,159 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// defaultHelmTestTimeout is the time each test is given to complete when no timeout is set in HelmOptions
+	defaultHelmTestTimeout = 5 * time.Minute
+
+	// maxHelmTestLogsLength is the maximum length of test logs reported. Only the most recent are kept.
+	maxHelmTestLogsLength = 4096
+)
+
+// helmTestsPassed returns true if all test hooks of the release ran successfully after the
+// release was last deployed. A release without test hooks has nothing to run.
+func helmTestsPassed(rel *release.Release) bool {
+	for i := range rel.Hooks {
+		hook := rel.Hooks[i]
+		if !isHelmTestHook(hook) {
+			continue
+		}
+
+		if hook.LastRun.Phase != release.HookPhaseSucceeded {
+			return false
+		}
+
+		if rel.Info != nil && hook.LastRun.StartedAt.Before(rel.Info.LastDeployed) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isHelmTestHook(hook *release.Hook) bool {
+	for i := range hook.Events {
+		if hook.Events[i] == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
+// runHelmTests runs the chart tests (helm test) unless they already passed for the currently
+// deployed release revision. Returns an error, containing the logs of the test pods, if any test fails.
+func runHelmTests(requestedChart *configv1beta1.HelmChart, kubeconfig string,
+	registryOptions *registryClientOptions, logger logr.Logger) error {
+
+	actionConfig, err := actionConfigInit(requestedChart.ReleaseNamespace, kubeconfig, registryOptions,
+		getEnableClientCacheValue(requestedChart.Options))
+	if err != nil {
+		return err
+	}
+
+	rel, err := action.NewStatus(actionConfig).Run(requestedChart.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if rel.Info != nil && rel.Info.Status != release.StatusDeployed {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("release is in status %s. Not running tests", rel.Info.Status))
+		return nil
+	}
+
+	if helmTestsPassed(rel) {
+		logger.V(logs.LogDebug).Info("helm tests already passed")
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info("running helm tests")
+	testClient := action.NewReleaseTesting(actionConfig)
+	testClient.Namespace = requestedChart.ReleaseNamespace
+	testClient.Timeout = getHelmTestsTimeoutValue(requestedChart.Options)
+
+	version := rel.Version
+	rel, err = testClient.Run(requestedChart.ReleaseName)
+	if err == nil {
+		logger.V(logs.LogDebug).Info("helm tests passed")
+		return nil
+	}
+
+	message := fmt.Sprintf("helm tests for release %s/%s failed: %v",
+		requestedChart.ReleaseNamespace, requestedChart.ReleaseName, err)
+	if rel != nil {
+		var podLogs bytes.Buffer
+		if logErr := testClient.GetPodLogs(&podLogs, rel); logErr != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect helm test logs: %v", logErr))
+		} else if podLogs.Len() != 0 {
+			testLogs := strings.TrimSpace(podLogs.String())
+			if len(testLogs) > maxHelmTestLogsLength {
+				testLogs = testLogs[len(testLogs)-maxHelmTestLogsLength:]
+			}
+			message += fmt.Sprintf("\n%s", testLogs)
+		}
+	}
+
+	logger.V(logs.LogInfo).Info(message)
+
+	// A first install has no previous revision to go back to
+	if getRollbackOnHelmTestsFailureValue(requestedChart.Options) && version > 1 {
+		err = rollbackOnHelmTestsFailure(actionConfig, requestedChart, logger)
+		if err != nil {
+			return fmt.Errorf("%s\nrollback failed: %w", message, err)
+		}
+		// Retrying would upgrade the release again and run the same failing tests
+		return &NonRetriableError{Message: fmt.Sprintf("%s\nrelease %s", message, helmTestsRollbackMessage)}
+	}
+
+	return errors.New(message)
+}
+
+// rollbackOnHelmTestsFailure rolls the release back to its previous revision
+func rollbackOnHelmTestsFailure(actionConfig *action.Configuration, requestedChart *configv1beta1.HelmChart,
+	logger logr.Logger) error {
+
+	logger.V(logs.LogInfo).Info("helm tests failed. Rolling release back to previous revision")
+	rollbackClient := action.NewRollback(actionConfig)
+	// Version zero is the previous revision
+	rollbackClient.Version = 0
+	rollbackClient.Wait = getWaitHelmValue(requestedChart.Options)
+	rollbackClient.WaitForJobs = getWaitForJobsHelmValue(requestedChart.Options)
+	if timeout := getTimeoutValue(requestedChart.Options); timeout != nil {
+		rollbackClient.Timeout = timeout.Duration
+	}
+
+	return rollbackClient.Run(requestedChart.ReleaseName)
+}
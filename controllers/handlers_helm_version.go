@@ -0,0 +1,629 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	"github.com/projectsveltos/libsveltos/lib/sharding"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// how long the versions available for a chart are reused before the repository is queried again
+	chartVersionsCacheTTL = 5 * time.Minute
+)
+
+type chartVersionsCacheEntry struct {
+	versions   []string
+	expiration time.Time
+}
+
+var (
+	// chartVersionsCache caches the versions available for charts with a dynamic VersionPolicy, so
+	// repositories are not queried on every deployment.
+	// key: repository, chart and credentials used to access the repository
+	chartVersionsCache   = make(map[string]chartVersionsCacheEntry)
+	chartVersionsCacheMu sync.Mutex
+)
+
+// hasDynamicVersionPolicy returns true if the chart version to deploy needs to be
+// discovered from the repository
+func hasDynamicVersionPolicy(currentChart *configv1beta1.HelmChart) bool {
+	if currentChart.VersionPolicy == nil {
+		return false
+	}
+
+	return currentChart.VersionPolicy.Type == configv1beta1.VersionPolicyTypeSemverRange ||
+		currentChart.VersionPolicy.Type == configv1beta1.VersionPolicyTypeLatest
+}
+
+// validateHelmChartVersions verifies ChartVersion of each helm chart is an exact version.
+// Semver ranges are expressed with a SemverRange VersionPolicy, whose Constraint must be valid.
+func validateHelmChartVersions(spec *configv1beta1.Spec) error {
+	for i := range spec.HelmCharts {
+		hc := &spec.HelmCharts[i]
+		if _, err := semver.NewVersion(hc.ChartVersion); err != nil {
+			if _, constraintErr := semver.NewConstraint(hc.ChartVersion); constraintErr == nil {
+				return fmt.Errorf("helm chart %s/%s: chartVersion %q is a semver range, use a versionPolicy of type %s",
+					hc.ReleaseNamespace, hc.ReleaseName, hc.ChartVersion, configv1beta1.VersionPolicyTypeSemverRange)
+			}
+		}
+
+		if hc.VersionPolicy != nil && hc.VersionPolicy.Type == configv1beta1.VersionPolicyTypeSemverRange {
+			if _, err := semver.NewConstraint(hc.VersionPolicy.Constraint); err != nil {
+				return fmt.Errorf("helm chart %s/%s: invalid versionPolicy constraint %q: %w",
+					hc.ReleaseNamespace, hc.ReleaseName, hc.VersionPolicy.Constraint, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getVersionUpgradeMode(policy *configv1beta1.VersionPolicy) configv1beta1.VersionUpgradeMode {
+	if policy == nil || policy.UpgradeMode == "" {
+		return configv1beta1.VersionUpgradeModeAuto
+	}
+
+	return policy.UpgradeMode
+}
+
+// selectChartVersion returns, among the available versions, the highest one matching the policy.
+// Latest only considers stable versions.
+func selectChartVersion(versions []string, policy *configv1beta1.VersionPolicy) (string, error) {
+	constraintValue := "*"
+	if policy.Type == configv1beta1.VersionPolicyTypeSemverRange {
+		constraintValue = policy.Constraint
+	}
+
+	constraint, err := semver.NewConstraint(constraintValue)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint %q: %w", constraintValue, err)
+	}
+
+	var selected *semver.Version
+	for i := range versions {
+		// OCI tags cannot contain '+'. Helm replaces it with '_'
+		v, err := semver.NewVersion(strings.ReplaceAll(versions[i], "_", "+"))
+		if err != nil {
+			continue
+		}
+		if !constraint.Check(v) {
+			continue
+		}
+		if selected == nil || v.GreaterThan(selected) {
+			selected = v
+		}
+	}
+
+	if selected == nil {
+		return "", fmt.Errorf("no chart version matching %q", constraintValue)
+	}
+
+	return selected.Original(), nil
+}
+
+// getAvailableChartVersions returns all versions of the chart currently available in the repository.
+// For HTTP repositories the index file is downloaded. For OCI registries tags are listed.
+func getAvailableChartVersions(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart) ([]string, error) {
+
+	if isFluxChartSource(currentChart) {
+		return nil, fmt.Errorf("versionPolicy is not supported for charts stored in a Flux source")
+	}
+
+	credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, getManagementClusterClient(),
+		clusterSummary.Spec.ClusterNamespace, currentChart)
+	if err != nil {
+		return nil, err
+	}
+	if credentialsPath != "" {
+		defer os.Remove(credentialsPath)
+	}
+	if caPath != "" {
+		defer os.Remove(caPath)
+	}
+
+	registryOptions := &registryClientOptions{
+		credentialsPath: credentialsPath, caPath: caPath,
+		skipTLSVerify: getInsecureSkipTLSVerify(currentChart),
+		plainHTTP:     getPlainHTTP(currentChart),
+	}
+
+	if registry.IsOCI(currentChart.RepositoryURL) {
+		return getOCIChartVersions(currentChart, registryOptions)
+	}
+
+	err = setHelmRepositoryAuth(ctx, getManagementClusterClient(), clusterSummary.Spec.ClusterNamespace,
+		currentChart, registryOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer removeHelmRepositoryAuthFiles(registryOptions)
+
+	return getRepositoryChartVersions(currentChart, registryOptions)
+}
+
+func getOCIChartVersions(currentChart *configv1beta1.HelmChart, registryOptions *registryClientOptions,
+) ([]string, error) {
+
+	chartRef, _, err := getHelmChartAndRepoName(currentChart.ChartName, currentChart.RepositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	registryClient, err := getRegistryClient(currentChart.ReleaseNamespace, registryOptions,
+		getEnableClientCacheValue(currentChart.Options))
+	if err != nil {
+		return nil, err
+	}
+
+	return registryClient.Tags(strings.TrimPrefix(chartRef, fmt.Sprintf("%s://", registry.OCIScheme)))
+}
+
+func getRepositoryChartVersions(currentChart *configv1beta1.HelmChart, registryOptions *registryClientOptions,
+) ([]string, error) {
+
+	settings := getSettings(currentChart.ReleaseNamespace, registryOptions)
+
+	entry := getHelmRepositoryEntry(currentChart.RepositoryName, currentChart.RepositoryURL, registryOptions)
+	chartRepo, err := repo.NewChartRepository(entry, getter.All(settings))
+	if err != nil {
+		return nil, err
+	}
+	chartRepo.CachePath = settings.RepositoryCache
+
+	// Always download the index so newly published versions are discovered
+	indexPath, err := chartRepo.DownloadIndexFile()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	chartName := strings.TrimPrefix(currentChart.ChartName, currentChart.RepositoryName+"/")
+	versions := make([]string, 0)
+	for _, chartVersion := range index.Entries[chartName] {
+		versions = append(versions, chartVersion.Version)
+	}
+
+	return versions, nil
+}
+
+// getChartVersionsCacheKey returns the key of the chart in chartVersionsCache. Credentials, with their
+// namespace resolved for the cluster, are part of the key, so versions of a private chart are only
+// reused by ClusterSummaries accessing the repository with the same credentials.
+func getChartVersionsCacheKey(clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart) string {
+
+	credentials := ""
+	if currentChart.RegistryCredentialsConfig != nil &&
+		currentChart.RegistryCredentialsConfig.CredentialsSecretRef != nil {
+
+		credSecretRef := currentChart.RegistryCredentialsConfig.CredentialsSecretRef
+		credentials = fmt.Sprintf("%s/%s",
+			libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace, credSecretRef.Namespace),
+			credSecretRef.Name)
+	}
+
+	return fmt.Sprintf("%s|%s|%s", currentChart.RepositoryURL, currentChart.ChartName, credentials)
+}
+
+// getCachedChartVersions returns the versions available for the chart. Versions are fetched from
+// the repository if not cached, cached ones are expired or refresh is set.
+func getCachedChartVersions(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart, refresh bool) ([]string, error) {
+
+	key := getChartVersionsCacheKey(clusterSummary, currentChart)
+
+	if !refresh {
+		chartVersionsCacheMu.Lock()
+		entry, ok := chartVersionsCache[key]
+		chartVersionsCacheMu.Unlock()
+		if ok && time.Now().Before(entry.expiration) {
+			return entry.versions, nil
+		}
+	}
+
+	versions, err := getAvailableChartVersions(ctx, clusterSummary, currentChart)
+	if err != nil {
+		return nil, err
+	}
+
+	chartVersionsCacheMu.Lock()
+	chartVersionsCache[key] = chartVersionsCacheEntry{
+		versions:   versions,
+		expiration: time.Now().Add(chartVersionsCacheTTL),
+	}
+	chartVersionsCacheMu.Unlock()
+
+	return versions, nil
+}
+
+// resolveChartVersion returns the highest version, available in the repository, matching the
+// chart VersionPolicy. Available versions are cached for chartVersionsCacheTTL, unless refresh is set.
+func resolveChartVersion(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart, refresh bool) (string, error) {
+
+	versions, err := getCachedChartVersions(ctx, clusterSummary, currentChart, refresh)
+	if err != nil {
+		return "", err
+	}
+
+	return selectChartVersion(versions, currentChart.VersionPolicy)
+}
+
+// getPendingUpgradeVersion returns resolvedVersion if it is newer than the chart pinned version.
+// Empty string is returned otherwise.
+func getPendingUpgradeVersion(currentChart *configv1beta1.HelmChart, resolvedVersion string) string {
+	pinned, err := semver.NewVersion(currentChart.ChartVersion)
+	if err != nil {
+		return resolvedVersion
+	}
+
+	resolved, err := semver.NewVersion(resolvedVersion)
+	if err != nil {
+		return ""
+	}
+
+	if resolved.GreaterThan(pinned) {
+		return resolvedVersion
+	}
+
+	return ""
+}
+
+// resolveHelmChartVersions resolves, once per deployment, the version of the helm charts:
+// - charts whose version is pinned by the chart versions ConfigMap are deployed with that version;
+// - charts with a dynamic VersionPolicy are deployed with the version resolved from the repository.
+// Versions are recorded in the HelmChartSummaries, so any consumer (metrics, profile diff, ...) can
+// report the version being deployed.
+// Returns a copy of clusterSummary where ChartVersion is the version to deploy. In UpgradeMode Manual,
+// ChartVersion is unchanged and the newer version, if any, is reported in the HelmChartSummary as
+// PendingUpgradeVersion.
+// With ContinueOnError set, charts whose version cannot be resolved are returned in resolveErrors
+// (key: release namespace/name), so only those are not deployed. Otherwise first failure is returned.
+func resolveHelmChartVersions(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) (resolved *configv1beta1.ClusterSummary, resolveErrors map[string]error, err error) {
+
+	pinnedVersions, err := getPinnedChartVersions(ctx, c, clusterSummary)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolveErrors = make(map[string]error)
+	resolved = clusterSummary
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		if currentChart.HelmChartAction == configv1beta1.HelmChartActionUninstall {
+			continue
+		}
+
+		// Version of helm releases managed by another ClusterSummary is not resolved
+		rs := getHelmChartSummary(clusterSummary, currentChart)
+		if rs != nil && rs.Status == configv1beta1.HelmChartStatusConflict {
+			continue
+		}
+
+		var version string
+		if pinnedVersion, ok := pinnedVersions[i]; ok {
+			version = pinnedVersion
+			if rs != nil && rs.ResolvedVersion != version {
+				err = updateVersionsOnHelmChartSummary(ctx, c, currentChart, clusterSummary, version, "")
+			}
+		} else if hasDynamicVersionPolicy(currentChart) {
+			version, err = resolveHelmChartVersion(ctx, c, clusterSummary, currentChart, logger)
+		} else {
+			version = currentChart.ChartVersion
+			if rs != nil && (rs.ResolvedVersion != "" || rs.PendingUpgradeVersion != "") {
+				// Version was previously pinned or resolved
+				err = updateVersionsOnHelmChartSummary(ctx, c, currentChart, clusterSummary, "", "")
+			}
+		}
+		if err != nil {
+			if !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				return nil, nil, err
+			}
+			resolveErrors[fmt.Sprintf("%s/%s", currentChart.ReleaseNamespace, currentChart.ReleaseName)] = err
+			err = nil
+			continue
+		}
+
+		if version == currentChart.ChartVersion {
+			continue
+		}
+
+		if resolved == clusterSummary {
+			resolved = clusterSummary.DeepCopy()
+		}
+		resolved.Spec.ClusterProfileSpec.HelmCharts[i].ChartVersion = version
+	}
+
+	return resolved, resolveErrors, nil
+}
+
+// resolveHelmChartVersion resolves the version of a chart with a dynamic VersionPolicy from the
+// repository, and records it in the HelmChartSummary. Returns the version to deploy.
+func resolveHelmChartVersion(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	currentChart *configv1beta1.HelmChart, logger logr.Logger) (string, error) {
+
+	// In disconnected environments, versions are listed from the registry mirrors
+	resolvedVersion, err := resolveChartVersion(ctx, clusterSummary, getMirroredChart(currentChart), false)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to resolve chart version: %v", err))
+		return "", err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("chart %s resolved version %s", currentChart.ChartName, resolvedVersion))
+
+	if getVersionUpgradeMode(currentChart.VersionPolicy) == configv1beta1.VersionUpgradeModeManual {
+		err = updateVersionsOnHelmChartSummary(ctx, c, currentChart, clusterSummary,
+			currentChart.ChartVersion, getPendingUpgradeVersion(currentChart, resolvedVersion))
+		return currentChart.ChartVersion, err
+	}
+
+	err = updateVersionsOnHelmChartSummary(ctx, c, currentChart, clusterSummary, resolvedVersion, "")
+	if err != nil {
+		return "", err
+	}
+
+	return resolvedVersion, nil
+}
+
+// updateVersionsOnHelmChartSummary stores resolved and pending upgrade version in the HelmChartSummary
+func updateVersionsOnHelmChartSummary(ctx context.Context, c client.Client, requestedChart *configv1beta1.HelmChart,
+	clusterSummary *configv1beta1.ClusterSummary, resolvedVersion, pendingUpgradeVersion string) error {
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx,
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		updated := false
+		for i := range currentClusterSummary.Status.HelmReleaseSummaries {
+			rs := &currentClusterSummary.Status.HelmReleaseSummaries[i]
+			if rs.ReleaseName == requestedChart.ReleaseName &&
+				rs.ReleaseNamespace == requestedChart.ReleaseNamespace {
+
+				if rs.ResolvedVersion != resolvedVersion || rs.PendingUpgradeVersion != pendingUpgradeVersion {
+					rs.ResolvedVersion = resolvedVersion
+					rs.PendingUpgradeVersion = pendingUpgradeVersion
+					updated = true
+				}
+			}
+		}
+
+		if !updated {
+			return nil
+		}
+
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+}
+
+// getHelmChartSummary returns the HelmChartSummary for the chart. Nil if not found.
+func getHelmChartSummary(clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart) *configv1beta1.HelmChartSummary {
+
+	for i := range clusterSummary.Status.HelmReleaseSummaries {
+		rs := &clusterSummary.Status.HelmReleaseSummaries[i]
+		if rs.ReleaseName == requestedChart.ReleaseName &&
+			rs.ReleaseNamespace == requestedChart.ReleaseNamespace {
+
+			return rs
+		}
+	}
+
+	return nil
+}
+
+// getDesiredChartVersion returns the chart version ClusterSummary is expected to deploy.
+// This is the version recorded in the HelmChartSummary for charts whose version is pinned by the
+// chart versions ConfigMap or resolved by a dynamic VersionPolicy, ChartVersion otherwise.
+func getDesiredChartVersion(clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart) string {
+
+	rs := getHelmChartSummary(clusterSummary, requestedChart)
+	if rs != nil && rs.ResolvedVersion != "" {
+		return rs.ResolvedVersion
+	}
+
+	return requestedChart.ChartVersion
+}
+
+// pollChartVersions periodically checks repositories of helm charts with a dynamic VersionPolicy.
+// When a newer version matching the policy is found:
+// - UpgradeMode Auto: Helm feature is redeployed;
+// - UpgradeMode Manual: the version is reported in the HelmChartSummary as PendingUpgradeVersion.
+func pollChartVersions(ctx context.Context, c client.Client, shardKey string, interval time.Duration,
+	logger logr.Logger) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.V(logs.LogInfo).Info("stop polling helm chart versions")
+			return
+		case <-ticker.C:
+		}
+
+		logger.V(logs.LogVerbose).Info("polling helm chart versions")
+		clusterSummaries := &configv1beta1.ClusterSummaryList{}
+		if err := c.List(ctx, clusterSummaries); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+			continue
+		}
+
+		for i := range clusterSummaries.Items {
+			if ctx.Err() != nil {
+				return
+			}
+
+			clusterSummary := &clusterSummaries.Items[i]
+			if !clusterSummary.DeletionTimestamp.IsZero() {
+				continue
+			}
+
+			l := logger.WithValues("clustersummary", fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name))
+			if err := pollClusterSummaryChartVersions(ctx, c, clusterSummary, shardKey, l); err != nil {
+				l.V(logs.LogInfo).Info(fmt.Sprintf("failed to poll chart versions: %v", err))
+			}
+		}
+	}
+}
+
+func pollClusterSummaryChartVersions(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, shardKey string, logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	redeploy := false
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		if !hasDynamicVersionPolicy(currentChart) ||
+			currentChart.HelmChartAction == configv1beta1.HelmChartActionUninstall {
+
+			continue
+		}
+
+		// Releases are tracked in Status with their instantiated name and namespace
+		if isHelmReleaseTemplate(currentChart) {
+			currentChart = currentChart.DeepCopy()
+			var err error
+			currentChart.ReleaseName, currentChart.ReleaseNamespace, err =
+				instantiateHelmChartRelease(ctx, clusterSummary, currentChart, logger)
+			if err != nil {
+				return err
+			}
+		}
+
+		rs := getHelmChartSummary(clusterSummary, currentChart)
+		if rs == nil || rs.Status != configv1beta1.HelmChartStatusManaging {
+			continue
+		}
+
+		match, err := isClusterSummaryAShardMatch(ctx, c, clusterSummary, shardKey)
+		if err != nil || !match {
+			return err
+		}
+
+		// As when deploying, Flux sources and Secrets in other namespaces must be granted
+		err = validateClusterSummaryReferences(ctx, c, clusterSummary, configv1beta1.FeatureHelm)
+		if err != nil {
+			return err
+		}
+
+		currentChart, err = resolveChartSourceRef(ctx, c, currentChart)
+		if err != nil {
+			return err
+		}
+		currentChart = getMirroredChart(currentChart)
+
+		// Polling is how newly published versions are discovered. Repository is always queried.
+		resolvedVersion, err := resolveChartVersion(ctx, clusterSummary, currentChart, true)
+		if err != nil {
+			return err
+		}
+
+		if getVersionUpgradeMode(currentChart.VersionPolicy) == configv1beta1.VersionUpgradeModeManual {
+			err = updateVersionsOnHelmChartSummary(ctx, c, currentChart, clusterSummary,
+				currentChart.ChartVersion, getPendingUpgradeVersion(currentChart, resolvedVersion))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if rs.ResolvedVersion != resolvedVersion {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("release %s/%s: new chart version %s available",
+				currentChart.ReleaseNamespace, currentChart.ReleaseName, resolvedVersion))
+			redeploy = true
+		}
+	}
+
+	if !redeploy {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		for i := range currentClusterSummary.Status.FeatureSummaries {
+			if currentClusterSummary.Status.FeatureSummaries[i].FeatureID == configv1beta1.FeatureHelm {
+				logger.V(logs.LogDebug).Info("redeploy helm")
+				currentClusterSummary.Status.FeatureSummaries[i].Hash = nil
+				currentClusterSummary.Status.FeatureSummaries[i].Status = configv1beta1.FeatureStatusProvisioning
+			}
+		}
+
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+}
+
+// isClusterSummaryAShardMatch returns true if ClusterSummary's cluster is a match for shardKey
+// and is owned by this addon-controller version
+func isClusterSummaryAShardMatch(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	shardKey string) (bool, error) {
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return sharding.IsShardAMatch(shardKey, cluster) && isMigrationVersionAMatch(cluster), nil
+}
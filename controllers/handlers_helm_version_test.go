@@ -0,0 +1,488 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const chartIndex = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 1.3.0
+    urls:
+    - nginx-1.3.0.tgz
+  - name: nginx
+    version: 1.2.5
+    urls:
+    - nginx-1.2.5.tgz
+  - name: nginx
+    version: 2.0.0-rc.1
+    urls:
+    - nginx-2.0.0-rc.1.tgz
+  - name: nginx
+    version: 2.1.0
+    urls:
+    - nginx-2.1.0.tgz
+`
+
+var _ = Describe("Helm chart VersionPolicy", func() {
+	var server *httptest.Server
+	var indexRequests atomic.Int32
+
+	BeforeEach(func() {
+		indexRequests.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			indexRequests.Add(1)
+			_, _ = w.Write([]byte(chartIndex))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("selectChartVersion returns highest version matching the policy", func() {
+		versions := []string{"1.2.0", "1.2.5", "1.3.0", "2.0.0", "2.1.0-rc.1", "invalid"}
+
+		version, err := controllers.SelectChartVersion(versions,
+			&configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: ">=1.2 <2.0"})
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("1.3.0"))
+
+		version, err = controllers.SelectChartVersion(versions,
+			&configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeLatest})
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("2.0.0"))
+
+		_, err = controllers.SelectChartVersion(versions,
+			&configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: ">=3.0"})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("getPendingUpgradeVersion returns resolved version only when newer than pinned one", func() {
+		chart := &configv1beta1.HelmChart{ChartVersion: "1.2.5"}
+		Expect(controllers.GetPendingUpgradeVersion(chart, "1.3.0")).To(Equal("1.3.0"))
+		Expect(controllers.GetPendingUpgradeVersion(chart, "1.2.5")).To(BeEmpty())
+	})
+
+	It("resolveChartVersion uses repository index", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{ClusterNamespace: randomString()},
+		}
+		chart := &configv1beta1.HelmChart{
+			RepositoryURL:    server.URL,
+			RepositoryName:   randomString(),
+			ChartVersion:     "1.2.5",
+			ReleaseName:      randomString(),
+			ReleaseNamespace: randomString(),
+			VersionPolicy:    &configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: "~1.2"},
+		}
+		chart.ChartName = chart.RepositoryName + "/nginx"
+
+		version, err := controllers.ResolveChartVersion(context.TODO(), clusterSummary, chart, false)
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("1.2.5"))
+
+		chart.VersionPolicy = &configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeLatest}
+		version, err = controllers.ResolveChartVersion(context.TODO(), clusterSummary, chart, false)
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("2.1.0"))
+	})
+
+	It("resolveChartVersion caches available versions unless refresh is set", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{ClusterNamespace: randomString()},
+		}
+		chart := &configv1beta1.HelmChart{
+			RepositoryURL:    server.URL,
+			RepositoryName:   randomString(),
+			ChartVersion:     "1.2.0",
+			ReleaseName:      randomString(),
+			ReleaseNamespace: randomString(),
+			VersionPolicy: &configv1beta1.VersionPolicy{
+				Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: ">=1.2.0 <2.0.0",
+			},
+		}
+		chart.ChartName = chart.RepositoryName + "/nginx"
+
+		version, err := controllers.ResolveChartVersion(context.TODO(), clusterSummary, chart, false)
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("1.3.0"))
+		Expect(indexRequests.Load()).To(Equal(int32(1)))
+
+		// A different policy on the same chart reuses the cached versions
+		chart.VersionPolicy.Constraint = "~1.2"
+		version, err = controllers.ResolveChartVersion(context.TODO(), clusterSummary, chart, false)
+		Expect(err).To(BeNil())
+		Expect(version).To(Equal("1.2.5"))
+		Expect(indexRequests.Load()).To(Equal(int32(1)))
+
+		_, err = controllers.ResolveChartVersion(context.TODO(), clusterSummary, chart, true)
+		Expect(err).To(BeNil())
+		Expect(indexRequests.Load()).To(Equal(int32(2)))
+	})
+
+	It("getChartVersionsCacheKey uses credentials namespace resolved for the cluster", func() {
+		chart := &configv1beta1.HelmChart{
+			RepositoryURL: server.URL,
+			ChartName:     randomString(),
+			RegistryCredentialsConfig: &configv1beta1.RegistryCredentialsConfig{
+				CredentialsSecretRef: &corev1.SecretReference{Name: randomString()},
+			},
+		}
+
+		clusterSummary1 := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{ClusterNamespace: randomString()},
+		}
+		clusterSummary2 := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{ClusterNamespace: randomString()},
+		}
+		Expect(controllers.GetChartVersionsCacheKey(clusterSummary1, chart)).ToNot(
+			Equal(controllers.GetChartVersionsCacheKey(clusterSummary2, chart)))
+
+		chart.RegistryCredentialsConfig.CredentialsSecretRef.Namespace = randomString()
+		Expect(controllers.GetChartVersionsCacheKey(clusterSummary1, chart)).To(
+			Equal(controllers.GetChartVersionsCacheKey(clusterSummary2, chart)))
+	})
+
+	It("resolveHelmChartVersions resolves versions once and records them in the HelmChartSummaries", func() {
+		repositoryName := randomString()
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{
+							RepositoryURL: server.URL, RepositoryName: repositoryName, ChartName: repositoryName + "/nginx",
+							ChartVersion: "1.2.0", ReleaseName: "auto", ReleaseNamespace: "nginx",
+							VersionPolicy: &configv1beta1.VersionPolicy{
+								Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: ">=1.2.0 <2.0.0",
+							},
+						},
+						{
+							RepositoryURL: server.URL, RepositoryName: repositoryName, ChartName: repositoryName + "/nginx",
+							ChartVersion: "1.2.5", ReleaseName: "manual", ReleaseNamespace: "nginx",
+							VersionPolicy: &configv1beta1.VersionPolicy{
+								Type: configv1beta1.VersionPolicyTypeLatest, UpgradeMode: configv1beta1.VersionUpgradeModeManual,
+							},
+						},
+					},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				HelmReleaseSummaries: []configv1beta1.HelmChartSummary{
+					{ReleaseName: "auto", ReleaseNamespace: "nginx", Status: configv1beta1.HelmChartStatusManaging},
+					{ReleaseName: "manual", ReleaseNamespace: "nginx", Status: configv1beta1.HelmChartStatusManaging},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		resolved, resolveErrors, err := controllers.ResolveHelmChartVersions(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(resolveErrors).To(BeEmpty())
+		Expect(resolved.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion).To(Equal("1.3.0"))
+		Expect(resolved.Spec.ClusterProfileSpec.HelmCharts[1].ChartVersion).To(Equal("1.2.5"))
+		// ClusterSummary itself is never modified
+		Expect(clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion).To(Equal("1.2.0"))
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[0].ResolvedVersion).To(Equal("1.3.0"))
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[1].PendingUpgradeVersion).To(Equal("2.1.0"))
+
+		// With ContinueOnError, failures are returned per helm release
+		clusterSummary.Spec.ClusterProfileSpec.ContinueOnError = true
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].VersionPolicy.Constraint = ">=3.0.0"
+		resolved, resolveErrors, err = controllers.ResolveHelmChartVersions(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(resolveErrors).To(HaveKey("nginx/auto"))
+		Expect(resolved.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion).To(Equal("1.2.0"))
+
+		clusterSummary.Spec.ClusterProfileSpec.ContinueOnError = false
+		_, _, err = controllers.ResolveHelmChartVersions(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("resolveHelmChartVersions deploys versions pinned by the chart versions ConfigMap", func() {
+		namespace := randomString()
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data:       map[string]string{"charts.jetstack.io_jetstack_cert-manager": "v1.14.4"},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Annotations: map[string]string{
+					controllers.ChartVersionsAnnotation: configMap.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: configv1beta1.ProfileKind, APIVersion: configv1beta1.GroupVersion.String(),
+						Name: randomString(), UID: types.UID(randomString())},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{
+							RepositoryURL: "https://charts.jetstack.io", RepositoryName: "jetstack",
+							ChartName: "jetstack/cert-manager", ChartVersion: "v1.14.3",
+							ReleaseName: "cert-manager", ReleaseNamespace: "cert-manager",
+						},
+						{
+							RepositoryURL: "https://kyverno.github.io/kyverno/", RepositoryName: "kyverno",
+							ChartName: "kyverno/kyverno", ChartVersion: "v3.0.1",
+							ReleaseName: "kyverno", ReleaseNamespace: "kyverno",
+						},
+					},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				HelmReleaseSummaries: []configv1beta1.HelmChartSummary{
+					{ReleaseName: "cert-manager", ReleaseNamespace: "cert-manager", Status: configv1beta1.HelmChartStatusManaging},
+					{ReleaseName: "kyverno", ReleaseNamespace: "kyverno", Status: configv1beta1.HelmChartStatusManaging,
+						ResolvedVersion: "v3.0.0"},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterSummary, configMap}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(clusterSummary).
+			WithObjects(initObjects...).Build()
+
+		resolved, resolveErrors, err := controllers.ResolveHelmChartVersions(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(resolveErrors).To(BeEmpty())
+		Expect(resolved.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion).To(Equal("v1.14.4"))
+		Expect(resolved.Spec.ClusterProfileSpec.HelmCharts[1].ChartVersion).To(Equal("v3.0.1"))
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[0].ResolvedVersion).To(Equal("v1.14.4"))
+		// Version not pinned anymore is cleared
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[1].ResolvedVersion).To(BeEmpty())
+
+		// A Profile cannot reference a ConfigMap in another namespace
+		clusterSummary.Annotations[controllers.ChartVersionsAnnotation] = randomString() + "/" + configMap.Name
+		_, _, err = controllers.ResolveHelmChartVersions(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("getChartVersionsKey returns repository URL and chart name", func() {
+		Expect(controllers.GetChartVersionsKey(&configv1beta1.HelmChart{
+			RepositoryURL: "https://charts.jetstack.io", RepositoryName: "jetstack",
+			ChartName: "jetstack/cert-manager"})).To(Equal("charts.jetstack.io_jetstack_cert-manager"))
+		Expect(controllers.GetChartVersionsKey(&configv1beta1.HelmChart{
+			RepositoryURL: "oci://registry-1.docker.io/bitnamicharts", RepositoryName: "oci-vault",
+			ChartName: "oci://registry-1.docker.io/bitnamicharts/vault"})).To(Equal("registry-1.docker.io_bitnamicharts_vault"))
+	})
+
+	It("getChartVersionsKey distinguishes charts with the same name from different repositories", func() {
+		bitnami := &configv1beta1.HelmChart{
+			RepositoryURL: "https://charts.bitnami.com/bitnami", RepositoryName: "nginx", ChartName: "nginx/nginx",
+		}
+		nginxInc := &configv1beta1.HelmChart{
+			RepositoryURL: "https://helm.nginx.com/stable", RepositoryName: "nginx", ChartName: "nginx/nginx",
+		}
+
+		Expect(controllers.GetChartVersionsKey(bitnami)).To(Equal("charts.bitnami.com_bitnami_nginx_nginx"))
+		Expect(controllers.GetChartVersionsKey(nginxInc)).To(Equal("helm.nginx.com_stable_nginx_nginx"))
+
+		// Only the chart of the pinned repository is deployed with the pinned version
+		namespace := randomString()
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data:       map[string]string{controllers.GetChartVersionsKey(nginxInc): "2.0.0"},
+		}
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        randomString(),
+				Annotations: map[string]string{controllers.ChartVersionsAnnotation: configMap.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: configv1beta1.ProfileKind, APIVersion: configv1beta1.GroupVersion.String(),
+						Name: randomString(), UID: types.UID(randomString())},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{*bitnami, *nginxInc},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		pinned, err := controllers.GetPinnedChartVersions(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(pinned).To(Equal(map[int]string{1: "2.0.0"}))
+	})
+
+	It("pollChartVersions returns when context is canceled", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan struct{})
+		go func() {
+			controllers.PollChartVersions(ctx, c, "", time.Millisecond, textlogger.NewLogger(textlogger.NewConfig()))
+			close(done)
+		}()
+
+		cancel()
+		Eventually(done, time.Second).Should(BeClosed())
+	})
+
+	It("validateHelmChartVersions requires semver ranges to be expressed with a VersionPolicy", func() {
+		spec := &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{ReleaseName: randomString(), ReleaseNamespace: randomString(), ChartVersion: "1.2.5"},
+			},
+		}
+		Expect(controllers.ValidateHelmChartVersions(spec)).To(Succeed())
+
+		spec.HelmCharts[0].ChartVersion = ">=1.2.0 <2.0.0"
+		err := controllers.ValidateHelmChartVersions(spec)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("is a semver range"))
+
+		spec.HelmCharts[0].ChartVersion = "1.2.5"
+		spec.HelmCharts[0].VersionPolicy = &configv1beta1.VersionPolicy{
+			Type:       configv1beta1.VersionPolicyTypeSemverRange,
+			Constraint: ">=1.2.0 <2.0.0",
+		}
+		Expect(controllers.ValidateHelmChartVersions(spec)).To(Succeed())
+
+		spec.HelmCharts[0].VersionPolicy.Constraint = "not a range"
+		Expect(controllers.ValidateHelmChartVersions(spec)).ToNot(Succeed())
+	})
+
+	It("pollClusterSummaryChartVersions redeploys or reports pending upgrade", func() {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		repositoryName := randomString()
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ClusterProfileKind,
+						Name: randomString(), UID: "1"},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{
+							RepositoryURL:    server.URL,
+							RepositoryName:   repositoryName,
+							ChartName:        repositoryName + "/nginx",
+							ChartVersion:     "1.2.5",
+							ReleaseName:      "nginx",
+							ReleaseNamespace: "nginx",
+							VersionPolicy: &configv1beta1.VersionPolicy{
+								Type: configv1beta1.VersionPolicyTypeSemverRange, Constraint: ">=1.2 <2.0",
+								UpgradeMode: configv1beta1.VersionUpgradeModeManual,
+							},
+						},
+					},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned, Hash: []byte(randomString())},
+				},
+				HelmReleaseSummaries: []configv1beta1.HelmChartSummary{
+					{ReleaseName: "nginx", ReleaseNamespace: "nginx", Status: configv1beta1.HelmChartStatusManaging},
+				},
+			},
+		}
+
+		initObjects := []client.Object{cluster, clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		Expect(controllers.PollClusterSummaryChartVersions(context.TODO(), c, clusterSummary, "", logger)).To(Succeed())
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.HelmReleaseSummaries[0].PendingUpgradeVersion).To(Equal("1.3.0"))
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Hash).ToNot(BeNil())
+
+		// Auto mode: new version causes helm feature to be redeployed
+		currentClusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].VersionPolicy.UpgradeMode =
+			configv1beta1.VersionUpgradeModeAuto
+		Expect(c.Update(context.TODO(), currentClusterSummary)).To(Succeed())
+
+		Expect(controllers.PollClusterSummaryChartVersions(context.TODO(), c, currentClusterSummary, "", logger)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Hash).To(BeNil())
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusProvisioning))
+	})
+})
@@ -0,0 +1,430 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdexlab/go-render/render"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// jobLabel is added to all Jobs created in managed clusters because of the Jobs feature
+	jobLabel = "projectsveltos.io/job"
+
+	// jobOwnerAnnotation is set on Jobs created in managed clusters. Value is ClusterSummary namespace/name
+	jobOwnerAnnotation = "projectsveltos.io/job-owner"
+
+	// jobHashAnnotation is set on Jobs created in managed clusters. Value is the hash of the Job manifest.
+	// Job spec is immutable, so when the manifest changes the Job is deleted and created again.
+	jobHashAnnotation = "projectsveltos.io/job-hash"
+
+	// jobLogsTailLines is the number of lines of the failed Job logs reported in the ClusterSummary status
+	jobLogsTailLines = 20
+)
+
+func deployJobs(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1beta1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	clusterSummary, remoteClient, err := getClusterSummaryAndClusterClient(ctx, clusterNamespace, applicant, c, logger)
+	if err != nil {
+		return err
+	}
+
+	err = validateClusterSummaryReferences(ctx, c, clusterSummary, configv1beta1.FeatureJobs)
+	if err != nil {
+		return err
+	}
+
+	remoteRestConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.V(logs.LogDebug).Info("deployJobs")
+
+	jobs, err := getJobs(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	if isDryRunSync(clusterSummary) {
+		logger.V(logs.LogDebug).Info("dry run mode. Jobs are not run.")
+		return nil
+	}
+
+	// Jobs are run in order. A Job is created only when all previous ones are completed.
+	for i := range jobs {
+		current, err := deployJob(ctx, remoteClient, jobs[i], logger)
+		if err != nil {
+			return err
+		}
+
+		if isJobFailed(current) {
+			clientset, err := kubernetes.NewForConfig(remoteRestConfig)
+			if err != nil {
+				return err
+			}
+			return &NonRetriableError{Message: getJobFailureMessage(ctx, clientset, current, logger)}
+		}
+
+		if !isJobCompleted(current) {
+			return fmt.Errorf("job %s/%s has not completed yet", current.Namespace, current.Name)
+		}
+	}
+
+	return removeStaleJobs(ctx, remoteClient, clusterSummary, jobs, logger)
+}
+
+func undeployJobs(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1beta1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	// Get ClusterSummary that requested this
+	clusterSummary, err := configv1beta1.GetClusterSummary(ctx, c, clusterNamespace, applicant)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	applyObserveOnly(clusterSummary)
+
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName)).
+		WithValues("clusterSummary", clusterSummary.Name).WithValues("admin", fmt.Sprintf("%s/%s", adminNamespace, adminName))
+
+	logger.V(logs.LogDebug).Info("undeployJobs")
+
+	if isDryRunSync(clusterSummary) {
+		logger.V(logs.LogDebug).Info("dry run mode. Jobs are not removed.")
+		return nil
+	}
+
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	return removeStaleJobs(ctx, remoteClient, clusterSummary, nil, logger)
+}
+
+// getJobs returns all the Jobs contained in the resources referenced by the ClusterSummary JobRefs.
+// Returned Jobs are ready to be created in the managed cluster.
+func getJobs(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) ([]*batchv1.Job, error) {
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*batchv1.Job, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.Jobs {
+		jobs, err := getJobRefJobs(ctx, c, clusterSummary, mgmtResources,
+			&clusterSummary.Spec.ClusterProfileSpec.Jobs[i], logger)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, jobs...)
+	}
+
+	return result, nil
+}
+
+// getJobRefJobs returns the Jobs contained in the resource referenced by jobRef, sorted by namespace/name.
+func getJobRefJobs(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	mgmtResources map[string]*unstructured.Unstructured, jobRef *configv1beta1.JobRef, logger logr.Logger,
+) ([]*batchv1.Job, error) {
+
+	valuesFrom := []configv1beta1.ValueFrom{{Namespace: jobRef.Namespace, Name: jobRef.Name, Kind: jobRef.Kind}}
+	template, nonTemplate, err := getValuesFrom(ctx, c, clusterSummary, valuesFrom, true, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	instantiated, err := collectContent(ctx, clusterSummary, mgmtResources, template, true, logger)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := collectContent(ctx, clusterSummary, mgmtResources, nonTemplate, false, logger)
+	if err != nil {
+		return nil, err
+	}
+	policies = append(instantiated, policies...)
+
+	// Data keys have no order. Sort Jobs so the order they are run in is stable.
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].GetNamespace()+"/"+policies[i].GetName() <
+			policies[j].GetNamespace()+"/"+policies[j].GetName()
+	})
+
+	result := make([]*batchv1.Job, 0, len(policies))
+	for j := range policies {
+		if policies[j].GetKind() != "Job" {
+			return nil, &NonRetriableError{Message: fmt.Sprintf("%s %s/%s contains a %s. Only Jobs are supported",
+				jobRef.Kind, jobRef.Namespace, jobRef.Name, policies[j].GetKind())}
+		}
+
+		job := &batchv1.Job{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(policies[j].UnstructuredContent(),
+			job); err != nil {
+			return nil, err
+		}
+
+		if err := prepareJob(job, jobRef, clusterSummary); err != nil {
+			return nil, err
+		}
+		result = append(result, job)
+	}
+
+	return result, nil
+}
+
+// prepareJob applies JobRef settings and adds the metadata used to track the Job
+func prepareJob(job *batchv1.Job, jobRef *configv1beta1.JobRef, clusterSummary *configv1beta1.ClusterSummary) error {
+	if job.Namespace == "" {
+		job.Namespace = metav1.NamespaceDefault
+	}
+
+	if jobRef.Timeout != nil && job.Spec.ActiveDeadlineSeconds == nil {
+		seconds := int64(jobRef.Timeout.Seconds())
+		job.Spec.ActiveDeadlineSeconds = &seconds
+	}
+
+	if jobRef.BackoffLimit != nil {
+		backoffLimit := *jobRef.BackoffLimit
+		job.Spec.BackoffLimit = &backoffLimit
+	}
+
+	manifest, err := json.Marshal(job.Spec)
+	if err != nil {
+		return err
+	}
+
+	labels := job.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[jobLabel] = "ok"
+	job.SetLabels(labels)
+
+	annotations := job.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[jobOwnerAnnotation] = fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name)
+	annotations[jobHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256(manifest))
+	job.SetAnnotations(annotations)
+
+	return nil
+}
+
+// deployJob creates the Job in the managed cluster, if not there already, and returns its current state.
+// If a Job with the same name exists but was created from a different manifest, it is deleted so
+// it can be created again.
+func deployJob(ctx context.Context, remoteClient client.Client, job *batchv1.Job, logger logr.Logger,
+) (*batchv1.Job, error) {
+
+	current := &batchv1.Job{}
+	err := remoteClient.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, current)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("creating job %s/%s", job.Namespace, job.Name))
+			if err := remoteClient.Create(ctx, job); err != nil {
+				return nil, err
+			}
+			return job, nil
+		}
+		return nil, err
+	}
+
+	if current.Annotations[jobOwnerAnnotation] != job.Annotations[jobOwnerAnnotation] {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("job %s/%s already exists and is not managed by %s",
+			job.Namespace, job.Name, job.Annotations[jobOwnerAnnotation])}
+	}
+
+	if current.Annotations[jobHashAnnotation] != job.Annotations[jobHashAnnotation] {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("job %s/%s has changed. Deleting it", job.Namespace, job.Name))
+		if err := deleteJob(ctx, remoteClient, current); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("job %s/%s is being recreated", job.Namespace, job.Name)
+	}
+
+	return current, nil
+}
+
+func isJobCompleted(job *batchv1.Job) bool {
+	return isJobConditionTrue(job, batchv1.JobComplete)
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	return isJobConditionTrue(job, batchv1.JobFailed)
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == conditionType &&
+			job.Status.Conditions[i].Status == corev1.ConditionTrue {
+
+			return true
+		}
+	}
+	return false
+}
+
+// getJobFailureMessage returns why the Job failed along with the last lines of logs of its most recent Pod
+func getJobFailureMessage(ctx context.Context, clientset kubernetes.Interface, job *batchv1.Job,
+	logger logr.Logger) string {
+
+	message := fmt.Sprintf("job %s/%s failed", job.Namespace, job.Name)
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == batchv1.JobFailed {
+			message += fmt.Sprintf(": %s %s", job.Status.Conditions[i].Reason, job.Status.Conditions[i].Message)
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", batchv1.JobNameLabel, job.Name)})
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list pods for job %s/%s: %v", job.Namespace, job.Name, err))
+		return message
+	}
+	if len(pods.Items) == 0 {
+		return message
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	pod := &pods.Items[len(pods.Items)-1]
+
+	tailLines := int64(jobLogsTailLines)
+	podLogs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name,
+		&corev1.PodLogOptions{TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get logs for pod %s/%s: %v", pod.Namespace, pod.Name, err))
+		return message
+	}
+
+	return fmt.Sprintf("%s. Pod %s logs:\n%s", message, pod.Name, strings.TrimSpace(string(podLogs)))
+}
+
+// removeStaleJobs deletes all Jobs created because of the ClusterSummary which are not in jobs anymore
+func removeStaleJobs(ctx context.Context, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	jobs []*batchv1.Job, logger logr.Logger) error {
+
+	current := make(map[types.NamespacedName]bool, len(jobs))
+	for i := range jobs {
+		current[types.NamespacedName{Namespace: jobs[i].Namespace, Name: jobs[i].Name}] = true
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := remoteClient.List(ctx, jobList, client.MatchingLabels{jobLabel: "ok"}); err != nil {
+		return err
+	}
+
+	owner := fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name)
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Annotations[jobOwnerAnnotation] != owner {
+			continue
+		}
+		if current[types.NamespacedName{Namespace: job.Namespace, Name: job.Name}] {
+			continue
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("deleting stale job %s/%s", job.Namespace, job.Name))
+		if err := deleteJob(ctx, remoteClient, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteJob(ctx context.Context, remoteClient client.Client, job *batchv1.Job) error {
+	// Pods created by the Job must be removed as well
+	err := remoteClient.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// jobsHash returns the hash of all the JobRefs and of the content of the referenced resources
+func jobsHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) ([]byte, error) {
+
+	clusterProfileSpecHash, err := getClusterProfileSpecHash(ctx, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	var config string
+	config += string(clusterProfileSpecHash)
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	config += render.AsCode(clusterSummary.Spec.ClusterProfileSpec.Jobs)
+
+	valuesFrom := make([]configv1beta1.ValueFrom, len(clusterSummary.Spec.ClusterProfileSpec.Jobs))
+	for i := range clusterSummary.Spec.ClusterProfileSpec.Jobs {
+		jobRef := &clusterSummary.Spec.ClusterProfileSpec.Jobs[i]
+		valuesFrom[i] = configv1beta1.ValueFrom{Namespace: jobRef.Namespace, Name: jobRef.Name, Kind: jobRef.Kind}
+	}
+
+	valuesFromHash, err := getValuesFromResourceHash(ctx, c, clusterSummary, valuesFrom, logger)
+	if err != nil {
+		return nil, err
+	}
+	config += valuesFromHash
+
+	h.Write([]byte(config))
+	return h.Sum(nil), nil
+}
+
+func getJobRefs(clusterSummary *configv1beta1.ClusterSummary) []configv1beta1.PolicyRef {
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const jobTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: bootstrap
+        image: busybox
+        command: ["sh", "-c", "%s"]`
+
+var _ = Describe("Jobs", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var configMap *corev1.ConfigMap
+	var namespace string
+
+	BeforeEach(func() {
+		namespace = randomString()
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Data: map[string]string{
+				"first":  fmt.Sprintf(jobTemplate, "a-init", "bootstrap", "echo init"),
+				"second": fmt.Sprintf(jobTemplate, "b-seed", "bootstrap", "echo seed"),
+			},
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					Jobs: []configv1beta1.JobRef{
+						{
+							Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Name: configMap.Name,
+							Timeout:      &metav1.Duration{Duration: 5 * time.Minute},
+							BackoffLimit: func() *int32 { v := int32(2); return &v }(),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("getJobs returns, sorted, the Jobs contained in referenced resources", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+		jobs, err := controllers.GetJobs(context.TODO(), c, clusterSummary, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(len(jobs)).To(Equal(2))
+		Expect(jobs[0].Name).To(Equal("a-init"))
+		Expect(jobs[1].Name).To(Equal("b-seed"))
+		Expect(*jobs[0].Spec.ActiveDeadlineSeconds).To(Equal(int64(300)))
+		Expect(*jobs[0].Spec.BackoffLimit).To(Equal(int32(2)))
+		Expect(jobs[0].Annotations[controllers.JobOwnerAnnotation]).To(
+			Equal(fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name)))
+
+		configMap.Data["third"] = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: foo
+  namespace: bar`
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		_, err = controllers.GetJobs(context.TODO(), c, clusterSummary, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("deployJob creates the Job and recreates it when its manifest changes", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		jobs, err := controllers.GetJobs(context.TODO(), c, clusterSummary, logger)
+		Expect(err).To(BeNil())
+
+		_, err = controllers.DeployJob(context.TODO(), remoteClient, jobs[0], logger)
+		Expect(err).To(BeNil())
+
+		current := &batchv1.Job{}
+		Expect(remoteClient.Get(context.TODO(), types.NamespacedName{Namespace: "bootstrap", Name: "a-init"},
+			current)).To(Succeed())
+
+		// Running again does not change anything
+		_, err = controllers.DeployJob(context.TODO(), remoteClient, jobs[0], logger)
+		Expect(err).To(BeNil())
+
+		configMap.Data["first"] = fmt.Sprintf(jobTemplate, "a-init", "bootstrap", "echo init v2")
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		jobs, err = controllers.GetJobs(context.TODO(), c, clusterSummary, logger)
+		Expect(err).To(BeNil())
+
+		_, err = controllers.DeployJob(context.TODO(), remoteClient, jobs[0], logger)
+		Expect(err).ToNot(BeNil())
+		err = remoteClient.Get(context.TODO(), types.NamespacedName{Namespace: "bootstrap", Name: "a-init"}, current)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("removeStaleJobs removes only Jobs created by the ClusterSummary and not referenced anymore", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		jobs, err := controllers.GetJobs(context.TODO(), c, clusterSummary, logger)
+		Expect(err).To(BeNil())
+		for i := range jobs {
+			_, err = controllers.DeployJob(context.TODO(), remoteClient, jobs[i], logger)
+			Expect(err).To(BeNil())
+		}
+
+		Expect(controllers.RemoveStaleJobs(context.TODO(), remoteClient, clusterSummary, jobs[:1], logger)).To(Succeed())
+
+		jobList := &batchv1.JobList{}
+		Expect(remoteClient.List(context.TODO(), jobList)).To(Succeed())
+		Expect(len(jobList.Items)).To(Equal(1))
+		Expect(jobList.Items[0].Name).To(Equal("a-init"))
+
+		Expect(controllers.RemoveStaleJobs(context.TODO(), remoteClient, clusterSummary, nil, logger)).To(Succeed())
+		Expect(remoteClient.List(context.TODO(), jobList)).To(Succeed())
+		Expect(len(jobList.Items)).To(BeZero())
+	})
+
+	It("getJobFailureMessage reports failure reason and pod logs", func() {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "bootstrap", Name: "a-init"},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+						Message: "Job has reached the specified backoff limit"},
+				},
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: job.Namespace,
+				Name:      job.Name + "-abcde",
+				Labels:    map[string]string{batchv1.JobNameLabel: job.Name},
+			},
+		}
+
+		clientset := fakeclientset.NewSimpleClientset(pod)
+		message := controllers.GetJobFailureMessage(context.TODO(), clientset, job,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(message).To(ContainSubstring("BackoffLimitExceeded"))
+		Expect(message).To(ContainSubstring(pod.Name))
+		// fake clientset always returns "fake logs"
+		Expect(message).To(ContainSubstring("fake logs"))
+	})
+})
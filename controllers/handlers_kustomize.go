@@ -48,9 +48,9 @@ import (
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	"github.com/projectsveltos/libsveltos/lib/funcmap"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
@@ -99,6 +99,11 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
+	err = validateClusterSummaryReferences(ctx, c, clusterSummary, configv1beta1.FeatureKustomize)
+	if err != nil {
+		return err
+	}
+
 	remoteRestConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
@@ -112,8 +117,28 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
+	// When WriteBudget is set, only a chunk of the resources is applied in this pass
+	ctx = withWriteBudget(ctx, clusterSummary, configv1beta1.FeatureKustomize)
+	// Number and size of resources deployed are limited by the Guardrails, if any
+	ctx = withGuardrails(ctx)
+	// Deployment is aborted if ClusterSummary Spec changes in the meantime
+	ctx = withDeploymentRevision(ctx, c, clusterSummary)
+	// Image tags are pinned to digests, if ImageDigestResolution is set
+	ctx, err = withImageDigests(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Rendered resources are passed through the WasmPlugins, if any
+	ctx, err = withWasmPlugins(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Resources managed by a different profile are reported in the Status
+	ctx = withConflicts(ctx)
+
 	localResourceReports, remoteResourceReports, deployError := deployEachKustomizeRefs(ctx, c, remoteRestConfig,
 		clusterSummary, logger)
+	recordConflicts(ctx, clusterSummary, configv1beta1.FeatureKustomize, deployError, logger)
 
 	// Irrespective of error, update deployed gvks. Otherwise cleanup won't happen in case
 	var gvkErr error
@@ -123,6 +148,11 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return gvkErr
 	}
 
+	if isDeploymentSupersededError(deployError) {
+		// Stale resources are cleaned by the deployment of the latest Spec
+		return deployError
+	}
+
 	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
 		return err
@@ -146,13 +176,17 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
-	var undeployed []configv1beta1.ResourceReport
-	_, undeployed, err = cleanStaleKustomizeResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
-		localResourceReports, remoteResourceReports, logger)
-	if err != nil {
-		return err
+	// With ContinueOnError, resources contained in items which failed to be deployed are missing from
+	// the reports. Those must not be considered stale.
+	if deployError == nil || !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+		var undeployed []configv1beta1.ResourceReport
+		_, undeployed, err = cleanStaleKustomizeResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
+			localResourceReports, remoteResourceReports, logger)
+		if err != nil {
+			return err
+		}
+		remoteResourceReports = append(remoteResourceReports, undeployed...)
 	}
-	remoteResourceReports = append(remoteResourceReports, undeployed...)
 
 	err = handleWatchers(ctx, clusterSummary, localResourceReports, featureHandler)
 	if err != nil {
@@ -178,6 +212,12 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return deployError
 	}
 
+	if err := getWriteBudget(ctx).complete(); err != nil {
+		return err
+	}
+
+	recordResolvedImages(ctx, clusterSummary, configv1beta1.FeatureKustomize, logger)
+
 	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureKustomize, logger)
 }
 
@@ -213,6 +253,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 		}
 		return err
 	}
+	applyObserveOnly(clusterSummary)
 
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
@@ -238,7 +279,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -309,6 +350,17 @@ func kustomizationHash(ctx context.Context, c client.Client, clusterSummaryScope
 		}
 		config += string(result)
 
+		// When Path is a template, a change in the Cluster (labels for instance) can lead
+		// to a different path being deployed
+		if strings.Contains(kustomizationRef.Path, "{{") {
+			instantiatedPath, err := instantiateKustomizationPath(ctx, clusterSummary, kustomizationRef, logger)
+			if err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate path %v", err))
+				return nil, err
+			}
+			config += instantiatedPath
+		}
+
 		valueFromHash, err := getKustomizeReferenceResourceHash(ctx, c, clusterSummary,
 			kustomizationRef, logger)
 		if err != nil {
@@ -399,10 +451,8 @@ func instantiateKustomizeSubstituteValues(ctx context.Context, clusterSummary *c
 
 	requestorName := clusterSummary.Namespace + clusterSummary.Name + "kustomize"
 
-	instantiatedValue, err :=
-		instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-			clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
-			requestorName, stringifiedValues, mgmtResources, logger)
+	instantiatedValue, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
+		requestorName, stringifiedValues, mgmtResources, logger)
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate values %v", err))
 		return nil, err
@@ -473,19 +523,15 @@ func deployKustomizeRef(ctx context.Context, c client.Client, remoteRestConfig *
 
 	defer os.RemoveAll(tmpDir)
 
-	// Path can be expressed as a template and instantiate using Cluster fields.
-	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-		clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
-		clusterSummary.GetName(), kustomizationRef.Path, nil, logger)
+	instantiatedPath, err := instantiateKustomizationPath(ctx, clusterSummary, kustomizationRef, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	logger.V(logs.LogDebug).Info(fmt.Sprintf("using path %s", instantiatedPath))
 
-	// check build path exists
-	dirPath := filepath.Join(tmpDir, instantiatedPath)
-	_, err = os.Stat(dirPath)
+	// check build paths exist
+	dirPaths, err := getSourcePaths(tmpDir, instantiatedPath)
 	if err != nil {
 		err = fmt.Errorf("kustomization path not found: %w", err)
 		return nil, nil, err
@@ -493,13 +539,33 @@ func deployKustomizeRef(ctx context.Context, c client.Client, remoteRestConfig *
 
 	fs := filesys.MakeFsOnDisk()
 
-	var resMap resmap.ResMap
-	resMap, err = buildKustomization(fs, dirPath)
-	if err != nil {
-		return nil, nil, err
+	for i := range dirPaths {
+		var resMap resmap.ResMap
+		resMap, err = buildKustomization(fs, dirPaths[i])
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+
+		var tmpLocal, tmpRemote []configv1beta1.ResourceReport
+		tmpLocal, tmpRemote, err = deployKustomizeResources(ctx, c, remoteRestConfig, kustomizationRef, resMap,
+			clusterSummary, logger)
+		localReports = append(localReports, tmpLocal...)
+		remoteReports = append(remoteReports, tmpRemote...)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
 	}
 
-	return deployKustomizeResources(ctx, c, remoteRestConfig, kustomizationRef, resMap, clusterSummary, logger)
+	return localReports, remoteReports, nil
+}
+
+// instantiateKustomizationPath returns the KustomizationRef Path. Path can be expressed as a template
+// (e.g. overlays/{{ .Cluster.metadata.labels.region }}) and instantiated using Cluster fields.
+func instantiateKustomizationPath(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	kustomizationRef *configv1beta1.KustomizationRef, logger logr.Logger) (string, error) {
+
+	return instantiateClusterSummaryTemplate(ctx, clusterSummary,
+		clusterSummary.GetName(), kustomizationRef.Path, nil, logger)
 }
 
 func prepareFileSystem(ctx context.Context, c client.Client,
@@ -821,19 +887,26 @@ func deployEachKustomizeRefs(ctx context.Context, c client.Client, remoteRestCon
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger,
 ) (localResourceReports, remoteResourceReports []configv1beta1.ResourceReport, err error) {
 
+	deployErrors := &deploymentErrors{}
 	for i := range clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs {
 		kustomizationRef := &clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[i]
 		var tmpLocal []configv1beta1.ResourceReport
 		var tmpRemote []configv1beta1.ResourceReport
 		tmpLocal, tmpRemote, err = deployKustomizeRef(ctx, c, remoteRestConfig, kustomizationRef, clusterSummary, logger)
 		if err != nil {
+			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				deployErrors.add(fmt.Sprintf("kustomizationRef %s %s/%s", kustomizationRef.Kind,
+					kustomizationRef.Namespace, kustomizationRef.Name), err)
+				continue
+			}
 			return nil, nil, err
 		}
 		localResourceReports = append(localResourceReports, tmpLocal...)
 		remoteResourceReports = append(remoteResourceReports, tmpRemote...)
 	}
 
-	return localResourceReports, remoteResourceReports, err
+	return localResourceReports, remoteResourceReports,
+		deployErrors.toError(len(clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs))
 }
 
 func extractTarGz(src, dest string) error {
@@ -321,6 +321,7 @@ var _ = Describe("Hash methods", func() {
 		config += fmt.Sprintf("%v", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Reloader)
 		config += fmt.Sprintf("%v", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tier)
 		config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict)
+		config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnError)
 		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Patches)
 		h := sha256.New()
 		h.Write([]byte(config))
@@ -343,6 +344,72 @@ var _ = Describe("Hash methods", func() {
 		Expect(reflect.DeepEqual(hash, expectHash)).To(BeTrue())
 	})
 
+	It("kustomizationHash considers instantiated Path when Path is a template", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"region": "eu"},
+			},
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sveltosCluster.Namespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(testEnv.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, sveltosCluster)).To(Succeed())
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: sveltosCluster.Namespace,
+				ClusterName:      sveltosCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					KustomizationRefs: []configv1beta1.KustomizationRef{
+						{
+							Namespace: randomString(), Name: randomString(), Kind: sourcev1.GitRepositoryKind,
+							Path: "overlays/{{ .Cluster.metadata.labels.region }}",
+						},
+					},
+				},
+			},
+		}
+
+		path, err := controllers.InstantiateKustomizationPath(context.TODO(), clusterSummary,
+			&clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[0], textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(path).To(Equal("overlays/eu"))
+
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		hash, err := controllers.KustomizationHash(context.TODO(), c, clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		// Changing cluster labels makes a different path to be deployed
+		Expect(testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: sveltosCluster.Namespace, Name: sveltosCluster.Name}, sveltosCluster)).To(Succeed())
+		sveltosCluster.Labels = map[string]string{"region": "us"}
+		Expect(testEnv.Update(context.TODO(), sveltosCluster)).To(Succeed())
+
+		Eventually(func() bool {
+			newHash, err := controllers.KustomizationHash(context.TODO(), c, clusterSummaryScope,
+				textlogger.NewLogger(textlogger.NewConfig()))
+			return err == nil && !reflect.DeepEqual(hash, newHash)
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+
 	It(`getKustomizeReferenceResourceHash returns the hash considering all referenced 
 	ConfigMap/Secret in the ValueFrom section`, func() {
 		namespace := randomString()
@@ -710,3 +777,46 @@ func createTarGz(dest string) {
 	})
 	Expect(err).To(BeNil())
 }
+
+var _ = Describe("ContinueOnError", func() {
+	It("deployEachKustomizeRefs deploys remaining KustomizationRefs only when ContinueOnError is set", func() {
+		namespace := randomString()
+
+		// Neither ConfigMap contains kustomize.tar.gz, so deploying any KustomizationRef fails
+		configMaps := []*corev1.ConfigMap{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()}},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+		for i := range configMaps {
+			clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs = append(
+				clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs,
+				configv1beta1.KustomizationRef{
+					Namespace: namespace, Name: configMaps[i].Name,
+					Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+				})
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMaps[0], configMaps[1]).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		_, _, err := controllers.DeployEachKustomizeRefs(context.TODO(), c, nil, clusterSummary, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).ToNot(ContainSubstring(configMaps[1].Name))
+
+		clusterSummary.Spec.ClusterProfileSpec.ContinueOnError = true
+		_, _, err = controllers.DeployEachKustomizeRefs(context.TODO(), c, nil, clusterSummary, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("2 of 2 failed to deploy"))
+		Expect(err.Error()).To(ContainSubstring(configMaps[0].Name))
+		Expect(err.Error()).To(ContainSubstring(configMaps[1].Name))
+	})
+})
@@ -0,0 +1,205 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// verbs needed to create, update and remove (when stale) a resource
+	generatedRBACVerbs = []string{"get", "update", "patch", "delete"}
+)
+
+// getGeneratedRBACName returns the name of the Roles/ClusterRole generated for clusterSummary
+func getGeneratedRBACName(clusterSummary *configv1beta1.ClusterSummary) string {
+	return fmt.Sprintf("sveltos-%s", clusterSummary.Name)
+}
+
+type rbacResource struct {
+	group    string
+	resource string
+}
+
+// getGeneratedRBAC returns the minimal Roles (one per namespace) and ClusterRole needed to apply
+// the resources in reports. Since namespaces are created when missing, when there is at least one
+// namespaced resource, ClusterRole also allows to get and create namespaces.
+func getGeneratedRBAC(reports []configv1beta1.ResourceReport, mapper meta.RESTMapper, name string,
+) ([]*unstructured.Unstructured, error) {
+
+	// key: namespace (empty for cluster wide resources); value: resource names by group/resource
+	resources := make(map[string]map[rbacResource]map[string]bool)
+	for i := range reports {
+		r := &reports[i].Resource
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: r.Group, Kind: r.Kind}, r.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace = r.Namespace
+		}
+
+		if _, ok := resources[namespace]; !ok {
+			resources[namespace] = make(map[rbacResource]map[string]bool)
+		}
+		key := rbacResource{group: r.Group, resource: mapping.Resource.Resource}
+		if _, ok := resources[namespace][key]; !ok {
+			resources[namespace][key] = make(map[string]bool)
+		}
+		resources[namespace][key][r.Name] = true
+	}
+
+	if len(resources) == 0 {
+		return nil, nil
+	}
+
+	clusterRules := getPolicyRules(resources[""])
+	if len(resources) > 1 || resources[""] == nil {
+		clusterRules = append(clusterRules, rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "create"},
+		})
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(resources))
+	clusterRole := &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		Rules:    clusterRules,
+	}
+	clusterRole.Name = name
+	u, err := toUnstructured(clusterRole)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, u)
+
+	namespaces := make([]string, 0, len(resources))
+	for ns := range resources {
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		role := &rbacv1.Role{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			Rules:    getPolicyRules(resources[ns]),
+		}
+		role.Namespace = ns
+		role.Name = name
+		u, err := toUnstructured(role)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, u)
+	}
+
+	return result, nil
+}
+
+// getPolicyRules returns, sorted, the rules needed to create and manage resources. Create cannot
+// be restricted by resource name, so there is a separate rule for it.
+func getPolicyRules(resources map[rbacResource]map[string]bool) []rbacv1.PolicyRule {
+	keys := make([]rbacResource, 0, len(resources))
+	for k := range resources {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].resource < keys[j].resource
+	})
+
+	rules := make([]rbacv1.PolicyRule, 0, 2*len(keys))
+	for _, k := range keys {
+		names := make([]string, 0, len(resources[k]))
+		for n := range resources[k] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		rules = append(rules,
+			rbacv1.PolicyRule{APIGroups: []string{k.group}, Resources: []string{k.resource}, Verbs: []string{"create"}},
+			rbacv1.PolicyRule{APIGroups: []string{k.group}, Resources: []string{k.resource}, ResourceNames: names,
+				Verbs: generatedRBACVerbs},
+		)
+	}
+
+	return rules
+}
+
+// deployGeneratedRBAC deploys in the managed cluster the RBAC needed to apply the resources in reports
+func deployGeneratedRBAC(ctx context.Context, remoteClient client.Client, remoteConfig *rest.Config,
+	clusterSummary *configv1beta1.ClusterSummary, reports []configv1beta1.ResourceReport,
+	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger) ([]configv1beta1.ResourceReport, error) {
+
+	dc, err := discovery.NewDiscoveryClientForConfig(remoteConfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+
+	resources, err := getGeneratedRBAC(reports, mapper, getGeneratedRBACName(clusterSummary))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("deploying %d generated RBAC resources", len(resources)))
+
+	// Generated resources are tracked as coming from the ClusterSummary itself
+	ref := &corev1.ObjectReference{
+		Kind:      configv1beta1.ClusterSummaryKind,
+		Namespace: clusterSummary.Namespace,
+		Name:      clusterSummary.Name,
+	}
+	return deployUnstructured(ctx, false, remoteConfig, remoteClient, resources, ref,
+		configv1beta1.FeatureResources, clusterSummary, mgmtResources, nil, logger)
+}
+
+func toUnstructured(o runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{Object: content}
+	// Drop the null creationTimestamp set by the conversion
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	return u, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Generated RBAC", func() {
+	It("getGeneratedRBAC returns minimal ClusterRole and Roles for deployed resources", func() {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+		mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+		reports := []configv1beta1.ResourceReport{
+			{Resource: configv1beta1.Resource{Group: appsv1.GroupName, Version: "v1", Kind: "Deployment",
+				Namespace: "nginx", Name: "nginx"}},
+			{Resource: configv1beta1.Resource{Group: appsv1.GroupName, Version: "v1", Kind: "Deployment",
+				Namespace: "nginx", Name: "api"}},
+			{Resource: configv1beta1.Resource{Group: rbacv1.GroupName, Version: "v1", Kind: "ClusterRole",
+				Name: "viewer"}},
+		}
+
+		name := randomString()
+		result, err := controllers.GetGeneratedRBAC(reports, mapper, name)
+		Expect(err).To(BeNil())
+		Expect(len(result)).To(Equal(2))
+
+		clusterRole := &rbacv1.ClusterRole{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(result[0].Object, clusterRole)).To(Succeed())
+		Expect(clusterRole.Name).To(Equal(name))
+		Expect(clusterRole.Rules).To(ContainElement(rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName},
+			Resources: []string{"clusterroles"}, ResourceNames: []string{"viewer"},
+			Verbs: []string{"get", "update", "patch", "delete"}}))
+		Expect(clusterRole.Rules).To(ContainElement(rbacv1.PolicyRule{APIGroups: []string{""},
+			Resources: []string{"namespaces"}, Verbs: []string{"get", "create"}}))
+
+		role := &rbacv1.Role{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(result[1].Object, role)).To(Succeed())
+		Expect(role.Namespace).To(Equal("nginx"))
+		Expect(role.Name).To(Equal(name))
+		Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{APIGroups: []string{appsv1.GroupName},
+			Resources: []string{"deployments"}, Verbs: []string{"create"}}))
+		Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{APIGroups: []string{appsv1.GroupName},
+			Resources: []string{"deployments"}, ResourceNames: []string{"api", "nginx"},
+			Verbs: []string{"get", "update", "patch", "delete"}}))
+	})
+
+	It("getGeneratedRBAC returns nothing when no resource is deployed", func() {
+		result, err := controllers.GetGeneratedRBAC(nil, meta.NewDefaultRESTMapper(nil), randomString())
+		Expect(err).To(BeNil())
+		Expect(result).To(BeEmpty())
+	})
+})
@@ -0,0 +1,302 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// reloadWorkloadsHashesAnnotation is set on Deployments/StatefulSets consuming ConfigMaps/Secrets
+	// deployed from PolicyRefs with ReloadWorkloads. Value is a JSON map with the hash of each consumed
+	// ConfigMap/Secret. It is also set on the pod template to trigger a rolling restart.
+	reloadWorkloadsHashesAnnotation = "projectsveltos.io/reload-hashes"
+
+	configMapKind = "ConfigMap"
+	secretKind    = "Secret"
+)
+
+// reloadWorkloads starts a rolling restart of the Deployments/StatefulSets in the managed cluster consuming a
+// ConfigMap/Secret, deployed from a PolicyRef with ReloadWorkloads, whose content changed since last time.
+func reloadWorkloads(ctx context.Context, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	remoteResourceReports []configv1beta1.ResourceReport, logger logr.Logger) error {
+
+	owners, err := getReloadWorkloadsOwners(clusterSummary)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 || isDryRunSync(clusterSummary) {
+		return nil
+	}
+
+	// key: <kind>/<namespace>/<name> of a ConfigMap/Secret; value: hash of its content
+	deployed := make(map[string]string)
+	for i := range remoteResourceReports {
+		resource := &remoteResourceReports[i].Resource
+		if resource.Group != "" || (resource.Kind != configMapKind && resource.Kind != secretKind) {
+			continue
+		}
+		if !owners[getReloadWorkloadsKey(resource.Owner.Kind, resource.Owner.Namespace, resource.Owner.Name)] {
+			continue
+		}
+
+		var hash string
+		hash, err = getDeployedDataHash(ctx, remoteClient, resource)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		deployed[getReloadWorkloadsKey(resource.Kind, resource.Namespace, resource.Name)] = hash
+	}
+
+	if len(deployed) == 0 {
+		return nil
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := remoteClient.List(ctx, deployments); err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		err = reloadWorkload(ctx, remoteClient, d, &d.Spec.Template, deployed, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := remoteClient.List(ctx, statefulSets); err != nil {
+		return err
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		err = reloadWorkload(ctx, remoteClient, s, &s.Spec.Template, deployed, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reloadWorkload restarts workload if any of the ConfigMaps/Secrets it consumes has a new hash
+func reloadWorkload(ctx context.Context, remoteClient client.Client, workload client.Object,
+	template *corev1.PodTemplateSpec, deployed map[string]string, logger logr.Logger) error {
+
+	hashes := make(map[string]string)
+	for _, key := range getConsumedConfigKeys(workload.GetNamespace(), &template.Spec) {
+		if hash, ok := deployed[key]; ok {
+			hashes[key] = hash
+		}
+	}
+
+	tracked, changed, restart := trackConsumedHashes(workload.GetAnnotations(), reloadWorkloadsHashesAnnotation, hashes)
+	if !changed {
+		return nil
+	}
+
+	err := patchWorkloadHashes(ctx, remoteClient, workload, template, reloadWorkloadsHashesAnnotation, tracked, restart)
+	if err != nil {
+		return err
+	}
+
+	if restart {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("consumed ConfigMap/Secret changed. Restarted %s/%s",
+			workload.GetNamespace(), workload.GetName()))
+	}
+	return nil
+}
+
+// getReloadWorkloadsOwners returns the ConfigMaps/Secrets referenced by PolicyRefs with ReloadWorkloads set
+func getReloadWorkloadsOwners(clusterSummary *configv1beta1.ClusterSummary) (map[string]bool, error) {
+	owners := make(map[string]bool)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
+		if !ref.ReloadWorkloads || ref.DeploymentType == configv1beta1.DeploymentTypeLocal {
+			continue
+		}
+
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Namespace, ref.Namespace)
+		name, err := libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		owners[getReloadWorkloadsKey(ref.Kind, namespace, name)] = true
+	}
+
+	return owners, nil
+}
+
+func getReloadWorkloadsKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// getDeployedDataHash returns the hash of the content of a ConfigMap/Secret deployed in the managed cluster
+func getDeployedDataHash(ctx context.Context, remoteClient client.Client, resource *configv1beta1.Resource,
+) (string, error) {
+
+	key := types.NamespacedName{Namespace: resource.Namespace, Name: resource.Name}
+
+	var config string
+	if resource.Kind == configMapKind {
+		configMap := &corev1.ConfigMap{}
+		if err := remoteClient.Get(ctx, key, configMap); err != nil {
+			return "", err
+		}
+		config = getDataSectionHash(configMap.Data) + getDataSectionHash(configMap.BinaryData)
+	} else {
+		secret := &corev1.Secret{}
+		if err := remoteClient.Get(ctx, key, secret); err != nil {
+			return "", err
+		}
+		config = getDataSectionHash(secret.Data)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(config))), nil
+}
+
+// getConsumedConfigKeys returns the ConfigMaps/Secrets a pod consumes as volumes, envFrom or env
+func getConsumedConfigKeys(namespace string, podSpec *corev1.PodSpec) []string {
+	keys := make([]string, 0)
+	add := func(kind, name string) {
+		keys = append(keys, getReloadWorkloadsKey(kind, namespace, name))
+	}
+
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		if volume.ConfigMap != nil {
+			add(configMapKind, volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			add(secretKind, volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for j := range volume.Projected.Sources {
+				source := &volume.Projected.Sources[j]
+				if source.ConfigMap != nil {
+					add(configMapKind, source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					add(secretKind, source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for i := range containers {
+		for j := range containers[i].EnvFrom {
+			envFrom := &containers[i].EnvFrom[j]
+			if envFrom.ConfigMapRef != nil {
+				add(configMapKind, envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				add(secretKind, envFrom.SecretRef.Name)
+			}
+		}
+		for j := range containers[i].Env {
+			valueFrom := containers[i].Env[j].ValueFrom
+			if valueFrom == nil {
+				continue
+			}
+			if valueFrom.ConfigMapKeyRef != nil {
+				add(configMapKind, valueFrom.ConfigMapKeyRef.Name)
+			}
+			if valueFrom.SecretKeyRef != nil {
+				add(secretKind, valueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	return keys
+}
+
+// trackConsumedHashes merges hashes into the JSON map stored in annotation. Returns the merged map, whether
+// it differs from the stored one and whether a hash already stored changed, in which case the workload
+// must be restarted. Hashes seen for the first time do not cause a restart.
+func trackConsumedHashes(annotations map[string]string, annotation string, hashes map[string]string,
+) (tracked map[string]string, changed, restart bool) {
+
+	tracked = make(map[string]string)
+	if current := annotations[annotation]; current != "" {
+		if err := json.Unmarshal([]byte(current), &tracked); err != nil {
+			// Invalid value is reset. Start tracking again.
+			tracked = make(map[string]string)
+		}
+	}
+
+	for k, hash := range hashes {
+		previous, ok := tracked[k]
+		if ok && previous == hash {
+			continue
+		}
+		if ok {
+			restart = true
+		}
+		tracked[k] = hash
+		changed = true
+	}
+
+	return tracked, changed, restart
+}
+
+// patchWorkloadHashes records tracked hashes on the workload. If restart is set, hashes are also set on
+// the workload pod template (which must belong to workload), starting a rolling restart.
+func patchWorkloadHashes(ctx context.Context, remoteClient client.Client, workload client.Object,
+	template *corev1.PodTemplateSpec, annotation string, tracked map[string]string, restart bool) error {
+
+	value, err := json.Marshal(tracked)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+
+	annotations := workload.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotation] = string(value)
+	workload.SetAnnotations(annotations)
+
+	if restart {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[annotation] = string(value)
+	}
+
+	return remoteClient.Patch(ctx, workload, patch)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	reloadWorkloadsHashesAnnotation = "projectsveltos.io/reload-hashes"
+)
+
+var _ = Describe("Reload workloads", func() {
+	It("reloadWorkloads restarts Deployments consuming a changed ConfigMap deployed by a PolicyRef", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		namespace := randomString()
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					PolicyRefs: []configv1beta1.PolicyRef{
+						{
+							Namespace: randomString(), Name: randomString(), ReloadWorkloads: true,
+							Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+						},
+					},
+				},
+			},
+		}
+		policyRef := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[0]
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data:       map[string]string{"key": randomString()},
+		}
+
+		consumer := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: randomString(),
+								EnvFrom: []corev1.EnvFromSource{
+									{
+										ConfigMapRef: &corev1.ConfigMapEnvSource{
+											LocalObjectReference: corev1.LocalObjectReference{Name: configMap.Name},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		other := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+		}
+
+		reports := []configv1beta1.ResourceReport{
+			{
+				Resource: configv1beta1.Resource{
+					Namespace: configMap.Namespace, Name: configMap.Name, Kind: "ConfigMap", Version: "v1",
+					Owner: corev1.ObjectReference{Kind: policyRef.Kind, Namespace: policyRef.Namespace, Name: policyRef.Name},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, consumer, other).Build()
+
+		getDeployment := func(d *appsv1.Deployment) *appsv1.Deployment {
+			current := &appsv1.Deployment{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: d.Namespace, Name: d.Name},
+				current)).To(Succeed())
+			return current
+		}
+
+		// ConfigMap is delivered for the first time: hash is recorded, Deployment is not restarted
+		Expect(controllers.ReloadWorkloads(context.TODO(), c, clusterSummary, reports, logger)).To(Succeed())
+		current := getDeployment(consumer)
+		Expect(current.Annotations).To(HaveKey(reloadWorkloadsHashesAnnotation))
+		Expect(current.Spec.Template.Annotations).ToNot(HaveKey(reloadWorkloadsHashesAnnotation))
+
+		// ConfigMap content changes: Deployment pod template changes, starting a rolling restart
+		configMap.Data = map[string]string{"key": randomString()}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+		Expect(controllers.ReloadWorkloads(context.TODO(), c, clusterSummary, reports, logger)).To(Succeed())
+		current = getDeployment(consumer)
+		Expect(current.Spec.Template.Annotations).To(HaveKey(reloadWorkloadsHashesAnnotation))
+		Expect(current.Spec.Template.Annotations[reloadWorkloadsHashesAnnotation]).To(
+			Equal(current.Annotations[reloadWorkloadsHashesAnnotation]))
+
+		Expect(getDeployment(other).Annotations).ToNot(HaveKey(reloadWorkloadsHashesAnnotation))
+
+		// Without ReloadWorkloads nothing is restarted
+		restarted := current.Spec.Template.Annotations[reloadWorkloadsHashesAnnotation]
+		policyRef.ReloadWorkloads = false
+		configMap.Data = map[string]string{"key": randomString()}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+		Expect(controllers.ReloadWorkloads(context.TODO(), c, clusterSummary, reports, logger)).To(Succeed())
+		Expect(getDeployment(consumer).Spec.Template.Annotations[reloadWorkloadsHashesAnnotation]).To(Equal(restarted))
+	})
+})
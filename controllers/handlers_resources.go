@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"time"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"github.com/gdexlab/go-render/render"
@@ -34,9 +35,9 @@ import (
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
@@ -55,6 +56,11 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
+	err = validateClusterSummaryReferences(ctx, c, clusterSummary, configv1beta1.FeatureResources)
+	if err != nil {
+		return err
+	}
+
 	remoteRestConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
@@ -66,8 +72,28 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
+	// When WriteBudget is set, only a chunk of the resources is applied in this pass
+	ctx = withWriteBudget(ctx, clusterSummary, configv1beta1.FeatureResources)
+	// Number and size of resources deployed are limited by the Guardrails, if any
+	ctx = withGuardrails(ctx)
+	// Deployment is aborted if ClusterSummary Spec changes in the meantime
+	ctx = withDeploymentRevision(ctx, c, clusterSummary)
+	// Image tags are pinned to digests, if ImageDigestResolution is set
+	ctx, err = withImageDigests(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Rendered resources are passed through the WasmPlugins, if any
+	ctx, err = withWasmPlugins(ctx, c, clusterSummary)
+	if err != nil {
+		return err
+	}
+	// Resources managed by a different profile are reported in the Status
+	ctx = withConflicts(ctx)
+
 	localResourceReports, remoteResourceReports, deployError := deployPolicyRefs(ctx, c, remoteRestConfig,
 		clusterSummary, featureHandler, logger)
+	recordConflicts(ctx, clusterSummary, configv1beta1.FeatureResources, deployError, logger)
 
 	// Irrespective of error, update deployed gvks. Otherwise cleanup won't happen in case
 	var gvkErr error
@@ -77,6 +103,11 @@ func deployResources(ctx context.Context, c client.Client,
 		return gvkErr
 	}
 
+	if isDeploymentSupersededError(deployError) {
+		// Stale resources are cleaned by the deployment of the latest Spec
+		return deployError
+	}
+
 	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
 		return err
@@ -100,13 +131,17 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
-	var undeployed []configv1beta1.ResourceReport
-	_, undeployed, err = cleanStaleResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
-		localResourceReports, remoteResourceReports, logger)
-	if err != nil {
-		return err
+	// With ContinueOnError, resources contained in items which failed to be deployed are missing from
+	// the reports. Those must not be considered stale.
+	if deployError == nil || !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+		var undeployed []configv1beta1.ResourceReport
+		_, undeployed, err = cleanStaleResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
+			localResourceReports, remoteResourceReports, logger)
+		if err != nil {
+			return err
+		}
+		remoteResourceReports = append(remoteResourceReports, undeployed...)
 	}
-	remoteResourceReports = append(remoteResourceReports, undeployed...)
 
 	err = handleWatchers(ctx, clusterSummary, localResourceReports, featureHandler)
 	if err != nil {
@@ -132,6 +167,23 @@ func deployResources(ctx context.Context, c client.Client,
 		return deployError
 	}
 
+	if err := getWriteBudget(ctx).complete(); err != nil {
+		return err
+	}
+
+	recordResolvedImages(ctx, clusterSummary, configv1beta1.FeatureResources, logger)
+
+	// All resources have been delivered. Complete Secret rotations, if any.
+	err = runSecretRotationHooks(ctx, c, remoteClient, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	err = reloadWorkloads(ctx, remoteClient, clusterSummary, remoteResourceReports, logger)
+	if err != nil {
+		return err
+	}
+
 	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureResources, logger)
 }
 
@@ -272,6 +324,7 @@ func undeployResources(ctx context.Context, c client.Client,
 		}
 		return err
 	}
+	applyObserveOnly(clusterSummary)
 
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName)).
@@ -279,7 +332,7 @@ func undeployResources(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployResources")
 
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -406,6 +459,26 @@ func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *sc
 		}
 	}
 
+	if len(clusterSummary.Spec.ClusterProfileSpec.InlineResources) != 0 {
+		config += render.AsCode(clusterSummary.Spec.ClusterProfileSpec.InlineResources)
+	}
+
+	if clusterSummary.Spec.ClusterProfileSpec.GenerateRBAC {
+		// Generated RBAC needs to be deployed or withdrawn
+		config += "generateRBAC"
+	}
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.SecretRotationHooks) != 0 {
+		config += render.AsCode(clusterSummary.Spec.ClusterProfileSpec.SecretRotationHooks)
+		var valuesFromHash string
+		valuesFromHash, err = getValuesFromResourceHash(ctx, c, clusterSummary,
+			getSecretRotationJobsValuesFrom(clusterSummary), logger)
+		if err != nil {
+			return nil, err
+		}
+		config += valuesFromHash
+	}
+
 	for i := range clusterSummary.Spec.ClusterProfileSpec.ValidateHealths {
 		h := &clusterSummary.Spec.ClusterProfileSpec.ValidateHealths[i]
 		if h.FeatureID == configv1beta1.FeatureResources {
@@ -440,6 +513,11 @@ func updateClusterReportWithResourceReports(ctx context.Context, c client.Client
 	clusterReportName := getClusterReportName(profileOwnerRef.Kind, profileOwnerRef.Name,
 		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 
+	resourceReports, err = compressResourceReports(resourceReports)
+	if err != nil {
+		return err
+	}
+
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		clusterReport := &configv1beta1.ClusterReport{}
 		err = c.Get(ctx,
@@ -449,8 +527,16 @@ func updateClusterReportWithResourceReports(ctx context.Context, c client.Client
 		}
 
 		if featureID == configv1beta1.FeatureResources {
+			if sameReports(clusterReport.Status.ResourceReports, resourceReports) {
+				return nil
+			}
+			archiveClusterReport(clusterReport, time.Now())
 			clusterReport.Status.ResourceReports = resourceReports
 		} else if featureID == configv1beta1.FeatureKustomize {
+			if sameReports(clusterReport.Status.KustomizeResourceReports, resourceReports) {
+				return nil
+			}
+			archiveClusterReport(clusterReport, time.Now())
 			clusterReport.Status.KustomizeResourceReports = resourceReports
 		}
 
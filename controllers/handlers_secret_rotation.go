@@ -0,0 +1,232 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// restartOnSecretRotationAnnotation is set by users on Deployments in managed clusters. Value is a
+	// comma separated list of Secret names. Deployments are restarted when any of those Secrets is rotated.
+	restartOnSecretRotationAnnotation = "projectsveltos.io/restart-on-secret-rotation"
+
+	// secretRotationHashesAnnotation is set on annotated Deployments. Value is a JSON map with the hash
+	// of each Secret last delivered. It is also set on the Deployment pod template to trigger a rolling restart.
+	secretRotationHashesAnnotation = "projectsveltos.io/secret-rotation-hashes"
+
+	// secretRotationJobLabel is added to all Jobs created in managed clusters by SecretRotationHooks
+	secretRotationJobLabel = "projectsveltos.io/secret-rotation-job"
+)
+
+// runSecretRotationHooks runs the SecretRotationHooks once the Resources feature has been deployed.
+// Hooks are idempotent: Deployments are restarted and Jobs created again only when the referenced
+// Secret content differs from the one seen last time.
+func runSecretRotationHooks(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+
+	hooks := clusterSummary.Spec.ClusterProfileSpec.SecretRotationHooks
+	if len(hooks) == 0 || isDryRunSync(clusterSummary) {
+		return nil
+	}
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	// key: Secret name; value: hash of the Secret content
+	restartHashes := make(map[string]string)
+	for i := range hooks {
+		hook := &hooks[i]
+
+		var name, hash string
+		name, hash, err = getSecretRotationHash(ctx, c, clusterSummary, hook)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("secret %s/%s does not exist yet", hook.Namespace, hook.Name))
+				continue
+			}
+			return err
+		}
+
+		if hook.RestartDeployments {
+			restartHashes[name] = hash
+		}
+
+		if hook.Job != nil {
+			err = runSecretRotationJobs(ctx, c, remoteClient, clusterSummary, mgmtResources, hook.Job, hash, logger)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(restartHashes) == 0 {
+		return nil
+	}
+
+	return restartDeploymentsOnSecretRotation(ctx, remoteClient, restartHashes, logger)
+}
+
+// getSecretRotationHash returns the instantiated name of the Secret referenced by the hook along
+// with the hash of its content
+func getSecretRotationHash(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	hook *configv1beta1.SecretRotationHook) (name, hash string, err error) {
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Namespace, hook.Namespace)
+
+	name, err = libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), hook.Name)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := getSecret(ctx, c, types.NamespacedName{Namespace: namespace, Name: name})
+	if err != nil {
+		return "", "", err
+	}
+
+	// Annotations are not considered. Only a change of the Secret data is a rotation.
+	config := getDataSectionHash(secret.Data) + getDataSectionHash(secret.StringData)
+	return name, fmt.Sprintf("%x", sha256.Sum256([]byte(config))), nil
+}
+
+// runSecretRotationJobs creates the Jobs referenced by jobRef in the managed cluster. The Secret hash is
+// part of the Job hash, so Jobs are deleted and created again every time the Secret is rotated.
+func runSecretRotationJobs(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
+	jobRef *configv1beta1.JobRef, secretHash string, logger logr.Logger) error {
+
+	jobs, err := getJobRefJobs(ctx, c, clusterSummary, mgmtResources, jobRef, logger)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		// Those Jobs must not be considered by the Jobs feature
+		delete(jobs[i].Labels, jobLabel)
+		jobs[i].Labels[secretRotationJobLabel] = "ok"
+		jobs[i].Annotations[jobHashAnnotation] = fmt.Sprintf("%x",
+			sha256.Sum256([]byte(jobs[i].Annotations[jobHashAnnotation]+secretHash)))
+
+		if _, err = deployJob(ctx, remoteClient, jobs[i], logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restartDeploymentsOnSecretRotation triggers a rolling restart of every Deployment annotated with
+// restartOnSecretRotationAnnotation for which at least one of the listed Secrets has a new hash.
+// Deployments seeing a Secret for the first time only record its hash.
+func restartDeploymentsOnSecretRotation(ctx context.Context, remoteClient client.Client,
+	secretHashes map[string]string, logger logr.Logger) error {
+
+	deployments := &appsv1.DeploymentList{}
+	if err := remoteClient.List(ctx, deployments); err != nil {
+		return err
+	}
+
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		value, ok := deployment.Annotations[restartOnSecretRotationAnnotation]
+		if !ok {
+			continue
+		}
+
+		hashes := make(map[string]string)
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if hash, found := secretHashes[name]; found {
+				hashes[name] = hash
+			}
+		}
+
+		tracked, changed, rotated := trackConsumedHashes(deployment.Annotations, secretRotationHashesAnnotation, hashes)
+		if !changed {
+			continue
+		}
+
+		err := patchWorkloadHashes(ctx, remoteClient, deployment, &deployment.Spec.Template,
+			secretRotationHashesAnnotation, tracked, rotated)
+		if err != nil {
+			return err
+		}
+
+		if rotated {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("secret rotated. Restarted deployment %s/%s",
+				deployment.Namespace, deployment.Name))
+		}
+	}
+
+	return nil
+}
+
+// validateSecretRotationHooks verifies each SecretRotationHook references a Secret deployed via PolicyRefs
+func validateSecretRotationHooks(spec *configv1beta1.Spec) error {
+	for i := range spec.SecretRotationHooks {
+		hook := &spec.SecretRotationHooks[i]
+
+		found := false
+		for j := range spec.PolicyRefs {
+			ref := &spec.PolicyRefs[j]
+			if ref.Kind == string(libsveltosv1beta1.SecretReferencedResourceKind) &&
+				ref.Namespace == hook.Namespace && ref.Name == hook.Name {
+
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("secretRotationHook %s/%s: secret is not referenced in policyRefs",
+				hook.Namespace, hook.Name)
+		}
+	}
+
+	return nil
+}
+
+// getSecretRotationJobsValuesFrom returns the resources containing the Jobs run by SecretRotationHooks
+func getSecretRotationJobsValuesFrom(clusterSummary *configv1beta1.ClusterSummary) []configv1beta1.ValueFrom {
+	valuesFrom := make([]configv1beta1.ValueFrom, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.SecretRotationHooks {
+		jobRef := clusterSummary.Spec.ClusterProfileSpec.SecretRotationHooks[i].Job
+		if jobRef != nil {
+			valuesFrom = append(valuesFrom,
+				configv1beta1.ValueFrom{Namespace: jobRef.Namespace, Name: jobRef.Name, Kind: jobRef.Kind})
+		}
+	}
+	return valuesFrom
+}
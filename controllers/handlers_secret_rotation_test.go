@@ -0,0 +1,114 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	restartOnSecretRotationAnnotation = "projectsveltos.io/restart-on-secret-rotation"
+	secretRotationHashesAnnotation    = "projectsveltos.io/secret-rotation-hashes"
+)
+
+var _ = Describe("Secret rotation hooks", func() {
+	It("restartDeploymentsOnSecretRotation restarts annotated Deployments only on rotation", func() {
+		secretName := randomString()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		annotated := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   randomString(),
+				Name:        randomString(),
+				Annotations: map[string]string{restartOnSecretRotationAnnotation: "other, " + secretName},
+			},
+		}
+		notAnnotated := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(annotated, notAnnotated).Build()
+
+		getDeployment := func(d *appsv1.Deployment) *appsv1.Deployment {
+			current := &appsv1.Deployment{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: d.Namespace, Name: d.Name},
+				current)).To(Succeed())
+			return current
+		}
+
+		// Secret is delivered for the first time: hash is recorded, Deployment is not restarted
+		Expect(controllers.RestartDeploymentsOnSecretRotation(context.TODO(), c,
+			map[string]string{secretName: "hash1"}, logger)).To(Succeed())
+		current := getDeployment(annotated)
+		Expect(current.Annotations[secretRotationHashesAnnotation]).To(ContainSubstring("hash1"))
+		Expect(current.Spec.Template.Annotations).ToNot(HaveKey(secretRotationHashesAnnotation))
+
+		// Same Secret content: nothing changes
+		Expect(controllers.RestartDeploymentsOnSecretRotation(context.TODO(), c,
+			map[string]string{secretName: "hash1"}, logger)).To(Succeed())
+		Expect(getDeployment(annotated).Spec.Template.Annotations).ToNot(HaveKey(secretRotationHashesAnnotation))
+
+		// Secret is rotated: Deployment pod template changes, starting a rolling restart
+		Expect(controllers.RestartDeploymentsOnSecretRotation(context.TODO(), c,
+			map[string]string{secretName: "hash2"}, logger)).To(Succeed())
+		current = getDeployment(annotated)
+		Expect(current.Annotations[secretRotationHashesAnnotation]).To(ContainSubstring("hash2"))
+		Expect(current.Spec.Template.Annotations[secretRotationHashesAnnotation]).To(ContainSubstring("hash2"))
+
+		current = getDeployment(notAnnotated)
+		Expect(current.Annotations).ToNot(HaveKey(secretRotationHashesAnnotation))
+		Expect(current.Spec.Template.Annotations).ToNot(HaveKey(secretRotationHashesAnnotation))
+	})
+
+	It("validateSecretRotationHooks requires the Secret to be referenced in PolicyRefs", func() {
+		namespace := randomString()
+		name := randomString()
+
+		spec := &configv1beta1.Spec{
+			PolicyRefs: []configv1beta1.PolicyRef{
+				{Namespace: namespace, Name: name, Kind: string(libsveltosv1beta1.SecretReferencedResourceKind)},
+			},
+			SecretRotationHooks: []configv1beta1.SecretRotationHook{
+				{Namespace: namespace, Name: name, RestartDeployments: true},
+			},
+		}
+		Expect(controllers.ValidateSecretRotationHooks(spec)).To(Succeed())
+
+		spec.PolicyRefs[0].Kind = string(libsveltosv1beta1.ConfigMapReferencedResourceKind)
+		Expect(controllers.ValidateSecretRotationHooks(spec)).ToNot(Succeed())
+
+		spec.PolicyRefs[0].Kind = string(libsveltosv1beta1.SecretReferencedResourceKind)
+		spec.SecretRotationHooks[0].Name = randomString()
+		Expect(controllers.ValidateSecretRotationHooks(spec)).ToNot(Succeed())
+	})
+})
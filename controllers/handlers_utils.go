@@ -52,6 +52,7 @@ import (
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
@@ -154,8 +155,7 @@ func deployContentOfSource(ctx context.Context, deployingToMgmtCluster bool, des
 	defer os.RemoveAll(tmpDir)
 
 	// Path can be expressed as a template and instantiate using Cluster fields.
-	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-		clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+	instantiatedPath, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
 		clusterSummary.GetName(), path, nil, logger)
 	if err != nil {
 		return nil, err
@@ -163,19 +163,30 @@ func deployContentOfSource(ctx context.Context, deployingToMgmtCluster bool, des
 
 	logger.V(logs.LogDebug).Info(fmt.Sprintf("using path %s", instantiatedPath))
 
-	// check build path exists
-	dirPath := filepath.Join(tmpDir, instantiatedPath)
-	_, err = os.Stat(dirPath)
+	// check build paths exist
+	dirPaths, err := getSourcePaths(tmpDir, instantiatedPath)
 	if err != nil {
 		logger.Error(err, "source path not found")
 		return nil, err
 	}
 
-	var content map[string]string
-	content, err = readFiles(dirPath)
-	if err != nil {
-		logger.Error(err, "failed to read content")
-		return nil, err
+	content := make(map[string]string)
+	for i := range dirPaths {
+		var dirContent map[string]string
+		dirContent, err = readFiles(dirPaths[i])
+		if err != nil {
+			logger.Error(err, "failed to read content")
+			return nil, err
+		}
+
+		for k, v := range dirContent {
+			if len(dirPaths) > 1 {
+				// Same file name can be present in more than one directory
+				relPath, _ := filepath.Rel(tmpDir, dirPaths[i])
+				k = filepath.Join(relPath, k)
+			}
+			content[k] = v
+		}
 	}
 
 	return deployContent(ctx, deployingToMgmtCluster, destConfig, destClient, source, content,
@@ -218,15 +229,15 @@ func applySubresources(ctx context.Context, dr dynamic.ResourceInterface,
 	return err
 }
 
-// updateResource creates or updates a resource in a Cluster.
+// updateResource creates or updates a resource in a Cluster. Returns the resource as stored in the Cluster.
 // No action in DryRun mode.
 func updateResource(ctx context.Context, dr dynamic.ResourceInterface,
 	clusterSummary *configv1beta1.ClusterSummary, object *unstructured.Unstructured, subresources []string,
-	logger logr.Logger) error {
+	logger logr.Logger) (*unstructured.Unstructured, error) {
 
 	// No-op in DryRun mode
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
-		return nil
+		return nil, nil
 	}
 
 	l := logger.WithValues("resourceNamespace", object.GetNamespace(), "resourceName", object.GetName(),
@@ -249,10 +260,10 @@ func updateResource(ctx context.Context, dr dynamic.ResourceInterface,
 	// the spec.replicas is removed.
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		if clusterSummary.Spec.ClusterProfileSpec.DriftExclusions != nil {
-			_, err := dr.Create(ctx, object, metav1.CreateOptions{})
+			created, err := dr.Create(ctx, object, metav1.CreateOptions{})
 			if err != nil {
 				if !apierrors.IsAlreadyExists(err) {
-					return err
+					return nil, err
 				}
 				// The resource already exist. Apply Patches to avoid resetting fields that should be ignored for
 				// drift evaluation
@@ -261,26 +272,26 @@ func updateResource(ctx context.Context, dr dynamic.ResourceInterface,
 				var patchedObjects []*unstructured.Unstructured
 				patchedObjects, err = p.RunUnstructured([]*unstructured.Unstructured{object})
 				if err != nil {
-					return err
+					return nil, err
 				}
 				object = patchedObjects[0]
 			} else {
-				return applySubresources(ctx, dr, object, subresources, &options)
+				return created, applySubresources(ctx, dr, object, subresources, &options)
 			}
 		}
 	}
 
 	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, object)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = dr.Patch(ctx, object.GetName(), types.ApplyPatchType, data, options)
+	applied, err := dr.Patch(ctx, object.GetName(), types.ApplyPatchType, data, options)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return applySubresources(ctx, dr, object, subresources, &options)
+	return applied, applySubresources(ctx, dr, object, subresources, &options)
 }
 
 func instantiateTemplate(referencedObject client.Object, logger logr.Logger) bool {
@@ -369,6 +380,11 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 	featureID configv1beta1.FeatureID, clusterSummary *configv1beta1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
 	subresources []string, logger logr.Logger) (reports []configv1beta1.ResourceReport, err error) {
 
+	// Do not apply resources of a superseded ClusterSummary Spec. Latest Spec is deployed next.
+	if err = checkDeploymentSuperseded(ctx); err != nil {
+		return nil, err
+	}
+
 	profile, profileTier, err := configv1beta1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
 	if err != nil {
 		return nil, err
@@ -390,16 +406,74 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 		}
 	}
 
+	// Resources are passed through the WasmPlugins, if any
+	referencedUnstructured, err = runWasmPlugins(ctx, referencedUnstructured)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources meant for node operating systems/architectures the cluster does not have are not deployed
+	referencedUnstructured, err = filterByNodePlatforms(ctx, destClient, referencedUnstructured, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkGuardrails(referencedUnstructured, getGuardrailsTally(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolveImageDigests(ctx, referencedUnstructured)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := getTenant(clusterSummary)
+
+	transformer, err := getSecretTransformer(ctx, getManagementClusterClient(), clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	// CustomResourceDefinitions are deployed first. Instances of CustomResourceDefinitions contained
+	// in the same bundle are deployed once the API server serves them.
+	referencedUnstructured = sortCustomResourceDefinitionsFirst(referencedUnstructured)
+	bundleGroupKinds := getCustomResourceDefinitionGroupKinds(referencedUnstructured)
+
 	conflictErrorMsg := ""
 	reports = make([]configv1beta1.ResourceReport, 0)
 	for i := range referencedUnstructured {
 		policy := referencedUnstructured[i]
 
+		if bundleGroupKinds[policy.GroupVersionKind().GroupKind()] &&
+			clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun {
+
+			if err := waitForDiscovery(ctx, destConfig, policy.GroupVersionKind(), logger); err != nil {
+				return reports, err
+			}
+			// Once served, no need to wait for other instances
+			delete(bundleGroupKinds, policy.GroupVersionKind().GroupKind())
+		}
+
 		err := adjustNamespace(policy, destConfig)
 		if err != nil {
 			return nil, err
 		}
 
+		if tenant != "" {
+			// adjustNamespace sets namespace for all and only namespaced resources
+			err = applyTenantNamespace(policy, tenant, policy.GetNamespace() != "")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Secrets are transformed once their namespace is final
+		policy, err = transformer.transform(policy)
+		if err != nil {
+			return nil, err
+		}
+
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("deploying resource %s %s/%s (deploy to management cluster: %v)",
 			policy.GetKind(), policy.GetNamespace(), policy.GetName(), deployingToMgmtCluster))
 
@@ -424,6 +498,7 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 			ok := errors.As(err, &conflictErr)
 			if ok {
 				conflictResourceReport := generateConflictResourceReport(ctx, dr, resource)
+				trackConflict(ctx, getResourceConflict(featureID, resource, resourceInfo, err.Error()))
 				if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
 					reports = append(reports, *conflictResourceReport)
 					continue
@@ -440,6 +515,8 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 
 		addMetadata(policy, resourceInfo.ResourceVersion, profile,
 			clusterSummary.Spec.ClusterProfileSpec.ExtraLabels, clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations)
+		addLabel(policy, InventoryLabel, getInventoryLabelValue(clusterSummary, profile, featureID))
+		markPruneProtected(ctx, policy)
 
 		if deployingToMgmtCluster {
 			// When deploying resources in the management cluster, just setting (Cluster)Profile as OwnerReference is
@@ -458,7 +535,14 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 			}
 		}
 
-		err = updateResource(ctx, dr, clusterSummary, policy, subresources, logger)
+		if !getWriteBudget(ctx).consume(deployingToMgmtCluster, policy, policyHash) {
+			// Either already applied in a previous pass or WriteBudget is exhausted and resource
+			// will be applied in a following pass. Still reported so it is not considered stale.
+			reports = append(reports, *generateResourceReport(policyHash, resourceInfo, resource))
+			continue
+		}
+
+		err = deployResourceAndMetadata(ctx, dr, clusterSummary, policy, subresources, logger)
 		if err != nil {
 			return reports, err
 		}
@@ -554,6 +638,7 @@ func requeueAllOldOwners(ctx context.Context, profileOwners []corev1.ObjectRefer
 // referenced resource => it cannot be updated
 // - if resource is currently already deployed in the managed cluster but owned by different (Cluster)Profile
 // => it can be updated only if current (Cluster)Profile tier is lower than profile currently deploying the resource
+// or if current (Cluster)Profile is adopting resources from it
 //
 // If resource cannot be deployed, return a ConflictError.
 // If any other error occurs while doing those verification, the error is returned
@@ -570,7 +655,16 @@ func canDeployResource(ctx context.Context, dr dynamic.ResourceInterface, policy
 		ok := errors.As(err, &conflictErr)
 		if ok {
 			// There is a conflict.
-			if hasHigherOwnershipPriority(getTier(resourceInfo.OwnerTier), profileTier) {
+			if isOwnedByAdoptedProfile(profile, resourceInfo.OwnerReferences) {
+				l.V(logs.LogDebug).Info("conflict detected but resource is adopted from previous profile")
+				return resourceInfo, false, nil
+			}
+			adopted, err := isOwnedByAdoptingProfile(ctx, getManagementClusterClient(), profile,
+				resourceInfo.OwnerReferences)
+			if err != nil {
+				return nil, false, err
+			}
+			if !adopted && hasHigherOwnershipPriority(getTier(resourceInfo.OwnerTier), profileTier) {
 				l.V(logs.LogDebug).Info("conflict detected but resource ownership can change")
 				// Because of tier, ownership must change. Which also means current ClusterProfile/Profile
 				// owning the resource must be requeued for reconciliation
@@ -751,8 +845,7 @@ func collectContent(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 		section := data[k]
 
 		if instantiateTemplate {
-			instance, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-				clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			instance, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
 				clusterSummary.GetName(), section, mgmtResources, logger)
 			if err != nil {
 				logger.Error(err, fmt.Sprintf("failed to instantiate policy from Data %.100s", section))
@@ -853,6 +946,8 @@ func getClusterSummaryAndClusterClient(ctx context.Context, clusterNamespace, cl
 		return nil, nil, fmt.Errorf("clustersummary is marked for deletion")
 	}
 
+	applyObserveOnly(clusterSummary)
+
 	// Get CAPI Cluster
 	cluster, err := clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
 		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
@@ -866,8 +961,20 @@ func getClusterSummaryAndClusterClient(ctx context.Context, clusterNamespace, cl
 		return nil, nil, fmt.Errorf("cluster is marked for deletion")
 	}
 
+	if clusterSummary.Spec.ClusterProfileSpec.DedicatedIdentity {
+		remoteRestConfig, _, err := getRestConfig(ctx, c, clusterSummary, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		clusterClient, err := client.New(remoteRestConfig, client.Options{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return clusterSummary, clusterClient, nil
+	}
+
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
-	clusterClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+	clusterClient, err := secretprovider.GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
 		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return nil, nil, err
@@ -975,8 +1082,9 @@ func deployReferencedObjects(ctx context.Context, c client.Client, remoteConfig
 			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", adminNamespace, adminName),
 		}
 	}
+	deployErrors := &deploymentErrors{}
 	tmpResourceReports, err = deployObjects(ctx, true, c, localConfig, objectsToDeployLocally, clusterSummary,
-		mgmtResources, logger)
+		mgmtResources, deployErrors, logger)
 	localReports = append(localReports, tmpResourceReports...)
 	if err != nil {
 		return localReports, nil, err
@@ -984,36 +1092,66 @@ func deployReferencedObjects(ctx context.Context, c client.Client, remoteConfig
 
 	// Deploy all resources that need to be deployed in the managed cluster
 	tmpResourceReports, err = deployObjects(ctx, false, remoteClient, remoteConfig, objectsToDeployRemotely, clusterSummary,
-		mgmtResources, logger)
+		mgmtResources, deployErrors, logger)
 	remoteReports = append(remoteReports, tmpResourceReports...)
 	if err != nil {
 		return localReports, remoteReports, err
 	}
 
-	return localReports, remoteReports, nil
+	if len(clusterSummary.Spec.ClusterProfileSpec.InlineResources) != 0 {
+		tmpResourceReports, err = deployInlineResources(ctx, remoteConfig, remoteClient, clusterSummary,
+			mgmtResources, logger)
+		remoteReports = append(remoteReports, tmpResourceReports...)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+	}
+
+	if clusterSummary.Spec.ClusterProfileSpec.GenerateRBAC {
+		tmpResourceReports, err = deployGeneratedRBAC(ctx, remoteClient, remoteConfig, clusterSummary, remoteReports,
+			mgmtResources, logger)
+		remoteReports = append(remoteReports, tmpResourceReports...)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+	}
+
+	return localReports, remoteReports,
+		deployErrors.toError(len(objectsToDeployLocally) + len(objectsToDeployRemotely))
 }
 
-// deployObjects deploys content of referencedObjects
+// deployObjects deploys content of referencedObjects.
+// If ContinueOnError is set, failures are collected in deployErrors and remaining referencedObjects
+// are deployed.
 func deployObjects(ctx context.Context, deployingToMgmtCluster bool, destClient client.Client, destConfig *rest.Config,
 	referencedObjects []client.Object, clusterSummary *configv1beta1.ClusterSummary,
-	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+	mgmtResources map[string]*unstructured.Unstructured, deployErrors *deploymentErrors, logger logr.Logger,
 ) (reports []configv1beta1.ResourceReport, err error) {
 
+	var pruneAllowlists map[string][]configv1beta1.PruneKind
+	pruneAllowlists, err = getPruneAllowlists(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range referencedObjects {
 		var tmpResourceReports []configv1beta1.ResourceReport
+		objectCtx := withPruneAllowlist(ctx, pruneAllowlists[getPruneAllowlistKey(
+			referencedObjects[i].GetObjectKind().GroupVersionKind().Kind,
+			referencedObjects[i].GetNamespace(), referencedObjects[i].GetName())])
 		if referencedObjects[i].GetObjectKind().GroupVersionKind().Kind == string(libsveltosv1beta1.ConfigMapReferencedResourceKind) {
 			configMap := referencedObjects[i].(*corev1.ConfigMap)
 			l := logger.WithValues("configMapNamespace", configMap.Namespace, "configMapName", configMap.Name)
 			l.V(logs.LogDebug).Info("deploying ConfigMap content")
 			tmpResourceReports, err =
-				deployContentOfConfigMap(ctx, deployingToMgmtCluster, destConfig, destClient, configMap,
+				deployContentOfConfigMap(objectCtx, deployingToMgmtCluster, destConfig, destClient, configMap,
 					clusterSummary, mgmtResources, l)
 		} else if referencedObjects[i].GetObjectKind().GroupVersionKind().Kind == string(libsveltosv1beta1.SecretReferencedResourceKind) {
 			secret := referencedObjects[i].(*corev1.Secret)
 			l := logger.WithValues("secretNamespace", secret.Namespace, "secretName", secret.Name)
 			l.V(logs.LogDebug).Info("deploying Secret content")
 			tmpResourceReports, err =
-				deployContentOfSecret(ctx, deployingToMgmtCluster, destConfig, destClient, secret,
+				deployContentOfSecret(objectCtx, deployingToMgmtCluster, destConfig, destClient, secret,
 					clusterSummary, mgmtResources, l)
 		} else {
 			source := referencedObjects[i]
@@ -1021,7 +1159,7 @@ func deployObjects(ctx context.Context, deployingToMgmtCluster bool, destClient
 			annotations := source.GetAnnotations()
 			path := annotations[pathAnnotation]
 			tmpResourceReports, err =
-				deployContentOfSource(ctx, deployingToMgmtCluster, destConfig, destClient, source, path,
+				deployContentOfSource(objectCtx, deployingToMgmtCluster, destConfig, destClient, source, path,
 					clusterSummary, mgmtResources, logger)
 		}
 
@@ -1030,6 +1168,12 @@ func deployObjects(ctx context.Context, deployingToMgmtCluster bool, destClient
 		}
 
 		if err != nil {
+			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				deployErrors.add(fmt.Sprintf("%s %s/%s",
+					referencedObjects[i].GetObjectKind().GroupVersionKind().Kind,
+					referencedObjects[i].GetNamespace(), referencedObjects[i].GetName()), err)
+				continue
+			}
 			return reports, err
 		}
 	}
@@ -1070,6 +1214,11 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 		profile.SetName(profileNameToOwnerReferenceName(profile))
 	}
 
+	leavePolicies, err := isLeavePolicies(ctx, clusterSummary, featureID, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	undeployed := make([]configv1beta1.ResourceReport, 0)
 
 	dc := discovery.NewDiscoveryClientForConfigOrDie(remoteConfig)
@@ -1118,7 +1267,7 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 		for j := range list.Items {
 			r := list.Items[j]
 			rr, err := undeployStaleResource(ctx, isMgmtCluster, remoteClient, profile, clusterSummary,
-				r, currentPolicies, logger)
+				r, currentPolicies, leavePolicies, logger)
 			if err != nil {
 				return nil, err
 			}
@@ -1134,7 +1283,8 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 
 func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient client.Client,
 	profile client.Object, clusterSummary *configv1beta1.ClusterSummary, r unstructured.Unstructured,
-	currentPolicies map[string]configv1beta1.Resource, logger logr.Logger) (*configv1beta1.ResourceReport, error) {
+	currentPolicies map[string]configv1beta1.Resource, leavePolicies bool, logger logr.Logger,
+) (*configv1beta1.ResourceReport, error) {
 
 	logger.V(logs.LogVerbose).Info(fmt.Sprintf("considering %s/%s", r.GetNamespace(), r.GetName()))
 	// Verify if this policy was deployed because of a projectsveltos (ReferenceLabelName
@@ -1160,8 +1310,8 @@ func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient
 	// If this ClusterSummary is the only OwnerReference and it is not deploying this policy anymore,
 	// policy would be withdrawn
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
-		if canDelete(&r, currentPolicies) && deployer.IsOnlyOwnerReference(&r, profile) &&
-			!isLeavePolicies(clusterSummary, logger) {
+		if canDelete(&r, currentPolicies) && deployer.IsOnlyOwnerReference(&r, profile) && !leavePolicies &&
+			!isPruneProtected(&r) {
 
 			resourceReport = &configv1beta1.ResourceReport{
 				Resource: configv1beta1.Resource{
@@ -1177,11 +1327,20 @@ func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient
 		deployer.RemoveOwnerReference(&r, profile)
 
 		if len(r.GetOwnerReferences()) != 0 {
-			// Other ClusterSummary are still deploying this very same policy
-			return nil, nil
+			// Other ClusterSummary are still deploying this very same policy. Only remove the
+			// labels/annotations this profile added.
+			return nil, releaseExtraMetadata(ctx, remoteClient, clusterSummary, &r, logger)
 		}
 
-		err := handleResourceDelete(ctx, remoteClient, &r, clusterSummary, logger)
+		leave := leavePolicies
+		if isPruneProtected(&r) {
+			// Kind is not in the PruneAllowlist of the PolicyRef which deployed this resource
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("%s %s/%s is protected from pruning. Leaving it",
+				r.GetKind(), r.GetNamespace(), r.GetName()))
+			leave = true
+		}
+
+		err := handleResourceDelete(ctx, remoteClient, &r, leave, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -1191,11 +1350,11 @@ func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient
 }
 
 func handleResourceDelete(ctx context.Context, remoteClient client.Client, policy client.Object,
-	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+	leavePolicies bool, logger logr.Logger) error {
 
 	// If mode is set to LeavePolicies, leave policies in the workload cluster.
 	// Remove all labels added by Sveltos.
-	if isLeavePolicies(clusterSummary, logger) {
+	if leavePolicies {
 		l := policy.GetLabels()
 		delete(l, deployer.ReferenceKindLabel)
 		delete(l, deployer.ReferenceNameLabel)
@@ -1228,15 +1387,24 @@ func canDelete(policy client.Object, currentReferencedPolicies map[string]config
 
 // isLeavePolicies returns true if:
 // - ClusterSummary is marked for deletion
-// - StopMatchingBehavior is set to LeavePolicies
-func isLeavePolicies(clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) bool {
-	if !clusterSummary.DeletionTimestamp.IsZero() &&
-		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehavior == configv1beta1.LeavePolicies {
+// - StopMatchingBehavior for featureID is LeavePolicies
+func isLeavePolicies(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) (bool, error) {
+
+	if clusterSummary.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
 
+	behavior, err := getStopMatchingBehavior(ctx, clusterSummary, featureID, logger)
+	if err != nil {
+		return false, err
+	}
+
+	if behavior == configv1beta1.LeavePolicies {
 		logger.V(logs.LogInfo).Info("ClusterProfile StopMatchingBehavior set to LeavePolicies")
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
 }
 
 // hasLabel search if key is one of the label.
@@ -1359,7 +1527,9 @@ func updateClusterProfileResources(ctx context.Context, c client.Client, profile
 	for i := range profileResources.Features {
 		if profileResources.Features[i].FeatureID == featureID {
 			if policyDeployed != nil {
-				profileResources.Features[i].Resources = policyDeployed
+				if err := setFeatureResources(&profileResources.Features[i], policyDeployed); err != nil {
+					return err
+				}
 			}
 			if chartDeployed != nil {
 				profileResources.Features[i].Charts = chartDeployed
@@ -1373,10 +1543,11 @@ func updateClusterProfileResources(ctx context.Context, c client.Client, profile
 		if profileResources.Features == nil {
 			profileResources.Features = make([]configv1beta1.Feature, 0)
 		}
-		profileResources.Features = append(
-			profileResources.Features,
-			configv1beta1.Feature{FeatureID: featureID, Resources: policyDeployed, Charts: chartDeployed},
-		)
+		feature := configv1beta1.Feature{FeatureID: featureID, Charts: chartDeployed}
+		if err := setFeatureResources(&feature, policyDeployed); err != nil {
+			return err
+		}
+		profileResources.Features = append(profileResources.Features, feature)
 	}
 
 	clusterConfiguration.OwnerReferences = util.EnsureOwnerRef(clusterConfiguration.OwnerReferences, *profileOwnerRef)
@@ -1395,7 +1566,9 @@ func updateProfileResources(ctx context.Context, c client.Client, profileOwnerRe
 	for i := range profileResources.Features {
 		if profileResources.Features[i].FeatureID == featureID {
 			if policyDeployed != nil {
-				profileResources.Features[i].Resources = policyDeployed
+				if err := setFeatureResources(&profileResources.Features[i], policyDeployed); err != nil {
+					return err
+				}
 			}
 			if chartDeployed != nil {
 				profileResources.Features[i].Charts = chartDeployed
@@ -1409,10 +1582,11 @@ func updateProfileResources(ctx context.Context, c client.Client, profileOwnerRe
 		if profileResources.Features == nil {
 			profileResources.Features = make([]configv1beta1.Feature, 0)
 		}
-		profileResources.Features = append(
-			profileResources.Features,
-			configv1beta1.Feature{FeatureID: featureID, Resources: policyDeployed, Charts: chartDeployed},
-		)
+		feature := configv1beta1.Feature{FeatureID: featureID, Charts: chartDeployed}
+		if err := setFeatureResources(&feature, policyDeployed); err != nil {
+			return err
+		}
+		profileResources.Features = append(profileResources.Features, feature)
 	}
 
 	clusterConfiguration.OwnerReferences = util.EnsureOwnerRef(clusterConfiguration.OwnerReferences, *profileOwnerRef)
@@ -1591,6 +1765,13 @@ func getRestConfig(ctx context.Context, c client.Client, clusterSummary *configv
 		return nil, logger, err
 	}
 
+	if clusterSummary.Spec.ClusterProfileSpec.DedicatedIdentity {
+		remoteRestConfig, err = getProfileIdentityRestConfig(ctx, remoteRestConfig, clusterSummary, logger)
+		if err != nil {
+			return nil, logger, err
+		}
+	}
+
 	return remoteRestConfig, logger, nil
 }
 
@@ -1655,12 +1836,19 @@ func getValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv
 			return nil, nil, err
 		}
 
+		var data map[string]string
+		var isTemplate bool
 		if valuesFrom[i].Kind == string(libsveltosv1beta1.ConfigMapReferencedResourceKind) {
 			configMap, err := getConfigMap(ctx, c, types.NamespacedName{Namespace: namespace, Name: name})
 			if err != nil {
 				msg := fmt.Sprintf("failed to get ConfigMap %s/%s", namespace, name)
 				logger.V(logs.LogInfo).Info(fmt.Sprintf("%s: %v", msg, err))
 				if apierrors.IsNotFound(err) {
+					if valuesFrom[i].Optional {
+						logger.V(logs.LogDebug).Info(fmt.Sprintf("optional ConfigMap %s/%s does not exist. Skipping it",
+							namespace, name))
+						continue
+					}
 					msg := fmt.Sprintf("Referenced resource: %s %s/%s does not exist",
 						libsveltosv1beta1.ConfigMapReferencedResourceKind, namespace, name)
 					logger.V(logs.LogInfo).Info(msg)
@@ -1669,29 +1857,19 @@ func getValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv
 				return nil, nil, errors.Wrapf(err, "%s", msg)
 			}
 
-			if instantiateTemplate(configMap, logger) {
-				for key, value := range configMap.Data {
-					if overrideKeys {
-						template[key] = value
-					} else {
-						addToMap(template, key, value)
-					}
-				}
-			} else {
-				for key, value := range configMap.Data {
-					if overrideKeys {
-						nonTemplate[key] = value
-					} else {
-						addToMap(nonTemplate, key, value)
-					}
-				}
-			}
+			data = configMap.Data
+			isTemplate = instantiateTemplate(configMap, logger)
 		} else if valuesFrom[i].Kind == string(libsveltosv1beta1.SecretReferencedResourceKind) {
 			secret, err := getSecret(ctx, c, types.NamespacedName{Namespace: namespace, Name: name})
 			if err != nil {
 				msg := fmt.Sprintf("failed to get Secret %s/%s", namespace, name)
 				logger.V(logs.LogInfo).Info(fmt.Sprintf("%s: %v", msg, err))
 				if apierrors.IsNotFound(err) {
+					if valuesFrom[i].Optional {
+						logger.V(logs.LogDebug).Info(fmt.Sprintf("optional Secret %s/%s does not exist. Skipping it",
+							namespace, name))
+						continue
+					}
 					msg := fmt.Sprintf("Referenced resource: %s %s/%s does not exist",
 						libsveltosv1beta1.SecretReferencedResourceKind, namespace, name)
 					logger.V(logs.LogInfo).Info(msg)
@@ -1699,22 +1877,28 @@ func getValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv
 				}
 				return nil, nil, errors.Wrapf(err, "%s", msg)
 			}
-			if instantiateTemplate(secret, logger) {
-				for key, value := range secret.Data {
-					if overrideKeys {
-						template[key] = string(value)
-					} else {
-						addToMap(template, key, string(value))
-					}
-				}
+
+			data = make(map[string]string, len(secret.Data))
+			for key, value := range secret.Data {
+				data[key] = string(value)
+			}
+			isTemplate = instantiateTemplate(secret, logger)
+		}
+
+		data, err = selectValueFromKey(data, &valuesFrom[i], namespace, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		current := nonTemplate
+		if isTemplate {
+			current = template
+		}
+		for key, value := range data {
+			if overrideKeys {
+				current[key] = value
 			} else {
-				for key, value := range secret.Data {
-					if overrideKeys {
-						nonTemplate[key] = string(value)
-					} else {
-						addToMap(nonTemplate, key, string(value))
-					}
-				}
+				addToMap(current, key, value)
 			}
 		}
 	}
@@ -1722,6 +1906,28 @@ func getValuesFrom(ctx context.Context, c client.Client, clusterSummary *configv
 	return template, nonTemplate, nil
 }
 
+// selectValueFromKey returns the data a ValueFrom contributes: only the entry for Key when Key is set,
+// all of data otherwise. A missing Key is tolerated, and nothing is returned, only for Optional entries.
+func selectValueFromKey(data map[string]string, valueFrom *configv1beta1.ValueFrom,
+	namespace, name string) (map[string]string, error) {
+
+	if valueFrom.Key == "" {
+		return data, nil
+	}
+
+	value, ok := data[valueFrom.Key]
+	if !ok {
+		if valueFrom.Optional {
+			return nil, nil
+		}
+		msg := fmt.Sprintf("Referenced resource: %s %s/%s does not contain key %s",
+			valueFrom.Kind, namespace, name, valueFrom.Key)
+		return nil, &NonRetriableError{Message: msg}
+	}
+
+	return map[string]string{valueFrom.Key: value}, nil
+}
+
 func addToMap(m map[string]string, key, value string) {
 	// Check if the key exists in the map
 	if existingValue, ok := m[key]; ok {
@@ -1745,8 +1951,7 @@ func initiatePatches(ctx context.Context, clusterSummary *configv1beta1.ClusterS
 	instantiatedPatches = clusterSummary.Spec.ClusterProfileSpec.Patches
 
 	for k := range instantiatedPatches {
-		instantiatedPatch, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
-			clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		instantiatedPatch, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
 			requestor, instantiatedPatches[k].Patch, mgmtResources, logger)
 		if err != nil {
 			return nil, err
@@ -1775,6 +1980,7 @@ func getClusterProfileSpecHash(ctx context.Context, clusterSummary *configv1beta
 	// So consider it in the hash
 	config += fmt.Sprintf("%d", clusterProfileSpec.Tier)
 	config += fmt.Sprintf("%t", clusterProfileSpec.ContinueOnConflict)
+	config += fmt.Sprintf("%t", clusterProfileSpec.ContinueOnError)
 
 	if clusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// Use the version. This will cause drift-detection, Sveltos CRDs
@@ -1794,6 +2000,26 @@ func getClusterProfileSpecHash(ctx context.Context, clusterSummary *configv1beta
 		config += render.AsCode(clusterProfileSpec.Patches)
 	}
 
+	if clusterProfileSpec.SecretTransformer != nil {
+		config += render.AsCode(clusterProfileSpec.SecretTransformer)
+		// SealedSecrets must be encrypted again when the certificate changes
+		if clusterProfileSpec.SecretTransformer.Type == configv1beta1.SecretTransformerSealedSecret {
+			certificate, err := getSealedSecretsCertificate(ctx, getManagementClusterClient(), clusterSummary)
+			if err == nil {
+				config += string(certificate)
+			}
+		}
+	}
+
+	if len(clusterProfileSpec.WasmPlugins) > 0 {
+		config += render.AsCode(clusterProfileSpec.WasmPlugins)
+		// Resources must be deployed again when a module stored in a ConfigMap changes
+		modulesHash, err := getWasmPluginsConfigMapModulesHash(ctx, getManagementClusterClient(), clusterSummary)
+		if err == nil {
+			config += modulesHash
+		}
+	}
+
 	// If drift-detectionmanager configuration is in a ConfigMap. fetch ConfigMap and use its Data
 	// section in the hash evaluation.
 	if driftDetectionConfigMap := getDriftDetectionConfigMap(); driftDetectionConfigMap != "" {
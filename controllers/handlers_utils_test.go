@@ -622,8 +622,9 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(err).To(BeNil())
 
 		// following will successfully create deployment
-		Expect(controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
-			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		_, err = controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
 
 		currentDeployment := &appsv1.Deployment{}
 		Eventually(func() bool {
@@ -663,8 +664,9 @@ var _ = Describe("HandlersUtils", func() {
 		}, timeout, pollingInterval).Should(BeTrue())
 
 		// New deploy will not override replicas
-		Expect(controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
-			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		_, err = controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
 
 		Consistently(func() bool {
 			err := testEnv.Get(context.TODO(),
@@ -684,8 +686,9 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(err).To(BeNil())
 
 		// following will successfully create deployment
-		Expect(controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
-			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		_, err = controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
 
 		currentDeployment := &appsv1.Deployment{}
 		Eventually(func() bool {
@@ -733,8 +736,9 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(err).To(BeNil())
 
 		// New deploy will not override replicas
-		Expect(controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, []string{"status"},
-			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		_, err = controllers.UpdateResource(context.TODO(), dr, clusterSummary, u, []string{"status"},
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
 
 		Consistently(func() bool {
 			err := testEnv.Get(context.TODO(),
@@ -1373,8 +1377,13 @@ metadata:
 		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
 			currentClusterSummary)).To(Succeed())
 
-		Expect(controllers.HandleResourceDelete(ctx, c, depl, currentClusterSummary,
-			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		leavePolicies, err := controllers.IsLeavePolicies(ctx, currentClusterSummary, configv1beta1.FeatureResources,
+			logger)
+		Expect(err).To(BeNil())
+		Expect(leavePolicies).To(BeTrue())
+
+		Expect(controllers.HandleResourceDelete(ctx, c, depl, leavePolicies, logger)).To(Succeed())
 
 		currentDepl := &appsv1.Deployment{}
 		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: depl.Namespace, Name: depl.Name}, currentDepl)).To(Succeed())
@@ -0,0 +1,92 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// getClusterConditions returns the conditions evaluated by the ClusterHealthCheck for the cluster.
+// Returns false if ClusterHealthCheck is not (yet) evaluated for the cluster.
+func getClusterConditions(chc *libsveltosv1beta1.ClusterHealthCheck, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) ([]libsveltosv1beta1.Condition, bool) {
+
+	for i := range chc.Status.ClusterConditions {
+		cluster := &chc.Status.ClusterConditions[i].ClusterInfo.Cluster
+		if cluster.Namespace == clusterNamespace && cluster.Name == clusterName &&
+			clusterproxy.GetClusterType(cluster) == clusterType {
+
+			return chc.Status.ClusterConditions[i].Conditions, true
+		}
+	}
+
+	return nil, false
+}
+
+// areHealthChecksPassing returns true if all conditions reported, for the cluster, by the ClusterHealthChecks
+// listed as HealthCheckGates are passing. When it returns false, message explains which health check
+// deployment is waiting for.
+// Gates only apply to updates. The first deployment of the features of a ClusterSummary is never gated.
+func (r *ClusterSummaryReconciler) areHealthChecksPassing(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) (passing bool, message string, err error) {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	if clusterSummary.Status.LastSuccessfulSyncTime == nil {
+		return true, "", nil
+	}
+
+	for _, chcName := range clusterSummary.Spec.ClusterProfileSpec.HealthCheckGates {
+		chc := &libsveltosv1beta1.ClusterHealthCheck{}
+		if err := r.Get(ctx, types.NamespacedName{Name: chcName}, chc); err != nil {
+			if apierrors.IsNotFound(err) {
+				msg := fmt.Sprintf("waiting for ClusterHealthCheck %s: not found", chcName)
+				logger.V(logs.LogInfo).Info(msg)
+				return false, msg, nil
+			}
+			return false, "", err
+		}
+
+		conditions, ok := getClusterConditions(chc, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+		if !ok {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("ClusterHealthCheck %s does not match cluster", chcName))
+			continue
+		}
+
+		for i := range conditions {
+			if conditions[i].Status != corev1.ConditionTrue {
+				msg := fmt.Sprintf("waiting for ClusterHealthCheck %s: condition %s is not passing",
+					chcName, conditions[i].Name)
+				logger.V(logs.LogInfo).Info(msg)
+				return false, msg, nil
+			}
+		}
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Health check gates", func() {
+	var clusterNamespace, clusterName string
+
+	BeforeEach(func() {
+		clusterNamespace = randomString()
+		clusterName = randomString()
+	})
+
+	getClusterHealthCheck := func(status corev1.ConditionStatus) *libsveltosv1beta1.ClusterHealthCheck {
+		return &libsveltosv1beta1.ClusterHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Status: libsveltosv1beta1.ClusterHealthCheckStatus{
+				ClusterConditions: []libsveltosv1beta1.ClusterCondition{
+					{
+						ClusterInfo: libsveltosv1beta1.ClusterInfo{
+							Cluster: corev1.ObjectReference{
+								Namespace:  clusterNamespace,
+								Name:       clusterName,
+								Kind:       clusterKind,
+								APIVersion: clusterv1.GroupVersion.String(),
+							},
+						},
+						Conditions: []libsveltosv1beta1.Condition{
+							{Name: randomString(), Type: libsveltosv1beta1.ConditionType(randomString()), Status: status},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	getClusterSummary := func(healthCheckGates []string) *configv1beta1.ClusterSummary {
+		now := metav1.Now()
+		return &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: clusterNamespace,
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: clusterNamespace,
+				ClusterName:      clusterName,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HealthCheckGates: healthCheckGates,
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				LastSuccessfulSyncTime: &now,
+			},
+		}
+	}
+
+	areHealthChecksPassing := func(c client.Client, clusterSummary *configv1beta1.ClusterSummary) (bool, string) {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         logger,
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		passing, msg, err := controllers.AreHealthChecksPassing(reconciler, context.TODO(), clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		return passing, msg
+	}
+
+	It("areHealthChecksPassing gates updates on ClusterHealthCheck conditions", func() {
+		healthy := getClusterHealthCheck(corev1.ConditionTrue)
+		unhealthy := getClusterHealthCheck(corev1.ConditionFalse)
+
+		// ClusterHealthCheck not evaluated for this cluster
+		otherCluster := getClusterHealthCheck(corev1.ConditionFalse)
+		otherCluster.Status.ClusterConditions[0].ClusterInfo.Cluster.Name = randomString()
+
+		initObjects := []client.Object{healthy, unhealthy, otherCluster}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		passing, _ := areHealthChecksPassing(c, getClusterSummary([]string{healthy.Name, otherCluster.Name}))
+		Expect(passing).To(BeTrue())
+
+		passing, msg := areHealthChecksPassing(c, getClusterSummary([]string{healthy.Name, unhealthy.Name}))
+		Expect(passing).To(BeFalse())
+		Expect(msg).To(ContainSubstring(unhealthy.Name))
+		Expect(msg).To(ContainSubstring(unhealthy.Status.ClusterConditions[0].Conditions[0].Name))
+
+		notExisting := randomString()
+		passing, msg = areHealthChecksPassing(c, getClusterSummary([]string{notExisting}))
+		Expect(passing).To(BeFalse())
+		Expect(msg).To(ContainSubstring(notExisting))
+	})
+
+	It("areHealthChecksPassing does not gate first deployment", func() {
+		unhealthy := getClusterHealthCheck(corev1.ConditionFalse)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(unhealthy).Build()
+
+		clusterSummary := getClusterSummary([]string{unhealthy.Name})
+		clusterSummary.Status.LastSuccessfulSyncTime = nil
+
+		passing, _ := areHealthChecksPassing(c, clusterSummary)
+		Expect(passing).To(BeTrue())
+	})
+})
@@ -0,0 +1,258 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-logr/logr"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	chartCacheExtension = ".tgz"
+)
+
+// chartCacheEntry is a chart archive stored in the chart cache
+type chartCacheEntry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// chartCache stores the chart archives downloaded from helm repositories and OCI registries, so
+// all ClusterSummaries deploying the same chart version reuse it instead of downloading it again.
+// When the archives exceed maxSize, least recently used ones are removed.
+type chartCache struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+	size    int64
+	// key: cache key; value: archive stored in dir
+	entries map[string]*chartCacheEntry
+}
+
+var (
+	helmChartCache *chartCache
+)
+
+// SetHelmChartCache enables the chart cache in dir, limited to maxSize bytes. Archives already
+// in dir, for instance cached before a restart, are reused. An empty dir disables the cache.
+func SetHelmChartCache(dir string, maxSize int64, logger logr.Logger) error {
+	if dir == "" {
+		helmChartCache = nil
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, permission0755); err != nil {
+		return err
+	}
+
+	cache := &chartCache{dir: dir, maxSize: maxSize, entries: make(map[string]*chartCacheEntry)}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for i := range files {
+		if files[i].IsDir() || !strings.HasSuffix(files[i].Name(), chartCacheExtension) {
+			continue
+		}
+		var info os.FileInfo
+		info, err = files[i].Info()
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(files[i].Name(), chartCacheExtension)
+		cache.entries[key] = &chartCacheEntry{size: info.Size(), lastUsed: info.ModTime()}
+		cache.size += info.Size()
+	}
+
+	cache.mu.Lock()
+	cache.evict(logger)
+	cache.mu.Unlock()
+
+	helmChartCache = cache
+	return nil
+}
+
+// getChartCacheKey returns the key of a chart in the cache. Only charts with an exact version (or an
+// OCI chart pinned to a digest) are cached, as any other reference can resolve to a different chart
+// over time. Charts verified before deployment are never cached, so verification always happens
+// against the content served by the repository. Credentials are part of the key, so a private chart
+// is only reused by profiles with the same credentials. The credentials Secret namespace is resolved
+// as when the Secret is fetched: when not set, it is the namespace of the cluster (clusterNamespace).
+func getChartCacheKey(clusterNamespace string, requestedChart *configv1beta1.HelmChart, chartName string,
+) (string, bool) {
+
+	if requestedChart.Verify != nil || isFluxChartSource(requestedChart) {
+		return "", false
+	}
+
+	if _, err := semver.StrictNewVersion(strings.TrimPrefix(requestedChart.ChartVersion, "v")); err != nil &&
+		!strings.Contains(chartName, "@sha256:") {
+
+		return "", false
+	}
+
+	credentials := ""
+	if requestedChart.RegistryCredentialsConfig != nil &&
+		requestedChart.RegistryCredentialsConfig.CredentialsSecretRef != nil {
+
+		credSecretRef := requestedChart.RegistryCredentialsConfig.CredentialsSecretRef
+		credentials = fmt.Sprintf("%s/%s",
+			libsveltostemplate.GetReferenceResourceNamespace(clusterNamespace, credSecretRef.Namespace),
+			credSecretRef.Name)
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%s", requestedChart.RepositoryURL, chartName, requestedChart.ChartVersion, credentials)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key))), true
+}
+
+func (c *chartCache) path(key string) string {
+	return filepath.Join(c.dir, key+chartCacheExtension)
+}
+
+// get copies the cached archive, if any, in a new temporary directory. Returns the path of the
+// copy and the temporary directory, which caller must remove. Copying allows the cached archive to
+// be evicted while the chart is being deployed.
+func (c *chartCache) get(key string) (chartPath, tmpDir string, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", "", false
+	}
+
+	tmpDir, err := os.MkdirTemp("", "chart-cache-")
+	if err != nil {
+		return "", "", false
+	}
+
+	chartPath = filepath.Join(tmpDir, key+chartCacheExtension)
+	if err = copyFile(c.path(key), chartPath); err != nil {
+		os.RemoveAll(tmpDir)
+		// Cached archive is unusable. Download it again.
+		c.remove(key)
+		return "", "", false
+	}
+
+	entry.lastUsed = time.Now()
+	return chartPath, tmpDir, true
+}
+
+// add stores the archive at chartPath in the cache, then evicts least recently used archives
+// if the cache exceeds its size
+func (c *chartCache) add(key, chartPath string, logger logr.Logger) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		c.entries[key].lastUsed = time.Now()
+		return nil
+	}
+
+	// Archive is copied in a temporary file first, so a partially written archive is never used
+	tmpFile, err := os.CreateTemp(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	tmpFile.Close()
+
+	if err = copyFile(chartPath, tmpFile.Name()); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	info, err := os.Stat(tmpFile.Name())
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err = os.Rename(tmpFile.Name(), c.path(key)); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	c.entries[key] = &chartCacheEntry{size: info.Size(), lastUsed: time.Now()}
+	c.size += info.Size()
+
+	c.evict(logger)
+	return nil
+}
+
+// evict removes least recently used archives till the cache size does not exceed maxSize.
+// Must be called with mu held.
+func (c *chartCache) evict(logger logr.Logger) {
+	for c.size > c.maxSize && len(c.entries) > 0 {
+		oldestKey := ""
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if oldestKey == "" || entry.lastUsed.Before(oldest) {
+				oldestKey = key
+				oldest = entry.lastUsed
+			}
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("evicting chart %s from cache", oldestKey))
+		c.remove(oldestKey)
+	}
+}
+
+// remove deletes an archive from the cache. Must be called with mu held.
+func (c *chartCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	os.Remove(c.path(key))
+	c.size -= entry.size
+	delete(c.entries, key)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, writeFilePermission)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
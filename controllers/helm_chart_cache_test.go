@@ -0,0 +1,117 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm chart cache", func() {
+	AfterEach(func() {
+		Expect(controllers.SetHelmChartCache("", 0, logr.Discard())).To(Succeed())
+	})
+
+	It("getChartCacheKey caches only charts with an exact version", func() {
+		clusterNamespace := randomString()
+		helmChart := &configv1beta1.HelmChart{
+			RepositoryURL: "https://kyverno.github.io/kyverno/",
+			ChartName:     "kyverno/kyverno",
+			ChartVersion:  "v3.0.1",
+			ReleaseName:   "kyverno-latest",
+		}
+
+		key, cacheable := controllers.GetChartCacheKey(clusterNamespace, helmChart, helmChart.ChartName)
+		Expect(cacheable).To(BeTrue())
+		Expect(key).ToNot(BeEmpty())
+
+		// Same chart fetched with different credentials has a different key
+		withCredentials := helmChart.DeepCopy()
+		withCredentials.RegistryCredentialsConfig = &configv1beta1.RegistryCredentialsConfig{
+			CredentialsSecretRef: &corev1.SecretReference{Namespace: randomString(), Name: randomString()},
+		}
+		otherKey, cacheable := controllers.GetChartCacheKey(clusterNamespace, withCredentials, withCredentials.ChartName)
+		Expect(cacheable).To(BeTrue())
+		Expect(otherKey).ToNot(Equal(key))
+
+		// Credentials Secret namespace defaults to the cluster namespace. Same Secret name in different
+		// namespaces is a different key.
+		withCredentials.RegistryCredentialsConfig.CredentialsSecretRef.Namespace = ""
+		defaultNamespaceKey, _ := controllers.GetChartCacheKey(clusterNamespace, withCredentials, withCredentials.ChartName)
+		otherNamespaceKey, _ := controllers.GetChartCacheKey(randomString(), withCredentials, withCredentials.ChartName)
+		Expect(defaultNamespaceKey).ToNot(Equal(otherNamespaceKey))
+
+		withCredentials.RegistryCredentialsConfig.CredentialsSecretRef.Namespace = clusterNamespace
+		explicitNamespaceKey, _ := controllers.GetChartCacheKey(clusterNamespace, withCredentials, withCredentials.ChartName)
+		Expect(explicitNamespaceKey).To(Equal(defaultNamespaceKey))
+
+		helmChart.ChartVersion = ">=3.0.0"
+		_, cacheable = controllers.GetChartCacheKey(clusterNamespace, helmChart, helmChart.ChartName)
+		Expect(cacheable).To(BeFalse())
+
+		helmChart.ChartVersion = "3.0.1"
+		helmChart.Verify = &configv1beta1.ChartVerification{}
+		_, cacheable = controllers.GetChartCacheKey(clusterNamespace, helmChart, helmChart.ChartName)
+		Expect(cacheable).To(BeFalse())
+	})
+
+	It("evicts least recently used charts when cache is full", func() {
+		dir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		cacheDir := filepath.Join(dir, "cache")
+		const archiveSize = 8
+		Expect(controllers.SetHelmChartCache(cacheDir, 2*archiveSize, logr.Discard())).To(Succeed())
+
+		for _, key := range []string{"a", "b", "c"} {
+			archive := filepath.Join(dir, key+".tgz")
+			Expect(os.WriteFile(archive, []byte("chart-"+key+"0"), os.ModePerm)).To(Succeed())
+			Expect(controllers.AddToHelmChartCache(key, archive)).To(Succeed())
+
+			if key == "b" {
+				// a is used, so b becomes the least recently used chart
+				chartPath, tmpDir, found := controllers.GetFromHelmChartCache("a")
+				Expect(found).To(BeTrue())
+				content, err := os.ReadFile(chartPath)
+				Expect(err).To(BeNil())
+				Expect(string(content)).To(Equal("chart-a0"))
+				Expect(os.RemoveAll(tmpDir)).To(Succeed())
+			}
+		}
+
+		_, _, found := controllers.GetFromHelmChartCache("b")
+		Expect(found).To(BeFalse())
+
+		// Cached charts are found again after a restart
+		Expect(controllers.SetHelmChartCache(cacheDir, 2*archiveSize, logr.Discard())).To(Succeed())
+		for _, key := range []string{"a", "c"} {
+			_, tmpDir, found := controllers.GetFromHelmChartCache(key)
+			Expect(found).To(BeTrue())
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		}
+	})
+})
@@ -0,0 +1,342 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// chartDependenciesCacheDir is the directory, within the helm repository cache, where
+	// downloaded chart dependencies are cached
+	chartDependenciesCacheDir = "sveltos-chart-dependencies"
+
+	// chartsDir is the directory, within a chart, containing its dependencies
+	chartsDir = "charts"
+)
+
+// validateDependencyRepositories verifies DependencyRepositories of each helm chart reference
+// HTTP helm repositories
+func validateDependencyRepositories(spec *configv1beta1.Spec) error {
+	for i := range spec.HelmCharts {
+		helmChart := &spec.HelmCharts[i]
+		for j := range helmChart.DependencyRepositories {
+			repoURL := helmChart.DependencyRepositories[j].URL
+			if registry.IsOCI(repoURL) {
+				return fmt.Errorf("helm chart %s/%s: dependencyRepositories %s: OCI registries are accessed with "+
+					"the chart registryCredentialsConfig", helmChart.ReleaseNamespace, helmChart.ReleaseName, repoURL)
+			}
+			if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
+				return fmt.Errorf("helm chart %s/%s: dependencyRepositories %s: only HTTP helm repositories are supported",
+					helmChart.ReleaseNamespace, helmChart.ReleaseName, repoURL)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveChartDependencies downloads the dependencies declared in Chart.yaml and missing from the
+// chart at chartPath, for instance for charts stored in Git or in local tarballs without vendored subcharts.
+// Dependencies are downloaded from Chart.lock, if present, unless DependencyUpdate is set. Downloaded
+// dependencies are cached so charts with the same dependencies do not download them again.
+// Returns the chart with all its dependencies. Archives are expanded in a temporary directory which
+// is removed before returning.
+func resolveChartDependencies(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, chartRequested *chart.Chart, chartPath string, settings *cli.EnvSettings,
+	registryOptions *registryClientOptions, keyring string, logger logr.Logger) (*chart.Chart, error) {
+
+	if chartRequested.Metadata == nil || len(chartRequested.Metadata.Dependencies) == 0 {
+		return chartRequested, nil
+	}
+
+	if err := action.CheckDependencies(chartRequested, chartRequested.Metadata.Dependencies); err == nil {
+		return chartRequested, nil
+	}
+
+	logger.V(logs.LogDebug).Info("resolving chart dependencies")
+
+	chartDir, cleanupDir, err := getChartDirectory(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupDir()
+
+	cacheDir := getChartDependenciesCacheDir(settings, chartRequested)
+	if !getDependenciesUpdateValue(requestedChart.Options) {
+		var resolved *chart.Chart
+		resolved, err = loadCachedChartDependencies(cacheDir, chartDir)
+		if err == nil && resolved != nil {
+			logger.V(logs.LogDebug).Info("chart dependencies found in cache")
+			return resolved, nil
+		}
+	}
+
+	cleanupRepositories, err := addDependencyRepositories(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		requestedChart, settings, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupRepositories()
+
+	registryClient, err := getRegistryClient(requestedChart.ReleaseNamespace, registryOptions,
+		getEnableClientCacheValue(requestedChart.Options))
+	if err != nil {
+		return nil, err
+	}
+
+	man := &downloader.Manager{
+		Out:              io.Discard,
+		ChartPath:        chartDir,
+		Keyring:          keyring,
+		Getters:          getter.All(settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+
+	_, statErr := os.Stat(filepath.Join(chartDir, "Chart.lock"))
+	if statErr == nil && !getDependenciesUpdateValue(requestedChart.Options) {
+		err = man.Build()
+	} else {
+		err = man.Update()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chart dependencies: %w", err)
+	}
+
+	resolved, err := loader.Load(chartDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed reloading chart after dependencies update", err)
+	}
+
+	if err = storeChartDependencies(chartDir, cacheDir); err != nil {
+		// Cache is an optimization only
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to cache chart dependencies: %v", err))
+	}
+
+	return resolved, nil
+}
+
+// getChartDirectory returns the directory containing the unpacked chart. Chart archives are expanded
+// in a temporary directory, removed by the returned cleanup function.
+func getChartDirectory(chartPath string) (chartDir string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	fi, err := os.Stat(chartPath)
+	if err != nil {
+		return "", cleanup, err
+	}
+	if fi.IsDir() {
+		return chartPath, cleanup, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "chart-dependencies-")
+	if err != nil {
+		return "", cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(tmpDir) }
+
+	if err = chartutil.ExpandFile(tmpDir, chartPath); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		cleanup()
+		return "", func() {}, fmt.Errorf("unexpected content in chart archive %s", chartPath)
+	}
+
+	return filepath.Join(tmpDir, entries[0].Name()), cleanup, nil
+}
+
+// getChartDependenciesCacheDir returns the cache directory for the chart dependencies. The directory
+// depends on the declared dependencies and, if present, on the Chart.lock digest.
+func getChartDependenciesCacheDir(settings *cli.EnvSettings, chartRequested *chart.Chart) string {
+	dependencies := make([]string, len(chartRequested.Metadata.Dependencies))
+	for i, dependency := range chartRequested.Metadata.Dependencies {
+		dependencies[i] = fmt.Sprintf("%s/%s/%s", dependency.Repository, dependency.Name, dependency.Version)
+	}
+	sort.Strings(dependencies)
+
+	key := strings.Join(dependencies, ",")
+	if chartRequested.Lock != nil {
+		key += chartRequested.Lock.Digest
+	}
+
+	return filepath.Join(settings.RepositoryCache, chartDependenciesCacheDir,
+		fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+}
+
+// loadCachedChartDependencies copies cached dependencies in the chart charts directory. Returns
+// the chart, with its dependencies, only if all of them were found in the cache.
+func loadCachedChartDependencies(cacheDir, chartDir string) (*chart.Chart, error) {
+	if _, err := os.Stat(cacheDir); err != nil {
+		// Nothing is cached
+		return nil, nil
+	}
+
+	if err := copyChartArchives(cacheDir, filepath.Join(chartDir, chartsDir)); err != nil {
+		return nil, err
+	}
+
+	cached, err := loader.Load(chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = action.CheckDependencies(cached, cached.Metadata.Dependencies); err != nil {
+		// Cache is stale, dependencies are downloaded again
+		return nil, nil
+	}
+
+	return cached, nil
+}
+
+// storeChartDependencies copies the dependencies of the chart in the cache directory
+func storeChartDependencies(chartDir, cacheDir string) error {
+	// Dependencies are copied in a temporary directory first, so an incomplete cache is never used
+	if err := os.MkdirAll(filepath.Dir(cacheDir), permission0755); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(cacheDir), "tmp-")
+	if err != nil {
+		return err
+	}
+
+	if err = copyChartArchives(filepath.Join(chartDir, chartsDir), tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	os.RemoveAll(cacheDir)
+	if err = os.Rename(tmpDir, cacheDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	return nil
+}
+
+// copyChartArchives copies the chart archives (.tgz) from src to dst
+func copyChartArchives(src, dst string) error {
+	archives, err := filepath.Glob(filepath.Join(src, "*.tgz"))
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dst, permission0755); err != nil {
+		return err
+	}
+
+	for _, archive := range archives {
+		var content []byte
+		content, err = os.ReadFile(archive)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filepath.Join(dst, filepath.Base(archive)), content, writeFilePermission)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addDependencyRepositories adds the DependencyRepositories of the chart to the helm repository
+// configuration, so dependencies are downloaded with the configured credentials. The returned
+// cleanup function removes the temporary files with certificates.
+func addDependencyRepositories(ctx context.Context, c client.Client, clusterNamespace string,
+	requestedChart *configv1beta1.HelmChart, settings *cli.EnvSettings, logger logr.Logger) (func(), error) {
+
+	optionsList := make([]*registryClientOptions, 0, len(requestedChart.DependencyRepositories))
+	cleanup := func() {
+		for _, options := range optionsList {
+			if options.caPath != "" {
+				os.Remove(options.caPath)
+			}
+			removeHelmRepositoryAuthFiles(options)
+		}
+	}
+
+	for i := range requestedChart.DependencyRepositories {
+		dependencyRepository := &requestedChart.DependencyRepositories[i]
+		dependencyChart := &configv1beta1.HelmChart{
+			RepositoryURL:             dependencyRepository.URL,
+			RegistryCredentialsConfig: dependencyRepository.RegistryCredentialsConfig,
+		}
+
+		caPath, err := createFileWithCA(ctx, c, clusterNamespace, dependencyChart)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		options := &registryClientOptions{
+			caPath:        caPath,
+			skipTLSVerify: getInsecureSkipTLSVerify(dependencyChart),
+			plainHTTP:     getPlainHTTP(dependencyChart),
+		}
+		optionsList = append(optionsList, options)
+
+		err = setHelmRepositoryAuth(ctx, c, clusterNamespace, dependencyChart, options)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		err = repoAddOrUpdate(settings, getDependencyRepositoryName(dependencyRepository.URL),
+			dependencyRepository.URL, options, logger)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
+	return cleanup, nil
+}
+
+// getDependencyRepositoryName returns the name of the helm repository entry for a DependencyRepository
+func getDependencyRepositoryName(repoURL string) string {
+	const length = 16
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(repoURL)))
+	return "sveltos-dependency-" + hash[:length]
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+const (
+	childChart = `apiVersion: v2
+name: child
+version: 0.1.0
+`
+	parentChart = `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+- name: child
+  version: 0.1.0
+  repository: file://../child
+`
+)
+
+var _ = Describe("Helm chart dependencies", func() {
+	It("validateDependencyRepositories accepts HTTP helm repositories only", func() {
+		spec := &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL: "https://kyverno.github.io/kyverno/", ReleaseName: randomString(),
+					DependencyRepositories: []configv1beta1.DependencyRepository{
+						{URL: "https://charts.bitnami.com/bitnami"},
+					},
+				},
+			},
+		}
+		Expect(controllers.ValidateDependencyRepositories(spec)).To(Succeed())
+
+		spec.HelmCharts[0].DependencyRepositories = append(spec.HelmCharts[0].DependencyRepositories,
+			configv1beta1.DependencyRepository{URL: "oci://registry-1.docker.io/bitnamicharts"})
+		Expect(controllers.ValidateDependencyRepositories(spec)).ToNot(Succeed())
+	})
+
+	It("resolveChartDependencies downloads missing dependencies and caches them", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		dir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		for name, content := range map[string]string{"child": childChart, "parent": parentChart} {
+			Expect(os.MkdirAll(filepath.Join(dir, name, "templates"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, name, "Chart.yaml"), []byte(content), os.ModePerm)).To(Succeed())
+		}
+
+		settings := cli.New()
+		settings.RepositoryCache = filepath.Join(dir, "cache")
+		settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+
+		parentPath := filepath.Join(dir, "parent")
+		parent, err := loader.Load(parentPath)
+		Expect(err).To(BeNil())
+		Expect(parent.Dependencies()).To(BeEmpty())
+
+		resolved, err := controllers.ResolveChartDependencies(context.TODO(), nil, &configv1beta1.HelmChart{},
+			parent, parentPath, settings, logger)
+		Expect(err).To(BeNil())
+		Expect(resolved.Dependencies()).To(HaveLen(1))
+		Expect(resolved.Dependencies()[0].Name()).To(Equal("child"))
+
+		// Dependency is cached. Once removed from its repository, it is still found.
+		Expect(os.RemoveAll(filepath.Join(dir, "child"))).To(Succeed())
+		Expect(os.RemoveAll(filepath.Join(parentPath, "charts"))).To(Succeed())
+		Expect(os.RemoveAll(filepath.Join(parentPath, "Chart.lock"))).To(Succeed())
+
+		parent, err = loader.Load(parentPath)
+		Expect(err).To(BeNil())
+		resolved, err = controllers.ResolveChartDependencies(context.TODO(), nil, &configv1beta1.HelmChart{},
+			parent, parentPath, settings, logger)
+		Expect(err).To(BeNil())
+		Expect(resolved.Dependencies()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,395 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/go-logr/logr"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"oras.land/oras-go/pkg/auth"
+	dockerauth "oras.land/oras-go/pkg/auth/docker"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// cosignSignatureAnnotation is the annotation, on each layer of a cosign signature manifest,
+	// containing the base64 encoded signature of the layer (the signed payload)
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// cosignSignatureTagSuffix is the suffix of the tag cosign stores signatures with.
+	// Tag is sha256-<chart manifest digest>.sig
+	cosignSignatureTagSuffix = ".sig"
+
+	// maxCosignContentSize is the maximum size of signature manifests and payloads read from the registry
+	maxCosignContentSize = 4 * 1024 * 1024
+)
+
+// cosignPayload is the simple signing payload signed by cosign
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// validateHelmChartVerification verifies the verification provider of each helm chart is supported for the
+// chart repository
+func validateHelmChartVerification(spec *configv1beta1.Spec) error {
+	for i := range spec.HelmCharts {
+		if err := checkChartVerificationSupported(&spec.HelmCharts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkChartVerificationSupported returns an error if the chart Verify provider can not be used for the chart
+// repository: cosign is supported for OCI registries only, gpg for HTTP repositories only.
+// Charts stored in Flux GitRepository/OCIRepository/Bucket can not be verified.
+func checkChartVerificationSupported(requestedChart *configv1beta1.HelmChart) error {
+	if requestedChart.Verify == nil {
+		return nil
+	}
+
+	if isFluxChartSource(requestedChart) {
+		return fmt.Errorf("helm chart %s/%s: verify is not supported for charts stored in Flux sources",
+			requestedChart.ReleaseNamespace, requestedChart.ReleaseName)
+	}
+
+	if requestedChart.SourceRef != nil {
+		// HelmRepository sourceRef. URL is only known once the HelmRepository is fetched.
+		return nil
+	}
+
+	repositoryURL := requestedChart.RepositoryURL
+	switch requestedChart.Verify.Provider {
+	case configv1beta1.ChartVerificationProviderCosign:
+		if !registry.IsOCI(repositoryURL) {
+			return fmt.Errorf("helm chart %s/%s: cosign verification requires an OCI registry",
+				requestedChart.ReleaseNamespace, requestedChart.ReleaseName)
+		}
+	case configv1beta1.ChartVerificationProviderGPG:
+		if registry.IsOCI(repositoryURL) {
+			return fmt.Errorf("helm chart %s/%s: gpg verification requires an HTTP repository",
+				requestedChart.ReleaseNamespace, requestedChart.ReleaseName)
+		}
+	default:
+		return fmt.Errorf("helm chart %s/%s: unsupported verification provider %q",
+			requestedChart.ReleaseNamespace, requestedChart.ReleaseName, requestedChart.Verify.Provider)
+	}
+
+	return nil
+}
+
+// prepareChartVerification verifies the chart, if requested, before it is located and deployed.
+// - cosign: the chart signature is verified now;
+// - gpg: chartPathOptions are set so helm verifies the chart provenance file when downloading the chart.
+// Returned function must be called once the chart has been located.
+func prepareChartVerification(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, chartName string, chartPathOptions *action.ChartPathOptions,
+	registryOptions *registryClientOptions, logger logr.Logger) (func(), error) {
+
+	cleanup := func() {}
+	if requestedChart.Verify == nil {
+		return cleanup, nil
+	}
+
+	if err := checkChartVerificationSupported(requestedChart); err != nil {
+		return cleanup, &NonRetriableError{Message: err.Error()}
+	}
+
+	keys, err := getChartVerificationKeys(ctx, getManagementClusterClient(),
+		clusterSummary.Spec.ClusterNamespace, requestedChart.Verify)
+	if err != nil {
+		return cleanup, err
+	}
+
+	if requestedChart.Verify.Provider == configv1beta1.ChartVerificationProviderCosign {
+		err = verifyCosignSignature(ctx, chartName, requestedChart.ChartVersion, registryOptions, keys)
+		if err != nil {
+			return cleanup, fmt.Errorf("chart %s:%s verification failed: %w", chartName, requestedChart.ChartVersion, err)
+		}
+		logger.V(logs.LogDebug).Info("chart cosign signature verified")
+		return cleanup, nil
+	}
+
+	// Keyring is the concatenation of all the exported keyrings
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var keyring []byte
+	for i := range names {
+		keyring = append(keyring, keys[names[i]]...)
+	}
+
+	keyringPath, err := createTemporaryFile("keyring-*.gpg", keyring)
+	if err != nil {
+		return cleanup, err
+	}
+
+	chartPathOptions.Verify = true
+	chartPathOptions.Keyring = keyringPath
+	return func() { os.Remove(keyringPath) }, nil
+}
+
+// getChartVerificationError returns the error to report when locating a chart fails. With gpg verification,
+// helm downloads and verifies the provenance file while locating the chart.
+func getChartVerificationError(requestedChart *configv1beta1.HelmChart, err error) error {
+	if requestedChart.Verify == nil || requestedChart.Verify.Provider != configv1beta1.ChartVerificationProviderGPG {
+		return err
+	}
+	return fmt.Errorf("failed to download chart or verify its provenance: %w", err)
+}
+
+// getChartVerificationKeys returns the data of the Secret referenced by the chart Verify section.
+// For cosign, only keys ending with .pub are returned.
+func getChartVerificationKeys(ctx context.Context, c client.Client, clusterNamespace string,
+	verification *configv1beta1.ChartVerification) (map[string][]byte, error) {
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterNamespace, verification.SecretRef.Namespace)
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: verification.SecretRef.Name}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte)
+	for k, v := range secret.Data {
+		if verification.Provider == configv1beta1.ChartVerificationProviderCosign && !strings.HasSuffix(k, ".pub") {
+			continue
+		}
+		keys[k] = v
+	}
+
+	if len(keys) == 0 {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("secret %s/%s referenced in HelmChart verify section contains no key",
+			namespace, verification.SecretRef.Name)}
+	}
+
+	return keys, nil
+}
+
+// verifyCosignSignature verifies the chart chartRef (oci://<registry>/<repository>) at version has a
+// cosign signature made with one of the public keys.
+func verifyCosignSignature(ctx context.Context, chartRef, version string, registryOptions *registryClientOptions,
+	keys map[string][]byte) error {
+
+	resolver, err := getOCIResolver(registryOptions)
+	if err != nil {
+		return err
+	}
+
+	repository := strings.TrimPrefix(chartRef, fmt.Sprintf("%s://", registry.OCIScheme))
+	// OCI tags do not support +. Helm replaces it with _
+	ref := fmt.Sprintf("%s:%s", repository, strings.ReplaceAll(version, "+", "_"))
+	_, chartDesc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	signatureRef := fmt.Sprintf("%s:%s%s", repository, strings.Replace(chartDesc.Digest.String(), ":", "-", 1),
+		cosignSignatureTagSuffix)
+	_, signatureDesc, err := resolver.Resolve(ctx, signatureRef)
+	if err != nil {
+		return fmt.Errorf("no cosign signature found: %w", err)
+	}
+
+	data, err := fetchOCIContent(ctx, resolver, signatureRef, signatureDesc, maxCosignContentSize)
+	if err != nil {
+		return err
+	}
+
+	manifest := &ocispec.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return fmt.Errorf("invalid cosign signature manifest: %w", err)
+	}
+
+	for i := range manifest.Layers {
+		signature, ok := manifest.Layers[i].Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		var payload []byte
+		payload, err = fetchOCIContent(ctx, resolver, signatureRef, manifest.Layers[i], maxCosignContentSize)
+		if err != nil {
+			return err
+		}
+
+		if verifyCosignPayload(keys, chartDesc.Digest.String(), payload, signature) == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no valid cosign signature found for digest %s", chartDesc.Digest)
+}
+
+// verifyCosignPayload verifies signature (base64 encoded) is a valid signature of payload made with any of the
+// PEM encoded public keys, and that payload refers to manifestDigest.
+func verifyCosignPayload(keys map[string][]byte, manifestDigest string, payload []byte, signature string) error {
+	rawSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	p := &cosignPayload{}
+	if err := json.Unmarshal(payload, p); err != nil {
+		return fmt.Errorf("invalid signature payload: %w", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != manifestDigest {
+		return fmt.Errorf("signature payload refers to digest %s, not %s",
+			p.Critical.Image.DockerManifestDigest, manifestDigest)
+	}
+
+	for k := range keys {
+		var publicKey crypto.PublicKey
+		publicKey, err = parsePublicKey(keys[k])
+		if err != nil {
+			return fmt.Errorf("invalid public key %s: %w", k, err)
+		}
+
+		if verifySignature(publicKey, payload, rawSignature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature does not match any public key")
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func verifySignature(publicKey crypto.PublicKey, payload, signature []byte) bool {
+	hash := sha256.Sum256(payload)
+	switch k := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, hash[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	}
+
+	return false
+}
+
+// getOCIResolver returns an OCI resolver using the credentials and TLS settings in registryOptions
+func getOCIResolver(registryOptions *registryClientOptions) (remotes.Resolver, error) {
+	var configPaths []string
+	if registryOptions.credentialsPath != "" {
+		configPaths = append(configPaths, registryOptions.credentialsPath)
+	}
+
+	authClient, err := dockerauth.NewClientWithDockerFallback(configPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []auth.ResolverOption
+	if registryOptions.plainHTTP {
+		options = append(options, auth.WithResolverPlainHTTP())
+	}
+
+	if registryOptions.caPath != "" || registryOptions.skipTLSVerify {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: registryOptions.skipTLSVerify, //nolint: gosec // user explicitly asked for it
+		}
+		if registryOptions.caPath != "" {
+			var ca []byte
+			ca, err = os.ReadFile(registryOptions.caPath)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		options = append(options, auth.WithResolverClient(&http.Client{Transport: transport}))
+	}
+
+	return authClient.ResolverWithOpts(options...)
+}
+
+// fetchOCIContent fetches the content described by desc, up to maxSize bytes, and verifies its digest
+func fetchOCIContent(ctx context.Context, resolver remotes.Resolver, ref string, desc ocispec.Descriptor,
+	maxSize int64) ([]byte, error) {
+
+	if desc.Size > maxSize {
+		return nil, fmt.Errorf("content %s is too large (%d bytes)", desc.Digest, desc.Size)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if digest.FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("content %s does not match its digest", desc.Digest)
+	}
+
+	return data, nil
+}
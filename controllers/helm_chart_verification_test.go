@@ -0,0 +1,101 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm chart verification", func() {
+	It("validateHelmChartVerification verifies provider is supported for the chart repository", func() {
+		spec := &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL: "oci://registry-1.docker.io/bitnamicharts", ReleaseName: randomString(),
+					Verify: &configv1beta1.ChartVerification{Provider: configv1beta1.ChartVerificationProviderCosign},
+				},
+				{
+					RepositoryURL: "https://kyverno.github.io/kyverno/", ReleaseName: randomString(),
+					Verify: &configv1beta1.ChartVerification{Provider: configv1beta1.ChartVerificationProviderGPG},
+				},
+			},
+		}
+		Expect(controllers.ValidateHelmChartVerification(spec)).To(Succeed())
+
+		spec.HelmCharts[0].Verify.Provider = configv1beta1.ChartVerificationProviderGPG
+		Expect(controllers.ValidateHelmChartVerification(spec)).ToNot(Succeed())
+
+		spec.HelmCharts[0].Verify.Provider = configv1beta1.ChartVerificationProviderCosign
+		spec.HelmCharts[1].Verify.Provider = configv1beta1.ChartVerificationProviderCosign
+		Expect(controllers.ValidateHelmChartVerification(spec)).ToNot(Succeed())
+
+		// Charts stored in Flux sources can not be verified
+		spec.HelmCharts[1].RepositoryURL = ""
+		spec.HelmCharts[1].SourceRef = &configv1beta1.ChartSourceRef{
+			Kind: sourcev1.GitRepositoryKind, Namespace: randomString(), Name: randomString(), Path: "charts/app",
+		}
+		Expect(controllers.ValidateHelmChartVerification(spec)).ToNot(Succeed())
+	})
+
+	It("verifyCosignPayload verifies signature and digest", func() {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		Expect(err).To(BeNil())
+		keys := map[string][]byte{
+			"cosign.pub": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}),
+		}
+
+		manifestDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(randomString())))
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry/chart"},`+
+			`"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`,
+			manifestDigest))
+		hash := sha256.Sum256(payload)
+		rawSignature, err := ecdsa.SignASN1(rand.Reader, privateKey, hash[:])
+		Expect(err).To(BeNil())
+		signature := base64.StdEncoding.EncodeToString(rawSignature)
+
+		Expect(controllers.VerifyCosignPayload(keys, manifestDigest, payload, signature)).To(Succeed())
+
+		// Payload signed for a different chart digest
+		otherDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(randomString())))
+		Expect(controllers.VerifyCosignPayload(keys, otherDigest, payload, signature)).ToNot(Succeed())
+
+		// Payload signed with a different key
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		rawSignature, err = ecdsa.SignASN1(rand.Reader, otherKey, hash[:])
+		Expect(err).To(BeNil())
+		Expect(controllers.VerifyCosignPayload(keys, manifestDigest, payload,
+			base64.StdEncoding.EncodeToString(rawSignature))).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,305 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Helm charts stored in a Flux source, for instance in-house charts kept in Git and never published to
+// a helm repository, are referenced setting the HelmChart SourceRef to the GitRepository, OCIRepository
+// or Bucket containing the chart, and SourceRef Path to the chart path within the source artifact.
+// ChartVersion must match the version in the chart Chart.yaml.
+
+// fluxChartSource identifies a helm chart stored in a Flux source
+type fluxChartSource struct {
+	kind      string
+	namespace string
+	name      string
+	path      string
+}
+
+// isFluxChartSource returns true if the chart is stored in the artifact of a Flux source
+// (GitRepository, OCIRepository or Bucket)
+func isFluxChartSource(requestedChart *configv1beta1.HelmChart) bool {
+	return requestedChart.SourceRef != nil && requestedChart.SourceRef.Kind != sourcev1.HelmRepositoryKind
+}
+
+// getFluxChartSource returns the Flux source, and the chart path within it, of a chart stored in the
+// artifact of a Flux source. Nil otherwise.
+func getFluxChartSource(requestedChart *configv1beta1.HelmChart) *fluxChartSource {
+	if !isFluxChartSource(requestedChart) {
+		return nil
+	}
+
+	sourceRef := requestedChart.SourceRef
+	return &fluxChartSource{
+		kind:      sourceRef.Kind,
+		namespace: sourceRef.Namespace,
+		name:      sourceRef.Name,
+		path:      filepath.Clean(strings.Trim(sourceRef.Path, "/")),
+	}
+}
+
+// getObjectReference returns a reference to the Flux source
+func (s *fluxChartSource) getObjectReference() *corev1.ObjectReference {
+	apiVersion := sourcev1b2.GroupVersion.String()
+	if s.kind == sourcev1.GitRepositoryKind {
+		apiVersion = sourcev1.GroupVersion.String()
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       s.kind,
+		Namespace:  s.namespace,
+		Name:       s.name,
+	}
+}
+
+// getFluxChartSourceRevision returns the revision of the Flux source artifact containing the chart.
+// Empty if the source does not exist or has no artifact yet.
+func getFluxChartSourceRevision(ctx context.Context, c client.Client, s *fluxChartSource) (string, error) {
+	source, err := getSource(ctx, c, s.namespace, s.name, s.kind)
+	if err != nil {
+		return "", err
+	}
+	if source == nil {
+		return "", nil
+	}
+
+	artifact := source.(sourcev1.Source).GetArtifact()
+	if artifact == nil {
+		return "", nil
+	}
+	return artifact.Revision, nil
+}
+
+// locateFluxChart extracts the artifact of the Flux source containing the chart and returns the path
+// of the chart within it. tmpDir, where the artifact is extracted, must be removed by the caller.
+func locateFluxChart(ctx context.Context, c client.Client, s *fluxChartSource, logger logr.Logger,
+) (chartPath, tmpDir string, err error) {
+
+	source, err := getSource(ctx, c, s.namespace, s.name, s.kind)
+	if err != nil {
+		return "", "", err
+	}
+	if source == nil {
+		return "", "", fmt.Errorf("source %s %s/%s not found", s.kind, s.namespace, s.name)
+	}
+
+	tmpDir, err = prepareFileSystemWithFluxSource(source.(sourcev1.Source), logger)
+	if err != nil {
+		return "", "", err
+	}
+
+	chartPath = filepath.Join(tmpDir, s.path)
+	if _, statErr := os.Stat(filepath.Join(chartPath, "Chart.yaml")); statErr != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", fmt.Errorf("no helm chart found at %s in source %s %s/%s: %w",
+			s.path, s.kind, s.namespace, s.name, statErr)
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("chart located at %s in source %s %s/%s (revision %s)",
+		s.path, s.kind, s.namespace, s.name, source.(sourcev1.Source).GetArtifact().Revision))
+	return chartPath, tmpDir, nil
+}
+
+// locateChart returns the local path of the requested chart. Charts stored in a Flux source are
+// extracted from the source artifact, in which case tmpDir, if not empty, must be removed by the caller.
+// All other charts are located, and eventually downloaded, by helm.
+func locateChart(ctx context.Context, clusterNamespace string, requestedChart *configv1beta1.HelmChart,
+	chartPathOptions *action.ChartPathOptions, chartName string, settings *cli.EnvSettings, logger logr.Logger,
+) (chartPath, tmpDir string, err error) {
+
+	if s := getFluxChartSource(requestedChart); s != nil {
+		return locateFluxChart(ctx, getManagementClusterClient(), s, logger)
+	}
+
+	cache := helmChartCache
+	key, cacheable := getChartCacheKey(clusterNamespace, requestedChart, chartName)
+	if cache != nil && cacheable {
+		var found bool
+		chartPath, tmpDir, found = cache.get(key)
+		if found {
+			logger.V(logs.LogDebug).Info("chart found in cache")
+			return chartPath, tmpDir, nil
+		}
+	}
+
+	chartPath, err = chartPathOptions.LocateChart(chartName, settings)
+	if err != nil {
+		return "", "", err
+	}
+
+	if cache != nil && cacheable {
+		if cacheErr := cache.add(key, chartPath, logger); cacheErr != nil {
+			// Cache is an optimization only
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to cache chart: %v", cacheErr))
+		}
+	}
+
+	return chartPath, "", nil
+}
+
+// validateFluxChartVersion verifies, for charts stored in a Flux source, that the chart version matches
+// the requested ChartVersion. Version is not used to locate such charts, but it is used to decide whether
+// a release needs to be upgraded.
+func validateFluxChartVersion(requestedChart *configv1beta1.HelmChart, chartRequested *chart.Chart) error {
+	s := getFluxChartSource(requestedChart)
+	if s == nil {
+		return nil
+	}
+
+	if chartRequested.Metadata == nil || chartRequested.Metadata.Version != requestedChart.ChartVersion {
+		version := ""
+		if chartRequested.Metadata != nil {
+			version = chartRequested.Metadata.Version
+		}
+		return fmt.Errorf("chart version %s at %s in source %s %s/%s does not match chartVersion %s",
+			version, s.path, s.kind, s.namespace, s.name, requestedChart.ChartVersion)
+	}
+
+	return nil
+}
+
+// resolveChartSourceRef returns the chart to deploy. If the chart is referenced with a SourceRef, a copy
+// with the URL of the referenced Flux source is returned: the URL of a HelmRepository or the URL of the
+// artifact of a GitRepository/OCIRepository/Bucket. The latter is informational only, such charts are
+// always located using the SourceRef. For a HelmRepository, unless the chart has its own
+// RegistryCredentialsConfig, credentials and TLS settings of the HelmRepository are used.
+func resolveChartSourceRef(ctx context.Context, c client.Client, requestedChart *configv1beta1.HelmChart,
+) (*configv1beta1.HelmChart, error) {
+
+	if requestedChart.SourceRef == nil {
+		return requestedChart, nil
+	}
+
+	if s := getFluxChartSource(requestedChart); s != nil {
+		source, err := getSource(ctx, c, s.namespace, s.name, s.kind)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, fmt.Errorf("source %s %s/%s not found", s.kind, s.namespace, s.name)
+		}
+
+		resolvedChart := requestedChart.DeepCopy()
+		if artifact := source.(sourcev1.Source).GetArtifact(); artifact != nil {
+			resolvedChart.RepositoryURL = artifact.URL
+		}
+		return resolvedChart, nil
+	}
+
+	helmRepository := &sourcev1.HelmRepository{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: requestedChart.SourceRef.Namespace,
+		Name: requestedChart.SourceRef.Name}, helmRepository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HelmRepository %s/%s: %w",
+			requestedChart.SourceRef.Namespace, requestedChart.SourceRef.Name, err)
+	}
+
+	resolvedChart := requestedChart.DeepCopy()
+	resolvedChart.RepositoryURL = helmRepository.Spec.URL
+	if resolvedChart.RegistryCredentialsConfig == nil {
+		resolvedChart.RegistryCredentialsConfig, err = getHelmRepositoryCredentialsConfig(ctx, c, helmRepository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resolvedChart, nil
+}
+
+// getHelmRepositoryCredentialsConfig returns the RegistryCredentialsConfig equivalent to the authentication
+// (SecretRef) and TLS (CertSecretRef) settings of a Flux HelmRepository. Nil if there is none.
+func getHelmRepositoryCredentialsConfig(ctx context.Context, c client.Client, helmRepository *sourcev1.HelmRepository,
+) (*configv1beta1.RegistryCredentialsConfig, error) {
+
+	spec := &helmRepository.Spec
+	if spec.SecretRef == nil && spec.CertSecretRef == nil && !spec.Insecure {
+		return nil, nil
+	}
+
+	credentialsConfig := &configv1beta1.RegistryCredentialsConfig{PlainHTTP: spec.Insecure}
+	if spec.SecretRef != nil {
+		credentialsConfig.CredentialsSecretRef = &corev1.SecretReference{
+			Namespace: helmRepository.Namespace, Name: spec.SecretRef.Name,
+		}
+	}
+
+	if spec.CertSecretRef != nil {
+		// Flux CertSecretRef can contain a CA (ca.crt) and/or a client certificate (tls.crt, tls.key)
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: helmRepository.Namespace, Name: spec.CertSecretRef.Name},
+			secret)
+		if err != nil {
+			return nil, err
+		}
+
+		secretRef := &corev1.SecretReference{Namespace: helmRepository.Namespace, Name: spec.CertSecretRef.Name}
+		const caKey = "ca.crt"
+		if _, ok := secret.Data[caKey]; ok {
+			credentialsConfig.CASecretRef = secretRef
+		}
+		if _, ok := secret.Data[corev1.TLSCertKey]; ok {
+			credentialsConfig.CertSecretRef = secretRef
+		}
+	}
+
+	return credentialsConfig, nil
+}
+
+// validateHelmChartSources verifies each helm chart is referenced either by RepositoryURL or by SourceRef
+func validateHelmChartSources(spec *configv1beta1.Spec) error {
+	for i := range spec.HelmCharts {
+		hc := &spec.HelmCharts[i]
+		if (hc.RepositoryURL == "") == (hc.SourceRef == nil) {
+			return fmt.Errorf("helm chart %s/%s: exactly one of repositoryURL and sourceRef must be set",
+				hc.ReleaseNamespace, hc.ReleaseName)
+		}
+
+		if s := getFluxChartSource(hc); s != nil {
+			if strings.Trim(hc.SourceRef.Path, "/") == "" {
+				return fmt.Errorf("helm chart %s/%s: sourceRef path is required for %s",
+					hc.ReleaseNamespace, hc.ReleaseName, hc.SourceRef.Kind)
+			}
+			if s.path == ".." || strings.HasPrefix(s.path, "../") {
+				return fmt.Errorf("helm chart %s/%s: sourceRef path must be within the source",
+					hc.ReleaseNamespace, hc.ReleaseName)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm charts stored in Flux sources", func() {
+	It("isFluxChartSource detects charts stored in Flux sources", func() {
+		requestedChart := &configv1beta1.HelmChart{
+			SourceRef: &configv1beta1.ChartSourceRef{
+				Kind: sourcev1.GitRepositoryKind, Namespace: randomString(), Name: randomString(), Path: "charts/app",
+			},
+		}
+		Expect(controllers.IsFluxChartSource(requestedChart)).To(BeTrue())
+
+		requestedChart.SourceRef.Kind = sourcev1b2.OCIRepositoryKind
+		Expect(controllers.IsFluxChartSource(requestedChart)).To(BeTrue())
+
+		requestedChart.SourceRef.Kind = sourcev1b2.BucketKind
+		Expect(controllers.IsFluxChartSource(requestedChart)).To(BeTrue())
+
+		// Charts in a HelmRepository are fetched from the helm repository
+		requestedChart.SourceRef.Kind = sourcev1.HelmRepositoryKind
+		Expect(controllers.IsFluxChartSource(requestedChart)).To(BeFalse())
+
+		Expect(controllers.IsFluxChartSource(&configv1beta1.HelmChart{
+			RepositoryURL: "https://kyverno.github.io/kyverno/",
+		})).To(BeFalse())
+	})
+
+	It("getFluxChartSource returns the Flux source and the chart path", func() {
+		namespace := randomString()
+		name := randomString()
+
+		requestedChart := &configv1beta1.HelmChart{
+			SourceRef: &configv1beta1.ChartSourceRef{
+				Kind: sourcev1.GitRepositoryKind, Namespace: namespace, Name: name, Path: "/charts/app/",
+			},
+		}
+		s := controllers.GetFluxChartSource(requestedChart)
+		Expect(s).ToNot(BeNil())
+		ref := controllers.GetFluxChartSourceObjectReference(s)
+		Expect(ref.Kind).To(Equal(sourcev1.GitRepositoryKind))
+		Expect(ref.APIVersion).To(Equal(sourcev1.GroupVersion.String()))
+		Expect(ref.Namespace).To(Equal(namespace))
+		Expect(ref.Name).To(Equal(name))
+
+		requestedChart.SourceRef.Kind = sourcev1b2.OCIRepositoryKind
+		ref = controllers.GetFluxChartSourceObjectReference(controllers.GetFluxChartSource(requestedChart))
+		Expect(ref.Kind).To(Equal(sourcev1b2.OCIRepositoryKind))
+		Expect(ref.APIVersion).To(Equal(sourcev1b2.GroupVersion.String()))
+
+		requestedChart.SourceRef.Kind = sourcev1.HelmRepositoryKind
+		Expect(controllers.GetFluxChartSource(requestedChart)).To(BeNil())
+	})
+
+	It("validateFluxChartVersion verifies chart version matches ChartVersion", func() {
+		requestedChart := &configv1beta1.HelmChart{
+			SourceRef: &configv1beta1.ChartSourceRef{
+				Kind: sourcev1.GitRepositoryKind, Namespace: randomString(), Name: randomString(), Path: "charts/app",
+			},
+			ChartVersion: "1.2.0",
+		}
+
+		chartRequested := &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.0"}}
+		Expect(controllers.ValidateFluxChartVersion(requestedChart, chartRequested)).To(Succeed())
+
+		chartRequested.Metadata.Version = "1.3.0"
+		Expect(controllers.ValidateFluxChartVersion(requestedChart, chartRequested)).ToNot(Succeed())
+
+		// Not verified for charts fetched from a helm repository
+		requestedChart.SourceRef = nil
+		requestedChart.RepositoryURL = "https://kyverno.github.io/kyverno/"
+		Expect(controllers.ValidateFluxChartVersion(requestedChart, chartRequested)).To(Succeed())
+	})
+
+	It("resolveChartSourceRef does not rewrite charts stored in Flux sources to a repository URL", func() {
+		gitRepository := &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gitRepository).Build()
+
+		requestedChart := &configv1beta1.HelmChart{
+			SourceRef: &configv1beta1.ChartSourceRef{
+				Kind: sourcev1.GitRepositoryKind, Namespace: gitRepository.Namespace, Name: gitRepository.Name,
+				Path: "charts/app",
+			},
+		}
+
+		// Source has no artifact yet
+		resolvedChart, err := controllers.ResolveChartSourceRef(context.TODO(), c, requestedChart)
+		Expect(err).To(BeNil())
+		Expect(resolvedChart.RepositoryURL).To(BeEmpty())
+		Expect(controllers.IsFluxChartSource(resolvedChart)).To(BeTrue())
+
+		requestedChart.SourceRef.Name = randomString()
+		_, err = controllers.ResolveChartSourceRef(context.TODO(), c, requestedChart)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("resolveChartSourceRef uses URL and credentials of the referenced HelmRepository", func() {
+		helmRepository := &sourcev1.HelmRepository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: sourcev1.HelmRepositorySpec{
+				URL:           "https://kyverno.github.io/kyverno/",
+				SecretRef:     &meta.LocalObjectReference{Name: randomString()},
+				CertSecretRef: &meta.LocalObjectReference{Name: randomString()},
+			},
+		}
+
+		certSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: helmRepository.Namespace, Name: helmRepository.Spec.CertSecretRef.Name},
+			Data:       map[string][]byte{"ca.crt": []byte(randomString())},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(helmRepository, certSecret).Build()
+
+		requestedChart := &configv1beta1.HelmChart{
+			RepositoryName: "kyverno",
+			ChartName:      "kyverno/kyverno",
+			SourceRef: &configv1beta1.ChartSourceRef{
+				Kind: sourcev1.HelmRepositoryKind, Namespace: helmRepository.Namespace, Name: helmRepository.Name,
+			},
+		}
+
+		resolvedChart, err := controllers.ResolveChartSourceRef(context.TODO(), c, requestedChart)
+		Expect(err).To(BeNil())
+		Expect(resolvedChart.RepositoryURL).To(Equal(helmRepository.Spec.URL))
+		Expect(resolvedChart.RegistryCredentialsConfig).ToNot(BeNil())
+		Expect(resolvedChart.RegistryCredentialsConfig.CredentialsSecretRef).To(Equal(&corev1.SecretReference{
+			Namespace: helmRepository.Namespace, Name: helmRepository.Spec.SecretRef.Name}))
+		Expect(resolvedChart.RegistryCredentialsConfig.CASecretRef).To(Equal(&corev1.SecretReference{
+			Namespace: helmRepository.Namespace, Name: helmRepository.Spec.CertSecretRef.Name}))
+		// Secret has no client certificate
+		Expect(resolvedChart.RegistryCredentialsConfig.CertSecretRef).To(BeNil())
+		// Original chart is not modified
+		Expect(requestedChart.RepositoryURL).To(BeEmpty())
+
+		requestedChart.SourceRef.Name = randomString()
+		_, err = controllers.ResolveChartSourceRef(context.TODO(), c, requestedChart)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("HelmChart requires exactly one of RepositoryURL and SourceRef at admission", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				HelmCharts: []configv1beta1.HelmChart{
+					{
+						RepositoryURL: "https://kyverno.github.io/kyverno/", RepositoryName: "kyverno",
+						ChartName: "kyverno/kyverno", ChartVersion: "v3.0.1",
+						ReleaseName: "kyverno-latest", ReleaseNamespace: "kyverno",
+						SourceRef: &configv1beta1.ChartSourceRef{
+							Kind: sourcev1.HelmRepositoryKind, Namespace: randomString(), Name: randomString(),
+						},
+					},
+				},
+			},
+		}
+
+		err := testEnv.Create(context.TODO(), clusterProfile)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("exactly one of repositoryURL and sourceRef must be set"))
+
+		clusterProfile.Spec.HelmCharts[0].RepositoryURL = ""
+		clusterProfile.Spec.HelmCharts[0].SourceRef = nil
+		err = testEnv.Create(context.TODO(), clusterProfile)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("exactly one of repositoryURL and sourceRef must be set"))
+
+		clusterProfile.Spec.HelmCharts[0].SourceRef = &configv1beta1.ChartSourceRef{
+			Kind: sourcev1.HelmRepositoryKind, Namespace: randomString(), Name: randomString(),
+		}
+		Expect(testEnv.Create(context.TODO(), clusterProfile)).To(Succeed())
+		Expect(testEnv.Delete(context.TODO(), clusterProfile)).To(Succeed())
+	})
+
+	It("validateHelmChartSources requires exactly one of RepositoryURL and SourceRef", func() {
+		spec := &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{RepositoryURL: "https://kyverno.github.io/kyverno/"},
+			},
+		}
+		Expect(controllers.ValidateHelmChartSources(spec)).To(Succeed())
+
+		spec.HelmCharts[0].SourceRef = &configv1beta1.ChartSourceRef{
+			Kind: sourcev1.HelmRepositoryKind, Namespace: randomString(), Name: randomString(),
+		}
+		Expect(controllers.ValidateHelmChartSources(spec)).ToNot(Succeed())
+
+		spec.HelmCharts[0].RepositoryURL = ""
+		Expect(controllers.ValidateHelmChartSources(spec)).To(Succeed())
+
+		// Path is required for GitRepository/OCIRepository
+		spec.HelmCharts[0].SourceRef.Kind = sourcev1.GitRepositoryKind
+		Expect(controllers.ValidateHelmChartSources(spec)).ToNot(Succeed())
+
+		spec.HelmCharts[0].SourceRef.Path = "charts/app"
+		Expect(controllers.ValidateHelmChartSources(spec)).To(Succeed())
+
+		// Path must be within the source
+		spec.HelmCharts[0].SourceRef.Path = "../app"
+		Expect(controllers.ValidateHelmChartSources(spec)).ToNot(Succeed())
+
+		spec.HelmCharts[0].SourceRef = nil
+		Expect(controllers.ValidateHelmChartSources(spec)).ToNot(Succeed())
+	})
+})
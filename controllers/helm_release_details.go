@@ -0,0 +1,152 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+const (
+	// redactedValue replaces, in the computed values reported in ClusterConfiguration, the value
+	// of any key suggesting sensitive content
+	redactedValue = "<redacted>"
+
+	// maxValuesDiffLength is the maximum length of the values diff reported in the
+	// ClusterSummary status. Longer diffs are truncated.
+	maxValuesDiffLength = 4096
+
+	valuesDiffTruncated = "\n... (truncated)\n"
+)
+
+var (
+	// sensitiveKeyFragments are, lowercase, the fragments of a values key which cause its value
+	// to be redacted
+	sensitiveKeyFragments = []string{"password", "passwd", "secret", "token", "credential",
+		"apikey", "api_key", "privatekey", "private_key", "accesskey", "access_key"}
+)
+
+// getReleaseComputedValues returns the final values the release was deployed with, that is
+// the chart default values overridden by the values provided
+func getReleaseComputedValues(results *release.Release) chartutil.Values {
+	if results.Chart == nil {
+		return results.Config
+	}
+
+	values, err := chartutil.CoalesceValues(results.Chart, results.Config)
+	if err != nil {
+		return results.Config
+	}
+	return values
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for i := range sensitiveKeyFragments {
+		if strings.Contains(key, sensitiveKeyFragments[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValues returns a copy of values where the value of any key suggesting sensitive
+// content is replaced by redactedValue
+func redactValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if isSensitiveKey(k) {
+			redacted[k] = redactedValue
+			continue
+		}
+		redacted[k] = redactValue(v)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactValues(v)
+	case chartutil.Values:
+		return redactValues(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i := range v {
+			redacted[i] = redactValue(v[i])
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// setChartReleaseDetails stores, compressed, the release notes and the redacted computed values
+// on the Chart reported in ClusterConfiguration
+func setChartReleaseDetails(chart *configv1beta1.Chart, currentRelease *releaseInfo) error {
+	if currentRelease.Notes != "" {
+		notes, err := configv1beta1.CompressPayload([]byte(currentRelease.Notes))
+		if err != nil {
+			return err
+		}
+		chart.Notes = notes
+	}
+
+	if len(currentRelease.ComputedValues) != 0 {
+		data, err := yaml.Marshal(redactValues(currentRelease.ComputedValues))
+		if err != nil {
+			return err
+		}
+		values, err := configv1beta1.CompressPayload(data)
+		if err != nil {
+			return err
+		}
+		chart.ComputedValues = values
+	}
+
+	return nil
+}
+
+// getValuesDiff returns the unified diff between the redacted values a release was deployed with
+// before and after an upgrade. Returns an empty string if values have not changed.
+func getValuesDiff(previous, current map[string]interface{}) (string, error) {
+	from, err := yaml.Marshal(redactValues(previous))
+	if err != nil {
+		return "", err
+	}
+
+	to, err := yaml.Marshal(redactValues(current))
+	if err != nil {
+		return "", err
+	}
+
+	diff := unifiedDiff("previous values", "current values", string(from), string(to))
+	if len(diff) > maxValuesDiffLength {
+		diff = diff[:maxValuesDiffLength] + valuesDiffTruncated
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm release details", func() {
+	It("redactValues redacts values whose key suggests sensitive content", func() {
+		values := map[string]interface{}{
+			"replicaCount": 2,
+			"auth": map[string]interface{}{
+				"username":     "admin",
+				"rootPassword": "changeme",
+			},
+			"extraEnv": []interface{}{
+				map[string]interface{}{"name": "API_TOKEN", "apiToken": "abc"},
+			},
+		}
+
+		redacted := controllers.RedactValues(values)
+		Expect(redacted["replicaCount"]).To(Equal(2))
+		auth := redacted["auth"].(map[string]interface{})
+		Expect(auth["username"]).To(Equal("admin"))
+		Expect(auth["rootPassword"]).To(Equal("<redacted>"))
+		extraEnv := redacted["extraEnv"].([]interface{})
+		Expect(extraEnv[0].(map[string]interface{})["name"]).To(Equal("API_TOKEN"))
+		Expect(extraEnv[0].(map[string]interface{})["apiToken"]).To(Equal("<redacted>"))
+
+		// Original values are not modified
+		Expect(values["auth"].(map[string]interface{})["rootPassword"]).To(Equal("changeme"))
+	})
+
+	It("getReleaseComputedValues overrides chart default values with provided values", func() {
+		results := &release.Release{
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: randomString()},
+				Values:   map[string]interface{}{"replicaCount": 1, "image": "nginx"},
+			},
+			Config: map[string]interface{}{"replicaCount": 3},
+		}
+
+		values := controllers.GetReleaseComputedValues(results)
+		Expect(values["replicaCount"]).To(Equal(3))
+		Expect(values["image"]).To(Equal("nginx"))
+	})
+
+	It("setChartReleaseDetails stores compressed notes and redacted values", func() {
+		notes := "Thank you for installing " + randomString()
+		chart := &configv1beta1.Chart{ReleaseName: randomString()}
+		Expect(controllers.SetChartReleaseDetails(chart, notes,
+			map[string]interface{}{"password": "changeme", "replicaCount": 3})).To(Succeed())
+
+		Expect(configv1beta1.IsCompressedPayload(chart.Notes)).To(BeTrue())
+		Expect(chart.GetNotes()).To(Equal(notes))
+
+		computedValues, err := chart.GetComputedValues()
+		Expect(err).To(BeNil())
+		values := map[string]interface{}{}
+		Expect(yaml.Unmarshal([]byte(computedValues), &values)).To(Succeed())
+		Expect(values["password"]).To(Equal("<redacted>"))
+		Expect(values["replicaCount"]).To(BeEquivalentTo(3))
+	})
+
+	It("getValuesDiff reports redacted values changed by an upgrade", func() {
+		previous := map[string]interface{}{"replicaCount": 1, "password": "old", "image": "nginx"}
+		current := map[string]interface{}{"replicaCount": 3, "password": "new", "image": "nginx"}
+
+		diff, err := controllers.GetValuesDiff(previous, current)
+		Expect(err).To(BeNil())
+		Expect(diff).To(ContainSubstring("-replicaCount: 1"))
+		Expect(diff).To(ContainSubstring("+replicaCount: 3"))
+		Expect(diff).ToNot(ContainSubstring("old"))
+		Expect(diff).ToNot(ContainSubstring("new"))
+
+		diff, err = controllers.GetValuesDiff(previous, previous)
+		Expect(err).To(BeNil())
+		Expect(diff).To(BeEmpty())
+	})
+})
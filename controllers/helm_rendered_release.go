@@ -0,0 +1,110 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+// validateRenderedRelease renders the helm release, manifest and hooks, and verifies it before it
+// is installed or upgraded in the managed cluster. crds are the CRDs deployed along with the release.
+// Helm post renderers only see the release manifest. Hooks are rendered and deployed unmodified,
+// so tenant isolation and Guardrails are verified on the full release.
+func validateRenderedRelease(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration, chartRequested *chart.Chart,
+	values map[string]interface{}, postRenderer postrender.PostRenderer, isUpgrade bool, crds []chart.CRD) error {
+
+	tenant := getTenant(clusterSummary)
+	if tenant == "" && !hasGuardrails() {
+		return nil
+	}
+
+	rel, err := renderRelease(ctx, requestedChart, actionConfig, chartRequested, values, postRenderer, isUpgrade)
+	if err != nil {
+		return err
+	}
+
+	if tenant != "" {
+		var mapper meta.RESTMapper
+		mapper, err = actionConfig.RESTClientGetter.ToRESTMapper()
+		if err != nil {
+			return err
+		}
+
+		err = validateTenantReleaseHooks(rel, tenant, mapper)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasGuardrails() {
+		return checkReleaseGuardrails(rel, crds)
+	}
+
+	return nil
+}
+
+// renderRelease renders the helm release client side, i.e. without contacting the managed cluster
+// release storage. Capabilities (Kubernetes version and API versions) are the ones of the managed
+// cluster actionConfig points to.
+func renderRelease(ctx context.Context, requestedChart *configv1beta1.HelmChart, actionConfig *action.Configuration,
+	chartRequested *chart.Chart, values map[string]interface{}, postRenderer postrender.PostRenderer, isUpgrade bool,
+) (*release.Release, error) {
+
+	discoveryClient, err := actionConfig.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersions, err := action.GetVersionSet(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// A client only install replaces KubeClient and release storage of its configuration.
+	// So it cannot share actionConfig.
+	renderClient := action.NewInstall(&action.Configuration{Log: debugf})
+	renderClient.ClientOnly = true
+	renderClient.DryRun = true
+	renderClient.ReleaseName = requestedChart.ReleaseName
+	renderClient.Namespace = requestedChart.ReleaseNamespace
+	renderClient.IsUpgrade = isUpgrade
+	renderClient.SkipSchemaValidation = getSkipSchemaValidation(requestedChart.Options)
+	renderClient.PostRenderer = postRenderer
+	renderClient.KubeVersion = &chartutil.KubeVersion{
+		Version: serverVersion.GitVersion,
+		Major:   serverVersion.Major,
+		Minor:   serverVersion.Minor,
+	}
+	renderClient.APIVersions = apiVersions
+
+	return renderClient.RunWithContext(ctx, chartRequested, values)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosset "github.com/projectsveltos/libsveltos/lib/set"
+)
+
+// getHelmValuesPresetValues returns the values of the HelmValuesPreset version referenced.
+// When no version is referenced, the last one is used.
+func getHelmValuesPresetValues(ctx context.Context, c client.Client, ref *configv1beta1.ValuesPresetRef,
+) (string, error) {
+
+	preset := &configv1beta1.HelmValuesPreset{}
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, preset); err != nil {
+		return "", err
+	}
+
+	versions := preset.Spec.Versions
+	if len(versions) == 0 {
+		return "", fmt.Errorf("HelmValuesPreset %s has no version", ref.Name)
+	}
+
+	if ref.Version == "" {
+		return versions[len(versions)-1].Values, nil
+	}
+
+	for i := range versions {
+		if versions[i].Version == ref.Version {
+			return versions[i].Values, nil
+		}
+	}
+
+	return "", fmt.Errorf("HelmValuesPreset %s has no version %s", ref.Name, ref.Version)
+}
+
+// getValuesPresets returns the values of the HelmValuesPresets referenced by a HelmChart,
+// instantiated and merged in order, each overriding the previous ones
+func getValuesPresets(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	mgmtResources map[string]*unstructured.Unstructured, requestedChart *configv1beta1.HelmChart,
+	logger logr.Logger) (chartutil.Values, error) {
+
+	merged := chartutil.Values{}
+	for i := range requestedChart.ValuesPresets {
+		values, err := getHelmValuesPresetValues(ctx, c, &requestedChart.ValuesPresets[i])
+		if err != nil {
+			return nil, err
+		}
+
+		instantiatedValues, err := instantiateClusterSummaryTemplate(ctx, clusterSummary,
+			requestedChart.ChartName, values, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		presetValues, err := chartutil.ReadValues([]byte(instantiatedValues))
+		if err != nil {
+			return nil, fmt.Errorf("HelmValuesPreset %s: %w", requestedChart.ValuesPresets[i].Name, err)
+		}
+
+		merged = chartutil.CoalesceTables(presetValues, merged)
+	}
+
+	return merged, nil
+}
+
+// getValuesPresetsHash returns a string representing the content of the HelmValuesPresets
+// referenced by a HelmChart, so that any change causes the helm release to be upgraded
+func getValuesPresetsHash(ctx context.Context, c client.Client, helmChart *configv1beta1.HelmChart,
+) (string, error) {
+
+	var config string
+	for i := range helmChart.ValuesPresets {
+		values, err := getHelmValuesPresetValues(ctx, c, &helmChart.ValuesPresets[i])
+		if err != nil {
+			return "", err
+		}
+		config += values
+	}
+
+	return config, nil
+}
+
+// getValuesPresetsReferences returns the HelmValuesPresets referenced by a HelmChart
+func getValuesPresetsReferences(helmChart *configv1beta1.HelmChart) *libsveltosset.Set {
+	references := &libsveltosset.Set{}
+	for i := range helmChart.ValuesPresets {
+		references.Insert(&corev1.ObjectReference{
+			APIVersion: configv1beta1.GroupVersion.String(),
+			Kind:       configv1beta1.HelmValuesPresetKind,
+			Name:       helmChart.ValuesPresets[i].Name,
+		})
+	}
+	return references
+}
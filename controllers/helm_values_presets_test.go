@@ -0,0 +1,116 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("HelmValuesPresets", func() {
+	var hardening *configv1beta1.HelmValuesPreset
+	var resources *configv1beta1.HelmValuesPreset
+
+	BeforeEach(func() {
+		hardening = &configv1beta1.HelmValuesPreset{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.HelmValuesPresetSpec{
+				Versions: []configv1beta1.HelmValuesPresetVersion{
+					{Version: "v1", Values: "securityContext:\n  runAsNonRoot: true\n"},
+					{Version: "v2", Values: "securityContext:\n  runAsNonRoot: true\n  readOnlyRootFilesystem: true\n"},
+				},
+			},
+		}
+
+		resources = &configv1beta1.HelmValuesPreset{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.HelmValuesPresetSpec{
+				Versions: []configv1beta1.HelmValuesPresetVersion{
+					{Version: "v1", Values: "resources:\n  limits:\n    memory: 256Mi\nsecurityContext:\n  runAsNonRoot: false\n"},
+				},
+			},
+		}
+	})
+
+	It("getHelmValuesPresetValues returns the requested version or the last one", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hardening).Build()
+
+		values, err := controllers.GetHelmValuesPresetValues(context.TODO(), c,
+			&configv1beta1.ValuesPresetRef{Name: hardening.Name, Version: "v1"})
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal(hardening.Spec.Versions[0].Values))
+
+		values, err = controllers.GetHelmValuesPresetValues(context.TODO(), c,
+			&configv1beta1.ValuesPresetRef{Name: hardening.Name})
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal(hardening.Spec.Versions[1].Values))
+
+		_, err = controllers.GetHelmValuesPresetValues(context.TODO(), c,
+			&configv1beta1.ValuesPresetRef{Name: hardening.Name, Version: "v3"})
+		Expect(err).ToNot(BeNil())
+
+		_, err = controllers.GetHelmValuesPresetValues(context.TODO(), c,
+			&configv1beta1.ValuesPresetRef{Name: randomString()})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("getValuesPresets merges presets in order", func() {
+		cluster := prepareCluster()
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		helmChart := &configv1beta1.HelmChart{
+			ChartName: randomString(),
+			ValuesPresets: []configv1beta1.ValuesPresetRef{
+				{Name: hardening.Name},
+				{Name: resources.Name},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hardening, resources).Build()
+		values, err := controllers.GetValuesPresets(context.TODO(), c, clusterSummary, nil, helmChart,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		securityContext, err := values.Table("securityContext")
+		Expect(err).To(BeNil())
+		// Later presets override previous ones
+		Expect(securityContext["runAsNonRoot"]).To(BeFalse())
+		Expect(securityContext["readOnlyRootFilesystem"]).To(BeTrue())
+
+		memory, err := values.PathValue("resources.limits.memory")
+		Expect(err).To(BeNil())
+		Expect(memory).To(Equal("256Mi"))
+	})
+})
@@ -0,0 +1,322 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/distribution/reference"
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/postrender"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+var (
+	// podSpecPaths contains, for each kind running pods, the path of the pod spec
+	podSpecPaths = map[string][]string{
+		"Pod":                   {"spec"},
+		"Deployment":            {"spec", "template", "spec"},
+		"StatefulSet":           {"spec", "template", "spec"},
+		"DaemonSet":             {"spec", "template", "spec"},
+		"ReplicaSet":            {"spec", "template", "spec"},
+		"ReplicationController": {"spec", "template", "spec"},
+		"Job":                   {"spec", "template", "spec"},
+		"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+
+	containerFields = []string{"initContainers", "containers", "ephemeralContainers"}
+)
+
+type imageDigestsContextKey struct{}
+
+// imageDigestResolver resolves image tags to digests for a ClusterSummary feature.
+// Each image is resolved only once.
+type imageDigestResolver struct {
+	resolver remotes.Resolver
+
+	// key: image as referenced by the rendered resources; value: digest
+	resolved map[string]string
+}
+
+// withImageDigests returns a context resolving the image tags of the resources rendered for
+// a ClusterSummary feature. If ImageDigestResolution is not set, ctx is returned unchanged.
+func withImageDigests(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (context.Context, error) {
+
+	config := clusterSummary.Spec.ClusterProfileSpec.ImageDigestResolution
+	if config == nil {
+		return ctx, nil
+	}
+
+	registryOptions := &registryClientOptions{}
+	if config.CredentialsSecretRef != nil {
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
+			config.CredentialsSecretRef.Namespace)
+		secret, err := getSecret(ctx, c, types.NamespacedName{Namespace: namespace, Name: config.CredentialsSecretRef.Name})
+		if err != nil {
+			return ctx, err
+		}
+
+		dockerConfig, ok := secret.Data[corev1.DockerConfigJsonKey]
+		if !ok {
+			return ctx, &NonRetriableError{Message: fmt.Sprintf("secret %s/%s does not contain %s",
+				namespace, config.CredentialsSecretRef.Name, corev1.DockerConfigJsonKey)}
+		}
+
+		// Credentials are loaded when the resolver is created. File is not needed afterwards.
+		registryOptions.credentialsPath, err = createTemporaryFile("image-digests", dockerConfig)
+		if err != nil {
+			return ctx, err
+		}
+		defer os.Remove(registryOptions.credentialsPath)
+	}
+
+	resolver, err := getOCIResolver(registryOptions)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, imageDigestsContextKey{},
+		&imageDigestResolver{resolver: resolver, resolved: make(map[string]string)}), nil
+}
+
+func getImageDigestResolver(ctx context.Context) *imageDigestResolver {
+	r, ok := ctx.Value(imageDigestsContextKey{}).(*imageDigestResolver)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// resolveImageDigests replaces, in place, the image of each container with the image pinned to
+// the digest its tag currently points to. Nothing is done if ctx does not resolve image tags.
+func resolveImageDigests(ctx context.Context, resources []*unstructured.Unstructured) error {
+	r := getImageDigestResolver(ctx)
+	if r == nil {
+		return nil
+	}
+
+	for i := range resources {
+		if err := r.resolveResource(ctx, resources[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *imageDigestResolver) resolveResource(ctx context.Context, resource *unstructured.Unstructured) error {
+	podSpecPath, ok := podSpecPaths[resource.GetKind()]
+	if !ok {
+		return nil
+	}
+
+	for _, field := range containerFields {
+		path := append(append([]string{}, podSpecPath...), field)
+		containers, found, err := unstructured.NestedSlice(resource.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+
+		for i := range containers {
+			container, ok := containers[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, ok := container["image"].(string)
+			if !ok || image == "" {
+				continue
+			}
+
+			var pinned string
+			pinned, err = r.resolveImage(ctx, image)
+			if err != nil {
+				return fmt.Errorf("%s %s/%s: %w", resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+			}
+			container["image"] = pinned
+		}
+
+		if err = unstructured.SetNestedSlice(resource.Object, containers, path...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveImage returns image pinned to the digest its tag points to. Images already
+// referenced by digest are returned unchanged.
+func (r *imageDigestResolver) resolveImage(ctx context.Context, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", &NonRetriableError{Message: fmt.Sprintf("invalid image %q: %v", image, err)}
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return image, nil
+	}
+
+	if digest, ok := r.resolved[image]; ok {
+		return image + "@" + digest, nil
+	}
+
+	// Image is resolved from its registry mirror, if any. Image is not modified: the mirror is
+	// expected to be configured in the container runtime of the managed cluster nodes.
+	ref := getMirroredURL(reference.TagNameOnly(named).String())
+	_, desc, err := r.resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image %s: %w", ref, err)
+	}
+	r.resolved[image] = desc.Digest.String()
+
+	return image + "@" + r.resolved[image], nil
+}
+
+// recordResolvedImages reports, in the ClusterSummary status, the digests the images deployed by
+// featureID were resolved to. Images of featureID not deployed anymore are removed, as are all
+// images of featureID once ImageDigestResolution is unset.
+func recordResolvedImages(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) {
+
+	resolved := make(map[string]string)
+	if r := getImageDigestResolver(ctx); r != nil {
+		resolved = r.resolved
+	} else if !hasResolvedImages(clusterSummary, featureID) {
+		return
+	}
+
+	c := getManagementClusterClient()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			return err
+		}
+
+		resolvedImages := make([]configv1beta1.ResolvedImage, 0, len(resolved))
+		for i := range currentClusterSummary.Status.ResolvedImages {
+			if currentClusterSummary.Status.ResolvedImages[i].FeatureID != featureID {
+				resolvedImages = append(resolvedImages, currentClusterSummary.Status.ResolvedImages[i])
+			}
+		}
+		for image, digest := range resolved {
+			resolvedImages = append(resolvedImages,
+				configv1beta1.ResolvedImage{FeatureID: featureID, Image: image, Digest: digest})
+		}
+		sort.Slice(resolvedImages, func(i, j int) bool {
+			if resolvedImages[i].FeatureID != resolvedImages[j].FeatureID {
+				return resolvedImages[i].FeatureID < resolvedImages[j].FeatureID
+			}
+			return resolvedImages[i].Image < resolvedImages[j].Image
+		})
+
+		currentClusterSummary.Status.ResolvedImages = resolvedImages
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+	if err != nil {
+		// Status is informational only
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to report resolved images: %v", err))
+	}
+}
+
+func hasResolvedImages(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) bool {
+	for i := range clusterSummary.Status.ResolvedImages {
+		if clusterSummary.Status.ResolvedImages[i].FeatureID == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// imageDigestsPostRenderer is a helm post renderer pinning the images of the rendered
+// resources to digests. It runs after next, if set.
+type imageDigestsPostRenderer struct {
+	ctx  context.Context //nolint: containedctx // helm post renderers are not passed a context
+	next postrender.PostRenderer
+}
+
+func (p *imageDigestsPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if p.next != nil {
+		var err error
+		renderedManifests, err = p.next.Run(renderedManifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elements, err := customSplit(renderedManifests.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &bytes.Buffer{}
+	for i := range elements {
+		if strings.TrimSpace(elements[i]) == "" {
+			continue
+		}
+
+		var policy *unstructured.Unstructured
+		policy, err = utils.GetUnstructured([]byte(elements[i]))
+		if err != nil {
+			return nil, err
+		}
+		if policy == nil {
+			continue
+		}
+
+		err = resolveImageDigests(p.ctx, []*unstructured.Unstructured{policy})
+		if err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		data, err = yaml.Marshal(policy.Object)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString("---\n")
+		result.Write(data)
+	}
+
+	return result, nil
+}
+
+// getImageDigestsPostRenderer returns a post renderer pinning images to digests after next.
+// If ctx does not resolve image tags, next is returned.
+func getImageDigestsPostRenderer(ctx context.Context, next postrender.PostRenderer) postrender.PostRenderer {
+	if getImageDigestResolver(ctx) == nil {
+		return next
+	}
+
+	return &imageDigestsPostRenderer{ctx: ctx, next: next}
+}
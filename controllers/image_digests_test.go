@@ -0,0 +1,92 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	nginxDigest   = "sha256:0c86dddac19f2ce4fd716ac58c0fd87bf69bfd4edabfd6971fb885bafd12a00b"
+	busyboxDigest = "sha256:9ae97d36d26566ff84e8893c64a6dc4fe8ca6d1144bf5b87b2b85a32def253c7"
+
+	deploymentWithImages = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: default
+spec:
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      initContainers:
+      - name: init
+        image: busybox@` + busyboxDigest + `
+      containers:
+      - name: nginx
+        image: nginx:1.27
+      - name: sidecar
+        image: nginx:1.27`
+)
+
+var _ = Describe("Image digests", func() {
+	It("resolveImageDigests pins container images to digests", func() {
+		deployment, err := utils.GetUnstructured([]byte(deploymentWithImages))
+		Expect(err).To(BeNil())
+
+		ctx := controllers.WithResolvedImageDigests(context.TODO(), map[string]string{"nginx:1.27": nginxDigest})
+		Expect(controllers.ResolveImageDigests(ctx, []*unstructured.Unstructured{deployment})).To(Succeed())
+
+		containers, found, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(containers).To(HaveLen(2))
+		for i := range containers {
+			Expect(containers[i].(map[string]interface{})["image"]).To(Equal("nginx:1.27@" + nginxDigest))
+		}
+
+		// Images already pinned to a digest are left unchanged
+		initContainers, found, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "initContainers")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(initContainers[0].(map[string]interface{})["image"]).To(Equal("busybox@" + busyboxDigest))
+	})
+
+	It("resolveImageDigests leaves resources unchanged when image digest resolution is not set", func() {
+		deployment, err := utils.GetUnstructured([]byte(deploymentWithImages))
+		Expect(err).To(BeNil())
+
+		Expect(controllers.ResolveImageDigests(context.TODO(), []*unstructured.Unstructured{deployment})).To(Succeed())
+
+		containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+		Expect(err).To(BeNil())
+		Expect(containers[0].(map[string]interface{})["image"]).To(Equal("nginx:1.27"))
+	})
+})
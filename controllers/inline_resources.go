@@ -0,0 +1,97 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// maximum size of all InlineResources contents of a profile
+	maxInlineResourcesSize = 64 * 1024
+)
+
+// deployInlineResources deploys in the managed cluster the resources contained in the InlineResources
+func deployInlineResources(ctx context.Context, destConfig *rest.Config, destClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) ([]configv1beta1.ResourceReport, error) {
+
+	inlineResources := clusterSummary.Spec.ClusterProfileSpec.InlineResources
+
+	resources := make([]*unstructured.Unstructured, 0)
+	for i := range inlineResources {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("collecting inline resource %s", inlineResources[i].Name))
+
+		data := map[string]string{inlineResources[i].Name: inlineResources[i].Content}
+		tmpResources, err := collectContent(ctx, clusterSummary, mgmtResources, data,
+			inlineResources[i].Template, logger)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, tmpResources...)
+	}
+
+	// Inline resources are tracked as coming from the ClusterSummary itself
+	ref := &corev1.ObjectReference{
+		Kind:      configv1beta1.ClusterSummaryKind,
+		Namespace: clusterSummary.Namespace,
+		Name:      clusterSummary.Name,
+	}
+	return deployUnstructured(ctx, false, destConfig, destClient, resources, ref,
+		configv1beta1.FeatureResources, clusterSummary, mgmtResources, nil, logger)
+}
+
+// validateInlineResources verifies InlineResources do not exceed the maximum size and, unless
+// expressed as templates (which can only be validated once instantiated), contain valid resources.
+func validateInlineResources(spec *configv1beta1.Spec) error {
+	size := 0
+	for i := range spec.InlineResources {
+		inlineResource := &spec.InlineResources[i]
+		size += len(inlineResource.Content)
+
+		if inlineResource.Template {
+			continue
+		}
+
+		resources, err := getUnstructured([]byte(inlineResource.Content), logr.Discard())
+		if err != nil {
+			return fmt.Errorf("inline resource %s: %w", inlineResource.Name, err)
+		}
+		for j := range resources {
+			if resources[j].GetKind() == "" || resources[j].GetAPIVersion() == "" || resources[j].GetName() == "" {
+				return fmt.Errorf("inline resource %s: apiVersion, kind and metadata.name must be set",
+					inlineResource.Name)
+			}
+		}
+	}
+
+	if size > maxInlineResourcesSize {
+		return fmt.Errorf("inline resources size %d exceeds maximum size %d", size, maxInlineResourcesSize)
+	}
+
+	return nil
+}
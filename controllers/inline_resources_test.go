@@ -0,0 +1,95 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Inline resources", func() {
+	const configMapTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+data:
+  key: value`
+
+	It("validateInlineResources accepts valid resources", func() {
+		spec := &configv1beta1.Spec{
+			InlineResources: []configv1beta1.InlineResource{
+				{Name: randomString(), Content: fmt.Sprintf(configMapTemplate, randomString())},
+				{
+					Name: randomString(),
+					Content: fmt.Sprintf(configMapTemplate, randomString()) + "\n---\n" +
+						fmt.Sprintf(configMapTemplate, randomString()),
+				},
+			},
+		}
+		Expect(controllers.ValidateInlineResources(spec)).To(Succeed())
+	})
+
+	It("validateInlineResources rejects invalid resources", func() {
+		spec := &configv1beta1.Spec{
+			InlineResources: []configv1beta1.InlineResource{
+				{Name: randomString(), Content: "apiVersion: v1\nkind: ConfigMap\nmetadata: [invalid"},
+			},
+		}
+		Expect(controllers.ValidateInlineResources(spec)).ToNot(Succeed())
+
+		// kind is missing
+		spec.InlineResources[0].Content = "apiVersion: v1\nmetadata:\n  name: " + randomString()
+		Expect(controllers.ValidateInlineResources(spec)).ToNot(Succeed())
+
+		// name is missing
+		spec.InlineResources[0].Content = "apiVersion: v1\nkind: ConfigMap"
+		Expect(controllers.ValidateInlineResources(spec)).ToNot(Succeed())
+	})
+
+	It("validateInlineResources does not validate templates", func() {
+		spec := &configv1beta1.Spec{
+			InlineResources: []configv1beta1.InlineResource{
+				{
+					Name:     randomString(),
+					Content:  fmt.Sprintf(configMapTemplate, "{{ .Cluster.metadata.name }}"),
+					Template: true,
+				},
+			},
+		}
+		Expect(controllers.ValidateInlineResources(spec)).To(Succeed())
+	})
+
+	It("validateInlineResources rejects resources exceeding maximum size", func() {
+		content := fmt.Sprintf(configMapTemplate, randomString()) + "\n  big: " + strings.Repeat("a", 16*1024)
+		spec := &configv1beta1.Spec{InlineResources: make([]configv1beta1.InlineResource, 0)}
+		for i := 0; i < 4; i++ {
+			spec.InlineResources = append(spec.InlineResources,
+				configv1beta1.InlineResource{Name: randomString(), Content: content})
+		}
+		Expect(controllers.ValidateInlineResources(spec)).ToNot(Succeed())
+
+		spec.InlineResources = spec.InlineResources[:1]
+		Expect(controllers.ValidateInlineResources(spec)).To(Succeed())
+	})
+})
@@ -0,0 +1,184 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// InventoryLabel is set on each resource deployed by the Resources and Kustomize features to
+	// a short hash of the owning ClusterProfile/Profile, feature and ClusterSummary revision.
+	// Agents and garbage collectors can match resources to their owner with a single label
+	// selector instead of inspecting owner annotations.
+	InventoryLabel = "projectsveltos.io/inventory"
+
+	// inventoryHashLength is the number of hex characters of the InventoryLabel value
+	inventoryHashLength = 16
+)
+
+// getInventoryLabelValue returns the InventoryLabel value for resources deployed by featureID
+// of a ClusterSummary. Profile name must be the one used in owner references.
+func getInventoryLabelValue(clusterSummary *configv1beta1.ClusterSummary, profile client.Object,
+	featureID configv1beta1.FeatureID) string {
+
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s/%s:%s:%d", profile.GetObjectKind().GroupVersionKind().Kind,
+		profile.GetName(), featureID, clusterSummary.Generation)))
+	return hex.EncodeToString(h[:])[:inventoryHashLength]
+}
+
+// RelabelInventory sets the InventoryLabel on resources deployed in the managed clusters before the label
+// was introduced, so they can be matched to their owner without being redeployed.
+// Resources already labeled are not updated.
+func RelabelInventory(ctx context.Context, c client.Client, logger logr.Logger) {
+	// Nothing is ever updated in managed clusters in observe-only mode
+	if observeOnly {
+		logger.V(logs.LogInfo).Info("observe-only mode. Deployed resources are not relabeled.")
+		return
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaries); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return
+	}
+
+	for i := range clusterSummaries.Items {
+		clusterSummary := &clusterSummaries.Items[i]
+		if !clusterSummary.DeletionTimestamp.IsZero() || isDryRunSync(clusterSummary) {
+			continue
+		}
+
+		l := logger.WithValues("clustersummary", fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name))
+		relabeled, err := relabelClusterSummaryResources(ctx, c, clusterSummary, l)
+		if err != nil {
+			l.V(logs.LogInfo).Info(fmt.Sprintf("failed to relabel deployed resources: %v", err))
+			continue
+		}
+		l.V(logs.LogDebug).Info(fmt.Sprintf("relabeled %d resources", relabeled))
+	}
+}
+
+// relabelClusterSummaryResources sets the InventoryLabel on the resources deployed in the managed cluster by
+// the Resources and Kustomize features of a ClusterSummary. Returns the number of resources relabeled.
+func relabelClusterSummaryResources(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) (int, error) {
+
+	remoteConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return 0, err
+	}
+
+	profile, _, err := configv1beta1.GetProfileOwnerAndTier(ctx, c, clusterSummary)
+	if err != nil {
+		return 0, err
+	}
+	if profile.GetObjectKind().GroupVersionKind().Kind == configv1beta1.ProfileKind {
+		profile.SetName(profileNameToOwnerReferenceName(profile))
+	}
+
+	dc, err := discovery.NewDiscoveryClientForConfig(remoteConfig)
+	if err != nil {
+		return 0, err
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(dc)
+	if err != nil {
+		return 0, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	d, err := dynamic.NewForConfig(remoteConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	relabeled := 0
+	for _, featureID := range []configv1beta1.FeatureID{configv1beta1.FeatureResources, configv1beta1.FeatureKustomize} {
+		value := getInventoryLabelValue(clusterSummary, profile, featureID)
+		listOptions := metav1.ListOptions{
+			LabelSelector: labels.Set{reasonLabel: string(featureID)}.String(),
+		}
+
+		deployedGVKs := getDeployedGroupVersionKinds(clusterSummary, featureID)
+		for i := range deployedGVKs {
+			mapping, err := mapper.RESTMapping(deployedGVKs[i].GroupKind(), deployedGVKs[i].Version)
+			if err != nil {
+				if meta.IsNoMatchError(err) {
+					continue
+				}
+				return relabeled, err
+			}
+
+			resourceID := schema.GroupVersionResource{
+				Group:    deployedGVKs[i].Group,
+				Version:  deployedGVKs[i].Version,
+				Resource: mapping.Resource.Resource,
+			}
+
+			list, err := d.Resource(resourceID).List(ctx, listOptions)
+			if err != nil {
+				return relabeled, err
+			}
+
+			for j := range list.Items {
+				r := &list.Items[j]
+				if r.GetLabels()[InventoryLabel] == value || !deployer.IsOwnerReference(r, profile) {
+					continue
+				}
+
+				patch, err := json.Marshal(map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]string{InventoryLabel: value},
+					},
+				})
+				if err != nil {
+					return relabeled, err
+				}
+
+				logger.V(logs.LogVerbose).Info(fmt.Sprintf("relabeling %s %s/%s", r.GetKind(), r.GetNamespace(), r.GetName()))
+				_, err = d.Resource(resourceID).Namespace(r.GetNamespace()).Patch(ctx, r.GetName(),
+					types.MergePatchType, patch, metav1.PatchOptions{})
+				if err != nil {
+					return relabeled, fmt.Errorf("failed to relabel %s %s/%s: %w", r.GetKind(),
+						r.GetNamespace(), r.GetName(), err)
+				}
+				relabeled++
+			}
+		}
+	}
+
+	return relabeled, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("InventoryLabels", func() {
+	It("getInventoryLabelValue returns a short hash of profile, feature and revision", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  randomString(),
+				Name:       randomString(),
+				Generation: 3,
+			},
+		}
+
+		value := controllers.GetInventoryLabelValue(clusterSummary, clusterProfile, configv1beta1.FeatureResources)
+		Expect(value).To(HaveLen(16))
+		Expect(validation.IsValidLabelValue(value)).To(BeEmpty())
+		Expect(controllers.GetInventoryLabelValue(clusterSummary, clusterProfile,
+			configv1beta1.FeatureResources)).To(Equal(value))
+
+		Expect(controllers.GetInventoryLabelValue(clusterSummary, clusterProfile,
+			configv1beta1.FeatureKustomize)).ToNot(Equal(value))
+
+		otherProfile := clusterProfile.DeepCopy()
+		otherProfile.Name = randomString()
+		Expect(controllers.GetInventoryLabelValue(clusterSummary, otherProfile,
+			configv1beta1.FeatureResources)).ToNot(Equal(value))
+
+		clusterSummary.Generation++
+		Expect(controllers.GetInventoryLabelValue(clusterSummary, clusterProfile,
+			configv1beta1.FeatureResources)).ToNot(Equal(value))
+	})
+})
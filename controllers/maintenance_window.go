@@ -0,0 +1,243 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// MaintenanceWindowAnnotation can be set on a Cluster/SveltosCluster to restrict when
+// add-ons are deployed to it. Outside of the window ClusterSummaries for the cluster are not
+// reconciled, changes are applied once the window opens. ClusterSummaries report when the next window
+// opens in the OutsideMaintenanceWindow condition.
+// Format is "<days> <HH:MM>-<HH:MM> [<time zone>]", for instance "Sat 02:00-06:00 Europe/Zurich".
+// Days can be a single day (Sat), a range (Mon-Fri), a list (Sat,Sun) or * for every day.
+// Time zone defaults to UTC. Multiple windows can be separated by ';'.
+// A window whose end is before its start spans midnight.
+const MaintenanceWindowAnnotation = "projectsveltos.io/maintenance-window"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+type maintenanceWindow struct {
+	days        map[time.Weekday]bool
+	startHour   int
+	startMinute int
+	// duration of the window
+	duration time.Duration
+	location *time.Location
+}
+
+// parseMaintenanceWindows parses the value of MaintenanceWindowAnnotation
+func parseMaintenanceWindows(value string) ([]maintenanceWindow, error) {
+	result := make([]maintenanceWindow, 0)
+	for _, w := range strings.Split(value, ";") {
+		if strings.TrimSpace(w) == "" {
+			continue
+		}
+		window, err := parseMaintenanceWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *window)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no maintenance window defined")
+	}
+
+	return result, nil
+}
+
+func parseMaintenanceWindow(value string) (*maintenanceWindow, error) {
+	fields := strings.Fields(value)
+	const minFields, maxFields = 2, 3
+	if len(fields) < minFields || len(fields) > maxFields {
+		return nil, fmt.Errorf("invalid maintenance window %q: expected \"<days> <HH:MM>-<HH:MM> [<time zone>]\"", value)
+	}
+
+	days, err := parseWeekdays(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	window := &maintenanceWindow{days: days, location: time.UTC}
+	if len(fields) == maxFields {
+		window.location, err = time.LoadLocation(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", fields[2], err)
+		}
+	}
+
+	times := strings.Split(fields[1], "-")
+	const timesLen = 2
+	if len(times) != timesLen {
+		return nil, fmt.Errorf("invalid time range %q", fields[1])
+	}
+	start, err := time.Parse("15:04", times[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", times[0], err)
+	}
+	end, err := time.Parse("15:04", times[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time %q: %w", times[1], err)
+	}
+
+	window.startHour, window.startMinute = start.Hour(), start.Minute()
+	window.duration = end.Sub(start)
+	if window.duration <= 0 {
+		// window spans midnight
+		window.duration += 24 * time.Hour
+	}
+
+	return window, nil
+}
+
+func parseWeekdays(value string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	if value == "*" {
+		for _, d := range weekdays {
+			days[d] = true
+		}
+		return days, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		bounds := strings.Split(strings.ToLower(entry), "-")
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) > 1 {
+			last, ok = weekdays[bounds[1]]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+		const daysInWeek = 7
+		for d := first; ; d = (d + 1) % daysInWeek {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	return days, nil
+}
+
+// timeUntilOpen returns zero if now is within the window. Otherwise returns how long
+// until the window opens next.
+func (w *maintenanceWindow) timeUntilOpen(now time.Time) time.Duration {
+	local := now.In(w.location)
+
+	var next time.Duration
+	// Start from the day before to consider windows spanning midnight
+	const firstDay, lastDay = -1, 7
+	for offset := firstDay; offset <= lastDay; offset++ {
+		start := time.Date(local.Year(), local.Month(), local.Day()+offset, w.startHour, w.startMinute, 0, 0,
+			w.location)
+		if !w.days[start.Weekday()] {
+			continue
+		}
+		end := start.Add(w.duration)
+		if !now.Before(start) && now.Before(end) {
+			return 0
+		}
+		if start.After(now) && (next == 0 || start.Sub(now) < next) {
+			next = start.Sub(now)
+		}
+	}
+
+	return next
+}
+
+// getTimeUntilMaintenanceWindow returns zero if add-ons can currently be deployed to the
+// ClusterSummary's cluster. Otherwise returns how long until the cluster maintenance window opens.
+func getTimeUntilMaintenanceWindow(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	now time.Time) (time.Duration, error) {
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	value, ok := cluster.GetAnnotations()[MaintenanceWindowAnnotation]
+	if !ok {
+		return 0, nil
+	}
+
+	windows, err := parseMaintenanceWindows(value)
+	if err != nil {
+		return 0, err
+	}
+
+	var next time.Duration
+	for i := range windows {
+		wait := windows[i].timeUntilOpen(now)
+		if wait == 0 {
+			return 0, nil
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+
+	return next, nil
+}
+
+// setMaintenanceWindowCondition reports in the OutsideMaintenanceWindowCondition whether deployments
+// are held back. waitFor is how long, from now, until the next maintenance window opens. Zero means
+// deployments are allowed.
+func setMaintenanceWindowCondition(clusterSummary *configv1beta1.ClusterSummary, waitFor time.Duration,
+	now time.Time) {
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.OutsideMaintenanceWindowCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.DeploymentAllowedReason,
+		Message:            "deployments are allowed",
+		ObservedGeneration: clusterSummary.Generation,
+	}
+
+	if waitFor > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.WaitingForMaintenanceWindowReason
+		condition.Message = fmt.Sprintf("outside cluster maintenance window. Deployments resume when next window opens at %s",
+			now.Add(waitFor).UTC().Format(time.RFC3339))
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, condition)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Maintenance window", func() {
+	var cluster *clusterv1.Cluster
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+	})
+
+	getWait := func(window string, now time.Time) (time.Duration, error) {
+		if window != "" {
+			cluster.Annotations = map[string]string{controllers.MaintenanceWindowAnnotation: window}
+		}
+		initObjects := []client.Object{cluster, clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		return controllers.GetTimeUntilMaintenanceWindow(context.TODO(), c, clusterSummary, now)
+	}
+
+	It("getTimeUntilMaintenanceWindow returns zero when cluster has no window", func() {
+		wait, err := getWait("", time.Now())
+		Expect(err).To(BeNil())
+		Expect(wait).To(BeZero())
+	})
+
+	It("getTimeUntilMaintenanceWindow evaluates the cluster window", func() {
+		zurich, err := time.LoadLocation("Europe/Zurich")
+		Expect(err).To(BeNil())
+
+		// Saturday 2024-06-01 03:00 Europe/Zurich is within the window
+		wait, err := getWait("Sat 02:00-06:00 Europe/Zurich", time.Date(2024, 6, 1, 3, 0, 0, 0, zurich))
+		Expect(err).To(BeNil())
+		Expect(wait).To(BeZero())
+
+		// Friday 2024-05-31 23:00 Europe/Zurich, window opens in 3 hours
+		wait, err = getWait("Sat 02:00-06:00 Europe/Zurich", time.Date(2024, 5, 31, 23, 0, 0, 0, zurich))
+		Expect(err).To(BeNil())
+		Expect(wait).To(Equal(3 * time.Hour))
+
+		// Saturday 2024-06-01 07:00 Europe/Zurich, next window is next Saturday
+		wait, err = getWait("Sat 02:00-06:00 Europe/Zurich", time.Date(2024, 6, 1, 7, 0, 0, 0, zurich))
+		Expect(err).To(BeNil())
+		Expect(wait).To(Equal(7*24*time.Hour - 5*time.Hour))
+	})
+
+	It("getTimeUntilMaintenanceWindow supports day ranges, multiple windows and midnight", func() {
+		// Tuesday 2024-06-04 01:00 UTC, within window started Monday at 22:00
+		wait, err := getWait("Mon-Fri 22:00-02:00", time.Date(2024, 6, 4, 1, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(wait).To(BeZero())
+
+		// Tuesday 2024-06-04 12:00 UTC
+		wait, err = getWait("Sat,Sun 00:00-23:59; Tue 13:00-14:00 UTC", time.Date(2024, 6, 4, 12, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(wait).To(Equal(time.Hour))
+
+		_, err = getWait("Someday 02:00-06:00", time.Now())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("setMaintenanceWindowCondition reports when next maintenance window opens", func() {
+		now := time.Date(2024, 6, 4, 12, 0, 0, 0, time.UTC)
+		controllers.SetMaintenanceWindowCondition(clusterSummary, time.Hour, now)
+
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1beta1.OutsideMaintenanceWindowCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.WaitingForMaintenanceWindowReason))
+		Expect(condition.Message).To(ContainSubstring("2024-06-04T13:00:00Z"))
+
+		controllers.SetMaintenanceWindowCondition(clusterSummary, 0, now)
+		condition = meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1beta1.OutsideMaintenanceWindowCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.DeploymentAllowedReason))
+	})
+})
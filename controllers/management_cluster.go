@@ -22,6 +22,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -29,6 +30,7 @@ var (
 	managementClusterClient client.Client
 	managementClusterConfig *rest.Config
 	driftdetectionConfigMap string
+	eventRecorder           record.EventRecorder
 )
 
 func SetManagementClusterAccess(c client.Client, config *rest.Config) {
@@ -40,6 +42,11 @@ func SetDriftdetectionConfigMap(name string) {
 	driftdetectionConfigMap = name
 }
 
+// SetEventRecorder sets the recorder used to emit events on management cluster resources
+func SetEventRecorder(recorder record.EventRecorder) {
+	eventRecorder = recorder
+}
+
 func getManagementClusterConfig() *rest.Config {
 	return managementClusterConfig
 }
@@ -48,6 +55,10 @@ func getManagementClusterClient() client.Client {
 	return managementClusterClient
 }
 
+func getEventRecorder() record.EventRecorder {
+	return eventRecorder
+}
+
 func getDriftDetectionConfigMap() string {
 	return driftdetectionConfigMap
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// AcknowledgeMatchCountAnnotation, set on a ClusterProfile/Profile with MatchExpansionGuard to
+// the number of matching clusters, acknowledges an increase of matching clusters above MaxIncrease
+const AcknowledgeMatchCountAnnotation = "projectsveltos.io/acknowledge-match-count"
+
+func isMatchCountAcknowledged(annotations map[string]string, matchCount int) bool {
+	return annotations[AcknowledgeMatchCountAnnotation] == strconv.Itoa(matchCount)
+}
+
+// guardMatchExpansion returns the clusters the ClusterProfile/Profile is allowed to match.
+// When MatchExpansionGuard is set and more than MaxIncrease clusters, not currently matching,
+// now match, only currently matching clusters are returned until the increase is acknowledged.
+// MatchExpansionPendingCondition is updated accordingly and removed when no guard is set.
+func guardMatchExpansion(profileScope *scope.ProfileScope, matchingClusters []corev1.ObjectReference,
+	logger logr.Logger) []corev1.ObjectReference {
+
+	guard := profileScope.GetSpec().MatchExpansionGuard
+	if guard == nil {
+		meta.RemoveStatusCondition(&profileScope.GetStatus().Conditions, configv1beta1.MatchExpansionPendingCondition)
+		return matchingClusters
+	}
+
+	currentClusters := getCurrentClusterSet(profileScope.GetStatus().MatchingClusterRefs)
+
+	allowed := make([]corev1.ObjectReference, 0, len(matchingClusters))
+	added := 0
+	for i := range matchingClusters {
+		if currentClusters.Has(&matchingClusters[i]) {
+			allowed = append(allowed, matchingClusters[i])
+		} else {
+			added++
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.MatchExpansionPendingCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.MatchExpansionAllowedReason,
+		Message:            "all matching clusters are managed",
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+
+	if added <= int(guard.MaxIncrease) ||
+		isMatchCountAcknowledged(profileScope.Profile.GetAnnotations(), len(matchingClusters)) {
+
+		meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+		return matchingClusters
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("%d new matching clusters exceed maxIncrease %d",
+		added, guard.MaxIncrease))
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = configv1beta1.MatchExpansionExceededReason
+	condition.Message = fmt.Sprintf("matching clusters would go from %d to %d, more than maxIncrease (%d) new clusters: "+
+		"annotate with %s=%d to proceed", currentClusters.Len(), len(matchingClusters), guard.MaxIncrease,
+		AcknowledgeMatchCountAnnotation, len(matchingClusters))
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+
+	return allowed
+}
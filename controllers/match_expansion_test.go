@@ -0,0 +1,104 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Match expansion guard", func() {
+	getClusterRefs := func(count int) []corev1.ObjectReference {
+		refs := make([]corev1.ObjectReference, count)
+		for i := range refs {
+			refs[i] = corev1.ObjectReference{
+				Namespace:  "default",
+				Name:       fmt.Sprintf("cluster-%d", i),
+				Kind:       libsveltosv1beta1.SveltosClusterKind,
+				APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			}
+		}
+		return refs
+	}
+
+	It("guardMatchExpansion holds back new clusters until the increase is acknowledged", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				MatchExpansionGuard: &configv1beta1.MatchExpansionGuard{MaxIncrease: 10},
+			},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: getClusterRefs(5),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Increase within MaxIncrease
+		matching := controllers.GuardMatchExpansion(profileScope, getClusterRefs(15), logger)
+		Expect(matching).To(HaveLen(15))
+		Expect(meta.IsStatusConditionFalse(profileScope.GetStatus().Conditions,
+			configv1beta1.MatchExpansionPendingCondition)).To(BeTrue())
+
+		// Increase above MaxIncrease: only currently matching clusters are kept
+		matching = controllers.GuardMatchExpansion(profileScope, getClusterRefs(500), logger)
+		Expect(matching).To(Equal(getClusterRefs(5)))
+		condition := meta.FindStatusCondition(profileScope.GetStatus().Conditions,
+			configv1beta1.MatchExpansionPendingCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.MatchExpansionExceededReason))
+
+		// Acknowledging a different count does not resume the rollout
+		clusterProfile.Annotations = map[string]string{controllers.AcknowledgeMatchCountAnnotation: "499"}
+		Expect(controllers.GuardMatchExpansion(profileScope, getClusterRefs(500), logger)).To(HaveLen(5))
+
+		clusterProfile.Annotations = map[string]string{controllers.AcknowledgeMatchCountAnnotation: "500"}
+		Expect(controllers.GuardMatchExpansion(profileScope, getClusterRefs(500), logger)).To(HaveLen(500))
+		Expect(meta.IsStatusConditionFalse(profileScope.GetStatus().Conditions,
+			configv1beta1.MatchExpansionPendingCondition)).To(BeTrue())
+
+		// No guard, no condition
+		clusterProfile.Spec.MatchExpansionGuard = nil
+		Expect(controllers.GuardMatchExpansion(profileScope, getClusterRefs(500), logger)).To(HaveLen(500))
+		Expect(meta.FindStatusCondition(profileScope.GetStatus().Conditions,
+			configv1beta1.MatchExpansionPendingCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,187 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ExtraLabels and ExtraAnnotations are applied along with the resource and, additionally, with a
+// field manager per (Cluster)Profile. Server side apply then tracks which profile set each
+// label/annotation, so when multiple profiles add metadata to the same resource, each profile
+// only ever removes its own contributions.
+
+const (
+	metadataFieldManagerPrefix = "sveltos/"
+
+	// maxFieldManagerLength is the maximum length of a field manager accepted by the API server
+	maxFieldManagerLength = 128
+)
+
+// getMetadataFieldManager returns the field manager used to apply ExtraLabels and ExtraAnnotations
+// on behalf of the (Cluster)Profile owning the ClusterSummary
+func getMetadataFieldManager(clusterSummary *configv1beta1.ClusterSummary) (string, error) {
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return "", err
+	}
+
+	var manager string
+	if profileOwnerRef.Kind == configv1beta1.ProfileKind {
+		manager = fmt.Sprintf("%s%s/%s/%s", metadataFieldManagerPrefix, profileOwnerRef.Kind,
+			clusterSummary.Namespace, profileOwnerRef.Name)
+	} else {
+		manager = fmt.Sprintf("%s%s/%s", metadataFieldManagerPrefix, profileOwnerRef.Kind, profileOwnerRef.Name)
+	}
+
+	if len(manager) > maxFieldManagerLength {
+		manager = fmt.Sprintf("%s%s/%x", metadataFieldManagerPrefix, profileOwnerRef.Kind,
+			sha256.Sum256([]byte(manager)))
+	}
+
+	return manager, nil
+}
+
+func hasExtraMetadata(clusterSummary *configv1beta1.ClusterSummary) bool {
+	return len(clusterSummary.Spec.ClusterProfileSpec.ExtraLabels) != 0 ||
+		len(clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations) != 0
+}
+
+// isManagedBy returns true if fieldManager owns any field of the object
+func isManagedBy(object *unstructured.Unstructured, fieldManager string) bool {
+	managedFields := object.GetManagedFields()
+	for i := range managedFields {
+		if managedFields[i].Manager == fieldManager &&
+			managedFields[i].Operation == metav1.ManagedFieldsOperationApply {
+
+			return true
+		}
+	}
+	return false
+}
+
+// getExtraMetadataObject returns an object containing only the identity of policy and the
+// ClusterSummary ExtraLabels and ExtraAnnotations. Applying an object with no labels and no
+// annotations releases all metadata previously set by the field manager.
+func getExtraMetadataObject(policy *unstructured.Unstructured, extraLabels, extraAnnotations map[string]string,
+) *unstructured.Unstructured {
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(policy.GetAPIVersion())
+	u.SetKind(policy.GetKind())
+	u.SetNamespace(policy.GetNamespace())
+	u.SetName(policy.GetName())
+	addExtraLabels(u, extraLabels)
+	addExtraAnnotations(u, extraAnnotations)
+	return u
+}
+
+// applyExtraMetadata applies the ClusterSummary ExtraLabels and ExtraAnnotations to policy with the
+// (Cluster)Profile field manager. Policy must exist. Returns the updated object.
+// No action in DryRun mode.
+func applyExtraMetadata(ctx context.Context, dr dynamic.ResourceInterface,
+	clusterSummary *configv1beta1.ClusterSummary, policy *unstructured.Unstructured, logger logr.Logger,
+) (*unstructured.Unstructured, error) {
+
+	// No-op in DryRun mode
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil, nil
+	}
+
+	fieldManager, err := getMetadataFieldManager(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("applying extra labels/annotations to %s %s/%s",
+		policy.GetKind(), policy.GetNamespace(), policy.GetName()))
+
+	metadata := getExtraMetadataObject(policy, clusterSummary.Spec.ClusterProfileSpec.ExtraLabels,
+		clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations)
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	forceConflict := true
+	options := metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &forceConflict,
+	}
+
+	return dr.Patch(ctx, policy.GetName(), types.ApplyPatchType, data, options)
+}
+
+// releaseExtraMetadata removes the labels and annotations set on policy by the (Cluster)Profile owning
+// the ClusterSummary. Labels and annotations also set by other profiles are left untouched.
+func releaseExtraMetadata(ctx context.Context, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, policy *unstructured.Unstructured, logger logr.Logger) error {
+
+	fieldManager, err := getMetadataFieldManager(clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	if !isManagedBy(policy, fieldManager) {
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("releasing extra labels/annotations on %s %s/%s",
+		policy.GetKind(), policy.GetNamespace(), policy.GetName()))
+
+	metadata := getExtraMetadataObject(policy, nil, nil)
+	return remoteClient.Patch(ctx, metadata, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// deployResourceAndMetadata deploys policy, which already contains ExtraLabels and ExtraAnnotations,
+// and records the (Cluster)Profile ownership of ExtraLabels and ExtraAnnotations.
+func deployResourceAndMetadata(ctx context.Context, dr dynamic.ResourceInterface,
+	clusterSummary *configv1beta1.ClusterSummary, policy *unstructured.Unstructured, subresources []string,
+	logger logr.Logger) error {
+
+	current, err := updateResource(ctx, dr, clusterSummary, policy, subresources, logger)
+	if err != nil || current == nil {
+		return err
+	}
+
+	fieldManager, err := getMetadataFieldManager(clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	// When ExtraLabels/ExtraAnnotations are removed from the profile, metadata previously
+	// set by the profile must be released
+	if !hasExtraMetadata(clusterSummary) && !isManagedBy(current, fieldManager) {
+		return nil
+	}
+
+	_, err = applyExtraMetadata(ctx, dr, clusterSummary, current, logger)
+	return err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Extra metadata ownership", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Kind:       configv1beta1.ProfileKind,
+						Name:       randomString(),
+					},
+				},
+			},
+		}
+	})
+
+	It("getMetadataFieldManager returns a field manager per profile", func() {
+		fieldManager, err := controllers.GetMetadataFieldManager(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(fieldManager).To(Equal("sveltos/Profile/" + clusterSummary.Namespace + "/" +
+			clusterSummary.OwnerReferences[0].Name))
+
+		clusterSummary.OwnerReferences[0].Kind = configv1beta1.ClusterProfileKind
+		fieldManager, err = controllers.GetMetadataFieldManager(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(fieldManager).To(Equal("sveltos/ClusterProfile/" + clusterSummary.OwnerReferences[0].Name))
+
+		clusterSummary.OwnerReferences[0].Name = strings.Repeat("a", 200)
+		fieldManager, err = controllers.GetMetadataFieldManager(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(len(fieldManager) <= 128).To(BeTrue())
+		Expect(strings.HasPrefix(fieldManager, "sveltos/ClusterProfile/")).To(BeTrue())
+	})
+
+	It("getExtraMetadataObject contains only the identity of the resource and the extra metadata", func() {
+		policy := &unstructured.Unstructured{}
+		policy.SetAPIVersion("v1")
+		policy.SetKind("ConfigMap")
+		policy.SetNamespace(randomString())
+		policy.SetName(randomString())
+		policy.SetLabels(map[string]string{randomString(): randomString()})
+		Expect(unstructured.SetNestedField(policy.Object, randomString(), "data", "key")).To(Succeed())
+
+		extraLabels := map[string]string{randomString(): randomString()}
+		extraAnnotations := map[string]string{randomString(): randomString()}
+
+		metadata := controllers.GetExtraMetadataObject(policy, extraLabels, extraAnnotations)
+		Expect(metadata.GetAPIVersion()).To(Equal(policy.GetAPIVersion()))
+		Expect(metadata.GetKind()).To(Equal(policy.GetKind()))
+		Expect(metadata.GetNamespace()).To(Equal(policy.GetNamespace()))
+		Expect(metadata.GetName()).To(Equal(policy.GetName()))
+		Expect(metadata.GetLabels()).To(Equal(extraLabels))
+		Expect(metadata.GetAnnotations()).To(Equal(extraAnnotations))
+		_, found, err := unstructured.NestedFieldNoCopy(metadata.Object, "data")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeFalse())
+
+		metadata = controllers.GetExtraMetadataObject(policy, nil, nil)
+		Expect(metadata.GetLabels()).To(BeEmpty())
+		Expect(metadata.GetAnnotations()).To(BeEmpty())
+	})
+
+	It("isManagedBy returns true only when field manager applied fields", func() {
+		fieldManager, err := controllers.GetMetadataFieldManager(clusterSummary)
+		Expect(err).To(BeNil())
+
+		object := &unstructured.Unstructured{}
+		object.SetManagedFields([]metav1.ManagedFieldsEntry{
+			{Manager: "application/apply-patch", Operation: metav1.ManagedFieldsOperationApply},
+			{Manager: fieldManager, Operation: metav1.ManagedFieldsOperationUpdate},
+		})
+		Expect(controllers.IsManagedBy(object, fieldManager)).To(BeFalse())
+
+		object.SetManagedFields(append(object.GetManagedFields(),
+			metav1.ManagedFieldsEntry{Manager: fieldManager, Operation: metav1.ManagedFieldsOperationApply}))
+		Expect(controllers.IsManagedBy(object, fieldManager)).To(BeTrue())
+	})
+})
@@ -14,6 +14,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
@@ -46,12 +48,329 @@ var (
 			Buckets:   []float64{1, 10, 30, 60, 120, 180, 240},
 		},
 	)
+
+	artifactCacheRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "artifact_cache_requests_total",
+			Help:      "Flux artifacts requests by cache result (hit or miss)",
+		},
+		[]string{"result"},
+	)
+
+	artifactDownloadedBytesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "artifact_downloaded_bytes_total",
+			Help:      "Bytes of Flux artifacts downloaded from source-controller",
+		},
+	)
+
+	artifactSavedBytesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "artifact_saved_bytes_total",
+			Help:      "Bytes of Flux artifacts served from the cache instead of being downloaded",
+		},
+	)
+
+	templateInstantiationDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "projectsveltos",
+			Name:      "template_instantiation_time_seconds",
+			Help:      "Template instantiation duration distribution per profile",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		profileMetricLabels,
+	)
+
+	templateInstantiationFailuresCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "template_instantiation_failures_total",
+			Help:      "Template instantiation failures per profile",
+		},
+		profileMetricLabels,
+	)
+
+	luaEvaluationDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "projectsveltos",
+			Name:      "lua_evaluation_time_seconds",
+			Help:      "Lua script evaluation duration distribution per profile",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		profileMetricLabels,
+	)
+
+	luaEvaluationFailuresCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "lua_evaluation_failures_total",
+			Help:      "Lua script evaluation failures per profile",
+		},
+		profileMetricLabels,
+	)
+
+	clusterThrottledCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_throttled_total",
+			Help:      "Deployments failed because the managed cluster throttled requests or was unavailable",
+		},
+		[]string{"cluster", "cluster_type", "reason"},
+	)
+
+	clusterThrottleBackoffGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_throttle_backoff_seconds",
+			Help:      "Current backoff of deployments to a managed cluster throttling requests. Zero once recovered",
+		},
+		[]string{"cluster", "cluster_type"},
+	)
+)
+
+// profileMetricLabels are the labels identifying the (Cluster)Profile in per profile metrics
+var profileMetricLabels = []string{"profile_kind", "profile_namespace", "profile_name"}
+
+var (
+	helmReleaseInfoDesc = prometheus.NewDesc(
+		"sveltos_helm_release_info",
+		"Helm releases managed by Sveltos across all managed clusters. Value is always 1",
+		[]string{"cluster", "cluster_type", "release", "release_namespace", "chart", "version", "status"},
+		nil,
+	)
+
+	missingReferenceDesc = prometheus.NewDesc(
+		"sveltos_missing_reference",
+		"ConfigMaps/Secrets referenced by a ClusterSummary which do not exist. Value is always 1",
+		[]string{"cluster", "cluster_type", "feature", "kind", "namespace", "name"},
+		nil,
+	)
+
+	lastSuccessfulSyncDesc = prometheus.NewDesc(
+		"sveltos_cluster_last_successful_sync_timestamp",
+		"Unix time of the last successful deployment of all features of a ClusterSummary in a managed cluster",
+		[]string{"cluster", "cluster_type", "clustersummary"},
+		nil,
+	)
 )
 
 //nolint:gochecknoinits // forced pattern, can't workaround
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram)
+	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram,
+		artifactCacheRequestsCounter, artifactDownloadedBytesCounter, artifactSavedBytesCounter,
+		templateInstantiationDurationHistogram, templateInstantiationFailuresCounter,
+		luaEvaluationDurationHistogram, luaEvaluationFailuresCounter,
+		clusterThrottledCounter, clusterThrottleBackoffGauge)
+}
+
+// helmReleaseCollector is a prometheus Collector exposing, for each helm release managed
+// by a ClusterSummary, chart, version and status. Data is built from ClusterSummaries
+// at scrape time, so there is no need to scrape each managed cluster.
+type helmReleaseCollector struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// RegisterHelmReleaseCollector registers with the global prometheus registry the collector
+// exposing sveltos_helm_release_info metric.
+func RegisterHelmReleaseCollector(c client.Client, logger logr.Logger) error {
+	return metrics.Registry.Register(&helmReleaseCollector{c: c, logger: logger})
+}
+
+func (h *helmReleaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- helmReleaseInfoDesc
+}
+
+func (h *helmReleaseCollector) Collect(ch chan<- prometheus.Metric) {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := h.c.List(ctx, clusterSummaries); err != nil {
+		h.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return
+	}
+
+	for i := range clusterSummaries.Items {
+		for _, labels := range getHelmReleaseInfo(ctx, &clusterSummaries.Items[i], h.logger) {
+			ch <- prometheus.MustNewConstMetric(helmReleaseInfoDesc, prometheus.GaugeValue, 1, labels...)
+		}
+	}
+}
+
+// getHelmReleaseInfo returns, for each helm chart referenced by the ClusterSummary, the label
+// values of sveltos_helm_release_info metric.
+// Release name and namespace are instantiated for the cluster. Version is the chart version
+// the release is currently deployed with. Status is evaluated per release:
+// - Conflict if another ClusterSummary is managing the release;
+// - Failed if last deployment of the release failed;
+// - Provisioned if the release is deployed with the desired version (or uninstalled);
+// - Provisioning otherwise.
+func getHelmReleaseInfo(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) [][]string {
+
+	helmCharts, err := getInstantiatedHelmCharts(ctx, clusterSummary, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate helm releases: %v", err))
+		return nil
+	}
+
+	cluster := fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
+	result := make([][]string, 0, len(helmCharts))
+	for i := range helmCharts {
+		chart := &helmCharts[i]
+		version, status := getHelmReleaseVersionAndStatus(clusterSummary, chart)
+		result = append(result, []string{cluster, string(clusterSummary.Spec.ClusterType), chart.ReleaseName,
+			chart.ReleaseNamespace, chart.ChartName, version, status})
+	}
+
+	return result
+}
+
+// getHelmReleaseVersionAndStatus returns the version the helm release is deployed with and the
+// status of the helm release, as reported in the ClusterSummary HelmReleaseSummaries.
+func getHelmReleaseVersionAndStatus(clusterSummary *configv1beta1.ClusterSummary,
+	chart *configv1beta1.HelmChart) (version, status string) {
+
+	rs := getHelmChartSummary(clusterSummary, chart)
+	if rs == nil {
+		return "", string(configv1beta1.FeatureStatusProvisioning)
+	}
+
+	switch {
+	case rs.Status == configv1beta1.HelmChartStatusConflict:
+		status = string(configv1beta1.HelmChartStatusConflict)
+	case rs.FailureMessage != "":
+		status = string(configv1beta1.FeatureStatusFailed)
+	case isDeployedWithDesiredVersion(clusterSummary, chart, rs):
+		status = string(configv1beta1.FeatureStatusProvisioned)
+	default:
+		status = string(configv1beta1.FeatureStatusProvisioning)
+	}
+
+	return rs.DeployedVersion, status
+}
+
+// isDeployedWithDesiredVersion returns true if the helm release is deployed with the version
+// requested for it. For a chart being uninstalled, returns true once the release is gone.
+func isDeployedWithDesiredVersion(clusterSummary *configv1beta1.ClusterSummary, chart *configv1beta1.HelmChart,
+	rs *configv1beta1.HelmChartSummary) bool {
+
+	if chart.HelmChartAction == configv1beta1.HelmChartActionUninstall {
+		return rs.DeployedVersion == "" && rs.ValuesHash != nil
+	}
+
+	if rs.DeployedVersion == "" {
+		return false
+	}
+
+	return strings.TrimPrefix(rs.DeployedVersion, "v") ==
+		strings.TrimPrefix(getDesiredChartVersion(clusterSummary, chart), "v")
+}
+
+// missingReferenceCollector is a prometheus Collector exposing, for each ClusterSummary, the
+// referenced ConfigMaps/Secrets which do not exist. Data is built from ClusterSummaries Status
+// at scrape time.
+type missingReferenceCollector struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// RegisterMissingReferenceCollector registers with the global prometheus registry the collector
+// exposing sveltos_missing_reference metric.
+func RegisterMissingReferenceCollector(c client.Client, logger logr.Logger) error {
+	return metrics.Registry.Register(&missingReferenceCollector{c: c, logger: logger})
+}
+
+func (m *missingReferenceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- missingReferenceDesc
+}
+
+func (m *missingReferenceCollector) Collect(ch chan<- prometheus.Metric) {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := m.c.List(ctx, clusterSummaries); err != nil {
+		m.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return
+	}
+
+	for i := range clusterSummaries.Items {
+		for _, labels := range getMissingReferenceInfo(&clusterSummaries.Items[i]) {
+			ch <- prometheus.MustNewConstMetric(missingReferenceDesc, prometheus.GaugeValue, 1, labels...)
+		}
+	}
+}
+
+// getMissingReferenceInfo returns, for each missing reference reported in the ClusterSummary Status,
+// the label values of sveltos_missing_reference metric.
+func getMissingReferenceInfo(clusterSummary *configv1beta1.ClusterSummary) [][]string {
+	cluster := fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
+	result := make([][]string, 0, len(clusterSummary.Status.MissingReferences))
+	for i := range clusterSummary.Status.MissingReferences {
+		ref := &clusterSummary.Status.MissingReferences[i]
+		result = append(result, []string{cluster, string(clusterSummary.Spec.ClusterType), string(ref.FeatureID),
+			ref.Kind, ref.Namespace, ref.Name})
+	}
+
+	return result
+}
+
+// lastSuccessfulSyncCollector is a prometheus Collector exposing, for each ClusterSummary, the last
+// time all its features were successfully deployed. Data is built from ClusterSummaries Status
+// at scrape time.
+type lastSuccessfulSyncCollector struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// RegisterLastSuccessfulSyncCollector registers with the global prometheus registry the collector
+// exposing sveltos_cluster_last_successful_sync_timestamp metric.
+func RegisterLastSuccessfulSyncCollector(c client.Client, logger logr.Logger) error {
+	return metrics.Registry.Register(&lastSuccessfulSyncCollector{c: c, logger: logger})
+}
+
+func (l *lastSuccessfulSyncCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastSuccessfulSyncDesc
+}
+
+func (l *lastSuccessfulSyncCollector) Collect(ch chan<- prometheus.Metric) {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := l.c.List(ctx, clusterSummaries); err != nil {
+		l.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return
+	}
+
+	for i := range clusterSummaries.Items {
+		labels, value := getLastSuccessfulSyncInfo(&clusterSummaries.Items[i])
+		if labels != nil {
+			ch <- prometheus.MustNewConstMetric(lastSuccessfulSyncDesc, prometheus.GaugeValue, value, labels...)
+		}
+	}
+}
+
+// getLastSuccessfulSyncInfo returns the label values and the value of the
+// sveltos_cluster_last_successful_sync_timestamp metric. Labels are nil if ClusterSummary was never synced.
+func getLastSuccessfulSyncInfo(clusterSummary *configv1beta1.ClusterSummary) (labels []string, value float64) {
+	if clusterSummary.Status.LastSuccessfulSyncTime == nil {
+		return nil, 0
+	}
+
+	cluster := fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
+	return []string{cluster, string(clusterSummary.Spec.ClusterType),
+			fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name)},
+		float64(clusterSummary.Status.LastSuccessfulSyncTime.Unix())
 }
 
 func newResourceHistogram(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
@@ -143,3 +462,36 @@ func programDuration(elapsed time.Duration, clusterNamespace, clusterName, featu
 		}
 	}
 }
+
+// getProfileMetricLabels returns the values of profileMetricLabels for the (Cluster)Profile
+// owning clusterSummary
+func getProfileMetricLabels(clusterSummary *configv1beta1.ClusterSummary) []string {
+	profileRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return []string{"", "", ""}
+	}
+
+	namespace := ""
+	if profileRef.Kind == configv1beta1.ProfileKind {
+		namespace = clusterSummary.Namespace
+	}
+	return []string{profileRef.Kind, namespace, profileRef.Name}
+}
+
+func observeTemplateInstantiation(clusterSummary *configv1beta1.ClusterSummary, elapsed time.Duration,
+	err error) {
+
+	labels := getProfileMetricLabels(clusterSummary)
+	templateInstantiationDurationHistogram.WithLabelValues(labels...).Observe(elapsed.Seconds())
+	if err != nil {
+		templateInstantiationFailuresCounter.WithLabelValues(labels...).Inc()
+	}
+}
+
+func observeLuaEvaluation(clusterSummary *configv1beta1.ClusterSummary, elapsed time.Duration, err error) {
+	labels := getProfileMetricLabels(clusterSummary)
+	luaEvaluationDurationHistogram.WithLabelValues(labels...).Observe(elapsed.Seconds())
+	if err != nil {
+		luaEvaluationFailuresCounter.WithLabelValues(labels...).Inc()
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Evaluation metrics", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Kind:       configv1beta1.ProfileKind,
+						Name:       randomString(),
+					},
+				},
+			},
+		}
+	})
+
+	It("getProfileMetricLabels returns the profile owning the ClusterSummary", func() {
+		Expect(controllers.GetProfileMetricLabels(clusterSummary)).To(Equal([]string{configv1beta1.ProfileKind,
+			clusterSummary.Namespace, clusterSummary.OwnerReferences[0].Name}))
+
+		clusterSummary.OwnerReferences[0].Kind = configv1beta1.ClusterProfileKind
+		Expect(controllers.GetProfileMetricLabels(clusterSummary)).To(Equal([]string{configv1beta1.ClusterProfileKind,
+			"", clusterSummary.OwnerReferences[0].Name}))
+	})
+
+	It("observeTemplateInstantiation and observeLuaEvaluation count failures per profile", func() {
+		labels := controllers.GetProfileMetricLabels(clusterSummary)
+
+		controllers.ObserveTemplateInstantiation(clusterSummary, time.Millisecond, nil)
+		controllers.ObserveTemplateInstantiation(clusterSummary, time.Millisecond, errors.New("missing key"))
+		Expect(testutil.ToFloat64(
+			controllers.TemplateInstantiationFailuresCounter.WithLabelValues(labels...))).To(Equal(float64(1)))
+
+		controllers.ObserveLuaEvaluation(clusterSummary, time.Millisecond, errors.New("syntax error"))
+		controllers.ObserveLuaEvaluation(clusterSummary, time.Millisecond, errors.New("syntax error"))
+		Expect(testutil.ToFloat64(
+			controllers.LuaEvaluationFailuresCounter.WithLabelValues(labels...))).To(Equal(float64(2)))
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// NodeOSAnnotation can be set on any resource deployed by the Resources and Kustomize features.
+	// Value is a comma separated list of operating systems (for instance windows). Resource is deployed
+	// only if the destination cluster has at least one node running one of those operating systems.
+	// This allows profiles to contain both Linux and Windows variants of a DaemonSet.
+	NodeOSAnnotation = "projectsveltos.io/node-os"
+
+	// NodeArchAnnotation can be set on any resource deployed by the Resources and Kustomize features.
+	// Value is a comma separated list of architectures (for instance amd64,arm64). Resource is deployed
+	// only if the destination cluster has at least one node with one of those architectures.
+	NodeArchAnnotation = "projectsveltos.io/node-arch"
+)
+
+// nodePlatforms contains the operating systems and architectures of the nodes of a cluster
+type nodePlatforms struct {
+	os   map[string]bool
+	arch map[string]bool
+}
+
+// getNodePlatforms returns the operating systems and architectures of the cluster nodes, as reported
+// by the kubelet on each node
+func getNodePlatforms(ctx context.Context, c client.Client) (*nodePlatforms, error) {
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+
+	platforms := &nodePlatforms{os: make(map[string]bool), arch: make(map[string]bool)}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		nodeOS := node.Labels[corev1.LabelOSStable]
+		if nodeOS == "" {
+			nodeOS = node.Status.NodeInfo.OperatingSystem
+		}
+		nodeArch := node.Labels[corev1.LabelArchStable]
+		if nodeArch == "" {
+			nodeArch = node.Status.NodeInfo.Architecture
+		}
+
+		platforms.os[strings.ToLower(nodeOS)] = true
+		platforms.arch[strings.ToLower(nodeArch)] = true
+	}
+
+	return platforms, nil
+}
+
+// filterByNodePlatforms returns the resources applicable to the destination cluster. Resources with
+// NodeOSAnnotation/NodeArchAnnotation are removed unless at least one node matches. Nodes are listed
+// only if at least one resource has those annotations.
+func filterByNodePlatforms(ctx context.Context, c client.Client, resources []*unstructured.Unstructured,
+	logger logr.Logger) ([]*unstructured.Unstructured, error) {
+
+	var platforms *nodePlatforms
+	result := make([]*unstructured.Unstructured, 0, len(resources))
+	for i := range resources {
+		annotations := resources[i].GetAnnotations()
+		osValue, hasOS := annotations[NodeOSAnnotation]
+		archValue, hasArch := annotations[NodeArchAnnotation]
+		if !hasOS && !hasArch {
+			result = append(result, resources[i])
+			continue
+		}
+
+		if platforms == nil {
+			var err error
+			platforms, err = getNodePlatforms(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if (hasOS && !matchesNodePlatform(osValue, platforms.os)) ||
+			(hasArch && !matchesNodePlatform(archValue, platforms.arch)) {
+
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("no node matches %s %s/%s platform. Skipping it",
+				resources[i].GetKind(), resources[i].GetNamespace(), resources[i].GetName()))
+			continue
+		}
+
+		result = append(result, resources[i])
+	}
+
+	return result, nil
+}
+
+// matchesNodePlatform returns true if any of the comma separated values is in available
+func matchesNodePlatform(value string, available map[string]bool) bool {
+	for _, v := range strings.Split(value, ",") {
+		if available[strings.ToLower(strings.TrimSpace(v))] {
+			return true
+		}
+	}
+	return false
+}
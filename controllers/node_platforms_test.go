@@ -0,0 +1,67 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Node platforms", func() {
+	It("filterByNodePlatforms skips resources no node can run", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   randomString(),
+				Labels: map[string]string{corev1.LabelOSStable: "linux", corev1.LabelArchStable: "amd64"},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+
+		newDaemonSet := func(annotations map[string]string) *unstructured.Unstructured {
+			u := &unstructured.Unstructured{}
+			u.SetAPIVersion("apps/v1")
+			u.SetKind("DaemonSet")
+			u.SetNamespace(randomString())
+			u.SetName(randomString())
+			u.SetAnnotations(annotations)
+			return u
+		}
+
+		linux := newDaemonSet(map[string]string{controllers.NodeOSAnnotation: "linux"})
+		windows := newDaemonSet(map[string]string{controllers.NodeOSAnnotation: "windows"})
+		arm := newDaemonSet(map[string]string{controllers.NodeOSAnnotation: "linux", controllers.NodeArchAnnotation: "arm64"})
+		multiArch := newDaemonSet(map[string]string{controllers.NodeArchAnnotation: "arm64, amd64"})
+		generic := newDaemonSet(nil)
+
+		result, err := controllers.FilterByNodePlatforms(context.TODO(), c,
+			[]*unstructured.Unstructured{linux, windows, arm, multiArch, generic}, logger)
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal([]*unstructured.Unstructured{linux, multiArch, generic}))
+	})
+})
@@ -0,0 +1,54 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+var (
+	// observeOnly, when set, makes the controller never apply anything to managed clusters
+	observeOnly bool
+)
+
+// SetObserveOnly enables/disables observe-only mode. In observe-only mode matching clusters are
+// computed and content is rendered, but every ClusterSummary is processed as if its SyncMode was
+// DryRun: ClusterReports list what would change and metrics are collected, while nothing is ever
+// created, updated or removed in managed clusters. This is meant to shadow-run a new controller
+// version against a production fleet.
+func SetObserveOnly(enabled bool) {
+	observeOnly = enabled
+}
+
+// applyObserveOnly, when observe-only mode is enabled, makes the in-memory copy of clusterSummary be
+// processed in DryRun mode. DedicatedIdentity is also ignored, as it would require creating a
+// ServiceAccount in the managed cluster.
+// Deployment handlers only ever update ClusterSummary Status, so those changes are never persisted.
+func applyObserveOnly(clusterSummary *configv1beta1.ClusterSummary) {
+	if !observeOnly {
+		return
+	}
+
+	clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1beta1.SyncModeDryRun
+	clusterSummary.Spec.ClusterProfileSpec.DedicatedIdentity = false
+}
+
+// isDryRunSync returns true if ClusterSummary is in DryRun mode or controller is in observe-only mode.
+// Every path writing to managed clusters must be skipped when it returns true.
+func isDryRunSync(clusterSummary *configv1beta1.ClusterSummary) bool {
+	return observeOnly || clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Observe-only mode", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					SyncMode:          configv1beta1.SyncModeContinuous,
+					DedicatedIdentity: true,
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetObserveOnly(false)
+	})
+
+	It("applyObserveOnly leaves ClusterSummary unchanged when observe-only mode is disabled", func() {
+		controllers.SetObserveOnly(false)
+		controllers.ApplyObserveOnly(clusterSummary)
+		Expect(clusterSummary.Spec.ClusterProfileSpec.SyncMode).To(Equal(configv1beta1.SyncModeContinuous))
+		Expect(clusterSummary.Spec.ClusterProfileSpec.DedicatedIdentity).To(BeTrue())
+	})
+
+	It("applyObserveOnly processes ClusterSummary in DryRun mode when observe-only mode is enabled", func() {
+		controllers.SetObserveOnly(true)
+		controllers.ApplyObserveOnly(clusterSummary)
+		Expect(clusterSummary.Spec.ClusterProfileSpec.SyncMode).To(Equal(configv1beta1.SyncModeDryRun))
+		Expect(clusterSummary.Spec.ClusterProfileSpec.DedicatedIdentity).To(BeFalse())
+	})
+
+	It("isDryRunSync returns true in observe-only mode", func() {
+		controllers.SetObserveOnly(false)
+		Expect(controllers.IsDryRunSync(clusterSummary)).To(BeFalse())
+
+		controllers.SetObserveOnly(true)
+		Expect(controllers.IsDryRunSync(clusterSummary)).To(BeTrue())
+
+		controllers.SetObserveOnly(false)
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1beta1.SyncModeDryRun
+		Expect(controllers.IsDryRunSync(clusterSummary)).To(BeTrue())
+	})
+})
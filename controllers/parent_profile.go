@@ -0,0 +1,86 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// getParentMatchingClusters returns the clusters currently matching the ParentProfile.
+// A ParentProfile which does not exist (or references the profile itself) matches no cluster.
+func getParentMatchingClusters(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	parentName := profileScope.GetSpec().ParentProfile
+	if parentName == profileScope.Name() {
+		logger.V(logs.LogInfo).Info("profile cannot be its own parent")
+		return nil, nil
+	}
+
+	var parentStatus *configv1beta1.Status
+	var parent client.Object
+	if profileScope.GetKind() == configv1beta1.ClusterProfileKind {
+		clusterProfile := &configv1beta1.ClusterProfile{}
+		parentStatus = &clusterProfile.Status
+		parent = clusterProfile
+	} else {
+		profile := &configv1beta1.Profile{}
+		parentStatus = &profile.Status
+		parent = profile
+	}
+
+	err := c.Get(ctx, types.NamespacedName{Namespace: profileScope.Namespace(), Name: parentName}, parent)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("parent %s %s not found", profileScope.GetKind(), parentName))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	matchingClusters := make([]corev1.ObjectReference, len(parentStatus.MatchingClusterRefs))
+	copy(matchingClusters, parentStatus.MatchingClusterRefs)
+	return matchingClusters, nil
+}
+
+// getProfileDependencies returns the profiles that must be deployed, in a cluster, before
+// the profile with this spec: DependsOn and ParentProfile
+func getProfileDependencies(spec *configv1beta1.Spec) []string {
+	if spec.ParentProfile == "" {
+		return spec.DependsOn
+	}
+
+	dependencies := make([]string, 0, len(spec.DependsOn)+1)
+	dependencies = append(dependencies, spec.ParentProfile)
+	for i := range spec.DependsOn {
+		if spec.DependsOn[i] != spec.ParentProfile {
+			dependencies = append(dependencies, spec.DependsOn[i])
+		}
+	}
+	return dependencies
+}
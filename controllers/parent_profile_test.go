@@ -0,0 +1,111 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Parent profile", func() {
+	var parent *configv1beta1.ClusterProfile
+	var child *configv1beta1.ClusterProfile
+
+	BeforeEach(func() {
+		parent = &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{
+					{
+						Namespace:  randomString(),
+						Name:       randomString(),
+						Kind:       libsveltosv1beta1.SveltosClusterKind,
+						APIVersion: libsveltosv1beta1.GroupVersion.String(),
+					},
+				},
+			},
+		}
+
+		child = &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				ParentProfile: parent.Name,
+			},
+		}
+	})
+
+	getProfileScope := func(initObjects ...client.Object) (client.Client, *scope.ProfileScope) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: textlogger.NewLogger(textlogger.NewConfig()), Profile: child,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		return c, profileScope
+	}
+
+	It("getParentMatchingClusters returns the clusters matching the parent", func() {
+		c, profileScope := getProfileScope(parent, child)
+
+		matching, err := controllers.GetParentMatchingClusters(context.TODO(), c, profileScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(Equal(parent.Status.MatchingClusterRefs))
+	})
+
+	It("getParentMatchingClusters returns no cluster when parent does not exist", func() {
+		c, profileScope := getProfileScope(child)
+
+		matching, err := controllers.GetParentMatchingClusters(context.TODO(), c, profileScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(BeEmpty())
+	})
+
+	It("getProfileDependencies returns ParentProfile and DependsOn", func() {
+		dependency := randomString()
+		spec := &configv1beta1.Spec{DependsOn: []string{dependency}}
+		Expect(controllers.GetProfileDependencies(spec)).To(Equal([]string{dependency}))
+
+		spec.ParentProfile = randomString()
+		Expect(controllers.GetProfileDependencies(spec)).To(Equal([]string{spec.ParentProfile, dependency}))
+
+		spec.DependsOn = append(spec.DependsOn, spec.ParentProfile)
+		Expect(controllers.GetProfileDependencies(spec)).To(Equal([]string{spec.ParentProfile, dependency}))
+	})
+})
@@ -0,0 +1,244 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// preflightChecks, when set, verifies the managed cluster is reachable and grants the
+	// permissions needed before deploying a ClusterSummary
+	preflightChecks bool
+
+	// helmStorageVerbs are the verbs helm needs on the resources release information is stored in
+	helmStorageVerbs = []string{"get", "list", "create", "update", "delete"}
+
+	// deployedResourceVerbs are the verbs needed on resources deployed by Resources/Kustomize features
+	deployedResourceVerbs = []string{"get", "create", "patch", "delete"}
+)
+
+func SetPreflightChecks(enabled bool) {
+	preflightChecks = enabled
+}
+
+func getPreflightChecks() bool {
+	return preflightChecks
+}
+
+// shouldRunPreflight returns true if preflight checks are enabled and the ClusterSummary
+// has not passed them for its current generation
+func shouldRunPreflight(clusterSummary *configv1beta1.ClusterSummary) bool {
+	if !getPreflightChecks() {
+		return false
+	}
+
+	condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.PreflightFailedCondition)
+	return condition == nil || condition.Status != metav1.ConditionFalse ||
+		condition.ObservedGeneration != clusterSummary.Generation
+}
+
+// runPreflight verifies the managed cluster API server is reachable and that all permissions needed
+// to deploy the ClusterSummary are granted. Outcome is reported in the PreflightFailedCondition.
+// Returns true if the ClusterSummary can be deployed.
+func (r *ClusterSummaryReconciler) runPreflight(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) bool {
+
+	if !shouldRunPreflight(clusterSummary) {
+		return true
+	}
+
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	remoteRestConfig, err := clustercache.GetManager().GetKubernetesRestConfig(ctx, r.Client,
+		clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, adminNamespace, adminName,
+		clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		setPreflightCondition(clusterSummary, configv1beta1.ClusterUnreachableReason,
+			fmt.Sprintf("failed to get cluster kubeconfig: %v", err))
+		return false
+	}
+
+	reason, message := checkPreflight(ctx, remoteRestConfig, clusterSummary, adminName != "")
+	setPreflightCondition(clusterSummary, reason, message)
+	if reason != configv1beta1.PreflightPassedReason {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("preflight failed: %s", message))
+		return false
+	}
+
+	return true
+}
+
+// checkPreflight fetches the managed cluster version and runs a SelfSubjectAccessReview
+// for each permission needed. Returns the PreflightFailedCondition reason and message.
+func checkPreflight(ctx context.Context, remoteRestConfig *rest.Config, clusterSummary *configv1beta1.ClusterSummary,
+	tenant bool) (reason, message string) {
+
+	clientset, err := kubernetes.NewForConfig(remoteRestConfig)
+	if err != nil {
+		return configv1beta1.ClusterUnreachableReason, fmt.Sprintf("failed to create client: %v", err)
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return configv1beta1.ClusterUnreachableReason, fmt.Sprintf("failed to fetch API server version: %v", err)
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(clientset.Discovery())
+	if err != nil {
+		return configv1beta1.ClusterUnreachableReason, fmt.Sprintf("failed to discover API resources: %v", err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	missing := make([]string, 0)
+	for _, attributes := range getPreflightRequirements(clusterSummary, mapper, tenant) {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+		}
+		result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return configv1beta1.ClusterUnreachableReason, fmt.Sprintf("failed to review access: %v", err)
+		}
+		if !result.Status.Allowed {
+			missing = append(missing, describeResourceAttributes(&attributes))
+		}
+	}
+
+	if len(missing) != 0 {
+		return configv1beta1.InsufficientPermissionsReason,
+			fmt.Sprintf("missing permissions: %s", strings.Join(missing, ", "))
+	}
+
+	return configv1beta1.PreflightPassedReason,
+		fmt.Sprintf("API server %s reachable and all required permissions granted", version.GitVersion)
+}
+
+// getPreflightRequirements returns the permissions needed to deploy the ClusterSummary:
+// - read namespaces;
+// - manage the helm release storage for each helm chart;
+// - manage the resources already deployed by the Resources and Kustomize features.
+// For tenants, permissions on namespaced deployed resources are not checked as those would
+// be evaluated across all namespaces.
+func getPreflightRequirements(clusterSummary *configv1beta1.ClusterSummary, mapper meta.RESTMapper,
+	tenant bool) []authorizationv1.ResourceAttributes {
+
+	requirements := []authorizationv1.ResourceAttributes{
+		{Verb: "get", Resource: "namespaces"},
+	}
+
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		chart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		resource := "secrets"
+		namespace := chart.ReleaseNamespace
+		if storage := getHelmStorageValue(chart.Options); storage != nil {
+			switch storage.Driver {
+			case configv1beta1.HelmStorageDriverSQL:
+				continue
+			case configv1beta1.HelmStorageDriverConfigMap:
+				resource = "configmaps"
+			}
+			if storage.Namespace != "" {
+				namespace = storage.Namespace
+			}
+		}
+		if strings.Contains(namespace, "{{") {
+			// Namespace is instantiated at deployment time
+			continue
+		}
+		for _, verb := range helmStorageVerbs {
+			requirements = append(requirements,
+				authorizationv1.ResourceAttributes{Verb: verb, Resource: resource, Namespace: namespace})
+		}
+	}
+
+	for _, featureID := range []configv1beta1.FeatureID{configv1beta1.FeatureResources, configv1beta1.FeatureKustomize} {
+		for _, gvk := range getDeployedGroupVersionKinds(clusterSummary, featureID) {
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				// Deployment reports resources whose CRD is missing
+				continue
+			}
+			if tenant && mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				continue
+			}
+			for _, verb := range deployedResourceVerbs {
+				requirements = append(requirements, authorizationv1.ResourceAttributes{
+					Verb: verb, Group: mapping.Resource.Group, Version: mapping.Resource.Version,
+					Resource: mapping.Resource.Resource,
+				})
+			}
+		}
+	}
+
+	return removeDuplicateResourceAttributes(requirements)
+}
+
+func removeDuplicateResourceAttributes(attributes []authorizationv1.ResourceAttributes,
+) []authorizationv1.ResourceAttributes {
+
+	seen := make(map[authorizationv1.ResourceAttributes]bool)
+	result := make([]authorizationv1.ResourceAttributes, 0, len(attributes))
+	for i := range attributes {
+		if seen[attributes[i]] {
+			continue
+		}
+		seen[attributes[i]] = true
+		result = append(result, attributes[i])
+	}
+	return result
+}
+
+// describeResourceAttributes returns a human readable description, for instance
+// "create deployments.apps" or "get secrets in namespace kube-system"
+func describeResourceAttributes(attributes *authorizationv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if attributes.Group != "" {
+		resource = fmt.Sprintf("%s.%s", resource, attributes.Group)
+	}
+	if attributes.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s", attributes.Verb, resource, attributes.Namespace)
+	}
+	return fmt.Sprintf("%s %s", attributes.Verb, resource)
+}
+
+func setPreflightCondition(clusterSummary *configv1beta1.ClusterSummary, reason, message string) {
+	status := metav1.ConditionTrue
+	if reason == configv1beta1.PreflightPassedReason {
+		status = metav1.ConditionFalse
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, metav1.Condition{
+		Type:               configv1beta1.PreflightFailedCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: clusterSummary.Generation,
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Preflight", func() {
+	AfterEach(func() {
+		controllers.SetPreflightChecks(false)
+	})
+
+	It("shouldRunPreflight returns true until preflight passed for current generation", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), Namespace: randomString(), Generation: 1},
+		}
+
+		// Disabled
+		Expect(controllers.ShouldRunPreflight(clusterSummary)).To(BeFalse())
+
+		controllers.SetPreflightChecks(true)
+		Expect(controllers.ShouldRunPreflight(clusterSummary)).To(BeTrue())
+
+		controllers.SetPreflightCondition(clusterSummary, configv1beta1.InsufficientPermissionsReason,
+			"missing permissions: get namespaces")
+		Expect(controllers.ShouldRunPreflight(clusterSummary)).To(BeTrue())
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.PreflightFailedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+		controllers.SetPreflightCondition(clusterSummary, configv1beta1.PreflightPassedReason, "")
+		Expect(controllers.ShouldRunPreflight(clusterSummary)).To(BeFalse())
+
+		clusterSummary.Generation = 2
+		Expect(controllers.ShouldRunPreflight(clusterSummary)).To(BeTrue())
+	})
+
+	It("getPreflightRequirements returns permissions needed by helm storage and deployed resources", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{ReleaseName: randomString(), ReleaseNamespace: "kyverno"},
+						{ReleaseName: randomString(), ReleaseNamespace: "kyverno"},
+						{ReleaseName: randomString(), ReleaseNamespace: "{{ .Cluster.metadata.name }}"},
+						{
+							ReleaseName: randomString(), ReleaseNamespace: "nginx",
+							Options: &configv1beta1.HelmOptions{Storage: &configv1beta1.HelmStorage{
+								Driver: configv1beta1.HelmStorageDriverConfigMap, Namespace: "helm",
+							}},
+						},
+					},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				DeployedGVKs: []configv1beta1.FeatureDeploymentInfo{
+					{
+						FeatureID:                configv1beta1.FeatureResources,
+						DeployedGroupVersionKind: []string{"Deployment.v1.apps", "ClusterRole.v1.rbac.authorization.k8s.io"},
+					},
+				},
+			},
+		}
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+		mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+			meta.RESTScopeRoot)
+
+		requirements := controllers.GetPreflightRequirements(clusterSummary, mapper, false)
+		Expect(requirements).To(ContainElement(authorizationv1.ResourceAttributes{Verb: "get", Resource: "namespaces"}))
+		Expect(requirements).To(ContainElement(
+			authorizationv1.ResourceAttributes{Verb: "create", Resource: "secrets", Namespace: "kyverno"}))
+		Expect(requirements).To(ContainElement(
+			authorizationv1.ResourceAttributes{Verb: "update", Resource: "configmaps", Namespace: "helm"}))
+		Expect(requirements).To(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "patch", Group: "apps", Version: "v1", Resource: "deployments"}))
+		Expect(requirements).To(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "delete", Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}))
+		// 1 namespaces + 5 verbs for each of 2 storage namespaces + 4 verbs for each of 2 deployed resources
+		Expect(requirements).To(HaveLen(19))
+
+		// Tenants are not checked for namespaced deployed resources
+		requirements = controllers.GetPreflightRequirements(clusterSummary, mapper, true)
+		Expect(requirements).ToNot(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "patch", Group: "apps", Version: "v1", Resource: "deployments"}))
+		Expect(requirements).To(HaveLen(15))
+	})
+
+	It("checkPreflight reports unreachable clusters", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{}
+		remoteRestConfig := &rest.Config{Host: "https://127.0.0.1:1", Timeout: time.Second}
+
+		reason, message := controllers.CheckPreflight(context.TODO(), remoteRestConfig, clusterSummary, false)
+		Expect(reason).To(Equal(configv1beta1.ClusterUnreachableReason))
+		Expect(message).To(ContainSubstring("failed to fetch API server version"))
+	})
+})
@@ -0,0 +1,146 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+const (
+	// AdoptFromAnnotation is set on a ClusterProfile/Profile to the name of a previous ClusterProfile
+	// (or Profile in the same namespace) whose deployed resources and helm releases must be adopted.
+	// This allows renaming a profile: once the new profile is created with this annotation, it takes
+	// over ownership of what the old profile deployed in each matching cluster without removing and
+	// deploying it again. The old profile can then be deleted without affecting managed clusters.
+	AdoptFromAnnotation = "projectsveltos.io/adopt-from"
+)
+
+// getAdoptFrom returns the name of the profile obj is adopting resources from, if any
+func getAdoptFrom(obj client.Object) string {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		return ""
+	}
+	return annotations[AdoptFromAnnotation]
+}
+
+// isOwnedByAdoptedProfile returns true if one of the owners of a resource deployed in a managed
+// cluster is the profile the adopter profile is adopting resources from
+func isOwnedByAdoptedProfile(adopter client.Object, owners []corev1.ObjectReference) bool {
+	adoptFrom := getAdoptFrom(adopter)
+	if adoptFrom == "" || adoptFrom == adopter.GetName() {
+		return false
+	}
+
+	kind := adopter.GetObjectKind().GroupVersionKind().Kind
+	for i := range owners {
+		if owners[i].Kind != kind {
+			continue
+		}
+		switch kind {
+		case configv1beta1.ClusterProfileKind:
+			if owners[i].Name == adoptFrom {
+				return true
+			}
+		case configv1beta1.ProfileKind:
+			ownerName := getProfileNameFromOwnerReferenceName(owners[i].Name)
+			if ownerName.Name == adoptFrom &&
+				(ownerName.Namespace == "" || ownerName.Namespace == adopter.GetNamespace()) {
+
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isOwnedByAdoptingProfile returns true if one of the owners of a resource deployed in a managed
+// cluster is a profile adopting resources from profile. Once adopted, resources can never be taken back.
+func isOwnedByAdoptingProfile(ctx context.Context, c client.Client, profile client.Object,
+	owners []corev1.ObjectReference) (bool, error) {
+
+	kind := profile.GetObjectKind().GroupVersionKind().Kind
+	for i := range owners {
+		if owners[i].Kind != kind {
+			continue
+		}
+
+		var owner client.Object
+		switch kind {
+		case configv1beta1.ClusterProfileKind:
+			owner = &configv1beta1.ClusterProfile{}
+			if err := c.Get(ctx, types.NamespacedName{Name: owners[i].Name}, owner); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return false, err
+			}
+		case configv1beta1.ProfileKind:
+			ownerName := getProfileNameFromOwnerReferenceName(owners[i].Name)
+			if ownerName.Namespace == "" {
+				ownerName.Namespace = profile.GetNamespace()
+			}
+			owner = &configv1beta1.Profile{}
+			if err := c.Get(ctx, *ownerName, owner); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return false, err
+			}
+			if owner.GetNamespace() != profile.GetNamespace() {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if owner.GetName() != profile.GetName() && getAdoptFrom(owner) == profile.GetName() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isAdoptingFrom returns true if the profile owning the adopter ClusterSummary is adopting resources
+// from the profile owning the adoptee ClusterSummary
+func isAdoptingFrom(adopter, adoptee *configv1beta1.ClusterSummary) bool {
+	adoptFrom := getAdoptFrom(adopter)
+	if adoptFrom == "" {
+		return false
+	}
+
+	adopterOwnerRef, err := configv1beta1.GetProfileOwnerReference(adopter)
+	if err != nil || adopterOwnerRef == nil {
+		return false
+	}
+	adopteeOwnerRef, err := configv1beta1.GetProfileOwnerReference(adoptee)
+	if err != nil || adopteeOwnerRef == nil {
+		return false
+	}
+
+	return adopterOwnerRef.Kind == adopteeOwnerRef.Kind && adopteeOwnerRef.Name == adoptFrom &&
+		adopterOwnerRef.Name != adoptFrom
+}
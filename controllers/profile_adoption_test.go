@@ -0,0 +1,121 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Profile adoption", func() {
+	var oldProfile, newProfile *configv1beta1.ClusterProfile
+
+	BeforeEach(func() {
+		oldProfile = &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		newProfile = &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        randomString(),
+				Annotations: map[string]string{controllers.AdoptFromAnnotation: oldProfile.Name},
+			},
+		}
+	})
+
+	It("isOwnedByAdoptedProfile returns true when resource is owned by adopted profile", func() {
+		owners := []corev1.ObjectReference{
+			{Kind: configv1beta1.ClusterProfileKind, Name: oldProfile.Name, APIVersion: configv1beta1.GroupVersion.String()},
+		}
+		Expect(controllers.IsOwnedByAdoptedProfile(newProfile, owners)).To(BeTrue())
+		Expect(controllers.IsOwnedByAdoptedProfile(oldProfile, owners)).To(BeFalse())
+
+		owners[0].Name = randomString()
+		Expect(controllers.IsOwnedByAdoptedProfile(newProfile, owners)).To(BeFalse())
+
+		namespace := randomString()
+		profile := &configv1beta1.Profile{
+			TypeMeta: metav1.TypeMeta{Kind: configv1beta1.ProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        randomString(),
+				Annotations: map[string]string{controllers.AdoptFromAnnotation: oldProfile.Name},
+			},
+		}
+		owners = []corev1.ObjectReference{
+			{Kind: configv1beta1.ProfileKind, Name: fmt.Sprintf("%s/%s", namespace, oldProfile.Name)},
+		}
+		Expect(controllers.IsOwnedByAdoptedProfile(profile, owners)).To(BeTrue())
+
+		// Profiles can only adopt from Profiles in the same namespace
+		owners[0].Name = fmt.Sprintf("%s/%s", randomString(), oldProfile.Name)
+		Expect(controllers.IsOwnedByAdoptedProfile(profile, owners)).To(BeFalse())
+	})
+
+	It("isOwnedByAdoptingProfile returns true when resource is owned by a profile adopting from profile", func() {
+		initObjects := []client.Object{oldProfile, newProfile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		owners := []corev1.ObjectReference{
+			{Kind: configv1beta1.ClusterProfileKind, Name: newProfile.Name, APIVersion: configv1beta1.GroupVersion.String()},
+		}
+		adopted, err := controllers.IsOwnedByAdoptingProfile(context.TODO(), c, oldProfile, owners)
+		Expect(err).To(BeNil())
+		Expect(adopted).To(BeTrue())
+
+		owners[0].Name = oldProfile.Name
+		adopted, err = controllers.IsOwnedByAdoptingProfile(context.TODO(), c, newProfile, owners)
+		Expect(err).To(BeNil())
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("isAdoptingFrom returns true when ClusterSummary profile adopts from the other ClusterSummary profile", func() {
+		getClusterSummary := func(profile *configv1beta1.ClusterProfile) *configv1beta1.ClusterSummary {
+			return &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        randomString(),
+					Namespace:   randomString(),
+					Annotations: profile.Annotations,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: configv1beta1.GroupVersion.String(),
+							Kind:       configv1beta1.ClusterProfileKind,
+							Name:       profile.Name,
+						},
+					},
+				},
+			}
+		}
+
+		oldClusterSummary := getClusterSummary(oldProfile)
+		newClusterSummary := getClusterSummary(newProfile)
+		Expect(controllers.IsAdoptingFrom(newClusterSummary, oldClusterSummary)).To(BeTrue())
+		Expect(controllers.IsAdoptingFrom(oldClusterSummary, newClusterSummary)).To(BeFalse())
+	})
+})
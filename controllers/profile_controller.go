@@ -129,7 +129,10 @@ func (r *ProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	}
 
 	// limit all references to be in the namespace
-	r.limitReferencesToNamespace(profile)
+	r.limitReferencesToNamespace(ctx, profile)
+	if getTenantNamespaceIsolation() {
+		r.limitTargetNamespacesToTenant(profile)
+	}
 
 	profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
 		Client:         r.Client,
@@ -191,30 +194,53 @@ func (r *ProfileReconciler) reconcileNormal(
 		}
 	}
 
-	// Limit the search of matching cluster to the Profile namespace
-	matchingCluster, err := getMatchingClusters(ctx, r.Client, profileScope.Profile.GetNamespace(),
-		profileScope.GetSelector(), profileScope.GetSpec().ClusterRefs, logger)
-	if err != nil {
-		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+	var matchingCluster []corev1.ObjectReference
+	var err error
+	if profileScope.GetSpec().ParentProfile != "" {
+		// Clusters are inherited from the parent Profile
+		matchingCluster, err = getParentMatchingClusters(ctx, r.Client, profileScope, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+	} else {
+		// Limit the search of matching cluster to the Profile namespace
+		matchingCluster, err = getMatchingClusters(ctx, r.Client, profileScope.Profile.GetNamespace(),
+			profileScope.GetSelector(), profileScope.GetSpec().ClusterRefs, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+
+		// Get all clusters from referenced Sets
+		var clusterSetClusters []corev1.ObjectReference
+		clusterSetClusters, err = r.getClustersFromSets(ctx, profileScope.Namespace(), profileScope.GetSpec().SetRefs, logger)
+		if err != nil {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		}
+		matchingCluster = append(matchingCluster, clusterSetClusters...)
 	}
 
-	// Get all clusters from referenced Sets
-	clusterSetClusters, err := r.getClustersFromSets(ctx, profileScope.Namespace(), profileScope.GetSpec().SetRefs, logger)
+	// External matcher, if configured, has the final say on which clusters are a match
+	matchingCluster, err = filterByExternalMatcher(ctx, r.Client, profileScope, removeDuplicates(matchingCluster), logger)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
-	matchingCluster = append(matchingCluster, clusterSetClusters...)
 
-	profileScope.SetMatchingClusterRefs(removeDuplicates(matchingCluster))
+	// Clusters newly matching are held back if their number exceeds MatchExpansionGuard
+	profileScope.SetMatchingClusterRefs(guardMatchExpansion(profileScope, matchingCluster, logger))
 
 	r.updateMaps(profileScope)
 
-	if err := reconcileNormalCommon(ctx, r.Client, profileScope, logger); err != nil {
+	result, err := reconcileNormalCommon(ctx, r.Client, profileScope, logger)
+	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
 
 	logger.V(logs.LogInfo).Info("Reconcile success")
-	return reconcile.Result{}
+	if result.RequeueAfter == 0 && profileScope.GetSpec().ErrorBudget != nil {
+		// Re-evaluate the Degraded condition as deployment outcomes leave the window
+		return reconcile.Result{RequeueAfter: errorBudgetRequeueAfter}
+	}
+	return result
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -236,6 +262,12 @@ func (r *ProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SveltosClusterPredicates(mgr.GetLogger().WithValues("predicate", "sveltosclusterpredicate")),
 			),
 		).
+		Watches(&configv1beta1.Profile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForParent),
+		).
+		Watches(&configv1beta1.ReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForReferenceGrant),
+		).
 		Build(r)
 	if err != nil {
 		return errors.Wrap(err, "error creating controller")
@@ -279,43 +311,146 @@ func (r *ProfileReconciler) WatchForCAPI(mgr ctrl.Manager, c controller.Controll
 	return nil
 }
 
-func (r *ProfileReconciler) limitReferencesToNamespace(profile *configv1beta1.Profile) {
+// limitReferencesToNamespace resets the namespace of all references to be the Profile namespace.
+// Resources in other namespaces can only be referenced when a ReferenceGrant in that
+// namespace grants it. Those references are left untouched.
+func (r *ProfileReconciler) limitReferencesToNamespace(ctx context.Context, profile *configv1beta1.Profile) {
 	for i := range profile.Spec.ClusterRefs {
 		profile.Spec.ClusterRefs[i].Namespace = profile.Namespace
 	}
 
 	for i := range profile.Spec.PolicyRefs {
-		profile.Spec.PolicyRefs[i].Namespace = profile.Namespace
+		pr := &profile.Spec.PolicyRefs[i]
+		pr.Namespace = r.getReferenceNamespace(ctx, profile, pr.Kind, pr.Namespace, pr.Name)
 	}
 
 	for i := range profile.Spec.KustomizationRefs {
-		profile.Spec.KustomizationRefs[i].Namespace = profile.Namespace
-		r.limitKustomizationRefsToNamespace(profile, &profile.Spec.KustomizationRefs[i])
+		kr := &profile.Spec.KustomizationRefs[i]
+		kr.Namespace = r.getReferenceNamespace(ctx, profile, kr.Kind, kr.Namespace, kr.Name)
+		r.limitKustomizationRefsToNamespace(ctx, profile, kr)
 	}
 
 	for i := range profile.Spec.HelmCharts {
 		hc := &profile.Spec.HelmCharts[i]
-		if hc.RegistryCredentialsConfig != nil {
-			if hc.RegistryCredentialsConfig.CredentialsSecretRef != nil {
-				hc.RegistryCredentialsConfig.CredentialsSecretRef.Namespace = profile.Namespace
-			}
-			if hc.RegistryCredentialsConfig.CASecretRef != nil {
-				hc.RegistryCredentialsConfig.CASecretRef.Namespace = profile.Namespace
-			}
+		if hc.SourceRef != nil {
+			hc.SourceRef.Namespace = r.getReferenceNamespace(ctx, profile, hc.SourceRef.Kind,
+				hc.SourceRef.Namespace, hc.SourceRef.Name)
+		}
+		r.limitRegistryCredentialsToNamespace(ctx, profile, hc.RegistryCredentialsConfig)
+		for j := range hc.ValuesFrom {
+			vf := &hc.ValuesFrom[j]
+			vf.Namespace = r.getReferenceNamespace(ctx, profile, vf.Kind, vf.Namespace, vf.Name)
+		}
+		for j := range hc.DependencyRepositories {
+			r.limitRegistryCredentialsToNamespace(ctx, profile, hc.DependencyRepositories[j].RegistryCredentialsConfig)
+		}
+		if hc.Options != nil && hc.Options.Storage != nil {
+			r.limitSecretReferenceToNamespace(ctx, profile, hc.Options.Storage.SQLConnectionSecretRef)
+		}
+		if hc.Verify != nil {
+			r.limitSecretReferenceToNamespace(ctx, profile, &hc.Verify.SecretRef)
 		}
 	}
+
+	for i := range profile.Spec.Jobs {
+		jr := &profile.Spec.Jobs[i]
+		jr.Namespace = r.getReferenceNamespace(ctx, profile, jr.Kind, jr.Namespace, jr.Name)
+	}
+
+	for i := range profile.Spec.SecretRotationHooks {
+		hook := &profile.Spec.SecretRotationHooks[i]
+		hook.Namespace = r.getReferenceNamespace(ctx, profile,
+			string(libsveltosv1beta1.SecretReferencedResourceKind), hook.Namespace, hook.Name)
+		if hook.Job != nil {
+			hook.Job.Namespace = r.getReferenceNamespace(ctx, profile, hook.Job.Kind, hook.Job.Namespace, hook.Job.Name)
+		}
+	}
+
+	if transformer := profile.Spec.SecretTransformer; transformer != nil && transformer.CertificateRef != nil {
+		ref := transformer.CertificateRef
+		ref.Namespace = r.getReferenceNamespace(ctx, profile, ref.Kind, ref.Namespace, ref.Name)
+	}
+
+	if profile.Spec.ImageDigestResolution != nil {
+		r.limitSecretReferenceToNamespace(ctx, profile, profile.Spec.ImageDigestResolution.CredentialsSecretRef)
+	}
+
+	for i := range profile.Spec.WasmPlugins {
+		wp := &profile.Spec.WasmPlugins[i]
+		if wp.ConfigMapRef != nil {
+			wp.ConfigMapRef.Namespace = r.getReferenceNamespace(ctx, profile,
+				string(libsveltosv1beta1.ConfigMapReferencedResourceKind), wp.ConfigMapRef.Namespace, wp.ConfigMapRef.Name)
+		}
+		r.limitSecretReferenceToNamespace(ctx, profile, wp.CredentialsSecretRef)
+	}
+}
+
+// limitRegistryCredentialsToNamespace resets Namespace of the Secrets referenced by credentialsConfig,
+// unless granted by a ReferenceGrant.
+func (r *ProfileReconciler) limitRegistryCredentialsToNamespace(ctx context.Context, profile *configv1beta1.Profile,
+	credentialsConfig *configv1beta1.RegistryCredentialsConfig) {
+
+	if credentialsConfig == nil {
+		return
+	}
+
+	r.limitSecretReferenceToNamespace(ctx, profile, credentialsConfig.CredentialsSecretRef)
+	r.limitSecretReferenceToNamespace(ctx, profile, credentialsConfig.CASecretRef)
+	r.limitSecretReferenceToNamespace(ctx, profile, credentialsConfig.CertSecretRef)
+}
+
+// limitSecretReferenceToNamespace resets Namespace of the Secret referenced by profile, unless
+// granted by a ReferenceGrant.
+func (r *ProfileReconciler) limitSecretReferenceToNamespace(ctx context.Context, profile *configv1beta1.Profile,
+	secretRef *corev1.SecretReference) {
+
+	if secretRef == nil {
+		return
+	}
+
+	secretRef.Namespace = r.getReferenceNamespace(ctx, profile,
+		string(libsveltosv1beta1.SecretReferencedResourceKind), secretRef.Namespace, secretRef.Name)
+}
+
+// limitTargetNamespacesToTenant maps helm release namespaces to the tenant namespace slice in the
+// managed clusters. Tenant is the Profile namespace.
+// Kustomize target namespaces are not mapped here: like any other resource deployed by the Resources
+// and Kustomize features, they are mapped once at deployment time.
+func (r *ProfileReconciler) limitTargetNamespacesToTenant(profile *configv1beta1.Profile) {
+	for i := range profile.Spec.HelmCharts {
+		hc := &profile.Spec.HelmCharts[i]
+		hc.ReleaseNamespace = getTenantNamespace(profile.Namespace, hc.ReleaseNamespace)
+	}
 }
 
 // limitKustomizationRefsToNamespace reset Namespace of all ConfigMap/Secret
-// instances referenced by kustomizationRef.
-func (r *ProfileReconciler) limitKustomizationRefsToNamespace(profile *configv1beta1.Profile,
+// instances referenced by kustomizationRef, unless granted by a ReferenceGrant.
+func (r *ProfileReconciler) limitKustomizationRefsToNamespace(ctx context.Context, profile *configv1beta1.Profile,
 	kustomizationRef *configv1beta1.KustomizationRef) {
 
 	for i := range kustomizationRef.ValuesFrom {
-		kustomizationRef.ValuesFrom[i].Namespace = profile.Namespace
+		vf := &kustomizationRef.ValuesFrom[i]
+		vf.Namespace = r.getReferenceNamespace(ctx, profile, vf.Kind, vf.Namespace, vf.Name)
 	}
 }
 
+// getReferenceNamespace returns the namespace to use for a resource referenced by profile.
+// That is namespace only if a ReferenceGrant in namespace grants it. Profile namespace otherwise.
+func (r *ProfileReconciler) getReferenceNamespace(ctx context.Context, profile *configv1beta1.Profile,
+	kind, namespace, name string) string {
+
+	if namespace == "" || namespace == profile.Namespace {
+		return profile.Namespace
+	}
+
+	granted, err := isReferenceGranted(ctx, r.Client, profile.Namespace, kind, namespace, name)
+	if err != nil || !granted {
+		return profile.Namespace
+	}
+
+	return namespace
+}
+
 func (r *ProfileReconciler) cleanMaps(profileScope *scope.ProfileScope) {
 	r.Mux.Lock()
 	defer r.Mux.Unlock()
@@ -29,11 +29,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2/textlogger"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	libsveltosset "github.com/projectsveltos/libsveltos/lib/set"
 )
@@ -105,6 +107,36 @@ var _ = Describe("Profile Controller", func() {
 					Name:      randomString(),
 				},
 			},
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					SourceRef: &configv1beta1.ChartSourceRef{
+						Kind:      sourcev1.HelmRepositoryKind,
+						Namespace: randomString(),
+						Name:      randomString(),
+					},
+					Options: &configv1beta1.HelmOptions{
+						Storage: &configv1beta1.HelmStorage{
+							SQLConnectionSecretRef: &corev1.SecretReference{Namespace: randomString(), Name: randomString()},
+						},
+					},
+					Verify: &configv1beta1.ChartVerification{
+						SecretRef: corev1.SecretReference{Namespace: randomString(), Name: randomString()},
+					},
+				},
+			},
+			SecretRotationHooks: []configv1beta1.SecretRotationHook{
+				{Namespace: randomString(), Name: randomString()},
+			},
+			ImageDigestResolution: &configv1beta1.ImageDigestResolution{
+				CredentialsSecretRef: &corev1.SecretReference{Namespace: randomString(), Name: randomString()},
+			},
+			SecretTransformer: &configv1beta1.SecretTransformer{
+				CertificateRef: &configv1beta1.SealedSecretsCertificateRef{
+					Kind:      string(libsveltosv1beta1.SecretReferencedResourceKind),
+					Namespace: randomString(),
+					Name:      randomString(),
+				},
+			},
 		}
 
 		initObjects := []client.Object{
@@ -122,7 +154,7 @@ var _ = Describe("Profile Controller", func() {
 			Mux:           sync.Mutex{},
 		}
 
-		controllers.LimitReferencesToNamespace(reconciler, profile)
+		controllers.LimitReferencesToNamespace(reconciler, context.TODO(), profile)
 
 		for i := range profile.Spec.ClusterRefs {
 			Expect(profile.Spec.ClusterRefs[i].Namespace).To(Equal(profile.Namespace))
@@ -135,6 +167,122 @@ var _ = Describe("Profile Controller", func() {
 		for i := range profile.Spec.KustomizationRefs {
 			Expect(profile.Spec.KustomizationRefs[i].Namespace).To(Equal(profile.Namespace))
 		}
+
+		helmChart := &profile.Spec.HelmCharts[0]
+		Expect(helmChart.SourceRef.Namespace).To(Equal(profile.Namespace))
+		Expect(helmChart.Options.Storage.SQLConnectionSecretRef.Namespace).To(Equal(profile.Namespace))
+		Expect(helmChart.Verify.SecretRef.Namespace).To(Equal(profile.Namespace))
+		Expect(profile.Spec.SecretRotationHooks[0].Namespace).To(Equal(profile.Namespace))
+		Expect(profile.Spec.ImageDigestResolution.CredentialsSecretRef.Namespace).To(Equal(profile.Namespace))
+		Expect(profile.Spec.SecretTransformer.CertificateRef.Namespace).To(Equal(profile.Namespace))
+	})
+
+	It("limitReferencesToNamespace keeps references granted by a ReferenceGrant", func() {
+		grantedNamespace := randomString()
+		grant := &configv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: grantedNamespace, Name: randomString()},
+			Spec: configv1beta1.ReferenceGrantSpec{
+				From: []configv1beta1.ReferenceGrantFrom{{Namespace: profile.Namespace}},
+				To:   []configv1beta1.ReferenceGrantTo{{Kind: string(libsveltosv1beta1.SecretReferencedResourceKind)}},
+			},
+		}
+
+		profile.Spec = configv1beta1.Spec{
+			PolicyRefs: []configv1beta1.PolicyRef{
+				{
+					Kind:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+					Namespace: grantedNamespace,
+					Name:      randomString(),
+				},
+				{
+					Kind:      string(libsveltosv1beta1.SecretReferencedResourceKind),
+					Namespace: grantedNamespace,
+					Name:      randomString(),
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, grant).Build()
+		reconciler := getProfileReconciler(c)
+
+		controllers.LimitReferencesToNamespace(reconciler, context.TODO(), profile)
+
+		// Only Secrets are granted
+		Expect(profile.Spec.PolicyRefs[0].Namespace).To(Equal(profile.Namespace))
+		Expect(profile.Spec.PolicyRefs[1].Namespace).To(Equal(grantedNamespace))
+	})
+
+	It("limitReferencesToNamespace resets helm repository credentials not granted by a ReferenceGrant", func() {
+		grantedNamespace := randomString()
+		grant := &configv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: grantedNamespace, Name: randomString()},
+			Spec: configv1beta1.ReferenceGrantSpec{
+				From: []configv1beta1.ReferenceGrantFrom{{Namespace: profile.Namespace}},
+				To: []configv1beta1.ReferenceGrantTo{
+					{Kind: string(libsveltosv1beta1.SecretReferencedResourceKind), Name: "basic-auth"},
+				},
+			},
+		}
+
+		profile.Spec = configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RegistryCredentialsConfig: &configv1beta1.RegistryCredentialsConfig{
+						CredentialsSecretRef: &corev1.SecretReference{Namespace: grantedNamespace, Name: "basic-auth"},
+						CertSecretRef:        &corev1.SecretReference{Namespace: grantedNamespace, Name: "client-cert"},
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, grant).Build()
+		reconciler := getProfileReconciler(c)
+
+		controllers.LimitReferencesToNamespace(reconciler, context.TODO(), profile)
+
+		// Only the basic auth Secret is granted
+		credentialsConfig := profile.Spec.HelmCharts[0].RegistryCredentialsConfig
+		Expect(credentialsConfig.CredentialsSecretRef.Namespace).To(Equal(grantedNamespace))
+		Expect(credentialsConfig.CertSecretRef.Namespace).To(Equal(profile.Namespace))
+	})
+
+	It("updateSpecInvalidCondition reports references not granted by a ReferenceGrant", func() {
+		grantedNamespace := randomString()
+		profile.Spec.PolicyRefs = []configv1beta1.PolicyRef{
+			{
+				Kind:      string(libsveltosv1beta1.SecretReferencedResourceKind),
+				Namespace: grantedNamespace,
+				Name:      randomString(),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: profile, ControllerName: "profile",
+		})
+		Expect(err).To(BeNil())
+
+		Expect(controllers.UpdateSpecInvalidCondition(context.TODO(), c, profileScope)).ToNot(Succeed())
+		condition := meta.FindStatusCondition(profile.Status.Conditions, configv1beta1.SpecInvalidCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.SpecValidationFailedReason))
+		Expect(condition.Message).To(ContainSubstring(configv1beta1.ReferenceGrantKind))
+
+		grant := &configv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: grantedNamespace, Name: randomString()},
+			Spec: configv1beta1.ReferenceGrantSpec{
+				From: []configv1beta1.ReferenceGrantFrom{{Namespace: profile.Namespace}},
+				To:   []configv1beta1.ReferenceGrantTo{{Kind: string(libsveltosv1beta1.SecretReferencedResourceKind)}},
+			},
+		}
+		Expect(c.Create(context.TODO(), grant)).To(Succeed())
+
+		Expect(controllers.UpdateSpecInvalidCondition(context.TODO(), c, profileScope)).To(Succeed())
+		condition = meta.FindStatusCondition(profile.Status.Conditions, configv1beta1.SpecInvalidCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.SpecValidReason))
 	})
 
 	It("getClustersFromClusterSets gets cluster selected by referenced sets", func() {
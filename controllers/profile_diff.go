@@ -0,0 +1,509 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ProfileDiffPath is the path the profile diff endpoint is served on
+const ProfileDiffPath = "/profile-diff"
+
+// DiffAction describes what would happen to a resource/helm release if the spec was applied
+type DiffAction string
+
+const (
+	DiffActionCreate    = DiffAction("Create")
+	DiffActionUpdate    = DiffAction("Update")
+	DiffActionDelete    = DiffAction("Delete")
+	DiffActionNoChange  = DiffAction("NoChange")
+	DiffActionInstall   = DiffAction("Install")
+	DiffActionUpgrade   = DiffAction("Upgrade")
+	DiffActionUninstall = DiffAction("Uninstall")
+)
+
+// ResourceDiff describes the difference between a resource currently deployed and
+// the one rendered from the spec
+type ResourceDiff struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// DeployedInManagementCluster is true if the resource is deployed in the management cluster
+	DeployedInManagementCluster bool       `json:"deployedInManagementCluster,omitempty"`
+	Action                      DiffAction `json:"action"`
+	// Patch is the JSON patch which would be applied to the deployed resource
+	Patch []jsonpatch.Operation `json:"patch,omitempty"`
+
+	// current and desired are the YAML of the deployed and rendered resource. Current only
+	// contains fields set in the rendered resource. Used for the unified diff format.
+	current string
+	desired string
+}
+
+// HelmReleaseDiff describes the difference between a helm release currently deployed
+// and the one requested by the spec
+type HelmReleaseDiff struct {
+	ReleaseNamespace string     `json:"releaseNamespace"`
+	ReleaseName      string     `json:"releaseName"`
+	Action           DiffAction `json:"action"`
+	DeployedVersion  string     `json:"deployedVersion,omitempty"`
+	DesiredVersion   string     `json:"desiredVersion,omitempty"`
+}
+
+// ProfileDiff contains all the changes applying a profile spec would cause in a cluster
+type ProfileDiff struct {
+	Cluster      corev1.ObjectReference `json:"cluster"`
+	Resources    []ResourceDiff         `json:"resources,omitempty"`
+	HelmReleases []HelmReleaseDiff      `json:"helmReleases,omitempty"`
+}
+
+type profileDiffHandler struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// NewProfileDiffHandler returns an http.Handler computing, without applying anything, the
+// changes a ClusterProfile/Profile would cause in a given cluster.
+// Query parameters:
+// - kind (ClusterProfile or Profile), name and, for Profile, namespace identify the profile;
+// - clusterNamespace, clusterName and clusterType (Capi or Sveltos) identify the cluster.
+// - format (json, summary or unified) selects the output format. Default is json.
+// With GET the current profile spec is used. With POST the request body is a Spec
+// (for instance the one from a pull request) used in place of the current one.
+func NewProfileDiffHandler(c client.Client, logger logr.Logger) http.Handler {
+	return &profileDiffHandler{c: c, logger: logger}
+}
+
+func (h *profileDiffHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("kind")
+	if kind != configv1beta1.ClusterProfileKind && kind != configv1beta1.ProfileKind {
+		http.Error(w, fmt.Sprintf("kind must be %s or %s", configv1beta1.ClusterProfileKind,
+			configv1beta1.ProfileKind), http.StatusBadRequest)
+		return
+	}
+
+	format, err := getDiffFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	const timeout = time.Minute
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	clusterSummary, spec, err := h.getClusterSummaryAndSpec(ctx, r, kind)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	diff, err := computeProfileDiff(ctx, h.c, clusterSummary, spec, h.logger)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case DiffFormatUnified:
+		w.Header().Set("Content-Type", "text/plain")
+		_, err = w.Write([]byte(renderUnifiedProfileDiff(diff)))
+	case DiffFormatSummary:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(summarizeProfileDiff(diff))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(diff)
+	}
+	if err != nil {
+		h.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write response: %v", err))
+	}
+}
+
+func (h *profileDiffHandler) getClusterSummaryAndSpec(ctx context.Context, r *http.Request, kind string,
+) (*configv1beta1.ClusterSummary, *configv1beta1.Spec, error) {
+
+	query := r.URL.Query()
+	profileKey := types.NamespacedName{Name: query.Get("name")}
+	var spec *configv1beta1.Spec
+	if kind == configv1beta1.ClusterProfileKind {
+		clusterProfile := &configv1beta1.ClusterProfile{}
+		if err := h.c.Get(ctx, profileKey, clusterProfile); err != nil {
+			return nil, nil, err
+		}
+		spec = &clusterProfile.Spec
+	} else {
+		profileKey.Namespace = query.Get("namespace")
+		profile := &configv1beta1.Profile{}
+		if err := h.c.Get(ctx, profileKey, profile); err != nil {
+			return nil, nil, err
+		}
+		spec = &profile.Spec
+	}
+
+	clusterType := libsveltosv1beta1.ClusterTypeCapi
+	if query.Get("clusterType") == string(libsveltosv1beta1.ClusterTypeSveltos) {
+		clusterType = libsveltosv1beta1.ClusterTypeSveltos
+	}
+
+	clusterSummary, err := getClusterSummary(ctx, h.c, kind, profileKey.Name, query.Get("clusterNamespace"),
+		query.Get("clusterName"), clusterType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.Method == http.MethodPost {
+		spec = &configv1beta1.Spec{}
+		if err := json.NewDecoder(r.Body).Decode(spec); err != nil {
+			return nil, nil, fmt.Errorf("invalid spec: %w", err)
+		}
+	}
+
+	return clusterSummary, spec, nil
+}
+
+// computeProfileDiff renders the content referenced by spec for the ClusterSummary's cluster and
+// compares it with what is currently deployed. Nothing is applied.
+// Resources referenced via PolicyRefs (ConfigMaps/Secrets) are compared field by field. Helm
+// releases are compared by chart version.
+func computeProfileDiff(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	spec *configv1beta1.Spec, logger logr.Logger) (*ProfileDiff, error) {
+
+	clusterSummary = clusterSummary.DeepCopy()
+	clusterSummary.Spec.ClusterProfileSpec = *spec
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName))
+
+	diff := &ProfileDiff{
+		Cluster: corev1.ObjectReference{
+			Namespace: clusterSummary.Spec.ClusterNamespace,
+			Name:      clusterSummary.Spec.ClusterName,
+		},
+	}
+	if clusterSummary.Spec.ClusterType == libsveltosv1beta1.ClusterTypeSveltos {
+		diff.Cluster.Kind = libsveltosv1beta1.SveltosClusterKind
+		diff.Cluster.APIVersion = libsveltosv1beta1.GroupVersion.String()
+	} else {
+		diff.Cluster.Kind = clusterKind
+		diff.Cluster.APIVersion = clusterv1.GroupVersion.String()
+	}
+
+	profileRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	deployedFeatures, err := getDeployedFeatures(ctx, c, clusterSummary, profileRef.Kind, profileRef.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	diff.Resources, err = getResourcesDiff(ctx, c, clusterSummary, deployedFeatures, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	diff.HelmReleases, err = getHelmReleasesDiff(ctx, clusterSummary, deployedFeatures, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// getResourcesDiff compares resources rendered from the PolicyRefs with those deployed
+func getResourcesDiff(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	deployedFeatures []configv1beta1.Feature, logger logr.Logger) ([]ResourceDiff, error) {
+
+	local, remote, err := collectReferencedObjects(ctx, c, clusterSummary,
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ResourceDiff, 0)
+	if len(local) != 0 {
+		var localDiff []ResourceDiff
+		localDiff, err = getReferencedObjectsDiff(ctx, c, clusterSummary, local, mgmtResources, true, logger)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, localDiff...)
+	}
+
+	remoteClient, err := secretprovider.GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, "", "", clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteDiff, err := getReferencedObjectsDiff(ctx, remoteClient, clusterSummary, remote, mgmtResources,
+		false, logger)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, remoteDiff...)
+
+	// Resources currently deployed in the managed cluster and not rendered anymore would be removed
+	rendered := make(map[string]bool)
+	for i := range remoteDiff {
+		rendered[getResourceDiffKey(remoteDiff[i].APIVersion, remoteDiff[i].Kind,
+			remoteDiff[i].Namespace, remoteDiff[i].Name)] = true
+	}
+
+	for i := range deployedFeatures {
+		if deployedFeatures[i].FeatureID != configv1beta1.FeatureResources {
+			continue
+		}
+		deployedResources, err := deployedFeatures[i].GetResources()
+		if err != nil {
+			return nil, err
+		}
+		for j := range deployedResources {
+			r := &deployedResources[j]
+			apiVersion := schema.GroupVersion{Group: r.Group, Version: r.Version}.String()
+			if rendered[getResourceDiffKey(apiVersion, r.Kind, r.Namespace, r.Name)] {
+				continue
+			}
+			result = append(result, ResourceDiff{
+				APIVersion: apiVersion, Kind: r.Kind, Namespace: r.Namespace, Name: r.Name,
+				Action: DiffActionDelete,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func getResourceDiffKey(apiVersion, kind, namespace, name string) string {
+	return fmt.Sprintf("%s:%s:%s/%s", apiVersion, kind, namespace, name)
+}
+
+// getReferencedObjectsDiff renders the content of the referenced ConfigMaps/Secrets and compares
+// each resource with the one currently present in the destination cluster
+func getReferencedObjectsDiff(ctx context.Context, destClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, referencedObjects []client.Object,
+	mgmtResources map[string]*unstructured.Unstructured, isMgmtCluster bool, logger logr.Logger,
+) ([]ResourceDiff, error) {
+
+	result := make([]ResourceDiff, 0)
+	for i := range referencedObjects {
+		var data map[string]string
+		switch o := referencedObjects[i].(type) {
+		case *corev1.ConfigMap:
+			data = o.Data
+		case *corev1.Secret:
+			data = make(map[string]string)
+			for key, value := range o.Data {
+				data[key] = string(value)
+			}
+		default:
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("diff not supported for %s %s/%s",
+				referencedObjects[i].GetObjectKind().GroupVersionKind().Kind,
+				referencedObjects[i].GetNamespace(), referencedObjects[i].GetName()))
+			continue
+		}
+
+		policies, err := collectContent(ctx, clusterSummary, mgmtResources, data,
+			instantiateTemplate(referencedObjects[i], logger), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range policies {
+			resourceDiff, err := getResourceDiff(ctx, destClient, policies[j])
+			if err != nil {
+				return nil, err
+			}
+			resourceDiff.DeployedInManagementCluster = isMgmtCluster
+			result = append(result, *resourceDiff)
+		}
+	}
+
+	return result, nil
+}
+
+// getResourceDiff compares the desired resource with the one in the cluster.
+// Only fields set in the desired resource are considered. Fields only present in the deployed
+// resource (status, defaults set by the API server, etc.) are ignored.
+func getResourceDiff(ctx context.Context, c client.Client, desired *unstructured.Unstructured,
+) (*ResourceDiff, error) {
+
+	if desired.GetNamespace() == "" {
+		namespaced, err := c.IsObjectNamespaced(desired)
+		if err == nil && namespaced {
+			desired.SetNamespace("default")
+		}
+	}
+
+	resourceDiff := &ResourceDiff{
+		APIVersion: desired.GetAPIVersion(), Kind: desired.GetKind(),
+		Namespace: desired.GetNamespace(), Name: desired.GetName(),
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(desired.GroupVersionKind())
+	err := c.Get(ctx, types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}, current)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			resourceDiff.Action = DiffActionCreate
+			resourceDiff.desired, err = marshalDiffContent(desired.Object)
+			if err != nil {
+				return nil, err
+			}
+			return resourceDiff, nil
+		}
+		return nil, err
+	}
+
+	currentJSON, err := json.Marshal(current.Object)
+	if err != nil {
+		return nil, err
+	}
+	desiredJSON, err := json.Marshal(desired.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, err := jsonpatch.CreatePatch(currentJSON, desiredJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range operations {
+		if operations[i].Operation == "remove" {
+			continue
+		}
+		resourceDiff.Patch = append(resourceDiff.Patch, operations[i])
+	}
+
+	resourceDiff.Action = DiffActionNoChange
+	if len(resourceDiff.Patch) != 0 {
+		resourceDiff.Action = DiffActionUpdate
+	}
+
+	resourceDiff.current, err = marshalDiffContent(filterDiffFields(current.Object, desired.Object))
+	if err != nil {
+		return nil, err
+	}
+	resourceDiff.desired, err = marshalDiffContent(desired.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceDiff, nil
+}
+
+// getHelmReleasesDiff compares requested helm charts with those deployed. Releases are compared
+// by their name and namespace instantiated for the cluster.
+func getHelmReleasesDiff(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	deployedFeatures []configv1beta1.Feature, logger logr.Logger) ([]HelmReleaseDiff, error) {
+
+	helmCharts, err := getInstantiatedHelmCharts(ctx, clusterSummary, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make(map[types.NamespacedName]string)
+	for i := range deployedFeatures {
+		if deployedFeatures[i].FeatureID != configv1beta1.FeatureHelm {
+			continue
+		}
+		for j := range deployedFeatures[i].Charts {
+			chart := &deployedFeatures[i].Charts[j]
+			deployed[types.NamespacedName{Namespace: chart.Namespace, Name: chart.ReleaseName}] = chart.ChartVersion
+		}
+	}
+
+	result := make([]HelmReleaseDiff, 0)
+	for i := range helmCharts {
+		chart := &helmCharts[i]
+		key := types.NamespacedName{Namespace: chart.ReleaseNamespace, Name: chart.ReleaseName}
+		deployedVersion, isDeployed := deployed[key]
+		delete(deployed, key)
+
+		releaseDiff := HelmReleaseDiff{
+			ReleaseNamespace: chart.ReleaseNamespace, ReleaseName: chart.ReleaseName,
+			DeployedVersion: deployedVersion,
+		}
+
+		switch {
+		case chart.HelmChartAction == configv1beta1.HelmChartActionUninstall:
+			releaseDiff.Action = DiffActionNoChange
+			if isDeployed {
+				releaseDiff.Action = DiffActionUninstall
+			}
+		case !isDeployed:
+			releaseDiff.Action = DiffActionInstall
+			releaseDiff.DesiredVersion = getDesiredChartVersion(clusterSummary, chart)
+		default:
+			releaseDiff.DesiredVersion = getDesiredChartVersion(clusterSummary, chart)
+			releaseDiff.Action = DiffActionNoChange
+			if releaseDiff.DesiredVersion != deployedVersion {
+				releaseDiff.Action = DiffActionUpgrade
+			}
+		}
+
+		result = append(result, releaseDiff)
+	}
+
+	// Releases deployed and not referenced anymore would be uninstalled
+	for key, version := range deployed {
+		result = append(result, HelmReleaseDiff{
+			ReleaseNamespace: key.Namespace, ReleaseName: key.Name,
+			Action: DiffActionUninstall, DeployedVersion: version,
+		})
+	}
+
+	return result, nil
+}
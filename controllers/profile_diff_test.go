@@ -0,0 +1,159 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Profile diff", func() {
+	It("getResourceDiff reports create, update and no change", func() {
+		configMap := &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{"key": "value"},
+		}
+
+		initObjects := []client.Object{configMap}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+		Expect(err).To(BeNil())
+		desired := &unstructured.Unstructured{Object: content}
+		unstructured.RemoveNestedField(desired.Object, "metadata", "creationTimestamp")
+
+		resourceDiff, err := controllers.GetResourceDiff(context.TODO(), c, desired)
+		Expect(err).To(BeNil())
+		Expect(resourceDiff.Action).To(Equal(controllers.DiffActionNoChange))
+
+		Expect(unstructured.SetNestedField(desired.Object, "new-value", "data", "key")).To(Succeed())
+		resourceDiff, err = controllers.GetResourceDiff(context.TODO(), c, desired)
+		Expect(err).To(BeNil())
+		Expect(resourceDiff.Action).To(Equal(controllers.DiffActionUpdate))
+		Expect(len(resourceDiff.Patch)).To(Equal(1))
+		Expect(resourceDiff.Patch[0].Path).To(Equal("/data/key"))
+
+		desired.SetName(randomString())
+		resourceDiff, err = controllers.GetResourceDiff(context.TODO(), c, desired)
+		Expect(err).To(BeNil())
+		Expect(resourceDiff.Action).To(Equal(controllers.DiffActionCreate))
+	})
+
+	It("getHelmReleasesDiff compares requested and deployed chart versions", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{ReleaseNamespace: "kyverno", ReleaseName: "kyverno", ChartVersion: "v3.0.2"},
+						{ReleaseNamespace: "nginx", ReleaseName: "nginx", ChartVersion: "1.0.0"},
+						{ReleaseNamespace: "gatekeeper", ReleaseName: "gatekeeper", ChartVersion: "3.14.0"},
+					},
+				},
+			},
+		}
+
+		deployed := []configv1beta1.Feature{
+			{
+				FeatureID: configv1beta1.FeatureHelm,
+				Charts: []configv1beta1.Chart{
+					{Namespace: "kyverno", ReleaseName: "kyverno", ChartVersion: "v3.0.1"},
+					{Namespace: "gatekeeper", ReleaseName: "gatekeeper", ChartVersion: "3.14.0"},
+					{Namespace: "prometheus", ReleaseName: "prometheus", ChartVersion: "25.0.0"},
+				},
+			},
+		}
+
+		result, err := controllers.GetHelmReleasesDiff(context.TODO(), clusterSummary, deployed,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		actions := map[string]controllers.DiffAction{}
+		for i := range result {
+			actions[result[i].ReleaseName] = result[i].Action
+		}
+		Expect(actions).To(Equal(map[string]controllers.DiffAction{
+			"kyverno":    controllers.DiffActionUpgrade,
+			"nginx":      controllers.DiffActionInstall,
+			"gatekeeper": controllers.DiffActionNoChange,
+			"prometheus": controllers.DiffActionUninstall,
+		}))
+	})
+
+	It("getHelmReleasesDiff compares releases by their instantiated name and namespace", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sveltosCluster.Namespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(testEnv.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, sveltosCluster)).To(Succeed())
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: sveltosCluster.Namespace,
+				ClusterName:      sveltosCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						{ReleaseNamespace: "ingress", ReleaseName: "{{ .Cluster.metadata.labels.env }}-ingress", ChartVersion: "1.0.0"},
+					},
+				},
+			},
+		}
+
+		deployed := []configv1beta1.Feature{
+			{
+				FeatureID: configv1beta1.FeatureHelm,
+				Charts: []configv1beta1.Chart{
+					{Namespace: "ingress", ReleaseName: "prod-ingress", ChartVersion: "1.0.0"},
+				},
+			},
+		}
+
+		result, err := controllers.GetHelmReleasesDiff(context.TODO(), clusterSummary, deployed,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(len(result)).To(Equal(1))
+		Expect(result[0].ReleaseName).To(Equal("prod-ingress"))
+		Expect(result[0].Action).To(Equal(controllers.DiffActionNoChange))
+	})
+})
@@ -0,0 +1,231 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// namespace, in the managed cluster, where profile ServiceAccounts are created
+	profileIdentityNamespace = "projectsveltos"
+
+	// validity requested for profile ServiceAccount tokens
+	profileIdentityTokenValidity = time.Hour
+
+	// tokens are rotated once less than this is left before expiration
+	profileIdentityTokenRefresh = 20 * time.Minute
+)
+
+type profileIdentityToken struct {
+	token      string
+	expiration time.Time
+}
+
+var (
+	// profileIdentityTokens caches tokens of profile ServiceAccounts.
+	// key: managed cluster and ServiceAccount name
+	profileIdentityTokens   = make(map[string]profileIdentityToken)
+	profileIdentityTokensMu sync.Mutex
+)
+
+// getProfileIdentityName returns the name of the ServiceAccount, in the managed cluster, used to apply
+// the resources of the profile owning clusterSummary
+func getProfileIdentityName(clusterSummary *configv1beta1.ClusterSummary) (string, error) {
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return "", err
+	}
+
+	if profileOwnerRef.Kind == configv1beta1.ProfileKind {
+		// ClusterSummaries created by a Profile are in the Profile namespace
+		return fmt.Sprintf("sveltos-profile-%s-%s", clusterSummary.Namespace, profileOwnerRef.Name), nil
+	}
+
+	return fmt.Sprintf("sveltos-clusterprofile-%s", profileOwnerRef.Name), nil
+}
+
+func getProfileIdentityTokenKey(clusterSummary *configv1beta1.ClusterSummary, name string) string {
+	return fmt.Sprintf("%s:%s/%s:%s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, name)
+}
+
+// ensureProfileIdentity creates, if not existing yet, the profile ServiceAccount in the managed cluster
+// and binds it to cluster-admin. Generated RBAC (see GenerateRBAC) is computed from the resources
+// deployed, so it cannot be used to grant the permissions needed to deploy those very resources.
+func ensureProfileIdentity(ctx context.Context, remoteClient client.Client, name string,
+	clusterSummary *configv1beta1.ClusterSummary) error {
+
+	labels := map[string]string{ClusterSummaryLabelName: clusterSummary.Name}
+
+	ns := &corev1.Namespace{}
+	err := remoteClient.Get(ctx, types.NamespacedName{Name: profileIdentityNamespace}, ns)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		ns.Name = profileIdentityNamespace
+		if err := remoteClient.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: profileIdentityNamespace, Name: name, Labels: labels},
+	}
+	if err := remoteClient.Create(ctx, serviceAccount); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "cluster-admin",
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Namespace: profileIdentityNamespace, Name: name},
+		},
+	}
+	if err := remoteClient.Create(ctx, clusterRoleBinding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+// getProfileIdentityToken returns a valid token for the profile ServiceAccount. A new token is
+// requested when none is cached or the cached one is about to expire.
+func getProfileIdentityToken(ctx context.Context, adminConfig *rest.Config, name string,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) (string, error) {
+
+	key := getProfileIdentityTokenKey(clusterSummary, name)
+
+	profileIdentityTokensMu.Lock()
+	defer profileIdentityTokensMu.Unlock()
+
+	if t, ok := profileIdentityTokens[key]; ok && time.Until(t.expiration) > profileIdentityTokenRefresh {
+		return t.token, nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("requesting token for ServiceAccount %s/%s",
+		profileIdentityNamespace, name))
+
+	clientset, err := kubernetes.NewForConfig(adminConfig)
+	if err != nil {
+		return "", err
+	}
+
+	expirationSeconds := int64(profileIdentityTokenValidity.Seconds())
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}
+	tokenRequest, err = clientset.CoreV1().ServiceAccounts(profileIdentityNamespace).
+		CreateToken(ctx, name, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	profileIdentityTokens[key] = profileIdentityToken{
+		token:      tokenRequest.Status.Token,
+		expiration: tokenRequest.Status.ExpirationTimestamp.Time,
+	}
+
+	return tokenRequest.Status.Token, nil
+}
+
+// getProfileIdentityRestConfig returns the restConfig to access the managed cluster as the profile
+// ServiceAccount. adminConfig is used to create the ServiceAccount and request its tokens.
+func getProfileIdentityRestConfig(ctx context.Context, adminConfig *rest.Config,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) (*rest.Config, error) {
+
+	name, err := getProfileIdentityName(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	adminClient, err := client.New(adminConfig, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = ensureProfileIdentity(ctx, adminClient, name, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getProfileIdentityToken(ctx, adminConfig, name, clusterSummary, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTokenRestConfig(adminConfig, token), nil
+}
+
+// getTokenRestConfig returns a copy of config authenticating with token only
+func getTokenRestConfig(config *rest.Config, token string) *rest.Config {
+	tokenConfig := rest.AnonymousClientConfig(config)
+	tokenConfig.BearerToken = token
+	return tokenConfig
+}
+
+// removeProfileIdentity removes the profile ServiceAccount and its ClusterRoleBinding created
+// for clusterSummary from the managed cluster
+func removeProfileIdentity(ctx context.Context, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+
+	name, err := getProfileIdentityName(clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("removing ServiceAccount %s/%s", profileIdentityNamespace, name))
+
+	profileIdentityTokensMu.Lock()
+	delete(profileIdentityTokens, getProfileIdentityTokenKey(clusterSummary, name))
+	profileIdentityTokensMu.Unlock()
+
+	objects := []client.Object{
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: profileIdentityNamespace, Name: name}},
+	}
+	for i := range objects {
+		if err := remoteClient.Delete(ctx, objects[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Profile identity", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var profileName string
+
+	BeforeEach(func() {
+		profileName = randomString()
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ProfileKind,
+						Name: profileName, UID: "1"},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      "Capi",
+			},
+		}
+	})
+
+	It("getProfileIdentityName returns a name identifying the profile", func() {
+		name, err := controllers.GetProfileIdentityName(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(name).To(Equal("sveltos-profile-" + clusterSummary.Namespace + "-" + profileName))
+
+		clusterSummary.OwnerReferences[0].Kind = configv1beta1.ClusterProfileKind
+		name, err = controllers.GetProfileIdentityName(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(name).To(Equal("sveltos-clusterprofile-" + profileName))
+	})
+
+	It("ensureProfileIdentity and removeProfileIdentity manage ServiceAccount and ClusterRoleBinding", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		name, err := controllers.GetProfileIdentityName(clusterSummary)
+		Expect(err).To(BeNil())
+
+		Expect(controllers.EnsureProfileIdentity(context.TODO(), c, name, clusterSummary)).To(Succeed())
+		// Must be idempotent
+		Expect(controllers.EnsureProfileIdentity(context.TODO(), c, name, clusterSummary)).To(Succeed())
+
+		serviceAccount := &corev1.ServiceAccount{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "projectsveltos", Name: name},
+			serviceAccount)).To(Succeed())
+
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name}, clusterRoleBinding)).To(Succeed())
+		Expect(clusterRoleBinding.Subjects).To(ContainElement(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind,
+			Namespace: "projectsveltos", Name: name}))
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		Expect(controllers.RemoveProfileIdentity(context.TODO(), c, clusterSummary, logger)).To(Succeed())
+		// Must be idempotent
+		Expect(controllers.RemoveProfileIdentity(context.TODO(), c, clusterSummary, logger)).To(Succeed())
+
+		err = c.Get(context.TODO(), types.NamespacedName{Namespace: "projectsveltos", Name: name}, serviceAccount)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = c.Get(context.TODO(), types.NamespacedName{Name: name}, clusterRoleBinding)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("getTokenRestConfig drops any other credential", func() {
+		config := &rest.Config{
+			Host:        "https://10.0.0.1:6443",
+			BearerToken: randomString(),
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData:   []byte(randomString()),
+				CertData: []byte(randomString()),
+				KeyData:  []byte(randomString()),
+			},
+		}
+
+		token := randomString()
+		tokenConfig := controllers.GetTokenRestConfig(config, token)
+		Expect(tokenConfig.Host).To(Equal(config.Host))
+		Expect(tokenConfig.BearerToken).To(Equal(token))
+		Expect(tokenConfig.CAData).To(Equal(config.CAData))
+		Expect(tokenConfig.CertData).To(BeEmpty())
+		Expect(tokenConfig.KeyData).To(BeEmpty())
+	})
+})
@@ -0,0 +1,167 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltosset "github.com/projectsveltos/libsveltos/lib/set"
+)
+
+const (
+	// migrationRequeueAfter is how often a profile being migrated to its successor is reconciled,
+	// so remaining clusters are handed over once the ones being migrated are provisioned
+	migrationRequeueAfter = 10 * time.Second
+)
+
+// getMigratedClusters returns the clusters handed over to the successor profile
+func getMigratedClusters(profileScope *scope.ProfileScope) *libsveltosset.Set {
+	migratedClusters := &libsveltosset.Set{}
+	for i := range profileScope.GetStatus().MigratedClusterRefs {
+		migratedClusters.Insert(&profileScope.GetStatus().MigratedClusterRefs[i])
+	}
+	return migratedClusters
+}
+
+// migrateToSuccessor hands over, for a profile with SupersededBy set, each matching cluster
+// also matching the successor. Handing over a cluster means deleting the ClusterSummary of this
+// profile leaving all resources and helm releases in the cluster, so the successor can take them
+// over without a gap.
+// If MaxUpdate is set, no more than MaxUpdate clusters are migrated in parallel. A cluster is being
+// migrated till the successor ClusterSummary is provisioned.
+// Returns true if migration is not completed yet.
+func migrateToSuccessor(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	logger logr.Logger) (pending bool, err error) {
+
+	successor := profileScope.GetSpec().SupersededBy
+	if successor == "" || successor == profileScope.Name() {
+		profileScope.GetStatus().MigratedClusterRefs = nil
+		return false, nil
+	}
+
+	logger = logger.WithValues("successor", successor)
+
+	matchingClusters := &libsveltosset.Set{}
+	for i := range profileScope.GetStatus().MatchingClusterRefs {
+		matchingClusters.Insert(&profileScope.GetStatus().MatchingClusterRefs[i])
+	}
+
+	// Walk migrated clusters:
+	// - clusters not matching anymore are forgotten;
+	// - clusters the successor is not managing anymore are taken back;
+	// - clusters where successor is not provisioned yet are still being migrated.
+	inProgress := 0
+	migratedClusters := make([]corev1.ObjectReference, 0)
+	for i := range profileScope.GetStatus().MigratedClusterRefs {
+		cluster := &profileScope.GetStatus().MigratedClusterRefs[i]
+		if !matchingClusters.Has(cluster) {
+			continue
+		}
+
+		successorClusterSummary, err := getClusterSummary(ctx, c, profileScope.GetKind(), successor,
+			cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster))
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("successor not managing cluster %s/%s anymore",
+					cluster.Namespace, cluster.Name))
+				continue
+			}
+			return false, err
+		}
+
+		if !isCluterSummaryProvisioned(successorClusterSummary) {
+			inProgress++
+		}
+		migratedClusters = append(migratedClusters, *cluster)
+	}
+	profileScope.GetStatus().MigratedClusterRefs = migratedClusters
+
+	maxUpdate := getMaxUpdate(profileScope)
+	migrated := getMigratedClusters(profileScope)
+	for i := range profileScope.GetStatus().MatchingClusterRefs {
+		cluster := &profileScope.GetStatus().MatchingClusterRefs[i]
+		if migrated.Has(cluster) {
+			continue
+		}
+
+		// Only clusters successor is matching can be handed over
+		_, err := getClusterSummary(ctx, c, profileScope.GetKind(), successor,
+			cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster))
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+
+		if maxUpdate != 0 && inProgress >= int(maxUpdate) {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("Already %d clusters being migrated", inProgress))
+			pending = true
+			continue
+		}
+
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("handing over cluster %s/%s to successor",
+			cluster.Namespace, cluster.Name))
+		if err := releaseClusterSummary(ctx, c, profileScope, cluster); err != nil {
+			return false, err
+		}
+
+		profileScope.GetStatus().MigratedClusterRefs = append(profileScope.GetStatus().MigratedClusterRefs,
+			*cluster)
+		inProgress++
+	}
+
+	return pending || inProgress > 0, nil
+}
+
+// releaseClusterSummary deletes the ClusterSummary for a cluster, leaving deployed resources
+// and helm releases in the cluster
+func releaseClusterSummary(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	cluster *corev1.ObjectReference) error {
+
+	clusterSummary, err := getClusterSummary(ctx, c, profileScope.GetKind(), profileScope.Name(),
+		cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehavior != configv1beta1.LeavePolicies ||
+		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehaviorTemplate != "" {
+
+		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehavior = configv1beta1.LeavePolicies
+		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehaviorTemplate = ""
+		if err := c.Update(ctx, clusterSummary); err != nil {
+			return err
+		}
+	}
+
+	return c.Delete(ctx, clusterSummary)
+}
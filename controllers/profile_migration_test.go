@@ -0,0 +1,223 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Profile migration", func() {
+	It("migrateToSuccessor hands over clusters one by one respecting MaxUpdate", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		maxUpdate := intstr.FromInt32(1)
+
+		successor := &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				HelmCharts: []configv1beta1.HelmChart{
+					{RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: "1.0.0",
+						ReleaseName: randomString(), ReleaseNamespace: randomString()},
+				},
+			},
+		}
+		deprecated := &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				SupersededBy: successor.Name,
+				MaxUpdate:    &maxUpdate,
+			},
+		}
+
+		clusters := []corev1.ObjectReference{
+			{Namespace: randomString(), Name: randomString(), APIVersion: clusterv1.GroupVersion.String(), Kind: clusterKind},
+			{Namespace: randomString(), Name: randomString(), APIVersion: clusterv1.GroupVersion.String(), Kind: clusterKind},
+			{Namespace: randomString(), Name: randomString(), APIVersion: clusterv1.GroupVersion.String(), Kind: clusterKind},
+		}
+		deprecated.Status.MatchingClusterRefs = clusters
+
+		initObjects := []client.Object{deprecated, successor}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithStatusSubresource(&configv1beta1.ClusterSummary{}).WithObjects(initObjects...).Build()
+
+		deprecatedScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: deprecated, ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		successorScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: successor, ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		for i := range clusters {
+			Expect(controllers.CreateClusterSummary(context.TODO(), c, deprecatedScope, &clusters[i])).To(Succeed())
+		}
+		// successor does not match last cluster
+		for i := range clusters[:2] {
+			Expect(controllers.CreateClusterSummary(context.TODO(), c, successorScope, &clusters[i])).To(Succeed())
+		}
+
+		getClusterSummary := func(profileName string, cluster *corev1.ObjectReference) (*configv1beta1.ClusterSummary, error) {
+			return controllers.GetClusterSummary(context.TODO(), c, configv1beta1.ClusterProfileKind, profileName,
+				cluster.Namespace, cluster.Name, libsveltosv1beta1.ClusterTypeCapi)
+		}
+
+		setProvisioned := func(cluster *corev1.ObjectReference) {
+			cs, err := getClusterSummary(successor.Name, cluster)
+			Expect(err).To(BeNil())
+			cs.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+				{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+			}
+			Expect(c.Status().Update(context.TODO(), cs)).To(Succeed())
+		}
+
+		// First cluster is handed over
+		pending, err := controllers.MigrateToSuccessor(context.TODO(), c, deprecatedScope, logger)
+		Expect(err).To(BeNil())
+		Expect(pending).To(BeTrue())
+		Expect(deprecated.Status.MigratedClusterRefs).To(Equal(clusters[:1]))
+		_, err = getClusterSummary(deprecated.Name, &clusters[0])
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = getClusterSummary(deprecated.Name, &clusters[1])
+		Expect(err).To(BeNil())
+
+		// Till successor is provisioned in the first cluster, second one is not handed over
+		pending, err = controllers.MigrateToSuccessor(context.TODO(), c, deprecatedScope, logger)
+		Expect(err).To(BeNil())
+		Expect(pending).To(BeTrue())
+		Expect(deprecated.Status.MigratedClusterRefs).To(HaveLen(1))
+
+		setProvisioned(&clusters[0])
+		pending, err = controllers.MigrateToSuccessor(context.TODO(), c, deprecatedScope, logger)
+		Expect(err).To(BeNil())
+		Expect(pending).To(BeTrue())
+		Expect(deprecated.Status.MigratedClusterRefs).To(Equal(clusters[:2]))
+		_, err = getClusterSummary(deprecated.Name, &clusters[1])
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		setProvisioned(&clusters[1])
+		pending, err = controllers.MigrateToSuccessor(context.TODO(), c, deprecatedScope, logger)
+		Expect(err).To(BeNil())
+		Expect(pending).To(BeFalse())
+
+		// cluster not matched by successor is still managed by deprecated profile
+		_, err = getClusterSummary(deprecated.Name, &clusters[2])
+		Expect(err).To(BeNil())
+	})
+
+	It("reconcileNormalCommon requeues, with no error, while migration to successor is in progress", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		cluster := corev1.ObjectReference{Namespace: randomString(), Name: randomString(),
+			APIVersion: clusterv1.GroupVersion.String(), Kind: clusterKind}
+
+		successor := &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		deprecated := &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec:       configv1beta1.Spec{SupersededBy: successor.Name},
+			Status:     configv1beta1.Status{MatchingClusterRefs: []corev1.ObjectReference{cluster}},
+		}
+
+		// Cluster is not ready, so ClusterSummaries are not updated
+		capiCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name},
+		}
+		clusterConfiguration := &configv1beta1.ClusterConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      controllers.GetClusterConfigurationName(cluster.Name, libsveltosv1beta1.ClusterTypeCapi),
+			},
+		}
+
+		initObjects := []client.Object{deprecated, successor, capiCluster, clusterConfiguration}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithStatusSubresource(&configv1beta1.ClusterSummary{}, deprecated, clusterConfiguration).WithObjects(initObjects...).Build()
+
+		deprecatedScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: deprecated, ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		successorScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: successor, ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		Expect(controllers.CreateClusterSummary(context.TODO(), c, deprecatedScope, &cluster)).To(Succeed())
+		Expect(controllers.CreateClusterSummary(context.TODO(), c, successorScope, &cluster)).To(Succeed())
+
+		// Cluster is handed over, but successor is not provisioned yet
+		result, err := controllers.ReconcileNormalCommon(context.TODO(), c, deprecatedScope, logger)
+		Expect(err).To(BeNil())
+		Expect(result.RequeueAfter).ToNot(BeZero())
+		Expect(deprecated.Status.MigratedClusterRefs).To(Equal([]corev1.ObjectReference{cluster}))
+	})
+
+	It("releaseClusterSummary leaves resources in the cluster", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta:   metav1.TypeMeta{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		cluster := &corev1.ObjectReference{Namespace: randomString(), Name: randomString(),
+			APIVersion: clusterv1.GroupVersion.String(), Kind: clusterKind}
+
+		initObjects := []client.Object{clusterProfile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: logger, Profile: clusterProfile, ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		Expect(controllers.CreateClusterSummary(context.TODO(), c, profileScope, cluster)).To(Succeed())
+
+		// Add a finalizer so ClusterSummary is only marked for deletion
+		cs, err := controllers.GetClusterSummary(context.TODO(), c, configv1beta1.ClusterProfileKind,
+			clusterProfile.Name, cluster.Namespace, cluster.Name, libsveltosv1beta1.ClusterTypeCapi)
+		Expect(err).To(BeNil())
+		cs.Finalizers = []string{configv1beta1.ClusterSummaryFinalizer}
+		Expect(c.Update(context.TODO(), cs)).To(Succeed())
+
+		Expect(controllers.ReleaseClusterSummary(context.TODO(), c, profileScope, cluster)).To(Succeed())
+
+		cs, err = controllers.GetClusterSummary(context.TODO(), c, configv1beta1.ClusterProfileKind,
+			clusterProfile.Name, cluster.Namespace, cluster.Name, libsveltosv1beta1.ClusterTypeCapi)
+		Expect(err).To(BeNil())
+		Expect(cs.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(cs.Spec.ClusterProfileSpec.StopMatchingBehavior).To(Equal(configv1beta1.LeavePolicies))
+	})
+})
@@ -0,0 +1,219 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ProfileSimulationPath is the path the profile simulation endpoint is served on
+const ProfileSimulationPath = "/profile-simulation"
+
+// SimulatedCluster is a cluster a profile would have matched at the simulated time
+type SimulatedCluster struct {
+	Cluster corev1.ObjectReference `json:"cluster"`
+	Labels  map[string]string      `json:"labels,omitempty"`
+	Version string                 `json:"version,omitempty"`
+	// SnapshotTime is when the cluster metadata used for the simulation was recorded
+	SnapshotTime metav1.Time `json:"snapshotTime"`
+}
+
+// ProfileSimulation contains the clusters a profile spec would have matched at Time
+type ProfileSimulation struct {
+	Time             metav1.Time        `json:"time"`
+	MatchingClusters []SimulatedCluster `json:"matchingClusters"`
+}
+
+type profileSimulationHandler struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// NewProfileSimulationHandler returns an http.Handler evaluating which clusters a ClusterProfile/Profile
+// would have matched at a point in time, using the cluster snapshots (see RecordClusterSnapshots).
+// Query parameters:
+// - kind (ClusterProfile or Profile), name and, for Profile, namespace identify the profile;
+// - time (RFC3339) is the point in time to simulate.
+// With GET the current profile spec is used. With POST the request body is a Spec used in place
+// of the current one. Only ClusterSelector and ClusterRefs are simulated.
+func NewProfileSimulationHandler(c client.Client, logger logr.Logger) http.Handler {
+	return &profileSimulationHandler{c: c, logger: logger}
+}
+
+func (h *profileSimulationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("kind")
+	if kind != configv1beta1.ClusterProfileKind && kind != configv1beta1.ProfileKind {
+		http.Error(w, fmt.Sprintf("kind must be %s or %s", configv1beta1.ClusterProfileKind,
+			configv1beta1.ProfileKind), http.StatusBadRequest)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, query.Get("time"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("time must be in RFC3339 format: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	const timeout = time.Minute
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	spec, err := h.getSpec(ctx, r, kind)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	namespace := ""
+	if kind == configv1beta1.ProfileKind {
+		namespace = query.Get("namespace")
+	}
+
+	simulation, err := simulateProfile(ctx, h.c, namespace, spec, at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(simulation)
+	if err != nil {
+		h.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write response: %v", err))
+	}
+}
+
+func (h *profileSimulationHandler) getSpec(ctx context.Context, r *http.Request, kind string,
+) (*configv1beta1.Spec, error) {
+
+	if r.Method == http.MethodPost {
+		spec := &configv1beta1.Spec{}
+		if err := json.NewDecoder(r.Body).Decode(spec); err != nil {
+			return nil, fmt.Errorf("invalid spec: %w", err)
+		}
+		return spec, nil
+	}
+
+	query := r.URL.Query()
+	profileKey := types.NamespacedName{Name: query.Get("name")}
+	if kind == configv1beta1.ClusterProfileKind {
+		clusterProfile := &configv1beta1.ClusterProfile{}
+		if err := h.c.Get(ctx, profileKey, clusterProfile); err != nil {
+			return nil, err
+		}
+		return &clusterProfile.Spec, nil
+	}
+
+	profileKey.Namespace = query.Get("namespace")
+	profile := &configv1beta1.Profile{}
+	if err := h.c.Get(ctx, profileKey, profile); err != nil {
+		return nil, err
+	}
+	return &profile.Spec, nil
+}
+
+// simulateProfile returns the clusters spec would have matched at time at. If namespace is
+// set (Profile), only clusters in that namespace can match.
+func simulateProfile(ctx context.Context, c client.Client, namespace string, spec *configv1beta1.Spec,
+	at time.Time) (*ProfileSimulation, error) {
+
+	selector := labels.Nothing()
+	clusterSelector := &spec.ClusterSelector.LabelSelector
+	if len(clusterSelector.MatchLabels)+len(clusterSelector.MatchExpressions) != 0 {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(clusterSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	err := c.List(ctx, configMaps, client.InNamespace(projectsveltos),
+		client.HasLabels{clusterSnapshotLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &ProfileSimulation{
+		Time:             metav1.NewTime(at),
+		MatchingClusters: make([]SimulatedCluster, 0),
+	}
+	for i := range configMaps.Items {
+		var clusterRef *corev1.ObjectReference
+		var snapshots []clusterSnapshot
+		clusterRef, snapshots, err = getClusterSnapshots(&configMaps.Items[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if namespace != "" && clusterRef.Namespace != namespace {
+			continue
+		}
+
+		snapshot := getClusterSnapshotAt(snapshots, at)
+		if snapshot == nil {
+			continue
+		}
+
+		if selector.Matches(labels.Set(snapshot.Labels)) || isReferencedCluster(spec.ClusterRefs, clusterRef) {
+			simulation.MatchingClusters = append(simulation.MatchingClusters, SimulatedCluster{
+				Cluster:      *clusterRef,
+				Labels:       snapshot.Labels,
+				Version:      snapshot.Version,
+				SnapshotTime: snapshot.Time,
+			})
+		}
+	}
+
+	return simulation, nil
+}
+
+func isReferencedCluster(clusterRefs []corev1.ObjectReference, clusterRef *corev1.ObjectReference) bool {
+	for i := range clusterRefs {
+		if clusterRefs[i].Namespace == clusterRef.Namespace && clusterRefs[i].Name == clusterRef.Name &&
+			clusterproxy.GetClusterType(&clusterRefs[i]) == clusterproxy.GetClusterType(clusterRef) {
+
+			return true
+		}
+	}
+	return false
+}
@@ -18,12 +18,15 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
 func (r *ProfileReconciler) requeueProfileForSveltosCluster(
@@ -77,3 +80,60 @@ func (r *ProfileReconciler) requeueProfileForSet(
 
 	return requeueForSet(set, r.SetMap, configv1beta1.ProfileKind, r.Logger)
 }
+
+// requeueProfileForReferenceGrant requeues all Profiles in the namespaces a ReferenceGrant
+// grants (or used to grant) access to.
+func (r *ProfileReconciler) requeueProfileForReferenceGrant(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	grant, ok := o.(*configv1beta1.ReferenceGrant)
+	if !ok {
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range grant.Spec.From {
+		profiles := &configv1beta1.ProfileList{}
+		if err := r.List(ctx, profiles, client.InNamespace(grant.Spec.From[i].Namespace)); err != nil {
+			r.Logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list Profiles: %v", err))
+			continue
+		}
+		for j := range profiles.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: profiles.Items[j].Namespace,
+					Name:      profiles.Items[j].Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// requeueProfileForParent requeues all Profiles having the Profile as ParentProfile
+func (r *ProfileReconciler) requeueProfileForParent(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := r.List(ctx, profiles, client.InNamespace(o.GetNamespace())); err != nil {
+		r.Logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list Profiles: %v", err))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range profiles.Items {
+		if profiles.Items[i].Spec.ParentProfile == o.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: profiles.Items[i].Namespace,
+					Name:      profiles.Items[i].Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
@@ -23,6 +23,7 @@ import (
 	"math"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/dariubs/percent"
 	"github.com/gdexlab/go-render/render"
@@ -30,6 +31,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -37,6 +39,7 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
@@ -541,6 +544,12 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 
 	maxUpdate := getMaxUpdate(profileScope)
 
+	// Clusters handed over to the successor are not managed by this profile anymore
+	migratedClusters := getMigratedClusters(profileScope)
+
+	// Clusters which opted out of management are reported in the ClustersUnmanaged condition
+	unmanagedClusters := make([]corev1.ObjectReference, 0)
+
 	skippedUpdate := false
 	// Consider matchingCluster number and MaxUpdate, walk remaining matching clusters.  If more clusters can be
 	// updated, update ClusterSummary and add it to UpdatingClusters
@@ -550,6 +559,23 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 		logger := profileScope.Logger
 		logger = logger.WithValues("cluster", fmt.Sprintf("%s:%s/%s", cluster.Kind, cluster.Namespace, cluster.Name))
 
+		if migratedClusters.Has(&cluster) {
+			logger.V(logs.LogDebug).Info("Cluster has been handed over to successor")
+			continue
+		}
+
+		unmanaged, err := isClusterUnmanaged(ctx, c, cluster.Namespace, cluster.Name,
+			clusterproxy.GetClusterType(&cluster))
+		if err != nil {
+			return err
+		}
+		if unmanaged {
+			// ClusterSummary, if any, is left untouched
+			logger.V(logs.LogDebug).Info("Cluster has opted out of management")
+			unmanagedClusters = append(unmanagedClusters, cluster)
+			continue
+		}
+
 		ready, err := clusterproxy.IsClusterReadyToBeConfigured(ctx, c, &cluster, profileScope.Logger)
 		if err != nil {
 			return err
@@ -582,9 +608,14 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 
 		// if maxUpdate is set no more than maxUpdate clusters can be updated in parallel by ClusterProfile
 		if maxUpdate != 0 && !updatingClusters.Has(&cluster) && updatingClusters.Len() >= int(maxUpdate) {
-			logger.V(logs.LogDebug).Info(fmt.Sprintf("Already %d being updating", updatingClusters.Len()))
-			skippedUpdate = true
-			continue
+			bg := getActiveBreakGlass(profileScope.Profile.GetAnnotations(), cluster.Namespace, cluster.Name,
+				clusterproxy.GetClusterType(&cluster), time.Now(), logger)
+			if bg == nil {
+				logger.V(logs.LogDebug).Info(fmt.Sprintf("Already %d being updating", updatingClusters.Len()))
+				skippedUpdate = true
+				continue
+			}
+			recordBreakGlass(bg, "MaxUpdate", logger)
 		}
 
 		// ClusterProfile does not look at whether Cluster is paused or not.
@@ -606,6 +637,8 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 		profileScope.GetStatus().UpdatingClusters.Hash = currentHash
 	}
 
+	updateUnmanagedClustersCondition(profileScope, unmanagedClusters)
+
 	if skippedUpdate {
 		return fmt.Errorf("not all clusters updated yet. %d still being updated",
 			len(profileScope.GetStatus().UpdatingClusters.Clusters))
@@ -657,8 +690,14 @@ func cleanClusterSummaries(ctx context.Context, c client.Client, profileScope *s
 		return fmt.Sprintf("%s-%s-%s", clusterType, clusterNamespace, clusterName)
 	}
 
+	// Clusters handed over to the successor are not managed by this profile anymore
+	migratedClusters := getMigratedClusters(profileScope)
+
 	for i := range profileScope.GetStatus().MatchingClusterRefs {
 		reference := profileScope.GetStatus().MatchingClusterRefs[i]
+		if migratedClusters.Has(&reference) {
+			continue
+		}
 		clusterName := getClusterInfo(reference.Namespace, reference.Name, clusterproxy.GetClusterType(&reference))
 		matching[clusterName] = true
 	}
@@ -877,9 +916,15 @@ func getProfileSpecHash(profileScope *scope.ProfileScope) []byte {
 // - UpdatedClusters represents list of matching clusters already updated since last ClusterProfile/Profile change
 // - UpdatingClusters represents list of matching clusters being updated since last ClusterProfile/Profile change
 func reviseUpdatedAndUpdatingClusters(profileScope *scope.ProfileScope) {
+	// Clusters handed over to the successor are treated as not matching
+	migratedClusters := getMigratedClusters(profileScope)
+
 	matchingCluster := libsveltosset.Set{}
 	for i := range profileScope.GetStatus().MatchingClusterRefs {
 		cluster := profileScope.GetStatus().MatchingClusterRefs[i]
+		if migratedClusters.Has(&cluster) {
+			continue
+		}
 		matchingCluster.Insert(&cluster)
 	}
 
@@ -1007,6 +1052,13 @@ func addFinalizer(ctx context.Context, profileScope *scope.ProfileScope, finaliz
 func reconcileDeleteCommon(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
 	finalizer string, logger logr.Logger) error {
 
+	// The finalizer is held till deletion of a ClusterProfile/Profile with DeletionProtection
+	// is confirmed. This does not rely on the validating webhooks being served.
+	if err := guardProfileDeletion(profileScope); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("deletion blocked: %v", err))
+		return err
+	}
+
 	profileScope.SetMatchingClusterRefs(nil)
 
 	if err := cleanClusterSummaries(ctx, c, profileScope); err != nil {
@@ -1041,48 +1093,92 @@ func reconcileDeleteCommon(ctx context.Context, c client.Client, profileScope *s
 		controllerutil.RemoveFinalizer(profile, finalizer)
 	}
 
+	forgetCommitStatus(profileScope)
+
 	return nil
 }
 
 func reconcileNormalCommon(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
-	logger logr.Logger) error {
+	logger logr.Logger) (reconcile.Result, error) {
+
+	updateSpecWarningsCondition(profileScope)
+
+	// An invalid Spec is never propagated to ClusterSummaries. Validation does not rely on the
+	// validating webhooks being served.
+	if err := updateSpecInvalidCondition(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("invalid spec: %v", err))
+		return reconcile.Result{}, err
+	}
+
+	if err := guardHelmChartsRemoval(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("helm charts removal blocked: %v", err))
+		return reconcile.Result{}, err
+	}
 
 	// For each matching Sveltos/Cluster, create/update corresponding ClusterConfiguration
 	if err := updateClusterConfigurations(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterConfigurations")
-		return err
+		return reconcile.Result{}, err
 	}
 	// For each matching Sveltos/Cluster, create or delete corresponding ClusterReport if needed
 	if err := updateClusterReports(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterReports")
-		return err
+		return reconcile.Result{}, err
+	}
+	// If profile is superseded, hand over matching clusters to the successor
+	migrationPending, err := migrateToSuccessor(ctx, c, profileScope, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to migrate to successor")
+		return reconcile.Result{}, err
 	}
 	// For each matching Sveltos/Cluster, create/update corresponding ClusterSummary
-	if err := updateClusterSummaries(ctx, c, profileScope); err != nil {
+	err = updateClusterSummaries(ctx, c, profileScope)
+	if summaryErr := updateProfileStatusSummary(ctx, c, profileScope); summaryErr != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update status summary: %v", summaryErr))
+	}
+	// If profile was created from a Git commit, report rollout state back to the Git provider
+	pending := reportCommitStatus(ctx, c, profileScope, err, logger)
+	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterSummaries")
-		return err
+		return reconcile.Result{}, err
 	}
 
 	// For Sveltos/Cluster not matching, deletes corresponding ClusterSummary
 	if err := cleanClusterSummaries(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to clean ClusterSummaries")
-		return err
+		return reconcile.Result{}, err
 	}
 
 	// For Sveltos/Cluster not matching, removes ClusterProfile/Profile as OwnerReference
 	// from corresponding ClusterConfiguration
 	if err := cleanClusterConfigurations(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to clean ClusterConfigurations")
-		return err
+		return reconcile.Result{}, err
 	}
 
 	// For Sveltos/Cluster not matching, remove ClusterReports
 	if err := cleanClusterReports(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to clean ClusterConfigurations")
-		return err
+		return reconcile.Result{}, err
 	}
 
-	return nil
+	result := reconcile.Result{}
+	if pending {
+		// Requeue so that final rollout state is reported once reached
+		logger.V(logs.LogDebug).Info("rollout of commit still in progress")
+		result.RequeueAfter = commitStatusRequeueAfter
+	}
+
+	if migrationPending {
+		// Requeue so that remaining clusters are handed over to the successor
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("migration to %s still in progress",
+			profileScope.GetSpec().SupersededBy))
+		if result.RequeueAfter == 0 || migrationRequeueAfter < result.RequeueAfter {
+			result.RequeueAfter = migrationRequeueAfter
+		}
+	}
+
+	return result, nil
 }
 
 func getCurrentClusterSet(matchingClusterRefs []corev1.ObjectReference) *libsveltosset.Set {
@@ -1110,3 +1206,71 @@ func getConsumersForEntry(currentMap map[corev1.ObjectReference]*libsveltosset.S
 	}
 	return s
 }
+
+// validateProfileSpec verifies a ClusterProfile/Profile Spec. For a Profile, ConfigMaps/Secrets
+// referenced in other namespaces must be granted by a ReferenceGrant. Used both by the reconcilers
+// and by the validating webhooks.
+func validateProfileSpec(ctx context.Context, c client.Client, kind, namespace string,
+	annotations map[string]string, spec *configv1beta1.Spec) error {
+
+	validations := []func(*configv1beta1.Spec) error{
+		validateInlineResources,
+		validateGuardrails,
+		validateHelmChartSources,
+		validateHelmChartVersions,
+		validateHelmChartVerification,
+		validateDependencyRepositories,
+		validateSecretRotationHooks,
+		validateStopMatchingBehaviorTemplate,
+		validateSecretTransformer,
+		validateVariables,
+		validateWasmPlugins,
+	}
+
+	if err := validateBreakGlass(annotations); err != nil {
+		return err
+	}
+
+	for i := range validations {
+		if err := validations[i](spec); err != nil {
+			return err
+		}
+	}
+
+	if kind != configv1beta1.ProfileKind {
+		return nil
+	}
+
+	features := []configv1beta1.FeatureID{configv1beta1.FeatureResources, configv1beta1.FeatureKustomize,
+		configv1beta1.FeatureHelm, configv1beta1.FeatureJobs}
+	for i := range features {
+		if err := validateProfileReferences(ctx, c, namespace, spec, features[i], nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSpecInvalidCondition validates the ClusterProfile/Profile Spec and sets the SpecInvalidCondition.
+// Returns the validation error, if any.
+func updateSpecInvalidCondition(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	condition := metav1.Condition{
+		Type:               configv1beta1.SpecInvalidCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.SpecValidReason,
+		Message:            "spec is valid",
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+
+	err := validateProfileSpec(ctx, c, profileScope.GetKind(), profileScope.Namespace(),
+		profileScope.Profile.GetAnnotations(), profileScope.GetSpec())
+	if err != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.SpecValidationFailedReason
+		condition.Message = err.Error()
+	}
+
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+	return err
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -624,6 +625,48 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(len(clusterSummaryList.Items)).To(Equal(0))
 	})
 
+	It("updateClusterSummaries does not create ClusterSummary for matching unmanaged Cluster", func() {
+		clusterProfile.Status.MatchingClusterRefs = []corev1.ObjectReference{
+			{
+				Namespace:  matchingCluster.Namespace,
+				Name:       matchingCluster.Name,
+				Kind:       clusterKind,
+				APIVersion: clusterv1.GroupVersion.String(),
+			},
+		}
+
+		matchingCluster.Status.ControlPlaneReady = true
+		matchingCluster.Labels[controllers.UnmanagedLabel] = "true"
+
+		initObjects := []client.Object{
+			clusterProfile,
+			nonMatchingCluster,
+			matchingCluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		clusterProfileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         logger,
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		err = controllers.UpdateClusterSummaries(context.TODO(), c, clusterProfileScope)
+		Expect(err).To(BeNil())
+
+		clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+		Expect(c.List(context.TODO(), clusterSummaryList)).To(BeNil())
+		Expect(len(clusterSummaryList.Items)).To(Equal(0))
+
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.ClustersUnmanagedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(matchingCluster.Name))
+	})
+
 	It("updateClusterSummaries does not create ClusterSummary for matching Paused Cluster", func() {
 		maxUpdate := int32(3)
 		clusterProfile.Spec.MaxUpdate = &intstr.IntOrString{Type: intstr.Int, IntVal: maxUpdate}
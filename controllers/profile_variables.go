@@ -0,0 +1,118 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+// convertVariableValue converts value to the type declared for variable
+func convertVariableValue(variable *configv1beta1.Variable, value string) (interface{}, error) {
+	switch variable.Type {
+	case configv1beta1.VariableTypeString, "":
+		return value, nil
+	case configv1beta1.VariableTypeInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a valid Int", variable.Name, value)
+		}
+		return v, nil
+	case configv1beta1.VariableTypeBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a valid Bool", variable.Name, value)
+		}
+		return v, nil
+	case configv1beta1.VariableTypeEnum:
+		if !slices.Contains(variable.Enum, value) {
+			return nil, fmt.Errorf("variable %s: %q is not one of %v", variable.Name, value, variable.Enum)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("variable %s: unknown type %s", variable.Name, variable.Type)
+	}
+}
+
+// getVariableZeroValue returns the value passed to templates for a variable with neither
+// Value nor Default set
+func getVariableZeroValue(variable *configv1beta1.Variable) interface{} {
+	switch variable.Type {
+	case configv1beta1.VariableTypeInt:
+		return int64(0)
+	case configv1beta1.VariableTypeBool:
+		return false
+	default:
+		return ""
+	}
+}
+
+// getVariableValue returns the typed value of variable. Value takes precedence over Default.
+func getVariableValue(variable *configv1beta1.Variable) (interface{}, error) {
+	if variable.Type == configv1beta1.VariableTypeEnum && len(variable.Enum) == 0 {
+		return nil, fmt.Errorf("variable %s: enum must be set for type Enum", variable.Name)
+	}
+	if variable.Type != configv1beta1.VariableTypeEnum && len(variable.Enum) != 0 {
+		return nil, fmt.Errorf("variable %s: enum is only allowed for type Enum", variable.Name)
+	}
+
+	if variable.Default != nil {
+		// Default is validated even when Value is set
+		if _, err := convertVariableValue(variable, *variable.Default); err != nil {
+			return nil, fmt.Errorf("invalid default: %w", err)
+		}
+	}
+
+	switch {
+	case variable.Value != nil:
+		return convertVariableValue(variable, *variable.Value)
+	case variable.Default != nil:
+		return convertVariableValue(variable, *variable.Default)
+	case variable.Required:
+		return nil, fmt.Errorf("variable %s is required but neither value nor default is set", variable.Name)
+	default:
+		return getVariableZeroValue(variable), nil
+	}
+}
+
+// getTemplateVariables returns the typed values of variables, keyed by variable name.
+// Templates access those as .Variables.<name>
+func getTemplateVariables(variables []configv1beta1.Variable) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(variables))
+	for i := range variables {
+		if _, ok := result[variables[i].Name]; ok {
+			return nil, fmt.Errorf("variable %s is declared more than once", variables[i].Name)
+		}
+
+		value, err := getVariableValue(&variables[i])
+		if err != nil {
+			return nil, err
+		}
+		result[variables[i].Name] = value
+	}
+
+	return result, nil
+}
+
+// validateVariables verifies provided values and defaults match the variables declaration
+func validateVariables(spec *configv1beta1.Spec) error {
+	_, err := getTemplateVariables(spec.Variables)
+	return err
+}
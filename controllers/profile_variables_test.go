@@ -0,0 +1,83 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Profile variables", func() {
+	toPointer := func(s string) *string {
+		return &s
+	}
+
+	It("getTemplateVariables converts values and defaults to their declared type", func() {
+		variables := []configv1beta1.Variable{
+			{Name: "region", Type: configv1beta1.VariableTypeString, Value: toPointer("eu-west-1")},
+			{Name: "replicas", Type: configv1beta1.VariableTypeInt, Value: toPointer("3"), Default: toPointer("1")},
+			{Name: "monitoring", Type: configv1beta1.VariableTypeBool, Default: toPointer("true")},
+			{Name: "tier", Type: configv1beta1.VariableTypeEnum, Enum: []string{"gold", "silver"},
+				Value: toPointer("gold")},
+			{Name: "optional", Type: configv1beta1.VariableTypeInt},
+			{Name: "untyped", Value: toPointer("value")},
+		}
+
+		result, err := controllers.GetTemplateVariables(variables)
+		Expect(err).To(BeNil())
+		Expect(result).To(HaveKeyWithValue("region", "eu-west-1"))
+		Expect(result).To(HaveKeyWithValue("replicas", int64(3)))
+		Expect(result).To(HaveKeyWithValue("monitoring", true))
+		Expect(result).To(HaveKeyWithValue("tier", "gold"))
+		Expect(result).To(HaveKeyWithValue("optional", int64(0)))
+		Expect(result).To(HaveKeyWithValue("untyped", "value"))
+	})
+
+	It("validateVariables rejects values not matching the declaration", func() {
+		spec := &configv1beta1.Spec{}
+		Expect(controllers.ValidateVariables(spec)).To(Succeed())
+
+		invalid := []configv1beta1.Variable{
+			{Name: "replicas", Type: configv1beta1.VariableTypeInt, Value: toPointer("three")},
+			{Name: "replicas", Type: configv1beta1.VariableTypeInt, Value: toPointer("3"), Default: toPointer("one")},
+			{Name: "monitoring", Type: configv1beta1.VariableTypeBool, Value: toPointer("maybe")},
+			{Name: "tier", Type: configv1beta1.VariableTypeEnum, Enum: []string{"gold"}, Value: toPointer("bronze")},
+			{Name: "tier", Type: configv1beta1.VariableTypeEnum, Value: toPointer("gold")},
+			{Name: "region", Type: configv1beta1.VariableTypeString, Enum: []string{"eu"}},
+			{Name: "region", Type: configv1beta1.VariableTypeString, Required: true},
+		}
+
+		for i := range invalid {
+			spec.Variables = []configv1beta1.Variable{invalid[i]}
+			Expect(controllers.ValidateVariables(spec)).ToNot(Succeed())
+		}
+
+		spec.Variables = []configv1beta1.Variable{
+			{Name: "region", Value: toPointer("eu")},
+			{Name: "region", Value: toPointer("us")},
+		}
+		Expect(controllers.ValidateVariables(spec)).ToNot(Succeed())
+
+		spec.Variables = []configv1beta1.Variable{
+			{Name: "region", Type: configv1beta1.VariableTypeString, Required: true, Default: toPointer("eu")},
+		}
+		Expect(controllers.ValidateVariables(spec)).To(Succeed())
+	})
+})
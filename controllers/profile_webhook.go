@@ -0,0 +1,100 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+//nolint: lll // marker
+//+kubebuilder:webhook:path=/validate-config-projectsveltos-io-v1beta1-profile,mutating=false,failurePolicy=fail,sideEffects=None,groups=config.projectsveltos.io,resources=profiles,verbs=create;update;delete,versions=v1beta1,name=vprofile.projectsveltos.io,admissionReviewVersions=v1
+
+// ProfileValidator rejects Profiles referencing ConfigMaps/Secrets in other namespaces
+// not granted by a ReferenceGrant, Profiles with invalid InlineResources and deletions of
+// Profiles with DeletionProtection not confirmed. Common pitfalls are reported as warnings.
+type ProfileValidator struct {
+	Client client.Client
+}
+
+// SetupWebhookWithManager registers the Profile validating webhook with the manager.
+func (v *ProfileValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&configv1beta1.Profile{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *ProfileValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if err := v.validate(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	profile, ok := obj.(*configv1beta1.Profile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Profile but got %T", obj)
+	}
+	return lintSpec(&profile.Spec), nil
+}
+
+func (v *ProfileValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	if err := v.validate(ctx, newObj); err != nil {
+		return nil, err
+	}
+
+	oldProfile, ok := oldObj.(*configv1beta1.Profile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Profile but got %T", oldObj)
+	}
+	profile, ok := newObj.(*configv1beta1.Profile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Profile but got %T", newObj)
+	}
+	if err := validateHelmChartsRemoval(configv1beta1.ProfileKind, profile.Namespace+"/"+profile.Name,
+		profile.Annotations, &oldProfile.Spec, &profile.Spec); err != nil {
+		return nil, err
+	}
+
+	return lintSpec(&profile.Spec), nil
+}
+
+func (v *ProfileValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	profile, ok := obj.(*configv1beta1.Profile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Profile but got %T", obj)
+	}
+
+	return nil, validateProfileDeletion(configv1beta1.ProfileKind, profile.Namespace+"/"+profile.Name,
+		profile.Annotations, &profile.Spec)
+}
+
+func (v *ProfileValidator) validate(ctx context.Context, obj runtime.Object) error {
+	profile, ok := obj.(*configv1beta1.Profile)
+	if !ok {
+		return fmt.Errorf("expected a Profile but got %T", obj)
+	}
+
+	return validateProfileSpec(ctx, v.Client, configv1beta1.ProfileKind, profile.Namespace,
+		profile.Annotations, &profile.Spec)
+}
@@ -0,0 +1,538 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	promotionStaging    = "staging"
+	promotionProduction = "production"
+
+	// promotionSpecKey is the key, in the revision ConfigMap, containing the revision Spec
+	promotionSpecKey = "spec.yaml"
+
+	// promotionRevisionLength is the number of hex digits of a revision
+	promotionRevisionLength = 12
+
+	// promotionRequeueAfter is how often Promotions are reconciled, so that changes to the
+	// ConfigMaps/Secrets referenced by the ClusterProfile produce a new revision
+	promotionRequeueAfter = time.Minute
+)
+
+// PromotionReconciler reconciles a Promotion object
+type PromotionReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+	Logger               logr.Logger
+}
+
+// promotionReference is a ConfigMap/Secret referenced by a revision Spec
+type promotionReference struct {
+	kind      string
+	namespace string
+	// name points to the field of the Spec containing the name, so the reference can be
+	// redirected to the revision copy
+	name *string
+}
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=promotions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=promotions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterprofiles,verbs=create;update;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=create;delete
+
+func (r *PromotionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(logs.LogInfo).Info("Reconciling")
+
+	promotion := &configv1beta1.Promotion{}
+	if err := r.Get(ctx, req.NamespacedName, promotion); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Generated ClusterProfiles and ConfigMaps/Secrets are garbage collected (OwnerReference)
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, "Failed to fetch Promotion")
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to fetch Promotion %s", req.NamespacedName)
+	}
+
+	if !promotion.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	err := r.reconcilePromotion(ctx, promotion, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to reconcile promotion: %v", err))
+		failureMessage := err.Error()
+		promotion.Status.FailureMessage = &failureMessage
+	} else {
+		promotion.Status.FailureMessage = nil
+	}
+
+	if err := r.Status().Update(ctx, promotion); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update status: %v", err))
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	logger.V(logs.LogInfo).Info("Reconcile success")
+	return reconcile.Result{RequeueAfter: promotionRequeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PromotionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		For(&configv1beta1.Promotion{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Watches(&configv1beta1.ClusterProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeuePromotionForClusterProfile),
+		).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "error creating controller")
+	}
+
+	return nil
+}
+
+// requeuePromotionForClusterProfile requeues the Promotions promoting the ClusterProfile or
+// owning the ClusterProfile
+func (r *PromotionReconciler) requeuePromotionForClusterProfile(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	requests := make([]reconcile.Request, 0)
+	if name, ok := o.GetLabels()[configv1beta1.PromotionLabel]; ok {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+
+	promotions := &configv1beta1.PromotionList{}
+	if err := r.List(ctx, promotions); err != nil {
+		r.Logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list Promotions: %v", err))
+		return requests
+	}
+
+	for i := range promotions.Items {
+		if promotions.Items[i].Spec.ClusterProfileName == o.GetName() {
+			requests = append(requests,
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: promotions.Items[i].Name}})
+		}
+	}
+
+	return requests
+}
+
+func (r *PromotionReconciler) reconcilePromotion(ctx context.Context, promotion *configv1beta1.Promotion,
+	logger logr.Logger) error {
+
+	clusterProfile := &configv1beta1.ClusterProfile{}
+	err := r.Get(ctx, types.NamespacedName{Name: promotion.Spec.ClusterProfileName}, clusterProfile)
+	if err != nil {
+		return err
+	}
+
+	revision, err := stageRevision(ctx, r.Client, promotion, &clusterProfile.Spec)
+	if err != nil {
+		return err
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("staged revision %s", revision))
+
+	if err := applyPromotionStage(ctx, r.Client, promotion, revision, promotionStaging,
+		&promotion.Spec.Staging); err != nil {
+		return err
+	}
+	promotion.Status.StagedRevision = revision
+
+	if promotion.Spec.PromotedRevision == "" {
+		if err := removePromotionStage(ctx, r.Client, promotion, promotionProduction); err != nil {
+			return err
+		}
+		promotion.Status.ProductionRevision = ""
+	} else {
+		// Only the snapshots of the staged revision and of the revision currently in production
+		// are retained. Any other revision cannot be promoted.
+		if err := applyPromotionStage(ctx, r.Client, promotion, promotion.Spec.PromotedRevision,
+			promotionProduction, &promotion.Spec.Production); err != nil {
+			return err
+		}
+		promotion.Status.ProductionRevision = promotion.Spec.PromotedRevision
+	}
+
+	return removeStaleRevisions(ctx, r.Client, promotion,
+		[]string{promotion.Status.StagedRevision, promotion.Status.ProductionRevision})
+}
+
+// getPromotionProfileName returns the name of the ClusterProfile deploying a Promotion stage
+func getPromotionProfileName(promotionName, stage string) string {
+	return fmt.Sprintf("%s-%s", promotionName, stage)
+}
+
+// getPromotionRevisionName returns the name of the ConfigMap containing a revision Spec
+func getPromotionRevisionName(promotionName, revision string) string {
+	return fmt.Sprintf("promotion-%s-%s", promotionName, revision)
+}
+
+// getPromotionReferenceName returns the name of the revision copy of a referenced ConfigMap/Secret
+func getPromotionReferenceName(name, revision string) string {
+	return fmt.Sprintf("%s-%s", name, revision)
+}
+
+func getPromotionOwnerReference(promotion *configv1beta1.Promotion) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: configv1beta1.GroupVersion.String(),
+			Kind:       configv1beta1.PromotionKind,
+			Name:       promotion.Name,
+			UID:        promotion.UID,
+		},
+	}
+}
+
+// getPromotionReferences returns all ConfigMaps/Secrets referenced by spec. Only references
+// which do not depend on the matching cluster can be promoted.
+func getPromotionReferences(spec *configv1beta1.Spec) ([]promotionReference, error) {
+	references := make([]promotionReference, 0)
+	add := func(kind, namespace string, name *string) error {
+		if kind != string(libsveltosv1beta1.ConfigMapReferencedResourceKind) &&
+			kind != string(libsveltosv1beta1.SecretReferencedResourceKind) {
+
+			return fmt.Errorf("%s %s/%s cannot be promoted: only ConfigMaps/Secrets can be referenced",
+				kind, namespace, *name)
+		}
+		if namespace == "" || strings.Contains(namespace, "{{") || strings.Contains(*name, "{{") {
+			return fmt.Errorf("%s %s/%s cannot be promoted: namespace must be set and namespace/name "+
+				"cannot be templates", kind, namespace, *name)
+		}
+		references = append(references, promotionReference{kind: kind, namespace: namespace, name: name})
+		return nil
+	}
+
+	for i := range spec.PolicyRefs {
+		ref := &spec.PolicyRefs[i]
+		if err := add(ref.Kind, ref.Namespace, &ref.Name); err != nil {
+			return nil, err
+		}
+	}
+	for i := range spec.KustomizationRefs {
+		ref := &spec.KustomizationRefs[i]
+		if err := add(ref.Kind, ref.Namespace, &ref.Name); err != nil {
+			return nil, err
+		}
+		for j := range ref.ValuesFrom {
+			if err := add(ref.ValuesFrom[j].Kind, ref.ValuesFrom[j].Namespace, &ref.ValuesFrom[j].Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range spec.HelmCharts {
+		chart := &spec.HelmCharts[i]
+		for j := range chart.ValuesFrom {
+			if err := add(chart.ValuesFrom[j].Kind, chart.ValuesFrom[j].Namespace, &chart.ValuesFrom[j].Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range spec.Jobs {
+		ref := &spec.Jobs[i]
+		if err := add(ref.Kind, ref.Namespace, &ref.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return references, nil
+}
+
+// stageRevision computes the revision of the ClusterProfile spec and, if not existing yet, creates
+// its snapshot: a ConfigMap, in the projectsveltos namespace, containing the revision Spec plus a
+// copy of each referenced ConfigMap/Secret. Revision Spec is spec with:
+// - clusters selection removed;
+// - helm chart versions pinned (VersionPolicy removed, so exactly ChartVersion is deployed);
+// - references to ConfigMaps/Secrets redirected to their copies.
+// Revision is a hash of all of that.
+func stageRevision(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	spec *configv1beta1.Spec) (string, error) {
+
+	revisionSpec := spec.DeepCopy()
+	revisionSpec.ClusterSelector = libsveltosv1beta1.Selector{}
+	revisionSpec.ClusterRefs = nil
+	revisionSpec.SetRefs = nil
+	for i := range revisionSpec.HelmCharts {
+		revisionSpec.HelmCharts[i].VersionPolicy = nil
+	}
+
+	references, err := getPromotionReferences(revisionSpec)
+	if err != nil {
+		return "", err
+	}
+
+	specYAML, err := yaml.Marshal(revisionSpec)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(promotion.Name))
+	h.Write(specYAML)
+
+	objects := make([]client.Object, len(references))
+	for i := range references {
+		key := types.NamespacedName{Namespace: references[i].namespace, Name: *references[i].name}
+		if references[i].kind == string(libsveltosv1beta1.ConfigMapReferencedResourceKind) {
+			configMap, err := getConfigMap(ctx, c, key)
+			if err != nil {
+				return "", err
+			}
+			h.Write([]byte(getConfigMapHash(configMap)))
+			objects[i] = configMap
+		} else {
+			secret, err := getSecret(ctx, c, key)
+			if err != nil {
+				return "", err
+			}
+			h.Write([]byte(getSecretHash(secret)))
+			objects[i] = secret
+		}
+	}
+
+	revision := fmt.Sprintf("%x", h.Sum(nil))[:promotionRevisionLength]
+
+	for i := range references {
+		*references[i].name = getPromotionReferenceName(*references[i].name, revision)
+		if err := createPromotionCopy(ctx, c, promotion, revision, objects[i]); err != nil {
+			return "", err
+		}
+	}
+
+	specYAML, err = yaml.Marshal(revisionSpec)
+	if err != nil {
+		return "", err
+	}
+
+	immutable := true
+	revisionConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       projectsveltos,
+			Name:            getPromotionRevisionName(promotion.Name, revision),
+			Labels:          getPromotionLabels(promotion, revision, nil),
+			OwnerReferences: getPromotionOwnerReference(promotion),
+		},
+		Data:      map[string]string{promotionSpecKey: string(specYAML)},
+		Immutable: &immutable,
+	}
+	if err := c.Create(ctx, revisionConfigMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+
+	return revision, nil
+}
+
+func getPromotionLabels(promotion *configv1beta1.Promotion, revision string, labels map[string]string,
+) map[string]string {
+
+	result := make(map[string]string)
+	for k := range labels {
+		result[k] = labels[k]
+	}
+	result[configv1beta1.PromotionLabel] = promotion.Name
+	result[configv1beta1.PromotionRevisionLabel] = revision
+	return result
+}
+
+// createPromotionCopy creates, if not existing yet, an immutable copy of the referenced ConfigMap/Secret
+// for the revision
+func createPromotionCopy(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	revision string, object client.Object) error {
+
+	immutable := true
+	objectMeta := metav1.ObjectMeta{
+		Namespace:       object.GetNamespace(),
+		Name:            getPromotionReferenceName(object.GetName(), revision),
+		Labels:          getPromotionLabels(promotion, revision, object.GetLabels()),
+		Annotations:     object.GetAnnotations(),
+		OwnerReferences: getPromotionOwnerReference(promotion),
+	}
+
+	var promotionCopy client.Object
+	switch o := object.(type) {
+	case *corev1.ConfigMap:
+		promotionCopy = &corev1.ConfigMap{
+			ObjectMeta: objectMeta,
+			Data:       o.Data,
+			BinaryData: o.BinaryData,
+			Immutable:  &immutable,
+		}
+	case *corev1.Secret:
+		promotionCopy = &corev1.Secret{
+			ObjectMeta: objectMeta,
+			Type:       o.Type,
+			Data:       o.Data,
+			StringData: o.StringData,
+			Immutable:  &immutable,
+		}
+	}
+
+	if err := c.Create(ctx, promotionCopy); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// getRevisionSpec returns the Spec stored in the revision snapshot
+func getRevisionSpec(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	revision string) (*configv1beta1.Spec, error) {
+
+	configMap, err := getConfigMap(ctx, c,
+		types.NamespacedName{Namespace: projectsveltos, Name: getPromotionRevisionName(promotion.Name, revision)})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("revision %s is not staged", revision)
+		}
+		return nil, err
+	}
+
+	spec := &configv1beta1.Spec{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[promotionSpecKey]), spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// applyPromotionStage creates/updates the ClusterProfile deploying revision to the clusters of stage
+func applyPromotionStage(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	revision, stageName string, stage *configv1beta1.PromotionStage) error {
+
+	spec, err := getRevisionSpec(ctx, c, promotion, revision)
+	if err != nil {
+		return err
+	}
+	spec.ClusterSelector = *stage.ClusterSelector.DeepCopy()
+
+	clusterProfile := &configv1beta1.ClusterProfile{}
+	err = c.Get(ctx, types.NamespacedName{Name: getPromotionProfileName(promotion.Name, stageName)}, clusterProfile)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            getPromotionProfileName(promotion.Name, stageName),
+				Labels:          getPromotionLabels(promotion, revision, nil),
+				OwnerReferences: getPromotionOwnerReference(promotion),
+			},
+			Spec: *spec,
+		}
+		return c.Create(ctx, clusterProfile)
+	}
+
+	if reflect.DeepEqual(clusterProfile.Spec, *spec) &&
+		clusterProfile.Labels[configv1beta1.PromotionRevisionLabel] == revision {
+
+		return nil
+	}
+
+	clusterProfile.Spec = *spec
+	clusterProfile.Labels = getPromotionLabels(promotion, revision, clusterProfile.Labels)
+	return c.Update(ctx, clusterProfile)
+}
+
+// removePromotionStage deletes the ClusterProfile deploying to the clusters of stage
+func removePromotionStage(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	stageName string) error {
+
+	clusterProfile := &configv1beta1.ClusterProfile{}
+	err := c.Get(ctx, types.NamespacedName{Name: getPromotionProfileName(promotion.Name, stageName)}, clusterProfile)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return c.Delete(ctx, clusterProfile)
+}
+
+// removeStaleRevisions deletes the snapshots of all revisions but the ones in keep
+func removeStaleRevisions(ctx context.Context, c client.Client, promotion *configv1beta1.Promotion,
+	keep []string) error {
+
+	isStale := func(o client.Object) bool {
+		revision := o.GetLabels()[configv1beta1.PromotionRevisionLabel]
+		for i := range keep {
+			if keep[i] == revision {
+				return false
+			}
+		}
+		return true
+	}
+
+	listOptions := []client.ListOption{
+		client.MatchingLabels{configv1beta1.PromotionLabel: promotion.Name},
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMaps, listOptions...); err != nil {
+		return err
+	}
+	for i := range configMaps.Items {
+		if isStale(&configMaps.Items[i]) {
+			if err := c.Delete(ctx, &configMaps.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, listOptions...); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		if isStale(&secrets.Items[i]) {
+			if err := c.Delete(ctx, &secrets.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
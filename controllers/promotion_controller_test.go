@@ -0,0 +1,168 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Promotion", func() {
+	var configMap *corev1.ConfigMap
+	var clusterProfile *configv1beta1.ClusterProfile
+	var promotion *configv1beta1.Promotion
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{"policy.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: eng\n"},
+		}
+
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				PolicyRefs: []configv1beta1.PolicyRef{
+					{
+						Kind:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+						Namespace: configMap.Namespace,
+						Name:      configMap.Name,
+					},
+				},
+				HelmCharts: []configv1beta1.HelmChart{
+					{
+						RepositoryURL:    "https://kyverno.github.io/kyverno/",
+						RepositoryName:   "kyverno",
+						ChartName:        "kyverno/kyverno",
+						ChartVersion:     "v3.0.1",
+						ReleaseName:      "kyverno-latest",
+						ReleaseNamespace: "kyverno",
+						VersionPolicy:    &configv1beta1.VersionPolicy{Type: configv1beta1.VersionPolicyTypeLatest},
+					},
+				},
+			},
+		}
+
+		promotion = &configv1beta1.Promotion{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.PromotionSpec{
+				ClusterProfileName: clusterProfile.Name,
+				Staging: configv1beta1.PromotionStage{
+					ClusterSelector: libsveltosv1beta1.Selector{
+						LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "staging"}},
+					},
+				},
+				Production: configv1beta1.PromotionStage{
+					ClusterSelector: libsveltosv1beta1.Selector{
+						LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+					},
+				},
+			},
+		}
+	})
+
+	getStageProfile := func(c client.Client, stage string) *configv1beta1.ClusterProfile {
+		stageProfile := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Name: controllers.GetPromotionProfileName(promotion.Name, stage)},
+			stageProfile)).To(Succeed())
+		return stageProfile
+	}
+
+	It("reconcilePromotion stages every revision and promotes the staged one as is", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, clusterProfile, promotion).Build()
+		reconciler := &controllers.PromotionReconciler{Client: c, Scheme: scheme}
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.ReconcilePromotion(reconciler, context.TODO(), promotion, logger)).To(Succeed())
+		firstRevision := promotion.Status.StagedRevision
+		Expect(firstRevision).ToNot(BeEmpty())
+		Expect(promotion.Status.ProductionRevision).To(BeEmpty())
+
+		staging := getStageProfile(c, "staging")
+		Expect(staging.Spec.ClusterSelector).To(Equal(promotion.Spec.Staging.ClusterSelector))
+		Expect(staging.Spec.HelmCharts[0].VersionPolicy).To(BeNil())
+		Expect(staging.Spec.PolicyRefs[0].Name).ToNot(Equal(configMap.Name))
+
+		// PolicyRef points to an immutable copy of the ConfigMap
+		pinned := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: configMap.Namespace, Name: staging.Spec.PolicyRefs[0].Name},
+			pinned)).To(Succeed())
+		Expect(pinned.Data).To(Equal(configMap.Data))
+		Expect(*pinned.Immutable).To(BeTrue())
+
+		// Nothing is deployed to production before promotion
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Name: controllers.GetPromotionProfileName(promotion.Name, "production")},
+			&configv1beta1.ClusterProfile{})).ToNot(Succeed())
+
+		// Promote
+		promotion.Spec.PromotedRevision = firstRevision
+		Expect(controllers.ReconcilePromotion(reconciler, context.TODO(), promotion, logger)).To(Succeed())
+		Expect(promotion.Status.ProductionRevision).To(Equal(firstRevision))
+
+		production := getStageProfile(c, "production")
+		Expect(production.Spec.ClusterSelector).To(Equal(promotion.Spec.Production.ClusterSelector))
+		production.Spec.ClusterSelector = staging.Spec.ClusterSelector
+		Expect(production.Spec).To(Equal(staging.Spec))
+
+		// A change to the referenced ConfigMap is a new revision, deployed to staging only
+		configMap.Data = map[string]string{"policy.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n"}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+
+		Expect(controllers.ReconcilePromotion(reconciler, context.TODO(), promotion, logger)).To(Succeed())
+		Expect(promotion.Status.StagedRevision).ToNot(Equal(firstRevision))
+		Expect(promotion.Status.ProductionRevision).To(Equal(firstRevision))
+
+		production = getStageProfile(c, "production")
+		Expect(production.Spec.PolicyRefs[0].Name).To(Equal(staging.Spec.PolicyRefs[0].Name))
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: configMap.Namespace, Name: production.Spec.PolicyRefs[0].Name},
+			pinned)).To(Succeed())
+		Expect(pinned.Data["policy.yaml"]).To(ContainSubstring("name: eng"))
+
+		// Revisions never staged cannot be promoted
+		promotion.Spec.PromotedRevision = randomString()
+		Expect(controllers.ReconcilePromotion(reconciler, context.TODO(), promotion, logger)).ToNot(Succeed())
+	})
+
+	It("reconcilePromotion fails when a reference depends on the cluster", func() {
+		clusterProfile.Spec.PolicyRefs[0].Name = "{{ .Cluster.metadata.name }}"
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, clusterProfile, promotion).Build()
+		reconciler := &controllers.PromotionReconciler{Client: c, Scheme: scheme}
+
+		Expect(controllers.ReconcilePromotion(reconciler, context.TODO(), promotion,
+			textlogger.NewLogger(textlogger.NewConfig()))).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	// pruneProtectedAnnotation is set on resources deployed from a PolicyRef with a PruneAllowlist
+	// not containing their kind. Such resources are never removed by Sveltos. The annotation is
+	// recorded at deployment time so resources stay protected even once the PolicyRef is removed.
+	pruneProtectedAnnotation = "projectsveltos.io/prune-protected"
+)
+
+type pruneAllowlistContextKey struct{}
+
+// getPruneAllowlists returns the PruneAllowlist of each PolicyRef having one.
+// Key is <kind>/<namespace>/<name> of the referenced resource.
+func getPruneAllowlists(clusterSummary *configv1beta1.ClusterSummary) (map[string][]configv1beta1.PruneKind, error) {
+	allowlists := make(map[string][]configv1beta1.PruneKind)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
+		if len(ref.PruneAllowlist) == 0 {
+			continue
+		}
+
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Namespace, ref.Namespace)
+		name, err := libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		allowlists[getPruneAllowlistKey(ref.Kind, namespace, name)] = ref.PruneAllowlist
+	}
+
+	return allowlists, nil
+}
+
+func getPruneAllowlistKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// withPruneAllowlist returns a context carrying the PruneAllowlist of the PolicyRef whose content
+// is being deployed. If allowlist is empty, ctx is returned unchanged.
+func withPruneAllowlist(ctx context.Context, allowlist []configv1beta1.PruneKind) context.Context {
+	if len(allowlist) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, pruneAllowlistContextKey{}, allowlist)
+}
+
+// markPruneProtected sets pruneProtectedAnnotation on policy if ctx carries a PruneAllowlist not
+// containing policy kind
+func markPruneProtected(ctx context.Context, policy *unstructured.Unstructured) {
+	allowlist, ok := ctx.Value(pruneAllowlistContextKey{}).([]configv1beta1.PruneKind)
+	if !ok || isPruneAllowed(allowlist, policy.GroupVersionKind()) {
+		return
+	}
+
+	addAnnotation(policy, pruneProtectedAnnotation, "true")
+}
+
+// isPruneAllowed returns true if gvk matches any entry of allowlist. Entries without
+// version match all versions.
+func isPruneAllowed(allowlist []configv1beta1.PruneKind, gvk schema.GroupVersionKind) bool {
+	for i := range allowlist {
+		if allowlist[i].Group == gvk.Group && allowlist[i].Kind == gvk.Kind &&
+			(allowlist[i].Version == "" || allowlist[i].Version == gvk.Version) {
+
+			return true
+		}
+	}
+	return false
+}
+
+// isPruneProtected returns true if resource must never be removed by Sveltos
+func isPruneProtected(resource client.Object) bool {
+	return resource.GetAnnotations()[pruneProtectedAnnotation] == "true"
+}
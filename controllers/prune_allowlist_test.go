@@ -0,0 +1,164 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+const (
+	pruneProtectedAnnotation = "projectsveltos.io/prune-protected"
+)
+
+var _ = Describe("Prune allowlist", func() {
+	It("markPruneProtected protects resources whose kind is not in the PolicyRef PruneAllowlist", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					PolicyRefs: []configv1beta1.PolicyRef{
+						{
+							Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+							PruneAllowlist: []configv1beta1.PruneKind{
+								{Kind: "ConfigMap"},
+								{Group: "apps", Version: "v1", Kind: "Deployment"},
+							},
+						},
+						{
+							Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1beta1.SecretReferencedResourceKind),
+						},
+					},
+				},
+			},
+		}
+
+		allowlists, err := controllers.GetPruneAllowlists(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(len(allowlists)).To(Equal(1))
+
+		ref := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[0]
+		allowlist := allowlists[controllers.GetPruneAllowlistKey(ref.Kind, ref.Namespace, ref.Name)]
+		Expect(allowlist).To(Equal(ref.PruneAllowlist))
+
+		namespace := &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind("Namespace")
+		namespace.SetName(randomString())
+
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(randomString())
+
+		deployment := &unstructured.Unstructured{}
+		deployment.SetAPIVersion("apps/v1")
+		deployment.SetKind("Deployment")
+		deployment.SetName(randomString())
+
+		// Without a PruneAllowlist, nothing is protected
+		controllers.MarkPruneProtected(controllers.WithPruneAllowlist(context.TODO(), nil), namespace)
+		Expect(namespace.GetAnnotations()).ToNot(HaveKey(pruneProtectedAnnotation))
+
+		ctx := controllers.WithPruneAllowlist(context.TODO(), allowlist)
+		controllers.MarkPruneProtected(ctx, namespace)
+		controllers.MarkPruneProtected(ctx, configMap)
+		controllers.MarkPruneProtected(ctx, deployment)
+		Expect(namespace.GetAnnotations()).To(HaveKeyWithValue(pruneProtectedAnnotation, "true"))
+		Expect(configMap.GetAnnotations()).ToNot(HaveKey(pruneProtectedAnnotation))
+		Expect(deployment.GetAnnotations()).ToNot(HaveKey(pruneProtectedAnnotation))
+	})
+
+	It("undeployStaleResource leaves resources protected from pruning", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+		}
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+				Labels: map[string]string{
+					deployer.ReferenceKindLabel:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+					deployer.ReferenceNameLabel:      randomString(),
+					deployer.ReferenceNamespaceLabel: randomString(),
+				},
+				Annotations: map[string]string{
+					pruneProtectedAnnotation: "true",
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Name:       clusterProfile.Name,
+						UID:        types.UID(randomString()),
+					},
+				},
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, namespace)).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace).Build()
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(namespace)
+		Expect(err).To(BeNil())
+		u := unstructured.Unstructured{Object: content}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		_, err = controllers.UndeployStaleResource(context.TODO(), false, c, clusterProfile, clusterSummary, u,
+			map[string]configv1beta1.Resource{}, false, logger)
+		Expect(err).To(BeNil())
+
+		currentNamespace := &corev1.Namespace{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: namespace.Name}, currentNamespace)).To(Succeed())
+		Expect(currentNamespace.Labels).ToNot(HaveKey(deployer.ReferenceNameLabel))
+		Expect(currentNamespace.OwnerReferences).To(BeEmpty())
+	})
+})
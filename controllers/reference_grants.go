@@ -0,0 +1,207 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+// getSpecReferences returns the resources, in the management cluster, referenced by featureID in spec,
+// as expressed in the spec (namespace can be empty and name can be a template).
+func getSpecReferences(spec *configv1beta1.Spec, featureID configv1beta1.FeatureID) []corev1.ObjectReference {
+	result := make([]corev1.ObjectReference, 0)
+	add := func(kind, namespace, name string) {
+		result = append(result, corev1.ObjectReference{Kind: kind, Namespace: namespace, Name: name})
+	}
+	addSecret := func(ref *corev1.SecretReference) {
+		if ref != nil {
+			add(string(libsveltosv1beta1.SecretReferencedResourceKind), ref.Namespace, ref.Name)
+		}
+	}
+	addRegistryCredentials := func(config *configv1beta1.RegistryCredentialsConfig) {
+		if config != nil {
+			addSecret(config.CredentialsSecretRef)
+			addSecret(config.CASecretRef)
+			addSecret(config.CertSecretRef)
+		}
+	}
+
+	switch featureID {
+	case configv1beta1.FeatureResources:
+		for i := range spec.PolicyRefs {
+			add(spec.PolicyRefs[i].Kind, spec.PolicyRefs[i].Namespace, spec.PolicyRefs[i].Name)
+		}
+		for i := range spec.SecretRotationHooks {
+			hook := &spec.SecretRotationHooks[i]
+			add(string(libsveltosv1beta1.SecretReferencedResourceKind), hook.Namespace, hook.Name)
+			if hook.Job != nil {
+				add(hook.Job.Kind, hook.Job.Namespace, hook.Job.Name)
+			}
+		}
+	case configv1beta1.FeatureKustomize:
+		for i := range spec.KustomizationRefs {
+			kr := &spec.KustomizationRefs[i]
+			add(kr.Kind, kr.Namespace, kr.Name)
+			for j := range kr.ValuesFrom {
+				add(kr.ValuesFrom[j].Kind, kr.ValuesFrom[j].Namespace, kr.ValuesFrom[j].Name)
+			}
+		}
+	case configv1beta1.FeatureHelm:
+		for i := range spec.HelmCharts {
+			hc := &spec.HelmCharts[i]
+			for j := range hc.ValuesFrom {
+				add(hc.ValuesFrom[j].Kind, hc.ValuesFrom[j].Namespace, hc.ValuesFrom[j].Name)
+			}
+			if hc.SourceRef != nil {
+				add(hc.SourceRef.Kind, hc.SourceRef.Namespace, hc.SourceRef.Name)
+			}
+			addRegistryCredentials(hc.RegistryCredentialsConfig)
+			for j := range hc.DependencyRepositories {
+				addRegistryCredentials(hc.DependencyRepositories[j].RegistryCredentialsConfig)
+			}
+			if hc.Options != nil && hc.Options.Storage != nil {
+				addSecret(hc.Options.Storage.SQLConnectionSecretRef)
+			}
+			if hc.Verify != nil {
+				addSecret(&hc.Verify.SecretRef)
+			}
+		}
+	case configv1beta1.FeatureJobs:
+		for i := range spec.Jobs {
+			add(spec.Jobs[i].Kind, spec.Jobs[i].Namespace, spec.Jobs[i].Name)
+		}
+	}
+
+	// SecretTransformer, ImageDigestResolution and WasmPlugins apply to the resources deployed by
+	// the Resources, Kustomize and Helm features
+	if featureID == configv1beta1.FeatureResources || featureID == configv1beta1.FeatureKustomize ||
+		featureID == configv1beta1.FeatureHelm {
+
+		if spec.SecretTransformer != nil && spec.SecretTransformer.CertificateRef != nil {
+			ref := spec.SecretTransformer.CertificateRef
+			add(ref.Kind, ref.Namespace, ref.Name)
+		}
+		if spec.ImageDigestResolution != nil {
+			addSecret(spec.ImageDigestResolution.CredentialsSecretRef)
+		}
+		for i := range spec.WasmPlugins {
+			if ref := spec.WasmPlugins[i].ConfigMapRef; ref != nil {
+				add(string(libsveltosv1beta1.ConfigMapReferencedResourceKind), ref.Namespace, ref.Name)
+			}
+			addSecret(spec.WasmPlugins[i].CredentialsSecretRef)
+		}
+	}
+
+	return result
+}
+
+// isReferenceGranted returns true if a ReferenceGrant in namespace allows Profiles in fromNamespace
+// to reference the resource of kind/name.
+// A name expressed as a template can only be granted by a ReferenceGrant not restricting the name.
+func isReferenceGranted(ctx context.Context, c client.Client, fromNamespace, kind, namespace, name string,
+) (bool, error) {
+
+	grants := &configv1beta1.ReferenceGrantList{}
+	if err := c.List(ctx, grants, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+
+	for i := range grants.Items {
+		spec := &grants.Items[i].Spec
+
+		fromAllowed := false
+		for j := range spec.From {
+			if spec.From[j].Namespace == fromNamespace {
+				fromAllowed = true
+				break
+			}
+		}
+		if !fromAllowed {
+			continue
+		}
+
+		for j := range spec.To {
+			if spec.To[j].Kind == kind && (spec.To[j].Name == "" || spec.To[j].Name == name) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// validateProfileReferences verifies that all resources, referenced by spec, in a namespace
+// other than profileNamespace are granted by a ReferenceGrant.
+// When clusterSummary is not nil, referenced names are instantiated for the ClusterSummary cluster.
+func validateProfileReferences(ctx context.Context, c client.Client, profileNamespace string,
+	spec *configv1beta1.Spec, featureID configv1beta1.FeatureID, clusterSummary *configv1beta1.ClusterSummary) error {
+
+	for _, ref := range getSpecReferences(spec, featureID) {
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(profileNamespace, ref.Namespace)
+		if namespace == profileNamespace {
+			continue
+		}
+
+		name := ref.Name
+		if clusterSummary != nil {
+			var err error
+			name, err = libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+				clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), ref.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		granted, err := isReferenceGranted(ctx, c, profileNamespace, ref.Kind, namespace, name)
+		if err != nil {
+			return err
+		}
+		if !granted {
+			return fmt.Errorf("%s %s/%s cannot be referenced by Profiles in namespace %s: no %s in namespace %s grants it",
+				ref.Kind, namespace, name, profileNamespace, configv1beta1.ReferenceGrantKind, namespace)
+		}
+	}
+
+	return nil
+}
+
+// validateClusterSummaryReferences verifies that, when ClusterSummary is created by a Profile, all
+// resources referenced by featureID in other namespaces are granted by a ReferenceGrant.
+// References of ClusterSummaries created by ClusterProfiles are never restricted.
+func validateClusterSummaryReferences(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) error {
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return err
+	}
+	if profileOwnerRef.Kind != configv1beta1.ProfileKind {
+		return nil
+	}
+
+	// ClusterSummaries created by a Profile are in the Profile namespace
+	return validateProfileReferences(ctx, c, clusterSummary.Namespace, &clusterSummary.Spec.ClusterProfileSpec,
+		featureID, clusterSummary)
+}
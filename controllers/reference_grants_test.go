@@ -0,0 +1,338 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("ReferenceGrant", func() {
+	var profileNamespace string
+	var targetNamespace string
+	var grant *configv1beta1.ReferenceGrant
+
+	BeforeEach(func() {
+		profileNamespace = randomString()
+		targetNamespace = randomString()
+
+		grant = &configv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: randomString()},
+			Spec: configv1beta1.ReferenceGrantSpec{
+				From: []configv1beta1.ReferenceGrantFrom{{Namespace: profileNamespace}},
+				To: []configv1beta1.ReferenceGrantTo{
+					{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Name: "shared"},
+				},
+			},
+		}
+	})
+
+	It("validateClusterSummaryReferences rejects cross-namespace references not granted", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: profileNamespace,
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ProfileKind,
+						Name: randomString(), UID: "1"},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: profileNamespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					PolicyRefs: []configv1beta1.PolicyRef{
+						{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Name: randomString()},
+						{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: targetNamespace,
+							Name: "shared"},
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		Expect(controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources)).ToNot(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(grant).Build()
+		Expect(controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources)).To(Succeed())
+
+		// Grant is for a ConfigMap named shared only
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[1].Name = randomString()
+		Expect(controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources)).ToNot(Succeed())
+
+		// References of ClusterSummaries created by ClusterProfiles are not restricted
+		clusterSummary.OwnerReferences[0].Kind = configv1beta1.ClusterProfileKind
+		Expect(controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources)).To(Succeed())
+	})
+
+	It("ProfileValidator rejects Profiles with cross-namespace references not granted", func() {
+		profile := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: profileNamespace, Name: randomString()},
+			Spec: configv1beta1.Spec{
+				HelmCharts: []configv1beta1.HelmChart{
+					{
+						RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+						ReleaseName: randomString(), ReleaseNamespace: randomString(),
+						ValuesFrom: []configv1beta1.ValueFrom{
+							{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: targetNamespace,
+								Name: "shared"},
+						},
+					},
+				},
+			},
+		}
+
+		validator := &controllers.ProfileValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+		_, err := validator.ValidateCreate(context.TODO(), profile)
+		Expect(err).ToNot(BeNil())
+
+		validator = &controllers.ProfileValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(grant).Build(),
+		}
+		_, err = validator.ValidateUpdate(context.TODO(), profile, profile)
+		Expect(err).To(BeNil())
+	})
+
+	It("validateClusterSummaryReferences rejects registry credentials Secrets not granted", func() {
+		spec := configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+					ReleaseName: randomString(), ReleaseNamespace: randomString(),
+					RegistryCredentialsConfig: &configv1beta1.RegistryCredentialsConfig{
+						CredentialsSecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+					},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+
+		spec.HelmCharts[0].RegistryCredentialsConfig = &configv1beta1.RegistryCredentialsConfig{
+			CASecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+
+		spec.HelmCharts[0].RegistryCredentialsConfig = &configv1beta1.RegistryCredentialsConfig{
+			CertSecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+
+		spec.HelmCharts[0].RegistryCredentialsConfig = nil
+		spec.HelmCharts[0].DependencyRepositories = []configv1beta1.DependencyRepository{
+			{
+				URL: randomString(),
+				RegistryCredentialsConfig: &configv1beta1.RegistryCredentialsConfig{
+					CredentialsSecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects helm storage Secrets not granted", func() {
+		spec := configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+					ReleaseName: randomString(), ReleaseNamespace: randomString(),
+					Options: &configv1beta1.HelmOptions{
+						Storage: &configv1beta1.HelmStorage{
+							Driver:                 configv1beta1.HelmStorageDriverSQL,
+							SQLConnectionSecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+						},
+					},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects chart verification Secrets not granted", func() {
+		spec := configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+					ReleaseName: randomString(), ReleaseNamespace: randomString(),
+					Verify: &configv1beta1.ChartVerification{
+						Provider:  configv1beta1.ChartVerificationProviderCosign,
+						SecretRef: corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+					},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects image digests credentials Secrets not granted", func() {
+		spec := configv1beta1.Spec{
+			ImageDigestResolution: &configv1beta1.ImageDigestResolution{
+				CredentialsSecretRef: &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"},
+			},
+		}
+		for _, featureID := range []configv1beta1.FeatureID{configv1beta1.FeatureResources,
+			configv1beta1.FeatureKustomize, configv1beta1.FeatureHelm} {
+
+			verifyReferenceRefused(profileNamespace, targetNamespace, &spec, featureID,
+				string(libsveltosv1beta1.SecretReferencedResourceKind))
+		}
+	})
+
+	It("validateClusterSummaryReferences rejects SealedSecrets certificates not granted", func() {
+		spec := configv1beta1.Spec{
+			SecretTransformer: &configv1beta1.SecretTransformer{
+				Type: configv1beta1.SecretTransformerSealedSecret,
+				CertificateRef: &configv1beta1.SealedSecretsCertificateRef{
+					Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: targetNamespace,
+					Name: "shared",
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureResources,
+			string(libsveltosv1beta1.ConfigMapReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects WasmPlugins ConfigMaps and credentials not granted", func() {
+		spec := configv1beta1.Spec{
+			WasmPlugins: []configv1beta1.WasmPlugin{
+				{
+					Name: randomString(),
+					ConfigMapRef: &configv1beta1.WasmModuleConfigMapRef{
+						Namespace: targetNamespace, Name: "shared",
+					},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureKustomize,
+			string(libsveltosv1beta1.ConfigMapReferencedResourceKind))
+
+		spec.WasmPlugins[0].ConfigMapRef = nil
+		spec.WasmPlugins[0].Image = randomString()
+		spec.WasmPlugins[0].CredentialsSecretRef = &corev1.SecretReference{Namespace: targetNamespace, Name: "shared"}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureKustomize,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects SecretRotationHooks references not granted", func() {
+		spec := configv1beta1.Spec{
+			SecretRotationHooks: []configv1beta1.SecretRotationHook{
+				{Namespace: targetNamespace, Name: "shared", RestartDeployments: true},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureResources,
+			string(libsveltosv1beta1.SecretReferencedResourceKind))
+
+		spec.SecretRotationHooks[0].Namespace = ""
+		spec.SecretRotationHooks[0].Job = &configv1beta1.JobRef{
+			Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: targetNamespace, Name: "shared",
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureResources,
+			string(libsveltosv1beta1.ConfigMapReferencedResourceKind))
+	})
+
+	It("validateClusterSummaryReferences rejects helm chart sourceRefs not granted", func() {
+		spec := configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryName: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+					ReleaseName: randomString(), ReleaseNamespace: randomString(),
+					SourceRef: &configv1beta1.ChartSourceRef{
+						Kind: sourcev1.HelmRepositoryKind, Namespace: targetNamespace, Name: "shared",
+					},
+				},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			sourcev1.HelmRepositoryKind)
+
+		spec.HelmCharts[0].SourceRef.Kind = sourcev1.GitRepositoryKind
+		spec.HelmCharts[0].SourceRef.Path = "charts/app"
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureHelm,
+			sourcev1.GitRepositoryKind)
+	})
+
+	It("validateClusterSummaryReferences rejects Flux sources not granted", func() {
+		spec := configv1beta1.Spec{
+			KustomizationRefs: []configv1beta1.KustomizationRef{
+				{Kind: sourcev1.GitRepositoryKind, Namespace: targetNamespace, Name: "shared", Path: randomString()},
+			},
+		}
+		verifyReferenceRefused(profileNamespace, targetNamespace, &spec, configv1beta1.FeatureKustomize,
+			sourcev1.GitRepositoryKind)
+	})
+})
+
+// verifyReferenceRefused verifies the ClusterSummary, created by a Profile in profileNamespace, with spec
+// is refused unless a ReferenceGrant in targetNamespace grants Profiles in profileNamespace access to
+// the resource of kind named shared.
+func verifyReferenceRefused(profileNamespace, targetNamespace string, spec *configv1beta1.Spec,
+	featureID configv1beta1.FeatureID, kind string) {
+
+	clusterSummary := &configv1beta1.ClusterSummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: profileNamespace,
+			Name:      randomString(),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ProfileKind,
+					Name: randomString(), UID: "1"},
+			},
+		},
+		Spec: configv1beta1.ClusterSummarySpec{
+			ClusterNamespace:   profileNamespace,
+			ClusterName:        randomString(),
+			ClusterType:        libsveltosv1beta1.ClusterTypeCapi,
+			ClusterProfileSpec: *spec,
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	err := controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary, featureID)
+	Expect(err).ToNot(BeNil())
+	Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("%s %s/shared cannot be referenced", kind, targetNamespace)))
+
+	grant := &configv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: randomString()},
+		Spec: configv1beta1.ReferenceGrantSpec{
+			From: []configv1beta1.ReferenceGrantFrom{{Namespace: profileNamespace}},
+			To:   []configv1beta1.ReferenceGrantTo{{Kind: kind, Name: "shared"}},
+		},
+	}
+	c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(grant).Build()
+	Expect(controllers.ValidateClusterSummaryReferences(context.TODO(), c, clusterSummary, featureID)).To(Succeed())
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/funcmap"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+// referencedContentType indicates how the content of a referenced ConfigMap/Secret is consumed
+type referencedContentType int
+
+const (
+	// manifestsContent is content made of Kubernetes resources (PolicyRefs, Jobs)
+	manifestsContent referencedContentType = iota
+	// valuesContent is content made of helm values (helm chart ValuesFrom)
+	valuesContent
+)
+
+type lintReference struct {
+	kind        string
+	namespace   string
+	name        string
+	contentType referencedContentType
+}
+
+// LintReferencedResources periodically validates the content of all ConfigMaps/Secrets
+// referenced by any ClusterProfile/Profile (YAML parse, template parse and basic Kubernetes
+// resource schema). Problems are reported in the ClusterProfile/Profile Status, so they
+// are visible before a deployment ever runs.
+func LintReferencedResources(ctx context.Context, c client.Client, interval time.Duration, logger logr.Logger) {
+	for {
+		time.Sleep(interval)
+
+		logger.V(logs.LogVerbose).Info("validating referenced resources")
+
+		clusterProfiles := &configv1beta1.ClusterProfileList{}
+		if err := c.List(ctx, clusterProfiles); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterProfiles: %v", err))
+		} else {
+			for i := range clusterProfiles.Items {
+				lintProfile(ctx, c, &clusterProfiles.Items[i], logger)
+			}
+		}
+
+		profiles := &configv1beta1.ProfileList{}
+		if err := c.List(ctx, profiles); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list Profiles: %v", err))
+		} else {
+			for i := range profiles.Items {
+				lintProfile(ctx, c, &profiles.Items[i], logger)
+			}
+		}
+	}
+}
+
+func lintProfile(ctx context.Context, c client.Client, profile client.Object, logger logr.Logger) {
+	if !profile.GetDeletionTimestamp().IsZero() {
+		return
+	}
+
+	l := logger.WithValues("profile", fmt.Sprintf("%s/%s", profile.GetNamespace(), profile.GetName()))
+
+	spec, _, err := getProfileSpecAndStatus(profile)
+	if err != nil {
+		l.V(logs.LogInfo).Info(fmt.Sprintf("failed to get profile spec: %v", err))
+		return
+	}
+
+	validationErrors := lintProfileSpec(ctx, c, profile.GetNamespace(), spec, l)
+	if err := updateReferenceValidationErrors(ctx, c, profile, validationErrors); err != nil {
+		l.V(logs.LogInfo).Info(fmt.Sprintf("failed to update reference validation errors: %v", err))
+	}
+}
+
+func getProfileSpecAndStatus(profile client.Object) (*configv1beta1.Spec, *configv1beta1.Status, error) {
+	switch p := profile.(type) {
+	case *configv1beta1.ClusterProfile:
+		return &p.Spec, &p.Status, nil
+	case *configv1beta1.Profile:
+		return &p.Spec, &p.Status, nil
+	}
+
+	return nil, nil, fmt.Errorf("unexpected type %T", profile)
+}
+
+// lintProfileSpec validates the content of all ConfigMaps/Secrets referenced by spec.
+// profileNamespace is empty for ClusterProfiles.
+func lintProfileSpec(ctx context.Context, c client.Client, profileNamespace string, spec *configv1beta1.Spec,
+	logger logr.Logger) []configv1beta1.ReferenceValidationError {
+
+	result := make([]configv1beta1.ReferenceValidationError, 0)
+	for _, ref := range getLintReferences(profileNamespace, spec) {
+		result = append(result, lintReferencedResource(ctx, c, ref, logger)...)
+	}
+
+	return result
+}
+
+// getLintReferences returns, sorted and with no duplicates, the ConfigMaps/Secrets referenced by spec
+// which can be resolved without knowing the matching cluster. References whose name is a template or,
+// for ClusterProfiles, whose namespace is left empty (cluster namespace) are skipped.
+func getLintReferences(profileNamespace string, spec *configv1beta1.Spec) []lintReference {
+	seen := make(map[lintReference]bool)
+	result := make([]lintReference, 0)
+
+	add := func(kind, namespace, name string, contentType referencedContentType) {
+		if kind != string(libsveltosv1beta1.ConfigMapReferencedResourceKind) &&
+			kind != string(libsveltosv1beta1.SecretReferencedResourceKind) {
+
+			return
+		}
+		if profileNamespace != "" {
+			// Profile can only reference resources in its own namespace
+			namespace = profileNamespace
+		}
+		if namespace == "" || strings.Contains(name, "{{") {
+			return
+		}
+
+		ref := lintReference{kind: kind, namespace: namespace, name: name, contentType: contentType}
+		if !seen[ref] {
+			seen[ref] = true
+			result = append(result, ref)
+		}
+	}
+
+	for i := range spec.PolicyRefs {
+		add(spec.PolicyRefs[i].Kind, spec.PolicyRefs[i].Namespace, spec.PolicyRefs[i].Name, manifestsContent)
+	}
+	for i := range spec.Jobs {
+		add(spec.Jobs[i].Kind, spec.Jobs[i].Namespace, spec.Jobs[i].Name, manifestsContent)
+	}
+	for i := range spec.HelmCharts {
+		for j := range spec.HelmCharts[i].ValuesFrom {
+			valuesFrom := &spec.HelmCharts[i].ValuesFrom[j]
+			if valuesFrom.Optional {
+				// Optional references are allowed to be missing
+				continue
+			}
+			add(valuesFrom.Kind, valuesFrom.Namespace, valuesFrom.Name, valuesContent)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", result[i].kind, result[i].namespace, result[i].name) <
+			fmt.Sprintf("%s/%s/%s", result[j].kind, result[j].namespace, result[j].name)
+	})
+
+	return result
+}
+
+func lintReferencedResource(ctx context.Context, c client.Client, ref lintReference,
+	logger logr.Logger) []configv1beta1.ReferenceValidationError {
+
+	newError := func(key, message string) configv1beta1.ReferenceValidationError {
+		return configv1beta1.ReferenceValidationError{
+			Kind: ref.kind, Namespace: ref.namespace, Name: ref.name, Key: key, Message: message,
+		}
+	}
+
+	var object client.Object
+	data := make(map[string]string)
+	if ref.kind == string(libsveltosv1beta1.ConfigMapReferencedResourceKind) {
+		configMap, err := getConfigMap(ctx, c, types.NamespacedName{Namespace: ref.namespace, Name: ref.name})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return []configv1beta1.ReferenceValidationError{newError("", "resource not found")}
+			}
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to get ConfigMap %s/%s: %v", ref.namespace, ref.name, err))
+			return nil
+		}
+		object = configMap
+		for k := range configMap.Data {
+			data[k] = configMap.Data[k]
+		}
+	} else {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: ref.namespace, Name: ref.name}, secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return []configv1beta1.ReferenceValidationError{newError("", "resource not found")}
+			}
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to get Secret %s/%s: %v", ref.namespace, ref.name, err))
+			return nil
+		}
+		// Secrets containing resources to deploy must be of type ClusterProfileSecretType
+		if ref.contentType == manifestsContent && secret.Type != libsveltosv1beta1.ClusterProfileSecretType {
+			return []configv1beta1.ReferenceValidationError{
+				newError("", libsveltosv1beta1.ErrSecretTypeNotSupported.Error()),
+			}
+		}
+		object = secret
+		for k := range secret.Data {
+			data[k] = string(secret.Data[k])
+		}
+	}
+
+	isTemplate := false
+	if annotations := object.GetAnnotations(); annotations != nil {
+		_, isTemplate = annotations[libsveltosv1beta1.PolicyTemplateAnnotation]
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]configv1beta1.ReferenceValidationError, 0)
+	for _, k := range keys {
+		var err error
+		switch {
+		case isTemplate:
+			// Templates can only be instantiated for a specific cluster. Only verify they parse.
+			err = lintTemplate(data[k])
+		case ref.contentType == valuesContent:
+			err = lintValues(data[k])
+		default:
+			err = lintManifests(data[k])
+		}
+		if err != nil {
+			result = append(result, newError(k, err.Error()))
+		}
+	}
+
+	return result
+}
+
+func lintTemplate(content string) error {
+	funcMap := funcmap.SveltosFuncMap()
+	funcMap["getResource"] = func(id string) map[string]interface{} {
+		return nil
+	}
+
+	_, err := template.New("lint").Option("missingkey=error").Funcs(funcMap).Parse(content)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+func lintValues(content string) error {
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(content), &values); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return nil
+}
+
+// lintManifests verifies content is made of valid Kubernetes resources, each one with
+// apiVersion, kind and name set
+func lintManifests(content string) error {
+	elements, err := customSplit(content)
+	if err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	for i := range elements {
+		if strings.TrimSpace(elements[i]) == "" {
+			continue
+		}
+
+		resource, err := utils.GetUnstructured([]byte(elements[i]))
+		if err != nil {
+			return fmt.Errorf("invalid resource: %w", err)
+		}
+		if resource.GetAPIVersion() == "" {
+			return fmt.Errorf("resource %d: apiVersion is missing", i)
+		}
+		if resource.GetKind() == "" {
+			return fmt.Errorf("resource %d: kind is missing", i)
+		}
+		if resource.GetName() == "" {
+			return fmt.Errorf("resource %d (%s): metadata.name is missing", i, resource.GetKind())
+		}
+	}
+
+	return nil
+}
+
+// updateReferenceValidationErrors updates, if changed, the ReferenceValidationErrors in the
+// ClusterProfile/Profile Status
+func updateReferenceValidationErrors(ctx context.Context, c client.Client, profile client.Object,
+	validationErrors []configv1beta1.ReferenceValidationError) error {
+
+	if len(validationErrors) == 0 {
+		validationErrors = nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentProfile := profile.DeepCopyObject().(client.Object)
+		err := c.Get(ctx, types.NamespacedName{Namespace: profile.GetNamespace(), Name: profile.GetName()},
+			currentProfile)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		_, status, err := getProfileSpecAndStatus(currentProfile)
+		if err != nil {
+			return err
+		}
+
+		if reflect.DeepEqual(status.ReferenceValidationErrors, validationErrors) {
+			return nil
+		}
+
+		status.ReferenceValidationErrors = validationErrors
+		return c.Status().Update(ctx, currentProfile)
+	})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Reference linter", func() {
+	It("lintProfileSpec reports invalid content of referenced ConfigMaps/Secrets", func() {
+		namespace := randomString()
+
+		valid := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data: map[string]string{
+				"sa": "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: foo\n  namespace: bar",
+			},
+		}
+		invalid := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data: map[string]string{
+				"noname": "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  namespace: bar",
+				"sa":     "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: foo",
+			},
+		}
+		template := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        randomString(),
+				Annotations: map[string]string{libsveltosv1beta1.PolicyTemplateAnnotation: "ok"},
+			},
+			Data: map[string]string{
+				"sa": "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: {{ .Cluster.metadata.name ",
+			},
+		}
+		values := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data: map[string][]byte{
+				"values": []byte("replicas: 1\n- nginx"),
+			},
+		}
+
+		spec := &configv1beta1.Spec{
+			PolicyRefs: []configv1beta1.PolicyRef{
+				{Namespace: namespace, Name: valid.Name, Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				{Namespace: namespace, Name: invalid.Name, Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				{Namespace: namespace, Name: template.Name, Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				{Namespace: namespace, Name: randomString(), Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				// Templated name cannot be resolved without a cluster
+				{Namespace: namespace, Name: "{{ .Cluster.metadata.name }}", Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+				// Namespace left empty is the cluster namespace
+				{Name: randomString(), Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind)},
+			},
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					ValuesFrom: []configv1beta1.ValueFrom{
+						{Namespace: namespace, Name: values.Name, Kind: string(libsveltosv1beta1.SecretReferencedResourceKind)},
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid, invalid, template, values).Build()
+
+		validationErrors := controllers.LintProfileSpec(context.TODO(), c, "", spec,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(len(validationErrors)).To(Equal(4))
+
+		found := make(map[string]string)
+		for i := range validationErrors {
+			Expect(validationErrors[i].Name).ToNot(Equal(valid.Name))
+			found[validationErrors[i].Name] = validationErrors[i].Key
+		}
+		Expect(found[invalid.Name]).To(Equal("noname"))
+		Expect(found[template.Name]).To(Equal("sa"))
+		Expect(found[values.Name]).To(Equal("values"))
+		Expect(found).To(HaveKey(spec.PolicyRefs[3].Name))
+	})
+})
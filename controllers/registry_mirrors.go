@@ -0,0 +1,107 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+var (
+	// registryMirrors maps registries, and helm repositories, to their mirror. Keys and values
+	// have no scheme, for instance docker.io -> registry.internal/docker.io, unless the mirror
+	// is served with a different scheme than the original.
+	registryMirrors map[string]string
+)
+
+// SetRegistryMirrors sets the mirrors used, in disconnected environments, in place of the
+// original registries and helm repositories
+func SetRegistryMirrors(mirrors map[string]string) {
+	registryMirrors = mirrors
+}
+
+// ParseRegistryMirrors parses a comma separated list of <source>=<mirror> entries. Source is a
+// registry or helm repository, optionally followed by a path (for instance docker.io or
+// charts.bitnami.com/bitnami). Mirror is the registry or helm repository replacing it. Mirror
+// can have a scheme (for instance https://mirror.internal/bitnami), in which case the scheme
+// of the original URL is replaced as well.
+func ParseRegistryMirrors(value string) (map[string]string, error) {
+	mirrors := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return mirrors, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		source, mirror, found := strings.Cut(strings.TrimSpace(entry), "=")
+		_, source = splitURLScheme(strings.TrimSuffix(strings.TrimSpace(source), "/"))
+		mirror = strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+		if !found || source == "" || mirror == "" {
+			return nil, fmt.Errorf("malformed registry mirror %q: expected <source>=<mirror>", entry)
+		}
+		mirrors[source] = mirror
+	}
+
+	return mirrors, nil
+}
+
+// getMirroredURL returns rawURL (a helm repository URL, an OCI reference or an image) with the
+// longest matching source replaced by its mirror. rawURL is returned unchanged if no source matches.
+func getMirroredURL(rawURL string) string {
+	scheme, rest := splitURLScheme(rawURL)
+
+	longest := ""
+	for source := range registryMirrors {
+		if (rest == source || strings.HasPrefix(rest, source+"/")) && len(source) > len(longest) {
+			longest = source
+		}
+	}
+	if longest == "" {
+		return rawURL
+	}
+
+	mirror := registryMirrors[longest]
+	if strings.Contains(mirror, "://") {
+		return mirror + strings.TrimPrefix(rest, longest)
+	}
+	return scheme + mirror + strings.TrimPrefix(rest, longest)
+}
+
+// getMirroredChart returns the chart to pull. If the chart repository has a mirror, a copy
+// pulling the chart from the mirror is returned. Credentials, if any, must be valid for the mirror.
+func getMirroredChart(requestedChart *configv1beta1.HelmChart) *configv1beta1.HelmChart {
+	if len(registryMirrors) == 0 || isFluxChartSource(requestedChart) {
+		return requestedChart
+	}
+
+	repositoryURL := getMirroredURL(requestedChart.RepositoryURL)
+	if repositoryURL == requestedChart.RepositoryURL {
+		return requestedChart
+	}
+
+	mirroredChart := requestedChart.DeepCopy()
+	mirroredChart.RepositoryURL = repositoryURL
+	return mirroredChart
+}
+
+func splitURLScheme(rawURL string) (scheme, rest string) {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		return rawURL[:i+len("://")], rawURL[i+len("://"):]
+	}
+	return "", rawURL
+}
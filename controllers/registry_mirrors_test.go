@@ -0,0 +1,78 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Registry mirrors", func() {
+	AfterEach(func() {
+		controllers.SetRegistryMirrors(nil)
+	})
+
+	It("ParseRegistryMirrors parses <source>=<mirror> entries", func() {
+		mirrors, err := controllers.ParseRegistryMirrors(
+			"docker.io=registry.internal/docker.io, https://charts.bitnami.com/bitnami/=https://charts.internal/bitnami")
+		Expect(err).To(BeNil())
+		Expect(mirrors).To(Equal(map[string]string{
+			"docker.io":                  "registry.internal/docker.io",
+			"charts.bitnami.com/bitnami": "https://charts.internal/bitnami",
+		}))
+
+		_, err = controllers.ParseRegistryMirrors("docker.io")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("getMirroredURL replaces the longest matching source", func() {
+		controllers.SetRegistryMirrors(map[string]string{
+			"docker.io":                  "registry.internal/docker.io",
+			"docker.io/bitnamicharts":    "registry.internal/bitnami",
+			"charts.bitnami.com/bitnami": "http://charts.internal/bitnami",
+		})
+
+		Expect(controllers.GetMirroredURL("oci://docker.io/bitnamicharts")).To(Equal("oci://registry.internal/bitnami"))
+		Expect(controllers.GetMirroredURL("oci://docker.io/library")).To(Equal("oci://registry.internal/docker.io/library"))
+		Expect(controllers.GetMirroredURL("docker.io/library/nginx:1.27")).To(
+			Equal("registry.internal/docker.io/library/nginx:1.27"))
+		Expect(controllers.GetMirroredURL("https://charts.bitnami.com/bitnami")).To(Equal("http://charts.internal/bitnami"))
+		// Source matches whole path elements only
+		Expect(controllers.GetMirroredURL("oci://docker.io.example.com/charts")).To(
+			Equal("oci://docker.io.example.com/charts"))
+	})
+
+	It("getMirroredChart returns a copy of the chart pulling from the mirror", func() {
+		controllers.SetRegistryMirrors(map[string]string{"docker.io": "registry.internal"})
+
+		helmChart := &configv1beta1.HelmChart{
+			RepositoryURL: "oci://docker.io/bitnamicharts",
+			ChartName:     "postgresql",
+			ChartVersion:  "16.0.0",
+			ReleaseName:   randomString(),
+		}
+		mirrored := controllers.GetMirroredChart(helmChart)
+		Expect(mirrored.RepositoryURL).To(Equal("oci://registry.internal/bitnamicharts"))
+		Expect(helmChart.RepositoryURL).To(Equal("oci://docker.io/bitnamicharts"))
+
+		helmChart.RepositoryURL = "https://kyverno.github.io/kyverno/"
+		Expect(controllers.GetMirroredChart(helmChart)).To(BeIdenticalTo(helmChart))
+	})
+})
@@ -0,0 +1,237 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/funcmap"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=create
+
+const (
+	// remediationJobTemplateKey is the key, in the remediation ConfigMap, containing the Job template
+	remediationJobTemplateKey = "job"
+
+	// RemediationFeatureLabel is set on remediation Jobs to the feature which failed
+	RemediationFeatureLabel = "projectsveltos.io/remediation-feature"
+)
+
+var (
+	// remediationFailureThreshold is the number of consecutive failures of a feature after which
+	// the remediation hook is invoked. Zero disables the remediation hook.
+	remediationFailureThreshold uint32
+	remediationConfigMapName    string
+	remediationWebhookURL       string
+)
+
+// SetRemediationHook configures the hook invoked once a feature fails failureThreshold consecutive
+// times on a cluster. configMapName is the name of a ConfigMap, in the projectsveltos namespace,
+// containing, in the "job" key, the template of a Job created in the management cluster.
+// webhookURL, if set, receives a POST request with the failure context.
+func SetRemediationHook(failureThreshold uint32, configMapName, webhookURL string) {
+	remediationFailureThreshold = failureThreshold
+	remediationConfigMapName = configMapName
+	remediationWebhookURL = webhookURL
+}
+
+// remediationContext is the information about a chronic failure passed to the remediation hook.
+// It is the payload of the webhook request and the data the Job template is instantiated with.
+type remediationContext struct {
+	ClusterNamespace        string `json:"clusterNamespace"`
+	ClusterName             string `json:"clusterName"`
+	ClusterType             string `json:"clusterType"`
+	ClusterSummaryNamespace string `json:"clusterSummaryNamespace"`
+	ClusterSummaryName      string `json:"clusterSummaryName"`
+	ProfileKind             string `json:"profileKind"`
+	ProfileName             string `json:"profileName"`
+	FeatureID               string `json:"featureID"`
+	ConsecutiveFailures     uint32 `json:"consecutiveFailures"`
+	FailureMessage          string `json:"failureMessage"`
+}
+
+func getRemediationContext(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+) (*remediationContext, error) {
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &remediationContext{
+		ClusterNamespace:        clusterSummary.Spec.ClusterNamespace,
+		ClusterName:             clusterSummary.Spec.ClusterName,
+		ClusterType:             string(clusterSummary.Spec.ClusterType),
+		ClusterSummaryNamespace: clusterSummary.Namespace,
+		ClusterSummaryName:      clusterSummary.Name,
+		ProfileKind:             profileOwnerRef.Kind,
+		ProfileName:             profileOwnerRef.Name,
+		FeatureID:               string(featureID),
+	}
+
+	if fs := getFeatureSummaryForFeatureID(clusterSummary, featureID); fs != nil {
+		rc.ConsecutiveFailures = fs.ConsecutiveFailures
+		if fs.FailureMessage != nil {
+			rc.FailureMessage = *fs.FailureMessage
+		}
+	}
+
+	return rc, nil
+}
+
+// processFeatureFailure records a failed deployment of the feature and, when the number of
+// consecutive failures reaches the remediation threshold, invokes the remediation hook.
+// Hook is invoked only once per streak of failures. Hook failures are logged and do not
+// affect the deployment.
+func (r *ClusterSummaryReconciler) processFeatureFailure(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1beta1.FeatureID, logger logr.Logger) {
+
+	consecutiveFailures := uint32(1)
+	if fs := getFeatureSummaryForFeatureID(clusterSummaryScope.ClusterSummary, featureID); fs != nil {
+		consecutiveFailures = fs.ConsecutiveFailures + 1
+	}
+	clusterSummaryScope.SetConsecutiveFailures(featureID, consecutiveFailures)
+
+	if remediationFailureThreshold == 0 || consecutiveFailures != remediationFailureThreshold {
+		return
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("feature failed %d consecutive times. Invoking remediation hook",
+		consecutiveFailures))
+
+	rc, err := getRemediationContext(clusterSummaryScope.ClusterSummary, featureID)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get remediation context: %v", err))
+		return
+	}
+
+	if remediationConfigMapName != "" {
+		if err := createRemediationJob(ctx, r.Client, rc, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to create remediation Job: %v", err))
+		}
+	}
+
+	if remediationWebhookURL != "" {
+		if err := callRemediationWebhook(ctx, remediationWebhookURL, rc); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to call remediation webhook: %v", err))
+		}
+	}
+}
+
+// getRemediationJob instantiates the Job template with the remediation context. Job is created in
+// the projectsveltos namespace unless the template sets a namespace. If the template does not set a
+// name, one is generated.
+func getRemediationJob(jobTemplate string, rc *remediationContext) (*batchv1.Job, error) {
+	tmpl, err := template.New("remediation").Option("missingkey=error").Funcs(funcmap.SveltosFuncMap()).
+		Parse(jobTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, rc); err != nil {
+		return nil, err
+	}
+
+	job := &batchv1.Job{}
+	if err := yaml.Unmarshal(buffer.Bytes(), job); err != nil {
+		return nil, err
+	}
+
+	if job.Namespace == "" {
+		job.Namespace = projectsveltos
+	}
+	if job.Name == "" && job.GenerateName == "" {
+		job.GenerateName = "sveltos-remediation-"
+	}
+
+	labels := job.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ClusterSummaryLabelName] = rc.ClusterSummaryName
+	labels[RemediationFeatureLabel] = rc.FeatureID
+	job.SetLabels(labels)
+
+	return job, nil
+}
+
+func createRemediationJob(ctx context.Context, c client.Client, rc *remediationContext, logger logr.Logger) error {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: remediationConfigMapName},
+		configMap); err != nil {
+		return err
+	}
+
+	jobTemplate, ok := configMap.Data[remediationJobTemplateKey]
+	if !ok {
+		return fmt.Errorf("configMap %s/%s does not contain key %s", projectsveltos,
+			remediationConfigMapName, remediationJobTemplateKey)
+	}
+
+	job, err := getRemediationJob(jobTemplate, rc)
+	if err != nil {
+		return err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("creating remediation Job in namespace %s", job.Namespace))
+	return c.Create(ctx, job)
+}
+
+func callRemediationWebhook(ctx context.Context, webhookURL string, rc *remediationContext) error {
+	body, err := json.Marshal(rc)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	const timeout = 10 * time.Second
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("remediation webhook request failed with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
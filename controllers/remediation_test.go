@@ -0,0 +1,174 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var _ = Describe("Remediation hook", func() {
+	const (
+		threshold     = 3
+		configMapName = "remediation"
+	)
+
+	var clusterSummary *configv1beta1.ClusterSummary
+	var failureMessage string
+
+	BeforeEach(func() {
+		failureMessage = randomString()
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ClusterProfileKind,
+						Name: randomString(), UID: "1"},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      "Capi",
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusFailed,
+						FailureMessage: &failureMessage},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetRemediationHook(0, "", "")
+	})
+
+	It("processFeatureFailure invokes remediation hook once threshold is reached", func() {
+		var mu sync.Mutex
+		payloads := make([]map[string]interface{}, 0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			payload := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			mu.Lock()
+			payloads = append(payloads, payload)
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		controllers.SetRemediationHook(threshold, configMapName, server.URL)
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "projectsveltos", Name: configMapName},
+			Data: map[string]string{
+				"job": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: remediate-{{ .ClusterName }}
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: remediate
+        image: busybox
+        args: ["{{ .FeatureID }}", "{{ .ClusterNamespace }}"]`,
+			},
+		}
+
+		initObjects := []client.Object{clusterSummary, configMap}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		for i := 1; i <= threshold+1; i++ {
+			controllers.ProcessFeatureFailure(reconciler, context.TODO(), clusterSummaryScope,
+				configv1beta1.FeatureHelm, logger)
+			Expect(clusterSummary.Status.FeatureSummaries[0].ConsecutiveFailures).To(Equal(uint32(i)))
+		}
+
+		// Hook is invoked only once
+		mu.Lock()
+		Expect(payloads).To(HaveLen(1))
+		Expect(payloads[0]["clusterName"]).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(payloads[0]["featureID"]).To(Equal(string(configv1beta1.FeatureHelm)))
+		Expect(payloads[0]["failureMessage"]).To(Equal(failureMessage))
+		Expect(payloads[0]["consecutiveFailures"]).To(BeEquivalentTo(threshold))
+		mu.Unlock()
+
+		jobs := &batchv1.JobList{}
+		Expect(c.List(context.TODO(), jobs)).To(Succeed())
+		Expect(jobs.Items).To(HaveLen(1))
+		job := &jobs.Items[0]
+		Expect(job.Namespace).To(Equal("projectsveltos"))
+		Expect(job.Name).To(Equal("remediate-" + clusterSummary.Spec.ClusterName))
+		Expect(job.Labels).To(HaveKeyWithValue(controllers.RemediationFeatureLabel,
+			string(configv1beta1.FeatureHelm)))
+		Expect(job.Spec.Template.Spec.Containers[0].Args).To(Equal(
+			[]string{string(configv1beta1.FeatureHelm), clusterSummary.Spec.ClusterNamespace}))
+	})
+
+	It("processFeatureFailure only counts failures when remediation hook is not configured", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		controllers.ProcessFeatureFailure(reconciler, context.TODO(), clusterSummaryScope,
+			configv1beta1.FeatureHelm, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(clusterSummary.Status.FeatureSummaries[0].ConsecutiveFailures).To(Equal(uint32(1)))
+
+		jobs := &batchv1.JobList{}
+		Expect(c.List(context.TODO(), jobs)).To(Succeed())
+		Expect(jobs.Items).To(BeEmpty())
+	})
+})
@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
@@ -53,6 +54,16 @@ func collectAndProcessResourceSummaries(ctx context.Context, c client.Client, sh
 
 		for i := range clusterList {
 			cluster := &clusterList[i]
+			var isMatch, unmanaged bool
+			isMatch, err = isClusterAMigrationVersionMatch(ctx, c, cluster)
+			if err != nil || !isMatch {
+				continue
+			}
+			unmanaged, err = isClusterUnmanaged(ctx, c, cluster.Namespace, cluster.Name,
+				clusterproxy.GetClusterType(cluster))
+			if err != nil || unmanaged {
+				continue
+			}
 			err = collectResourceSummariesFromCluster(ctx, c, cluster, version, logger)
 			if err != nil {
 				if !strings.Contains(err.Error(), "unable to retrieve the complete list of server APIs") {
@@ -88,7 +99,7 @@ func collectResourceSummariesFromCluster(ctx context.Context, c client.Client,
 
 	// Use cluster-admin role to collect Sveltos resources from managed clusters
 	var remoteClient client.Client
-	remoteClient, err = clusterproxy.GetKubernetesClient(ctx, c, cluster.Namespace, cluster.Name, "", "",
+	remoteClient, err = secretprovider.GetKubernetesClient(ctx, c, cluster.Namespace, cluster.Name, "", "",
 		clusterproxy.GetClusterType(clusterRef), logger)
 	if err != nil {
 		return err
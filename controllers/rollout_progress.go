@@ -0,0 +1,122 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+const (
+	// rolloutRateWindow is the number of most recently updated clusters the rollout pace is computed on
+	rolloutRateWindow = 10
+)
+
+// updateRolloutProgress sets the ClusterProfile/Profile Status Rollout. A new rollout starts every time
+// the Spec changes. A matching cluster is updated once its ClusterSummary has the current Spec and all of
+// its features are provisioned. The pace is the rolling average of the clusters updated per minute over
+// the rolloutRateWindow most recently updated clusters. Remaining clusters are expected to be updated at
+// the same pace.
+func updateRolloutProgress(profileScope *scope.ProfileScope, matchingClusterSummaries []*configv1beta1.ClusterSummary,
+	matching int, now time.Time) {
+
+	status := profileScope.GetStatus()
+	hash := getProfileSpecHash(profileScope)
+
+	if status.Rollout == nil || !reflect.DeepEqual(status.Rollout.Hash, hash) {
+		status.Rollout = &configv1beta1.RolloutProgress{
+			Hash:      hash,
+			StartTime: metav1.Time{Time: now},
+		}
+	}
+	rollout := status.Rollout
+
+	completions := make([]time.Time, 0, len(matchingClusterSummaries))
+	for i := range matchingClusterSummaries {
+		cs := matchingClusterSummaries[i]
+		if !cs.Status.Ready || cs.Status.CurrentRevision != cs.Generation ||
+			!reflect.DeepEqual(cs.Spec.ClusterProfileSpec, *profileScope.GetSpec()) {
+
+			continue
+		}
+
+		// A cluster already up to date (for instance Spec changed back to a previous version), or
+		// with no recorded sync time, is considered updated when the rollout started
+		completion := rollout.StartTime.Time
+		if cs.Status.LastSuccessfulSyncTime != nil && cs.Status.LastSuccessfulSyncTime.After(completion) {
+			completion = cs.Status.LastSuccessfulSyncTime.Time
+		}
+		completions = append(completions, completion)
+	}
+
+	rollout.UpdatedClusters = int32(len(completions))
+	rollout.RemainingClusters = int32(matching - len(completions))
+	if rollout.RemainingClusters < 0 {
+		rollout.RemainingClusters = 0
+	}
+
+	if rollout.RemainingClusters == 0 {
+		if rollout.CompletionTime == nil {
+			rollout.CompletionTime = &metav1.Time{Time: now}
+		}
+		rollout.EstimatedCompletionTime = nil
+		return
+	}
+	rollout.CompletionTime = nil
+
+	perMinute := getRolloutPace(rollout.StartTime.Time, completions)
+	if perMinute == 0 {
+		rollout.ClustersPerMinute = ""
+		rollout.EstimatedCompletionTime = nil
+		return
+	}
+
+	rollout.ClustersPerMinute = fmt.Sprintf("%.2f", perMinute)
+	remaining := time.Duration(float64(rollout.RemainingClusters) / perMinute * float64(time.Minute))
+	rollout.EstimatedCompletionTime = &metav1.Time{Time: now.Add(remaining)}
+}
+
+// getRolloutPace returns the number of clusters updated per minute over the rolloutRateWindow most
+// recent completions. Returns 0 if the pace cannot be computed yet.
+func getRolloutPace(startTime time.Time, completions []time.Time) float64 {
+	if len(completions) == 0 {
+		return 0
+	}
+
+	sort.Slice(completions, func(i, j int) bool { return completions[i].Before(completions[j]) })
+
+	from := startTime
+	count := len(completions)
+	if count > rolloutRateWindow {
+		from = completions[count-rolloutRateWindow-1]
+		count = rolloutRateWindow
+	}
+
+	elapsed := completions[len(completions)-1].Sub(from)
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(count) / elapsed.Minutes()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var _ = Describe("Rollout progress", func() {
+	It("updateRolloutProgress reports pace and estimated completion time", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				SyncMode: configv1beta1.SyncModeContinuous,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: textlogger.NewLogger(textlogger.NewConfig()), Profile: clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		start := time.Now().Add(-time.Hour)
+		controllers.UpdateRolloutProgress(profileScope, nil, 10, start)
+		rollout := clusterProfile.Status.Rollout
+		Expect(rollout).ToNot(BeNil())
+		Expect(rollout.StartTime.Time).To(Equal(start))
+		Expect(rollout.RemainingClusters).To(Equal(int32(10)))
+		Expect(rollout.EstimatedCompletionTime).To(BeNil())
+
+		// Four clusters updated, one every 5 minutes
+		clusterSummaries := make([]*configv1beta1.ClusterSummary, 0)
+		for i := 1; i <= 4; i++ {
+			clusterSummaries = append(clusterSummaries, &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{Name: randomString(), Generation: 1},
+				Spec:       configv1beta1.ClusterSummarySpec{ClusterProfileSpec: clusterProfile.Spec},
+				Status: configv1beta1.ClusterSummaryStatus{
+					Ready:                  true,
+					CurrentRevision:        1,
+					LastSuccessfulSyncTime: &metav1.Time{Time: start.Add(time.Duration(i*5) * time.Minute)},
+				},
+			})
+		}
+		// A cluster still deploying a previous Spec is not updated
+		clusterSummaries = append(clusterSummaries, &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), Generation: 2},
+			Spec:       configv1beta1.ClusterSummarySpec{ClusterProfileSpec: clusterProfile.Spec},
+			Status: configv1beta1.ClusterSummaryStatus{
+				Ready:                  true,
+				CurrentRevision:        1,
+				LastSuccessfulSyncTime: &metav1.Time{Time: start},
+			},
+		})
+
+		now := start.Add(20 * time.Minute)
+		controllers.UpdateRolloutProgress(profileScope, clusterSummaries, 10, now)
+		rollout = clusterProfile.Status.Rollout
+		Expect(rollout.StartTime.Time).To(Equal(start))
+		Expect(rollout.UpdatedClusters).To(Equal(int32(4)))
+		Expect(rollout.RemainingClusters).To(Equal(int32(6)))
+		Expect(rollout.ClustersPerMinute).To(Equal("0.20"))
+		Expect(rollout.EstimatedCompletionTime).ToNot(BeNil())
+		Expect(rollout.EstimatedCompletionTime.Time).To(BeTemporally("~", now.Add(30*time.Minute), time.Second))
+
+		// All clusters updated
+		controllers.UpdateRolloutProgress(profileScope, clusterSummaries[:4], 4, now)
+		Expect(clusterProfile.Status.Rollout.RemainingClusters).To(BeZero())
+		Expect(clusterProfile.Status.Rollout.CompletionTime).ToNot(BeNil())
+		Expect(clusterProfile.Status.Rollout.EstimatedCompletionTime).To(BeNil())
+
+		// A Spec change starts a new rollout
+		clusterProfile.Spec.SyncMode = configv1beta1.SyncModeOneTime
+		controllers.UpdateRolloutProgress(profileScope, clusterSummaries[:4], 4, now)
+		Expect(clusterProfile.Status.Rollout.StartTime.Time).To(Equal(now))
+		Expect(clusterProfile.Status.Rollout.RemainingClusters).To(Equal(int32(4)))
+	})
+})
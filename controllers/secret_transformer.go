@@ -0,0 +1,394 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+const (
+	externalSecretAPIVersion = "external-secrets.io/v1beta1"
+	externalSecretKind       = "ExternalSecret"
+
+	sealedSecretAPIVersion = "bitnami.com/v1alpha1"
+	sealedSecretKind       = "SealedSecret"
+
+	defaultSealedSecretsCertificateKey = "cert.pem"
+
+	// sealedSecretSessionKeyBytes is the size of the AES-GCM session key used by kubeseal
+	sealedSecretSessionKeyBytes = 32
+
+	// sealedSecretLengthPrefixBytes is the size of the encrypted session key length prefix
+	sealedSecretLengthPrefixBytes = 2
+)
+
+// secretTransformer converts Secrets into the objects configured by a ClusterProfile/Profile
+// SecretTransformer
+type secretTransformer struct {
+	config *configv1beta1.SecretTransformer
+
+	// publicKey is the managed cluster sealed-secrets controller key. Set only for SealedSecret.
+	publicKey *rsa.PublicKey
+}
+
+// validateSecretTransformer verifies SecretTransformer, if set, contains the fields needed by its Type
+func validateSecretTransformer(spec *configv1beta1.Spec) error {
+	transformer := spec.SecretTransformer
+	if transformer == nil {
+		return nil
+	}
+
+	switch transformer.Type {
+	case configv1beta1.SecretTransformerExternalSecret:
+		if transformer.SecretStoreRef == nil {
+			return errors.New("secretTransformer of type ExternalSecret requires secretStoreRef")
+		}
+	case configv1beta1.SecretTransformerSealedSecret:
+		if transformer.CertificateRef == nil {
+			return errors.New("secretTransformer of type SealedSecret requires certificateRef")
+		}
+	}
+	return nil
+}
+
+// getSecretTransformer returns the secretTransformer for the ClusterSummary. Returns nil
+// if Secrets are deployed as they are.
+func getSecretTransformer(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (*secretTransformer, error) {
+
+	config := clusterSummary.Spec.ClusterProfileSpec.SecretTransformer
+	if config == nil {
+		return nil, nil
+	}
+
+	transformer := &secretTransformer{config: config}
+	switch config.Type {
+	case configv1beta1.SecretTransformerExternalSecret:
+		if config.SecretStoreRef == nil {
+			return nil, errors.New("secretTransformer of type ExternalSecret requires secretStoreRef")
+		}
+	case configv1beta1.SecretTransformerSealedSecret:
+		certificate, err := getSealedSecretsCertificate(ctx, c, clusterSummary)
+		if err != nil {
+			return nil, err
+		}
+		transformer.publicKey, err = parseSealedSecretsCertificate(certificate)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported secretTransformer type %q", config.Type)
+	}
+
+	return transformer, nil
+}
+
+// getSealedSecretsCertificateReference returns the ConfigMap/Secret containing the managed cluster
+// sealed-secrets controller certificate
+func getSealedSecretsCertificateReference(clusterSummary *configv1beta1.ClusterSummary,
+) (*corev1.ObjectReference, error) {
+
+	config := clusterSummary.Spec.ClusterProfileSpec.SecretTransformer
+	if config == nil || config.Type != configv1beta1.SecretTransformerSealedSecret || config.CertificateRef == nil {
+		return nil, nil
+	}
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
+		config.CertificateRef.Namespace)
+	name, err := libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), config.CertificateRef.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       config.CertificateRef.Kind,
+		Namespace:  namespace,
+		Name:       name,
+	}, nil
+}
+
+func getSealedSecretsCertificate(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) ([]byte, error) {
+
+	ref, err := getSealedSecretsCertificateReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil {
+		return nil, errors.New("secretTransformer of type SealedSecret requires certificateRef")
+	}
+
+	key := clusterSummary.Spec.ClusterProfileSpec.SecretTransformer.CertificateRef.Key
+	if key == "" {
+		key = defaultSealedSecretsCertificateKey
+	}
+
+	var data []byte
+	var ok bool
+	if ref.Kind == string(libsveltosv1beta1.SecretReferencedResourceKind) {
+		secret, err := getSecret(ctx, c, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+		if err != nil {
+			return nil, err
+		}
+		data, ok = secret.Data[key]
+	} else {
+		configMap, err := getConfigMap(ctx, c, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+		if err != nil {
+			return nil, err
+		}
+		var value string
+		value, ok = configMap.Data[key]
+		data = []byte(value)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%s %s/%s does not contain key %s", ref.Kind, ref.Namespace, ref.Name, key)
+	}
+	return data, nil
+}
+
+// parseSealedSecretsCertificate returns the RSA public key of a PEM encoded certificate
+func parseSealedSecretsCertificate(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("sealed-secrets certificate is not PEM encoded")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sealed-secrets certificate: %w", err)
+	}
+
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sealed-secrets certificate does not contain an RSA public key")
+	}
+	return publicKey, nil
+}
+
+// isSecret returns true if policy is a core Secret
+func isSecret(policy *unstructured.Unstructured) bool {
+	gvk := policy.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// transform returns the object policy must be deployed as. Objects other than Secrets
+// are returned as they are.
+// Namespace of policy must already be set, as SealedSecrets are bound to it.
+func (t *secretTransformer) transform(policy *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if t == nil || !isSecret(policy) {
+		return policy, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(policy.UnstructuredContent(), secret); err != nil {
+		return nil, fmt.Errorf("failed to convert Secret %s/%s: %w", policy.GetNamespace(), policy.GetName(), err)
+	}
+
+	data := make(map[string][]byte, len(secret.Data)+len(secret.StringData))
+	for k := range secret.Data {
+		data[k] = secret.Data[k]
+	}
+	for k := range secret.StringData {
+		data[k] = []byte(secret.StringData[k])
+	}
+
+	switch t.config.Type {
+	case configv1beta1.SecretTransformerExternalSecret:
+		return t.toExternalSecret(secret, data), nil
+	case configv1beta1.SecretTransformerSealedSecret:
+		return t.toSealedSecret(rand.Reader, secret, data)
+	default:
+		return nil, fmt.Errorf("unsupported secretTransformer type %q", t.config.Type)
+	}
+}
+
+func (t *secretTransformer) toExternalSecret(secret *corev1.Secret, data map[string][]byte,
+) *unstructured.Unstructured {
+
+	kind := t.config.SecretStoreRef.Kind
+	if kind == "" {
+		kind = "SecretStore"
+	}
+	remoteKey := fmt.Sprintf("%s%s/%s", t.config.RemoteKeyPrefix, secret.Namespace, secret.Name)
+
+	entries := make([]interface{}, 0, len(data))
+	for _, k := range sortedKeys(data) {
+		entries = append(entries, map[string]interface{}{
+			"secretKey": k,
+			"remoteRef": map[string]interface{}{
+				"key":      remoteKey,
+				"property": k,
+			},
+		})
+	}
+
+	target := map[string]interface{}{
+		"name":           secret.Name,
+		"creationPolicy": "Owner",
+		"template": map[string]interface{}{
+			"type":     string(getSecretType(secret)),
+			"metadata": getSecretTemplateMetadata(secret),
+		},
+	}
+
+	externalSecret := newTransformedSecret(secret, externalSecretAPIVersion, externalSecretKind)
+	externalSecret.Object["spec"] = map[string]interface{}{
+		"secretStoreRef": map[string]interface{}{
+			"name": t.config.SecretStoreRef.Name,
+			"kind": kind,
+		},
+		"target": target,
+		"data":   entries,
+	}
+	return externalSecret
+}
+
+func (t *secretTransformer) toSealedSecret(rnd io.Reader, secret *corev1.Secret, data map[string][]byte,
+) (*unstructured.Unstructured, error) {
+
+	// Strict scope: encrypted values can only be decrypted into a Secret with same namespace and name
+	label := []byte(fmt.Sprintf("%s/%s", secret.Namespace, secret.Name))
+
+	encryptedData := make(map[string]interface{}, len(data))
+	for _, k := range sortedKeys(data) {
+		ciphertext, err := hybridEncrypt(rnd, t.publicKey, data[k], label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal key %s of Secret %s/%s: %w", k, secret.Namespace, secret.Name, err)
+		}
+		encryptedData[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	metadata := getSecretTemplateMetadata(secret)
+	metadata["name"] = secret.Name
+	metadata["namespace"] = secret.Namespace
+
+	sealedSecret := newTransformedSecret(secret, sealedSecretAPIVersion, sealedSecretKind)
+	sealedSecret.Object["spec"] = map[string]interface{}{
+		"encryptedData": encryptedData,
+		"template": map[string]interface{}{
+			"type":     string(getSecretType(secret)),
+			"metadata": metadata,
+		},
+	}
+	return sealedSecret, nil
+}
+
+// hybridEncrypt encrypts plaintext the way kubeseal does: a random AES-GCM session key encrypts
+// plaintext and is itself encrypted with RSA-OAEP. Output is the 2 bytes big endian length of the
+// encrypted session key, the encrypted session key and the encrypted plaintext.
+func hybridEncrypt(rnd io.Reader, publicKey *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sealedSecretSessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, publicKey, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, sealedSecretLengthPrefixBytes)
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+
+	// Session key is used only once, so a zero nonce is safe
+	zeroNonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+// newTransformedSecret returns an object of the given kind with the same name, namespace,
+// labels and annotations of secret
+func newTransformedSecret(secret *corev1.Secret, apiVersion, kind string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(secret.Name)
+	u.SetNamespace(secret.Namespace)
+	u.SetLabels(secret.Labels)
+	u.SetAnnotations(secret.Annotations)
+	return u
+}
+
+func getSecretTemplateMetadata(secret *corev1.Secret) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	if len(secret.Labels) != 0 {
+		metadata["labels"] = toInterfaceMap(secret.Labels)
+	}
+	if len(secret.Annotations) != 0 {
+		metadata["annotations"] = toInterfaceMap(secret.Annotations)
+	}
+	return metadata
+}
+
+func getSecretType(secret *corev1.Secret) corev1.SecretType {
+	if secret.Type == "" {
+		return corev1.SecretTypeOpaque
+	}
+	return secret.Type
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k := range m {
+		result[k] = m[k]
+	}
+	return result
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("SecretTransformer", func() {
+	var secret *unstructured.Unstructured
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		s := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"app": "db"},
+			},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"password": []byte("s3cr3t")},
+			StringData: map[string]string{"user": "admin"},
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(s)
+		Expect(err).To(BeNil())
+		secret = &unstructured.Unstructured{Object: content}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+		}
+	})
+
+	It("transforms Secrets into ExternalSecrets", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SecretTransformer = &configv1beta1.SecretTransformer{
+			Type:            configv1beta1.SecretTransformerExternalSecret,
+			SecretStoreRef:  &configv1beta1.SecretStoreRef{Name: "vault", Kind: "ClusterSecretStore"},
+			RemoteKeyPrefix: "fleet/",
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		transformer, err := controllers.GetSecretTransformer(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+
+		externalSecret, err := transformer.Transform(secret)
+		Expect(err).To(BeNil())
+		Expect(externalSecret.GetKind()).To(Equal("ExternalSecret"))
+		Expect(externalSecret.GetAPIVersion()).To(Equal("external-secrets.io/v1beta1"))
+		Expect(externalSecret.GetNamespace()).To(Equal(secret.GetNamespace()))
+		Expect(externalSecret.GetName()).To(Equal(secret.GetName()))
+		Expect(externalSecret.GetLabels()).To(HaveKeyWithValue("app", "db"))
+
+		storeName, _, err := unstructured.NestedString(externalSecret.Object, "spec", "secretStoreRef", "name")
+		Expect(err).To(BeNil())
+		Expect(storeName).To(Equal("vault"))
+
+		data, _, err := unstructured.NestedSlice(externalSecret.Object, "spec", "data")
+		Expect(err).To(BeNil())
+		Expect(data).To(HaveLen(2))
+		remoteKey := fmt.Sprintf("fleet/%s/%s", secret.GetNamespace(), secret.GetName())
+		for i, key := range []string{"password", "user"} {
+			entry := data[i].(map[string]interface{})
+			Expect(entry["secretKey"]).To(Equal(key))
+			Expect(entry["remoteRef"]).To(Equal(map[string]interface{}{"key": remoteKey, "property": key}))
+		}
+
+		// Plaintext values are not delivered
+		Expect(fmt.Sprintf("%v", externalSecret.Object)).ToNot(ContainSubstring("s3cr3t"))
+
+		// Objects other than Secrets are left untouched
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(randomString())
+		result, err := transformer.Transform(configMap)
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal(configMap))
+	})
+
+	It("transforms Secrets into SealedSecrets", func() {
+		privateKey, certificate := generateSealedSecretsCertificate()
+
+		certificateConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      "sealed-secrets-" + clusterSummary.Spec.ClusterName,
+			},
+			Data: map[string]string{"cert.pem": string(certificate)},
+		}
+
+		clusterSummary.Spec.ClusterProfileSpec.SecretTransformer = &configv1beta1.SecretTransformer{
+			Type: configv1beta1.SecretTransformerSealedSecret,
+			CertificateRef: &configv1beta1.SealedSecretsCertificateRef{
+				Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+				Name: "sealed-secrets-{{ .Cluster.metadata.name }}",
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(certificateConfigMap).Build()
+		transformer, err := controllers.GetSecretTransformer(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+
+		sealedSecret, err := transformer.Transform(secret)
+		Expect(err).To(BeNil())
+		Expect(sealedSecret.GetKind()).To(Equal("SealedSecret"))
+		Expect(sealedSecret.GetAPIVersion()).To(Equal("bitnami.com/v1alpha1"))
+
+		secretType, _, err := unstructured.NestedString(sealedSecret.Object, "spec", "template", "type")
+		Expect(err).To(BeNil())
+		Expect(secretType).To(Equal(string(corev1.SecretTypeOpaque)))
+
+		encryptedData, _, err := unstructured.NestedStringMap(sealedSecret.Object, "spec", "encryptedData")
+		Expect(err).To(BeNil())
+		Expect(encryptedData).To(HaveLen(2))
+
+		label := []byte(fmt.Sprintf("%s/%s", secret.GetNamespace(), secret.GetName()))
+		Expect(unsealValue(privateKey, encryptedData["password"], label)).To(Equal("s3cr3t"))
+		Expect(unsealValue(privateKey, encryptedData["user"], label)).To(Equal("admin"))
+	})
+
+	It("getSecretTransformer fails when the sealed-secrets certificate does not exist", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SecretTransformer = &configv1beta1.SecretTransformer{
+			Type: configv1beta1.SecretTransformerSealedSecret,
+			CertificateRef: &configv1beta1.SealedSecretsCertificateRef{
+				Kind: string(libsveltosv1beta1.SecretReferencedResourceKind),
+				Name: randomString(),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		_, err := controllers.GetSecretTransformer(context.TODO(), c, clusterSummary)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("validateSecretTransformer requires the fields needed by the type", func() {
+		spec := &configv1beta1.Spec{}
+		Expect(controllers.ValidateSecretTransformer(spec)).To(Succeed())
+
+		spec.SecretTransformer = &configv1beta1.SecretTransformer{Type: configv1beta1.SecretTransformerExternalSecret}
+		Expect(controllers.ValidateSecretTransformer(spec)).ToNot(Succeed())
+		spec.SecretTransformer.SecretStoreRef = &configv1beta1.SecretStoreRef{Name: randomString()}
+		Expect(controllers.ValidateSecretTransformer(spec)).To(Succeed())
+
+		spec.SecretTransformer = &configv1beta1.SecretTransformer{Type: configv1beta1.SecretTransformerSealedSecret}
+		Expect(controllers.ValidateSecretTransformer(spec)).ToNot(Succeed())
+	})
+})
+
+// generateSealedSecretsCertificate returns a private key and a PEM encoded self-signed certificate
+// for it, as the sealed-secrets controller does
+func generateSealedSecretsCertificate() (*rsa.PrivateKey, []byte) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).To(BeNil())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	Expect(err).To(BeNil())
+
+	return privateKey, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// unsealValue decrypts a SealedSecret value the way the sealed-secrets controller does
+func unsealValue(privateKey *rsa.PrivateKey, value string, label []byte) string {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	Expect(err).To(BeNil())
+
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext[2:2+rsaLen], label)
+	Expect(err).To(BeNil())
+
+	block, err := aes.NewCipher(sessionKey)
+	Expect(err).To(BeNil())
+	aead, err := cipher.NewGCM(block)
+	Expect(err).To(BeNil())
+
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+rsaLen:], nil)
+	Expect(err).To(BeNil())
+	return string(plaintext)
+}
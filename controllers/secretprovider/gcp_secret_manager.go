@@ -0,0 +1,127 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	gcpSecretManagerScheme = "gcp-sm"
+
+	defaultGCPSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+	defaultGCEMetadataHost          = "metadata.google.internal"
+)
+
+// gcpSecretManagerProvider reads secrets from Google Cloud Secret Manager.
+// URI format is gcp-sm://<project>/<secret>[?key=<key>&version=<version>]. Version
+// defaults to latest.
+// Access token is read from the GOOGLE_OAUTH_ACCESS_TOKEN environment variable or,
+// when not set, requested to the GCE metadata server (workload identity).
+// GOOGLE_SECRET_MANAGER_ENDPOINT overrides the Secret Manager endpoint.
+type gcpSecretManagerProvider struct{}
+
+func (p *gcpSecretManagerProvider) GetSecret(ctx context.Context, uri *url.URL) ([]byte, error) {
+	project := uri.Host
+	secret := strings.Trim(uri.Path, "/")
+	if project == "" || secret == "" || strings.Contains(secret, "/") {
+		return nil, fmt.Errorf("invalid GCP Secret Manager URI %s: expected gcp-sm://<project>/<secret>",
+			uri.Redacted())
+	}
+
+	version := uri.Query().Get("version")
+	if version == "" {
+		version = "latest"
+	}
+
+	token, err := getGCPAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := os.Getenv("GOOGLE_SECRET_MANAGER_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultGCPSecretManagerEndpoint
+	}
+	endpoint = fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/%s:access",
+		strings.TrimSuffix(endpoint, "/"), url.PathEscape(project), url.PathEscape(secret),
+		url.PathEscape(version))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP Secret Manager response: %w", err)
+	}
+
+	return selectKey(uri, response.Payload.Data)
+}
+
+func getGCPAccessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = defaultGCEMetadataHost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("http://%s/computeMetadata/v1/instance/service-accounts/default/token", host), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	body, err := doRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token from metadata server: %w", err)
+	}
+
+	response := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse metadata server response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return "", errors.New("metadata server returned an empty access token")
+	}
+
+	return response.AccessToken, nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// KubeconfigProviderAnnotation can be set on a SveltosCluster to fetch its kubeconfig
+	// from an external secret provider instead of a Kubernetes Secret. Value is a URI
+	// whose scheme selects the provider, for instance:
+	// - vault://secret/clusters/production?key=kubeconfig
+	// - aws-sm://us-east-1/clusters/production
+	// - gcp-sm://my-project/production-kubeconfig?version=3
+	KubeconfigProviderAnnotation = "projectsveltos.io/kubeconfig-provider"
+
+	// keyQueryParam selects, when the secret is a JSON object (or a Vault secret), the
+	// field containing the kubeconfig
+	keyQueryParam = "key"
+
+	defaultCacheTTL = 5 * time.Minute
+
+	// maxSecretSize is the maximum size of a response read from a provider
+	maxSecretSize = 1 << 20
+
+	// kubernetesAdmin is the admin name clusterproxy maps to the cluster kubeconfig
+	kubernetesAdmin = "kubernetes-admin"
+)
+
+// Provider fetches secrets from an external secret store
+type Provider interface {
+	// GetSecret returns the content of the secret identified by uri
+	GetSecret(ctx context.Context, uri *url.URL) ([]byte, error)
+}
+
+type cacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+var (
+	mu        = &sync.RWMutex{}
+	providers = map[string]Provider{
+		vaultScheme:             &vaultProvider{},
+		awsSecretsManagerScheme: &awsSecretsManagerProvider{},
+		gcpSecretManagerScheme:  &gcpSecretManagerProvider{},
+	}
+	cache    = map[string]*cacheEntry{}
+	cacheTTL = defaultCacheTTL
+
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// RegisterProvider registers p as the provider for URIs with the given scheme,
+// replacing any provider previously registered for it
+func RegisterProvider(scheme string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	providers[scheme] = p
+}
+
+// SetCacheTTL sets for how long secrets fetched from a provider are cached.
+// A ttl of zero disables caching.
+func SetCacheTTL(ttl time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cacheTTL = ttl
+	cache = map[string]*cacheEntry{}
+}
+
+// GetSecret returns the content of the secret identified by uri. Content is fetched
+// from the provider registered for the uri scheme and cached for the configured TTL.
+func GetSecret(ctx context.Context, uri string) ([]byte, error) {
+	now := time.Now()
+
+	mu.RLock()
+	entry, ok := cache[uri]
+	mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.data, nil
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret provider URI: %w", err)
+	}
+
+	mu.RLock()
+	p, ok := providers[u.Scheme]
+	ttl := cacheTTL
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no secret provider registered for scheme %q", u.Scheme)
+	}
+
+	data, err := p.GetSecret(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret from %s provider: %w", u.Scheme, err)
+	}
+
+	if ttl > 0 {
+		mu.Lock()
+		cache[uri] = &cacheEntry{data: data, expiresAt: now.Add(ttl)}
+		mu.Unlock()
+	}
+
+	return data, nil
+}
+
+// ForgetSecret removes from the cache the content of the secret identified by uri, so
+// that it is fetched again from the provider on next access
+func ForgetSecret(uri string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(cache, uri)
+}
+
+// GetKubeconfigURI returns the secret provider URI the kubeconfig of the cluster must
+// be fetched from. An empty string is returned if kubeconfig is stored in a Secret.
+func GetKubeconfigURI(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) (string, error) {
+
+	if clusterType != libsveltosv1beta1.ClusterTypeSveltos {
+		return "", nil
+	}
+
+	sveltosCluster := &libsveltosv1beta1.SveltosCluster{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: clusterNamespace, Name: clusterName}, sveltosCluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Let the caller report a missing cluster
+			return "", nil
+		}
+		return "", err
+	}
+
+	return sveltosCluster.Annotations[KubeconfigProviderAnnotation], nil
+}
+
+// GetSecretData returns the kubeconfig of the managed cluster. Same as clusterproxy.GetSecretData
+// but the kubeconfig of SveltosClusters referencing a secret provider is fetched from it.
+func GetSecretData(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) ([]byte, error) {
+
+	uri, err := getProviderURI(ctx, c, clusterNamespace, clusterName, adminName, clusterType)
+	if err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+			adminNamespace, adminName, clusterType, logger)
+	}
+
+	logger.V(logs.LogVerbose).Info("get kubeconfig from secret provider")
+	return GetSecret(ctx, uri)
+}
+
+// GetKubernetesRestConfig returns the restConfig of the managed cluster. Same as
+// clusterproxy.GetKubernetesRestConfig but the kubeconfig of SveltosClusters referencing
+// a secret provider is fetched from it.
+func GetKubernetesRestConfig(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (*rest.Config, error) {
+
+	uri, err := getProviderURI(ctx, c, clusterNamespace, clusterName, adminName, clusterType)
+	if err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
+			adminNamespace, adminName, clusterType, logger)
+	}
+
+	logger.V(logs.LogVerbose).Info("get kubeconfig from secret provider")
+	return GetKubernetesRestConfigFromURI(ctx, uri)
+}
+
+// GetKubernetesRestConfigFromURI returns the restConfig built from the kubeconfig stored
+// in the secret provider at uri
+func GetKubernetesRestConfigFromURI(ctx context.Context, uri string) (*rest.Config, error) {
+	kubeconfigContent, err := GetSecret(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfigContent)
+}
+
+// GetKubernetesClient returns a client for the managed cluster. Same as
+// clusterproxy.GetKubernetesClient but the kubeconfig of SveltosClusters referencing
+// a secret provider is fetched from it.
+func GetKubernetesClient(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (client.Client, error) {
+
+	config, err := GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(logs.LogVerbose).Info("return new client")
+	return client.New(config, client.Options{Scheme: c.Scheme()})
+}
+
+// getProviderURI returns the secret provider URI for the cluster kubeconfig. Kubeconfigs
+// for tenant admins are generated by Sveltos and always stored in Secrets.
+func getProviderURI(ctx context.Context, c client.Client, clusterNamespace, clusterName, adminName string,
+	clusterType libsveltosv1beta1.ClusterType) (string, error) {
+
+	if adminName != "" && adminName != kubernetesAdmin {
+		return "", nil
+	}
+
+	return GetKubeconfigURI(ctx, c, clusterNamespace, clusterName, clusterType)
+}
+
+// selectKey returns data unchanged if uri does not select a key. Otherwise data must
+// be a JSON object and the value of the selected key is returned.
+func selectKey(uri *url.URL, data []byte) ([]byte, error) {
+	key := uri.Query().Get(keyQueryParam)
+	if key == "" {
+		return data, nil
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("secret is not a JSON object, cannot select key %s: %w", key, err)
+	}
+
+	return getStringField(fields, key)
+}
+
+func getStringField(fields map[string]interface{}, key string) ([]byte, error) {
+	v, ok := fields[key]
+	if !ok {
+		return nil, fmt.Errorf("secret does not contain key %s", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("secret key %s is not a string", key)
+	}
+	return []byte(s), nil
+}
+
+// doRequest sends req and returns the response body. An error is returned if the
+// response status is not 2xx.
+func doRequest(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("request to %s failed with status %d", req.URL.Host, resp.StatusCode)
+	}
+
+	return body, nil
+}
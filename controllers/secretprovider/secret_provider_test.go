@@ -0,0 +1,224 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretprovider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers/secretprovider"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: workload
+  cluster:
+    server: https://workload.example.com:6443
+contexts:
+- name: workload
+  context:
+    cluster: workload
+    user: admin
+current-context: workload
+users:
+- name: admin
+  user:
+    token: secret-token
+`
+
+type countingProvider struct {
+	calls int
+	data  []byte
+}
+
+func (p *countingProvider) GetSecret(_ context.Context, _ *url.URL) ([]byte, error) {
+	p.calls++
+	return p.data, nil
+}
+
+var _ = Describe("SecretProvider", func() {
+	AfterEach(func() {
+		secretprovider.SetCacheTTL(time.Minute)
+	})
+
+	It("GetSecret caches content returned by provider", func() {
+		scheme := randomString()
+		p := &countingProvider{data: []byte(kubeconfig)}
+		secretprovider.RegisterProvider(scheme, p)
+
+		uri := fmt.Sprintf("%s://%s", scheme, randomString())
+		secretprovider.SetCacheTTL(time.Minute)
+		for i := 0; i < 3; i++ {
+			data, err := secretprovider.GetSecret(context.TODO(), uri)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(kubeconfig))
+		}
+		Expect(p.calls).To(Equal(1))
+
+		secretprovider.SetCacheTTL(0)
+		for i := 0; i < 2; i++ {
+			_, err := secretprovider.GetSecret(context.TODO(), uri)
+			Expect(err).To(BeNil())
+		}
+		Expect(p.calls).To(Equal(3))
+	})
+
+	It("ForgetSecret removes cached content", func() {
+		scheme := randomString()
+		p := &countingProvider{data: []byte(kubeconfig)}
+		secretprovider.RegisterProvider(scheme, p)
+
+		uri := fmt.Sprintf("%s://%s", scheme, randomString())
+		secretprovider.SetCacheTTL(time.Minute)
+		_, err := secretprovider.GetSecret(context.TODO(), uri)
+		Expect(err).To(BeNil())
+		_, err = secretprovider.GetSecret(context.TODO(), uri)
+		Expect(err).To(BeNil())
+		Expect(p.calls).To(Equal(1))
+
+		secretprovider.ForgetSecret(uri)
+		_, err = secretprovider.GetSecret(context.TODO(), uri)
+		Expect(err).To(BeNil())
+		Expect(p.calls).To(Equal(2))
+	})
+
+	It("GetSecret fails for unknown scheme", func() {
+		_, err := secretprovider.GetSecret(context.TODO(), "unknown://"+randomString())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("vault provider reads key from KV version 2 secret", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/secret/data/clusters/production" || r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data": map[string]string{"config": kubeconfig},
+				},
+			})
+		}))
+		defer server.Close()
+
+		GinkgoT().Setenv("VAULT_ADDR", server.URL)
+		GinkgoT().Setenv("VAULT_TOKEN", "vault-token")
+		secretprovider.SetCacheTTL(0)
+
+		data, err := secretprovider.GetSecret(context.TODO(), "vault://secret/clusters/production?key=config")
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(kubeconfig))
+
+		_, err = secretprovider.GetSecret(context.TODO(), "vault://secret/clusters/staging?key=config")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("aws-sm provider sends a signed GetSecretValue request", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			input := map[string]string{}
+			_ = json.Unmarshal(body, &input)
+			if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+				!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+				!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request") ||
+				input["SecretId"] != "clusters/production" {
+
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			secretString, _ := json.Marshal(map[string]string{"kubeconfig": kubeconfig})
+			_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": string(secretString)})
+		}))
+		defer server.Close()
+
+		GinkgoT().Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		secretprovider.SetCacheTTL(0)
+
+		data, err := secretprovider.GetSecret(context.TODO(), "aws-sm://eu-west-1/clusters/production?key=kubeconfig")
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(kubeconfig))
+	})
+
+	It("gcp-sm provider accesses secret version", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/projects/my-project/secrets/production/versions/latest:access" ||
+				r.Header.Get("Authorization") != "Bearer gcp-token" {
+
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(kubeconfig))},
+			})
+		}))
+		defer server.Close()
+
+		GinkgoT().Setenv("GOOGLE_SECRET_MANAGER_ENDPOINT", server.URL)
+		GinkgoT().Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "gcp-token")
+		secretprovider.SetCacheTTL(0)
+
+		data, err := secretprovider.GetSecret(context.TODO(), "gcp-sm://my-project/production")
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(kubeconfig))
+	})
+
+	It("GetKubernetesRestConfig uses kubeconfig from provider referenced by SveltosCluster", func() {
+		scheme := randomString()
+		secretprovider.RegisterProvider(scheme, &countingProvider{data: []byte(kubeconfig)})
+
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Annotations: map[string]string{
+					secretprovider.KubeconfigProviderAnnotation: fmt.Sprintf("%s://%s", scheme, randomString()),
+				},
+			},
+		}
+
+		s := runtime.NewScheme()
+		Expect(libsveltosv1beta1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(sveltosCluster).Build()
+
+		config, err := secretprovider.GetKubernetesRestConfig(context.TODO(), c, sveltosCluster.Namespace,
+			sveltosCluster.Name, "", "", libsveltosv1beta1.ClusterTypeSveltos,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(config.Host).To(Equal("https://workload.example.com:6443"))
+		Expect(config.BearerToken).To(Equal("secret-token"))
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	vaultScheme = "vault"
+
+	// defaultVaultKey is the key of the Vault secret containing the kubeconfig when
+	// none is set in the URI
+	defaultVaultKey = "kubeconfig"
+)
+
+// vaultProvider reads secrets from a Vault KV version 2 secrets engine.
+// URI format is vault://<mount>/<path>[?key=<key>&version=<version>].
+// Vault address and token are read from the VAULT_ADDR and VAULT_TOKEN environment
+// variables (VAULT_NAMESPACE is honored when set).
+type vaultProvider struct{}
+
+func (p *vaultProvider) GetSecret(ctx context.Context, uri *url.URL) ([]byte, error) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+
+	secretPath := strings.Trim(uri.Path, "/")
+	if uri.Host == "" || secretPath == "" {
+		return nil, fmt.Errorf("invalid vault URI %s: expected vault://<mount>/<path>", uri.Redacted())
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(address, "/"), uri.Host, secretPath)
+	if version := uri.Query().Get("version"); version != "" {
+		endpoint += "?version=" + url.QueryEscape(version)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	key := uri.Query().Get(keyQueryParam)
+	if key == "" {
+		key = defaultVaultKey
+	}
+	return getStringField(response.Data.Data, key)
+}
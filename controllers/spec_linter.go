@@ -0,0 +1,182 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+// lintSpec returns warnings about common pitfalls in a ClusterProfile/Profile Spec.
+// Unlike validation errors, warnings never prevent a ClusterProfile/Profile from being accepted.
+func lintSpec(spec *configv1beta1.Spec) []string {
+	var warnings []string
+
+	warnings = append(warnings, lintHelmReleaseCollisions(spec)...)
+	warnings = append(warnings, lintInlineResourceTemplates(spec)...)
+	warnings = append(warnings, lintDeprecatedFields(spec)...)
+	warnings = append(warnings, lintClusterSelector(spec)...)
+
+	return warnings
+}
+
+// lintHelmReleaseCollisions warns when the same helm release is deployed by more than one helm chart.
+// Release names and namespaces expressed as templates are compared as written, since they are only
+// instantiated per cluster (see checkInstantiatedHelmReleaseCollisions).
+func lintHelmReleaseCollisions(spec *configv1beta1.Spec) []string {
+	var warnings []string
+
+	releases := make(map[string]string)
+	for i := range spec.HelmCharts {
+		chart := &spec.HelmCharts[i]
+		release := fmt.Sprintf("%s/%s", chart.ReleaseNamespace, chart.ReleaseName)
+		if otherChart, ok := releases[release]; ok {
+			warnings = append(warnings,
+				fmt.Sprintf("helm release %s is deployed by both chart %s and chart %s: only one will be deployed",
+					release, otherChart, chart.ChartName))
+			continue
+		}
+		releases[release] = chart.ChartName
+	}
+
+	return warnings
+}
+
+// checkInstantiatedHelmReleaseCollisions returns an error if a helm chart whose release name or namespace
+// is a template is instantiated, for the cluster clusterSummary is for, to the helm release of another
+// helm chart. Collisions between releases not expressed as templates are reported by the spec linter.
+func checkInstantiatedHelmReleaseCollisions(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) error {
+
+	if !hasHelmReleaseTemplates(clusterSummary) {
+		return nil
+	}
+
+	helmCharts, err := getInstantiatedHelmCharts(ctx, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	var collisions []string
+	releases := make(map[string]int)
+	for i := range helmCharts {
+		chart := &helmCharts[i]
+		release := fmt.Sprintf("%s/%s", chart.ReleaseNamespace, chart.ReleaseName)
+		if j, ok := releases[release]; ok {
+			if isHelmReleaseTemplate(&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]) ||
+				isHelmReleaseTemplate(&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[j]) {
+
+				collisions = append(collisions, fmt.Sprintf("helm release %s is deployed by both chart %s and chart %s",
+					release, helmCharts[j].ChartName, chart.ChartName))
+			}
+			continue
+		}
+		releases[release] = i
+	}
+
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	return &NonRetriableError{Message: strings.Join(collisions, "; ")}
+}
+
+// lintInlineResourceTemplates warns when an inline resource looks like a template but is not
+// marked as such: its content would be deployed as is
+func lintInlineResourceTemplates(spec *configv1beta1.Spec) []string {
+	var warnings []string
+
+	for i := range spec.InlineResources {
+		inline := &spec.InlineResources[i]
+		if !inline.Template && strings.Contains(inline.Content, "{{") {
+			warnings = append(warnings,
+				fmt.Sprintf("inline resource %s contains template expressions but template is not set: "+
+					"it will be deployed without being instantiated", inline.Name))
+		}
+	}
+
+	return warnings
+}
+
+// lintDeprecatedFields warns when deprecated fields are set
+func lintDeprecatedFields(spec *configv1beta1.Spec) []string {
+	var warnings []string
+
+	if len(spec.ExtraLabels) != 0 {
+		warnings = append(warnings, "extraLabels is deprecated: use patches instead")
+	}
+	if len(spec.ExtraAnnotations) != 0 {
+		warnings = append(warnings, "extraAnnotations is deprecated: use patches instead")
+	}
+
+	return warnings
+}
+
+// lintClusterSelector warns when the ClusterSelector matches no cluster or, having only negative
+// requirements, every cluster but few
+func lintClusterSelector(spec *configv1beta1.Spec) []string {
+	selector := &spec.ClusterSelector.LabelSelector
+
+	if len(selector.MatchLabels) != 0 {
+		return nil
+	}
+
+	if len(selector.MatchExpressions) == 0 {
+		if len(spec.ClusterRefs) == 0 && len(spec.SetRefs) == 0 {
+			return []string{"clusterSelector, clusterRefs and setRefs are all empty: no cluster is matched"}
+		}
+		return nil
+	}
+
+	for i := range selector.MatchExpressions {
+		switch selector.MatchExpressions[i].Operator {
+		case metav1.LabelSelectorOpNotIn, metav1.LabelSelectorOpDoesNotExist:
+		default:
+			return nil
+		}
+	}
+
+	return []string{"clusterSelector only has NotIn/DoesNotExist requirements: " +
+		"every cluster, including clusters created later, not explicitly excluded is matched"}
+}
+
+// updateSpecWarningsCondition sets the ClusterProfile/Profile SpecWarningsCondition
+func updateSpecWarningsCondition(profileScope *scope.ProfileScope) {
+	condition := metav1.Condition{
+		Type:               configv1beta1.SpecWarningsCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.NoSpecPitfallsReason,
+		Message:            "no common pitfall found",
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+
+	if warnings := lintSpec(profileScope.GetSpec()); len(warnings) != 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.SpecPitfallsFoundReason
+		condition.Message = strings.Join(warnings, "; ")
+	}
+
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+}
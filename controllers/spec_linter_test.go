@@ -0,0 +1,155 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Spec linter", func() {
+	var spec *configv1beta1.Spec
+
+	BeforeEach(func() {
+		spec = &configv1beta1.Spec{
+			ClusterSelector: libsveltosv1beta1.Selector{
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "fv"}},
+			},
+		}
+	})
+
+	It("lintSpec returns no warning for a spec without pitfalls", func() {
+		spec.HelmCharts = []configv1beta1.HelmChart{
+			{ChartName: "kyverno/kyverno", ReleaseName: "kyverno", ReleaseNamespace: "kyverno"},
+			{ChartName: "prometheus/prometheus", ReleaseName: "prometheus", ReleaseNamespace: "kyverno"},
+		}
+		spec.InlineResources = []configv1beta1.InlineResource{
+			{Name: "ns", Template: true, Content: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: {{ .Cluster.metadata.name }}\n"},
+		}
+		Expect(controllers.LintSpec(spec)).To(BeEmpty())
+	})
+
+	It("lintSpec warns about helm release collisions", func() {
+		spec.HelmCharts = []configv1beta1.HelmChart{
+			{ChartName: "kyverno/kyverno", ReleaseName: "policy", ReleaseNamespace: "kyverno"},
+			{ChartName: "kyverno/kyverno-policies", ReleaseName: "policy", ReleaseNamespace: "kyverno"},
+		}
+		warnings := controllers.LintSpec(spec)
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("kyverno/policy"))
+	})
+
+	It("lintSpec warns about templated inline resources without template set", func() {
+		spec.InlineResources = []configv1beta1.InlineResource{
+			{Name: "ns", Content: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: {{ .Cluster.metadata.name }}\n"},
+		}
+		warnings := controllers.LintSpec(spec)
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("inline resource ns"))
+	})
+
+	It("lintSpec warns about deprecated fields", func() {
+		spec.ExtraLabels = map[string]string{"team": "platform"}
+		spec.ExtraAnnotations = map[string]string{"owner": "platform"}
+		Expect(controllers.LintSpec(spec)).To(HaveLen(2))
+	})
+
+	It("lintSpec warns about selectors matching no cluster or almost every cluster", func() {
+		spec.ClusterSelector = libsveltosv1beta1.Selector{}
+		Expect(controllers.LintSpec(spec)).To(HaveLen(1))
+
+		spec.ClusterRefs = []corev1.ObjectReference{{Namespace: randomString(), Name: randomString()}}
+		Expect(controllers.LintSpec(spec)).To(BeEmpty())
+
+		spec.ClusterSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"production"}},
+		}
+		Expect(controllers.LintSpec(spec)).To(HaveLen(1))
+
+		spec.ClusterSelector.MatchExpressions = append(spec.ClusterSelector.MatchExpressions,
+			metav1.LabelSelectorRequirement{Key: "region", Operator: metav1.LabelSelectorOpExists})
+		Expect(controllers.LintSpec(spec)).To(BeEmpty())
+	})
+
+	It("ClusterProfile webhook returns warnings without rejecting", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec:       *spec,
+		}
+		clusterProfile.Spec.ExtraLabels = map[string]string{"team": "platform"}
+
+		validator := &controllers.ClusterProfileValidator{}
+		warnings, err := validator.ValidateCreate(context.TODO(), clusterProfile)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(HaveLen(1))
+	})
+
+	It("checkInstantiatedHelmReleaseCollisions detects helm releases colliding once instantiated", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sveltosCluster.Namespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(testEnv.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, sveltosCluster)).To(Succeed())
+
+		spec.HelmCharts = []configv1beta1.HelmChart{
+			{ChartName: "ingress/ingress", ReleaseName: "{{ .Cluster.metadata.labels.env }}-ingress", ReleaseNamespace: "ingress"},
+			{ChartName: "ingress/ingress-prod", ReleaseName: "prod-ingress", ReleaseNamespace: "ingress"},
+		}
+		// Collision only exists once release name is instantiated
+		Expect(controllers.LintSpec(spec)).To(BeEmpty())
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace:   sveltosCluster.Namespace,
+				ClusterName:        sveltosCluster.Name,
+				ClusterType:        libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: *spec,
+			},
+		}
+
+		err := controllers.CheckInstantiatedHelmReleaseCollisions(context.TODO(), clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[1].ReleaseName = "staging-ingress"
+		Expect(controllers.CheckInstantiatedHelmReleaseCollisions(context.TODO(), clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+	})
+})
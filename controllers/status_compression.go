@@ -0,0 +1,119 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+var (
+	// statusCompressionThreshold is the size, in bytes, above which ClusterReport messages
+	// and ClusterConfiguration resource lists are stored compressed. 0 disables compression.
+	statusCompressionThreshold int
+)
+
+// SetStatusCompressionThreshold sets the size, in bytes, above which status payloads are compressed
+func SetStatusCompressionThreshold(threshold int) {
+	statusCompressionThreshold = threshold
+}
+
+func shouldCompress(size int) bool {
+	return statusCompressionThreshold > 0 && size > statusCompressionThreshold
+}
+
+// compressMessage returns message compressed if its size is above the threshold.
+func compressMessage(message string) (string, error) {
+	if !shouldCompress(len(message)) || configv1beta1.IsCompressedPayload(message) {
+		return message, nil
+	}
+	return configv1beta1.CompressPayload([]byte(message))
+}
+
+// compressResourceReports returns a copy of resourceReports where messages above the
+// threshold are compressed. Use ResourceReport.GetMessage to read those.
+func compressResourceReports(resourceReports []configv1beta1.ResourceReport,
+) ([]configv1beta1.ResourceReport, error) {
+
+	if statusCompressionThreshold == 0 || resourceReports == nil {
+		return resourceReports, nil
+	}
+
+	result := make([]configv1beta1.ResourceReport, len(resourceReports))
+	for i := range resourceReports {
+		result[i] = resourceReports[i]
+		message, err := compressMessage(resourceReports[i].Message)
+		if err != nil {
+			return nil, err
+		}
+		result[i].Message = message
+	}
+
+	return result, nil
+}
+
+// compressReleaseReports returns a copy of releaseReports where messages above the
+// threshold are compressed. Use ReleaseReport.GetMessage to read those.
+func compressReleaseReports(releaseReports []configv1beta1.ReleaseReport,
+) ([]configv1beta1.ReleaseReport, error) {
+
+	if statusCompressionThreshold == 0 || releaseReports == nil {
+		return releaseReports, nil
+	}
+
+	result := make([]configv1beta1.ReleaseReport, len(releaseReports))
+	for i := range releaseReports {
+		result[i] = releaseReports[i]
+		message, err := compressMessage(releaseReports[i].Message)
+		if err != nil {
+			return nil, err
+		}
+		result[i].Message = message
+	}
+
+	return result, nil
+}
+
+// setFeatureResources stores resources in feature. When the serialized list is above the
+// threshold, resources are stored in CompressedResources. Use Feature.GetResources to read those.
+func setFeatureResources(feature *configv1beta1.Feature, resources []configv1beta1.Resource) error {
+	feature.Resources = resources
+	feature.CompressedResources = ""
+
+	if statusCompressionThreshold == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+
+	if !shouldCompress(len(data)) {
+		return nil
+	}
+
+	compressed, err := configv1beta1.CompressPayload(data)
+	if err != nil {
+		return err
+	}
+
+	feature.Resources = nil
+	feature.CompressedResources = compressed
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Status compression", func() {
+	AfterEach(func() {
+		controllers.SetStatusCompressionThreshold(0)
+	})
+
+	It("compressResourceReports compresses only messages above threshold", func() {
+		controllers.SetStatusCompressionThreshold(64)
+
+		long := strings.Repeat(randomString(), 20)
+		resourceReports := []configv1beta1.ResourceReport{
+			{Action: string(configv1beta1.UpdateResourceAction), Message: long},
+			{Action: string(configv1beta1.NoResourceAction), Message: "short"},
+		}
+
+		result, err := controllers.CompressResourceReports(resourceReports)
+		Expect(err).To(BeNil())
+		Expect(result).To(HaveLen(2))
+		Expect(configv1beta1.IsCompressedPayload(result[0].Message)).To(BeTrue())
+		Expect(result[1].Message).To(Equal("short"))
+
+		// Original reports are not modified
+		Expect(resourceReports[0].Message).To(Equal(long))
+
+		message, err := result[0].GetMessage()
+		Expect(err).To(BeNil())
+		Expect(message).To(Equal(long))
+	})
+
+	It("compressReleaseReports leaves reports unchanged when compression is disabled", func() {
+		long := strings.Repeat(randomString(), 20)
+		releaseReports := []configv1beta1.ReleaseReport{
+			{ReleaseName: randomString(), Message: long},
+		}
+
+		result, err := controllers.CompressReleaseReports(releaseReports)
+		Expect(err).To(BeNil())
+		Expect(result[0].Message).To(Equal(long))
+	})
+
+	It("setFeatureResources stores large resource lists compressed", func() {
+		resources := make([]configv1beta1.Resource, 0)
+		for i := 0; i < 10; i++ {
+			resources = append(resources, configv1beta1.Resource{
+				Name: randomString(), Namespace: randomString(), Kind: "ConfigMap", Version: "v1",
+			})
+		}
+
+		feature := &configv1beta1.Feature{FeatureID: configv1beta1.FeatureResources}
+		Expect(controllers.SetFeatureResources(feature, resources)).To(Succeed())
+		Expect(feature.Resources).To(HaveLen(len(resources)))
+		Expect(feature.CompressedResources).To(BeEmpty())
+
+		controllers.SetStatusCompressionThreshold(128)
+		Expect(controllers.SetFeatureResources(feature, resources)).To(Succeed())
+		Expect(feature.Resources).To(BeNil())
+		Expect(configv1beta1.IsCompressedPayload(feature.CompressedResources)).To(BeTrue())
+
+		current, err := feature.GetResources()
+		Expect(err).To(BeNil())
+		Expect(current).To(Equal(resources))
+	})
+})
@@ -0,0 +1,98 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// updateClusterSummaryStatusSummary sets the ClusterSummary Status fields summarizing the
+// deployment state. Those are shown by kubectl get clustersummaries.
+func updateClusterSummaryStatusSummary(clusterSummary *configv1beta1.ClusterSummary) {
+	clusterSummary.Status.Ready = clusterSummary.Status.LastSuccessfulSyncTime != nil && isSynced(clusterSummary)
+
+	clusterSummary.Status.FailedFeature = ""
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.Status == configv1beta1.FeatureStatusFailed ||
+			fs.Status == configv1beta1.FeatureStatusFailedNonRetriable {
+
+			clusterSummary.Status.FailedFeature = fs.FeatureID
+			return
+		}
+	}
+}
+
+// updateProfileStatusSummary sets the ClusterProfile/Profile Status fields summarizing the
+// rollout state. Those are shown by kubectl get clusterprofiles/profiles.
+func updateProfileStatusSummary(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	status := profileScope.GetStatus()
+
+	// Clusters handed over to the successor are not managed by this profile anymore
+	migratedClusters := getMigratedClusters(profileScope)
+	matching := make(map[string]bool)
+	for i := range status.MatchingClusterRefs {
+		cluster := &status.MatchingClusterRefs[i]
+		if !migratedClusters.Has(cluster) {
+			matching[fmt.Sprintf("%s-%s-%s", clusterproxy.GetClusterType(cluster), cluster.Namespace, cluster.Name)] = true
+		}
+	}
+
+	listOptions := []client.ListOption{}
+	if profileScope.Profile.GetObjectKind().GroupVersionKind().Kind == configv1beta1.ClusterProfileKind {
+		listOptions = append(listOptions, client.MatchingLabels{ClusterProfileLabelName: profileScope.Name()})
+	} else {
+		listOptions = append(listOptions,
+			client.MatchingLabels{ProfileLabelName: profileScope.Name()},
+			client.InNamespace(profileScope.Profile.GetNamespace()))
+	}
+
+	clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaryList, listOptions...); err != nil {
+		return err
+	}
+
+	var ready, failed int32
+	matchingClusterSummaries := make([]*configv1beta1.ClusterSummary, 0, len(clusterSummaryList.Items))
+	for i := range clusterSummaryList.Items {
+		cs := &clusterSummaryList.Items[i]
+		if !matching[fmt.Sprintf("%s-%s-%s", cs.Spec.ClusterType, cs.Spec.ClusterNamespace, cs.Spec.ClusterName)] {
+			continue
+		}
+		matchingClusterSummaries = append(matchingClusterSummaries, cs)
+		if cs.Status.Ready {
+			ready++
+		}
+		if cs.Status.FailedFeature != "" {
+			failed++
+		}
+	}
+
+	status.ReadyClusters = fmt.Sprintf("%d/%d", ready, len(matching))
+	status.FailedClusters = failed
+	updateDegradedCondition(profileScope, matchingClusterSummaries, time.Now())
+	updateRolloutProgress(profileScope, matchingClusterSummaries, len(matching), time.Now())
+	return nil
+}
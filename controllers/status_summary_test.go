@@ -0,0 +1,123 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Status summary", func() {
+	It("updateClusterSummaryStatusSummary reports ready and failed feature", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned},
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+			},
+		}
+
+		// Never successfully synced
+		controllers.UpdateClusterSummaryStatusSummary(clusterSummary)
+		Expect(clusterSummary.Status.Ready).To(BeFalse())
+		Expect(clusterSummary.Status.FailedFeature).To(BeEmpty())
+
+		now := metav1.Now()
+		clusterSummary.Status.LastSuccessfulSyncTime = &now
+		controllers.UpdateClusterSummaryStatusSummary(clusterSummary)
+		Expect(clusterSummary.Status.Ready).To(BeTrue())
+		Expect(clusterSummary.Status.FailedFeature).To(BeEmpty())
+
+		clusterSummary.Status.FeatureSummaries[1].Status = configv1beta1.FeatureStatusFailed
+		controllers.UpdateClusterSummaryStatusSummary(clusterSummary)
+		Expect(clusterSummary.Status.Ready).To(BeFalse())
+		Expect(clusterSummary.Status.FailedFeature).To(Equal(configv1beta1.FeatureHelm))
+
+		clusterSummary.Status.FeatureSummaries[1].Status = configv1beta1.FeatureStatusProvisioning
+		controllers.UpdateClusterSummaryStatusSummary(clusterSummary)
+		Expect(clusterSummary.Status.FailedFeature).To(BeEmpty())
+	})
+
+	It("updateProfileStatusSummary reports ready and failed clusters", func() {
+		clusters := make([]corev1.ObjectReference, 4)
+		for i := range clusters {
+			clusters[i] = corev1.ObjectReference{
+				Namespace: randomString(), Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			}
+		}
+
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: clusters,
+				// Clusters handed over to the successor are not counted
+				MigratedClusterRefs: clusters[3:],
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&configv1beta1.ClusterSummary{}).
+			WithObjects(clusterProfile).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client: c, Logger: textlogger.NewLogger(textlogger.NewConfig()), Profile: clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		statuses := []configv1beta1.ClusterSummaryStatus{
+			{Ready: true},
+			{FailedFeature: configv1beta1.FeatureHelm},
+			{},
+			{Ready: true},
+		}
+		for i := range clusters {
+			Expect(controllers.CreateClusterSummary(context.TODO(), c, profileScope, &clusters[i])).To(Succeed())
+			cs, err := controllers.GetClusterSummary(context.TODO(), c, configv1beta1.ClusterProfileKind,
+				clusterProfile.Name, clusters[i].Namespace, clusters[i].Name, libsveltosv1beta1.ClusterTypeSveltos)
+			Expect(err).To(BeNil())
+			cs.Status = statuses[i]
+			Expect(c.Status().Update(context.TODO(), cs)).To(Succeed())
+		}
+
+		Expect(controllers.UpdateProfileStatusSummary(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.ReadyClusters).To(Equal("1/3"))
+		Expect(clusterProfile.Status.FailedClusters).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,124 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/funcmap"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// stopMatchingBehaviorObjects are the objects StopMatchingBehaviorTemplate is instantiated with
+type stopMatchingBehaviorObjects struct {
+	*currentClusterObjects
+	Feature string
+}
+
+func parseStopMatchingBehaviorTemplate(name, value string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Funcs(funcmap.SveltosFuncMap()).Parse(value)
+}
+
+// validateStopMatchingBehaviorTemplate verifies StopMatchingBehaviorTemplate, if set, is a valid template
+func validateStopMatchingBehaviorTemplate(spec *configv1beta1.Spec) error {
+	if spec.StopMatchingBehaviorTemplate == "" {
+		return nil
+	}
+
+	if _, err := parseStopMatchingBehaviorTemplate("stopMatchingBehavior", spec.StopMatchingBehaviorTemplate); err != nil {
+		return fmt.Errorf("invalid stopMatchingBehaviorTemplate: %w", err)
+	}
+	return nil
+}
+
+// getStopMatchingBehavior returns the StopMatchingBehavior for featureID in the cluster matching
+// clusterSummary. When StopMatchingBehaviorTemplate is set, it is instantiated for the cluster and
+// the feature. Otherwise, or if the cluster does not exist anymore, StopMatchingBehavior is returned.
+func getStopMatchingBehavior(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) (configv1beta1.StopMatchingBehavior, error) {
+
+	spec := &clusterSummary.Spec.ClusterProfileSpec
+	if spec.StopMatchingBehaviorTemplate == "" {
+		return spec.StopMatchingBehavior, nil
+	}
+
+	start := time.Now()
+	behavior, err := instantiateStopMatchingBehavior(ctx, clusterSummary, featureID, logger)
+	observeTemplateInstantiation(clusterSummary, time.Since(start), err)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return spec.StopMatchingBehavior, nil
+		}
+		return "", err
+	}
+
+	return behavior, nil
+}
+
+func instantiateStopMatchingBehavior(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) (configv1beta1.StopMatchingBehavior, error) {
+
+	spec := &clusterSummary.Spec.ClusterProfileSpec
+
+	objects, err := fecthClusterObjects(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+		clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return "", err
+	}
+
+	objects.Variables, err = getTemplateVariables(spec.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	templateName := getTemplateName(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Name)
+	tmpl, err := parseStopMatchingBehaviorTemplate(templateName, spec.StopMatchingBehaviorTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, &stopMatchingBehaviorObjects{
+		currentClusterObjects: objects,
+		Feature:               string(featureID),
+	}); err != nil {
+		return "", fmt.Errorf("error executing stopMatchingBehaviorTemplate: %w", err)
+	}
+
+	behavior := configv1beta1.StopMatchingBehavior(strings.TrimSpace(buffer.String()))
+	switch behavior {
+	case "":
+		behavior = spec.StopMatchingBehavior
+	case configv1beta1.LeavePolicies, configv1beta1.WithdrawPolicies:
+	default:
+		return "", fmt.Errorf("stopMatchingBehaviorTemplate evaluated to %q: expected %s or %s",
+			behavior, configv1beta1.LeavePolicies, configv1beta1.WithdrawPolicies)
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("stopMatchingBehavior for feature %s: %s", featureID, behavior))
+	return behavior, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("StopMatchingBehavior template", func() {
+	const behaviorTemplate = `{{ if and (eq (index .Cluster.metadata.labels "env") "prod") (ne .Feature "Kustomize") }}` +
+		`LeavePolicies{{ else }}WithdrawPolicies{{ end }}`
+
+	var sveltosCluster *libsveltosv1beta1.SveltosCluster
+
+	BeforeEach(func() {
+		namespace := randomString()
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		Expect(testEnv.Client.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, ns)).To(Succeed())
+
+		sveltosCluster = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+		Expect(testEnv.Client.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, sveltosCluster)).To(Succeed())
+	})
+
+	It("getStopMatchingBehavior decides per cluster and per feature", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: sveltosCluster.Namespace, Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: sveltosCluster.Namespace,
+				ClusterName:      sveltosCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					StopMatchingBehavior:         configv1beta1.WithdrawPolicies,
+					StopMatchingBehaviorTemplate: behaviorTemplate,
+				},
+			},
+		}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		behavior, err := controllers.GetStopMatchingBehavior(context.TODO(), clusterSummary,
+			configv1beta1.FeatureHelm, logger)
+		Expect(err).To(BeNil())
+		Expect(behavior).To(Equal(configv1beta1.LeavePolicies))
+
+		behavior, err = controllers.GetStopMatchingBehavior(context.TODO(), clusterSummary,
+			configv1beta1.FeatureKustomize, logger)
+		Expect(err).To(BeNil())
+		Expect(behavior).To(Equal(configv1beta1.WithdrawPolicies))
+
+		// Template evaluating to an invalid behavior
+		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehaviorTemplate = "{{ .Feature }}"
+		_, err = controllers.GetStopMatchingBehavior(context.TODO(), clusterSummary,
+			configv1beta1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+
+		// Cluster does not exist anymore, StopMatchingBehavior is used
+		clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehaviorTemplate = behaviorTemplate
+		clusterSummary.Spec.ClusterName = randomString()
+		behavior, err = controllers.GetStopMatchingBehavior(context.TODO(), clusterSummary,
+			configv1beta1.FeatureHelm, logger)
+		Expect(err).To(BeNil())
+		Expect(behavior).To(Equal(configv1beta1.WithdrawPolicies))
+	})
+
+	It("validateStopMatchingBehaviorTemplate rejects invalid templates", func() {
+		spec := &configv1beta1.Spec{StopMatchingBehaviorTemplate: behaviorTemplate}
+		Expect(controllers.ValidateStopMatchingBehaviorTemplate(spec)).To(Succeed())
+
+		spec.StopMatchingBehaviorTemplate = "{{ if .Feature }}"
+		Expect(controllers.ValidateStopMatchingBehaviorTemplate(spec)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,115 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+var (
+	// syncSLOWindow is the maximum time a ClusterSummary can go without a successful sync
+	// before being reported as stale. Zero disables the staleness check.
+	syncSLOWindow time.Duration
+)
+
+func SetSyncSLOWindow(window time.Duration) {
+	syncSLOWindow = window
+}
+
+func getSyncSLOWindow() time.Duration {
+	return syncSLOWindow
+}
+
+// setSyncSucceeded records all features of the ClusterSummary have been successfully deployed
+func setSyncSucceeded(clusterSummaryScope *scope.ClusterSummaryScope, now time.Time) {
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	clusterSummary.Status.LastSuccessfulSyncTime = &metav1.Time{Time: now}
+	clusterSummary.Status.CurrentRevision = clusterSummary.Generation
+
+	if getSyncSLOWindow() != 0 {
+		setSyncedCondition(clusterSummary)
+	}
+}
+
+func setSyncedCondition(clusterSummary *configv1beta1.ClusterSummary) {
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, metav1.Condition{
+		Type:               configv1beta1.SyncStaleCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.SyncedReason,
+		Message:            "all features are deployed",
+		ObservedGeneration: clusterSummary.Generation,
+	})
+}
+
+// isSynced returns true if all features of the ClusterSummary are provisioned
+func isSynced(clusterSummary *configv1beta1.ClusterSummary) bool {
+	for i := range clusterSummary.Status.FeatureSummaries {
+		if clusterSummary.Status.FeatureSummaries[i].Status != configv1beta1.FeatureStatusProvisioned {
+			return false
+		}
+	}
+	return true
+}
+
+// updateSyncStaleCondition sets the SyncStaleCondition. ClusterSummary is stale when not all of its
+// features are deployed and the last successful sync (or the ClusterSummary creation, if it was never
+// synced) is older than the sync SLO window.
+// Returns the time left before ClusterSummary becomes stale, zero if already stale or check is disabled.
+func updateSyncStaleCondition(clusterSummaryScope *scope.ClusterSummaryScope, now time.Time) time.Duration {
+	window := getSyncSLOWindow()
+	if window == 0 {
+		return 0
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	if isSynced(clusterSummary) {
+		setSyncedCondition(clusterSummary)
+		return 0
+	}
+
+	lastSync := clusterSummary.CreationTimestamp.Time
+	if clusterSummary.Status.LastSuccessfulSyncTime != nil {
+		lastSync = clusterSummary.Status.LastSuccessfulSyncTime.Time
+	}
+
+	condition := metav1.Condition{
+		Type:               configv1beta1.SyncStaleCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.SyncWithinSLOReason,
+		Message:            fmt.Sprintf("last successful sync at %s", lastSync.UTC().Format(time.RFC3339)),
+		ObservedGeneration: clusterSummary.Generation,
+	}
+
+	left := window - now.Sub(lastSync)
+	if left <= 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.SyncSLOExceededReason
+		condition.Message = fmt.Sprintf("no successful sync since %s (SLO window %s)",
+			lastSync.UTC().Format(time.RFC3339), window)
+		left = 0
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, condition)
+	return left
+}
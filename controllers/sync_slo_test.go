@@ -0,0 +1,153 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Sync SLO", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	const window = time.Hour
+
+	BeforeEach(func() {
+		controllers.SetSyncSLOWindow(window)
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         randomString(),
+				Name:              randomString(),
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * window)},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusFailed},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		controllers.SetSyncSLOWindow(0)
+	})
+
+	getScope := func() *scope.ClusterSummaryScope {
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+		return clusterSummaryScope
+	}
+
+	It("updateSyncStaleCondition reports ClusterSummary never synced since creation as stale", func() {
+		left := controllers.UpdateSyncStaleCondition(getScope(), time.Now())
+		Expect(left).To(BeZero())
+
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.SyncStaleCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1beta1.SyncSLOExceededReason))
+	})
+
+	It("updateSyncStaleCondition returns time left when last successful sync is within window", func() {
+		now := time.Now()
+		clusterSummary.Status.LastSuccessfulSyncTime = &metav1.Time{Time: now.Add(-window / 2)}
+
+		left := controllers.UpdateSyncStaleCondition(getScope(), now)
+		Expect(left).To(Equal(window / 2))
+
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.SyncStaleCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.SyncWithinSLOReason))
+	})
+
+	It("updateSyncStaleCondition does not report synced ClusterSummary as stale", func() {
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusProvisioned
+
+		left := controllers.UpdateSyncStaleCondition(getScope(), time.Now())
+		Expect(left).To(BeZero())
+
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.SyncStaleCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(configv1beta1.SyncedReason))
+	})
+
+	It("updateSyncStaleCondition does nothing when sync SLO window is not set", func() {
+		controllers.SetSyncSLOWindow(0)
+
+		Expect(controllers.UpdateSyncStaleCondition(getScope(), time.Now())).To(BeZero())
+		Expect(clusterSummary.Status.Conditions).To(BeEmpty())
+	})
+
+	It("setSyncSucceeded records last successful sync and clears staleness", func() {
+		clusterSummaryScope := getScope()
+		controllers.UpdateSyncStaleCondition(clusterSummaryScope, time.Now())
+
+		now := time.Now()
+		controllers.SetSyncSucceeded(clusterSummaryScope, now)
+		Expect(clusterSummary.Status.LastSuccessfulSyncTime).ToNot(BeNil())
+		Expect(clusterSummary.Status.LastSuccessfulSyncTime.Time).To(Equal(now))
+
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions, configv1beta1.SyncStaleCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("getLastSuccessfulSyncInfo returns last successful sync timestamp", func() {
+		labels, _ := controllers.GetLastSuccessfulSyncInfo(clusterSummary)
+		Expect(labels).To(BeNil())
+
+		now := time.Now()
+		clusterSummary.Status.LastSuccessfulSyncTime = &metav1.Time{Time: now}
+		labels, value := controllers.GetLastSuccessfulSyncInfo(clusterSummary)
+		Expect(labels).To(Equal([]string{
+			fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName),
+			string(libsveltosv1beta1.ClusterTypeCapi),
+			fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name),
+		}))
+		Expect(value).To(Equal(float64(now.Unix())))
+	})
+})
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"text/template"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/funcmap"
@@ -42,6 +44,7 @@ type currentClusterObjects struct {
 	KubeadmControlPlane    map[string]interface{}
 	InfrastructureProvider map[string]interface{}
 	MgmtResources          map[string]map[string]interface{}
+	Variables              map[string]interface{}
 }
 
 func fetchResource(ctx context.Context, config *rest.Config, namespace, name, apiVersion, kind string,
@@ -155,13 +158,19 @@ func fecthClusterObjects(ctx context.Context, config *rest.Config, c client.Clie
 
 func instantiateTemplateValues(ctx context.Context, config *rest.Config, c client.Client,
 	clusterType libsveltosv1beta1.ClusterType, clusterNamespace, clusterName, requestorName, values string,
-	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger) (string, error) {
+	mgmtResources map[string]*unstructured.Unstructured, variables []configv1beta1.Variable,
+	logger logr.Logger) (string, error) {
 
 	objects, err := fecthClusterObjects(ctx, config, c, clusterNamespace, clusterName, clusterType, logger)
 	if err != nil {
 		return "", err
 	}
 
+	objects.Variables, err = getTemplateVariables(variables)
+	if err != nil {
+		return "", err
+	}
+
 	if mgmtResources != nil {
 		objects.MgmtResources = make(map[string]map[string]interface{})
 		for k := range mgmtResources {
@@ -193,6 +202,22 @@ func instantiateTemplateValues(ctx context.Context, config *rest.Config, c clien
 	return instantiatedValues, nil
 }
 
+// instantiateClusterSummaryTemplate instantiates values for the cluster matching clusterSummary.
+// Time spent and failures are reported per (Cluster)Profile.
+func instantiateClusterSummaryTemplate(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestorName, values string, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) (string, error) {
+
+	start := time.Now()
+	instantiatedValues, err := instantiateTemplateValues(ctx, getManagementClusterConfig(),
+		getManagementClusterClient(), clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, requestorName, values, mgmtResources,
+		clusterSummary.Spec.ClusterProfileSpec.Variables, logger)
+	observeTemplateInstantiation(clusterSummary, time.Since(start), err)
+
+	return instantiatedValues, err
+}
+
 func getTemplateName(clusterNamespace, clusterName, requestorName string) string {
 	return fmt.Sprintf("%s-%s-%s", clusterNamespace, clusterName, requestorName)
 }
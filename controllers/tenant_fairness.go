@@ -0,0 +1,85 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TenantFairness configures how deployer workers are shared across namespaces. Profiles are
+// namespace-scoped and can only match clusters in their namespace, so the namespace of the
+// cluster identifies the tenant a request belongs to.
+type TenantFairness struct {
+	// Workers is the number of deployer workers
+	Workers int
+
+	// Weights contains, per namespace, the weight used to compute the share of workers of
+	// the namespace. Namespaces not listed have weight 1.
+	Weights map[string]int
+}
+
+// ParseTenantWeights parses a comma separated list of <namespace>=<weight>
+// (for instance team-a=3,team-b=1)
+func ParseTenantWeights(value string) (map[string]int, error) {
+	weights := make(map[string]int)
+	if strings.TrimSpace(value) == "" {
+		return weights, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		namespaceAndWeight := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(namespaceAndWeight) != 2 || namespaceAndWeight[0] == "" {
+			return nil, fmt.Errorf("malformed tenant weight %q: expected <namespace>=<weight>", entry)
+		}
+
+		if _, ok := weights[namespaceAndWeight[0]]; ok {
+			return nil, fmt.Errorf("weight for namespace %s defined more than once", namespaceAndWeight[0])
+		}
+
+		weight, err := strconv.Atoi(namespaceAndWeight[1])
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight %q in tenant weight %q", namespaceAndWeight[1], entry)
+		}
+
+		weights[namespaceAndWeight[0]] = weight
+	}
+
+	return weights, nil
+}
+
+// getShare returns the number of workers tenant can use at the same time: workers are shared
+// across tenant and the active tenants proportionally to their weights.
+// Every tenant can use at least one worker.
+func (f *TenantFairness) getShare(tenant string, active map[string]bool) int {
+	totalWeight := f.getWeight(tenant)
+	for namespace := range active {
+		if namespace != tenant {
+			totalWeight += f.getWeight(namespace)
+		}
+	}
+
+	return max(1, f.Workers*f.getWeight(tenant)/totalWeight)
+}
+
+func (f *TenantFairness) getWeight(namespace string) int {
+	if w, ok := f.Weights[namespace]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
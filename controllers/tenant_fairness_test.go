@@ -0,0 +1,54 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Tenant fairness", func() {
+	It("ParseTenantWeights parses namespace weights", func() {
+		weights, err := controllers.ParseTenantWeights("team-a=3, team-b=1")
+		Expect(err).To(BeNil())
+		Expect(weights).To(Equal(map[string]int{"team-a": 3, "team-b": 1}))
+
+		weights, err = controllers.ParseTenantWeights("")
+		Expect(err).To(BeNil())
+		Expect(weights).To(BeEmpty())
+
+		for _, value := range []string{"team-a", "=1", "team-a=0", "team-a=x", "team-a=1,team-a=2"} {
+			_, err = controllers.ParseTenantWeights(value)
+			Expect(err).ToNot(BeNil(), value)
+		}
+	})
+
+	It("getShare shares workers across active namespaces proportionally to their weights", func() {
+		fairness := &controllers.TenantFairness{Workers: 8, Weights: map[string]int{"team-a": 3}}
+
+		Expect(controllers.GetTenantShare(fairness, "team-a", map[string]bool{})).To(Equal(8))
+		Expect(controllers.GetTenantShare(fairness, "team-a", map[string]bool{"team-a": true, "team-b": true})).To(Equal(6))
+		Expect(controllers.GetTenantShare(fairness, "team-b", map[string]bool{"team-a": true})).To(Equal(2))
+
+		// Every namespace can use at least one worker
+		active := map[string]bool{"team-a": true, "team-b": true, "team-c": true, "team-d": true, "team-e": true,
+			"team-f": true}
+		Expect(controllers.GetTenantShare(fairness, "team-b", active)).To(Equal(1))
+	})
+})
@@ -0,0 +1,234 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+var (
+	// tenantNamespaceIsolation, when set, confines resources deployed by namespace-scoped Profiles
+	// to the managed cluster namespaces prefixed with the Profile namespace (the tenant).
+	tenantNamespaceIsolation bool
+)
+
+func SetTenantNamespaceIsolation(enabled bool) {
+	tenantNamespaceIsolation = enabled
+}
+
+func getTenantNamespaceIsolation() bool {
+	return tenantNamespaceIsolation
+}
+
+// getTenant returns the tenant resources deployed because of clusterSummary belong to.
+// That is the Profile namespace when tenant namespace isolation is enabled and clusterSummary
+// is created by a Profile. Empty otherwise.
+func getTenant(clusterSummary *configv1beta1.ClusterSummary) string {
+	if !getTenantNamespaceIsolation() {
+		return ""
+	}
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || profileOwnerRef.Kind != configv1beta1.ProfileKind {
+		return ""
+	}
+
+	// ClusterSummaries created by a Profile are in the Profile namespace
+	return clusterSummary.Namespace
+}
+
+// getTenantNamespace maps namespace to the tenant namespace slice, i.e. namespaces prefixed
+// with the tenant followed by a single dash. Dashes in the tenant are doubled so that the
+// mapping is unambiguous: tenant "a" and namespace "b-x" map to "a-b-x" while tenant "a-b"
+// and namespace "x" map to "a--b-x". Namespace is always prefixed, so mapping must be
+// applied exactly once.
+func getTenantNamespace(tenant, namespace string) string {
+	if tenant == "" {
+		return namespace
+	}
+
+	return fmt.Sprintf("%s-%s", strings.ReplaceAll(tenant, "-", "--"), namespace)
+}
+
+// isTenantNamespace returns true if namespace is within the tenant namespace slice
+func isTenantNamespace(tenant, namespace string) bool {
+	prefix := getTenantNamespace(tenant, "")
+	if !strings.HasPrefix(namespace, prefix) {
+		return false
+	}
+
+	// Namespace names cannot start with a dash. A dash here means namespace belongs to
+	// a different tenant (tenant "a" and namespace "a--b-x" of tenant "a-b")
+	name := strings.TrimPrefix(namespace, prefix)
+	return name != "" && !strings.HasPrefix(name, "-")
+}
+
+// applyTenantNamespace confines policy to the tenant namespace slice:
+// - namespaced resources are moved to the corresponding tenant namespace;
+// - Namespaces are renamed to the corresponding tenant namespace;
+// - any other cluster-wide resource is rejected.
+func applyTenantNamespace(policy *unstructured.Unstructured, tenant string, isResourceNamespaced bool) error {
+	if isResourceNamespaced {
+		policy.SetNamespace(getTenantNamespace(tenant, policy.GetNamespace()))
+		return nil
+	}
+
+	if policy.GroupVersionKind().Group == "" && policy.GetKind() == "Namespace" {
+		policy.SetName(getTenantNamespace(tenant, policy.GetName()))
+		return nil
+	}
+
+	return &NonRetriableError{Message: fmt.Sprintf("tenant %s cannot deploy cluster-wide resource %s %s",
+		tenant, policy.GetKind(), policy.GetName())}
+}
+
+// tenantPostRenderer is a helm post renderer confining the rendered resources to the
+// tenant namespace slice. It runs after next, if set.
+// Helm only post renders the release manifest: hooks are verified by validateTenantReleaseHooks.
+type tenantPostRenderer struct {
+	tenant string
+	// releaseNamespace is the helm release namespace, already mapped to the tenant namespace slice
+	releaseNamespace string
+	mapper           meta.RESTMapper
+	next             postrender.PostRenderer
+}
+
+func (p *tenantPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if p.next != nil {
+		var err error
+		renderedManifests, err = p.next.Run(renderedManifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elements, err := customSplit(renderedManifests.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &bytes.Buffer{}
+	for i := range elements {
+		if strings.TrimSpace(elements[i]) == "" {
+			continue
+		}
+
+		policy, err := utils.GetUnstructured([]byte(elements[i]))
+		if err != nil {
+			return nil, err
+		}
+
+		mapping, err := p.mapper.RESTMapping(policy.GroupVersionKind().GroupKind(), policy.GroupVersionKind().Version)
+		if err != nil {
+			return nil, err
+		}
+
+		isResourceNamespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+		// Resources with no namespace are deployed in the release namespace which is already
+		// within the tenant namespace slice
+		if !isResourceNamespaced || (policy.GetNamespace() != "" && policy.GetNamespace() != p.releaseNamespace) {
+			if err := applyTenantNamespace(policy, p.tenant, isResourceNamespaced); err != nil {
+				return nil, err
+			}
+		}
+
+		data, err := yaml.Marshal(policy.Object)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString("---\n")
+		result.Write(data)
+	}
+
+	return result, nil
+}
+
+// validateTenantReleaseNamespace returns an error if the helm release namespace is not within
+// the tenant namespace slice
+func validateTenantReleaseNamespace(requestedChart *configv1beta1.HelmChart, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+
+	// ReleaseNamespace is mapped to the tenant namespace slice by the Profile controller
+	if !isTenantNamespace(tenant, requestedChart.ReleaseNamespace) {
+		return &NonRetriableError{Message: fmt.Sprintf("tenant %s cannot deploy helm release %s in namespace %s",
+			tenant, requestedChart.ReleaseName, requestedChart.ReleaseNamespace)}
+	}
+
+	return nil
+}
+
+func getTenantPostRenderer(actionConfig *action.Configuration, tenant, releaseNamespace string,
+	next postrender.PostRenderer) (postrender.PostRenderer, error) {
+
+	mapper, err := actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	return newTenantPostRenderer(tenant, releaseNamespace, mapper, next), nil
+}
+
+func newTenantPostRenderer(tenant, releaseNamespace string, mapper meta.RESTMapper, next postrender.PostRenderer,
+) postrender.PostRenderer {
+
+	return &tenantPostRenderer{tenant: tenant, releaseNamespace: releaseNamespace, mapper: mapper, next: next}
+}
+
+// validateTenantReleaseHooks returns an error if any hook of the rendered helm release is not
+// confined to the release namespace. Helm does not post render hooks, so they cannot be moved
+// to the tenant namespace slice: hooks with no namespace, or in the release namespace, are the
+// only ones allowed.
+func validateTenantReleaseHooks(rel *release.Release, tenant string, mapper meta.RESTMapper) error {
+	for i := range rel.Hooks {
+		hook := rel.Hooks[i]
+		policy, err := utils.GetUnstructured([]byte(hook.Manifest))
+		if err != nil {
+			return err
+		}
+
+		mapping, err := mapper.RESTMapping(policy.GroupVersionKind().GroupKind(), policy.GroupVersionKind().Version)
+		if err != nil {
+			return err
+		}
+
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return &NonRetriableError{Message: fmt.Sprintf("tenant %s cannot deploy cluster-wide resource %s %s (hook %s)",
+				tenant, policy.GetKind(), policy.GetName(), hook.Path)}
+		}
+
+		if policy.GetNamespace() != "" && policy.GetNamespace() != rel.Namespace {
+			return &NonRetriableError{Message: fmt.Sprintf("tenant %s cannot deploy hook %s %s/%s outside release namespace %s",
+				tenant, policy.GetKind(), policy.GetNamespace(), policy.GetName(), rel.Namespace)}
+		}
+	}
+
+	return nil
+}
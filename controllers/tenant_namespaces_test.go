@@ -0,0 +1,160 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+const tenantManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: nginx
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: nginx
+`
+
+var _ = Describe("Tenant namespace isolation", func() {
+	AfterEach(func() {
+		controllers.SetTenantNamespaceIsolation(false)
+	})
+
+	It("getTenant returns Profile namespace only when isolation is enabled", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "blue",
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: configv1beta1.GroupVersion.String(), Kind: configv1beta1.ProfileKind,
+						Name: randomString(), UID: "1"},
+				},
+			},
+		}
+
+		Expect(controllers.GetTenant(clusterSummary)).To(BeEmpty())
+
+		controllers.SetTenantNamespaceIsolation(true)
+		Expect(controllers.GetTenant(clusterSummary)).To(Equal("blue"))
+
+		clusterSummary.OwnerReferences[0].Kind = configv1beta1.ClusterProfileKind
+		Expect(controllers.GetTenant(clusterSummary)).To(BeEmpty())
+	})
+
+	It("applyTenantNamespace confines resources to the tenant namespace slice", func() {
+		Expect(controllers.GetTenantNamespace("blue", "nginx")).To(Equal("blue-nginx"))
+		// Mapping is always applied and two tenants never share a namespace
+		Expect(controllers.GetTenantNamespace("blue", "blue-nginx")).To(Equal("blue-blue-nginx"))
+		Expect(controllers.GetTenantNamespace("a", "b-x")).To(Equal("a-b-x"))
+		Expect(controllers.GetTenantNamespace("a-b", "x")).To(Equal("a--b-x"))
+		Expect(controllers.IsTenantNamespace("a", "a-b-x")).To(BeTrue())
+		Expect(controllers.IsTenantNamespace("a", "a--b-x")).To(BeFalse())
+		Expect(controllers.IsTenantNamespace("a-b", "a--b-x")).To(BeTrue())
+		Expect(controllers.IsTenantNamespace("a-b", "a-b-x")).To(BeFalse())
+		Expect(controllers.IsTenantNamespace("a", "a-")).To(BeFalse())
+
+		deployment := &unstructured.Unstructured{}
+		deployment.SetAPIVersion("apps/v1")
+		deployment.SetKind("Deployment")
+		deployment.SetNamespace("nginx")
+		Expect(controllers.ApplyTenantNamespace(deployment, "blue", true)).To(Succeed())
+		Expect(deployment.GetNamespace()).To(Equal("blue-nginx"))
+
+		namespace := &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind("Namespace")
+		namespace.SetName("nginx")
+		Expect(controllers.ApplyTenantNamespace(namespace, "blue", false)).To(Succeed())
+		Expect(namespace.GetName()).To(Equal("blue-nginx"))
+
+		clusterRole := &unstructured.Unstructured{}
+		clusterRole.SetAPIVersion("rbac.authorization.k8s.io/v1")
+		clusterRole.SetKind("ClusterRole")
+		clusterRole.SetName("admin")
+		Expect(controllers.ApplyTenantNamespace(clusterRole, "blue", false)).ToNot(Succeed())
+	})
+
+	It("tenantPostRenderer moves helm rendered resources to the tenant namespace slice", func() {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), meta.RESTScopeNamespace)
+		mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+		postRenderer := controllers.NewTenantPostRenderer("blue", "blue-web", mapper, nil)
+		result, err := postRenderer.Run(bytes.NewBufferString(tenantManifests +
+			"---\napiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n  namespace: blue-web\n"))
+		Expect(err).To(BeNil())
+		Expect(result.String()).To(ContainSubstring("namespace: blue-nginx"))
+		// Resources in the release namespace are already within the tenant namespace slice
+		Expect(result.String()).To(ContainSubstring("namespace: blue-web\n"))
+		Expect(result.String()).ToNot(ContainSubstring("namespace: blue-blue-web"))
+		// Resources with no namespace are left to the (tenant) release namespace
+		Expect(result.String()).ToNot(ContainSubstring("namespace: blue-\n"))
+
+		_, err = postRenderer.Run(bytes.NewBufferString(tenantManifests +
+			"---\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: admin\n"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("validateTenantReleaseHooks confines helm hooks to the release namespace", func() {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), meta.RESTScopeNamespace)
+		mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+		rel := &release.Release{
+			Namespace: "blue-web",
+			Hooks: []*release.Hook{
+				{Path: "web/templates/sa.yaml",
+					Manifest: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n"},
+				{Path: "web/templates/sa-ns.yaml",
+					Manifest: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n  namespace: blue-web\n"},
+			},
+		}
+		Expect(controllers.ValidateTenantReleaseHooks(rel, "blue", mapper)).To(Succeed())
+
+		// Helm does not post render hooks: hooks outside the release namespace are rejected
+		rel.Hooks = append(rel.Hooks, &release.Hook{Path: "web/templates/sa-other.yaml",
+			Manifest: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n  namespace: kube-system\n"})
+		err := controllers.ValidateTenantReleaseHooks(rel, "blue", mapper)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("outside release namespace blue-web"))
+
+		rel.Hooks = []*release.Hook{{Path: "web/templates/role.yaml",
+			Manifest: "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: admin\n"}}
+		err = controllers.ValidateTenantReleaseHooks(rel, "blue", mapper)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("cannot deploy cluster-wide resource ClusterRole admin"))
+	})
+})
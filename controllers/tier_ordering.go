@@ -0,0 +1,124 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	// tierOrderedDeployment, when set, makes features of ClusterSummaries be deployed
+	// in a newly matching cluster in Tier order
+	tierOrderedDeployment bool
+)
+
+// SetTierOrderedDeployment enables/disables tier ordered deployment. When enabled, the first time
+// features of a ClusterSummary are deployed, deployment waits for all ClusterSummaries for the same
+// cluster in lower Tiers (for instance security policies and CNIs) to be provisioned.
+func SetTierOrderedDeployment(enabled bool) {
+	tierOrderedDeployment = enabled
+}
+
+// getTierStatuses returns, sorted by Tier, the deployment progress in each Tier of the ClusterSummaries
+func getTierStatuses(clusterSummaries []configv1beta1.ClusterSummary) []configv1beta1.TierStatus {
+	tiers := make(map[int32]*configv1beta1.TierStatus)
+	for i := range clusterSummaries {
+		cs := &clusterSummaries[i]
+		if !cs.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		tier := cs.Spec.ClusterProfileSpec.Tier
+		if _, ok := tiers[tier]; !ok {
+			tiers[tier] = &configv1beta1.TierStatus{Tier: tier}
+		}
+		tiers[tier].ClusterSummaries++
+		if isCluterSummaryProvisioned(cs) {
+			tiers[tier].Provisioned++
+		}
+	}
+
+	result := make([]configv1beta1.TierStatus, 0, len(tiers))
+	for tier := range tiers {
+		result = append(result, *tiers[tier])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tier < result[j].Tier
+	})
+
+	return result
+}
+
+// areLowerTiersProvisioned returns true if all ClusterSummaries, for the same cluster, in a Tier lower
+// than the one of this ClusterSummary are provisioned. When it returns false, message explains which
+// Tier deployment is waiting for.
+// Ordering only applies to the first deployment. Once all features of the ClusterSummary have been
+// provisioned, updates are deployed regardless of other Tiers.
+// It also updates ClusterSummary Status with the deployment progress of each Tier.
+func (r *ClusterSummaryReconciler) areLowerTiersProvisioned(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) (provisioned bool, message string, err error) {
+
+	if !tierOrderedDeployment {
+		clusterSummaryScope.SetTierStatuses(nil)
+		return true, "", nil
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+
+	listOptions := []client.ListOption{
+		client.InNamespace(clusterSummary.Spec.ClusterNamespace),
+		client.MatchingLabels{
+			configv1beta1.ClusterNameLabel: clusterSummary.Spec.ClusterName,
+			configv1beta1.ClusterTypeLabel: string(clusterSummary.Spec.ClusterType),
+		},
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := r.List(ctx, clusterSummaries, listOptions...); err != nil {
+		return false, "", err
+	}
+
+	tierStatuses := getTierStatuses(clusterSummaries.Items)
+	clusterSummaryScope.SetTierStatuses(tierStatuses)
+
+	if clusterSummary.Status.LastSuccessfulSyncTime != nil {
+		return true, "", nil
+	}
+
+	for i := range tierStatuses {
+		if tierStatuses[i].Tier >= clusterSummary.Spec.ClusterProfileSpec.Tier {
+			break
+		}
+		if tierStatuses[i].Provisioned != tierStatuses[i].ClusterSummaries {
+			msg := fmt.Sprintf("waiting for Tier %d to be provisioned (%d/%d)", tierStatuses[i].Tier,
+				tierStatuses[i].Provisioned, tierStatuses[i].ClusterSummaries)
+			logger.V(logs.LogInfo).Info(msg)
+			return false, msg, nil
+		}
+	}
+
+	return true, "", nil
+}
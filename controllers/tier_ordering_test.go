@@ -0,0 +1,163 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Tier ordered deployment", func() {
+	var clusterNamespace, clusterName string
+
+	BeforeEach(func() {
+		clusterNamespace = randomString()
+		clusterName = randomString()
+		controllers.SetTierOrderedDeployment(true)
+	})
+
+	AfterEach(func() {
+		controllers.SetTierOrderedDeployment(false)
+	})
+
+	getClusterSummary := func(tier int32) *configv1beta1.ClusterSummary {
+		return &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					configv1beta1.ClusterNameLabel: clusterName,
+					configv1beta1.ClusterTypeLabel: string(libsveltosv1beta1.ClusterTypeCapi),
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: clusterNamespace,
+				ClusterName:      clusterName,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					Tier: tier,
+					HelmCharts: []configv1beta1.HelmChart{
+						{RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: "1.0.0",
+							ReleaseName: randomString(), ReleaseNamespace: randomString()},
+					},
+				},
+			},
+		}
+	}
+
+	It("areLowerTiersProvisioned waits for lower Tiers to be provisioned", func() {
+		security := getClusterSummary(10)
+		cni := getClusterSummary(10)
+		apps := getClusterSummary(100)
+		observability := getClusterSummary(200)
+
+		initObjects := []client.Object{security, cni, apps, observability}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		reconciler := getClusterSummaryReconciler(c, nil)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         logger,
+			ClusterSummary: apps,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		provisioned, msg, err := controllers.AreLowerTiersProvisioned(reconciler, context.TODO(),
+			clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		Expect(provisioned).To(BeFalse())
+		Expect(msg).To(ContainSubstring("Tier 10"))
+		Expect(apps.Status.TierStatuses).To(Equal([]configv1beta1.TierStatus{
+			{Tier: 10, ClusterSummaries: 2, Provisioned: 0},
+			{Tier: 100, ClusterSummaries: 1, Provisioned: 0},
+			{Tier: 200, ClusterSummaries: 1, Provisioned: 0},
+		}))
+
+		security.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+			{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+		}
+		Expect(c.Status().Update(context.TODO(), security)).To(Succeed())
+
+		provisioned, _, err = controllers.AreLowerTiersProvisioned(reconciler, context.TODO(),
+			clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		Expect(provisioned).To(BeFalse())
+		Expect(apps.Status.TierStatuses[0]).To(Equal(
+			configv1beta1.TierStatus{Tier: 10, ClusterSummaries: 2, Provisioned: 1}))
+
+		cni.Status.FeatureSummaries = []configv1beta1.FeatureSummary{
+			{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+		}
+		Expect(c.Status().Update(context.TODO(), cni)).To(Succeed())
+
+		// Tier 200 not being provisioned does not matter
+		provisioned, _, err = controllers.AreLowerTiersProvisioned(reconciler, context.TODO(),
+			clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		Expect(provisioned).To(BeTrue())
+	})
+
+	It("areLowerTiersProvisioned only orders first deployment", func() {
+		security := getClusterSummary(10)
+		apps := getClusterSummary(100)
+		now := metav1.Now()
+		apps.Status.LastSuccessfulSyncTime = &now
+
+		initObjects := []client.Object{security, apps}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		reconciler := getClusterSummaryReconciler(c, nil)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         logger,
+			ClusterSummary: apps,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		provisioned, _, err := controllers.AreLowerTiersProvisioned(reconciler, context.TODO(),
+			clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		Expect(provisioned).To(BeTrue())
+		Expect(apps.Status.TierStatuses).To(HaveLen(2))
+
+		// When disabled, no Tier progress is reported
+		controllers.SetTierOrderedDeployment(false)
+		provisioned, _, err = controllers.AreLowerTiersProvisioned(reconciler, context.TODO(),
+			clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+		Expect(provisioned).To(BeTrue())
+		Expect(apps.Status.TierStatuses).To(BeNil())
+	})
+})
@@ -0,0 +1,87 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// UnmanagedLabel, set to "true" either as label or annotation on a Cluster/SveltosCluster, is a
+// per cluster kill-switch. While set, ClusterSummaries for the cluster are neither created nor updated
+// and nothing is deployed to, or removed from, the cluster. A ClusterSummary deleted meanwhile is released
+// leaving deployed resources in place. Profiles matching such a cluster report it in the ClustersUnmanaged
+// condition.
+const UnmanagedLabel = "sveltos.io/unmanaged"
+
+// isUnmanaged returns true if cluster has opted out of Sveltos management
+func isUnmanaged(cluster client.Object) bool {
+	return cluster.GetLabels()[UnmanagedLabel] == "true" ||
+		cluster.GetAnnotations()[UnmanagedLabel] == "true"
+}
+
+// isClusterUnmanaged returns true if the Sveltos/Cluster has opted out of Sveltos management
+func isClusterUnmanaged(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) (bool, error) {
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterNamespace, clusterName, clusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return isUnmanaged(cluster), nil
+}
+
+// updateUnmanagedClustersCondition sets the ClusterProfile/Profile ClustersUnmanagedCondition
+func updateUnmanagedClustersCondition(profileScope *scope.ProfileScope, unmanagedClusters []corev1.ObjectReference) {
+	condition := metav1.Condition{
+		Type:               configv1beta1.ClustersUnmanagedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             configv1beta1.AllClustersManagedReason,
+		Message:            "all matching clusters are managed",
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	}
+
+	if len(unmanagedClusters) != 0 {
+		names := make([]string, len(unmanagedClusters))
+		for i := range unmanagedClusters {
+			names[i] = fmt.Sprintf("%s:%s/%s", clusterproxy.GetClusterType(&unmanagedClusters[i]),
+				unmanagedClusters[i].Namespace, unmanagedClusters[i].Name)
+		}
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = configv1beta1.ClusterOptedOutReason
+		condition.Message = fmt.Sprintf("clusters opted out of management with %s: %s",
+			UnmanagedLabel, strings.Join(names, ", "))
+	}
+
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, condition)
+}
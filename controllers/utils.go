@@ -25,6 +25,7 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 	"github.com/gdexlab/go-render/render"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -40,6 +41,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
@@ -70,6 +72,45 @@ func (r *NonRetriableError) Error() string {
 	return r.Message
 }
 
+// deploymentErrors collects, when ContinueOnError is set, the failures of the items (helm charts,
+// KustomizationRefs, PolicyRefs) of a feature so deployment can proceed with remaining items.
+type deploymentErrors struct {
+	messages     []string
+	nonRetriable int
+	superseded   error
+}
+
+func (d *deploymentErrors) add(item string, err error) {
+	d.messages = append(d.messages, fmt.Sprintf("%s: %v", item, err))
+	var nonRetriableError *NonRetriableError
+	if errors.As(err, &nonRetriableError) {
+		d.nonRetriable++
+	}
+	if isDeploymentSupersededError(err) {
+		d.superseded = err
+	}
+}
+
+// toError returns nil if no failure was collected. Otherwise an error reporting how many of total
+// items failed. The error is non retriable only if all failures are. If the deployment pass was
+// superseded, the DeploymentSupersededError is returned instead.
+func (d *deploymentErrors) toError(total int) error {
+	if len(d.messages) == 0 {
+		return nil
+	}
+
+	if d.superseded != nil {
+		return d.superseded
+	}
+
+	message := fmt.Sprintf("%d of %d failed to deploy: %s", len(d.messages), total,
+		strings.Join(d.messages, "; "))
+	if d.nonRetriable == len(d.messages) {
+		return &NonRetriableError{Message: message}
+	}
+	return errors.New(message)
+}
+
 func InitScheme() (*runtime.Scheme, error) {
 	s := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(s); err != nil {
@@ -78,6 +119,9 @@ func InitScheme() (*runtime.Scheme, error) {
 	if err := clusterv1.AddToScheme(s); err != nil {
 		return nil, err
 	}
+	if err := expclusterv1.AddToScheme(s); err != nil {
+		return nil, err
+	}
 	if err := configv1beta1.AddToScheme(s); err != nil {
 		return nil, err
 	}
@@ -235,12 +279,15 @@ func isCluterSummaryProvisioned(clusterSumary *configv1beta1.ClusterSummary) boo
 	hasHelmCharts := false
 	hasRawYAMLs := false
 	hasKustomize := false
+	hasJobs := false
+	hasExtensions := false
 
 	if len(clusterSumary.Spec.ClusterProfileSpec.HelmCharts) != 0 {
 		hasHelmCharts = true
 	}
 
-	if len(clusterSumary.Spec.ClusterProfileSpec.PolicyRefs) != 0 {
+	if len(clusterSumary.Spec.ClusterProfileSpec.PolicyRefs) != 0 ||
+		len(clusterSumary.Spec.ClusterProfileSpec.InlineResources) != 0 {
 		hasRawYAMLs = true
 	}
 
@@ -248,9 +295,19 @@ func isCluterSummaryProvisioned(clusterSumary *configv1beta1.ClusterSummary) boo
 		hasKustomize = true
 	}
 
+	if len(clusterSumary.Spec.ClusterProfileSpec.Jobs) != 0 {
+		hasJobs = true
+	}
+
+	if len(clusterSumary.Spec.ClusterProfileSpec.Extensions) != 0 {
+		hasExtensions = true
+	}
+
 	deployedHelmCharts := false
 	deployedRawYAMLs := false
 	deployedKustomize := false
+	deployedJobs := false
+	deployedExtensions := false
 
 	for i := range clusterSumary.Status.FeatureSummaries {
 		fs := &clusterSumary.Status.FeatureSummaries[i]
@@ -264,6 +321,10 @@ func isCluterSummaryProvisioned(clusterSumary *configv1beta1.ClusterSummary) boo
 			deployedRawYAMLs = true
 		case configv1beta1.FeatureKustomize:
 			deployedKustomize = true
+		case configv1beta1.FeatureJobs:
+			deployedJobs = true
+		case configv1beta1.FeatureExtensions:
+			deployedExtensions = true
 		}
 	}
 
@@ -285,6 +346,18 @@ func isCluterSummaryProvisioned(clusterSumary *configv1beta1.ClusterSummary) boo
 		}
 	}
 
+	if hasJobs {
+		if !deployedJobs {
+			return false
+		}
+	}
+
+	if hasExtensions {
+		if !deployedExtensions {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -115,6 +116,9 @@ func setupScheme() (*runtime.Scheme, error) {
 	if err := clusterv1.AddToScheme(s); err != nil {
 		return nil, err
 	}
+	if err := expclusterv1.AddToScheme(s); err != nil {
+		return nil, err
+	}
 	if err := clientgoscheme.AddToScheme(s); err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	lua "github.com/yuin/gopher-lua"
@@ -53,7 +54,7 @@ func validateHealthPolicies(ctx context.Context, remoteConfig *rest.Config, clus
 			continue
 		}
 
-		if err := validateHealthPolicy(ctx, remoteConfig, check, logger); err != nil {
+		if err := validateHealthPolicy(ctx, remoteConfig, clusterSummary, check, logger); err != nil {
 			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to validate check: %s", err))
 			return err
 		}
@@ -62,8 +63,8 @@ func validateHealthPolicies(ctx context.Context, remoteConfig *rest.Config, clus
 	return nil
 }
 
-func validateHealthPolicy(ctx context.Context, remoteConfig *rest.Config, check *configv1beta1.ValidateHealth,
-	logger logr.Logger) error {
+func validateHealthPolicy(ctx context.Context, remoteConfig *rest.Config, clusterSummary *configv1beta1.ClusterSummary,
+	check *configv1beta1.ValidateHealth, logger logr.Logger) error {
 
 	l := logger.WithValues("validation", check.Name)
 	l.V(logs.LogDebug).Info("running health validation")
@@ -82,7 +83,11 @@ func validateHealthPolicy(ctx context.Context, remoteConfig *rest.Config, check
 		l.V(logs.LogDebug).Info("examing resource's health")
 		var healthy bool
 		var msg string
+		start := time.Now()
 		healthy, msg, err = isHealthy(&list.Items[i], check.Script, logger)
+		if check.Script != "" {
+			observeLuaEvaluation(clusterSummary, time.Since(start), err)
+		}
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+var (
+	profileClusterInSyncDesc = prometheus.NewDesc(
+		"sveltos_profile_cluster_in_sync",
+		"1 if the cluster is running the current profile Spec and all features are provisioned, 0 otherwise",
+		[]string{"profile_kind", "profile", "cluster", "cluster_type"},
+		nil,
+	)
+
+	helmChartVersionSkewDesc = prometheus.NewDesc(
+		"sveltos_helm_chart_version_skew",
+		"1 if the chart version deployed in the cluster differs from the version requested by the profile, 0 otherwise",
+		[]string{"profile_kind", "profile", "cluster", "cluster_type", "release", "release_namespace",
+			"desired_version", "deployed_version"},
+		nil,
+	)
+)
+
+// versionSkewCollector is a prometheus Collector comparing, for each cluster matching a
+// ClusterProfile/Profile, desired state (profile Spec and chart versions) with what is
+// currently deployed. This highlights laggards when rollouts are intentionally staggered
+// (MaxUpdate for instance).
+type versionSkewCollector struct {
+	c      client.Client
+	logger logr.Logger
+}
+
+// RegisterVersionSkewCollector registers with the global prometheus registry the collector
+// exposing sveltos_profile_cluster_in_sync and sveltos_helm_chart_version_skew metrics.
+func RegisterVersionSkewCollector(c client.Client, logger logr.Logger) error {
+	return metrics.Registry.Register(&versionSkewCollector{c: c, logger: logger})
+}
+
+func (v *versionSkewCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- profileClusterInSyncDesc
+	ch <- helmChartVersionSkewDesc
+}
+
+func (v *versionSkewCollector) Collect(ch chan<- prometheus.Metric) {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	specs, err := v.getProfileSpecs(ctx)
+	if err != nil {
+		v.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect profiles: %v", err))
+		return
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := v.c.List(ctx, clusterSummaries); err != nil {
+		v.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return
+	}
+
+	for i := range clusterSummaries.Items {
+		clusterSummary := &clusterSummaries.Items[i]
+		profileRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+		if err != nil {
+			continue
+		}
+
+		profileKey := types.NamespacedName{Name: profileRef.Name}
+		if profileRef.Kind == configv1beta1.ProfileKind {
+			profileKey.Namespace = clusterSummary.Namespace
+		}
+		spec, ok := specs[profileRef.Kind][profileKey]
+		if !ok {
+			continue
+		}
+
+		cluster := fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
+		clusterType := string(clusterSummary.Spec.ClusterType)
+
+		inSync := 0.0
+		if isClusterSummaryInSync(clusterSummary, spec) {
+			inSync = 1
+		}
+		ch <- prometheus.MustNewConstMetric(profileClusterInSyncDesc, prometheus.GaugeValue, inSync,
+			profileRef.Kind, profileKey.String(), cluster, clusterType)
+
+		deployed := v.getDeployedChartVersions(ctx, clusterSummary, profileRef.Kind, profileRef.Name)
+		for j := range spec.HelmCharts {
+			chart := &spec.HelmCharts[j]
+			if chart.HelmChartAction == configv1beta1.HelmChartActionUninstall {
+				continue
+			}
+			deployedVersion := deployed[types.NamespacedName{Namespace: chart.ReleaseNamespace, Name: chart.ReleaseName}]
+			desiredVersion := getDesiredChartVersion(clusterSummary, chart)
+			skew := 0.0
+			if deployedVersion != desiredVersion {
+				skew = 1
+			}
+			ch <- prometheus.MustNewConstMetric(helmChartVersionSkewDesc, prometheus.GaugeValue, skew,
+				profileRef.Kind, profileKey.String(), cluster, clusterType, chart.ReleaseName, chart.ReleaseNamespace,
+				desiredVersion, deployedVersion)
+		}
+	}
+}
+
+// getProfileSpecs returns the Spec of all existing ClusterProfiles and Profiles
+// indexed by kind and name
+func (v *versionSkewCollector) getProfileSpecs(ctx context.Context,
+) (map[string]map[types.NamespacedName]*configv1beta1.Spec, error) {
+
+	specs := map[string]map[types.NamespacedName]*configv1beta1.Spec{
+		configv1beta1.ClusterProfileKind: {},
+		configv1beta1.ProfileKind:        {},
+	}
+
+	clusterProfiles := &configv1beta1.ClusterProfileList{}
+	if err := v.c.List(ctx, clusterProfiles); err != nil {
+		return nil, err
+	}
+	for i := range clusterProfiles.Items {
+		cp := &clusterProfiles.Items[i]
+		specs[configv1beta1.ClusterProfileKind][types.NamespacedName{Name: cp.Name}] = &cp.Spec
+	}
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := v.c.List(ctx, profiles); err != nil {
+		return nil, err
+	}
+	for i := range profiles.Items {
+		p := &profiles.Items[i]
+		specs[configv1beta1.ProfileKind][types.NamespacedName{Namespace: p.Namespace, Name: p.Name}] = &p.Spec
+	}
+
+	return specs, nil
+}
+
+// getDeployedChartVersions returns the chart versions deployed in the cluster because of
+// the given profile, as reported in the ClusterConfiguration. Key is the release.
+func (v *versionSkewCollector) getDeployedChartVersions(ctx context.Context,
+	clusterSummary *configv1beta1.ClusterSummary, profileKind, profileName string) map[types.NamespacedName]string {
+
+	result := make(map[types.NamespacedName]string)
+
+	features, err := getDeployedFeatures(ctx, v.c, clusterSummary, profileKind, profileName)
+	if err != nil {
+		return result
+	}
+
+	for i := range features {
+		if features[i].FeatureID != configv1beta1.FeatureHelm {
+			continue
+		}
+		for j := range features[i].Charts {
+			chart := &features[i].Charts[j]
+			result[types.NamespacedName{Namespace: chart.Namespace, Name: chart.ReleaseName}] = chart.ChartVersion
+		}
+	}
+
+	return result
+}
+
+// getDeployedFeatures returns what is deployed in the ClusterSummary's cluster because of the
+// given profile, as reported in the ClusterConfiguration.
+func getDeployedFeatures(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	profileKind, profileName string) ([]configv1beta1.Feature, error) {
+
+	clusterConfiguration, err := getClusterConfiguration(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		getClusterConfigurationName(clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType))
+	if err != nil {
+		return nil, err
+	}
+
+	if profileKind == configv1beta1.ClusterProfileKind {
+		for i := range clusterConfiguration.Status.ClusterProfileResources {
+			if clusterConfiguration.Status.ClusterProfileResources[i].ClusterProfileName == profileName {
+				return clusterConfiguration.Status.ClusterProfileResources[i].Features, nil
+			}
+		}
+	} else {
+		for i := range clusterConfiguration.Status.ProfileResources {
+			if clusterConfiguration.Status.ProfileResources[i].ProfileName == profileName {
+				return clusterConfiguration.Status.ProfileResources[i].Features, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// isClusterSummaryInSync returns true if ClusterSummary has been updated with current
+// profile Spec and all features are provisioned
+func isClusterSummaryInSync(clusterSummary *configv1beta1.ClusterSummary, spec *configv1beta1.Spec) bool {
+	if !reflect.DeepEqual(clusterSummary.Spec.ClusterProfileSpec, *spec) {
+		return false
+	}
+
+	return isCluterSummaryProvisioned(clusterSummary)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Version skew", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var spec *configv1beta1.Spec
+
+	BeforeEach(func() {
+		spec = &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{
+					RepositoryURL:    "https://kyverno.github.io/kyverno/",
+					RepositoryName:   "kyverno",
+					ChartName:        "kyverno/kyverno",
+					ChartVersion:     "v3.0.1",
+					ReleaseName:      "kyverno-latest",
+					ReleaseNamespace: "kyverno",
+					HelmChartAction:  configv1beta1.HelmChartActionInstall,
+				},
+			},
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace:   randomString(),
+				ClusterName:        randomString(),
+				ClusterType:        libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: *spec.DeepCopy(),
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+			},
+		}
+	})
+
+	It("isClusterSummaryInSync compares ClusterSummary with profile Spec", func() {
+		Expect(controllers.IsClusterSummaryInSync(clusterSummary, spec)).To(BeTrue())
+
+		spec.HelmCharts[0].ChartVersion = "v3.0.2"
+		Expect(controllers.IsClusterSummaryInSync(clusterSummary, spec)).To(BeFalse())
+
+		clusterSummary.Spec.ClusterProfileSpec = *spec.DeepCopy()
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusProvisioning
+		Expect(controllers.IsClusterSummaryInSync(clusterSummary, spec)).To(BeFalse())
+	})
+
+	It("getHelmReleaseInfo reports deployed version and status per release", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		info := controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary, logger)
+		Expect(len(info)).To(Equal(1))
+		Expect(info[0][len(info[0])-2]).To(BeEmpty())
+		Expect(info[0][len(info[0])-1]).To(Equal(string(configv1beta1.FeatureStatusProvisioning)))
+
+		clusterSummary.Status.HelmReleaseSummaries = []configv1beta1.HelmChartSummary{
+			{
+				ReleaseName:      "kyverno-latest",
+				ReleaseNamespace: "kyverno",
+				Status:           configv1beta1.HelmChartStatusManaging,
+				DeployedVersion:  "3.0.1",
+			},
+		}
+		info = controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary, logger)
+		Expect(len(info)).To(Equal(1))
+		Expect(info[0][len(info[0])-2]).To(Equal("3.0.1"))
+		Expect(info[0][len(info[0])-1]).To(Equal(string(configv1beta1.FeatureStatusProvisioned)))
+
+		// A new version is requested. Release is still deployed with previous one
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion = "v3.0.2"
+		info = controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary, logger)
+		Expect(info[0][len(info[0])-2]).To(Equal("3.0.1"))
+		Expect(info[0][len(info[0])-1]).To(Equal(string(configv1beta1.FeatureStatusProvisioning)))
+
+		clusterSummary.Status.HelmReleaseSummaries[0].FailureMessage = "upgrade failed"
+		info = controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary, logger)
+		Expect(info[0][len(info[0])-1]).To(Equal(string(configv1beta1.FeatureStatusFailed)))
+
+		clusterSummary.Status.HelmReleaseSummaries[0] = configv1beta1.HelmChartSummary{
+			ReleaseName:      "kyverno-latest",
+			ReleaseNamespace: "kyverno",
+			Status:           configv1beta1.HelmChartStatusConflict,
+		}
+		info = controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary, logger)
+		Expect(len(info)).To(Equal(1))
+		Expect(info[0][len(info[0])-1]).To(Equal(string(configv1beta1.HelmChartStatusConflict)))
+	})
+
+	It("getHelmReleaseInfo reports instantiated release name and namespace", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      clusterSummary.Spec.ClusterName,
+			},
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sveltosCluster.Namespace}}
+		Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+		Expect(testEnv.Create(context.TODO(), sveltosCluster)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, sveltosCluster)).To(Succeed())
+
+		clusterSummary.Spec.ClusterType = libsveltosv1beta1.ClusterTypeSveltos
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseName = "kyverno-{{ .Cluster.metadata.name }}"
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ReleaseNamespace = "{{ .Cluster.metadata.namespace }}"
+
+		info := controllers.GetHelmReleaseInfo(context.TODO(), clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(len(info)).To(Equal(1))
+		Expect(info[0][2]).To(Equal("kyverno-" + clusterSummary.Spec.ClusterName))
+		Expect(info[0][3]).To(Equal(clusterSummary.Spec.ClusterNamespace))
+	})
+})
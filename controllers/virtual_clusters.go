@@ -0,0 +1,246 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Virtual clusters (vcluster) deployed by the Helm feature are registered as SveltosClusters,
+// in the namespace of the cluster they run in (parent cluster), so profiles can target them
+// with a clusterSelector on the labels below.
+// The kubeconfig exported by vcluster is used as is: vcluster must be configured to export a
+// kubeconfig whose server is reachable from the management cluster (exportKubeConfig.server).
+const (
+	// VirtualClusterLabel is set to "true" on the SveltosClusters registered for virtual clusters
+	VirtualClusterLabel = "projectsveltos.io/virtual-cluster"
+
+	// ParentClusterNamespaceLabel is the namespace of the cluster the virtual cluster runs in
+	ParentClusterNamespaceLabel = "projectsveltos.io/parent-cluster-namespace"
+
+	// ParentClusterNameLabel is the name of the cluster the virtual cluster runs in
+	ParentClusterNameLabel = "projectsveltos.io/parent-cluster-name"
+
+	// ParentClusterTypeLabel is the type of the cluster the virtual cluster runs in
+	ParentClusterTypeLabel = "projectsveltos.io/parent-cluster-type"
+
+	// VirtualClusterNamespaceLabel is the namespace, in the parent cluster, the virtual cluster runs in
+	VirtualClusterNamespaceLabel = "projectsveltos.io/virtual-cluster-namespace"
+
+	// virtualClusterOwnerLabel is the name of the ClusterSummary which registered the virtual cluster
+	virtualClusterOwnerLabel = "projectsveltos.io/virtual-cluster-owner"
+
+	vclusterChartName = "vcluster"
+
+	// vcluster exports the virtual cluster kubeconfig in the Secret vc-<release name>, key config
+	vclusterKubeconfigSecretPrefix = "vc-"
+	vclusterKubeconfigKey          = "config"
+
+	sveltosKubeconfigSecretNamePostfix = "-sveltos-kubeconfig"
+	virtualClusterKubeconfigKey        = "kubeconfig"
+)
+
+// isVirtualClusterChart returns true if helmChart deploys a vcluster
+func isVirtualClusterChart(helmChart *configv1beta1.HelmChart) bool {
+	return helmChart.HelmChartAction != configv1beta1.HelmChartActionUninstall &&
+		getChartName(helmChart) == vclusterChartName
+}
+
+// getVirtualClusterName returns the name of the SveltosCluster registered for the virtual
+// cluster deployed by helmChart
+func getVirtualClusterName(clusterSummary *configv1beta1.ClusterSummary, helmChart *configv1beta1.HelmChart) string {
+	return fmt.Sprintf("%s-%s-%s", clusterSummary.Spec.ClusterName, helmChart.ReleaseNamespace, helmChart.ReleaseName)
+}
+
+// registerVirtualClusters registers the virtual clusters deployed by the helm charts of
+// clusterSummary. Virtual clusters previously registered for clusterSummary and not deployed
+// anymore are unregistered.
+func registerVirtualClusters(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	current := make(map[string]bool)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		helmChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+		if !isVirtualClusterChart(helmChart) {
+			continue
+		}
+
+		if err := registerVirtualCluster(ctx, c, remoteClient, clusterSummary, helmChart, logger); err != nil {
+			return err
+		}
+		current[getVirtualClusterName(clusterSummary, helmChart)] = true
+	}
+
+	return unregisterVirtualClusters(ctx, c, clusterSummary, current, logger)
+}
+
+// registerVirtualCluster creates/updates the SveltosCluster, and its kubeconfig Secret, for the
+// virtual cluster deployed by helmChart. An error is returned till vcluster has exported the
+// virtual cluster kubeconfig.
+func registerVirtualCluster(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, helmChart *configv1beta1.HelmChart, logger logr.Logger) error {
+
+	exported := &corev1.Secret{}
+	err := remoteClient.Get(ctx, types.NamespacedName{Namespace: helmChart.ReleaseNamespace,
+		Name: vclusterKubeconfigSecretPrefix + helmChart.ReleaseName}, exported)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("kubeconfig of virtual cluster %s/%s not exported yet",
+				helmChart.ReleaseNamespace, helmChart.ReleaseName)
+		}
+		return err
+	}
+
+	kubeconfig, ok := exported.Data[vclusterKubeconfigKey]
+	if !ok {
+		return fmt.Errorf("secret %s/%s does not contain %s", exported.Namespace, exported.Name, vclusterKubeconfigKey)
+	}
+
+	name := getVirtualClusterName(clusterSummary, helmChart)
+	logger = logger.WithValues("virtualCluster", fmt.Sprintf("%s/%s", clusterSummary.Spec.ClusterNamespace, name))
+	logger.V(logs.LogDebug).Info("registering virtual cluster")
+
+	objectMeta := metav1.ObjectMeta{
+		Namespace: clusterSummary.Spec.ClusterNamespace,
+		Labels: map[string]string{
+			VirtualClusterLabel:          "true",
+			ParentClusterNamespaceLabel:  clusterSummary.Spec.ClusterNamespace,
+			ParentClusterNameLabel:       clusterSummary.Spec.ClusterName,
+			ParentClusterTypeLabel:       string(clusterSummary.Spec.ClusterType),
+			VirtualClusterNamespaceLabel: helmChart.ReleaseNamespace,
+			virtualClusterOwnerLabel:     clusterSummary.Name,
+		},
+		// Registration is removed along with the ClusterSummary
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: configv1beta1.GroupVersion.String(),
+				Kind:       configv1beta1.ClusterSummaryKind,
+				Name:       clusterSummary.Name,
+				UID:        clusterSummary.UID,
+			},
+		},
+	}
+
+	secret := &corev1.Secret{ObjectMeta: *objectMeta.DeepCopy()}
+	secret.Name = name + sveltosKubeconfigSecretNamePostfix
+	secret.Data = map[string][]byte{virtualClusterKubeconfigKey: kubeconfig}
+	if err := createOrUpdateVirtualClusterObject(ctx, c, secret, &corev1.Secret{}, func(current client.Object) {
+		current.(*corev1.Secret).Data = secret.Data
+	}); err != nil {
+		return err
+	}
+
+	sveltosCluster := &libsveltosv1beta1.SveltosCluster{ObjectMeta: *objectMeta.DeepCopy()}
+	sveltosCluster.Name = name
+	sveltosCluster.Spec = libsveltosv1beta1.SveltosClusterSpec{
+		KubeconfigName:    secret.Name,
+		KubeconfigKeyName: virtualClusterKubeconfigKey,
+	}
+	return createOrUpdateVirtualClusterObject(ctx, c, sveltosCluster, &libsveltosv1beta1.SveltosCluster{},
+		func(current client.Object) {
+			currentCluster := current.(*libsveltosv1beta1.SveltosCluster)
+			currentCluster.Spec.KubeconfigName = sveltosCluster.Spec.KubeconfigName
+			currentCluster.Spec.KubeconfigKeyName = sveltosCluster.Spec.KubeconfigKeyName
+		})
+}
+
+// createOrUpdateVirtualClusterObject creates desired or, if it exists, sets its labels and
+// owner and updates it with update.
+func createOrUpdateVirtualClusterObject(ctx context.Context, c client.Client, desired, current client.Object,
+	update func(client.Object)) error {
+
+	err := c.Get(ctx, types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}, current)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return c.Create(ctx, desired)
+		}
+		return err
+	}
+
+	labels := current.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for k, v := range desired.GetLabels() {
+		labels[k] = v
+	}
+	current.SetLabels(labels)
+	current.SetOwnerReferences(desired.GetOwnerReferences())
+	update(current)
+
+	return c.Update(ctx, current)
+}
+
+// unregisterVirtualClusters removes the SveltosClusters, and their kubeconfig Secrets, registered
+// for clusterSummary and not in current
+func unregisterVirtualClusters(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	current map[string]bool, logger logr.Logger) error {
+
+	listOptions := []client.ListOption{
+		client.InNamespace(clusterSummary.Spec.ClusterNamespace),
+		client.MatchingLabels{VirtualClusterLabel: "true", virtualClusterOwnerLabel: clusterSummary.Name},
+	}
+
+	sveltosClusters := &libsveltosv1beta1.SveltosClusterList{}
+	if err := c.List(ctx, sveltosClusters, listOptions...); err != nil {
+		return err
+	}
+	for i := range sveltosClusters.Items {
+		sveltosCluster := &sveltosClusters.Items[i]
+		if current[sveltosCluster.Name] {
+			continue
+		}
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("unregistering virtual cluster %s/%s",
+			sveltosCluster.Namespace, sveltosCluster.Name))
+		if err := c.Delete(ctx, sveltosCluster); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, listOptions...); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if current[strings.TrimSuffix(secret.Name, sveltosKubeconfigSecretNamePostfix)] {
+			continue
+		}
+		if err := c.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("VirtualClusters", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var vclusterChart configv1beta1.HelmChart
+
+	BeforeEach(func() {
+		vclusterChart = configv1beta1.HelmChart{
+			RepositoryURL:    "https://charts.loft.sh",
+			RepositoryName:   "loft",
+			ChartName:        "loft/vcluster",
+			ChartVersion:     "0.20.0",
+			ReleaseName:      randomString(),
+			ReleaseNamespace: randomString(),
+			HelmChartAction:  configv1beta1.HelmChartActionInstall,
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+				UID:       types.UID(randomString()),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HelmCharts: []configv1beta1.HelmChart{
+						vclusterChart,
+						{
+							RepositoryURL:    "https://kyverno.github.io/kyverno/",
+							RepositoryName:   "kyverno",
+							ChartName:        "kyverno/kyverno",
+							ChartVersion:     "v3.0.1",
+							ReleaseName:      "kyverno",
+							ReleaseNamespace: "kyverno",
+							HelmChartAction:  configv1beta1.HelmChartActionInstall,
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("registerVirtualClusters registers the exported vcluster kubeconfig as a SveltosCluster", func() {
+		kubeconfig := []byte(randomString())
+		exported := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: vclusterChart.ReleaseNamespace,
+				Name:      "vc-" + vclusterChart.ReleaseName,
+			},
+			Data: map[string][]byte{"config": kubeconfig},
+		}
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(exported).Build()
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		Expect(controllers.RegisterVirtualClusters(context.TODO(), c, remoteClient, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		name := controllers.GetVirtualClusterName(clusterSummary, &vclusterChart)
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Spec.ClusterNamespace, Name: name},
+			sveltosCluster)).To(Succeed())
+		Expect(sveltosCluster.Labels).To(HaveKeyWithValue(controllers.VirtualClusterLabel, "true"))
+		Expect(sveltosCluster.Labels).To(HaveKeyWithValue(controllers.ParentClusterNamespaceLabel,
+			clusterSummary.Spec.ClusterNamespace))
+		Expect(sveltosCluster.Labels).To(HaveKeyWithValue(controllers.ParentClusterNameLabel,
+			clusterSummary.Spec.ClusterName))
+		Expect(sveltosCluster.Labels).To(HaveKeyWithValue(controllers.ParentClusterTypeLabel,
+			string(libsveltosv1beta1.ClusterTypeCapi)))
+		Expect(sveltosCluster.Labels).To(HaveKeyWithValue(controllers.VirtualClusterNamespaceLabel,
+			vclusterChart.ReleaseNamespace))
+		Expect(sveltosCluster.OwnerReferences).To(HaveLen(1))
+		Expect(sveltosCluster.OwnerReferences[0].UID).To(Equal(clusterSummary.UID))
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Spec.ClusterNamespace, Name: sveltosCluster.Spec.KubeconfigName},
+			secret)).To(Succeed())
+		Expect(secret.Data[sveltosCluster.Spec.KubeconfigKeyName]).To(Equal(kubeconfig))
+
+		// Only the vcluster chart is registered
+		sveltosClusters := &libsveltosv1beta1.SveltosClusterList{}
+		Expect(c.List(context.TODO(), sveltosClusters)).To(Succeed())
+		Expect(sveltosClusters.Items).To(HaveLen(1))
+	})
+
+	It("registerVirtualClusters returns an error till the vcluster kubeconfig is exported", func() {
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		Expect(controllers.RegisterVirtualClusters(context.TODO(), c, remoteClient, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))).ToNot(Succeed())
+
+		sveltosClusters := &libsveltosv1beta1.SveltosClusterList{}
+		Expect(c.List(context.TODO(), sveltosClusters)).To(Succeed())
+		Expect(sveltosClusters.Items).To(BeEmpty())
+	})
+
+	It("registerVirtualClusters does nothing in DryRun mode", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1beta1.SyncModeDryRun
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		Expect(controllers.RegisterVirtualClusters(context.TODO(), c, remoteClient, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+	})
+
+	It("unregisterVirtualClusters removes virtual clusters not deployed anymore", func() {
+		labels := map[string]string{
+			controllers.VirtualClusterLabel:           "true",
+			"projectsveltos.io/virtual-cluster-owner": clusterSummary.Name,
+		}
+		current := randomString()
+		stale := randomString()
+		otherOwner := randomString()
+
+		initObjects := []client.Object{}
+		for _, name := range []string{current, stale} {
+			initObjects = append(initObjects,
+				&libsveltosv1beta1.SveltosCluster{
+					ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Spec.ClusterNamespace, Name: name, Labels: labels},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Spec.ClusterNamespace,
+						Name: name + "-sveltos-kubeconfig", Labels: labels},
+				})
+		}
+		// Virtual clusters registered by other ClusterSummaries are left alone
+		initObjects = append(initObjects, &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Spec.ClusterNamespace, Name: otherOwner,
+				Labels: map[string]string{
+					controllers.VirtualClusterLabel:           "true",
+					"projectsveltos.io/virtual-cluster-owner": randomString(),
+				}},
+		})
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		Expect(controllers.UnregisterVirtualClusters(context.TODO(), c, clusterSummary, map[string]bool{current: true},
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		sveltosClusters := &libsveltosv1beta1.SveltosClusterList{}
+		Expect(c.List(context.TODO(), sveltosClusters)).To(Succeed())
+		Expect(sveltosClusters.Items).To(HaveLen(2))
+		for i := range sveltosClusters.Items {
+			Expect(sveltosClusters.Items[i].Name).ToNot(Equal(stale))
+		}
+
+		secrets := &corev1.SecretList{}
+		Expect(c.List(context.TODO(), secrets)).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		Expect(secrets.Items[0].Name).To(Equal(current + "-sveltos-kubeconfig"))
+	})
+})
@@ -0,0 +1,524 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"helm.sh/helm/v3/pkg/postrender"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	defaultWasmModuleKey          = "plugin.wasm"
+	defaultWasmPluginTimeout      = 10 * time.Second
+	defaultWasmPluginMaxMemoryMiB = 16
+
+	// wasmPagesPerMiB is the number of WebAssembly memory pages (64KiB each) in a MiB
+	wasmPagesPerMiB = 16
+
+	// maxWasmModuleSize is the maximum size of a module pulled from a registry
+	maxWasmModuleSize = 64 * 1024 * 1024
+
+	// maxWasmPluginOutputSize is the maximum size of the resources a plugin can write to stdout
+	maxWasmPluginOutputSize = 32 * 1024 * 1024
+
+	// maxWasmPluginErrorSize is the maximum size of the plugin stderr reported when a plugin fails
+	maxWasmPluginErrorSize = 4 * 1024
+)
+
+var (
+	// wasmModuleMediaTypes are the media types of OCI layers containing a WebAssembly module
+	wasmModuleMediaTypes = map[string]bool{
+		"application/wasm":                                  true,
+		"application/vnd.wasm.content.layer.v1+wasm":        true,
+		"application/vnd.module.wasm.content.layer.v1+wasm": true,
+	}
+
+	// wasmCompilationCache is shared by all runtimes, so each module is compiled once
+	wasmCompilationCache = wazero.NewCompilationCache()
+)
+
+type wasmPluginsContextKey struct{}
+
+// wasmPluginModule is a WasmPlugin along with its module
+type wasmPluginModule struct {
+	plugin *configv1beta1.WasmPlugin
+	module []byte
+}
+
+// validateWasmPlugins verifies each WasmPlugin references its module either in a ConfigMap or
+// in a registry
+func validateWasmPlugins(spec *configv1beta1.Spec) error {
+	for i := range spec.WasmPlugins {
+		plugin := &spec.WasmPlugins[i]
+		if (plugin.ConfigMapRef == nil) == (plugin.Image == "") {
+			return fmt.Errorf("wasmPlugin %s: exactly one of configMapRef and image must be set", plugin.Name)
+		}
+		if plugin.Image != "" {
+			if _, err := reference.ParseNormalizedNamed(plugin.Image); err != nil {
+				return fmt.Errorf("wasmPlugin %s: invalid image %q: %w", plugin.Name, plugin.Image, err)
+			}
+		} else if plugin.CredentialsSecretRef != nil {
+			return fmt.Errorf("wasmPlugin %s: credentialsSecretRef can only be set along with image", plugin.Name)
+		}
+	}
+
+	return nil
+}
+
+// withWasmPlugins returns a context running the WasmPlugins over the resources rendered for a
+// ClusterSummary feature. Modules are loaded once. If no WasmPlugin is set, ctx is returned unchanged.
+func withWasmPlugins(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (context.Context, error) {
+
+	plugins := clusterSummary.Spec.ClusterProfileSpec.WasmPlugins
+	if len(plugins) == 0 {
+		return ctx, nil
+	}
+
+	modules := make([]wasmPluginModule, len(plugins))
+	for i := range plugins {
+		var err error
+		modules[i].plugin = &plugins[i]
+		modules[i].module, err = getWasmPluginModule(ctx, c, clusterSummary, &plugins[i])
+		if err != nil {
+			return ctx, fmt.Errorf("wasmPlugin %s: %w", plugins[i].Name, err)
+		}
+	}
+
+	return context.WithValue(ctx, wasmPluginsContextKey{}, modules), nil
+}
+
+func getWasmPluginModules(ctx context.Context) []wasmPluginModule {
+	modules, ok := ctx.Value(wasmPluginsContextKey{}).([]wasmPluginModule)
+	if !ok {
+		return nil
+	}
+	return modules
+}
+
+// getWasmPluginConfigMapReference returns the ConfigMap containing the plugin module.
+// Nil if the module is pulled from a registry.
+func getWasmPluginConfigMapReference(clusterSummary *configv1beta1.ClusterSummary, plugin *configv1beta1.WasmPlugin,
+) (*corev1.ObjectReference, error) {
+
+	if plugin.ConfigMapRef == nil {
+		return nil, nil
+	}
+
+	namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
+		plugin.ConfigMapRef.Namespace)
+	name, err := libsveltostemplate.GetReferenceResourceName(clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, string(clusterSummary.Spec.ClusterType), plugin.ConfigMapRef.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+		Namespace:  namespace,
+		Name:       name,
+	}, nil
+}
+
+// getWasmPluginsReferences returns the ConfigMaps containing the WasmPlugin modules and the
+// Secrets containing the credentials to pull them
+func getWasmPluginsReferences(clusterSummary *configv1beta1.ClusterSummary) ([]*corev1.ObjectReference, error) {
+	references := make([]*corev1.ObjectReference, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.WasmPlugins {
+		plugin := &clusterSummary.Spec.ClusterProfileSpec.WasmPlugins[i]
+
+		ref, err := getWasmPluginConfigMapReference(clusterSummary, plugin)
+		if err != nil {
+			return nil, err
+		}
+		if ref != nil {
+			references = append(references, ref)
+		}
+
+		if plugin.CredentialsSecretRef != nil {
+			references = append(references, &corev1.ObjectReference{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       string(libsveltosv1beta1.SecretReferencedResourceKind),
+				Namespace: libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
+					plugin.CredentialsSecretRef.Namespace),
+				Name: plugin.CredentialsSecretRef.Name,
+			})
+		}
+	}
+
+	return references, nil
+}
+
+// getWasmPluginsConfigMapModulesHash returns the hash of the modules stored in ConfigMaps. Modules
+// pulled from a registry are not included.
+func getWasmPluginsConfigMapModulesHash(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary) (string, error) {
+
+	h := sha256.New()
+	for i := range clusterSummary.Spec.ClusterProfileSpec.WasmPlugins {
+		plugin := &clusterSummary.Spec.ClusterProfileSpec.WasmPlugins[i]
+		if plugin.ConfigMapRef == nil {
+			continue
+		}
+
+		module, err := getWasmPluginModule(ctx, c, clusterSummary, plugin)
+		if err != nil {
+			return "", err
+		}
+		h.Write(module)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// getWasmPluginModule returns the module of plugin, either reading it from its ConfigMap or pulling
+// it from its registry
+func getWasmPluginModule(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	plugin *configv1beta1.WasmPlugin) ([]byte, error) {
+
+	if plugin.Image != "" {
+		return pullWasmPluginModule(ctx, c, clusterSummary, plugin)
+	}
+
+	ref, err := getWasmPluginConfigMapReference(clusterSummary, plugin)
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil {
+		return nil, &NonRetriableError{Message: "exactly one of configMapRef and image must be set"}
+	}
+
+	configMap, err := getConfigMap(ctx, c, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	key := plugin.ConfigMapRef.Key
+	if key == "" {
+		key = defaultWasmModuleKey
+	}
+
+	module, ok := configMap.BinaryData[key]
+	if !ok {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("configMap %s/%s does not contain binaryData %s",
+			ref.Namespace, ref.Name, key)}
+	}
+
+	return module, nil
+}
+
+// pullWasmPluginModule pulls the module of plugin from its registry. The module is the layer of
+// the artifact with a WebAssembly media type or, if none, its only layer.
+func pullWasmPluginModule(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	plugin *configv1beta1.WasmPlugin) ([]byte, error) {
+
+	named, err := reference.ParseNormalizedNamed(plugin.Image)
+	if err != nil {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("invalid image %q: %v", plugin.Image, err)}
+	}
+
+	registryOptions := &registryClientOptions{}
+	if plugin.CredentialsSecretRef != nil {
+		namespace := libsveltostemplate.GetReferenceResourceNamespace(clusterSummary.Spec.ClusterNamespace,
+			plugin.CredentialsSecretRef.Namespace)
+		secret, err := getSecret(ctx, c, types.NamespacedName{Namespace: namespace, Name: plugin.CredentialsSecretRef.Name})
+		if err != nil {
+			return nil, err
+		}
+
+		dockerConfig, ok := secret.Data[corev1.DockerConfigJsonKey]
+		if !ok {
+			return nil, &NonRetriableError{Message: fmt.Sprintf("secret %s/%s does not contain %s",
+				namespace, plugin.CredentialsSecretRef.Name, corev1.DockerConfigJsonKey)}
+		}
+
+		// Credentials are loaded when the resolver is created. File is not needed afterwards.
+		registryOptions.credentialsPath, err = createTemporaryFile("wasm-plugin", dockerConfig)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(registryOptions.credentialsPath)
+	}
+
+	resolver, err := getOCIResolver(registryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := getMirroredURL(reference.TagNameOnly(named).String())
+	_, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve image %s: %w", ref, err)
+	}
+
+	data, err := fetchOCIContent(ctx, resolver, ref, desc, maxCosignContentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ocispec.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest for image %s: %w", ref, err)
+	}
+
+	var layer *ocispec.Descriptor
+	for i := range manifest.Layers {
+		if wasmModuleMediaTypes[manifest.Layers[i].MediaType] {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil && len(manifest.Layers) == 1 {
+		layer = &manifest.Layers[0]
+	}
+	if layer == nil {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("image %s does not contain a WebAssembly module", ref)}
+	}
+
+	return fetchOCIContent(ctx, resolver, ref, *layer, maxWasmModuleSize)
+}
+
+// runWasmPlugins passes resources through each WasmPlugin, in order, and returns the resources
+// output by the last one. Resources are returned unchanged if ctx does not run any WasmPlugin.
+func runWasmPlugins(ctx context.Context, resources []*unstructured.Unstructured,
+) ([]*unstructured.Unstructured, error) {
+
+	modules := getWasmPluginModules(ctx)
+	if len(modules) == 0 {
+		return resources, nil
+	}
+
+	input := &bytes.Buffer{}
+	for i := range resources {
+		data, err := yaml.Marshal(resources[i].Object)
+		if err != nil {
+			return nil, err
+		}
+		input.WriteString("---\n")
+		input.Write(data)
+	}
+
+	output := input.Bytes()
+	for i := range modules {
+		var err error
+		output, err = runWasmPlugin(ctx, &modules[i], output)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elements, err := customSplit(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(elements))
+	for i := range elements {
+		if strings.TrimSpace(elements[i]) == "" {
+			continue
+		}
+
+		policy, err := utils.GetUnstructured([]byte(elements[i]))
+		if err != nil {
+			return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugins output is not valid: %v", err)}
+		}
+		if policy == nil {
+			continue
+		}
+		result = append(result, policy)
+	}
+
+	return result, nil
+}
+
+// runWasmPlugin runs the module of a WasmPlugin, passing input to its stdin, and returns its stdout.
+// Module runs in a dedicated runtime, with its memory and running time limited.
+func runWasmPlugin(ctx context.Context, m *wasmPluginModule, input []byte) ([]byte, error) {
+	timeout := defaultWasmPluginTimeout
+	if m.plugin.Timeout != nil {
+		timeout = m.plugin.Timeout.Duration
+	}
+	maxMemoryMiB := m.plugin.MaxMemoryMiB
+	if maxMemoryMiB == 0 {
+		maxMemoryMiB = defaultWasmPluginMaxMemoryMiB
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(maxMemoryMiB) * wasmPagesPerMiB).
+		WithCloseOnContextDone(true).
+		WithCompilationCache(wasmCompilationCache)
+	runtime := wazero.NewRuntimeWithConfig(runCtx, config)
+	defer runtime.Close(ctx)
+
+	wasi_snapshot_preview1.MustInstantiate(runCtx, runtime)
+
+	compiled, err := runtime.CompileModule(runCtx, m.module)
+	if err != nil {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugin %s: invalid module: %v", m.plugin.Name, err)}
+	}
+
+	stdout := &limitedBuffer{limit: maxWasmPluginOutputSize}
+	stderr := &limitedBuffer{limit: maxWasmPluginErrorSize, truncate: true}
+	moduleConfig := wazero.NewModuleConfig().
+		WithName(m.plugin.Name).
+		WithArgs(m.plugin.Name).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr)
+
+	_, err = runtime.InstantiateModule(runCtx, compiled, moduleConfig)
+	if err != nil {
+		// Deployment is being canceled
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var exitErr *sys.ExitError
+		switch {
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == sys.ExitCodeDeadlineExceeded:
+			return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugin %s did not complete within %s",
+				m.plugin.Name, timeout)}
+		case stdout.exceeded:
+			return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugin %s output exceeds %d bytes",
+				m.plugin.Name, maxWasmPluginOutputSize)}
+		case errors.As(err, &exitErr):
+			return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugin %s failed with exit code %d: %s",
+				m.plugin.Name, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))}
+		default:
+			return nil, &NonRetriableError{Message: fmt.Sprintf("wasmPlugin %s failed: %v", m.plugin.Name, err)}
+		}
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// limitedBuffer is a bytes.Buffer holding at most limit bytes. Past limit, writes fail or,
+// if truncate is set, are discarded.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		b.exceeded = true
+		if !b.truncate {
+			return 0, fmt.Errorf("output exceeds %d bytes", b.limit)
+		}
+		b.Buffer.Write(p[:b.limit-b.Len()])
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// wasmPluginsPostRenderer is a helm post renderer passing the rendered resources through the
+// WasmPlugins. It runs after next, if set.
+type wasmPluginsPostRenderer struct {
+	ctx  context.Context //nolint: containedctx // helm post renderers are not passed a context
+	next postrender.PostRenderer
+}
+
+func (p *wasmPluginsPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if p.next != nil {
+		var err error
+		renderedManifests, err = p.next.Run(renderedManifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elements, err := customSplit(renderedManifests.String())
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*unstructured.Unstructured, 0, len(elements))
+	for i := range elements {
+		if strings.TrimSpace(elements[i]) == "" {
+			continue
+		}
+
+		var policy *unstructured.Unstructured
+		policy, err = utils.GetUnstructured([]byte(elements[i]))
+		if err != nil {
+			return nil, err
+		}
+		if policy == nil {
+			continue
+		}
+		resources = append(resources, policy)
+	}
+
+	resources, err = runWasmPlugins(p.ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &bytes.Buffer{}
+	for i := range resources {
+		var data []byte
+		data, err = yaml.Marshal(resources[i].Object)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString("---\n")
+		result.Write(data)
+	}
+
+	return result, nil
+}
+
+// getWasmPluginsPostRenderer returns a post renderer running the WasmPlugins after next.
+// If ctx does not run any WasmPlugin, next is returned.
+func getWasmPluginsPostRenderer(ctx context.Context, next postrender.PostRenderer) postrender.PostRenderer {
+	if len(getWasmPluginModules(ctx)) == 0 {
+		return next
+	}
+
+	return &wasmPluginsPostRenderer{ctx: ctx, next: next}
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	wasmEmittedConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: emitted
+  namespace: default
+`
+)
+
+// WebAssembly instructions used by the test modules
+const (
+	wasmBlock    = 0x02
+	wasmLoop     = 0x03
+	wasmEnd      = 0x0b
+	wasmBr       = 0x0c
+	wasmBrIf     = 0x0d
+	wasmCall     = 0x10
+	wasmDrop     = 0x1a
+	wasmI32Load  = 0x28
+	wasmI32Store = 0x36
+	wasmI32Const = 0x41
+	wasmI32Eqz   = 0x45
+	wasmVoid     = 0x40
+
+	// functions imported by the test modules
+	wasmFdRead   = 0
+	wasmFdWrite  = 1
+	wasmProcExit = 2
+)
+
+// wasmLEB128 encodes n as signed LEB128. Used both for sizes and i32 constants, n is never negative.
+func wasmLEB128(n int) []byte {
+	var result []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 && b&0x40 == 0 {
+			return append(result, b)
+		}
+		result = append(result, b|0x80)
+	}
+}
+
+func wasmVector(items ...[]byte) []byte {
+	result := wasmLEB128(len(items))
+	for i := range items {
+		result = append(result, items[i]...)
+	}
+	return result
+}
+
+func wasmName(name string) []byte {
+	return append(wasmLEB128(len(name)), name...)
+}
+
+func wasmSection(id byte, content []byte) []byte {
+	return append(append([]byte{id}, wasmLEB128(len(content))...), content...)
+}
+
+func wasmI32(n int) []byte {
+	return append([]byte{wasmI32Const}, wasmLEB128(n)...)
+}
+
+// wasmStore stores value at address
+func wasmStore(address, value []byte) []byte {
+	return append(append(append([]byte{}, address...), value...), wasmI32Store, 0x02, 0x00)
+}
+
+// wasmLoad loads the value at address
+func wasmLoad(address int) []byte {
+	return append(wasmI32(address), wasmI32Load, 0x02, 0x00)
+}
+
+// wasmFdCall calls fd_read/fd_write on fd with the iovec at iovec. Result is dropped.
+func wasmFdCall(function, fd, iovec, resultAddress int) []byte {
+	code := append(append(append(wasmI32(fd), wasmI32(iovec)...), wasmI32(1)...), wasmI32(resultAddress)...)
+	return append(code, wasmCall, byte(function), wasmDrop)
+}
+
+// buildWasmModule returns a WASI command whose _start function runs code. Module has a memory
+// of memoryPages pages, with data stored at address 64.
+func buildWasmModule(code []byte, data string, memoryPages int) []byte {
+	const wasiModule = "wasi_snapshot_preview1"
+
+	i32 := byte(0x7f)
+	types := wasmVector(
+		[]byte{0x60, 0x04, i32, i32, i32, i32, 0x01, i32}, // fd_read, fd_write
+		[]byte{0x60, 0x00, 0x00},                          // _start
+		[]byte{0x60, 0x01, i32, 0x00},                     // proc_exit
+	)
+	imports := wasmVector(
+		append(append(wasmName(wasiModule), wasmName("fd_read")...), 0x00, 0x00),
+		append(append(wasmName(wasiModule), wasmName("fd_write")...), 0x00, 0x00),
+		append(append(wasmName(wasiModule), wasmName("proc_exit")...), 0x00, 0x02),
+	)
+	exports := wasmVector(
+		append(wasmName("_start"), 0x00, 0x03),
+		append(wasmName("memory"), 0x02, 0x00),
+	)
+	body := append(append([]byte{0x00}, code...), wasmEnd)
+	segment := append(append([]byte{0x00}, append(wasmI32(64), wasmEnd)...), wasmName(data)...)
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, wasmSection(1, types)...)
+	module = append(module, wasmSection(2, imports)...)
+	module = append(module, wasmSection(3, wasmVector([]byte{0x01}))...)
+	module = append(module, wasmSection(5, wasmVector(append([]byte{0x00}, wasmLEB128(memoryPages)...)))...)
+	module = append(module, wasmSection(7, exports)...)
+	module = append(module, wasmSection(10, wasmVector(append(wasmLEB128(len(body)), body...)))...)
+	module = append(module, wasmSection(11, wasmVector(segment))...)
+	return module
+}
+
+// wasmCopyModule copies stdin to stdout
+func wasmCopyModule() []byte {
+	const bufferSize = 4096
+
+	var code []byte
+	code = append(code, wasmBlock, wasmVoid, wasmLoop, wasmVoid)
+	// read up to bufferSize bytes at 64. Number of bytes read is stored at 8
+	code = append(code, wasmStore(wasmI32(0), wasmI32(64))...)
+	code = append(code, wasmStore(wasmI32(4), wasmI32(bufferSize))...)
+	code = append(code, wasmFdCall(wasmFdRead, 0, 0, 8)...)
+	// stop at end of stdin
+	code = append(code, wasmLoad(8)...)
+	code = append(code, wasmI32Eqz, wasmBrIf, 0x01)
+	// write bytes read to stdout
+	code = append(code, wasmStore(wasmI32(16), wasmI32(64))...)
+	code = append(code, wasmStore(wasmI32(20), wasmLoad(8))...)
+	code = append(code, wasmFdCall(wasmFdWrite, 1, 16, 24)...)
+	code = append(code, wasmBr, 0x00, wasmEnd, wasmEnd)
+
+	return buildWasmModule(code, "", 1)
+}
+
+// wasmWriteModule writes data to fd and exits with exitCode
+func wasmWriteModule(data string, fd, exitCode int) []byte {
+	var code []byte
+	code = append(code, wasmStore(wasmI32(0), wasmI32(64))...)
+	code = append(code, wasmStore(wasmI32(4), wasmI32(len(data)))...)
+	code = append(code, wasmFdCall(wasmFdWrite, fd, 0, 8)...)
+	code = append(code, wasmI32(exitCode)...)
+	code = append(code, wasmCall, wasmProcExit)
+
+	return buildWasmModule(code, data, 1)
+}
+
+// wasmLoopModule never completes
+func wasmLoopModule() []byte {
+	return buildWasmModule([]byte{wasmLoop, wasmVoid, wasmBr, 0x00, wasmEnd}, "", 1)
+}
+
+var _ = Describe("WasmPlugins", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+	var resources []*unstructured.Unstructured
+
+	BeforeEach(func() {
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+		}
+
+		deployment, err := utils.GetUnstructured([]byte(deploymentWithImages))
+		Expect(err).To(BeNil())
+		resources = []*unstructured.Unstructured{deployment}
+	})
+
+	// withWasmPlugins returns a context running a plugin, stored in a ConfigMap, for each module
+	withWasmPlugins := func(modules ...[]byte) context.Context {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterSummary.Spec.ClusterNamespace, Name: randomString()},
+			BinaryData: map[string][]byte{},
+		}
+		for i := range modules {
+			key := randomString()
+			configMap.BinaryData[key] = modules[i]
+			clusterSummary.Spec.ClusterProfileSpec.WasmPlugins = append(clusterSummary.Spec.ClusterProfileSpec.WasmPlugins,
+				configv1beta1.WasmPlugin{
+					Name:         randomString(),
+					ConfigMapRef: &configv1beta1.WasmModuleConfigMapRef{Name: configMap.Name, Key: key},
+					Timeout:      &metav1.Duration{Duration: time.Second},
+				})
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		ctx, err := controllers.WithWasmPlugins(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		return ctx
+	}
+
+	It("validateWasmPlugins requires exactly one of configMapRef and image", func() {
+		spec := &configv1beta1.Spec{
+			WasmPlugins: []configv1beta1.WasmPlugin{{Name: randomString()}},
+		}
+		Expect(controllers.ValidateWasmPlugins(spec)).ToNot(Succeed())
+
+		spec.WasmPlugins[0].Image = "registry.io/plugins/policy:v1"
+		Expect(controllers.ValidateWasmPlugins(spec)).To(Succeed())
+
+		spec.WasmPlugins[0].ConfigMapRef = &configv1beta1.WasmModuleConfigMapRef{Name: randomString()}
+		Expect(controllers.ValidateWasmPlugins(spec)).ToNot(Succeed())
+
+		spec.WasmPlugins[0].Image = ""
+		Expect(controllers.ValidateWasmPlugins(spec)).To(Succeed())
+
+		spec.WasmPlugins[0].CredentialsSecretRef = &corev1.SecretReference{Name: randomString()}
+		Expect(controllers.ValidateWasmPlugins(spec)).ToNot(Succeed())
+	})
+
+	It("runWasmPlugins leaves resources unchanged when no plugin is set", func() {
+		result, err := controllers.RunWasmPlugins(context.TODO(), resources)
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal(resources))
+	})
+
+	It("runWasmPlugins passes resources through each plugin", func() {
+		ctx := withWasmPlugins(wasmCopyModule(), wasmCopyModule())
+
+		result, err := controllers.RunWasmPlugins(ctx, resources)
+		Expect(err).To(BeNil())
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Object).To(Equal(resources[0].Object))
+	})
+
+	It("runWasmPlugins deploys the resources output by the plugins", func() {
+		ctx := withWasmPlugins(wasmCopyModule(), wasmWriteModule(wasmEmittedConfigMap, 1, 0))
+
+		result, err := controllers.RunWasmPlugins(ctx, resources)
+		Expect(err).To(BeNil())
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].GetKind()).To(Equal("ConfigMap"))
+		Expect(result[0].GetName()).To(Equal("emitted"))
+	})
+
+	It("runWasmPlugins rejects resources when a plugin exits with a non-zero code", func() {
+		ctx := withWasmPlugins(wasmWriteModule("replicas must be at least 3", 2, 3))
+
+		_, err := controllers.RunWasmPlugins(ctx, resources)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("exit code 3"))
+		Expect(err.Error()).To(ContainSubstring("replicas must be at least 3"))
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(err).To(BeAssignableToTypeOf(nonRetriableError))
+	})
+
+	It("runWasmPlugins stops plugins running past their timeout", func() {
+		ctx := withWasmPlugins(wasmLoopModule())
+
+		_, err := controllers.RunWasmPlugins(ctx, resources)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("did not complete within 1s"))
+	})
+
+	It("runWasmPlugins rejects modules needing more memory than allowed", func() {
+		const maxMemoryMiB = 1
+		// One page more than allowed
+		module := buildWasmModule(nil, "", maxMemoryMiB*16+1)
+		ctx := withWasmPlugins(module)
+		clusterSummary.Spec.ClusterProfileSpec.WasmPlugins[0].MaxMemoryMiB = maxMemoryMiB
+
+		_, err := controllers.RunWasmPlugins(ctx, resources)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("invalid module"))
+	})
+
+	It("withWasmPlugins fails when the ConfigMap does not contain the module", func() {
+		clusterSummary.Spec.ClusterProfileSpec.WasmPlugins = []configv1beta1.WasmPlugin{
+			{
+				Name:         randomString(),
+				ConfigMapRef: &configv1beta1.WasmModuleConfigMapRef{Name: randomString()},
+			},
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      clusterSummary.Spec.ClusterProfileSpec.WasmPlugins[0].ConfigMapRef.Name,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		_, err := controllers.WithWasmPlugins(context.TODO(), c, clusterSummary)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("plugin.wasm"))
+	})
+
+	It("getWasmPluginsReferences returns the ConfigMaps and credentials Secrets referenced", func() {
+		secretNamespace := randomString()
+		clusterSummary.Spec.ClusterProfileSpec.WasmPlugins = []configv1beta1.WasmPlugin{
+			{
+				Name:         randomString(),
+				ConfigMapRef: &configv1beta1.WasmModuleConfigMapRef{Name: "{{ .Cluster.metadata.name }}-plugin"},
+			},
+			{
+				Name:                 randomString(),
+				Image:                "registry.io/plugins/policy:v1",
+				CredentialsSecretRef: &corev1.SecretReference{Namespace: secretNamespace, Name: "credentials"},
+			},
+		}
+
+		references, err := controllers.GetWasmPluginsReferences(clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(references).To(HaveLen(2))
+		Expect(references[0].Kind).To(Equal(string(libsveltosv1beta1.ConfigMapReferencedResourceKind)))
+		Expect(references[0].Namespace).To(Equal(clusterSummary.Spec.ClusterNamespace))
+		Expect(references[0].Name).To(Equal(clusterSummary.Spec.ClusterName + "-plugin"))
+		Expect(references[1].Kind).To(Equal(string(libsveltosv1beta1.SecretReferencedResourceKind)))
+		Expect(references[1].Namespace).To(Equal(secretNamespace))
+		Expect(references[1].Name).To(Equal("credentials"))
+	})
+
+	It("wasmPluginsPostRenderer passes helm rendered resources through the plugins", func() {
+		ctx := withWasmPlugins(wasmWriteModule(wasmEmittedConfigMap, 1, 0))
+
+		postRenderer := controllers.GetWasmPluginsPostRenderer(ctx, nil)
+		Expect(postRenderer).ToNot(BeNil())
+
+		result, err := postRenderer.Run(bytes.NewBufferString(deploymentWithImages))
+		Expect(err).To(BeNil())
+		Expect(result.String()).To(ContainSubstring("name: emitted"))
+		Expect(result.String()).ToNot(ContainSubstring("kind: Deployment"))
+
+		Expect(controllers.GetWasmPluginsPostRenderer(context.TODO(), nil)).To(BeNil())
+	})
+})
@@ -0,0 +1,144 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+)
+
+// WriteBudgetExhaustedError is returned when, because of the WriteBudget, not all resources were
+// applied in a deployment pass. It is not a failure: remaining resources are applied in following passes.
+type WriteBudgetExhaustedError struct {
+	// Applied is the number of resources applied in this pass
+	Applied int32
+	// Pending is the number of resources still to be applied
+	Pending int32
+}
+
+func (e *WriteBudgetExhaustedError) Error() string {
+	return fmt.Sprintf("write budget exhausted: %d resources applied, %d still to be applied",
+		e.Applied, e.Pending)
+}
+
+type writeBudgetContextKey struct{}
+
+var (
+	// writeBudgetProgress tracks, for each ClusterSummary and feature whose resources are being
+	// applied in chunks, the resources already applied (and their policy hash) in previous passes.
+	// key: getWriteBudgetProgressKey; value: map resource key => policy hash
+	writeBudgetProgress = make(map[string]map[string]string)
+	writeBudgetMux      sync.Mutex
+)
+
+// writeBudget limits the number of resources applied to a cluster in a single deployment pass
+type writeBudget struct {
+	key     string
+	limit   int32
+	applied int32
+	pending int32
+}
+
+func getWriteBudgetProgressKey(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) string {
+	return fmt.Sprintf("%s/%s:%s", clusterSummary.Namespace, clusterSummary.Name, featureID)
+}
+
+// withWriteBudget returns a context carrying the write budget for a deployment pass of the ClusterSummary
+// feature. If no WriteBudget is set (or in DryRun mode, as nothing is applied), ctx is returned unchanged.
+func withWriteBudget(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID) context.Context {
+
+	limit := clusterSummary.Spec.ClusterProfileSpec.WriteBudget
+	if limit == nil || clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return ctx
+	}
+
+	return context.WithValue(ctx, writeBudgetContextKey{},
+		&writeBudget{key: getWriteBudgetProgressKey(clusterSummary, featureID), limit: *limit})
+}
+
+func getWriteBudget(ctx context.Context) *writeBudget {
+	budget, ok := ctx.Value(writeBudgetContextKey{}).(*writeBudget)
+	if !ok {
+		return nil
+	}
+	return budget
+}
+
+// consume returns true if policy must be applied in this pass.
+// Resources already applied, with the same content, in a previous pass of the ongoing chunked deployment
+// are skipped. Once the budget is exhausted, remaining resources are counted as pending.
+func (b *writeBudget) consume(deployingToMgmtCluster bool, policy *unstructured.Unstructured, policyHash string) bool {
+	if b == nil {
+		return true
+	}
+
+	resourceKey := fmt.Sprintf("%t:%s:%s:%s", deployingToMgmtCluster,
+		policy.GroupVersionKind().GroupKind().String(), policy.GetNamespace(), policy.GetName())
+
+	writeBudgetMux.Lock()
+	defer writeBudgetMux.Unlock()
+
+	progress := writeBudgetProgress[b.key]
+	if progress == nil {
+		progress = make(map[string]string)
+		writeBudgetProgress[b.key] = progress
+	}
+
+	if hash, ok := progress[resourceKey]; ok && hash == policyHash {
+		return false
+	}
+
+	if b.applied >= b.limit {
+		b.pending++
+		return false
+	}
+
+	b.applied++
+	progress[resourceKey] = policyHash
+	return true
+}
+
+// complete returns a WriteBudgetExhaustedError if some resources are still to be applied.
+// Otherwise, as all resources have been applied, forgets about the chunked deployment progress.
+func (b *writeBudget) complete() error {
+	if b == nil {
+		return nil
+	}
+
+	if b.pending > 0 {
+		return &WriteBudgetExhaustedError{Applied: b.applied, Pending: b.pending}
+	}
+
+	writeBudgetMux.Lock()
+	defer writeBudgetMux.Unlock()
+	delete(writeBudgetProgress, b.key)
+
+	return nil
+}
+
+// forgetWriteBudgetProgress removes any chunked deployment progress for the ClusterSummary feature
+func forgetWriteBudgetProgress(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) {
+	writeBudgetMux.Lock()
+	defer writeBudgetMux.Unlock()
+	delete(writeBudgetProgress, getWriteBudgetProgressKey(clusterSummary, featureID))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Write budget", func() {
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		writeBudget := int32(2)
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{
+					WriteBudget: &writeBudget,
+				},
+			},
+		}
+	})
+
+	getConfigMaps := func(n int) []*unstructured.Unstructured {
+		result := make([]*unstructured.Unstructured, n)
+		for i := range result {
+			result[i] = &unstructured.Unstructured{}
+			result[i].SetAPIVersion("v1")
+			result[i].SetKind("ConfigMap")
+			result[i].SetNamespace(randomString())
+			result[i].SetName(randomString())
+		}
+		return result
+	}
+
+	// deployPass simulates a deployment pass and returns the resources applied
+	deployPass := func(resources []*unstructured.Unstructured, hash string) (applied int, err error) {
+		ctx := controllers.WithWriteBudget(context.TODO(), clusterSummary, configv1beta1.FeatureResources)
+		budget := controllers.GetWriteBudget(ctx)
+		for i := range resources {
+			if controllers.ConsumeWriteBudget(budget, false, resources[i], hash) {
+				applied++
+			}
+		}
+		return applied, controllers.CompleteWriteBudget(budget)
+	}
+
+	It("resources are applied in chunks across passes", func() {
+		resources := getConfigMaps(5)
+
+		applied, err := deployPass(resources, "v1")
+		Expect(applied).To(Equal(2))
+		var writeBudgetError *controllers.WriteBudgetExhaustedError
+		Expect(errors.As(err, &writeBudgetError)).To(BeTrue())
+		Expect(writeBudgetError.Pending).To(Equal(int32(3)))
+
+		applied, err = deployPass(resources, "v1")
+		Expect(applied).To(Equal(2))
+		Expect(errors.As(err, &writeBudgetError)).To(BeTrue())
+		Expect(writeBudgetError.Pending).To(Equal(int32(1)))
+
+		applied, err = deployPass(resources, "v1")
+		Expect(applied).To(Equal(1))
+		Expect(err).To(BeNil())
+
+		// Once all resources are applied, a new pass starts from scratch
+		applied, err = deployPass(resources, "v1")
+		Expect(applied).To(Equal(2))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("resources whose content changed are applied again", func() {
+		resources := getConfigMaps(3)
+
+		applied, err := deployPass(resources, "v1")
+		Expect(applied).To(Equal(2))
+		Expect(err).ToNot(BeNil())
+
+		applied, err = deployPass(resources, "v2")
+		Expect(applied).To(Equal(2))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("no budget is enforced when WriteBudget is not set", func() {
+		clusterSummary.Spec.ClusterProfileSpec.WriteBudget = nil
+
+		applied, err := deployPass(getConfigMaps(5), "v1")
+		Expect(applied).To(Equal(5))
+		Expect(err).To(BeNil())
+	})
+})
@@ -5,8 +5,12 @@ go 1.22.7
 require (
 	github.com/a8m/envsubst v1.4.2
 	github.com/onsi/ginkgo/v2 v2.20.2
+	github.com/spf13/pflag v1.0.5
 	golang.org/x/oauth2 v0.23.0
 	k8s.io/client-go v0.31.2
+	k8s.io/code-generator v0.31.1
+	k8s.io/gengo/v2 v2.0.0-20240228010128-51d4e06bde70
+	k8s.io/klog/v2 v2.130.1
 	sigs.k8s.io/controller-tools v0.16.4
 	sigs.k8s.io/kind v0.24.0
 )
@@ -37,7 +41,6 @@ require (
 	github.com/pelletier/go-toml v1.9.5 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/spf13/cobra v1.8.1 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/mod v0.21.0 // indirect
 	golang.org/x/net v0.30.0 // indirect
@@ -53,7 +56,6 @@ require (
 	k8s.io/api v0.31.2 // indirect
 	k8s.io/apiextensions-apiserver v0.31.1 // indirect
 	k8s.io/apimachinery v0.31.2 // indirect
-	k8s.io/klog/v2 v2.130.1 // indirect
 	k8s.io/utils v0.0.0-20240902221715-702e33fdd3c3 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
@@ -0,0 +1,77 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// lister-gen runs k8s.io/code-generator lister-gen. Generated listers qualify resources
+// calling the Resource(string) function of the API package. api/v1beta1 already has a
+// Resource type, so API packages declaring a Resource type expose the function as
+// GroupResource(string) and the generated listers call that one instead.
+package main
+
+import (
+	"flag"
+
+	"github.com/spf13/pflag"
+	"k8s.io/code-generator/cmd/lister-gen/args"
+	"k8s.io/code-generator/cmd/lister-gen/generators"
+	"k8s.io/code-generator/pkg/util"
+	"k8s.io/gengo/v2"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	resourceFunction      = "Resource"
+	groupResourceFunction = "GroupResource"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	args := args.New()
+
+	args.AddFlags(pflag.CommandLine)
+	_ = flag.Set("logtostderr", "true")
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if err := args.Validate(); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+
+	myTargets := func(context *generator.Context) []generator.Target {
+		for _, input := range context.Inputs {
+			pkg := context.Universe.Package(input)
+			if _, ok := pkg.Types[resourceFunction]; !ok {
+				continue
+			}
+			// Generated listers look up the Resource function of the package they list types of
+			pkg.Functions[resourceFunction] = context.Universe.Function(
+				types.Name{Package: input, Name: groupResourceFunction})
+		}
+		return generators.GetTargets(context, args)
+	}
+
+	if err := gengo.Execute(
+		generators.NameSystems(util.PluralExceptionListToMapOrDie(args.PluralExceptions)),
+		generators.DefaultNameSystem(),
+		myTargets,
+		gengo.StdBuildTag,
+		pflag.Args(),
+	); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+	klog.V(2).Info("Completed successfully.")
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package extension defines the gRPC API out-of-tree deployment engines implement to
+// register with addon-controller as a feature type.
+//
+// A plugin serves the ExtensionPlugin service for one Kind. Every time a ClusterSummary
+// with extensions of that Kind needs to be (re)deployed to a cluster, addon-controller
+// invokes Deploy passing all the extensions of that Kind. When the extensions are not
+// referenced anymore (or the ClusterSummary is deleted) Undeploy is invoked.
+// Both methods must be idempotent.
+//
+// Messages are JSON encoded so plugins do not need any generated code.
+package extension
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	// ServiceName is the fully qualified name of the gRPC service plugins serve
+	ServiceName = "projectsveltos.addoncontroller.extension.v1.ExtensionPlugin"
+
+	// CodecName is the name of the codec used to encode messages
+	CodecName = "json"
+
+	deployMethod   = "Deploy"
+	undeployMethod = "Undeploy"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// Cluster identifies the managed cluster extensions are deployed to.
+// The cluster kubeconfig can be found in the Secret Sveltos/ClusterAPI created for the cluster.
+type Cluster struct {
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Type      libsveltosv1beta1.ClusterType `json:"type"`
+}
+
+// Request is sent to a plugin on both Deploy and Undeploy
+type Request struct {
+	Cluster Cluster `json:"cluster"`
+
+	// ClusterSummary is the name of the ClusterSummary (in the cluster namespace) requesting this
+	ClusterSummary string `json:"clusterSummary"`
+
+	// Kind is the Kind the plugin is registered for
+	Kind string `json:"kind"`
+
+	// Extensions are all the extensions of Kind the ClusterSummary contains.
+	// Empty on Undeploy.
+	Extensions []configv1beta1.Extension `json:"extensions,omitempty"`
+
+	// DryRun, when set, indicates the plugin must not change anything in the managed cluster
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Response is returned by a plugin on both Deploy and Undeploy.
+// Plugins report failures returning a gRPC error.
+type Response struct {
+	// Message is an optional message describing the outcome
+	Message string `json:"message,omitempty"`
+}
+
+// PluginServer is the interface a plugin implements
+type PluginServer interface {
+	Deploy(ctx context.Context, req *Request) (*Response, error)
+	Undeploy(ctx context.Context, req *Request) (*Response, error)
+}
+
+// RegisterPluginServer registers srv with the gRPC server s
+func RegisterPluginServer(s grpc.ServiceRegistrar, srv PluginServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// PluginClient is the client addon-controller uses to invoke a plugin
+type PluginClient interface {
+	Deploy(ctx context.Context, req *Request) (*Response, error)
+	Undeploy(ctx context.Context, req *Request) (*Response, error)
+}
+
+type pluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewPluginClient returns a PluginClient using the connection cc
+func NewPluginClient(cc grpc.ClientConnInterface) PluginClient {
+	return &pluginClient{cc: cc}
+}
+
+func (c *pluginClient) Deploy(ctx context.Context, req *Request) (*Response, error) {
+	return c.invoke(ctx, deployMethod, req)
+}
+
+func (c *pluginClient) Undeploy(ctx context.Context, req *Request) (*Response, error) {
+	return c.invoke(ctx, undeployMethod, req)
+}
+
+func (c *pluginClient) invoke(ctx context.Context, method string, req *Request) (*Response, error) {
+	resp := &Response{}
+	err := c.cc.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*PluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: deployMethod, Handler: deployHandler},
+		{MethodName: undeployMethod, Handler: undeployHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func deployHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor,
+) (any, error) {
+
+	req := &Request{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Deploy(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + deployMethod}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PluginServer).Deploy(ctx, req.(*Request))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func undeployHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor,
+) (any, error) {
+
+	req := &Request{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Undeploy(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + undeployMethod}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PluginServer).Undeploy(ctx, req.(*Request))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
@@ -129,6 +129,11 @@ func (s *ClusterSummaryScope) SetDependenciesMessage(message *string) {
 	s.ClusterSummary.Status.Dependencies = message
 }
 
+// SetTierStatuses sets the deployment progress of each Tier
+func (s *ClusterSummaryScope) SetTierStatuses(tierStatuses []configv1beta1.TierStatus) {
+	s.ClusterSummary.Status.TierStatuses = tierStatuses
+}
+
 // SetFailureMessage sets the infrastructure status failure message.
 func (s *ClusterSummaryScope) SetFailureMessage(featureID configv1beta1.FeatureID, failureMessage *string) {
 	for i := range s.ClusterSummary.Status.FeatureSummaries {
@@ -192,6 +197,51 @@ func (s *ClusterSummaryScope) SetLastAppliedTime(featureID configv1beta1.Feature
 	)
 }
 
+// SetPendingResources sets the number of resources of the feature still to be applied because
+// of the WriteBudget.
+func (s *ClusterSummaryScope) SetPendingResources(featureID configv1beta1.FeatureID,
+	pendingResources *int32) {
+
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].PendingResources = pendingResources
+			return
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1beta1.FeatureSummary{
+			FeatureID:        featureID,
+			PendingResources: pendingResources,
+		},
+	)
+}
+
+// SetConsecutiveFailures sets the number of consecutive failed deployments of the feature.
+func (s *ClusterSummaryScope) SetConsecutiveFailures(featureID configv1beta1.FeatureID,
+	consecutiveFailures uint32) {
+
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].ConsecutiveFailures = consecutiveFailures
+			return
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1beta1.FeatureSummary{
+			FeatureID:           featureID,
+			ConsecutiveFailures: consecutiveFailures,
+		},
+	)
+}
+
 // IsContinuousWithDriftDetection returns true if ClusterProfile is set to SyncModeContinuousWithDriftDetection
 func (s *ClusterSummaryScope) IsContinuousWithDriftDetection() bool {
 	return s.ClusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection
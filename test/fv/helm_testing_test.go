@@ -0,0 +1,150 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fv_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Helm tests", func() {
+	const (
+		namePrefix = "helm-tests-"
+	)
+
+	It("Gates helm feature on chart tests passing", Label("FV", "EXTENDED"), func() {
+		Byf("Create a ClusterProfile matching Cluster %s/%s", kindWorkloadCluster.Namespace, kindWorkloadCluster.Name)
+		clusterProfile := getClusterProfile(namePrefix, map[string]string{key: value})
+		clusterProfile.Spec.SyncMode = configv1beta1.SyncModeContinuous
+		Expect(k8sClient.Create(context.TODO(), clusterProfile)).To(Succeed())
+
+		verifyClusterProfileMatches(clusterProfile)
+
+		verifyClusterSummary(controllers.ClusterProfileLabelName,
+			clusterProfile.Name, &clusterProfile.Spec, kindWorkloadCluster.Namespace, kindWorkloadCluster.Name)
+
+		// With no replicas, the podinfo service has no endpoints and chart tests fail
+		Byf("Update ClusterProfile %s to deploy podinfo helm chart with failing tests", clusterProfile.Name)
+		currentClusterProfile := &configv1beta1.ClusterProfile{}
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		currentClusterProfile.Spec.HelmCharts = []configv1beta1.HelmChart{
+			getPodinfoHelmChart(0),
+		}
+		Expect(k8sClient.Update(context.TODO(), currentClusterProfile)).To(Succeed())
+
+		clusterSummary := verifyClusterSummary(controllers.ClusterProfileLabelName,
+			currentClusterProfile.Name, &currentClusterProfile.Spec,
+			kindWorkloadCluster.Namespace, kindWorkloadCluster.Name)
+
+		Byf("Getting client to access the workload cluster")
+		workloadClient, err := getKindWorkloadClusterKubeconfig()
+		Expect(err).To(BeNil())
+		Expect(workloadClient).ToNot(BeNil())
+
+		Byf("Verifying podinfo deployment is created in the workload cluster")
+		Eventually(func() error {
+			depl := &appsv1.Deployment{}
+			return workloadClient.Get(context.TODO(),
+				types.NamespacedName{Namespace: "podinfo", Name: "podinfo"}, depl)
+		}, timeout, pollingInterval).Should(BeNil())
+
+		Byf("Verifying ClusterSummary reports helm tests failure")
+		Eventually(func() bool {
+			currentClusterSummary := &configv1beta1.ClusterSummary{}
+			err = k8sClient.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+				currentClusterSummary)
+			if err != nil {
+				return false
+			}
+			for i := range currentClusterSummary.Status.FeatureSummaries {
+				fs := &currentClusterSummary.Status.FeatureSummaries[i]
+				if fs.FeatureID == configv1beta1.FeatureHelm {
+					return fs.Status != configv1beta1.FeatureStatusProvisioned &&
+						fs.FailureMessage != nil && strings.Contains(*fs.FailureMessage, "helm tests for release")
+				}
+			}
+			return false
+		}, timeout, pollingInterval).Should(BeTrue())
+
+		Byf("Update ClusterProfile %s to deploy podinfo helm chart with passing tests", clusterProfile.Name)
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		currentClusterProfile.Spec.HelmCharts = []configv1beta1.HelmChart{
+			getPodinfoHelmChart(1),
+		}
+		Expect(k8sClient.Update(context.TODO(), currentClusterProfile)).To(Succeed())
+
+		verifyClusterSummary(controllers.ClusterProfileLabelName,
+			currentClusterProfile.Name, &currentClusterProfile.Spec,
+			kindWorkloadCluster.Namespace, kindWorkloadCluster.Name)
+
+		Byf("Verifying ClusterSummary %s status is set to Deployed for Helm feature", clusterSummary.Name)
+		verifyFeatureStatusIsProvisioned(kindWorkloadCluster.Namespace, clusterSummary.Name, configv1beta1.FeatureHelm)
+
+		charts := []configv1beta1.Chart{
+			{ReleaseName: "podinfo", ChartVersion: "6.7.1", Namespace: "podinfo"},
+		}
+
+		verifyClusterConfiguration(configv1beta1.ClusterProfileKind, clusterProfile.Name,
+			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, configv1beta1.FeatureHelm,
+			nil, charts)
+
+		deleteClusterProfile(clusterProfile)
+
+		Byf("Verifying podinfo deployment is removed from workload cluster")
+		Eventually(func() bool {
+			depl := &appsv1.Deployment{}
+			err = workloadClient.Get(context.TODO(),
+				types.NamespacedName{Namespace: "podinfo", Name: "podinfo"}, depl)
+			return apierrors.IsNotFound(err)
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+})
+
+func getPodinfoHelmChart(replicas int) configv1beta1.HelmChart {
+	return configv1beta1.HelmChart{
+		RepositoryURL:    "https://stefanprodan.github.io/podinfo",
+		RepositoryName:   "podinfo",
+		ChartName:        "podinfo/podinfo",
+		ChartVersion:     "6.7.1",
+		ReleaseName:      "podinfo",
+		ReleaseNamespace: "podinfo",
+		HelmChartAction:  configv1beta1.HelmChartActionInstall,
+		Values: fmt.Sprintf(`test:
+  enable: true
+replicaCount: %d`, replicas),
+		Options: &configv1beta1.HelmOptions{
+			Wait:             true,
+			Timeout:          &metav1.Duration{Duration: 5 * time.Minute},
+			RunHelmTests:     true,
+			HelmTestsTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+}